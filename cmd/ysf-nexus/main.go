@@ -6,18 +6,20 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/handover"
 	"github.com/dbehnke/ysf-nexus/pkg/logger"
 	"github.com/dbehnke/ysf-nexus/pkg/reflector"
+	"github.com/dbehnke/ysf-nexus/pkg/selftest"
+	"github.com/dbehnke/ysf-nexus/pkg/version"
 )
 
-var (
-	Version   = "dev"
-	BuildTime = "unknown"
-)
+// defaultServiceName is the Windows service and event log source name
+const defaultServiceName = "ysf-nexus"
 
 func main() {
 	rootCmd := &cobra.Command{
@@ -25,7 +27,7 @@ func main() {
 		Short: "A modern YSF reflector written in Go",
 		Long: `YSF Nexus is a high-performance YSF (Yaesu System Fusion) reflector
 with web dashboard, MQTT integration, and bridge capabilities.`,
-		Version: fmt.Sprintf("%s (built at %s)", Version, BuildTime),
+		Version: version.Get().String(),
 		RunE:    runServer,
 	}
 
@@ -34,6 +36,23 @@ with web dashboard, MQTT integration, and bridge capabilities.`,
 	rootCmd.Flags().String("host", "", "Server host (overrides config)")
 	rootCmd.Flags().IntP("port", "p", 0, "Server port (overrides config)")
 	rootCmd.Flags().BoolP("debug", "d", false, "Enable debug logging (overrides config)")
+	rootCmd.Flags().String("service-name", defaultServiceName, "Windows service name, set by service install")
+	_ = rootCmd.Flags().MarkHidden("service-name")
+
+	selftestCmd := &cobra.Command{
+		Use:   "selftest",
+		Short: "Run a scripted self-test against a temporary reflector",
+		Long: `Starts a reflector on ephemeral loopback ports, drives it with simulated
+repeaters and a loopback bridge, and exits non-zero if any check fails.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runSelftest,
+	}
+	selftestCmd.Flags().BoolP("debug", "d", false, "Show reflector logs")
+	selftestCmd.Flags().Duration("timeout", 30*time.Second, "Abort the self-test after this long")
+	rootCmd.AddCommand(selftestCmd)
+
+	addServiceCommands(rootCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -47,6 +66,10 @@ func runServer(cmd *cobra.Command, args []string) error {
 	hostOverride, _ := cmd.Flags().GetString("host")
 	portOverride, _ := cmd.Flags().GetInt("port")
 	debugOverride, _ := cmd.Flags().GetBool("debug")
+	serviceName, _ := cmd.Flags().GetString("service-name")
+
+	// Under the Windows service manager there is no console; log to the event log too
+	asService := isWindowsService()
 
 	// Load configuration
 	cfg, err := config.Load(configFile)
@@ -54,12 +77,14 @@ func runServer(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	// Apply command line overrides
+	// Apply command line overrides; they replace any configured listen list
 	if hostOverride != "" {
 		cfg.Server.Host = hostOverride
+		cfg.Server.Listen = nil
 	}
 	if portOverride > 0 {
 		cfg.Server.Port = portOverride
+		cfg.Server.Listen = nil
 	}
 	if debugOverride {
 		cfg.Logging.Level = "debug"
@@ -75,6 +100,9 @@ func runServer(cmd *cobra.Command, args []string) error {
 		MaxAge:      cfg.Logging.MaxAge,
 		Development: cfg.Logging.Level == "debug",
 	}
+	if asService {
+		loggerConfig.EventLog = serviceName
+	}
 
 	log, err := logger.New(loggerConfig)
 	if err != nil {
@@ -82,13 +110,34 @@ func runServer(cmd *cobra.Command, args []string) error {
 	}
 	defer log.Sync()
 
+	build := version.Get()
 	log.Info("YSF Nexus starting",
-		logger.String("version", Version),
-		logger.String("build_time", BuildTime),
+		logger.String("version", build.Version),
+		logger.String("commit", build.Commit),
+		logger.Any("dirty", build.Dirty),
+		logger.String("build_time", build.BuildTime),
+		logger.String("go_version", build.GoVersion),
+		logger.String("platform", build.Platform),
 		logger.String("config_file", configFile))
 
 	// Create and start reflector
-	r := reflector.NewWithVersion(cfg, log, Version, BuildTime)
+	r := reflector.New(cfg, log)
+
+	// A process started by a binary upgrade takes over its predecessor's sockets
+	inherited, err := handover.FromEnvironment()
+	if err != nil {
+		log.Error("Failed to take over sockets, binding new ones", logger.Error(err))
+	}
+	r.AdoptHandover(inherited)
+
+	// The service manager's stop and shutdown requests replace signals
+	if asService {
+		if err := runAsService(serviceName, r.Start); err != nil {
+			log.Error("Service error", logger.Error(err))
+			return err
+		}
+		return nil
+	}
 
 	// Setup context for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
@@ -104,6 +153,47 @@ func runServer(cmd *cobra.Command, args []string) error {
 		cancel()
 	}()
 
+	// SIGHUP reloads the bridge definitions
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	defer signal.Stop(hupChan)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hupChan:
+				log.Info("Reload signal received")
+				if _, err := r.ReloadBridges(); err != nil {
+					log.Error("Failed to reload bridges", logger.Error(err))
+				}
+			}
+		}
+	}()
+
+	// SIGUSR2 hands the sockets to a fresh copy of the binary, then exits
+	upgradeChan := make(chan os.Signal, 1)
+	notifyUpgrade(upgradeChan)
+	defer signal.Stop(upgradeChan)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-upgradeChan:
+				log.Info("Upgrade signal received")
+				if err := r.Upgrade(ctx); err != nil {
+					log.Error("Binary upgrade failed, still serving", logger.Error(err))
+					continue
+				}
+				cancel()
+				return
+			}
+		}
+	}()
+
 	// Start the reflector
 	if err := r.Start(ctx); err != nil {
 		log.Error("Reflector error", logger.Error(err))
@@ -112,3 +202,44 @@ func runServer(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func runSelftest(cmd *cobra.Command, args []string) error {
+	debug, _ := cmd.Flags().GetBool("debug")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+
+	// The reflector under test logs through this logger; keep it quiet unless asked
+	level := "error"
+	if debug {
+		level = "debug"
+	}
+	log, err := logger.New(logger.Config{Level: level, Format: "console"})
+	if err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	defer log.Sync()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	fmt.Printf("YSF Nexus %s self-test\n", version.Version)
+	report, err := selftest.Run(ctx, log)
+	for _, check := range report.Checks {
+		status := "PASS"
+		if !check.Passed {
+			status = "FAIL"
+		}
+		fmt.Printf("  %s  %-32s %s\n", status, check.Name, check.Duration.Round(time.Millisecond))
+		if check.Detail != "" {
+			fmt.Printf("        %s\n", check.Detail)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("self-test aborted: %w", err)
+	}
+
+	if failed := report.Failed(); failed > 0 {
+		return fmt.Errorf("self-test failed: %d of %d checks failed", failed, len(report.Checks))
+	}
+	fmt.Printf("All %d checks passed\n", len(report.Checks))
+	return nil
+}
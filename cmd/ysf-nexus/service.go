@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// serviceStopTimeout bounds how long "service stop" waits for the
+	// reflector to drain and exit
+	serviceStopTimeout = 30 * time.Second
+	// serviceStopProgress is how often a stopping service tells the service
+	// control manager it is still making progress
+	serviceStopProgress = 2 * time.Second
+)
+
+// serviceState is the state a service reports to the service control manager
+type serviceState int
+
+const (
+	serviceStartPending serviceState = iota
+	serviceRunning
+	serviceStopPending
+)
+
+// serviceStatus is what a service reports about itself. While a stop is
+// pending CheckPoint grows with each report, and the manager waits up to
+// WaitHint for the next one before treating the service as hung.
+type serviceStatus struct {
+	State      serviceState
+	CheckPoint uint32
+	WaitHint   time.Duration
+}
+
+// serviceRequest is a control request from the service control manager
+type serviceRequest int
+
+const (
+	serviceInterrogate serviceRequest = iota
+	serviceStop
+)
+
+// serviceHost is the service control manager as seen by a running service
+type serviceHost interface {
+	// Requests delivers the control requests for the service
+	Requests() <-chan serviceRequest
+	// Report tells the manager the service's current status
+	Report(status serviceStatus)
+}
+
+// runService runs the reflector until it returns or host asks it to stop,
+// which cancels its context so it drains like on a signal. It returns the
+// service exit code.
+func runService(host serviceHost, run func(ctx context.Context) error, progress time.Duration) uint32 {
+	current := serviceStatus{State: serviceStartPending}
+	report := func(status serviceStatus) {
+		current = status
+		host.Report(status)
+	}
+	report(current)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- run(ctx) }()
+
+	report(serviceStatus{State: serviceRunning})
+
+	var ticks <-chan time.Time
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				return 1
+			}
+			return 0
+		case <-ticks:
+			report(serviceStatus{State: serviceStopPending, CheckPoint: current.CheckPoint + 1, WaitHint: serviceStopTimeout})
+		case req := <-host.Requests():
+			switch req {
+			case serviceInterrogate:
+				host.Report(current)
+			case serviceStop:
+				if ticks == nil {
+					report(serviceStatus{State: serviceStopPending, CheckPoint: 1, WaitHint: serviceStopTimeout})
+					cancel()
+					ticker := time.NewTicker(progress)
+					defer ticker.Stop()
+					ticks = ticker.C
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,23 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/spf13/cobra"
+)
+
+// isWindowsService is always false outside Windows
+func isWindowsService() bool {
+	return false
+}
+
+// runAsService is only supported on Windows
+func runAsService(name string, run func(ctx context.Context) error) error {
+	return errors.New("service mode is only available on Windows")
+}
+
+// addServiceCommands adds nothing outside Windows; use systemd or similar
+func addServiceCommands(root *cobra.Command) {}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeServiceHost records the statuses a service reports
+type fakeServiceHost struct {
+	requests chan serviceRequest
+	statuses chan serviceStatus
+}
+
+func newFakeServiceHost() *fakeServiceHost {
+	return &fakeServiceHost{requests: make(chan serviceRequest), statuses: make(chan serviceStatus, 100)}
+}
+
+func (h *fakeServiceHost) Requests() <-chan serviceRequest { return h.requests }
+func (h *fakeServiceHost) Report(status serviceStatus)     { h.statuses <- status }
+
+// next returns the next reported status
+func (h *fakeServiceHost) next(t *testing.T) serviceStatus {
+	t.Helper()
+	select {
+	case status := <-h.statuses:
+		return status
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a service status")
+		return serviceStatus{}
+	}
+}
+
+func TestRunServiceStop(t *testing.T) {
+	host := newFakeServiceHost()
+	release := make(chan struct{})
+	exit := make(chan uint32, 1)
+	go func() {
+		exit <- runService(host, func(ctx context.Context) error {
+			<-ctx.Done()
+			<-release // Still draining
+			return nil
+		}, 10*time.Millisecond)
+	}()
+
+	if status := host.next(t); status.State != serviceStartPending {
+		t.Fatalf("first status %+v, want start pending", status)
+	}
+	if status := host.next(t); status.State != serviceRunning {
+		t.Fatalf("second status %+v, want running", status)
+	}
+	host.requests <- serviceInterrogate
+	if status := host.next(t); status.State != serviceRunning {
+		t.Errorf("interrogated status %+v, want running", status)
+	}
+
+	// While the reflector drains the stop keeps reporting progress
+	host.requests <- serviceStop
+	for want := uint32(1); want <= 3; want++ {
+		status := host.next(t)
+		if status.State != serviceStopPending || status.CheckPoint != want || status.WaitHint <= 0 {
+			t.Fatalf("status %+v while stopping, want stop pending at check point %d", status, want)
+		}
+	}
+	host.requests <- serviceInterrogate
+	if status := host.next(t); status.State != serviceStopPending {
+		t.Errorf("interrogated status %+v while stopping, want stop pending", status)
+	}
+
+	close(release)
+	select {
+	case code := <-exit:
+		if code != 0 {
+			t.Errorf("exit code %d, want 0", code)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("service did not exit")
+	}
+}
+
+func TestRunServiceFails(t *testing.T) {
+	host := newFakeServiceHost()
+	code := runService(host, func(ctx context.Context) error {
+		return errors.New("bind failed")
+	}, time.Second)
+	if code != 1 {
+		t.Errorf("exit code %d, want 1", code)
+	}
+}
@@ -0,0 +1,263 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/eventlog"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// isWindowsService reports whether the process was started by the service
+// control manager
+func isWindowsService() bool {
+	ok, err := svc.IsWindowsService()
+	return err == nil && ok
+}
+
+// runAsService runs the reflector under the service control manager until
+// it is stopped or run returns
+func runAsService(name string, run func(ctx context.Context) error) error {
+	return svc.Run(name, &serviceHandler{run: run})
+}
+
+// serviceHandler runs the reflector as a service, see runService
+type serviceHandler struct {
+	run func(ctx context.Context) error
+}
+
+// Execute implements svc.Handler
+func (h *serviceHandler) Execute(args []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	host := &windowsServiceHost{requests: make(chan serviceRequest), status: status}
+	stop := make(chan struct{})
+	defer close(stop)
+	go host.translate(requests, stop)
+	return false, runService(host, h.run, serviceStopProgress)
+}
+
+// windowsServiceHost is the Windows service control manager as a serviceHost
+type windowsServiceHost struct {
+	requests chan serviceRequest
+	status   chan<- svc.Status
+}
+
+// translate passes on the requests the service handles until stop closes
+func (h *windowsServiceHost) translate(requests <-chan svc.ChangeRequest, stop <-chan struct{}) {
+	for {
+		var req serviceRequest
+		select {
+		case <-stop:
+			return
+		case change := <-requests:
+			switch change.Cmd {
+			case svc.Interrogate:
+				req = serviceInterrogate
+			case svc.Stop, svc.Shutdown:
+				req = serviceStop
+			default:
+				continue
+			}
+		}
+		select {
+		case <-stop:
+			return
+		case h.requests <- req:
+		}
+	}
+}
+
+// Requests implements serviceHost
+func (h *windowsServiceHost) Requests() <-chan serviceRequest {
+	return h.requests
+}
+
+// Report implements serviceHost
+func (h *windowsServiceHost) Report(status serviceStatus) {
+	s := svc.Status{CheckPoint: status.CheckPoint, WaitHint: uint32(status.WaitHint / time.Millisecond)}
+	switch status.State {
+	case serviceStartPending:
+		s.State = svc.StartPending
+	case serviceRunning:
+		s.State = svc.Running
+		s.Accepts = svc.AcceptStop | svc.AcceptShutdown
+	case serviceStopPending:
+		s.State = svc.StopPending
+	}
+	h.status <- s
+}
+
+// addServiceCommands adds the Windows service management subcommands
+func addServiceCommands(root *cobra.Command) {
+	serviceCmd := &cobra.Command{
+		Use:   "service",
+		Short: "Install and control the reflector as a Windows service",
+	}
+	serviceCmd.PersistentFlags().String("name", defaultServiceName, "Service name")
+
+	installCmd := &cobra.Command{
+		Use:          "install",
+		Short:        "Install the reflector as an automatically started service",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         installService,
+	}
+	installCmd.Flags().StringP("config", "c", "config.yaml", "Configuration file path used by the service")
+	installCmd.Flags().String("display-name", "YSF Nexus Reflector", "Service display name")
+
+	serviceCmd.AddCommand(installCmd,
+		&cobra.Command{
+			Use:          "uninstall",
+			Short:        "Remove the service and its event log source",
+			Args:         cobra.NoArgs,
+			SilenceUsage: true,
+			RunE:         uninstallService,
+		},
+		&cobra.Command{
+			Use:          "start",
+			Short:        "Start the service",
+			Args:         cobra.NoArgs,
+			SilenceUsage: true,
+			RunE:         startService,
+		},
+		&cobra.Command{
+			Use:          "stop",
+			Short:        "Stop the service and wait for it to exit",
+			Args:         cobra.NoArgs,
+			SilenceUsage: true,
+			RunE:         stopService,
+		},
+	)
+	root.AddCommand(serviceCmd)
+}
+
+// openService connects to the service manager and opens the named service
+func openService(cmd *cobra.Command) (*mgr.Mgr, *mgr.Service, error) {
+	name, _ := cmd.Flags().GetString("name")
+	m, err := mgr.Connect()
+	if err != nil {
+		return nil, nil, fmt.Errorf("connect to service manager: %w", err)
+	}
+	s, err := m.OpenService(name)
+	if err != nil {
+		_ = m.Disconnect()
+		return nil, nil, fmt.Errorf("open service %s: %w", name, err)
+	}
+	return m, s, nil
+}
+
+func installService(cmd *cobra.Command, args []string) error {
+	name, _ := cmd.Flags().GetString("name")
+	displayName, _ := cmd.Flags().GetString("display-name")
+	configFile, _ := cmd.Flags().GetString("config")
+
+	// Services start in the system directory, so both paths must be absolute
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locate executable: %w", err)
+	}
+	configPath, err := filepath.Abs(configFile)
+	if err != nil {
+		return fmt.Errorf("resolve config path: %w", err)
+	}
+	if _, err := os.Stat(configPath); err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("connect to service manager: %w", err)
+	}
+	defer func() { _ = m.Disconnect() }()
+
+	if s, err := m.OpenService(name); err == nil {
+		_ = s.Close()
+		return fmt.Errorf("service %s already exists", name)
+	}
+
+	s, err := m.CreateService(name, exe, mgr.Config{
+		DisplayName: displayName,
+		Description: "YSF (Yaesu System Fusion) reflector",
+		StartType:   mgr.StartAutomatic,
+	}, "--config", configPath, "--service-name", name)
+	if err != nil {
+		return fmt.Errorf("create service: %w", err)
+	}
+	defer func() { _ = s.Close() }()
+
+	if err := eventlog.InstallAsEventCreate(name, eventlog.Error|eventlog.Warning|eventlog.Info); err != nil {
+		_ = s.Delete()
+		return fmt.Errorf("register event log source: %w", err)
+	}
+
+	fmt.Printf("Installed service %s using %s\n", name, configPath)
+	return nil
+}
+
+func uninstallService(cmd *cobra.Command, args []string) error {
+	m, s, err := openService(cmd)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = m.Disconnect() }()
+	defer func() { _ = s.Close() }()
+
+	if err := s.Delete(); err != nil {
+		return fmt.Errorf("delete service: %w", err)
+	}
+	if err := eventlog.Remove(s.Name); err != nil {
+		return fmt.Errorf("remove event log source: %w", err)
+	}
+
+	fmt.Printf("Removed service %s\n", s.Name)
+	return nil
+}
+
+func startService(cmd *cobra.Command, args []string) error {
+	m, s, err := openService(cmd)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = m.Disconnect() }()
+	defer func() { _ = s.Close() }()
+
+	if err := s.Start(); err != nil {
+		return fmt.Errorf("start service: %w", err)
+	}
+	fmt.Printf("Started service %s\n", s.Name)
+	return nil
+}
+
+func stopService(cmd *cobra.Command, args []string) error {
+	m, s, err := openService(cmd)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = m.Disconnect() }()
+	defer func() { _ = s.Close() }()
+
+	status, err := s.Control(svc.Stop)
+	if err != nil {
+		return fmt.Errorf("stop service: %w", err)
+	}
+
+	deadline := time.Now().Add(serviceStopTimeout)
+	for status.State != svc.Stopped {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("service %s did not stop within %s", s.Name, serviceStopTimeout)
+		}
+		time.Sleep(300 * time.Millisecond)
+		if status, err = s.Query(); err != nil {
+			return fmt.Errorf("query service: %w", err)
+		}
+	}
+
+	fmt.Printf("Stopped service %s\n", s.Name)
+	return nil
+}
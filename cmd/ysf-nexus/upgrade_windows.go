@@ -0,0 +1,6 @@
+package main
+
+import "os"
+
+// notifyUpgrade does nothing on Windows, where sockets cannot be handed over
+func notifyUpgrade(c chan<- os.Signal) {}
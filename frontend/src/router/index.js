@@ -5,6 +5,7 @@ import Bridges from '@/views/Bridges.vue'
 import TalkLogs from '@/views/TalkLogs.vue'
 import Settings from '@/views/Settings.vue'
 import Login from '@/views/Login.vue'
+import StationProfile from '@/views/StationProfile.vue'
 
 const routes = [
   {
@@ -27,6 +28,11 @@ const routes = [
     name: 'TalkLogs',
     component: TalkLogs
   },
+  {
+    path: '/callsigns/:callsign',
+    name: 'StationProfile',
+    component: StationProfile
+  },
   {
     path: '/settings',
     name: 'Settings',
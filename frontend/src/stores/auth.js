@@ -6,16 +6,23 @@ export const useAuthStore = defineStore('auth', () => {
   // State
   const token = ref(localStorage.getItem('auth_token') || null)
   const authRequired = ref(false)
+  // Backend (local, proxy or oidc) and the signed-in user for proxy and
+  // single sign-on logins, which use a cookie instead of a stored token
+  const backend = ref('local')
+  const loginUrl = ref(null)
+  const signedIn = ref(false)
+  const user = ref(null)
+  const role = ref(null)
   const isLoading = ref(false)
   const error = ref(null)
 
   // Computed
   const isAuthenticated = computed(() => {
-    return !authRequired.value || !!token.value
+    return !authRequired.value || !!token.value || signedIn.value
   })
 
   const needsAuth = computed(() => {
-    return authRequired.value && !token.value
+    return authRequired.value && !token.value && !signedIn.value
   })
 
   // Actions
@@ -26,6 +33,11 @@ export const useAuthStore = defineStore('auth', () => {
 
       const response = await axios.get('/api/auth/status')
       authRequired.value = response.data.auth_required
+      backend.value = response.data.backend || 'local'
+      loginUrl.value = response.data.login_url || null
+      signedIn.value = !!response.data.authenticated && backend.value !== 'local'
+      user.value = response.data.user || null
+      role.value = response.data.role || null
 
       // If auth is required but we're not authenticated, clear any stale token
       if (authRequired.value && !response.data.authenticated) {
@@ -74,7 +86,7 @@ export const useAuthStore = defineStore('auth', () => {
   const logout = async () => {
     try {
       // Call logout endpoint if we have a token
-      if (token.value) {
+      if (token.value || signedIn.value) {
         await axios.post('/api/auth/logout')
       }
     } catch (err) {
@@ -82,6 +94,7 @@ export const useAuthStore = defineStore('auth', () => {
     } finally {
       // Clear local state regardless of API call success
       token.value = null
+      signedIn.value = false
       localStorage.removeItem('auth_token')
       setupAxiosInterceptor()
     }
@@ -129,6 +142,10 @@ export const useAuthStore = defineStore('auth', () => {
     // State
     token,
     authRequired,
+    backend,
+    loginUrl,
+    user,
+    role,
     isLoading,
     error,
 
@@ -10,7 +10,8 @@ export const useDashboardStore = defineStore('dashboard', () => {
     totalConnections: 0,
     totalPackets: 0,
     bytesReceived: 0,
-    bytesSent: 0
+    bytesSent: 0,
+    doublings: 0
   })
 
   const repeaters = ref([])
@@ -20,8 +21,16 @@ export const useDashboardStore = defineStore('dashboard', () => {
   const loading = ref(false)
   const error = ref(null)
 
+  // Watch-list notifications
+  const notifications = ref([])
+  const watchlist = ref({ session: '', watch: [], global: [], events: [], max_watch: 0 })
+
   // WebSocket connection
   const ws = ref(null)
+  // Sequence number of the last applied broadcast, and the messages received
+  // while a snapshot is being fetched
+  let lastSeq = 0
+  let pendingMessages = null
 
   // Timers
   const talkUpdateTimer = ref(null)
@@ -84,38 +93,63 @@ export const useDashboardStore = defineStore('dashboard', () => {
       console.log('Fetching current talker...')
       const response = await axios.get('/api/current-talker')
       console.log('Current talker response:', response.data)
-      
-      if (response.data.current_talker) {
-        const talker = response.data.current_talker
-        console.log('Setting current talker:', talker)
-        
-        // If it's a different talker, update currentTalker
-        if (!currentTalker.value || 
-            currentTalker.value.callsign !== talker.callsign ||
-            currentTalker.value.address !== talker.address ||
-            currentTalker.value.type !== talker.type) {
-          currentTalker.value = {
-            ...talker,
-            talk_start_time: new Date(Date.now() - (talker.talk_duration * 1000))
-          }
-        } else {
-          // Update existing talker's duration and other properties
-          currentTalker.value = {
-            ...currentTalker.value,
-            ...talker,
-            talk_start_time: currentTalker.value.talk_start_time // Keep original start time
-          }
+      setCurrentTalker(response.data.current_talker)
+      error.value = null
+    } catch (err) {
+      error.value = 'Failed to fetch current talker'
+      console.error('Error fetching current talker:', err)
+    }
+  }
+
+  function setCurrentTalker(talker) {
+    if (talker) {
+      console.log('Setting current talker:', talker)
+
+      // If it's a different talker, update currentTalker
+      if (!currentTalker.value ||
+          currentTalker.value.callsign !== talker.callsign ||
+          currentTalker.value.address !== talker.address ||
+          currentTalker.value.type !== talker.type) {
+        currentTalker.value = {
+          ...talker,
+          talk_start_time: new Date(Date.now() - (talker.talk_duration * 1000))
         }
-        console.log('Current talker set to:', currentTalker.value)
       } else {
-        console.log('No current talker in response, clearing currentTalker')
-        currentTalker.value = null
+        // Update existing talker's duration and other properties
+        currentTalker.value = {
+          ...currentTalker.value,
+          ...talker,
+          talk_start_time: currentTalker.value.talk_start_time // Keep original start time
+        }
       }
+      console.log('Current talker set to:', currentTalker.value)
+    } else {
+      console.log('No current talker in response, clearing currentTalker')
+      currentTalker.value = null
+    }
+  }
 
+  // Load the full state, then apply the WebSocket messages numbered after it.
+  // Messages arriving meanwhile are buffered and replayed once it is applied.
+  async function fetchSnapshot() {
+    pendingMessages = pendingMessages || []
+    try {
+      const response = await axios.get('/api/snapshot')
+      const snapshot = response.data
+      stats.value = { ...stats.value, ...snapshot.stats }
+      repeaters.value = snapshot.repeaters || []
+      setCurrentTalker(snapshot.current_talker)
+      talkLogs.value = snapshot.talk_logs || []
+      lastSeq = snapshot.seq
       error.value = null
     } catch (err) {
-      error.value = 'Failed to fetch current talker'
-      console.error('Error fetching current talker:', err)
+      lastSeq = 0
+      error.value = 'Failed to fetch snapshot'
+      console.error('Error fetching snapshot:', err)
+    } finally {
+      const buffered = pendingMessages
+      pendingMessages = null
+      buffered.forEach(receiveWebSocketMessage)
     }
   }
 
@@ -132,19 +166,22 @@ export const useDashboardStore = defineStore('dashboard', () => {
 
   function connectWebSocket() {
     const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:'
-    const wsUrl = `${protocol}//${window.location.host}/ws`
+    const session = localStorage.getItem('ysf-nexus-session')
+    const query = session ? `?session=${encodeURIComponent(session)}` : ''
+    const wsUrl = `${protocol}//${window.location.host}/ws${query}`
 
     ws.value = new WebSocket(wsUrl)
 
     ws.value.onopen = () => {
       connected.value = true
       console.log('WebSocket connected')
+      fetchSnapshot()
     }
 
     ws.value.onmessage = (event) => {
       try {
         const data = JSON.parse(event.data)
-        handleWebSocketMessage(data)
+        receiveWebSocketMessage(data)
       } catch (err) {
         console.error('Error parsing WebSocket message:', err)
       }
@@ -163,6 +200,28 @@ export const useDashboardStore = defineStore('dashboard', () => {
     }
   }
 
+  // Broadcast messages are numbered: those already in the snapshot are
+  // skipped, and a gap means some were dropped, so the state is reloaded
+  function receiveWebSocketMessage(data) {
+    if (data.seq) {
+      if (pendingMessages) {
+        pendingMessages.push(data)
+        return
+      }
+      if (data.seq <= lastSeq) {
+        return
+      }
+      if (lastSeq && data.seq > lastSeq + 1) {
+        console.warn(`WebSocket messages ${lastSeq + 1}-${data.seq - 1} lost, reloading state`)
+        pendingMessages = [data]
+        fetchSnapshot()
+        return
+      }
+      lastSeq = data.seq
+    }
+    handleWebSocketMessage(data)
+  }
+
   function handleWebSocketMessage(data) {
     switch (data.type) {
       case 'stats_update':
@@ -250,6 +309,18 @@ export const useDashboardStore = defineStore('dashboard', () => {
         }, 100)
         break
 
+      case 'session':
+        watchlist.value = data.data
+        localStorage.setItem('ysf-nexus-session', data.data.session)
+        break
+
+      case 'notification':
+        notifications.value.unshift(data.data)
+        if (notifications.value.length > 50) {
+          notifications.value = notifications.value.slice(0, 50)
+        }
+        break
+
       case 'event':
         // Handle other events as needed
         console.log('Event received:', data.data)
@@ -257,6 +328,18 @@ export const useDashboardStore = defineStore('dashboard', () => {
     }
   }
 
+  async function updateWatchlist(callsigns) {
+    if (!watchlist.value.session) return
+    try {
+      const response = await axios.put(`/api/watchlist/${watchlist.value.session}`, { watch: callsigns })
+      watchlist.value = response.data
+      error.value = null
+    } catch (err) {
+      error.value = 'Failed to update watch list'
+      console.error('Error updating watch list:', err)
+    }
+  }
+
   function disconnectWebSocket() {
     if (ws.value) {
       ws.value.close()
@@ -322,10 +405,7 @@ export const useDashboardStore = defineStore('dashboard', () => {
 
   // Initialize
   function initialize() {
-    fetchStats()
-    fetchRepeaters()
-    fetchCurrentTalker()
-    fetchTalkLogs()
+    // The snapshot is loaded once the WebSocket is open
     connectWebSocket()
     startSlowStatsTimer() // Start with slow refresh when idle
   }
@@ -339,6 +419,8 @@ export const useDashboardStore = defineStore('dashboard', () => {
     connected,
     loading,
     error,
+    notifications,
+    watchlist,
 
     // Computed
     activeTalkers,
@@ -351,6 +433,8 @@ export const useDashboardStore = defineStore('dashboard', () => {
     fetchRepeaters,
     fetchCurrentTalker,
     fetchTalkLogs,
+    fetchSnapshot,
+    updateWatchlist,
     connectWebSocket,
     disconnectWebSocket,
     startTalkUpdateTimer,
@@ -0,0 +1,196 @@
+package testhelpers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/network"
+)
+
+// Scenario files capture the data packets a live reflector sent to a monitor,
+// with their timing, so a field report can be replayed against an in-process
+// reflector as a regression test. See internal/tools/scenario_recorder.
+
+// scenarioPollInterval is how often replay clients re-poll to stay registered
+const scenarioPollInterval = 5 * time.Second
+
+// scenarioLinger delays the unlinks after a replay; the reflector handles
+// packets concurrently and would drop frames still queued behind an unlink
+const scenarioLinger = 500 * time.Millisecond
+
+// Scenario is a recorded packet sequence
+type Scenario struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Source      string           `json:"source,omitempty"` // Reflector the scenario was recorded from
+	Recorded    time.Time        `json:"recorded"`
+	Packets     []ScenarioPacket `json:"packets"`
+}
+
+// ScenarioPacket is one recorded packet
+type ScenarioPacket struct {
+	OffsetMS int64  `json:"offset_ms"` // Milliseconds since the start of the recording
+	Type     string `json:"type"`
+	Gateway  string `json:"gateway"` // Gateway callsign, used to register the replay client
+	Source   string `json:"source,omitempty"`
+	Data     []byte `json:"data"`
+}
+
+// ScenarioRecorder collects packets into a Scenario
+type ScenarioRecorder struct {
+	scenario Scenario
+	start    time.Time
+}
+
+// NewScenarioRecorder starts a recording of packets from source
+func NewScenarioRecorder(name, source string, start time.Time) *ScenarioRecorder {
+	return &ScenarioRecorder{
+		scenario: Scenario{Name: name, Source: source, Recorded: start.UTC()},
+		start:    start,
+	}
+}
+
+// Record adds a packet received at the given time. Only YSFD data packets
+// are replayable; Record reports whether the packet was kept.
+func (r *ScenarioRecorder) Record(data []byte, at time.Time) bool {
+	if len(data) < 34 || string(data[:4]) != network.PacketTypeData {
+		return false
+	}
+	fields, _ := network.ParseDataFields(data, false)
+	r.scenario.Packets = append(r.scenario.Packets, ScenarioPacket{
+		OffsetMS: at.Sub(r.start).Milliseconds(),
+		Type:     network.PacketTypeData,
+		Gateway:  fields.Gateway,
+		Source:   fields.Source,
+		Data:     append([]byte(nil), data...),
+	})
+	return true
+}
+
+// Len returns the number of recorded packets
+func (r *ScenarioRecorder) Len() int {
+	return len(r.scenario.Packets)
+}
+
+// Scenario returns the recording so far
+func (r *ScenarioRecorder) Scenario() Scenario {
+	sc := r.scenario
+	sc.Packets = append([]ScenarioPacket(nil), r.scenario.Packets...)
+	return sc
+}
+
+// SaveScenario writes a scenario file
+func SaveScenario(path string, sc Scenario) error {
+	data, err := json.MarshalIndent(sc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// LoadScenario reads a scenario file
+func LoadScenario(path string) (Scenario, error) {
+	var sc Scenario
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return sc, err
+	}
+	if err := json.Unmarshal(data, &sc); err != nil {
+		return sc, fmt.Errorf("parse scenario %s: %w", path, err)
+	}
+	for i, p := range sc.Packets {
+		if p.Gateway == "" || len(p.Data) < 4 {
+			return sc, fmt.Errorf("scenario %s: packet %d needs a gateway and data", path, i)
+		}
+	}
+	return sc, nil
+}
+
+// ReplayScenario sends the scenario's packets to target with their recorded
+// spacing divided by speed (values <= 0 replay in real time). Each gateway
+// gets its own socket and is registered with a poll first, so the reflector
+// sees the same repeaters as in the field. It returns the number of packets sent.
+func ReplayScenario(ctx context.Context, sc Scenario, target *net.UDPAddr, speed float64) (int, error) {
+	if speed <= 0 {
+		speed = 1
+	}
+
+	clients := make(map[string]*replayClient)
+	defer func() {
+		if len(clients) > 0 {
+			time.Sleep(scenarioLinger)
+		}
+		for gateway, c := range clients {
+			_, _ = c.conn.WriteToUDP(network.CreatePollPacket(network.PacketTypeUnlink, gateway), target)
+			_ = c.conn.Close()
+		}
+	}()
+	for _, p := range sc.Packets {
+		if _, ok := clients[p.Gateway]; ok {
+			continue
+		}
+		conn, err := net.ListenUDP("udp", nil)
+		if err != nil {
+			return 0, fmt.Errorf("bind replay client: %w", err)
+		}
+		c := &replayClient{conn: conn}
+		clients[p.Gateway] = c
+		if err := c.register(ctx, p.Gateway, target); err != nil {
+			return 0, err
+		}
+	}
+
+	start := time.Now()
+	var first int64
+	if len(sc.Packets) > 0 {
+		first = sc.Packets[0].OffsetMS
+	}
+	sent := 0
+	for _, p := range sc.Packets {
+		due := start.Add(time.Duration(float64(time.Duration(p.OffsetMS-first)*time.Millisecond) / speed))
+		select {
+		case <-ctx.Done():
+			return sent, ctx.Err()
+		case <-time.After(time.Until(due)):
+		}
+
+		c := clients[p.Gateway]
+		if time.Since(c.polled) > scenarioPollInterval {
+			_, _ = c.conn.WriteToUDP(network.CreatePollPacket(network.PacketTypePoll, p.Gateway), target)
+			c.polled = time.Now()
+		}
+		if _, err := c.conn.WriteToUDP(p.Data, target); err != nil {
+			return sent, fmt.Errorf("send packet %d: %w", sent, err)
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// replayClient is the socket of one replayed gateway
+type replayClient struct {
+	conn   *net.UDPConn
+	polled time.Time
+}
+
+// register polls target until it answers, for up to two seconds
+func (c *replayClient) register(ctx context.Context, gateway string, target *net.UDPAddr) error {
+	deadline := time.Now().Add(2 * time.Second)
+	buf := make([]byte, 512)
+	for time.Now().Before(deadline) && ctx.Err() == nil {
+		if _, err := c.conn.WriteToUDP(network.CreatePollPacket(network.PacketTypePoll, gateway), target); err != nil {
+			return fmt.Errorf("register %s: %w", gateway, err)
+		}
+		_ = c.conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		if n, _, err := c.conn.ReadFromUDP(buf); err == nil && n >= 4 && string(buf[:4]) == "YSFP" {
+			c.polled = time.Now()
+			_ = c.conn.SetReadDeadline(time.Time{})
+			return nil
+		}
+	}
+	return fmt.Errorf("register %s: no poll reply from %s", gateway, target)
+}
@@ -0,0 +1,145 @@
+package testhelpers
+
+import (
+	"context"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/reflector"
+)
+
+func TestScenarioRecorderRoundTrip(t *testing.T) {
+	start := time.Now()
+	rec := NewScenarioRecorder("roundtrip", "192.0.2.1:42000", start)
+
+	data := make([]byte, 155)
+	copy(data, "YSFDGATEWAY   W1AW      ALL       ")
+	if !rec.Record(data, start.Add(250*time.Millisecond)) {
+		t.Fatalf("expected a data packet to be recorded")
+	}
+	if rec.Record([]byte("YSFPGATEWAY   "), start) {
+		t.Errorf("expected polls to be skipped")
+	}
+
+	path := filepath.Join(t.TempDir(), "roundtrip.json")
+	if err := SaveScenario(path, rec.Scenario()); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	sc, err := LoadScenario(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(sc.Packets) != 1 {
+		t.Fatalf("expected 1 packet, got %d", len(sc.Packets))
+	}
+	if p := sc.Packets[0]; p.OffsetMS != 250 || p.Gateway != "GATEWAY" || p.Source != "W1AW" || len(p.Data) != 155 {
+		t.Errorf("unexpected packet %+v", p)
+	}
+}
+
+// TestReplayScenarios replays every recorded scenario in testdata/scenarios
+// against an in-process reflector and expects a monitor to receive every frame
+func TestReplayScenarios(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping real-time scenario replay in short mode")
+	}
+
+	files, err := filepath.Glob("testdata/scenarios/*.json")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no scenarios found: %v", err)
+	}
+
+	for _, file := range files {
+		file := file
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			sc, err := LoadScenario(file)
+			if err != nil {
+				t.Fatalf("load: %v", err)
+			}
+			target := startReflector(t)
+
+			monitor, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+			if err != nil {
+				t.Fatalf("bind monitor: %v", err)
+			}
+			defer func() { _ = monitor.Close() }()
+			mon := &replayClient{conn: monitor}
+			if err := mon.register(context.Background(), "MONITOR", target); err != nil {
+				t.Fatalf("register monitor: %v", err)
+			}
+
+			// Count frames until a second after the replay ends; the
+			// scenario may be quiet for longer between transmissions
+			replayed := make(chan struct{})
+			received := make(chan int, 1)
+			go func() {
+				buf := make([]byte, 1024)
+				count := 0
+				for {
+					_ = monitor.SetReadDeadline(time.Now().Add(time.Second))
+					n, _, err := monitor.ReadFromUDP(buf)
+					if err != nil {
+						select {
+						case <-replayed:
+							received <- count
+							return
+						default:
+							continue
+						}
+					}
+					if n >= 4 && string(buf[:4]) == "YSFD" {
+						count++
+					}
+				}
+			}()
+
+			sent, err := ReplayScenario(context.Background(), sc, target, 1)
+			close(replayed)
+			if err != nil {
+				t.Fatalf("replay: %v", err)
+			}
+			if got := <-received; got != sent {
+				t.Errorf("monitor received %d of %d frames", got, sent)
+			}
+		})
+	}
+}
+
+// startReflector runs a reflector on a free loopback port until the test ends
+func startReflector(t *testing.T) *net.UDPAddr {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("reserve port: %v", err)
+	}
+	target := conn.LocalAddr().(*net.UDPAddr)
+	_ = conn.Close()
+
+	cfg := &config.Config{Server: config.ServerConfig{
+		Host:              "127.0.0.1",
+		Port:              target.Port,
+		Timeout:           time.Minute,
+		MaxConnections:    20,
+		Name:              "Scenario",
+		TalkMaxDuration:   time.Minute,
+		BridgeTalkTimeout: 3 * time.Second,
+	}}
+	r := reflector.New(cfg, logger.NewTestLogger(io.Discard))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = r.Start(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return target
+}
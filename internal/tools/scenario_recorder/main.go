@@ -0,0 +1,126 @@
+// Command scenario_recorder connects to a live reflector as a monitor and
+// records the data packets it relays into a scenario file, or replays a
+// scenario file against a reflector. Scenario files dropped into
+// internal/testhelpers/testdata/scenarios are replayed by the test suite.
+//
+//	scenario_recorder -host reflector.example.org -out qso.json -duration 10m
+//	scenario_recorder -replay qso.json -host 127.0.0.1 -port 42000
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/internal/testhelpers"
+)
+
+// pollInterval keeps the monitor registered; reflectors time out idle repeaters
+const pollInterval = 5 * time.Second
+
+func main() {
+	host := flag.String("host", "127.0.0.1", "reflector UDP host")
+	port := flag.Int("port", 42000, "reflector UDP port")
+	callsign := flag.String("callsign", "MONITOR", "monitor callsign used to register with the reflector")
+	out := flag.String("out", "scenario.json", "scenario file to write")
+	name := flag.String("name", "", "scenario name (default: output file name)")
+	duration := flag.Duration("duration", 0, "how long to record (0 = until interrupted)")
+	replay := flag.String("replay", "", "replay this scenario file instead of recording")
+	speed := flag.Float64("speed", 1, "replay speed factor")
+	flag.Parse()
+
+	target, err := net.ResolveUDPAddr("udp", net.JoinHostPort(*host, fmt.Sprint(*port)))
+	if err != nil {
+		log.Fatalf("resolve %s:%d: %v", *host, *port, err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	if *duration > 0 {
+		ctx, cancel = context.WithTimeout(ctx, *duration)
+		defer cancel()
+	}
+
+	if *replay != "" {
+		sc, err := testhelpers.LoadScenario(*replay)
+		if err != nil {
+			log.Fatalf("load scenario: %v", err)
+		}
+		log.Printf("replaying %q (%d packets) to %s", sc.Name, len(sc.Packets), target)
+		sent, err := testhelpers.ReplayScenario(ctx, sc, target, *speed)
+		if err != nil {
+			log.Fatalf("replay stopped after %d packets: %v", sent, err)
+		}
+		log.Printf("sent %d packets", sent)
+		return
+	}
+
+	if *name == "" {
+		*name = strings.TrimSuffix(filepath.Base(*out), filepath.Ext(*out))
+	}
+	sc, err := record(ctx, target, *callsign, *name)
+	if err != nil {
+		log.Fatalf("record: %v", err)
+	}
+	if err := testhelpers.SaveScenario(*out, sc); err != nil {
+		log.Fatalf("save scenario: %v", err)
+	}
+	log.Printf("wrote %d packets to %s", len(sc.Packets), *out)
+}
+
+// record registers as a monitor and records data packets until ctx ends
+func record(ctx context.Context, target *net.UDPAddr, callsign, name string) (testhelpers.Scenario, error) {
+	conn, err := net.ListenUDP("udp", nil)
+	if err != nil {
+		return testhelpers.Scenario{}, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	poll := func(packetType string) {
+		data := make([]byte, 14)
+		copy(data[0:4], packetType)
+		copy(data[4:14], fmt.Sprintf("%-10s", callsign))
+		if _, err := conn.WriteToUDP(data, target); err != nil {
+			log.Printf("send %s: %v", packetType, err)
+		}
+	}
+	defer poll("YSFU")
+
+	rec := testhelpers.NewScenarioRecorder(name, target.String(), time.Now())
+	log.Printf("recording from %s as %s", target, callsign)
+
+	poll("YSFP")
+	lastPoll := time.Now()
+	buf := make([]byte, 1024)
+	for ctx.Err() == nil {
+		if time.Since(lastPoll) >= pollInterval {
+			poll("YSFP")
+			lastPoll = time.Now()
+		}
+
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			return rec.Scenario(), err
+		}
+		if !from.IP.Equal(target.IP) || from.Port != target.Port {
+			continue
+		}
+		if rec.Record(buf[:n], time.Now()) && rec.Len()%100 == 1 {
+			log.Printf("recorded %d packets", rec.Len())
+		}
+	}
+	return rec.Scenario(), nil
+}
@@ -0,0 +1,328 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+// State is the state of an alert rule
+type State string
+
+const (
+	StateOK      State = "ok"
+	StatePending State = "pending"
+	StateFiring  State = "firing"
+)
+
+// Rule defines a threshold condition on a metric
+type Rule struct {
+	Name      string
+	Metric    string
+	Target    string // Optional metric target, e.g. a bridge name
+	Operator  string // One of <, <=, >, >=, ==, !=
+	Threshold float64
+	For       time.Duration // How long the condition must hold before firing
+}
+
+// MetricFunc returns the current value of a metric for an optional target
+type MetricFunc func(target string) (float64, error)
+
+// Alert is the current state of a rule
+type Alert struct {
+	Rule          string     `json:"rule"`
+	Metric        string     `json:"metric"`
+	Target        string     `json:"target,omitempty"`
+	Operator      string     `json:"operator"`
+	Threshold     float64    `json:"threshold"`
+	State         State      `json:"state"`
+	Value         float64    `json:"value"`
+	Error         string     `json:"error,omitempty"`
+	PendingSince  *time.Time `json:"pending_since,omitempty"`
+	FiredAt       *time.Time `json:"fired_at,omitempty"`
+	ResolvedAt    *time.Time `json:"resolved_at,omitempty"`
+	LastEvaluated time.Time  `json:"last_evaluated"`
+}
+
+// Manager evaluates alert rules and notifies on state transitions
+type Manager struct {
+	rules    []Rule
+	interval time.Duration
+	events   chan<- repeater.Event
+	webhooks []string
+	email    *Emailer
+	client   *http.Client
+	logger   *logger.Logger
+
+	mu      sync.RWMutex
+	metrics map[string]MetricFunc
+	alerts  map[string]*Alert
+}
+
+// NewManager creates an alert manager that evaluates rules every interval
+func NewManager(rules []Rule, interval time.Duration, events chan<- repeater.Event, log *logger.Logger) *Manager {
+	alerts := make(map[string]*Alert, len(rules))
+	for _, rule := range rules {
+		alerts[rule.Name] = &Alert{
+			Rule:      rule.Name,
+			Metric:    rule.Metric,
+			Target:    rule.Target,
+			Operator:  rule.Operator,
+			Threshold: rule.Threshold,
+			State:     StateOK,
+		}
+	}
+
+	return &Manager{
+		rules:    rules,
+		interval: interval,
+		events:   events,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		logger:   log.WithComponent("alerting"),
+		metrics:  make(map[string]MetricFunc),
+		alerts:   alerts,
+	}
+}
+
+// RegisterMetric makes a metric available to rules
+func (m *Manager) RegisterMetric(name string, fn MetricFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.metrics[name] = fn
+}
+
+// SetWebhooks sets the URLs that receive a JSON POST on every alert transition
+func (m *Manager) SetWebhooks(urls []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.webhooks = urls
+}
+
+// SetEmailer sets the emailer notified on every alert transition
+func (m *Manager) SetEmailer(e *Emailer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.email = e
+}
+
+// Validate checks that every rule references a registered metric
+func (m *Manager) Validate() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, rule := range m.rules {
+		if _, ok := m.metrics[rule.Metric]; !ok {
+			return fmt.Errorf("alert rule %q: unknown metric %q", rule.Name, rule.Metric)
+		}
+	}
+	return nil
+}
+
+// Start evaluates rules every interval until ctx is cancelled
+func (m *Manager) Start(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	m.logger.Info("Alerting started",
+		logger.Int("rules", len(m.rules)),
+		logger.Duration("interval", m.interval))
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.Evaluate(now)
+		}
+	}
+}
+
+// Evaluate checks every rule once and notifies on transitions
+func (m *Manager) Evaluate(now time.Time) {
+	var transitions []Alert
+
+	m.mu.Lock()
+	for _, rule := range m.rules {
+		alert := m.alerts[rule.Name]
+		alert.LastEvaluated = now
+
+		fn, ok := m.metrics[rule.Metric]
+		if !ok {
+			alert.Error = "unknown metric"
+			continue
+		}
+
+		value, err := fn(rule.Target)
+		if err != nil {
+			// Keep the current state; a transient read error is not a recovery
+			alert.Error = err.Error()
+			continue
+		}
+		alert.Error = ""
+		alert.Value = value
+
+		if compare(value, rule.Operator, rule.Threshold) {
+			switch alert.State {
+			case StateOK:
+				alert.State = StatePending
+				since := now
+				alert.PendingSince = &since
+				fallthrough
+			case StatePending:
+				if now.Sub(*alert.PendingSince) >= rule.For {
+					alert.State = StateFiring
+					fired := now
+					alert.FiredAt = &fired
+					alert.ResolvedAt = nil
+					transitions = append(transitions, *alert)
+				}
+			}
+		} else {
+			if alert.State == StateFiring {
+				resolved := now
+				alert.ResolvedAt = &resolved
+				alert.State = StateOK
+				alert.PendingSince = nil
+				transitions = append(transitions, *alert)
+				continue
+			}
+			alert.State = StateOK
+			alert.PendingSince = nil
+		}
+	}
+	webhooks := m.webhooks
+	email := m.email
+	m.mu.Unlock()
+
+	for _, alert := range transitions {
+		m.notify(alert, webhooks, email)
+	}
+}
+
+// Alerts returns the state of all rules, sorted by rule name
+func (m *Manager) Alerts() []Alert {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	alerts := make([]Alert, 0, len(m.alerts))
+	for _, alert := range m.alerts {
+		alerts = append(alerts, *alert)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].Rule < alerts[j].Rule
+	})
+	return alerts
+}
+
+// notify logs the transition, emits an event, posts webhooks and sends email
+func (m *Manager) notify(alert Alert, webhooks []string, email *Emailer) {
+	eventType := repeater.EventAlertResolved
+	if alert.State == StateFiring {
+		eventType = repeater.EventAlertFiring
+		m.logger.Warn("Alert firing",
+			logger.String("rule", alert.Rule),
+			logger.String("metric", alert.Metric),
+			logger.Any("value", alert.Value))
+	} else {
+		m.logger.Info("Alert resolved",
+			logger.String("rule", alert.Rule),
+			logger.String("metric", alert.Metric),
+			logger.Any("value", alert.Value))
+	}
+
+	if m.events != nil {
+		event := repeater.Event{
+			Type:      eventType,
+			Timestamp: alert.LastEvaluated,
+			Data: map[string]interface{}{
+				"alert": alert,
+			},
+		}
+		select {
+		case m.events <- event:
+		default:
+			m.logger.Warn("Event channel full, dropping alert event", logger.String("rule", alert.Rule))
+		}
+	}
+
+	for _, url := range webhooks {
+		go m.postWebhook(url, eventType, alert)
+	}
+
+	if email != nil {
+		go m.sendEmail(email, eventType, alert)
+	}
+}
+
+// sendEmail emails a single alert transition
+func (m *Manager) sendEmail(email *Emailer, eventType string, alert Alert) {
+	title := "Alert firing: " + alert.Rule
+	message := fmt.Sprintf("%s is %v, %s %v.", alert.Metric, alert.Value, alert.Operator, alert.Threshold)
+	if alert.State != StateFiring {
+		title = "Alert resolved: " + alert.Rule
+		message = fmt.Sprintf("%s is back to %v.", alert.Metric, alert.Value)
+	}
+
+	err := email.Notify(eventType+":"+alert.Rule, EmailData{
+		Event:   eventType,
+		Title:   title,
+		Message: message,
+		Alert:   &alert,
+		Time:    alert.LastEvaluated,
+	})
+	if err != nil {
+		m.logger.Warn("Alert email failed", logger.String("rule", alert.Rule), logger.Error(err))
+	}
+}
+
+// postWebhook delivers a single alert transition to a webhook URL
+func (m *Manager) postWebhook(url, eventType string, alert Alert) {
+	body, err := json.Marshal(map[string]interface{}{
+		"type":  eventType,
+		"alert": alert,
+	})
+	if err != nil {
+		m.logger.Error("Failed to marshal alert webhook", logger.Error(err))
+		return
+	}
+
+	resp, err := m.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		m.logger.Warn("Alert webhook failed", logger.String("url", url), logger.Error(err))
+		return
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		m.logger.Warn("Alert webhook rejected",
+			logger.String("url", url),
+			logger.Int("status", resp.StatusCode))
+	}
+}
+
+// compare applies operator to value and threshold
+func compare(value float64, operator string, threshold float64) bool {
+	switch operator {
+	case "<":
+		return value < threshold
+	case "<=":
+		return value <= threshold
+	case ">":
+		return value > threshold
+	case ">=":
+		return value >= threshold
+	case "==":
+		return value == threshold
+	case "!=":
+		return value != threshold
+	}
+	return false
+}
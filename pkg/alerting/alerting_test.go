@@ -0,0 +1,109 @@
+package alerting
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+func TestAlertFiresAfterForAndResolves(t *testing.T) {
+	events := make(chan repeater.Event, 10)
+	rules := []Rule{{Name: "no_repeaters", Metric: "active_repeaters", Operator: "<", Threshold: 1, For: time.Minute}}
+	m := NewManager(rules, time.Second, events, logger.NewTestLogger(os.Stdout))
+
+	value := 0.0
+	m.RegisterMetric("active_repeaters", func(string) (float64, error) { return value, nil })
+	if err := m.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	start := time.Now()
+	m.Evaluate(start)
+	if got := m.Alerts()[0].State; got != StatePending {
+		t.Fatalf("expected pending, got %s", got)
+	}
+
+	m.Evaluate(start.Add(61 * time.Second))
+	if got := m.Alerts()[0].State; got != StateFiring {
+		t.Fatalf("expected firing, got %s", got)
+	}
+	if ev := <-events; ev.Type != repeater.EventAlertFiring {
+		t.Errorf("expected %s event, got %s", repeater.EventAlertFiring, ev.Type)
+	}
+
+	// Staying in breach must not re-notify
+	m.Evaluate(start.Add(90 * time.Second))
+	if len(events) != 0 {
+		t.Errorf("expected no repeat notification while firing")
+	}
+
+	value = 3
+	m.Evaluate(start.Add(120 * time.Second))
+	alert := m.Alerts()[0]
+	if alert.State != StateOK || alert.ResolvedAt == nil {
+		t.Fatalf("expected resolved alert, got %+v", alert)
+	}
+	if ev := <-events; ev.Type != repeater.EventAlertResolved {
+		t.Errorf("expected %s event, got %s", repeater.EventAlertResolved, ev.Type)
+	}
+}
+
+func TestAlertPendingClearsWithoutFiring(t *testing.T) {
+	events := make(chan repeater.Event, 10)
+	rules := []Rule{{Name: "errors", Metric: "packet_error_rate", Operator: ">", Threshold: 5, For: time.Minute}}
+	m := NewManager(rules, time.Second, events, logger.NewTestLogger(os.Stdout))
+
+	value := 10.0
+	m.RegisterMetric("packet_error_rate", func(string) (float64, error) { return value, nil })
+
+	start := time.Now()
+	m.Evaluate(start)
+	value = 1
+	m.Evaluate(start.Add(30 * time.Second))
+
+	if got := m.Alerts()[0].State; got != StateOK {
+		t.Errorf("expected ok, got %s", got)
+	}
+	if len(events) != 0 {
+		t.Errorf("a pending alert that clears must not notify")
+	}
+}
+
+func TestAlertWebhook(t *testing.T) {
+	received := make(chan map[string]interface{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		received <- body
+	}))
+	defer srv.Close()
+
+	rules := []Rule{{Name: "bridge_down", Metric: "bridges_down", Operator: ">", Threshold: 0}}
+	m := NewManager(rules, time.Second, nil, logger.NewTestLogger(os.Stdout))
+	m.RegisterMetric("bridges_down", func(string) (float64, error) { return 1, nil })
+	m.SetWebhooks([]string{srv.URL})
+
+	m.Evaluate(time.Now())
+
+	select {
+	case body := <-received:
+		if body["type"] != repeater.EventAlertFiring {
+			t.Errorf("unexpected webhook type: %v", body["type"])
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("webhook not delivered")
+	}
+}
+
+func TestValidateUnknownMetric(t *testing.T) {
+	m := NewManager([]Rule{{Name: "x", Metric: "nope", Operator: ">"}}, time.Second, nil, logger.NewTestLogger(os.Stdout))
+	if err := m.Validate(); err == nil {
+		t.Errorf("expected error for unknown metric")
+	}
+}
@@ -0,0 +1,241 @@
+package alerting
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// Email event types besides the alert transitions
+const (
+	EmailReflectorStarted = "reflector_started"
+	EmailReflectorStopped = "reflector_stopped"
+)
+
+// TLS modes for the SMTP connection
+const (
+	TLSStartTLS = "starttls" // Upgrade a plain connection with STARTTLS (default)
+	TLSImplicit = "tls"      // Connect over TLS, e.g. port 465
+	TLSNone     = "none"     // Plain text, for local relays only
+)
+
+// emailTimeout bounds a whole SMTP delivery
+const emailTimeout = 30 * time.Second
+
+// DefaultEmailSubject is used when no subject template is configured
+const DefaultEmailSubject = "[{{.Reflector}}] {{.Title}}"
+
+// DefaultEmailBody is used when no body template is configured
+const DefaultEmailBody = `{{.Title}}
+
+{{.Message}}
+{{if .Alert}}
+Rule:      {{.Alert.Rule}}
+Metric:    {{.Alert.Metric}}{{if .Alert.Target}} ({{.Alert.Target}}){{end}}
+Condition: {{.Alert.Operator}} {{.Alert.Threshold}}
+Value:     {{.Alert.Value}}
+{{end}}
+Time: {{.Time.Format "2006-01-02 15:04:05 MST"}}
+`
+
+// EmailOptions configures an Emailer
+type EmailOptions struct {
+	Host        string
+	Port        int
+	Username    string // Optional; enables PLAIN auth
+	Password    string
+	From        string
+	To          []string
+	TLS         string        // TLSStartTLS, TLSImplicit or TLSNone
+	Subject     string        // text/template; empty uses DefaultEmailSubject
+	Body        string        // text/template; empty uses DefaultEmailBody
+	MinInterval time.Duration // Minimum time between emails for the same event (0 = no limit)
+	Reflector   string        // Reflector name available to templates
+}
+
+// EmailData is the data passed to the subject and body templates
+type EmailData struct {
+	Reflector string
+	Event     string // alert_firing, alert_resolved, reflector_started or reflector_stopped
+	Title     string
+	Message   string
+	Alert     *Alert // Set for alert transitions
+	Time      time.Time
+}
+
+// Emailer sends notification emails over SMTP, at most one per event key
+// every MinInterval
+type Emailer struct {
+	opts    EmailOptions
+	subject *template.Template
+	body    *template.Template
+	logger  *logger.Logger
+
+	// send delivers a rendered message; replaced in tests
+	send func(msg []byte) error
+
+	mu         sync.Mutex
+	lastSent   map[string]time.Time
+	suppressed uint64
+}
+
+// NewEmailer creates an emailer, parsing its templates
+func NewEmailer(opts EmailOptions, log *logger.Logger) (*Emailer, error) {
+	if opts.Subject == "" {
+		opts.Subject = DefaultEmailSubject
+	}
+	if opts.Body == "" {
+		opts.Body = DefaultEmailBody
+	}
+	if opts.TLS == "" {
+		opts.TLS = TLSStartTLS
+	}
+
+	subject, err := template.New("subject").Parse(opts.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subject template: %w", err)
+	}
+	body, err := template.New("body").Parse(opts.Body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid body template: %w", err)
+	}
+
+	e := &Emailer{
+		opts:     opts,
+		subject:  subject,
+		body:     body,
+		logger:   log.WithComponent("email"),
+		lastSent: make(map[string]time.Time),
+	}
+	e.send = e.deliver
+	return e, nil
+}
+
+// Notify renders and sends an email unless one was sent for key within the
+// minimum interval. It blocks until the SMTP delivery finishes.
+func (e *Emailer) Notify(key string, data EmailData) error {
+	if data.Time.IsZero() {
+		data.Time = time.Now()
+	}
+	data.Reflector = e.opts.Reflector
+
+	e.mu.Lock()
+	if last, ok := e.lastSent[key]; ok && e.opts.MinInterval > 0 && data.Time.Sub(last) < e.opts.MinInterval {
+		e.suppressed++
+		e.mu.Unlock()
+		e.logger.Debug("Email rate limited", logger.String("event", key))
+		return nil
+	}
+	e.lastSent[key] = data.Time
+	e.mu.Unlock()
+
+	msg, err := e.render(data)
+	if err != nil {
+		return err
+	}
+	if err := e.send(msg); err != nil {
+		return fmt.Errorf("send email: %w", err)
+	}
+	e.logger.Info("Notification email sent",
+		logger.String("event", data.Event),
+		logger.Int("recipients", len(e.opts.To)))
+	return nil
+}
+
+// Suppressed returns how many emails the rate limit has dropped
+func (e *Emailer) Suppressed() uint64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.suppressed
+}
+
+// render builds the RFC 5322 message for data
+func (e *Emailer) render(data EmailData) ([]byte, error) {
+	var subject, body bytes.Buffer
+	if err := e.subject.Execute(&subject, data); err != nil {
+		return nil, fmt.Errorf("render subject: %w", err)
+	}
+	if err := e.body.Execute(&body, data); err != nil {
+		return nil, fmt.Errorf("render body: %w", err)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", e.opts.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.opts.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", strings.TrimSpace(subject.String())))
+	fmt.Fprintf(&msg, "Date: %s\r\n", data.Time.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(strings.ReplaceAll(body.String(), "\r\n", "\n"), "\n", "\r\n"))
+	return msg.Bytes(), nil
+}
+
+// deliver sends msg to the configured SMTP server
+func (e *Emailer) deliver(msg []byte) error {
+	addr := net.JoinHostPort(e.opts.Host, strconv.Itoa(e.opts.Port))
+	tlsConfig := &tls.Config{ServerName: e.opts.Host}
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+
+	var conn net.Conn
+	var err error
+	if e.opts.TLS == TLSImplicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	_ = conn.SetDeadline(time.Now().Add(emailTimeout))
+
+	c, err := smtp.NewClient(conn, e.opts.Host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer func() { _ = c.Close() }()
+
+	if e.opts.TLS == TLSStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("server %s does not support STARTTLS", addr)
+		}
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if e.opts.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", e.opts.Username, e.opts.Password, e.opts.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(e.opts.From); err != nil {
+		return err
+	}
+	for _, to := range e.opts.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
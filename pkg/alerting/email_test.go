@@ -0,0 +1,151 @@
+package alerting
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+// fakeSMTP accepts SMTP sessions and delivers each message body on the
+// returned channel
+func fakeSMTP(t *testing.T) (string, <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	messages := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer func() { _ = conn.Close() }()
+				r := bufio.NewReader(conn)
+				reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+				reply("220 localhost ESMTP")
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+					case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+						reply("250 localhost")
+					case cmd == "DATA":
+						reply("354 go ahead")
+						var msg strings.Builder
+						for {
+							l, err := r.ReadString('\n')
+							if err != nil {
+								return
+							}
+							if l == ".\r\n" {
+								break
+							}
+							msg.WriteString(l)
+						}
+						messages <- msg.String()
+						reply("250 queued")
+					case cmd == "QUIT":
+						reply("221 bye")
+						return
+					default:
+						reply("250 ok")
+					}
+				}
+			}(conn)
+		}
+	}()
+	return ln.Addr().String(), messages
+}
+
+func TestAlertEmail(t *testing.T) {
+	addr, messages := fakeSMTP(t)
+	host, port, _ := net.SplitHostPort(addr)
+	portNum, _ := net.LookupPort("tcp", port)
+
+	emailer, err := NewEmailer(EmailOptions{
+		Host: host, Port: portNum, TLS: TLSNone,
+		From: "nexus@example.org", To: []string{"sysop@example.org"},
+		Reflector: "Test Reflector",
+	}, logger.NewTestLogger(os.Stdout))
+	if err != nil {
+		t.Fatalf("NewEmailer: %v", err)
+	}
+
+	rules := []Rule{{Name: "bridge_down", Metric: "bridges_down", Operator: ">", Threshold: 0}}
+	m := NewManager(rules, time.Second, nil, logger.NewTestLogger(os.Stdout))
+	m.RegisterMetric("bridges_down", func(string) (float64, error) { return 1, nil })
+	m.SetEmailer(emailer)
+
+	m.Evaluate(time.Now())
+
+	select {
+	case msg := <-messages:
+		for _, want := range []string{
+			"Subject: [Test Reflector] Alert firing: bridge_down",
+			"To: sysop@example.org",
+			"Rule:      bridge_down",
+		} {
+			if !strings.Contains(msg, want) {
+				t.Errorf("email missing %q:\n%s", want, msg)
+			}
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("email not delivered")
+	}
+}
+
+func TestEmailRateLimit(t *testing.T) {
+	emailer, err := NewEmailer(EmailOptions{
+		From: "nexus@example.org", To: []string{"sysop@example.org"}, MinInterval: 15 * time.Minute,
+		Subject: "{{.Event}}",
+	}, logger.NewTestLogger(os.Stdout))
+	if err != nil {
+		t.Fatalf("NewEmailer: %v", err)
+	}
+	var sent []string
+	emailer.send = func(msg []byte) error {
+		sent = append(sent, string(msg))
+		return nil
+	}
+
+	start := time.Now()
+	notify := func(key string, at time.Time) {
+		t.Helper()
+		if err := emailer.Notify(key, EmailData{Event: repeater.EventAlertFiring, Time: at}); err != nil {
+			t.Fatalf("Notify: %v", err)
+		}
+	}
+	notify("alert_firing:bridge_down", start)
+	notify("alert_firing:bridge_down", start.Add(time.Minute))
+	notify("alert_firing:no_repeaters", start.Add(time.Minute))
+	notify("alert_firing:bridge_down", start.Add(16*time.Minute))
+
+	if len(sent) != 3 {
+		t.Fatalf("expected 3 emails, got %d", len(sent))
+	}
+	if got := emailer.Suppressed(); got != 1 {
+		t.Errorf("expected 1 suppressed email, got %d", got)
+	}
+	if !strings.Contains(sent[0], "Subject: "+repeater.EventAlertFiring) {
+		t.Errorf("custom subject template not applied:\n%s", sent[0])
+	}
+}
+
+func TestEmailInvalidTemplate(t *testing.T) {
+	if _, err := NewEmailer(EmailOptions{Body: "{{.Title"}, logger.NewTestLogger(os.Stdout)); err == nil {
+		t.Fatal("expected invalid body template to be rejected")
+	}
+}
@@ -1,15 +1,19 @@
 package bridge
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"math"
+	"math/rand"
 	"net"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/dbehnke/ysf-nexus/pkg/config"
 	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
 )
 
 // Bridge represents a connection to another YSF reflector
@@ -17,6 +21,8 @@ type Bridge struct {
 	config config.BridgeConfig
 	logger *logger.Logger
 	server NetworkServer
+	events chan<- repeater.Event
+	clock  Clock
 
 	// Connection state
 	mu             sync.RWMutex
@@ -31,18 +37,56 @@ type Bridge struct {
 	retryCount     int
 	maxRetries     int
 	baseRetryDelay time.Duration
+	// jitter randomizes retry delays so bridges don't reconnect in lockstep
+	jitter *rand.Rand
 
 	// Statistics
 	packetsRx uint64
 	packetsTx uint64
 	bytesRx   uint64
 	bytesTx   uint64
+	// connections counts successful connects over the bridge's lifetime
+	connections uint64
+	// usage accumulates the counters across restarts; nil when not managed
+	usage *usageTracker
+	// lastRxAt is when a packet was last received from the remote
+	lastRxAt *time.Time
+	// lastTalker is the callsign of the most recent transmission received over the bridge
+	lastTalker     string
+	lastTalkerTime *time.Time
+	// Frame loss of transmissions received over the bridge: the last one's
+	// loss and totals over the bridge's lifetime
+	lastTalkerLoss float64
+	streamFrames   uint64
+	streamMissing  uint64
+	// flap dampens link state events while the bridge keeps connecting and dropping
+	flap flapDamper
+	// The transmission last forwarded to the remote, the drain in progress
+	// if any, and whether a finished drain is stopping the bridge (see drain.go)
+	lastTxCallsign string
+	lastTxAt       time.Time
+	drain          *drainState
+	drainStopping  bool
+
+	// Temporary bridges are created at runtime and removed at expiresAt
+	temporary bool
+	expiresAt *time.Time
 
 	// Health checking
 	lastPacketTime time.Time
 	healthTicker   *time.Ticker
 	lastPingTime   time.Time
 	awaitingPong   bool
+	// awaitingSince is when the oldest unanswered ping was sent
+	awaitingSince time.Time
+	// lastPollReply is when the remote last answered a poll
+	lastPollReply time.Time
+
+	// Keepalive protocol variant (see protocol.go) and, with auto, the
+	// version queries sent and poll replies seen while detecting it
+	protocol      string
+	versionProbes int
+	detectPolls   int
 }
 
 // NewBridge creates a new bridge instance
@@ -54,14 +98,18 @@ func NewBridge(cfg config.BridgeConfig, server NetworkServer, logger *logger.Log
 		retryDelay = 30 * time.Second
 	}
 
+	clk := Clock(&RealClock{})
 	return &Bridge{
 		config:         cfg,
 		logger:         logger,
 		server:         server,
+		clock:          clk,
 		state:          StateDisconnected,
 		maxRetries:     maxRetries,
 		baseRetryDelay: retryDelay,
-		lastPacketTime: time.Now(),
+		jitter:         rand.New(rand.NewSource(clk.Now().UnixNano())),
+		lastPacketTime: clk.Now(),
+		protocol:       normalizeProtocol(cfg.Protocol),
 	}
 }
 
@@ -96,12 +144,12 @@ func (b *Bridge) RunPermanent(ctx context.Context) {
 				select {
 				case <-ctx.Done():
 					return
-				case <-time.After(delay):
+				case <-b.clock.After(delay):
 					continue
 				}
 			} else {
 				// Connected successfully, reset retry count
-				b.retryCount = 0
+				b.resetRetries()
 				b.maintainConnection(ctx)
 			}
 		}
@@ -111,7 +159,6 @@ func (b *Bridge) RunPermanent(ctx context.Context) {
 // RunScheduled runs a bridge connection for a scheduled duration
 func (b *Bridge) RunScheduled(ctx context.Context, duration time.Duration) {
 	b.logger.Info("Starting scheduled bridge", logger.Duration("duration", duration))
-	b.setState(StateScheduled)
 
 	// Create a timeout context for the scheduled duration
 	scheduleCtx, cancel := context.WithTimeout(ctx, duration)
@@ -121,13 +168,20 @@ func (b *Bridge) RunScheduled(ctx context.Context, duration time.Duration) {
 		logger.String("bridge", b.config.Name),
 		logger.Duration("duration", duration))
 
+	b.runWindow(scheduleCtx)
+}
+
+// runWindow keeps a scheduled bridge linked until ctx ends, reconnecting with
+// retries, then disconnects
+func (b *Bridge) runWindow(scheduleCtx context.Context) {
+	b.setState(StateScheduled)
+
 	// Try to connect with retries during the scheduled window
 	for {
 		select {
 		case <-scheduleCtx.Done():
 			b.logger.Info("Scheduled bridge window ended - disconnecting",
-				logger.String("bridge", b.config.Name),
-				logger.Duration("duration", duration))
+				logger.String("bridge", b.config.Name))
 			b.disconnect()
 			return
 		default:
@@ -148,12 +202,12 @@ func (b *Bridge) RunScheduled(ctx context.Context, duration time.Duration) {
 				select {
 				case <-scheduleCtx.Done():
 					return
-				case <-time.After(delay):
+				case <-b.clock.After(delay):
 					continue
 				}
 			} else {
 				// Connected successfully, reset retry count and maintain connection
-				b.retryCount = 0
+				b.resetRetries()
 				b.maintainConnection(scheduleCtx)
 				// Connection ended (dropped or stopped) — continue attempting to reconnect
 				// until the scheduled window (scheduleCtx) expires.
@@ -167,16 +221,12 @@ func (b *Bridge) RunScheduled(ctx context.Context, duration time.Duration) {
 func (b *Bridge) connect(ctx context.Context) error {
 	b.setState(StateConnecting)
 
-	// Resolve the remote address
-	addr, err := net.ResolveUDPAddr("udp", fmt.Sprintf("%s:%d", b.config.Host, b.config.Port))
+	// Resolve the remote address on every connect so a changed dynamic IP is picked up
+	addr, err := b.resolveRemote()
 	if err != nil {
-		return fmt.Errorf("failed to resolve bridge address: %w", err)
+		return err
 	}
 
-	b.mu.Lock()
-	b.remoteAddr = addr
-	b.mu.Unlock()
-
 	// Send initial connection packet (YSF handshake)
 	if err := b.sendHandshake(); err != nil {
 		return fmt.Errorf("failed to send handshake: %w", err)
@@ -186,25 +236,82 @@ func (b *Bridge) connect(ctx context.Context) error {
 	// For now, we'll consider the connection established after sending handshake
 	// In a full implementation, you'd wait for a response packet
 
-	now := time.Now()
+	now := b.clock.Now()
 	b.mu.Lock()
 	b.state = StateConnected
 	b.connectedAt = &now
 	b.disconnectedAt = nil
 	b.lastError = ""
+	b.drainStopping = false
+	b.connections++
+	b.usage.connected(b.config.Name, now)
+	b.stateChangedLocked(repeater.Event{
+		Type:      repeater.EventBridgeConnected,
+		Bridge:    b.config.Name,
+		Timestamp: now,
+		Data:      map[string]interface{}{"remote": addr.String()},
+	})
 	b.lastPacketTime = now
+	b.lastPollReply = now
+	b.sendVersionProbeLocked()
+	polls := b.pollIntervalLocked() > 0
 	b.mu.Unlock()
 
 	b.logger.Info("Bridge connected", logger.String("remote", addr.String()))
 
-	// Start health checking if configured
-	if b.config.HealthCheck > 0 {
+	// Start health checking if configured or required by the protocol
+	if polls {
 		b.startHealthCheck(ctx)
 	}
 
 	return nil
 }
 
+// resolveRemote resolves the configured host and records the new remote address,
+// emitting an event when it differs from the previous one
+func (b *Bridge) resolveRemote() (*net.UDPAddr, error) {
+	addr, err := net.ResolveUDPAddr("udp", fmt.Sprintf("%s:%d", b.config.Host, b.config.Port))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve bridge address: %w", err)
+	}
+
+	b.mu.Lock()
+	previous := b.remoteAddr
+	b.remoteAddr = addr
+	events := b.events
+	b.mu.Unlock()
+
+	if previous == nil || previous.String() == addr.String() {
+		return addr, nil
+	}
+
+	b.logger.Warn("Bridge remote address changed",
+		logger.String("bridge", b.config.Name),
+		logger.String("host", b.config.Host),
+		logger.String("old", previous.String()),
+		logger.String("new", addr.String()))
+
+	if events != nil {
+		event := repeater.Event{
+			Type:      repeater.EventBridgeAddressChanged,
+			Bridge:    b.config.Name,
+			Timestamp: b.clock.Now(),
+			Data: map[string]interface{}{
+				"host":        b.config.Host,
+				"old_address": previous.String(),
+				"new_address": addr.String(),
+			},
+		}
+		select {
+		case events <- event:
+		default:
+			b.logger.Warn("Event channel full, dropping bridge address event")
+		}
+	}
+
+	return addr, nil
+}
+
 // maintainConnection maintains the bridge connection and handles packets
 func (b *Bridge) maintainConnection(ctx context.Context) {
 	// Send periodic keep-alive packets
@@ -220,6 +327,12 @@ func (b *Bridge) maintainConnection(ctx context.Context) {
 			b.disconnect()
 			return
 		case <-keepAliveTicker.C:
+			b.mu.RLock()
+			statusKeepalive := b.profileLocked().statusKeepalive
+			b.mu.RUnlock()
+			if !statusKeepalive {
+				break
+			}
 			if err := b.sendKeepAlive(); err != nil {
 				b.logger.Error("Failed to send keep-alive", logger.Error(err))
 				b.setConnectionError("keep-alive failed: " + err.Error())
@@ -229,9 +342,12 @@ func (b *Bridge) maintainConnection(ctx context.Context) {
 		}
 
 		// Check if connection is healthy
-		if b.config.HealthCheck > 0 && time.Since(b.lastPacketTime) > b.config.HealthCheck*2 {
+		b.mu.RLock()
+		lastAlive := b.lastAliveLocked()
+		b.mu.RUnlock()
+		if b.config.HealthCheck > 0 && b.clock.Now().Sub(lastAlive) > b.config.HealthCheck*2 {
 			b.logger.Warn("Bridge connection unhealthy - no packets received",
-				logger.Any("last_packet", b.lastPacketTime))
+				logger.Any("last_packet", lastAlive))
 			b.setConnectionError("connection timeout - no packets received")
 			b.disconnect()
 			return
@@ -244,8 +360,28 @@ func (b *Bridge) disconnect() {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
+	now := b.clock.Now()
 	if b.state == StateConnected {
 		b.logger.Info("Disconnecting bridge")
+		b.usage.disconnected(b.config.Name, now, b.lastError)
+		event := repeater.Event{
+			Type:      repeater.EventBridgeDisconnected,
+			Bridge:    b.config.Name,
+			Timestamp: now,
+		}
+		if b.connectedAt != nil {
+			event.Duration = now.Sub(*b.connectedAt)
+		}
+		if b.lastError != "" {
+			event.Data = map[string]interface{}{"error": b.lastError}
+		}
+		if b.drainStopping {
+			if event.Data == nil {
+				event.Data = map[string]interface{}{}
+			}
+			event.Data["drained"] = true
+		}
+		b.stateChangedLocked(event)
 
 		// Send disconnect packet using sendDisconnectLocked to avoid double-lock
 		if b.remoteAddr != nil {
@@ -255,10 +391,11 @@ func (b *Bridge) disconnect() {
 		}
 	}
 
-	now := time.Now()
 	b.state = StateDisconnected
 	b.disconnectedAt = &now
 	b.connectedAt = nil
+	b.drain = nil
+	b.drainStopping = false
 
 	if b.healthTicker != nil {
 		b.healthTicker.Stop()
@@ -271,7 +408,7 @@ func (b *Bridge) handleConnectionFailure() {
 	b.mu.Lock()
 	b.retryCount++
 	b.state = StateFailed
-	now := time.Now()
+	now := b.clock.Now()
 	b.disconnectedAt = &now
 	b.mu.Unlock()
 
@@ -282,6 +419,13 @@ func (b *Bridge) handleConnectionFailure() {
 	}
 }
 
+// resetRetries clears the retry count after a successful connect
+func (b *Bridge) resetRetries() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.retryCount = 0
+}
+
 // calculateRetryDelay calculates the delay before next retry using exponential backoff
 func (b *Bridge) calculateRetryDelay() time.Duration {
 	// Exponential backoff: baseDelay * 2^retryCount with jitter
@@ -293,38 +437,40 @@ func (b *Bridge) calculateRetryDelay() time.Duration {
 		delay = maxDelay
 	}
 
-	// Add jitter (±25%) to avoid thundering herd
-	jitter := float64(delay) * 0.25
-	jitterDelay := time.Duration(float64(delay) + (jitter * (2*float64(b.retryCount%2) - 1)))
+	// Add random jitter (±25%) to avoid thundering herd
+	jitter := float64(delay) * 0.25 * (2*b.jitter.Float64() - 1)
 
-	return jitterDelay
+	return delay + time.Duration(jitter)
 }
 
 // startHealthCheck starts periodic health checking like a proper YSF repeater
 func (b *Bridge) startHealthCheck(ctx context.Context) {
-	if b.config.HealthCheck <= 0 {
-		return
-	}
-
-	// Use a ticker with a shorter interval to check if we need to send pings
-	b.healthTicker = time.NewTicker(5 * time.Second)
+	// Use a ticker with a shorter interval than the poll cadence to check if
+	// we need to send pings
+	ticker := time.NewTicker(time.Second)
+	b.mu.Lock()
+	b.healthTicker = ticker
+	b.mu.Unlock()
 
 	go func() {
-		defer b.healthTicker.Stop()
+		defer ticker.Stop()
 
 		// Send initial ping
 		if err := b.sendPing(); err != nil {
 			b.logger.Warn("Failed to send initial ping", logger.Error(err))
 		} else {
-			b.lastPingTime = time.Now()
+			b.mu.Lock()
+			b.lastPingTime = b.clock.Now()
 			b.awaitingPong = true
+			b.awaitingSince = b.lastPingTime
+			b.mu.Unlock()
 		}
 
 		for {
 			select {
 			case <-ctx.Done():
 				return
-			case <-b.healthTicker.C:
+			case <-ticker.C:
 				b.checkPingResponse(ctx)
 			}
 		}
@@ -333,30 +479,52 @@ func (b *Bridge) startHealthCheck(ctx context.Context) {
 
 // checkPingResponse checks if we need to send a ping or handle timeout
 func (b *Bridge) checkPingResponse(ctx context.Context) {
+	if b.checkPingTimeout() {
+		// The remote may have moved to a new dynamic IP; re-resolve before the next ping
+		if _, err := b.resolveRemote(); err != nil {
+			b.logger.Warn("Failed to re-resolve bridge host", logger.Error(err))
+		}
+	}
+
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	now := time.Now()
-
-	// If we're awaiting a pong and it's been too long, consider connection lost
-	if b.awaitingPong && now.Sub(b.lastPingTime) > b.config.HealthCheck {
-		b.logger.Warn("Bridge ping timeout - no response received",
-			logger.Duration("elapsed", now.Sub(b.lastPingTime)))
-		b.awaitingPong = false
-		// Connection might be lost, will retry on next health check cycle
-	}
+	now := b.clock.Now()
 
-	// If we're not awaiting a pong and enough time has passed, send a new ping
-	if !b.awaitingPong && now.Sub(b.lastPingTime) >= b.config.HealthCheck {
+	// Send a new ping once the interval has passed; polls at a protocol's
+	// cadence keep the link alive, so they go out even while a ping is unanswered
+	polling := b.profileLocked().pollInterval > 0
+	if (!b.awaitingPong || polling) && now.Sub(b.lastPingTime) >= b.pollIntervalLocked() {
 		if err := b.sendPingLocked(); err != nil {
 			b.logger.Warn("Failed to send ping", logger.Error(err))
 		} else {
 			b.lastPingTime = now
-			b.awaitingPong = true
+			if !b.awaitingPong {
+				b.awaitingPong = true
+				b.awaitingSince = now
+			}
+			b.sendVersionProbeLocked()
 		}
 	}
 }
 
+// checkPingTimeout reports whether an outstanding ping went unanswered, and clears it
+func (b *Bridge) checkPingTimeout() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	elapsed := b.clock.Now().Sub(b.awaitingSince)
+	if !b.awaitingPong || b.config.HealthCheck <= 0 || elapsed <= b.config.HealthCheck {
+		return false
+	}
+
+	b.logger.Warn("Bridge ping timeout - no response received",
+		logger.Duration("elapsed", elapsed))
+	b.awaitingPong = false
+	// Connection might be lost, will retry on next health check cycle
+	return true
+}
+
 // sendPingLocked sends a ping packet (assumes mutex is already locked)
 func (b *Bridge) sendPingLocked() error {
 	ping := b.createPingPacket()
@@ -401,6 +569,7 @@ func (b *Bridge) sendPacket(data []byte) error {
 		b.packetsTx++
 		b.bytesTx += uint64(len(data))
 		b.mu.Unlock()
+		b.usage.addTx(b.config.Name, uint64(len(data)))
 	}
 
 	return err
@@ -415,6 +584,7 @@ func (b *Bridge) sendPacketLocked(data []byte) error {
 	if err == nil {
 		b.packetsTx++
 		b.bytesTx += uint64(len(data))
+		b.usage.addTx(b.config.Name, uint64(len(data)))
 	}
 
 	return err
@@ -473,9 +643,23 @@ func (b *Bridge) GetStatus() BridgeStatus {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
+	var flappingSince *time.Time
+	if b.flap.flapping {
+		since := b.flap.since
+		flappingSince = &since
+	}
+	state := b.state
+	var drainingSince *time.Time
+	var drainStream string
+	if b.drain != nil {
+		state = StateDraining
+		since := b.drain.since
+		drainingSince = &since
+		drainStream = b.drain.stream
+	}
 	return BridgeStatus{
 		Name:           b.config.Name,
-		State:          b.state,
+		State:          state,
 		ConnectedAt:    b.connectedAt,
 		DisconnectedAt: b.disconnectedAt,
 		NextSchedule:   b.nextSchedule,
@@ -486,6 +670,24 @@ func (b *Bridge) GetStatus() BridgeStatus {
 		PacketsTx:      b.packetsTx,
 		BytesRx:        b.bytesRx,
 		BytesTx:        b.bytesTx,
+		Connections:    b.connections,
+		Temporary:      b.temporary,
+		RxOnly:         b.config.RxOnly,
+		Callsigns:      b.config.Callsigns,
+		ExpiresAt:      b.expiresAt,
+		LastTalker:     b.lastTalker,
+		LastTalkerTime: b.lastTalkerTime,
+		LastTalkerLoss: b.lastTalkerLoss,
+		StreamFrames:   b.streamFrames,
+		StreamLoss:     lossPercent(b.streamFrames, b.streamMissing),
+		LastRxTime:     b.lastRxAt,
+		Protocol:       b.protocol,
+		Flapping:       b.flap.flapping,
+		FlappingSince:  flappingSince,
+		Transitions:    b.flap.recent(b.clock.Now()),
+		Suppressed:     b.flap.suppressed,
+		DrainingSince:  drainingSince,
+		DrainStream:    drainStream,
 	}
 }
 
@@ -500,6 +702,14 @@ func (b *Bridge) IsConnectedTo(addr *net.UDPAddr) bool {
 	return b.remoteAddr != nil && b.remoteAddr.String() == addr.String()
 }
 
+// setClock replaces the bridge's time source; it must be called before the bridge runs
+func (b *Bridge) setClock(clk Clock) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.clock = clk
+	b.lastPacketTime = clk.Now()
+}
+
 func (b *Bridge) setState(state BridgeState) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
@@ -523,7 +733,38 @@ func (b *Bridge) IncrementRxStats(bytes uint64) {
 	defer b.mu.Unlock()
 	b.packetsRx++
 	b.bytesRx += bytes
-	b.lastPacketTime = time.Now()
+	b.usage.addRx(b.config.Name, bytes)
+	now := b.clock.Now()
+	b.lastPacketTime = now
+	b.lastRxAt = &now
+}
+
+// RecordTalker records callsign as the most recent talker heard over the bridge
+func (b *Bridge) RecordTalker(callsign string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.clock.Now()
+	b.lastTalker = callsign
+	b.lastTalkerTime = &now
+}
+
+// RecordTransmission accounts for the frame loss of a finished transmission
+// received over the bridge
+func (b *Bridge) RecordTransmission(quality repeater.QualityReport) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	missing := quality.Missing()
+	b.lastTalkerLoss = lossPercent(uint64(quality.Frames), uint64(missing))
+	b.streamFrames += uint64(quality.Frames)
+	b.streamMissing += uint64(missing)
+}
+
+// lossPercent returns the share of expected frames that were missing
+func lossPercent(received, missing uint64) float64 {
+	if received+missing == 0 {
+		return 0
+	}
+	return float64(missing) / float64(received+missing) * 100
 }
 
 // OnPacketReceived handles incoming packets for ping response detection
@@ -531,8 +772,15 @@ func (b *Bridge) OnPacketReceived(data []byte) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	// Check if this is a response to our ping (any packet indicates the bridge is alive)
-	if b.awaitingPong {
+	b.observeProtocolLocked(data)
+	isPollReply := bytes.HasPrefix(data, []byte("YSFP"))
+	if isPollReply {
+		b.lastPollReply = b.clock.Now()
+	}
+
+	// Check if this is a response to our ping: a poll reply, or with
+	// protocols that allow it any packet
+	if b.awaitingPong && (isPollReply || !b.profileLocked().pollReply) {
 		b.awaitingPong = false
 		b.logger.Debug("Received response from bridge",
 			logger.String("bridge", b.config.Name),
@@ -549,6 +797,35 @@ func (b *Bridge) ForwardPacket(data []byte) error {
 	return b.sendPacket(data)
 }
 
+// IsRxOnly returns true if local traffic must not be forwarded to this bridge
+func (b *Bridge) IsRxOnly() bool {
+	return b.config.RxOnly
+}
+
+// Routes reports whether local traffic from callsign is forwarded to this
+// bridge. A bridge without configured callsigns takes everyone's traffic.
+func (b *Bridge) Routes(callsign string) bool {
+	if len(b.config.Callsigns) == 0 {
+		return true
+	}
+	key := routeKey(callsign)
+	for _, routed := range b.config.Callsigns {
+		if routeKey(routed) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// routeKey reduces a callsign to its base for routing, e.g. "k5abc-1" -> "K5ABC"
+func routeKey(callsign string) string {
+	key := strings.ToUpper(strings.TrimSpace(callsign))
+	if i := strings.IndexAny(key, "-/"); i >= 0 {
+		key = key[:i]
+	}
+	return key
+}
+
 // IsConnected returns true if the bridge is currently connected
 func (b *Bridge) IsConnected() bool {
 	b.mu.RLock()
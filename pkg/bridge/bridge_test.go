@@ -9,6 +9,7 @@ import (
 
 	"github.com/dbehnke/ysf-nexus/pkg/config"
 	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
 )
 
 // MockNetworkServer implements NetworkServer for testing
@@ -286,3 +287,256 @@ func TestBridge_LongCallsignTruncation(t *testing.T) {
 
 	t.Logf("Long callsign correctly truncated: '%s'", callsign)
 }
+
+func TestBridgeManager_TemporaryBridge(t *testing.T) {
+	logger := logger.NewTestLogger(os.Stdout)
+	manager := NewManager(nil, &MockNetworkServer{}, logger)
+	defer manager.Stop()
+
+	cfg := config.BridgeConfig{Name: "temp-link", Host: "localhost", Port: 4200}
+	status, err := manager.StartTemporary(cfg, 200*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Failed to start temporary bridge: %v", err)
+	}
+	if !status.Temporary || status.ExpiresAt == nil {
+		t.Errorf("Expected temporary status with expiry, got %+v", status)
+	}
+
+	if _, err := manager.StartTemporary(cfg, time.Minute); err == nil {
+		t.Errorf("Expected error for duplicate bridge name")
+	}
+	if _, err := manager.StartTemporary(config.BridgeConfig{Name: "x", Host: "localhost", Port: 4200}, 48*time.Hour); err == nil {
+		t.Errorf("Expected error for duration above the maximum")
+	}
+
+	// The bridge is removed once it expires
+	deadline := time.Now().Add(2 * time.Second)
+	for manager.GetBridge("temp-link") != nil && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if manager.GetBridge("temp-link") != nil {
+		t.Fatalf("Expected temporary bridge to be removed after expiry")
+	}
+
+	// Early teardown
+	if _, err := manager.StartTemporary(cfg, time.Hour); err != nil {
+		t.Fatalf("Failed to restart temporary bridge: %v", err)
+	}
+	if err := manager.StopTemporary("temp-link"); err != nil {
+		t.Fatalf("Failed to stop temporary bridge: %v", err)
+	}
+	deadline = time.Now().Add(2 * time.Second)
+	for manager.GetBridge("temp-link") != nil && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if manager.GetBridge("temp-link") != nil {
+		t.Errorf("Expected temporary bridge to be removed after StopTemporary")
+	}
+	if err := manager.StopTemporary("temp-link"); err == nil {
+		t.Errorf("Expected error stopping an unknown temporary bridge")
+	}
+}
+
+func TestBridge_ResolveRemoteEmitsAddressChange(t *testing.T) {
+	events := make(chan repeater.Event, 1)
+	bridge := NewBridge(config.BridgeConfig{Name: "moving", Host: "127.0.0.1", Port: 4200}, &MockNetworkServer{}, logger.NewTestLogger(os.Stdout))
+	bridge.events = events
+
+	// First resolution has nothing to compare against
+	if _, err := bridge.resolveRemote(); err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("Expected no event on first resolution")
+	}
+
+	// Simulate a previously resolved address that has since changed
+	bridge.remoteAddr = &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 4200}
+	addr, err := bridge.resolveRemote()
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if addr.String() != "127.0.0.1:4200" || !bridge.IsConnectedTo(addr) {
+		t.Errorf("Expected bridge to use the new address, got %v", addr)
+	}
+
+	select {
+	case ev := <-events:
+		if ev.Type != repeater.EventBridgeAddressChanged || ev.Bridge != "moving" {
+			t.Errorf("Unexpected event: %+v", ev)
+		}
+		if ev.Data["old_address"] != "192.0.2.1:4200" || ev.Data["new_address"] != "127.0.0.1:4200" {
+			t.Errorf("Unexpected event data: %v", ev.Data)
+		}
+	default:
+		t.Fatalf("Expected address change event")
+	}
+}
+
+func TestBridgeManager_RxOnlyExcludedFromForwarding(t *testing.T) {
+	logger := logger.NewTestLogger(os.Stdout)
+	manager := NewManager(nil, &MockNetworkServer{}, logger)
+
+	for i, cfg := range []config.BridgeConfig{
+		{Name: "two-way", Host: "127.0.0.1", Port: 42001},
+		{Name: "monitor", Host: "127.0.0.1", Port: 42002, RxOnly: true},
+	} {
+		b := NewBridge(cfg, manager.server, logger)
+		b.state = StateConnected
+		b.remoteAddr = &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 42001 + i}
+		manager.bridges[cfg.Name] = b
+	}
+
+	if got := len(manager.GetConnectedAddresses()); got != 2 {
+		t.Errorf("Expected 2 connected addresses, got %d", got)
+	}
+	forward := manager.GetForwardAddresses()
+	if len(forward) != 1 || forward[0].Port != 42001 {
+		t.Errorf("Expected only the two-way bridge to receive local traffic, got %v", forward)
+	}
+	if !manager.GetStatus()["monitor"].RxOnly {
+		t.Errorf("Expected rx_only in bridge status")
+	}
+}
+
+func TestBridgeManager_ShutdownUnlinksBridges(t *testing.T) {
+	mockServer := &MockNetworkServer{}
+	manager := NewManager([]config.BridgeConfig{
+		{Name: "test-unlink", Host: "localhost", Port: 4200, Enabled: true, Permanent: true},
+	}, mockServer, logger.NewTestLogger(os.Stdout))
+
+	if err := manager.Start(); err != nil {
+		t.Fatalf("Failed to start manager: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	if !manager.Shutdown(time.Second) {
+		t.Fatalf("Expected the bridge to exit before the timeout")
+	}
+	if n := len(mockServer.sentPackets); n == 0 || string(mockServer.sentPackets[n-1][:4]) != "YSFU" {
+		t.Errorf("Expected the last packet to be an unlink")
+	}
+	if state := manager.GetStatus()["test-unlink"].State; state != StateDisconnected {
+		t.Errorf("Expected bridge to be disconnected, got %s", state)
+	}
+}
+
+func TestBridgeManager_CallsignRoutes(t *testing.T) {
+	logger := logger.NewTestLogger(os.Stdout)
+	manager := NewManager(nil, &MockNetworkServer{}, logger)
+
+	for i, cfg := range []config.BridgeConfig{
+		{Name: "shared", Host: "127.0.0.1", Port: 42001},
+		{Name: "personal", Host: "127.0.0.1", Port: 42002, Callsigns: []string{"k5abc"}},
+	} {
+		b := NewBridge(cfg, manager.server, logger)
+		b.state = StateConnected
+		b.remoteAddr = &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 42001 + i}
+		manager.bridges[cfg.Name] = b
+	}
+
+	if got := len(manager.GetForwardAddressesFor("K5ABC-7")); got != 2 {
+		t.Errorf("Expected the routed callsign to reach both bridges, got %d", got)
+	}
+	forward := manager.GetForwardAddressesFor("W1AW")
+	if len(forward) != 1 || forward[0].Port != 42001 {
+		t.Errorf("Expected other callsigns to reach only the shared bridge, got %v", forward)
+	}
+}
+
+func TestBridge_RetryBackoffWithFakeClock(t *testing.T) {
+	fake := &FakeClock{NowTime: time.Date(2025, 10, 3, 12, 0, 0, 0, time.UTC)}
+	// An invalid port fails to resolve without a DNS lookup
+	b := NewBridge(config.BridgeConfig{
+		Name: "retry", Host: "127.0.0.1", Port: 70000, MaxRetries: 3, RetryDelay: time.Minute,
+	}, &MockNetworkServer{}, logger.NewTestLogger(os.Stdout))
+	b.setClock(fake)
+
+	done := make(chan struct{})
+	go func() {
+		b.RunPermanent(context.Background())
+		close(done)
+	}()
+
+	// Two backoff waits of at most 2.5 minutes each, skipped by advancing the clock
+	for i := 0; i < 2; i++ {
+		deadline := time.Now().Add(time.Second)
+		for fake.Waiters() == 0 {
+			if time.Now().After(deadline) {
+				t.Fatalf("bridge did not wait for retry %d", i+1)
+			}
+			time.Sleep(time.Millisecond)
+		}
+		fake.Advance(10 * time.Minute)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("bridge did not give up after max retries")
+	}
+	if status := b.GetStatus(); status.RetryCount != 3 || status.State != StateFailed {
+		t.Errorf("Expected 3 failed attempts, got %d (%s)", status.RetryCount, status.State)
+	}
+}
+
+func TestBridge_RetryDelayJitter(t *testing.T) {
+	b := NewBridge(config.BridgeConfig{Name: "jitter", RetryDelay: time.Second}, &MockNetworkServer{}, logger.NewTestLogger(os.Stdout))
+
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 20; i++ {
+		delay := b.calculateRetryDelay()
+		if delay < 750*time.Millisecond || delay > 1250*time.Millisecond {
+			t.Fatalf("delay %v outside ±25%% of the base delay", delay)
+		}
+		seen[delay] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("expected jittered delays to vary")
+	}
+}
+
+func TestBridge_LastTalkerAndRxTime(t *testing.T) {
+	fake := &FakeClock{NowTime: time.Date(2025, 10, 3, 12, 0, 0, 0, time.UTC)}
+	b := NewBridge(config.BridgeConfig{Name: "talker", Host: "127.0.0.1", Port: 42000}, &MockNetworkServer{}, logger.NewTestLogger(os.Stdout))
+	b.setClock(fake)
+
+	status := b.GetStatus()
+	if status.LastTalker != "" || status.LastTalkerTime != nil || status.LastRxTime != nil {
+		t.Fatalf("expected no talker or rx time before traffic, got %+v", status)
+	}
+
+	b.IncrementRxStats(155)
+	fake.Advance(time.Second)
+	b.RecordTalker("W1AW")
+
+	status = b.GetStatus()
+	if status.LastTalker != "W1AW" {
+		t.Errorf("expected last talker W1AW, got %q", status.LastTalker)
+	}
+	if status.LastRxTime == nil || !status.LastRxTime.Equal(fake.NowTime.Add(-time.Second)) {
+		t.Errorf("unexpected last rx time %v", status.LastRxTime)
+	}
+	if status.LastTalkerTime == nil || !status.LastTalkerTime.Equal(fake.NowTime) {
+		t.Errorf("unexpected last talker time %v", status.LastTalkerTime)
+	}
+}
+
+func TestBridge_RecordTransmissionLoss(t *testing.T) {
+	b := NewBridge(config.BridgeConfig{Name: "loss", Host: "127.0.0.1", Port: 42000}, &MockNetworkServer{}, logger.NewTestLogger(os.Stdout))
+
+	// Counter shows 2 of 50 lost; cadence shows 10 of 100 missing
+	b.RecordTransmission(repeater.QualityReport{Frames: 48, LostFrames: 2, ExpectedFrames: 50})
+	b.RecordTransmission(repeater.QualityReport{Frames: 90, ExpectedFrames: 100})
+
+	status := b.GetStatus()
+	if status.LastTalkerLoss != 10 {
+		t.Errorf("expected 10%% loss on the last transmission, got %.1f", status.LastTalkerLoss)
+	}
+	if status.StreamFrames != 138 {
+		t.Errorf("expected 138 stream frames, got %d", status.StreamFrames)
+	}
+	if want := 12.0 / 150 * 100; status.StreamLoss != want {
+		t.Errorf("expected %.2f%% stream loss, got %.2f", want, status.StreamLoss)
+	}
+}
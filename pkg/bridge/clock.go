@@ -1,20 +1,13 @@
 package bridge
 
-import "time"
+import "github.com/dbehnke/ysf-nexus/pkg/clock"
 
-// Clock is a small abstraction over time so we can inject deterministic times in tests
-type Clock interface {
-	Now() time.Time
-}
+// Clock is the time source for schedules, retries and health checks, so
+// tests can inject deterministic times
+type Clock = clock.Clock
 
-// RealClock uses the real time.Now
-type RealClock struct{}
+// RealClock uses the system clock
+type RealClock = clock.Real
 
-func (r *RealClock) Now() time.Time { return time.Now() }
-
-// FakeClock returns a fixed time; tests can set NowTime to the desired value
-type FakeClock struct {
-	NowTime time.Time
-}
-
-func (f *FakeClock) Now() time.Time { return f.NowTime }
+// FakeClock is a manually advanced clock; tests can set NowTime to the desired value
+type FakeClock = clock.Fake
@@ -0,0 +1,186 @@
+package bridge
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// Draining takes a bridge out of service without cutting off a net: from the
+// drain request on, no new transmission is forwarded to it, only the one
+// already crossing it, and once the link has been quiet in both directions
+// for drainIdle the bridge's current run is stopped. A temporary bridge is
+// removed, a scheduled one stays down until its next window and a permanent
+// one until the bridges are reloaded or the reflector restarts.
+
+const (
+	// drainIdle is how long without frames ends a transmission; they arrive
+	// every 100ms while one is in progress
+	drainIdle = 2 * time.Second
+	// maxDrainTime bounds how long a drain waits for the link to go quiet
+	maxDrainTime = 5 * time.Minute
+	// drainCheckInterval is how often a draining bridge is checked
+	drainCheckInterval = 250 * time.Millisecond
+)
+
+var (
+	// ErrUnknownBridge is returned for a bridge name the manager doesn't know
+	ErrUnknownBridge = errors.New("unknown bridge")
+	// ErrNotLinked is returned when draining a bridge that isn't connected
+	ErrNotLinked = errors.New("bridge is not connected")
+)
+
+// drainState is the progress of a drain
+type drainState struct {
+	since time.Time
+	// stream is the callsign of the transmission still forwarded, empty once
+	// it has ended
+	stream string
+}
+
+// Drain stops forwarding new transmissions to a connected bridge and
+// disconnects it once the current one has finished. Draining a bridge that
+// is already draining returns its status.
+func (m *Manager) Drain(name string) (BridgeStatus, error) {
+	bridge := m.GetBridge(name)
+	if bridge == nil {
+		return BridgeStatus{}, ErrUnknownBridge
+	}
+	started, err := bridge.startDrain()
+	if err != nil {
+		return BridgeStatus{}, err
+	}
+	status := bridge.GetStatus()
+	if started {
+		m.logger.Info("Draining bridge",
+			logger.String("name", name),
+			logger.String("stream", status.DrainStream))
+		go m.watchDrain(name, bridge)
+	}
+	return status, nil
+}
+
+// watchDrain stops a draining bridge once its link is quiet, it disconnected
+// on its own or the drain took too long
+func (m *Manager) watchDrain(name string, bridge *Bridge) {
+	ticker := time.NewTicker(drainCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+			done, since := bridge.drainDone()
+			if !done && m.clock.Now().Sub(since) < maxDrainTime {
+				continue
+			}
+			if !done {
+				m.logger.Warn("Bridge drain timed out, disconnecting",
+					logger.String("name", name),
+					logger.Duration("timeout", maxDrainTime))
+			}
+			m.stopDrained(name, bridge)
+			return
+		}
+	}
+}
+
+// stopDrained ends the current run of a drained bridge, unless a reload has
+// replaced it meanwhile
+func (m *Manager) stopDrained(name string, bridge *Bridge) {
+	m.mu.Lock()
+	if m.bridges[name] != bridge {
+		m.mu.Unlock()
+		return
+	}
+	stop := bridge.disconnect
+	if cancel, ok := m.temporary[name]; ok {
+		stop = cancel
+	} else if run, ok := m.runs[name]; ok {
+		// Firing the run's timer now ends it as if its window had closed
+		stop = func() {}
+		if run.timer.Stop() {
+			run.timer.Reset(0)
+		}
+	} else if entry, ok := m.configured[name]; ok && entry.config.Permanent {
+		// No longer running from configuration, so a reload starts it again
+		delete(m.configured, name)
+		stop = entry.cancel
+	}
+	m.mu.Unlock()
+
+	bridge.stopForDrain()
+	stop()
+	m.logger.Info("Bridge drained", logger.String("name", name))
+}
+
+// startDrain marks a connected bridge as draining, keeping the transmission
+// forwarded to it within the last drainIdle. It reports false if the bridge
+// was already draining.
+func (b *Bridge) startDrain() (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != StateConnected {
+		return false, ErrNotLinked
+	}
+	if b.drain != nil {
+		return false, nil
+	}
+	now := b.clock.Now()
+	b.drain = &drainState{since: now}
+	if b.lastTxCallsign != "" && now.Sub(b.lastTxAt) < drainIdle {
+		b.drain.stream = b.lastTxCallsign
+	}
+	return true, nil
+}
+
+// stopForDrain marks the coming disconnect as the end of a drain, so its
+// event is told apart from the link dropping while draining
+func (b *Bridge) stopForDrain() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.drainStopping = true
+}
+
+// forwarding reports whether local traffic from callsign may be forwarded
+// now and, if so, records it as the transmission crossing the bridge. While
+// draining only the transmission in progress when the drain started is.
+func (b *Bridge) forwarding(callsign string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.clock.Now()
+	if d := b.drain; d != nil {
+		if d.stream != "" && now.Sub(b.lastTxAt) >= drainIdle {
+			d.stream = ""
+		}
+		if d.stream != callsign {
+			return false
+		}
+	}
+	b.lastTxCallsign = callsign
+	b.lastTxAt = now
+	return true
+}
+
+// drainDone reports whether a drain has finished, because the link has been
+// quiet in both directions for drainIdle or the bridge has disconnected
+// meanwhile, and when it started
+func (b *Bridge) drainDone() (bool, time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.drain == nil {
+		return true, time.Time{}
+	}
+	now := b.clock.Now()
+	quiet := now.Sub(b.lastTxAt) >= drainIdle &&
+		(b.lastTalkerTime == nil || now.Sub(*b.lastTalkerTime) >= drainIdle)
+	if quiet {
+		b.drain.stream = ""
+	}
+	return quiet, b.drain.since
+}
@@ -0,0 +1,130 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+func TestManagerDrain(t *testing.T) {
+	fake := &FakeClock{NowTime: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)}
+	cfg := config.BridgeConfig{Name: "net", Host: "127.0.0.1", Port: 42000, Enabled: true, Permanent: true,
+		Protocol: config.BridgeProtocolGeneric}
+	mgr := NewManagerWithClock([]config.BridgeConfig{cfg}, &MockNetworkServer{}, logger.NewTestLogger(io.Discard), fake)
+	events := make(chan repeater.Event, 10)
+	mgr.SetEventChannel(events)
+	if err := mgr.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer mgr.Stop()
+
+	bridge := mgr.GetBridge("net")
+	waitFor(t, "bridge to connect", bridge.IsConnected)
+	if _, err := mgr.Drain("nope"); !errors.Is(err, ErrUnknownBridge) {
+		t.Errorf("draining an unknown bridge: %v, want ErrUnknownBridge", err)
+	}
+
+	// N0CALL is talking when the drain starts
+	if got := len(mgr.GetForwardAddressesFor("N0CALL")); got != 1 {
+		t.Fatalf("forwarded to %d bridges before draining, want 1", got)
+	}
+	status, err := mgr.Drain("net")
+	if err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	if status.State != StateDraining || status.DrainStream != "N0CALL" || status.DrainingSince == nil {
+		t.Errorf("unexpected draining status %+v", status)
+	}
+	// Still linked, so not counted as down
+	if !status.State.Linked() || mgr.GetStats().ActiveBridges != 1 {
+		t.Errorf("expected the draining bridge to count as linked, active %d", mgr.GetStats().ActiveBridges)
+	}
+
+	// The transmission in progress continues, a new one is held back
+	fake.Advance(time.Second)
+	if got := len(mgr.GetForwardAddressesFor("W1AW")); got != 0 {
+		t.Errorf("new transmission forwarded to %d bridges while draining", got)
+	}
+	if got := len(mgr.GetForwardAddressesFor("N0CALL")); got != 1 {
+		t.Errorf("current transmission forwarded to %d bridges while draining, want 1", got)
+	}
+	time.Sleep(2 * drainCheckInterval)
+	if !bridge.IsConnected() {
+		t.Fatal("expected the bridge to stay linked while the transmission continues")
+	}
+
+	// Once it has ended nothing more is forwarded and the bridge unlinks
+	fake.Advance(drainIdle)
+	if got := len(mgr.GetForwardAddressesFor("N0CALL")); got != 0 {
+		t.Errorf("forwarded to %d bridges after the transmission ended", got)
+	}
+	waitFor(t, "bridge to disconnect", func() bool { return bridge.GetStatus().State == StateDisconnected })
+	if drained := disconnectDrained(events); len(drained) != 1 || !drained[0] {
+		t.Errorf("disconnect events drained = %v, want [true]", drained)
+	}
+	time.Sleep(2 * drainCheckInterval)
+	if bridge.IsConnected() {
+		t.Error("expected a drained permanent bridge to stay down")
+	}
+	if _, err := mgr.Drain("net"); !errors.Is(err, ErrNotLinked) {
+		t.Errorf("draining a disconnected bridge: %v, want ErrNotLinked", err)
+	}
+
+	// A reload brings it back
+	if result := mgr.Reload([]config.BridgeConfig{cfg}); len(result.Added) != 1 {
+		t.Fatalf("unexpected reload result %+v", result)
+	}
+	waitFor(t, "bridge to reconnect", mgr.GetBridge("net").IsConnected)
+}
+
+func TestDrainLinkLossNotDrained(t *testing.T) {
+	events := make(chan repeater.Event, 10)
+	bridge := NewBridge(config.BridgeConfig{Name: "net", Host: "127.0.0.1", Port: 42000, Protocol: config.BridgeProtocolGeneric}, &MockNetworkServer{}, logger.NewTestLogger(io.Discard))
+	bridge.events = events
+	if err := bridge.connect(context.Background()); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	if _, err := bridge.startDrain(); err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+
+	// The link drops before the drain finishes
+	bridge.setConnectionError("connection timeout - no packets received")
+	bridge.disconnect()
+	if drained := disconnectDrained(events); len(drained) != 1 || drained[0] {
+		t.Errorf("disconnect events drained = %v, want [false]", drained)
+	}
+	if bridge.GetStatus().State != StateDisconnected {
+		t.Error("expected the drain to end with the link")
+	}
+}
+
+// disconnectDrained returns whether each waiting bridge_disconnected event
+// is marked drained
+func disconnectDrained(events chan repeater.Event) []bool {
+	var drained []bool
+	for len(events) > 0 {
+		if event := <-events; event.Type == repeater.EventBridgeDisconnected {
+			drained = append(drained, event.Data["drained"] == true)
+		}
+	}
+	return drained
+}
+
+// waitFor polls cond for up to two seconds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
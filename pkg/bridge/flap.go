@@ -0,0 +1,167 @@
+package bridge
+
+import (
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+// Link state dampening. Each connect and disconnect of a bridge is reported
+// as an event, but a bridge that keeps linking and dropping would flood the
+// event feed and dashboards. Once a bridge changes state more than limit
+// times within the window it is flapping: one bridge_flapping event is sent
+// and further state changes are only counted. When the bridge has not
+// changed state for a whole window it is stable again, reported with a
+// bridge_stable event carrying the number of suppressed events.
+
+// flapCheckInterval is how often flapping bridges are checked for stability
+const flapCheckInterval = 15 * time.Second
+
+// flapDamper tracks the link state changes of a bridge
+type flapDamper struct {
+	limit  int           // State changes allowed within the window (0 = no dampening)
+	window time.Duration // Period over which state changes are counted
+
+	transitions []time.Time // Within the window, oldest first
+	flapping    bool
+	since       time.Time // When the bridge started flapping
+	suppressed  uint64    // Events held back while flapping
+}
+
+// pruneLocked drops state changes older than the window (assumes mutex is locked)
+func (f *flapDamper) pruneLocked(now time.Time) {
+	oldest := now.Add(-f.window)
+	for len(f.transitions) > 0 && f.transitions[0].Before(oldest) {
+		f.transitions = f.transitions[1:]
+	}
+}
+
+// recent returns the number of state changes within the window
+func (f *flapDamper) recent(now time.Time) int {
+	oldest := now.Add(-f.window)
+	count := 0
+	for _, at := range f.transitions {
+		if !at.Before(oldest) {
+			count++
+		}
+	}
+	return count
+}
+
+// stateChangedLocked reports a link state change, dampened while the bridge
+// is flapping (assumes mutex is locked)
+func (b *Bridge) stateChangedLocked(event repeater.Event) {
+	f := &b.flap
+	if f.limit <= 0 {
+		b.emitLocked(event)
+		return
+	}
+
+	now := event.Timestamp
+	f.pruneLocked(now)
+	f.transitions = append(f.transitions, now)
+
+	switch {
+	case f.flapping:
+		f.suppressed++
+	case len(f.transitions) > f.limit:
+		f.flapping = true
+		f.since = now
+		f.suppressed = 1
+		b.logger.Warn("Bridge is flapping - suppressing link state events",
+			logger.Int("transitions", len(f.transitions)),
+			logger.Duration("window", f.window))
+		b.emitLocked(repeater.Event{
+			Type:      repeater.EventBridgeFlapping,
+			Bridge:    b.config.Name,
+			Timestamp: now,
+			Data: map[string]interface{}{
+				"transitions": len(f.transitions),
+				"window":      f.window.String(),
+			},
+		})
+	default:
+		b.emitLocked(event)
+	}
+}
+
+// checkFlapping ends flapping once the bridge has not changed state for a
+// whole window, reporting the suppressed events and the current state
+func (b *Bridge) checkFlapping() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	f := &b.flap
+	if !f.flapping {
+		return
+	}
+	now := b.clock.Now()
+	f.pruneLocked(now)
+	if len(f.transitions) > 0 {
+		return
+	}
+
+	b.logger.Info("Bridge is stable again",
+		logger.Uint64("suppressed", f.suppressed),
+		logger.Duration("flapped_for", now.Sub(f.since)))
+	b.emitLocked(repeater.Event{
+		Type:      repeater.EventBridgeStable,
+		Bridge:    b.config.Name,
+		Timestamp: now,
+		Duration:  now.Sub(f.since),
+		Data: map[string]interface{}{
+			"state":      string(b.state),
+			"suppressed": f.suppressed,
+		},
+	})
+	f.flapping = false
+	f.since = time.Time{}
+	f.suppressed = 0
+}
+
+// emitLocked sends an event without blocking (assumes mutex is locked)
+func (b *Bridge) emitLocked(event repeater.Event) {
+	if b.events == nil {
+		return
+	}
+	select {
+	case b.events <- event:
+	default:
+		b.logger.Warn("Event channel full, dropping bridge event", logger.String("type", event.Type))
+	}
+}
+
+// SetFlapDampening makes bridges report flapping instead of each link state
+// change after more than limit changes within window (limit 0 = off). It must
+// be called before Start.
+func (m *Manager) SetFlapDampening(limit int, window time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.flapLimit = limit
+	m.flapWindow = window
+}
+
+// runFlapChecks ends the flapping of bridges that have settled until the
+// manager stops
+func (m *Manager) runFlapChecks() {
+	ticker := time.NewTicker(flapCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+			m.mu.RLock()
+			bridges := make([]*Bridge, 0, len(m.bridges))
+			for _, bridge := range m.bridges {
+				bridges = append(bridges, bridge)
+			}
+			m.mu.RUnlock()
+			for _, bridge := range bridges {
+				bridge.checkFlapping()
+			}
+		}
+	}
+}
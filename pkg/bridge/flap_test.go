@@ -0,0 +1,139 @@
+package bridge
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+// drainEvents returns the types of the events waiting on the channel
+func drainEvents(events chan repeater.Event) []string {
+	var types []string
+	for {
+		select {
+		case event := <-events:
+			types = append(types, event.Type)
+		default:
+			return types
+		}
+	}
+}
+
+func TestBridgeFlapDampening(t *testing.T) {
+	clk := &FakeClock{NowTime: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)}
+	events := make(chan repeater.Event, 100)
+
+	bridge := NewBridge(config.BridgeConfig{Name: "DX", Host: "127.0.0.1", Port: 42000, Protocol: config.BridgeProtocolGeneric}, &MockNetworkServer{}, logger.NewTestLogger(io.Discard))
+	bridge.setClock(clk)
+	bridge.events = events
+	bridge.flap.limit = 4
+	bridge.flap.window = 10 * time.Minute
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	flap := func() {
+		if err := bridge.connect(ctx); err != nil {
+			t.Fatalf("connect failed: %v", err)
+		}
+		clk.NowTime = clk.NowTime.Add(time.Minute)
+		bridge.disconnect()
+		clk.NowTime = clk.NowTime.Add(time.Minute)
+	}
+
+	// Four state changes are reported as they happen
+	flap()
+	flap()
+	got := drainEvents(events)
+	want := []string{
+		repeater.EventBridgeConnected, repeater.EventBridgeDisconnected,
+		repeater.EventBridgeConnected, repeater.EventBridgeDisconnected,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("events = %v, want %v", got, want)
+		}
+	}
+
+	// The fifth makes the bridge flap: one alert, then silence
+	flap()
+	flap()
+	got = drainEvents(events)
+	if len(got) != 1 || got[0] != repeater.EventBridgeFlapping {
+		t.Fatalf("events while flapping = %v", got)
+	}
+	status := bridge.GetStatus()
+	if !status.Flapping || status.FlappingSince == nil || status.Suppressed != 4 || status.Transitions != 8 {
+		t.Errorf("status while flapping = %+v", status)
+	}
+
+	// Still within the window of the last change
+	clk.NowTime = clk.NowTime.Add(5 * time.Minute)
+	bridge.checkFlapping()
+	if got := drainEvents(events); len(got) != 0 {
+		t.Errorf("expected no events before the bridge settled, got %v", got)
+	}
+
+	// A whole window without changes: stable again
+	clk.NowTime = clk.NowTime.Add(5 * time.Minute)
+	bridge.checkFlapping()
+	select {
+	case event := <-events:
+		if event.Type != repeater.EventBridgeStable || event.Data["suppressed"] != uint64(4) || event.Data["state"] != string(StateDisconnected) {
+			t.Errorf("stable event = %+v", event)
+		}
+	default:
+		t.Fatal("expected a bridge_stable event")
+	}
+	status = bridge.GetStatus()
+	if status.Flapping || status.FlappingSince != nil || status.Suppressed != 0 || status.Transitions != 0 {
+		t.Errorf("status after settling = %+v", status)
+	}
+
+	// State changes are reported again
+	flap()
+	if got := drainEvents(events); len(got) != 2 || got[0] != repeater.EventBridgeConnected {
+		t.Errorf("events after settling = %v", got)
+	}
+}
+
+func TestBridgeFlapDampeningDisabled(t *testing.T) {
+	clk := &FakeClock{NowTime: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)}
+	events := make(chan repeater.Event, 100)
+
+	bridge := NewBridge(config.BridgeConfig{Name: "DX", Host: "127.0.0.1", Port: 42000, Protocol: config.BridgeProtocolGeneric}, &MockNetworkServer{}, logger.NewTestLogger(io.Discard))
+	bridge.setClock(clk)
+	bridge.events = events
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for i := 0; i < 10; i++ {
+		if err := bridge.connect(ctx); err != nil {
+			t.Fatalf("connect failed: %v", err)
+		}
+		bridge.disconnect()
+	}
+
+	connects := 0
+	for _, eventType := range drainEvents(events) {
+		if eventType == repeater.EventBridgeFlapping {
+			t.Fatal("unexpected bridge_flapping event with dampening disabled")
+		}
+		if eventType == repeater.EventBridgeConnected {
+			connects++
+		}
+	}
+	if connects != 10 {
+		t.Errorf("expected 10 bridge_connected events, got %d", connects)
+	}
+	if status := bridge.GetStatus(); status.Flapping || status.Transitions != 0 {
+		t.Errorf("status = %+v", status)
+	}
+}
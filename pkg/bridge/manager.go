@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/dbehnke/ysf-nexus/pkg/config"
 	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
 	"github.com/robfig/cron/v3"
 )
 
@@ -19,6 +21,7 @@ type Manager struct {
 	server NetworkServer
 	cron   *cron.Cron
 	clock  Clock
+	events chan<- repeater.Event
 
 	// Bridge tracking
 	mu      sync.RWMutex
@@ -27,32 +30,69 @@ type Manager struct {
 	// Schedule tracking for missed recovery
 	schedules map[string]*ScheduleInfo
 
+	// Current runs of scheduled bridges, by bridge name
+	runs map[string]*scheduledRun
+
+	// Cancel functions for temporary bridges created at runtime
+	temporary map[string]context.CancelFunc
+
+	// Bridges started from configuration, so a reload can replace them
+	configured map[string]*configuredBridge
+
 	// Context for cancellation
 	ctx    context.Context
 	cancel context.CancelFunc
+	// running tracks bridge goroutines so Stop can wait for them to unlink
+	running sync.WaitGroup
 
 	// Statistics
 	stats BridgeStats
+	// usage is the cumulative per-bridge usage, optionally persisted
+	usage *usageTracker
+
+	// Link state dampening applied to new bridges
+	flapLimit  int
+	flapWindow time.Duration
 }
 
-// ScheduleInfo tracks schedule information for missed recovery
+// ScheduleInfo tracks schedule information for missed recovery. Schedule and
+// Duration are those of the first window; NextExecution is the soonest of all
+// windows and MissedWindows their total.
 type ScheduleInfo struct {
-	Name          string
-	Schedule      string
-	Duration      time.Duration
-	LastExecution *time.Time
-	NextExecution *time.Time
-	MissedWindows int
+	Name          string        `json:"name"`
+	Schedule      string        `json:"schedule"`
+	Timezone      string        `json:"timezone,omitempty"`
+	Duration      time.Duration `json:"duration"`
+	LastExecution *time.Time    `json:"last_execution,omitempty"`
+	LastDuration  time.Duration `json:"last_duration,omitempty"` // How long the last run kept the bridge linked
+	NextExecution *time.Time    `json:"next_execution,omitempty"`
+	MissedWindows int           `json:"missed_windows"`
+	Windows       []WindowInfo  `json:"windows"`
+}
+
+// WindowInfo tracks one schedule window of a bridge
+type WindowInfo struct {
+	Window        int           `json:"window"`
+	Schedule      string        `json:"schedule"`
+	Description   string        `json:"description"`
+	Duration      time.Duration `json:"duration"`
+	LastExecution *time.Time    `json:"last_execution,omitempty"`
+	LastDuration  time.Duration `json:"last_duration,omitempty"`
+	NextExecution *time.Time    `json:"next_execution,omitempty"`
+	MissedWindows int           `json:"missed_windows"`
 }
 
 // BridgeStats tracks overall bridge statistics
 type BridgeStats struct {
-	ActiveBridges    int
-	FailedBridges    int
-	TotalConnections uint64
-	MissedSchedules  int
+	ActiveBridges    int    `json:"active_bridges"`
+	FailedBridges    int    `json:"failed_bridges"`
+	TotalConnections uint64 `json:"total_connections"`
+	MissedSchedules  int    `json:"missed_schedules"`
 }
 
+// defaultShutdownTimeout bounds how long Stop waits for bridges to unlink
+const defaultShutdownTimeout = 5 * time.Second
+
 // BridgeState represents the current state of a bridge
 type BridgeState string
 
@@ -62,8 +102,16 @@ const (
 	StateConnected    BridgeState = "connected"
 	StateFailed       BridgeState = "failed"
 	StateScheduled    BridgeState = "scheduled"
+	// StateDraining is reported for a connected bridge that is being drained
+	StateDraining BridgeState = "draining"
 )
 
+// Linked reports whether a bridge in this state is linked to its remote
+// reflector; a draining bridge still is until its last transmission ends
+func (s BridgeState) Linked() bool {
+	return s == StateConnected || s == StateDraining
+}
+
 // BridgeStatus holds runtime status information for a bridge
 type BridgeStatus struct {
 	Name           string        `json:"name"`
@@ -78,6 +126,24 @@ type BridgeStatus struct {
 	PacketsTx      uint64        `json:"packets_tx"`
 	BytesRx        uint64        `json:"bytes_rx"`
 	BytesTx        uint64        `json:"bytes_tx"`
+	Connections    uint64        `json:"connections"`
+	Temporary      bool          `json:"temporary,omitempty"`
+	RxOnly         bool          `json:"rx_only,omitempty"`
+	Callsigns      []string      `json:"callsigns,omitempty"`
+	ExpiresAt      *time.Time    `json:"expires_at,omitempty"`
+	LastTalker     string        `json:"last_talker,omitempty"`
+	LastTalkerTime *time.Time    `json:"last_talker_time,omitempty"`
+	LastTalkerLoss float64       `json:"last_talker_loss_percent"` // Frames missing from the last transmission
+	StreamFrames   uint64        `json:"stream_frames"`            // Frames of transmissions received over the bridge
+	StreamLoss     float64       `json:"stream_loss_percent"`      // Frames missing from those transmissions
+	LastRxTime     *time.Time    `json:"last_rx_time,omitempty"`
+	Protocol       string        `json:"protocol"` // Keepalive variant in use; auto while detecting
+	Flapping       bool          `json:"flapping"`
+	FlappingSince  *time.Time    `json:"flapping_since,omitempty"`
+	Transitions    int           `json:"recent_transitions"`          // Link state changes within the dampening window
+	Suppressed     uint64        `json:"suppressed_events,omitempty"` // State change events held back while flapping
+	DrainingSince  *time.Time    `json:"draining_since,omitempty"`
+	DrainStream    string        `json:"drain_stream,omitempty"` // Callsign still being forwarded while draining
 }
 
 // NewManager creates a new bridge manager
@@ -96,12 +162,38 @@ func NewManagerWithClock(config []config.BridgeConfig, server NetworkServer, log
 		cron:      cron.New(cron.WithSeconds()),
 		bridges:   make(map[string]*Bridge),
 		schedules: make(map[string]*ScheduleInfo),
+		runs:      make(map[string]*scheduledRun),
+		temporary: make(map[string]context.CancelFunc),
 		ctx:       ctx,
 		cancel:    cancel,
 		clock:     clock,
+		usage:     newUsageTracker(clock),
+
+		configured: make(map[string]*configuredBridge),
 	}
 }
 
+// SetEventChannel sets the channel that receives bridge events such as address changes.
+// It must be called before Start.
+func (m *Manager) SetEventChannel(events chan<- repeater.Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = events
+}
+
+// newBridge creates a bridge wired to the manager's server and event channel
+func (m *Manager) newBridge(cfg config.BridgeConfig) *Bridge {
+	bridge := NewBridge(cfg, m.server, m.logger)
+	bridge.setClock(m.clock)
+	bridge.usage = m.usage
+	m.mu.RLock()
+	bridge.events = m.events
+	bridge.flap.limit = m.flapLimit
+	bridge.flap.window = m.flapWindow
+	m.mu.RUnlock()
+	return bridge
+}
+
 // Start initializes and starts all configured bridges
 func (m *Manager) Start() error {
 	m.logger.Info("Starting bridge manager")
@@ -127,6 +219,14 @@ func (m *Manager) Start() error {
 	// Start the missed schedule recovery checker
 	go m.runMissedScheduleRecovery()
 
+	if m.usage.path != "" {
+		go m.runUsagePersistence()
+	}
+
+	if m.flapLimit > 0 {
+		go m.runFlapChecks()
+	}
+
 	m.logger.Info("Bridge manager started", logger.Int("bridges", len(m.bridges)))
 	return nil
 }
@@ -148,38 +248,54 @@ func (m *Manager) runMissedScheduleRecovery() {
 
 // setupBridge configures a bridge based on its type (permanent or scheduled)
 func (m *Manager) setupBridge(config config.BridgeConfig) error {
-	bridge := NewBridge(config, m.server, m.logger)
+	bridge := m.newBridge(config)
+	ctx, cancel := context.WithCancel(m.ctx)
+	entry := &configuredBridge{config: config, ctx: ctx, cancel: cancel}
 
 	m.mu.Lock()
 	m.bridges[config.Name] = bridge
+	m.configured[config.Name] = entry
 	m.mu.Unlock()
 
 	if config.Permanent {
 		// Start permanent bridge immediately
-		go bridge.RunPermanent(m.ctx)
+		m.goBridge(func() { bridge.RunPermanent(ctx) })
 		m.logger.Info("Started permanent bridge", logger.String("name", config.Name))
-	} else if config.Schedule != "" {
+	} else if windows := config.ScheduleWindows(); len(windows) > 0 {
 		// Set up schedule tracking for missed recovery
 		m.setupScheduleTracking(config)
 
-		// Schedule the bridge using cron
-		_, err := m.cron.AddFunc(config.Schedule, func() {
-			m.startScheduledBridge(config.Name, config.Duration)
-		})
-		if err != nil {
-			return fmt.Errorf("failed to schedule bridge %s: %w", config.Name, err)
-		}
+		// Schedule every window of the bridge using cron
+		for i, window := range windows {
+			i, window := i, window
+			id, err := m.cron.AddFunc(scheduleSpec(window.Schedule, config.Timezone), func() {
+				m.startScheduledBridge(config.Name, i, window.Duration)
+			})
+			if err != nil {
+				return fmt.Errorf("failed to schedule bridge %s: %w", config.Name, err)
+			}
+			m.mu.Lock()
+			entry.entries = append(entry.entries, id)
+			m.mu.Unlock()
 
-		m.logger.Info("Scheduled bridge",
-			logger.String("name", config.Name),
-			logger.String("schedule", config.Schedule))
+			m.logger.Info("Scheduled bridge",
+				logger.String("name", config.Name),
+				logger.Int("window", i),
+				logger.String("schedule", window.Schedule),
+				logger.Duration("duration", window.Duration),
+				logger.String("timezone", config.Timezone),
+				logger.String("description", DescribeSchedule(window.Schedule)))
+		}
 
 		// Check if we should start this bridge now (missed schedule recovery)
-		if shouldStart, remainingDuration := m.shouldStartNowWithDuration(config); shouldStart {
-			m.logger.Info("Recovering missed schedule",
-				logger.String("name", config.Name),
-				logger.Duration("remaining_duration", remainingDuration))
-			go m.startScheduledBridge(config.Name, remainingDuration)
+		for i, window := range windows {
+			if shouldStart, remainingDuration := m.windowActive(config.Name, config.Timezone, window); shouldStart {
+				m.logger.Info("Recovering missed schedule",
+					logger.String("name", config.Name),
+					logger.Int("window", i),
+					logger.Duration("remaining_duration", remainingDuration))
+				go m.startScheduledBridge(config.Name, i, remainingDuration)
+			}
 		}
 	}
 
@@ -188,46 +304,86 @@ func (m *Manager) setupBridge(config config.BridgeConfig) error {
 
 // setupScheduleTracking initializes schedule tracking for missed recovery
 func (m *Manager) setupScheduleTracking(config config.BridgeConfig) {
-	parser := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
-	schedule, err := parser.Parse(config.Schedule)
-	if err != nil {
-		m.logger.Error("Failed to parse schedule for tracking",
-			logger.String("name", config.Name),
-			logger.Error(err))
+	windows := config.ScheduleWindows()
+	if len(windows) == 0 {
 		return
 	}
 
 	now := m.clock.Now()
-	nextRun := schedule.Next(now)
+	info := &ScheduleInfo{
+		Name:     config.Name,
+		Schedule: windows[0].Schedule,
+		Timezone: config.Timezone,
+		Duration: windows[0].Duration,
+		Windows:  make([]WindowInfo, len(windows)),
+	}
+	for i, window := range windows {
+		info.Windows[i] = WindowInfo{
+			Window:      i,
+			Schedule:    window.Schedule,
+			Description: DescribeSchedule(window.Schedule),
+			Duration:    window.Duration,
+		}
+		schedule, err := parseSchedule(window.Schedule, config.Timezone)
+		if err != nil {
+			m.logger.Error("Failed to parse schedule for tracking",
+				logger.String("name", config.Name),
+				logger.Int("window", i),
+				logger.Error(err))
+			continue
+		}
+		nextRun := schedule.Next(now)
+		info.Windows[i].NextExecution = &nextRun
+	}
+	info.NextExecution = info.earliestNext()
 
 	m.mu.Lock()
-	m.schedules[config.Name] = &ScheduleInfo{
-		Name:          config.Name,
-		Schedule:      config.Schedule,
-		Duration:      config.Duration,
-		NextExecution: &nextRun,
-	}
+	m.schedules[config.Name] = info
 
 	// Update the bridge's nextSchedule field
 	if bridge, ok := m.bridges[config.Name]; ok {
-		bridge.SetNextSchedule(&nextRun)
+		bridge.SetNextSchedule(info.NextExecution)
 	}
 	m.mu.Unlock()
 }
 
+// earliestNext returns the soonest next execution of any window
+func (s *ScheduleInfo) earliestNext() *time.Time {
+	var earliest *time.Time
+	for _, w := range s.Windows {
+		if w.NextExecution != nil && (earliest == nil || w.NextExecution.Before(*earliest)) {
+			next := *w.NextExecution
+			earliest = &next
+		}
+	}
+	return earliest
+}
+
 // NOTE: shouldStartNow was removed in favor of shouldStartNowWithDuration
 
-// shouldStartNowWithDuration determines if a scheduled bridge should start now and returns remaining duration
+// shouldStartNowWithDuration determines if a scheduled bridge should start now and
+// returns the remaining duration of its longest-running active window
 func (m *Manager) shouldStartNowWithDuration(config config.BridgeConfig) (bool, time.Duration) {
-	parser := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
-	schedule, err := parser.Parse(config.Schedule)
+	var longest time.Duration
+	for _, window := range config.ScheduleWindows() {
+		if active, remaining := m.windowActive(config.Name, config.Timezone, window); active && remaining > longest {
+			longest = remaining
+		}
+	}
+	return longest > 0, longest
+}
+
+// windowActive determines if a schedule window is open now and returns its
+// remaining duration
+func (m *Manager) windowActive(name, timezone string, window config.ScheduleWindow) (bool, time.Duration) {
+	schedule, err := parseSchedule(window.Schedule, timezone)
 	if err != nil {
 		return false, 0
 	}
 
 	now := m.clock.Now()
 
-	lastScheduled := m.findLastScheduledOccurrence(schedule, now, config.Duration)
+	lastScheduled := m.findLastScheduledOccurrence(schedule, now, window.Duration)
 
 	// If we didn't find an occurrence (odd schedule), abort
 	if lastScheduled.IsZero() {
@@ -235,11 +391,12 @@ func (m *Manager) shouldStartNowWithDuration(config config.BridgeConfig) (bool,
 	}
 
 	// Compute scheduled window end and remaining duration
-	windowEnd := lastScheduled.Add(config.Duration)
+	windowEnd := lastScheduled.Add(window.Duration)
 	if now.Before(windowEnd) {
 		remainingDuration := windowEnd.Sub(now)
 		m.logger.Info("Detected missed schedule within window",
-			logger.String("name", config.Name),
+			logger.String("name", name),
+			logger.String("schedule", window.Schedule),
 			logger.Any("scheduled_at", lastScheduled),
 			logger.Any("window_ends", windowEnd),
 			logger.Any("now", now),
@@ -250,94 +407,196 @@ func (m *Manager) shouldStartNowWithDuration(config config.BridgeConfig) (bool,
 	return false, 0
 }
 
-// startScheduledBridge starts a bridge for its scheduled duration
-func (m *Manager) startScheduledBridge(name string, duration time.Duration) {
-	m.mu.RLock()
-	bridge, exists := m.bridges[name]
-	m.mu.RUnlock()
+// scheduledRun is the current scheduled run of a bridge. A window that opens
+// while the bridge is already linked for another window extends the run
+// rather than starting a second one, so overlapping windows neither unlink
+// the bridge early nor link it twice. A window whose schedule fires again
+// before its duration is up does not extend its own run.
+type scheduledRun struct {
+	start   time.Time
+	end     time.Time
+	timer   *time.Timer // Ends the run at end
+	windows []int       // Windows served by this run
+}
 
+// startScheduledBridge links a bridge for duration on behalf of one of its
+// schedule windows, extending the current run if the bridge is already linked
+func (m *Manager) startScheduledBridge(name string, window int, duration time.Duration) {
+	now := m.clock.Now()
+	end := now.Add(duration)
+
+	m.mu.Lock()
+	bridge, exists := m.bridges[name]
 	if !exists {
+		m.mu.Unlock()
 		m.logger.Error("Attempted to start unknown bridge", logger.String("name", name))
 		return
 	}
 
+	// A window firing again during its own run does not extend it
+	if run, ok := m.runs[name]; ok && containsWindow(run.windows, window) {
+		m.mu.Unlock()
+		m.logger.Debug("Scheduled bridge window already running",
+			logger.String("name", name),
+			logger.Int("window", window))
+		return
+	}
+
+	// Stop fails if the run is already ending; a new run is started then
+	if run, ok := m.runs[name]; ok && run.timer.Stop() {
+		run.windows = append(run.windows, window)
+		if end.After(run.end) {
+			run.end = end
+		}
+		run.timer.Reset(run.end.Sub(now))
+		ends := run.end
+		m.mu.Unlock()
+
+		m.logger.Info("Scheduled bridge already linked, extending run",
+			logger.String("name", name),
+			logger.Int("window", window),
+			logger.Any("ends", ends))
+		return
+	}
+
+	// Create a context for this bridge that ends with the manager or a reload,
+	// or when the run's timer fires
+	parent := m.ctx
+	if entry, ok := m.configured[name]; ok {
+		parent = entry.ctx
+	}
+	bridgeCtx, cancel := context.WithCancel(parent)
+	run := &scheduledRun{start: now, end: end, windows: []int{window}}
+	run.timer = time.AfterFunc(duration, func() {
+		m.mu.Lock()
+		if m.runs[name] == run {
+			delete(m.runs, name)
+		}
+		m.mu.Unlock()
+		cancel()
+	})
+	m.runs[name] = run
+	m.mu.Unlock()
+
 	m.logger.Info("Starting scheduled bridge",
 		logger.String("name", name),
+		logger.Int("window", window),
 		logger.Duration("duration", duration))
 
-	// Create an independent context for this bridge that won't affect the manager
-	// The bridge will manage its own timeout via RunScheduled's WithTimeout
-	bridgeCtx, cancel := context.WithCancel(context.Background())
-
-	// Run the bridge for the scheduled duration in a goroutine
-	go func() {
+	// Run the bridge until its run ends in a goroutine
+	m.goBridge(func() {
 		defer cancel() // Clean up context when bridge completes
 
-		bridge.RunScheduled(bridgeCtx, duration)
+		bridge.runWindow(bridgeCtx)
+
+		m.mu.Lock()
+		if m.runs[name] == run {
+			run.timer.Stop()
+			delete(m.runs, name)
+		}
+		windows := append([]int(nil), run.windows...)
+		m.mu.Unlock()
 
 		// After the bridge completes, update the next schedule time
-		m.updateScheduleExecution(name)
+		m.updateScheduleExecution(name, windows, run.start)
 
 		m.logger.Info("Scheduled bridge completed, next run scheduled",
 			logger.String("name", name))
-	}()
+	})
+}
+
+// containsWindow reports whether windows includes window
+func containsWindow(windows []int, window int) bool {
+	for _, w := range windows {
+		if w == window {
+			return true
+		}
+	}
+	return false
 }
 
-// updateScheduleExecution updates the schedule tracking information
-func (m *Manager) updateScheduleExecution(name string) {
+// updateScheduleExecution records a completed run of the given windows that
+// started at started and recalculates the next execution of every window of
+// the bridge
+func (m *Manager) updateScheduleExecution(name string, windows []int, started time.Time) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if schedInfo, exists := m.schedules[name]; exists {
-		now := m.clock.Now()
-		schedInfo.LastExecution = &now
+	schedInfo, exists := m.schedules[name]
+	if !exists {
+		return
+	}
+
+	now := m.clock.Now()
+	schedInfo.LastExecution = &now
+	schedInfo.LastDuration = now.Sub(started)
+	for i := range schedInfo.Windows {
+		w := &schedInfo.Windows[i]
+		if containsWindow(windows, i) {
+			last := now
+			w.LastExecution = &last
+			w.LastDuration = schedInfo.LastDuration
+		}
 
 		// Calculate next execution time
-		parser := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
-		if schedule, err := parser.Parse(schedInfo.Schedule); err == nil {
+		if schedule, err := parseSchedule(w.Schedule, schedInfo.Timezone); err == nil {
 			next := schedule.Next(now)
-			schedInfo.NextExecution = &next
-
-			// Update the bridge's nextSchedule field so it shows in status
-			if bridge, ok := m.bridges[name]; ok {
-				bridge.SetNextSchedule(&next)
-			}
+			w.NextExecution = &next
 		}
 	}
+	schedInfo.NextExecution = schedInfo.earliestNext()
+
+	// Update the bridge's nextSchedule field so it shows in status
+	if bridge, ok := m.bridges[name]; ok {
+		bridge.SetNextSchedule(schedInfo.NextExecution)
+	}
+}
+
+// missedWindow is a schedule window considered for missed recovery
+type missedWindow struct {
+	name     string
+	timezone string
+	window   WindowInfo
 }
 
-// checkMissedSchedules checks for schedules that should be running but aren't
+// checkMissedSchedules checks for schedule windows that should be running but aren't
 func (m *Manager) checkMissedSchedules() {
 	m.mu.RLock()
-	schedules := make([]*ScheduleInfo, 0, len(m.schedules))
+	var windows []missedWindow
 	for _, sched := range m.schedules {
-		schedules = append(schedules, sched)
+		for _, w := range sched.Windows {
+			windows = append(windows, missedWindow{name: sched.Name, timezone: sched.Timezone, window: w})
+		}
 	}
 	m.mu.RUnlock()
 
-	for _, schedInfo := range schedules {
-		if shouldRecover, remainingDuration := m.shouldRecoverScheduleWithDuration(schedInfo); shouldRecover {
+	for _, mw := range windows {
+		if shouldRecover, remainingDuration := m.shouldRecoverScheduleWithDuration(mw.name, mw.timezone, mw.window); shouldRecover {
 			m.logger.Info("Recovering missed schedule",
-				logger.String("name", schedInfo.Name),
-				logger.Any("last_execution", schedInfo.LastExecution),
+				logger.String("name", mw.name),
+				logger.Int("window", mw.window.Window),
+				logger.Any("last_execution", mw.window.LastExecution),
 				logger.Duration("remaining_duration", remainingDuration))
 
 			m.mu.Lock()
-			schedInfo.MissedWindows++
+			if sched, ok := m.schedules[mw.name]; ok && mw.window.Window < len(sched.Windows) {
+				sched.Windows[mw.window.Window].MissedWindows++
+				sched.MissedWindows++
+			}
 			m.stats.MissedSchedules++
 			m.mu.Unlock()
 
-			go m.startScheduledBridge(schedInfo.Name, remainingDuration)
+			go m.startScheduledBridge(mw.name, mw.window.Window, remainingDuration)
 		}
 	}
 }
 
 // NOTE: shouldRecoverSchedule was removed in favor of shouldRecoverScheduleWithDuration
 
-// shouldRecoverScheduleWithDuration determines if a schedule should be recovered and returns remaining duration
-func (m *Manager) shouldRecoverScheduleWithDuration(schedInfo *ScheduleInfo) (bool, time.Duration) {
-	parser := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
-	schedule, err := parser.Parse(schedInfo.Schedule)
+// shouldRecoverScheduleWithDuration determines if a schedule window should be
+// recovered and returns its remaining duration
+func (m *Manager) shouldRecoverScheduleWithDuration(name, timezone string, window WindowInfo) (bool, time.Duration) {
+	schedule, err := parseSchedule(window.Schedule, timezone)
 	if err != nil {
 		return false, 0
 	}
@@ -346,30 +605,29 @@ func (m *Manager) shouldRecoverScheduleWithDuration(schedInfo *ScheduleInfo) (bo
 
 	// If we have a last execution time, use it as reference
 	checkFrom := now.Add(-1 * time.Hour) // Default to 1 hour back
-	if schedInfo.LastExecution != nil {
-		checkFrom = *schedInfo.LastExecution
+	if window.LastExecution != nil {
+		checkFrom = *window.LastExecution
 	}
 
 	// Find the most recent scheduled time that we might have missed
-	lastScheduled := m.findLastScheduledOccurrence(schedule, now, schedInfo.Duration)
+	lastScheduled := m.findLastScheduledOccurrence(schedule, now, window.Duration)
 
 	// Check if we're within the duration window of a missed schedule
 	if lastScheduled.After(checkFrom) && lastScheduled.Before(now) {
-		windowEnd := lastScheduled.Add(schedInfo.Duration)
+		windowEnd := lastScheduled.Add(window.Duration)
 		if now.Before(windowEnd) {
-			// We should be running but aren't - check if bridge is actually active
+			// We should be running - check whether the current run, if any,
+			// already covers this window
 			m.mu.RLock()
-			bridge, exists := m.bridges[schedInfo.Name]
+			_, exists := m.bridges[name]
+			run, running := m.runs[name]
+			covered := running && (containsWindow(run.windows, window.Window) || !run.end.Before(windowEnd))
 			m.mu.RUnlock()
 
-			if exists {
-				status := bridge.GetStatus()
-				// Only recover if the bridge is not currently connected or scheduled
-				if status.State == StateDisconnected || status.State == StateFailed {
-					// Calculate remaining duration
-					remainingDuration := windowEnd.Sub(now)
-					return true, remainingDuration
-				}
+			if exists && !covered {
+				// Calculate remaining duration
+				remainingDuration := windowEnd.Sub(now)
+				return true, remainingDuration
 			}
 		}
 	}
@@ -409,8 +667,15 @@ func (m *Manager) findLastScheduledOccurrence(schedule cron.Schedule, now time.T
 // shouldBeActive was removed because it was unused; schedule checking is handled
 // by shouldStartNow and related helpers in this manager.
 
-// Stop stops all bridges and the scheduler
+// Stop stops all bridges and the scheduler, waiting briefly for bridges to unlink
 func (m *Manager) Stop() {
+	m.Shutdown(defaultShutdownTimeout)
+}
+
+// Shutdown stops the scheduler, cancels all bridges and waits up to timeout
+// for them to send their unlink packets and exit. It reports whether every
+// bridge exited in time.
+func (m *Manager) Shutdown(timeout time.Duration) bool {
 	m.logger.Info("Stopping bridge manager")
 
 	// Stop the scheduler
@@ -419,7 +684,34 @@ func (m *Manager) Stop() {
 	// Cancel all bridge contexts
 	m.cancel()
 
-	m.logger.Info("Bridge manager stopped")
+	done := make(chan struct{})
+	go func() {
+		m.running.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		m.saveUsage()
+		m.logger.Info("Bridge manager stopped")
+		return true
+	case <-timer.C:
+		m.saveUsage()
+		m.logger.Warn("Bridge manager stopped before all bridges unlinked",
+			logger.Duration("timeout", timeout))
+		return false
+	}
+}
+
+// goBridge runs a bridge goroutine tracked by Shutdown
+func (m *Manager) goBridge(fn func()) {
+	m.running.Add(1)
+	go func() {
+		defer m.running.Done()
+		fn()
+	}()
 }
 
 // GetStatus returns the status of all bridges
@@ -435,6 +727,43 @@ func (m *Manager) GetStatus() map[string]BridgeStatus {
 	return status
 }
 
+// GetSchedules returns a copy of all schedule tracking entries, sorted by bridge name
+func (m *Manager) GetSchedules() []ScheduleInfo {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	schedules := make([]ScheduleInfo, 0, len(m.schedules))
+	for _, sched := range m.schedules {
+		info := *sched
+		info.Windows = append([]WindowInfo(nil), sched.Windows...)
+		schedules = append(schedules, info)
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].Name < schedules[j].Name
+	})
+
+	return schedules
+}
+
+// GetStats returns aggregate bridge statistics
+func (m *Manager) GetStats() BridgeStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stats := BridgeStats{MissedSchedules: m.stats.MissedSchedules}
+	for _, bridge := range m.bridges {
+		status := bridge.GetStatus()
+		if status.State.Linked() {
+			stats.ActiveBridges++
+		} else if status.State == StateFailed {
+			stats.FailedBridges++
+		}
+		stats.TotalConnections += status.Connections
+	}
+
+	return stats
+}
+
 // GetBridge returns a bridge by name
 func (m *Manager) GetBridge(name string) *Bridge {
 	m.mu.RLock()
@@ -485,6 +814,41 @@ func (m *Manager) HandleIncomingPacket(data []byte, fromAddr *net.UDPAddr) {
 	}
 }
 
+// GetForwardAddresses returns the addresses of connected bridges that accept
+// local traffic, i.e. excluding receive-only bridges
+func (m *Manager) GetForwardAddresses() []*net.UDPAddr {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var addresses []*net.UDPAddr
+	for _, bridge := range m.bridges {
+		if bridge.IsConnected() && !bridge.IsRxOnly() {
+			if addr := bridge.GetRemoteAddr(); addr != nil {
+				addresses = append(addresses, addr)
+			}
+		}
+	}
+	return addresses
+}
+
+// GetForwardAddressesFor returns the addresses of connected bridges that
+// accept local traffic from callsign, honouring each bridge's callsign routes
+// and drains. It is called for each frame forwarded.
+func (m *Manager) GetForwardAddressesFor(callsign string) []*net.UDPAddr {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var addresses []*net.UDPAddr
+	for _, bridge := range m.bridges {
+		if bridge.IsConnected() && !bridge.IsRxOnly() && bridge.Routes(callsign) && bridge.forwarding(callsign) {
+			if addr := bridge.GetRemoteAddr(); addr != nil {
+				addresses = append(addresses, addr)
+			}
+		}
+	}
+	return addresses
+}
+
 // GetConnectedAddresses returns the addresses of all currently connected bridges
 func (m *Manager) GetConnectedAddresses() []*net.UDPAddr {
 	m.mu.RLock()
@@ -66,3 +66,35 @@ func Test_shouldStartNowWithDuration_Table(t *testing.T) {
 		})
 	}
 }
+
+func TestGetSchedulesAndStats(t *testing.T) {
+	l := logger.NewTestLogger(os.Stdout)
+	fake := &FakeClock{NowTime: time.Date(2025, 10, 3, 12, 0, 0, 0, time.UTC)}
+	mgr := NewManagerWithClock([]config.BridgeConfig{}, nil, l, fake)
+
+	mgr.setupScheduleTracking(config.BridgeConfig{Name: "b", Schedule: "0 0 * * * *", Duration: time.Minute})
+	mgr.setupScheduleTracking(config.BridgeConfig{Name: "a", Schedule: "0 30 * * * *", Duration: time.Minute})
+
+	mgr.mu.Lock()
+	mgr.schedules["a"].MissedWindows = 2
+	mgr.stats.MissedSchedules = 2
+	mgr.mu.Unlock()
+
+	schedules := mgr.GetSchedules()
+	if len(schedules) != 2 || schedules[0].Name != "a" || schedules[1].Name != "b" {
+		t.Fatalf("expected schedules sorted by name, got %+v", schedules)
+	}
+	if schedules[0].MissedWindows != 2 || schedules[0].NextExecution == nil {
+		t.Errorf("unexpected schedule info: %+v", schedules[0])
+	}
+
+	// Returned entries are copies
+	schedules[0].MissedWindows = 99
+	if mgr.GetSchedules()[0].MissedWindows != 2 {
+		t.Errorf("GetSchedules must return copies")
+	}
+
+	if stats := mgr.GetStats(); stats.MissedSchedules != 2 {
+		t.Errorf("expected 2 missed schedules, got %d", stats.MissedSchedules)
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"net"
 )
 
-// NetworkServer interface defines the methods required for bridge network operations
+// NetworkServer interface defines the methods required for bridge network
+// operations. Bridges share the reflector's server rather than opening sockets
+// of their own: they send through it, and the reflector taps its packet Mux to
+// hand them what linked reflectors send back (Manager.HandleIncomingPacket).
 type NetworkServer interface {
 	SendPacket(data []byte, addr *net.UDPAddr) error
 	GetListenAddress() *net.UDPAddr
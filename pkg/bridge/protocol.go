@@ -0,0 +1,137 @@
+package bridge
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// Reflector implementations keep their links alive differently. YSFReflector
+// expects a gateway-style YSFP poll every few seconds and answers each one, so
+// an unanswered poll is the sign of a dead link; it answers a YSFS with its
+// full status, which makes YSFS a poor keepalive. pYSFReflector polls the same
+// way but also sends traffic of its own, such as YSFV replies, all of which
+// shows the link is up. Other implementations, YSF Nexus among them, get the
+// original behavior: a YSFS keepalive every 30 seconds and a poll every
+// health_check.
+//
+// With protocol auto the bridge sends a YSFV version query when it links and
+// with its first polls. A reply naming pYSFReflector selects that variant,
+// any other reply selects generic, and poll replies without a version reply
+// select YSFReflector. Until then it polls like YSFReflector and counts any
+// packet as a sign of life. The detected variant is kept across reconnects.
+
+// protocolProfile is how a bridge keeps its link to one kind of reflector alive
+type protocolProfile struct {
+	// pollInterval is the YSFP poll cadence; 0 polls every health_check
+	pollInterval time.Duration
+	// statusKeepalive sends a YSFS every 30 seconds
+	statusKeepalive bool
+	// pollReply counts only YSFP replies as signs of life; otherwise any packet does
+	pollReply bool
+}
+
+// gatewayPollInterval is the poll cadence of YSFGateway, which reflectors expect
+const gatewayPollInterval = 5 * time.Second
+
+var protocolProfiles = map[string]protocolProfile{
+	config.BridgeProtocolYSFReflector:  {pollInterval: gatewayPollInterval, pollReply: true},
+	config.BridgeProtocolPYSFReflector: {pollInterval: gatewayPollInterval},
+	config.BridgeProtocolGeneric:       {statusKeepalive: true},
+}
+
+// detectingProfile is used while protocol auto has not decided yet
+var detectingProfile = protocolProfile{pollInterval: gatewayPollInterval}
+
+// Auto-detection limits: version queries sent, and poll replies without a
+// version reply that identify YSFReflector
+const (
+	maxVersionProbes  = 3
+	detectPollReplies = 3
+)
+
+// normalizeProtocol maps an empty protocol setting to auto
+func normalizeProtocol(protocol string) string {
+	if protocol == "" {
+		return config.BridgeProtocolAuto
+	}
+	return protocol
+}
+
+// profileLocked returns the keepalive profile in use (assumes mutex is locked)
+func (b *Bridge) profileLocked() protocolProfile {
+	if profile, ok := protocolProfiles[b.protocol]; ok {
+		return profile
+	}
+	return detectingProfile
+}
+
+// pollIntervalLocked returns the poll cadence (assumes mutex is locked)
+func (b *Bridge) pollIntervalLocked() time.Duration {
+	if interval := b.profileLocked().pollInterval; interval > 0 {
+		return interval
+	}
+	return b.config.HealthCheck
+}
+
+// lastAliveLocked returns when the remote last showed the link is up
+// (assumes mutex is locked)
+func (b *Bridge) lastAliveLocked() time.Time {
+	if b.profileLocked().pollReply {
+		return b.lastPollReply
+	}
+	return b.lastPacketTime
+}
+
+// detectingLocked reports whether protocol auto is still detecting
+// (assumes mutex is locked)
+func (b *Bridge) detectingLocked() bool {
+	return b.protocol == config.BridgeProtocolAuto
+}
+
+// sendVersionProbeLocked sends a YSFV query while detecting the protocol
+// (assumes mutex is locked)
+func (b *Bridge) sendVersionProbeLocked() {
+	if !b.detectingLocked() || b.versionProbes >= maxVersionProbes {
+		return
+	}
+	if err := b.sendPacketLocked([]byte("YSFV")); err != nil {
+		b.logger.Debug("Failed to send version query", logger.Error(err))
+		return
+	}
+	b.versionProbes++
+}
+
+// observeProtocolLocked detects the protocol from a packet received from the
+// remote (assumes mutex is locked)
+func (b *Bridge) observeProtocolLocked(data []byte) {
+	if !b.detectingLocked() {
+		return
+	}
+	switch {
+	case bytes.HasPrefix(data, []byte("YSFV")):
+		software := strings.TrimSpace(string(data[4:]))
+		if strings.Contains(strings.ToLower(software), "pysf") {
+			b.setProtocolLocked(config.BridgeProtocolPYSFReflector, software)
+		} else {
+			b.setProtocolLocked(config.BridgeProtocolGeneric, software)
+		}
+	case bytes.HasPrefix(data, []byte("YSFP")):
+		b.detectPolls++
+		if b.detectPolls >= detectPollReplies {
+			b.setProtocolLocked(config.BridgeProtocolYSFReflector, "")
+		}
+	}
+}
+
+// setProtocolLocked records the detected protocol (assumes mutex is locked)
+func (b *Bridge) setProtocolLocked(protocol, software string) {
+	b.protocol = protocol
+	b.logger.Info("Bridge protocol detected",
+		logger.String("bridge", b.config.Name),
+		logger.String("protocol", protocol),
+		logger.String("software", software))
+}
@@ -0,0 +1,112 @@
+package bridge
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// newProtocolBridge returns a linked bridge on a fake clock
+func newProtocolBridge(t *testing.T, protocol string) (*Bridge, *MockNetworkServer, *FakeClock) {
+	t.Helper()
+	server := &MockNetworkServer{}
+	b := NewBridge(config.BridgeConfig{
+		Name:        "DX",
+		Host:        "127.0.0.1",
+		Port:        42000,
+		HealthCheck: time.Minute,
+		Protocol:    protocol,
+	}, server, logger.NewTestLogger(io.Discard))
+	clk := &FakeClock{NowTime: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	b.setClock(clk)
+	b.remoteAddr = &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 42000}
+	b.state = StateConnected
+	b.lastPingTime = clk.Now() // As after the initial ping
+	return b, server, clk
+}
+
+func TestProtocolDetection(t *testing.T) {
+	tests := []struct {
+		name    string
+		replies []string
+		want    string
+	}{
+		{"pYSFReflector version reply", []string{"YSFPREFLECTOR ", "YSFVpYSFReflector3 20230801"}, config.BridgeProtocolPYSFReflector},
+		{"other version reply", []string{"YSFVYSF-Nexus v1.4.0"}, config.BridgeProtocolGeneric},
+		{"poll replies only", []string{"YSFPREFLECTOR ", "YSFPREFLECTOR ", "YSFPREFLECTOR "}, config.BridgeProtocolYSFReflector},
+		{"undecided", []string{"YSFPREFLECTOR "}, config.BridgeProtocolAuto},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, _, _ := newProtocolBridge(t, "")
+			for _, reply := range tt.replies {
+				b.OnPacketReceived([]byte(reply))
+			}
+			if got := b.GetStatus().Protocol; got != tt.want {
+				t.Errorf("Protocol = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// A configured protocol is never overridden
+	b, _, _ := newProtocolBridge(t, config.BridgeProtocolGeneric)
+	b.OnPacketReceived([]byte("YSFVpYSFReflector3"))
+	if got := b.GetStatus().Protocol; got != config.BridgeProtocolGeneric {
+		t.Errorf("Protocol = %q, want generic", got)
+	}
+}
+
+func TestProtocolPollCadence(t *testing.T) {
+	b, server, clk := newProtocolBridge(t, config.BridgeProtocolYSFReflector)
+	ctx := context.Background()
+
+	// Polls go out every 5 seconds even while unanswered, and no YSFV is sent
+	for i := 0; i < 3; i++ {
+		clk.Advance(gatewayPollInterval)
+		b.checkPingResponse(ctx)
+	}
+	if len(server.sentPackets) != 3 {
+		t.Fatalf("sent %d packets, want 3 polls", len(server.sentPackets))
+	}
+	for _, packet := range server.sentPackets {
+		if string(packet[:4]) != "YSFP" {
+			t.Errorf("sent %q, want a poll", packet)
+		}
+	}
+
+	// Only a poll reply answers a poll
+	b.OnPacketReceived([]byte("YSFS"))
+	if !b.awaitingPong {
+		t.Error("a non-poll packet must not count as a poll reply")
+	}
+	b.OnPacketReceived([]byte("YSFPREFLECTOR "))
+	if b.awaitingPong {
+		t.Error("poll reply must clear the outstanding poll")
+	}
+}
+
+func TestProtocolGenericKeepsHealthCheckCadence(t *testing.T) {
+	b, server, clk := newProtocolBridge(t, config.BridgeProtocolGeneric)
+	ctx := context.Background()
+
+	clk.Advance(gatewayPollInterval)
+	b.checkPingResponse(ctx)
+	if len(server.sentPackets) != 0 {
+		t.Fatalf("generic bridge polled before health_check elapsed")
+	}
+	clk.Advance(time.Minute)
+	b.checkPingResponse(ctx)
+	if len(server.sentPackets) != 1 {
+		t.Fatalf("sent %d packets, want 1 poll", len(server.sentPackets))
+	}
+	// Any packet answers it
+	b.OnPacketReceived([]byte("YSFD"))
+	if b.awaitingPong {
+		t.Error("any packet must count as a sign of life")
+	}
+}
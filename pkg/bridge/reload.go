@@ -0,0 +1,121 @@
+package bridge
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/robfig/cron/v3"
+)
+
+// configuredBridge is a bridge started from configuration. Cancelling its
+// context unlinks it; entries are its cron jobs, one per schedule window.
+type configuredBridge struct {
+	config  config.BridgeConfig
+	ctx     context.Context
+	cancel  context.CancelFunc
+	entries []cron.EntryID
+}
+
+// ReloadResult lists the bridges a reload changed, by name
+type ReloadResult struct {
+	Added     []string `json:"added"`
+	Removed   []string `json:"removed"`
+	Restarted []string `json:"restarted"`
+}
+
+// Configs returns the current bridge definitions
+func (m *Manager) Configs() []config.BridgeConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]config.BridgeConfig(nil), m.config...)
+}
+
+// Reload applies a new set of bridge definitions. Unchanged bridges keep
+// running, removed ones are unlinked, changed ones are restarted and new ones
+// started. Temporary bridges are left alone.
+func (m *Manager) Reload(configs []config.BridgeConfig) ReloadResult {
+	wanted := make(map[string]config.BridgeConfig)
+	for _, cfg := range configs {
+		if cfg.Enabled {
+			wanted[cfg.Name] = cfg
+		}
+	}
+
+	var result ReloadResult
+	var stop []*configuredBridge
+	var start []config.BridgeConfig
+
+	m.mu.Lock()
+	for name, entry := range m.configured {
+		cfg, keep := wanted[name]
+		if keep && reflect.DeepEqual(cfg, entry.config) {
+			continue
+		}
+		if keep {
+			result.Restarted = append(result.Restarted, name)
+		} else {
+			result.Removed = append(result.Removed, name)
+		}
+		stop = append(stop, entry)
+		delete(m.configured, name)
+		delete(m.bridges, name)
+		delete(m.schedules, name)
+		if run, ok := m.runs[name]; ok {
+			run.timer.Stop()
+			delete(m.runs, name)
+		}
+	}
+	for name, cfg := range wanted {
+		if _, running := m.configured[name]; running {
+			continue
+		}
+		if _, temporary := m.temporary[name]; temporary {
+			m.logger.Warn("Bridge name is in use by a temporary bridge; not started",
+				logger.String("name", name))
+			continue
+		}
+		if !contains(result.Restarted, name) {
+			result.Added = append(result.Added, name)
+		}
+		start = append(start, cfg)
+	}
+	m.config = append([]config.BridgeConfig(nil), configs...)
+	m.mu.Unlock()
+
+	for _, entry := range stop {
+		for _, id := range entry.entries {
+			m.cron.Remove(id)
+		}
+		entry.cancel()
+	}
+	sort.Slice(start, func(i, j int) bool { return start[i].Name < start[j].Name })
+	for _, cfg := range start {
+		if err := m.setupBridge(cfg); err != nil {
+			m.logger.Error("Failed to setup bridge",
+				logger.String("name", cfg.Name),
+				logger.Error(err))
+		}
+	}
+
+	sort.Strings(result.Added)
+	sort.Strings(result.Removed)
+	sort.Strings(result.Restarted)
+	m.logger.Info("Bridges reloaded",
+		logger.Int("added", len(result.Added)),
+		logger.Int("removed", len(result.Removed)),
+		logger.Int("restarted", len(result.Restarted)))
+	return result
+}
+
+// contains reports whether names includes name
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
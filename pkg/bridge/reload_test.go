@@ -0,0 +1,60 @@
+package bridge
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+func TestManagerReload(t *testing.T) {
+	l := logger.NewTestLogger(os.Stdout)
+	fake := &FakeClock{NowTime: time.Date(2025, 10, 3, 12, 0, 0, 0, time.UTC)}
+
+	// Daily at 03:00, well outside the fake clock's window
+	scheduled := func(name string) config.BridgeConfig {
+		return config.BridgeConfig{Name: name, Host: "localhost", Port: 42000, Enabled: true,
+			Schedule: "0 0 3 * * *", Duration: time.Hour}
+	}
+	mgr := NewManagerWithClock([]config.BridgeConfig{scheduled("keep"), scheduled("change"), scheduled("drop")}, &MockNetworkServer{}, l, fake)
+	if err := mgr.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer mgr.Stop()
+	if got := len(mgr.cron.Entries()); got != 3 {
+		t.Fatalf("expected 3 cron entries, got %d", got)
+	}
+	kept := mgr.GetBridge("keep")
+
+	changed := scheduled("change")
+	changed.Port = 42001
+	disabled := scheduled("off")
+	disabled.Enabled = false
+	result := mgr.Reload([]config.BridgeConfig{scheduled("keep"), changed, scheduled("new"), disabled})
+
+	if len(result.Added) != 1 || result.Added[0] != "new" ||
+		len(result.Removed) != 1 || result.Removed[0] != "drop" ||
+		len(result.Restarted) != 1 || result.Restarted[0] != "change" {
+		t.Fatalf("unexpected reload result %+v", result)
+	}
+	if mgr.GetBridge("keep") != kept {
+		t.Errorf("expected an unchanged bridge to keep running")
+	}
+	if mgr.GetBridge("drop") != nil || mgr.GetBridge("off") != nil {
+		t.Errorf("expected removed and disabled bridges to be absent")
+	}
+	if b := mgr.GetBridge("change"); b == nil || b.config.Port != 42001 {
+		t.Errorf("expected the changed bridge to use its new definition")
+	}
+	if got := len(mgr.cron.Entries()); got != 3 {
+		t.Errorf("expected 3 cron entries after reload, got %d", got)
+	}
+	if got := len(mgr.GetSchedules()); got != 3 {
+		t.Errorf("expected 3 schedules after reload, got %d", got)
+	}
+	if got := len(mgr.Configs()); got != 4 {
+		t.Errorf("expected the new definitions to be kept, got %d", got)
+	}
+}
@@ -0,0 +1,203 @@
+package bridge
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// scheduleParser parses six-field (with seconds) cron expressions and descriptors
+var scheduleParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// scheduleSpec returns the cron spec with the time zone prefix applied, if any
+func scheduleSpec(schedule, timezone string) string {
+	if timezone == "" {
+		return schedule
+	}
+	return "CRON_TZ=" + timezone + " " + schedule
+}
+
+// parseSchedule parses a bridge schedule in the given time zone (empty = server local time)
+func parseSchedule(schedule, timezone string) (cron.Schedule, error) {
+	return scheduleParser.Parse(scheduleSpec(schedule, timezone))
+}
+
+// ScheduleRun is one planned run of a scheduled bridge
+type ScheduleRun struct {
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Window int       `json:"window"` // Index of the window the run belongs to
+}
+
+// SchedulePreview describes a bridge schedule and its upcoming runs. Schedule,
+// Description and Duration are those of the first window; Runs merges the
+// runs of all windows in start order.
+type SchedulePreview struct {
+	Name        string        `json:"name"`
+	Schedule    string        `json:"schedule"`
+	Timezone    string        `json:"timezone,omitempty"`
+	Description string        `json:"description"`
+	Duration    time.Duration `json:"duration"`
+	Windows     []WindowInfo  `json:"windows"`
+	Runs        []ScheduleRun `json:"runs"`
+}
+
+// PreviewSchedule returns the next count planned runs of a scheduled bridge
+// across all of its windows
+func (m *Manager) PreviewSchedule(name string, count int) (SchedulePreview, error) {
+	m.mu.RLock()
+	sched, ok := m.schedules[name]
+	var info ScheduleInfo
+	if ok {
+		info = *sched
+		info.Windows = append([]WindowInfo(nil), sched.Windows...)
+	}
+	m.mu.RUnlock()
+
+	if !ok {
+		return SchedulePreview{}, fmt.Errorf("no scheduled bridge named %s", name)
+	}
+
+	preview := SchedulePreview{
+		Name:        info.Name,
+		Schedule:    info.Schedule,
+		Timezone:    info.Timezone,
+		Description: DescribeSchedule(info.Schedule),
+		Duration:    info.Duration,
+		Windows:     info.Windows,
+		Runs:        make([]ScheduleRun, 0, count),
+	}
+
+	now := m.clock.Now()
+	for _, w := range info.Windows {
+		schedule, err := parseSchedule(w.Schedule, info.Timezone)
+		if err != nil {
+			return SchedulePreview{}, fmt.Errorf("invalid schedule for bridge %s: %w", name, err)
+		}
+
+		// The first count runs of each window include the first count overall
+		next := now
+		for i := 0; i < count; i++ {
+			next = schedule.Next(next)
+			if next.IsZero() {
+				break
+			}
+			preview.Runs = append(preview.Runs, ScheduleRun{Start: next, End: next.Add(w.Duration), Window: w.Window})
+		}
+	}
+
+	sort.SliceStable(preview.Runs, func(i, j int) bool {
+		return preview.Runs[i].Start.Before(preview.Runs[j].Start)
+	})
+	if len(preview.Runs) > count {
+		preview.Runs = preview.Runs[:count]
+	}
+
+	return preview, nil
+}
+
+var (
+	weekdayNames = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+	monthNames   = []string{"", "January", "February", "March", "April", "May", "June",
+		"July", "August", "September", "October", "November", "December"}
+)
+
+// DescribeSchedule returns a short English description of a six-field cron
+// expression or descriptor, e.g. "0 0 20 * * 6" -> "at 20:00:00, on Saturday".
+// Expressions it cannot describe are returned unchanged.
+func DescribeSchedule(spec string) string {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "@") {
+		switch spec {
+		case "@yearly", "@annually":
+			return "at 00:00:00 on January 1"
+		case "@monthly":
+			return "at 00:00:00 on day 1 of every month"
+		case "@weekly":
+			return "at 00:00:00 every Sunday"
+		case "@daily", "@midnight":
+			return "at 00:00:00 every day"
+		case "@hourly":
+			return "at the start of every hour"
+		}
+		if every := strings.TrimPrefix(spec, "@every "); every != spec {
+			return "every " + every
+		}
+		return spec
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 6 {
+		return spec
+	}
+	sec, min, hour, dom, month, dow := fields[0], fields[1], fields[2], fields[3], fields[4], fields[5]
+
+	var parts []string
+	s, errS := strconv.Atoi(sec)
+	mi, errM := strconv.Atoi(min)
+	h, errH := strconv.Atoi(hour)
+	if errS == nil && errM == nil && errH == nil {
+		parts = append(parts, fmt.Sprintf("at %02d:%02d:%02d", h, mi, s))
+	} else {
+		parts = append(parts, describeField(hour, "hour", nil),
+			describeField(min, "minute", nil),
+			describeField(sec, "second", nil))
+	}
+
+	anyDom := dom == "*" || dom == "?"
+	anyDow := dow == "*" || dow == "?"
+	switch {
+	case anyDom && anyDow:
+		parts = append(parts, "every day")
+	case !anyDow:
+		parts = append(parts, "on "+describeList(dow, weekdayNames))
+	default:
+		parts = append(parts, "on day "+describeList(dom, nil)+" of the month")
+	}
+
+	if month != "*" && month != "?" {
+		parts = append(parts, "in "+describeList(month, monthNames))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// describeField describes a single time field, e.g. "*/6" -> "every 6 hours"
+func describeField(field, unit string, names []string) string {
+	switch {
+	case field == "*" || field == "?":
+		return "every " + unit
+	case strings.HasPrefix(field, "*/"):
+		return "every " + strings.TrimPrefix(field, "*/") + " " + unit + "s"
+	default:
+		return unit + " " + describeList(field, names)
+	}
+}
+
+// describeList renders comma lists and ranges, mapping numbers to names when given
+func describeList(field string, names []string) string {
+	items := strings.Split(field, ",")
+	for i, item := range items {
+		if lo, hi, ok := strings.Cut(item, "-"); ok {
+			items[i] = nameFor(lo, names) + " through " + nameFor(hi, names)
+		} else {
+			items[i] = nameFor(item, names)
+		}
+	}
+	if len(items) == 1 {
+		return items[0]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
+
+// nameFor maps a numeric cron value to a name when a table is given
+func nameFor(value string, names []string) string {
+	if n, err := strconv.Atoi(value); err == nil && n >= 0 && n < len(names) && names[n] != "" {
+		return names[n]
+	}
+	return value
+}
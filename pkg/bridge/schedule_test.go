@@ -0,0 +1,196 @@
+package bridge
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+func TestDescribeSchedule(t *testing.T) {
+	tests := map[string]string{
+		"0 0 20 * * 6":      "at 20:00:00, on Saturday",
+		"0 30 8 * * 1-5":    "at 08:30:00, on Monday through Friday",
+		"0 0 */6 * * *":     "every 6 hours, minute 0, second 0, every day",
+		"0 0 12 1 1,7 *":    "at 12:00:00, on day 1 of the month, in January and July",
+		"@daily":            "at 00:00:00 every day",
+		"@every 90m":        "every 90m",
+		"not a cron string": "not a cron string",
+	}
+	for spec, want := range tests {
+		if got := DescribeSchedule(spec); got != want {
+			t.Errorf("DescribeSchedule(%q) = %q, want %q", spec, got, want)
+		}
+	}
+}
+
+func TestPreviewScheduleInTimezone(t *testing.T) {
+	l := logger.NewTestLogger(os.Stdout)
+	now := time.Date(2025, 10, 3, 12, 0, 0, 0, time.UTC) // Friday
+	mgr := NewManagerWithClock(nil, nil, l, &FakeClock{NowTime: now})
+
+	mgr.setupScheduleTracking(config.BridgeConfig{
+		Name:     "net",
+		Schedule: "0 0 20 * * 6", // Saturdays at 20:00 local
+		Duration: time.Hour,
+		Timezone: "America/New_York",
+	})
+
+	preview, err := mgr.PreviewSchedule("net", 5)
+	if err != nil {
+		t.Fatalf("preview failed: %v", err)
+	}
+	if len(preview.Runs) != 5 {
+		t.Fatalf("expected 5 runs, got %d", len(preview.Runs))
+	}
+
+	// 20:00 EDT is 00:00 UTC on Sunday
+	first := preview.Runs[0]
+	if want := time.Date(2025, 10, 5, 0, 0, 0, 0, time.UTC); !first.Start.Equal(want) {
+		t.Errorf("first run %v, want %v", first.Start.UTC(), want)
+	}
+	if first.End.Sub(first.Start) != time.Hour {
+		t.Errorf("expected run to last the bridge duration")
+	}
+	if preview.Description != "at 20:00:00, on Saturday" {
+		t.Errorf("unexpected description %q", preview.Description)
+	}
+
+	if _, err := mgr.PreviewSchedule("missing", 5); err == nil {
+		t.Errorf("expected error for unknown bridge")
+	}
+}
+
+func TestScheduleWindows(t *testing.T) {
+	l := logger.NewTestLogger(os.Stdout)
+	now := time.Date(2025, 10, 3, 19, 30, 0, 0, time.UTC) // Friday
+	fake := &FakeClock{NowTime: now}
+	mgr := NewManagerWithClock(nil, nil, l, fake)
+
+	cfg := config.BridgeConfig{
+		Name:     "sun",
+		Schedule: "0 0 19 * * 1-5", // Weekday evenings
+		Duration: 2 * time.Hour,
+		Windows: []config.ScheduleWindow{
+			{Schedule: "0 0 8 * * 0,6", Duration: 3 * time.Hour}, // Weekend mornings
+		},
+	}
+	mgr.setupScheduleTracking(cfg)
+
+	schedules := mgr.GetSchedules()
+	if len(schedules) != 1 || len(schedules[0].Windows) != 2 {
+		t.Fatalf("expected one bridge with two windows, got %+v", schedules)
+	}
+	weekend := schedules[0].Windows[1]
+	if weekend.Duration != 3*time.Hour || weekend.NextExecution == nil ||
+		!weekend.NextExecution.Equal(time.Date(2025, 10, 4, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected weekend window %+v", weekend)
+	}
+	// Saturday morning comes before Monday evening
+	if next := schedules[0].NextExecution; next == nil || !next.Equal(*weekend.NextExecution) {
+		t.Errorf("expected next execution to be the soonest window, got %v", next)
+	}
+
+	preview, err := mgr.PreviewSchedule("sun", 4)
+	if err != nil {
+		t.Fatalf("preview failed: %v", err)
+	}
+	want := []struct {
+		start  time.Time
+		window int
+	}{
+		{time.Date(2025, 10, 4, 8, 0, 0, 0, time.UTC), 1},
+		{time.Date(2025, 10, 5, 8, 0, 0, 0, time.UTC), 1},
+		{time.Date(2025, 10, 6, 19, 0, 0, 0, time.UTC), 0},
+		{time.Date(2025, 10, 7, 19, 0, 0, 0, time.UTC), 0},
+	}
+	if len(preview.Runs) != len(want) || len(preview.Windows) != 2 {
+		t.Fatalf("unexpected preview %+v", preview)
+	}
+	for i, w := range want {
+		run := preview.Runs[i]
+		if !run.Start.Equal(w.start) || run.Window != w.window {
+			t.Errorf("run %d = %v (window %d), want %v (window %d)", i, run.Start, run.Window, w.start, w.window)
+		}
+	}
+	if preview.Runs[0].End.Sub(preview.Runs[0].Start) != 3*time.Hour {
+		t.Errorf("expected weekend runs to last their window's duration")
+	}
+
+	// Only the weekday window is open at 19:30 on a Friday
+	if active, remaining := mgr.windowActive(cfg.Name, "", cfg.ScheduleWindows()[0]); !active || remaining != 90*time.Minute {
+		t.Errorf("expected weekday window open for 90m, got %v %v", active, remaining)
+	}
+	if active, _ := mgr.windowActive(cfg.Name, "", cfg.ScheduleWindows()[1]); active {
+		t.Errorf("weekend window should not be open")
+	}
+	if start, remaining := mgr.shouldStartNowWithDuration(cfg); !start || remaining != 90*time.Minute {
+		t.Errorf("expected bridge to start for 90m, got %v %v", start, remaining)
+	}
+}
+
+func TestOverlappingWindowsExtendRun(t *testing.T) {
+	l := logger.NewTestLogger(os.Stdout)
+	now := time.Date(2025, 10, 4, 8, 30, 0, 0, time.UTC) // Saturday
+	fake := &FakeClock{NowTime: now}
+	mgr := NewManagerWithClock(nil, &MockNetworkServer{}, l, fake)
+	defer mgr.Stop()
+
+	cfg := config.BridgeConfig{
+		Name:     "overlap",
+		Host:     "localhost",
+		Port:     4200,
+		Enabled:  true,
+		Schedule: "0 0 8 * * *", // Daily 08:00 for an hour
+		Duration: time.Hour,
+		Windows: []config.ScheduleWindow{
+			{Schedule: "0 30 8 * * 6", Duration: 2 * time.Hour}, // Saturdays 08:30 for two hours
+		},
+	}
+	if err := mgr.setupBridge(cfg); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	// Both windows are open; recovery must link the bridge once, until the
+	// later window ends
+	deadline := time.Now().Add(time.Second)
+	for {
+		mgr.mu.RLock()
+		run := mgr.runs["overlap"]
+		var end time.Time
+		var windows int
+		if run != nil {
+			end, windows = run.end, len(run.windows)
+		}
+		mgr.mu.RUnlock()
+		if windows == 2 {
+			if want := now.Add(2 * time.Hour); !end.Equal(want) {
+				t.Errorf("run ends %v, want %v", end, want)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected one run serving both windows")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// A window covered by the current run is not recovered again, but one
+	// reaching past it is
+	fake.Advance(10 * time.Minute)
+	mgr.mu.RLock()
+	weekend := mgr.schedules["overlap"].Windows[1]
+	mgr.mu.RUnlock()
+	if recover, _ := mgr.shouldRecoverScheduleWithDuration("overlap", "", weekend); recover {
+		t.Errorf("window covered by the current run should not be recovered")
+	}
+	mgr.mu.Lock()
+	mgr.runs["overlap"].end = now.Add(time.Hour)
+	mgr.runs["overlap"].windows = []int{0}
+	mgr.mu.Unlock()
+	if recover, remaining := mgr.shouldRecoverScheduleWithDuration("overlap", "", weekend); !recover || remaining != 110*time.Minute {
+		t.Errorf("expected window to be recovered for 110m, got %v %v", recover, remaining)
+	}
+}
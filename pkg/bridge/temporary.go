@@ -0,0 +1,91 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// MaxTemporaryDuration caps how long a temporary link may stay up
+const MaxTemporaryDuration = 24 * time.Hour
+
+// StartTemporary brings up a bridge for a limited time. The bridge is created
+// at runtime, reported as temporary in its status, and removed again when
+// the duration expires or StopTemporary is called.
+func (m *Manager) StartTemporary(cfg config.BridgeConfig, duration time.Duration) (BridgeStatus, error) {
+	if cfg.Name == "" || cfg.Host == "" || cfg.Port <= 0 || cfg.Port > 65535 {
+		return BridgeStatus{}, fmt.Errorf("temporary bridge requires a name, host and valid port")
+	}
+	if _, known := protocolProfiles[cfg.Protocol]; !known && normalizeProtocol(cfg.Protocol) != config.BridgeProtocolAuto {
+		return BridgeStatus{}, fmt.Errorf("unknown bridge protocol %q", cfg.Protocol)
+	}
+	if duration <= 0 || duration > MaxTemporaryDuration {
+		return BridgeStatus{}, fmt.Errorf("temporary bridge duration must be positive and at most %v", MaxTemporaryDuration)
+	}
+
+	cfg.Enabled = true
+	cfg.Permanent = false
+	cfg.Schedule = ""
+	cfg.Duration = duration
+	bridge := m.newBridge(cfg)
+	bridge.markTemporary(m.clock.Now().Add(duration))
+
+	ctx, cancel := context.WithCancel(m.ctx)
+
+	m.mu.Lock()
+	if _, exists := m.bridges[cfg.Name]; exists {
+		m.mu.Unlock()
+		cancel()
+		return BridgeStatus{}, fmt.Errorf("bridge %s already exists", cfg.Name)
+	}
+	m.bridges[cfg.Name] = bridge
+	m.temporary[cfg.Name] = cancel
+	m.mu.Unlock()
+
+	m.logger.Info("Starting temporary bridge",
+		logger.String("name", cfg.Name),
+		logger.String("host", cfg.Host),
+		logger.Duration("duration", duration))
+
+	m.goBridge(func() {
+		defer cancel()
+		bridge.RunScheduled(ctx, duration)
+
+		m.mu.Lock()
+		if m.bridges[cfg.Name] == bridge {
+			delete(m.bridges, cfg.Name)
+			delete(m.temporary, cfg.Name)
+		}
+		m.mu.Unlock()
+
+		m.logger.Info("Temporary bridge removed", logger.String("name", cfg.Name))
+	})
+
+	return bridge.GetStatus(), nil
+}
+
+// StopTemporary tears down a temporary bridge before it expires
+func (m *Manager) StopTemporary(name string) error {
+	m.mu.RLock()
+	cancel, ok := m.temporary[name]
+	m.mu.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("no temporary bridge named %s", name)
+	}
+
+	m.logger.Info("Stopping temporary bridge", logger.String("name", name))
+	cancel()
+	return nil
+}
+
+// markTemporary flags the bridge as a runtime-created link that expires at the given time
+func (b *Bridge) markTemporary(expiresAt time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.temporary = true
+	b.expiresAt = &expiresAt
+}
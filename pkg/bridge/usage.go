@@ -0,0 +1,364 @@
+package bridge
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/persist"
+)
+
+// BridgeUsage is the cumulative usage of one bridge. Unlike the counters in
+// BridgeStatus it survives restarts when bridge_stats.file is set, so it can
+// be used for monthly usage reporting.
+type BridgeUsage struct {
+	Name          string    `json:"name"`
+	Since         time.Time `json:"since"` // When tracking started
+	PacketsRx     uint64    `json:"packets_rx"`
+	PacketsTx     uint64    `json:"packets_tx"`
+	BytesRx       uint64    `json:"bytes_rx"`
+	BytesTx       uint64    `json:"bytes_tx"`
+	Connections   uint64    `json:"connections"`
+	ConnectedTime int64     `json:"connected_time"` // in seconds
+	// Days are per-day aggregates in the reflector's local time, oldest first
+	Days []DailyUsage `json:"days"`
+	// History is the most recent connections, oldest first
+	History []ConnectionRecord `json:"history"`
+}
+
+// DailyUsage is the usage of a bridge on one day
+type DailyUsage struct {
+	Date          string `json:"date"` // YYYY-MM-DD
+	PacketsRx     uint64 `json:"packets_rx"`
+	PacketsTx     uint64 `json:"packets_tx"`
+	BytesRx       uint64 `json:"bytes_rx"`
+	BytesTx       uint64 `json:"bytes_tx"`
+	Connections   uint64 `json:"connections"`
+	ConnectedTime int64  `json:"connected_time"` // in seconds
+}
+
+// ConnectionRecord is one connection of a bridge. DisconnectedAt is nil while
+// the bridge is linked, or if the reflector stopped without unlinking it.
+type ConnectionRecord struct {
+	ConnectedAt    time.Time  `json:"connected_at"`
+	DisconnectedAt *time.Time `json:"disconnected_at,omitempty"`
+	Error          string     `json:"error,omitempty"` // Why the link was lost, if it failed
+}
+
+const dateLayout = "2006-01-02"
+
+// usageRecord is the usage of one bridge with its days indexed by date
+type usageRecord struct {
+	usage BridgeUsage
+	days  map[string]*DailyUsage
+	// today caches the current day so counting a packet needs no formatting
+	today    *DailyUsage
+	todayEnd time.Time
+	// connectedSince is when connected time was last accounted, zero while unlinked
+	connectedSince time.Time
+}
+
+// usageTracker accumulates bridge usage, optionally persisted to a JSON file
+type usageTracker struct {
+	mu      sync.Mutex
+	clock   Clock
+	bridges map[string]*usageRecord
+	path    string
+	// retentionDays is how many days of aggregates are kept (0 = all)
+	retentionDays int
+	// history is how many connections are kept per bridge
+	history int
+	dirty   bool
+}
+
+// defaultUsageHistory is how many connections are kept per bridge by default
+const defaultUsageHistory = 100
+
+func newUsageTracker(clock Clock) *usageTracker {
+	return &usageTracker{
+		clock:   clock,
+		bridges: make(map[string]*usageRecord),
+		history: defaultUsageHistory,
+	}
+}
+
+// recordLocked returns the usage of a bridge, creating it (assumes mutex is locked)
+func (u *usageTracker) recordLocked(name string) *usageRecord {
+	record, ok := u.bridges[name]
+	if !ok {
+		record = &usageRecord{
+			usage: BridgeUsage{Name: name, Since: u.clock.Now()},
+			days:  make(map[string]*DailyUsage),
+		}
+		u.bridges[name] = record
+	}
+	return record
+}
+
+// dayLocked returns the aggregate of the day containing at (assumes mutex is locked)
+func (r *usageRecord) dayLocked(at time.Time) *DailyUsage {
+	if r.today != nil && at.Before(r.todayEnd) && !at.Before(r.todayEnd.AddDate(0, 0, -1)) {
+		return r.today
+	}
+	date := at.Format(dateLayout)
+	day, ok := r.days[date]
+	if !ok {
+		day = &DailyUsage{Date: date}
+		r.days[date] = day
+	}
+	r.today = day
+	r.todayEnd = nextMidnight(at)
+	return day
+}
+
+// nextMidnight returns the start of the day after at, in at's location
+func nextMidnight(at time.Time) time.Time {
+	year, month, day := at.Date()
+	return time.Date(year, month, day+1, 0, 0, 0, 0, at.Location())
+}
+
+// addConnectedLocked accounts connected time up to now, split across the days
+// it spans (assumes mutex is locked)
+func (r *usageRecord) addConnectedLocked(now time.Time) {
+	from := r.connectedSince
+	if from.IsZero() || !now.After(from) {
+		return
+	}
+	for from.Before(now) {
+		end := nextMidnight(from)
+		if end.After(now) {
+			end = now
+		}
+		seconds := int64(end.Sub(from).Seconds())
+		r.dayLocked(from).ConnectedTime += seconds
+		r.usage.ConnectedTime += seconds
+		from = end
+	}
+	// Keep the fraction of a second for the next call
+	r.connectedSince = now.Add(-now.Sub(r.connectedSince) % time.Second)
+}
+
+// addRx counts a packet received over a bridge
+func (u *usageTracker) addRx(name string, bytes uint64) {
+	if u == nil {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	record := u.recordLocked(name)
+	day := record.dayLocked(u.clock.Now())
+	record.usage.PacketsRx++
+	record.usage.BytesRx += bytes
+	day.PacketsRx++
+	day.BytesRx += bytes
+	u.dirty = true
+}
+
+// addTx counts a packet sent over a bridge
+func (u *usageTracker) addTx(name string, bytes uint64) {
+	if u == nil {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	record := u.recordLocked(name)
+	day := record.dayLocked(u.clock.Now())
+	record.usage.PacketsTx++
+	record.usage.BytesTx += bytes
+	day.PacketsTx++
+	day.BytesTx += bytes
+	u.dirty = true
+}
+
+// connected records a bridge linking at the given time
+func (u *usageTracker) connected(name string, at time.Time) {
+	if u == nil {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	record := u.recordLocked(name)
+	record.addConnectedLocked(at)
+	record.connectedSince = at
+	record.usage.Connections++
+	record.dayLocked(at).Connections++
+	record.usage.History = append(record.usage.History, ConnectionRecord{ConnectedAt: at})
+	if u.history > 0 && len(record.usage.History) > u.history {
+		record.usage.History = append([]ConnectionRecord(nil), record.usage.History[len(record.usage.History)-u.history:]...)
+	}
+	u.dirty = true
+}
+
+// disconnected records a linked bridge unlinking at the given time, with the
+// error that caused it if any
+func (u *usageTracker) disconnected(name string, at time.Time, reason string) {
+	if u == nil {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	record := u.recordLocked(name)
+	record.addConnectedLocked(at)
+	record.connectedSince = time.Time{}
+	if n := len(record.usage.History); n > 0 && record.usage.History[n-1].DisconnectedAt == nil {
+		record.usage.History[n-1].DisconnectedAt = &at
+		record.usage.History[n-1].Error = reason
+	}
+	u.dirty = true
+}
+
+// get returns the usage of a bridge with its last days of aggregates (0 = all)
+func (u *usageTracker) get(name string, days int) (BridgeUsage, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	record, ok := u.bridges[name]
+	if !ok {
+		return BridgeUsage{}, false
+	}
+	record.addConnectedLocked(u.clock.Now())
+	return record.snapshotLocked(days), true
+}
+
+// snapshotLocked copies the usage with its last days of aggregates, 0 = all
+// (assumes mutex is locked)
+func (r *usageRecord) snapshotLocked(days int) BridgeUsage {
+	usage := r.usage
+	usage.Days = make([]DailyUsage, 0, len(r.days))
+	for _, day := range r.days {
+		usage.Days = append(usage.Days, *day)
+	}
+	sort.Slice(usage.Days, func(i, j int) bool { return usage.Days[i].Date < usage.Days[j].Date })
+	if days > 0 && len(usage.Days) > days {
+		usage.Days = usage.Days[len(usage.Days)-days:]
+	}
+	usage.History = append([]ConnectionRecord{}, r.usage.History...)
+	return usage
+}
+
+// pruneLocked drops aggregates older than the retention (assumes mutex is locked)
+func (u *usageTracker) pruneLocked(now time.Time) {
+	if u.retentionDays <= 0 {
+		return
+	}
+	oldest := now.AddDate(0, 0, -u.retentionDays+1).Format(dateLayout)
+	for _, record := range u.bridges {
+		for date := range record.days {
+			if date < oldest {
+				delete(record.days, date)
+				u.dirty = true
+			}
+		}
+		if record.today != nil && record.today.Date < oldest {
+			record.today = nil
+		}
+	}
+}
+
+// load reads persisted usage; a missing file is not an error
+func (u *usageTracker) load() error {
+	if u.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(u.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var entries []BridgeUsage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", u.path, err)
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for _, entry := range entries {
+		record := &usageRecord{usage: entry, days: make(map[string]*DailyUsage, len(entry.Days))}
+		for i := range entry.Days {
+			record.days[entry.Days[i].Date] = &entry.Days[i]
+		}
+		record.usage.Days = nil
+		u.bridges[entry.Name] = record
+	}
+	return nil
+}
+
+// save writes the usage if it changed since the last save
+func (u *usageTracker) save() error {
+	if u.path == "" {
+		return nil
+	}
+
+	u.mu.Lock()
+	now := u.clock.Now()
+	for _, record := range u.bridges {
+		if !record.connectedSince.IsZero() {
+			record.addConnectedLocked(now)
+			u.dirty = true
+		}
+	}
+	u.pruneLocked(now)
+	if !u.dirty {
+		u.mu.Unlock()
+		return nil
+	}
+	entries := make([]BridgeUsage, 0, len(u.bridges))
+	for _, record := range u.bridges {
+		entries = append(entries, record.snapshotLocked(0))
+	}
+	u.dirty = false
+	u.mu.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	return persist.WriteFile(u.path, ".bridge-stats-*.json", data)
+}
+
+// SetUsageStore persists bridge usage to a JSON file, keeping retentionDays
+// of daily aggregates (0 = all) and the last history connections of each
+// bridge, and loads what was saved before. It must be called before Start.
+func (m *Manager) SetUsageStore(path string, retentionDays, history int) error {
+	m.usage.mu.Lock()
+	m.usage.path = path
+	m.usage.retentionDays = retentionDays
+	if history > 0 {
+		m.usage.history = history
+	}
+	m.usage.mu.Unlock()
+	return m.usage.load()
+}
+
+// GetUsage returns the cumulative usage of a bridge with its last days of
+// daily aggregates (0 = all). Bridges that no longer exist keep their usage.
+func (m *Manager) GetUsage(name string, days int) (BridgeUsage, bool) {
+	return m.usage.get(name, days)
+}
+
+// saveUsage writes bridge usage, logging failures
+func (m *Manager) saveUsage() {
+	if err := m.usage.save(); err != nil {
+		m.logger.Error("Failed to save bridge statistics", logger.Error(err))
+	}
+}
+
+// runUsagePersistence saves bridge usage every minute until the manager stops;
+// Shutdown saves it once more after the bridges unlink
+func (m *Manager) runUsagePersistence() {
+	persist.Every(m.ctx, time.Minute, m.saveUsage)
+}
@@ -0,0 +1,141 @@
+package bridge
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+func TestUsageTrackerDailyAggregates(t *testing.T) {
+	clk := &FakeClock{NowTime: time.Date(2025, 3, 1, 23, 0, 0, 0, time.UTC)}
+	u := newUsageTracker(clk)
+
+	u.connected("DX", clk.Now())
+	u.addRx("DX", 155)
+	u.addTx("DX", 155)
+	u.addTx("DX", 14)
+
+	// Crosses midnight: the connected time is split across both days
+	clk.NowTime = clk.NowTime.Add(90 * time.Minute)
+	u.addRx("DX", 155)
+	u.disconnected("DX", clk.Now(), "connection timeout")
+
+	usage, ok := u.get("DX", 0)
+	if !ok {
+		t.Fatal("expected usage for DX")
+	}
+	if usage.PacketsRx != 2 || usage.PacketsTx != 2 || usage.BytesRx != 310 || usage.BytesTx != 169 {
+		t.Errorf("totals = %+v", usage)
+	}
+	if usage.Connections != 1 || usage.ConnectedTime != 5400 {
+		t.Errorf("connections = %d, connected time = %d", usage.Connections, usage.ConnectedTime)
+	}
+	if len(usage.Days) != 2 {
+		t.Fatalf("expected 2 days, got %+v", usage.Days)
+	}
+	first, second := usage.Days[0], usage.Days[1]
+	if first.Date != "2025-03-01" || first.PacketsRx != 1 || first.PacketsTx != 2 || first.Connections != 1 || first.ConnectedTime != 3600 {
+		t.Errorf("first day = %+v", first)
+	}
+	if second.Date != "2025-03-02" || second.PacketsRx != 1 || second.Connections != 0 || second.ConnectedTime != 1800 {
+		t.Errorf("second day = %+v", second)
+	}
+	if len(usage.History) != 1 || usage.History[0].DisconnectedAt == nil || usage.History[0].Error != "connection timeout" {
+		t.Errorf("history = %+v", usage.History)
+	}
+
+	if usage, _ := u.get("DX", 1); len(usage.Days) != 1 || usage.Days[0].Date != "2025-03-02" {
+		t.Errorf("last day = %+v", usage.Days)
+	}
+	if _, ok := u.get("Unknown", 0); ok {
+		t.Error("expected no usage for an unknown bridge")
+	}
+}
+
+func TestUsageTrackerPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bridge-stats.json")
+	clk := &FakeClock{NowTime: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)}
+
+	u := newUsageTracker(clk)
+	u.path = path
+	u.connected("DX", clk.Now())
+	u.addRx("DX", 155)
+	clk.NowTime = clk.NowTime.Add(10 * time.Minute)
+	if err := u.save(); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	// A restart: the counters and the still open connection are restored
+	restored := newUsageTracker(clk)
+	restored.path = path
+	if err := restored.load(); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	usage, ok := restored.get("DX", 0)
+	if !ok {
+		t.Fatal("expected restored usage for DX")
+	}
+	if usage.PacketsRx != 1 || usage.BytesRx != 155 || usage.Connections != 1 || usage.ConnectedTime != 600 {
+		t.Errorf("restored usage = %+v", usage)
+	}
+	if len(usage.Days) != 1 || usage.Days[0].ConnectedTime != 600 {
+		t.Errorf("restored days = %+v", usage.Days)
+	}
+
+	restored.addRx("DX", 155)
+	if usage, _ := restored.get("DX", 0); usage.PacketsRx != 2 || usage.Days[0].PacketsRx != 2 {
+		t.Errorf("usage after restart = %+v", usage)
+	}
+}
+
+func TestUsageTrackerRetention(t *testing.T) {
+	clk := &FakeClock{NowTime: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)}
+	u := newUsageTracker(clk)
+	u.path = filepath.Join(t.TempDir(), "bridge-stats.json")
+	u.retentionDays = 7
+
+	u.addRx("DX", 155)
+	clk.NowTime = clk.NowTime.AddDate(0, 0, 7)
+	u.addRx("DX", 155)
+	if err := u.save(); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	usage, _ := u.get("DX", 0)
+	if len(usage.Days) != 1 || usage.Days[0].Date != "2025-03-08" {
+		t.Errorf("days after retention = %+v", usage.Days)
+	}
+	// Cumulative totals are kept
+	if usage.PacketsRx != 2 {
+		t.Errorf("PacketsRx = %d, want 2", usage.PacketsRx)
+	}
+}
+
+func TestBridgeRecordsUsage(t *testing.T) {
+	clk := &FakeClock{NowTime: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)}
+	mgr := NewManagerWithClock(nil, &MockNetworkServer{}, logger.NewTestLogger(io.Discard), clk)
+	b := mgr.newBridge(config.BridgeConfig{Name: "DX", Host: "127.0.0.1", Port: 42000, Protocol: config.BridgeProtocolGeneric})
+
+	if err := b.connect(t.Context()); err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	b.IncrementRxStats(155)
+	clk.NowTime = clk.NowTime.Add(time.Minute)
+	b.disconnect()
+
+	usage, ok := mgr.GetUsage("DX", 0)
+	if !ok {
+		t.Fatal("expected usage for DX")
+	}
+	status := b.GetStatus()
+	if usage.PacketsRx != status.PacketsRx || usage.PacketsTx != status.PacketsTx || usage.BytesTx != status.BytesTx {
+		t.Errorf("usage %+v does not match bridge counters %+v", usage, status)
+	}
+	if usage.Connections != 1 || usage.ConnectedTime != 60 || len(usage.History) != 1 || usage.History[0].DisconnectedAt == nil {
+		t.Errorf("usage = %+v", usage)
+	}
+}
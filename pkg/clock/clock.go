@@ -0,0 +1,98 @@
+// Package clock abstracts time so schedules, retries and timeouts can be
+// driven by a fake clock in tests instead of sleeping.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the time and waits for durations to pass
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// Real uses the system clock
+type Real struct{}
+
+// Now returns time.Now
+func (Real) Now() time.Time { return time.Now() }
+
+// After returns time.After
+func (Real) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Fake is a manually advanced clock. NowTime may be set directly before the
+// clock is shared; afterwards use Set or Advance, which also fire any After
+// channels whose deadline has passed.
+type Fake struct {
+	mu      sync.Mutex
+	NowTime time.Time
+	waiters []fakeWaiter
+}
+
+// fakeWaiter is a pending After call
+type fakeWaiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// NewFake returns a fake clock set to now
+func NewFake(now time.Time) *Fake {
+	return &Fake{NowTime: now}
+}
+
+// Now returns the fake time
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.NowTime
+}
+
+// After returns a channel that receives the fake time once the clock has
+// been advanced by at least d
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- f.NowTime
+		return ch
+	}
+	f.waiters = append(f.waiters, fakeWaiter{deadline: f.NowTime.Add(d), ch: ch})
+	return ch
+}
+
+// Advance moves the clock forward by d
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	now := f.NowTime.Add(d)
+	f.mu.Unlock()
+	f.Set(now)
+}
+
+// Set moves the clock to now and fires the After channels that are due
+func (f *Fake) Set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.NowTime = now
+	pending := f.waiters[:0]
+	for _, w := range f.waiters {
+		if now.Before(w.deadline) {
+			pending = append(pending, w)
+			continue
+		}
+		w.ch <- now
+	}
+	f.waiters = pending
+}
+
+// Waiters returns the number of pending After calls, so tests can wait
+// until a goroutine is blocked on the clock before advancing it
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
@@ -0,0 +1,42 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeAfterFiresOnAdvance(t *testing.T) {
+	start := time.Date(2025, 10, 3, 12, 0, 0, 0, time.UTC)
+	f := NewFake(start)
+
+	ch := f.After(time.Minute)
+	if f.Waiters() != 1 {
+		t.Fatalf("expected one pending waiter")
+	}
+
+	f.Advance(30 * time.Second)
+	select {
+	case <-ch:
+		t.Fatalf("fired before the deadline")
+	default:
+	}
+
+	f.Advance(30 * time.Second)
+	select {
+	case got := <-ch:
+		if !got.Equal(start.Add(time.Minute)) {
+			t.Errorf("expected the fake time, got %v", got)
+		}
+	default:
+		t.Fatalf("expected the waiter to fire at the deadline")
+	}
+	if f.Waiters() != 0 {
+		t.Errorf("expected no pending waiters")
+	}
+
+	select {
+	case <-f.After(0):
+	default:
+		t.Errorf("expected a zero duration to fire immediately")
+	}
+}
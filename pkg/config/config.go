@@ -2,7 +2,11 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -17,6 +21,39 @@ type Config struct {
 	Blocklist BlocklistConfig `mapstructure:"blocklist"`
 	Logging   LoggingConfig   `mapstructure:"logging"`
 	Metrics   MetricsConfig   `mapstructure:"metrics"`
+
+	Maintenance MaintenanceConfig `mapstructure:"maintenance"`
+	Alerting    AlertingConfig    `mapstructure:"alerting"`
+	Mirror      MirrorConfig      `mapstructure:"mirror"`
+	Snapshot    SnapshotConfig    `mapstructure:"snapshot"`
+	SNMP        SNMPConfig        `mapstructure:"snmp"`
+	GeoIP       GeoIPConfig       `mapstructure:"geoip"`
+	Groups      []GroupConfig     `mapstructure:"groups"`
+	DMRIDs      DMRIDConfig       `mapstructure:"dmr_ids"`
+	Health      HealthConfig      `mapstructure:"health"`
+	ClockCheck  ClockCheckConfig  `mapstructure:"clock_check"`
+	Population  PopulationConfig  `mapstructure:"population"`
+	TalkExport  TalkExportConfig  `mapstructure:"talk_export"`
+	BridgeStats BridgeStatsConfig `mapstructure:"bridge_stats"`
+
+	// BridgeDampening suppresses the link state events of flapping bridges
+	BridgeDampening BridgeDampeningConfig `mapstructure:"bridge_dampening"`
+	// Demo generates simulated repeaters and traffic for previewing the dashboard
+	Demo DemoConfig `mapstructure:"demo"`
+
+	// ExternalLinks are other systems connected to the reflector, such as an
+	// AllStar node or EchoLink conference, shown alongside the bridges
+	ExternalLinks []ExternalLinkConfig `mapstructure:"external_links"`
+
+	// Features enables experimental subsystems by flag name (see features.go)
+	Features map[string]bool `mapstructure:"features"`
+
+	// BridgeIncludes are glob patterns of files with more bridge definitions,
+	// e.g. "bridges.d/*.yaml", relative to the config file
+	BridgeIncludes []string `mapstructure:"bridge_includes"`
+
+	// File is the config file that was loaded, if any
+	File string `mapstructure:"-"`
 }
 
 // ServerConfig holds YSF server configuration
@@ -27,11 +64,186 @@ type ServerConfig struct {
 	MaxConnections int           `mapstructure:"max_connections"`
 	Name           string        `mapstructure:"name"`
 	Description    string        `mapstructure:"description"`
+	// MaxConnectionsPerIP caps repeater entries from a single IP (0 = unlimited)
+	MaxConnectionsPerIP int `mapstructure:"max_connections_per_ip"`
+	// FollowPortChanges matches repeaters by callsign and IP, so a NAT that
+	// rewrites the source port moves the session instead of creating a new entry
+	FollowPortChanges bool `mapstructure:"follow_port_changes"`
+	// ReflectorID is the 5-digit ID registered on the YSF host list, announced in status replies
+	ReflectorID string `mapstructure:"reflector_id"`
+	// Branding is operator/club information shown on dashboards
+	Branding BrandingConfig `mapstructure:"branding"`
 	// TalkMaxDuration is the maximum continuous talk duration before muting a repeater
 	TalkMaxDuration time.Duration `mapstructure:"talk_max_duration"`
 	// UnmuteAfter is the duration after which a muted repeater will be automatically unmuted
 	// If zero, muted repeaters remain muted until they stop talking
 	UnmuteAfter time.Duration `mapstructure:"unmute_after"`
+	// TalkLimits overrides talk_max_duration and unmute_after per callsign
+	TalkLimits []TalkLimitConfig `mapstructure:"talk_limits"`
+	// AntiKerchunk auto-mutes callsigns that repeatedly key up briefly
+	AntiKerchunk AntiKerchunkConfig `mapstructure:"anti_kerchunk"`
+	// Listen lists host:port addresses for the YSF socket; overrides host and port when set
+	Listen []string `mapstructure:"listen"`
+	// BridgeTalkTimeout ends a bridge talker after this long without frames
+	BridgeTalkTimeout time.Duration `mapstructure:"bridge_talk_timeout"`
+	// SimultaneousBridgeStreams forwards frames from several bridges at once
+	// instead of letting the first bridge stream hold the channel
+	SimultaneousBridgeStreams bool `mapstructure:"simultaneous_bridge_streams"`
+	// TransmitQuietPeriod is how long the channel must be free before a queued
+	// announcement or playback is transmitted
+	TransmitQuietPeriod time.Duration `mapstructure:"transmit_quiet_period"`
+	// Keepalive sets when a repeater's poll cadence is reported as likely to
+	// lose its NAT mapping
+	Keepalive KeepaliveConfig `mapstructure:"keepalive"`
+	// StrictCallsignFields rejects data packets whose callsign fields are not
+	// space-padded printable ASCII instead of repairing them
+	StrictCallsignFields bool `mapstructure:"strict_callsign_fields"`
+	// StatusReplies controls who gets an answer to YSFS status requests
+	StatusReplies StatusRepliesConfig `mapstructure:"status_replies"`
+	// ListenOnly lists callsigns that may listen but whose transmissions are dropped
+	ListenOnly ListenOnlyConfig `mapstructure:"listen_only"`
+	// Hold lets net control reserve the channel for priority traffic
+	Hold HoldConfig `mapstructure:"hold"`
+	// BroadcastPriority sends frames to critical stations first
+	BroadcastPriority BroadcastPriorityConfig `mapstructure:"broadcast_priority"`
+	// DrainTimeout bounds how long shutdown waits for an active transmission
+	// to finish before terminating it and unlinking bridges
+	DrainTimeout time.Duration `mapstructure:"drain_timeout"`
+	// PacketVariants maps YSFV, YSFO and YSFI packets to an action
+	PacketVariants map[string]string `mapstructure:"packet_variants"`
+	// Welcome greets newly connected repeaters
+	Welcome WelcomeConfig `mapstructure:"welcome"`
+	// Password admits only repeaters that present a room password
+	Password PasswordConfig `mapstructure:"password"`
+	// LastHeardQuery answers Wires-X searches for a keyword with the last heard callsigns
+	LastHeardQuery LastHeardQueryConfig `mapstructure:"last_heard_query"`
+}
+
+// LastHeardQueryConfig lets radio users ask for recent activity: a Wires-X
+// room search for one of the queries is answered with a list of the last
+// heard callsigns. Gateways must pass Wires-X commands through to the
+// reflector (YSFGateway: WiresXCommandPassthrough=1).
+type LastHeardQueryConfig struct {
+	Enabled bool     `mapstructure:"enabled"`
+	Queries []string `mapstructure:"queries"` // Search texts answered, case-insensitive
+	Count   int      `mapstructure:"count"`   // Callsigns listed in the reply
+}
+
+// PasswordConfig holds the room password for inbound repeater connections.
+// It is off while Token is empty.
+type PasswordConfig struct {
+	// Token must follow the gateway callsign in a YSFP poll or appear in the
+	// options string of a YSFO packet, either bare or as pw=<token>
+	Token  string   `mapstructure:"token" secret:"true"`
+	Exempt []string `mapstructure:"exempt"` // Gateway callsign patterns admitted without the token
+}
+
+// WelcomeConfig sends an informational message to newly connected repeaters
+// and reports it as a repeater_welcome event
+type WelcomeConfig struct {
+	Enabled  bool     `mapstructure:"enabled"`
+	Message  string   `mapstructure:"message"`                // Go text/template; empty uses the built-in message
+	RulesURL string   `mapstructure:"rules_url"`              // Available to the template as {{.RulesURL}}
+	Webhooks []string `mapstructure:"webhooks" secret:"true"` // URLs that receive a JSON POST per welcome
+}
+
+// Packet variant actions
+const (
+	VariantLog    = "log"    // log and ignore (default)
+	VariantIgnore = "ignore" // count only
+	VariantReply  = "reply"  // answer a YSFV query with the software version
+)
+
+// VariantAction returns the action for a packet variant, defaulting to log.
+// Keys are matched case-insensitively since the config loader lowercases them.
+func (c ServerConfig) VariantAction(packetType string) string {
+	for key, action := range c.PacketVariants {
+		if strings.EqualFold(key, packetType) {
+			return action
+		}
+	}
+	return VariantLog
+}
+
+// ListenOnlyConfig holds the listen-only callsigns
+type ListenOnlyConfig struct {
+	Callsigns []string `mapstructure:"callsigns"`
+	Notify    bool     `mapstructure:"notify"` // emit a listen_only_dropped event per dropped transmission
+}
+
+// TalkLimitConfig overrides the talk limits for one talker or gateway
+// callsign. Unset limits keep the server-wide value.
+type TalkLimitConfig struct {
+	Callsign        string         `mapstructure:"callsign"`
+	TalkMaxDuration *time.Duration `mapstructure:"talk_max_duration"`
+	UnmuteAfter     *time.Duration `mapstructure:"unmute_after"` // 0 = muted until the station stops
+}
+
+// HoldConfig sets the limits of a net control hold, started through
+// /api/hold, during which only the listed callsigns may transmit
+type HoldConfig struct {
+	Duration    time.Duration `mapstructure:"duration"`     // Expiry when a hold is started without one
+	MaxDuration time.Duration `mapstructure:"max_duration"` // Longest hold that may be requested
+	Message     string        `mapstructure:"message"`      // Sent with hold_muted events; empty uses the built-in message
+}
+
+// BroadcastPriorityConfig orders broadcast destinations so that critical
+// listeners such as net control get frames with the least added delay
+type BroadcastPriorityConfig struct {
+	Callsigns      []string `mapstructure:"callsigns"`       // Gateway callsign patterns, highest priority first
+	MeasureLatency bool     `mapstructure:"measure_latency"` // Track per-destination send latency
+}
+
+// Status reply modes
+const (
+	StatusRepliesOpen  = "open"  // answer everyone
+	StatusRepliesKnown = "known" // answer connected repeaters, bridges and allowed addresses only
+	StatusRepliesOff   = "off"   // never answer
+)
+
+// StatusRepliesConfig restricts YSFS status responses for reflectors that
+// should not be discoverable
+type StatusRepliesConfig struct {
+	Mode    string   `mapstructure:"mode"`    // open (default), known or off
+	Allow   []string `mapstructure:"allow"`   // IPs or CIDRs answered in known mode
+	Reduced bool     `mapstructure:"reduced"` // omit description and connection count
+}
+
+// Allows reports whether ip is on the allow list
+func (c StatusRepliesConfig) Allows(ip net.IP) bool {
+	for _, entry := range c.Allow {
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if network.Contains(ip) {
+				return true
+			}
+		} else if allowed := net.ParseIP(entry); allowed != nil && allowed.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListenAddresses returns the YSF listen addresses, falling back to host and port
+func (c ServerConfig) ListenAddresses() []string {
+	return listenAddresses(c.Listen, c.Host, c.Port)
+}
+
+// AntiKerchunkConfig holds the automatic kerchunk muting policy
+type AntiKerchunkConfig struct {
+	Enabled               bool          `mapstructure:"enabled"`
+	MaxShortTransmissions int           `mapstructure:"max_short_transmissions"` // N short transmissions allowed...
+	ShortThreshold        time.Duration `mapstructure:"short_threshold"`         // ...shorter than X...
+	Window                time.Duration `mapstructure:"window"`                  // ...within Y
+	Cooldown              time.Duration `mapstructure:"cooldown"`                // How long offenders stay muted
+	Exempt                []string      `mapstructure:"exempt"`                  // Callsigns never auto-muted
+}
+
+// BrandingConfig holds operator branding shown on dashboards
+type BrandingConfig struct {
+	LogoURL      string `mapstructure:"logo_url"`
+	ContactEmail string `mapstructure:"contact_email"`
+	SponsorText  string `mapstructure:"sponsor_text"`
+	Website      string `mapstructure:"website"`
 }
 
 // WebConfig holds web dashboard configuration
@@ -41,7 +253,105 @@ type WebConfig struct {
 	Port         int    `mapstructure:"port"`
 	AuthRequired bool   `mapstructure:"auth_required"`
 	Username     string `mapstructure:"username"`
-	Password     string `mapstructure:"password"`
+	Password     string `mapstructure:"password" secret:"true"`
+	// Auth selects how users log in when auth_required is set
+	Auth AuthConfig `mapstructure:"auth"`
+	// CallsignStatsFile persists per-callsign statistics across restarts (empty = memory only)
+	CallsignStatsFile string `mapstructure:"callsign_stats_file"`
+	// HeatmapFile persists the activity heatmap across restarts (empty = memory only)
+	HeatmapFile string `mapstructure:"heatmap_file"`
+	// HeatmapWeeks is how many weeks of talk time the activity heatmap covers
+	HeatmapWeeks int `mapstructure:"heatmap_weeks"`
+	// Listen lists host:port addresses for the dashboard; overrides host and port when set
+	Listen []string `mapstructure:"listen"`
+	// Notifications pushes watch-list notifications to dashboard clients
+	Notifications NotificationConfig `mapstructure:"notifications"`
+	// IPMasking is how repeater IPs are shown to everyone but logged-in operators: full, partial or none
+	IPMasking string `mapstructure:"ip_masking"`
+}
+
+// AuthConfig selects the dashboard authentication backend. The local backend
+// checks the web username and password; proxy trusts the user and groups
+// headers set by a reverse proxy such as Authelia; oidc signs users in with
+// an OpenID Connect provider such as Keycloak. Proxy and OIDC users get the
+// role of the first group they belong to in AdminGroups or ViewerGroups,
+// otherwise DefaultRole, which denies them unless set.
+type AuthConfig struct {
+	Backend      string          `mapstructure:"backend"` // local, proxy or oidc
+	Proxy        ProxyAuthConfig `mapstructure:"proxy"`
+	OIDC         OIDCAuthConfig  `mapstructure:"oidc"`
+	AdminGroups  []string        `mapstructure:"admin_groups"`  // Groups whose members may change settings
+	ViewerGroups []string        `mapstructure:"viewer_groups"` // Groups whose members have read-only access
+	DefaultRole  string          `mapstructure:"default_role"`  // Role of users in none of the groups: admin, viewer or none (denied)
+}
+
+// ProxyAuthConfig holds trusted reverse-proxy header authentication
+type ProxyAuthConfig struct {
+	UserHeader   string `mapstructure:"user_header"`   // Header carrying the user name
+	GroupsHeader string `mapstructure:"groups_header"` // Header carrying the comma-separated groups
+	// TrustedProxies are the IPs or CIDRs whose headers are believed; requests
+	// from anywhere else are not authenticated
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+}
+
+// Trusts reports whether requests from ip may carry the user headers
+func (c ProxyAuthConfig) Trusts(ip net.IP) bool {
+	for _, entry := range c.TrustedProxies {
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if network.Contains(ip) {
+				return true
+			}
+		} else if trusted := net.ParseIP(entry); trusted != nil && trusted.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// OIDCAuthConfig holds the OpenID Connect provider users sign in with
+type OIDCAuthConfig struct {
+	Issuer        string   `mapstructure:"issuer"` // Issuer URL, discovery is read from /.well-known/openid-configuration
+	ClientID      string   `mapstructure:"client_id"`
+	ClientSecret  string   `mapstructure:"client_secret" secret:"true"`
+	RedirectURL   string   `mapstructure:"redirect_url"`   // e.g. https://dashboard.example.org/api/auth/oidc/callback
+	Scopes        []string `mapstructure:"scopes"`         // Requested scopes; openid is always included
+	UsernameClaim string   `mapstructure:"username_claim"` // Claim naming the user; sub when missing
+	GroupsClaim   string   `mapstructure:"groups_claim"`   // Claim listing the user's groups or roles
+}
+
+// Authentication backends
+const (
+	AuthBackendLocal = "local" // web username and password
+	AuthBackendProxy = "proxy" // trusted reverse-proxy headers
+	AuthBackendOIDC  = "oidc"  // OpenID Connect login
+)
+
+// Dashboard roles
+const (
+	RoleAdmin  = "admin"  // full access
+	RoleViewer = "viewer" // read-only access to protected endpoints
+	RoleNone   = "none"   // denied
+)
+
+// NotificationConfig holds the dashboard watch-list notification rules
+type NotificationConfig struct {
+	Enabled  bool     `mapstructure:"enabled"`
+	Events   []string `mapstructure:"events"`    // Event types that notify: talk_start, talk_end, connect, disconnect
+	Watch    []string `mapstructure:"watch"`     // Callsigns watched for every dashboard client
+	MaxWatch int      `mapstructure:"max_watch"` // Maximum callsigns on a session's own watch list
+}
+
+// ListenAddresses returns the dashboard listen addresses, falling back to host and port
+func (c WebConfig) ListenAddresses() []string {
+	return listenAddresses(c.Listen, c.Host, c.Port)
+}
+
+// listenAddresses returns listen, or host:port when listen is empty
+func listenAddresses(listen []string, host string, port int) []string {
+	if len(listen) > 0 {
+		return listen
+	}
+	return []string{net.JoinHostPort(host, strconv.Itoa(port))}
 }
 
 // BridgeConfig holds bridge connection configuration
@@ -56,6 +366,41 @@ type BridgeConfig struct {
 	MaxRetries  int           `mapstructure:"max_retries"`  // Max reconnection attempts (0 = infinite)
 	RetryDelay  time.Duration `mapstructure:"retry_delay"`  // Initial retry delay for exponential backoff
 	HealthCheck time.Duration `mapstructure:"health_check"` // How often to check connection health
+	Timezone    string        `mapstructure:"timezone"`     // IANA time zone for the schedule (default: server local time)
+	RxOnly      bool          `mapstructure:"rx_only"`      // Receive remote traffic but never forward local traffic
+	// Callsigns limits forwarded local traffic to these source callsigns
+	// (suffixes ignored); empty forwards everyone
+	Callsigns []string `mapstructure:"callsigns"`
+	// Windows adds schedule windows, each with its own duration, e.g.
+	// weekday evenings plus weekend mornings
+	Windows []ScheduleWindow `mapstructure:"windows"`
+	// Protocol selects the keepalive variant of the remote reflector
+	// software; empty or auto detects it after linking
+	Protocol string `mapstructure:"protocol"`
+}
+
+// Bridge protocol variants
+const (
+	BridgeProtocolAuto          = "auto"          // detect from the remote's replies
+	BridgeProtocolYSFReflector  = "ysfreflector"  // G4KLX YSFReflector
+	BridgeProtocolPYSFReflector = "pysfreflector" // pYSFReflector
+	BridgeProtocolGeneric       = "generic"       // other implementations, including YSF Nexus
+)
+
+// ScheduleWindow is one recurring window in which a scheduled bridge is linked
+type ScheduleWindow struct {
+	Schedule string        `mapstructure:"schedule"` // Six-field cron expression (with seconds)
+	Duration time.Duration `mapstructure:"duration"` // How long the bridge stays linked
+}
+
+// ScheduleWindows returns every schedule window of the bridge: the schedule
+// and duration settings first, if set, followed by the windows list
+func (b BridgeConfig) ScheduleWindows() []ScheduleWindow {
+	var windows []ScheduleWindow
+	if b.Schedule != "" {
+		windows = append(windows, ScheduleWindow{Schedule: b.Schedule, Duration: b.Duration})
+	}
+	return append(windows, b.Windows...)
 }
 
 // MQTTConfig holds MQTT client configuration
@@ -65,15 +410,17 @@ type MQTTConfig struct {
 	TopicPrefix string `mapstructure:"topic_prefix"`
 	ClientID    string `mapstructure:"client_id"`
 	Username    string `mapstructure:"username"`
-	Password    string `mapstructure:"password"`
+	Password    string `mapstructure:"password" secret:"true"`
 	QoS         byte   `mapstructure:"qos"`
 	Retained    bool   `mapstructure:"retained"`
 }
 
 // BlocklistConfig holds blocklist configuration
 type BlocklistConfig struct {
-	Enabled   bool     `mapstructure:"enabled"`
-	Callsigns []string `mapstructure:"callsigns"`
+	Enabled         bool          `mapstructure:"enabled"`
+	Callsigns       []string      `mapstructure:"callsigns"`
+	Subscriptions   []string      `mapstructure:"subscriptions"`    // Remote blocklist URLs, one callsign per line
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"` // How often remote blocklists are fetched
 }
 
 // LoggingConfig holds logging configuration
@@ -99,10 +446,213 @@ type PrometheusConfig struct {
 	Path    string `mapstructure:"path"`
 }
 
+// MaintenanceConfig holds the nightly maintenance scheduler configuration
+type MaintenanceConfig struct {
+	Enabled          bool          `mapstructure:"enabled"`
+	Schedule         string        `mapstructure:"schedule"`           // Cron expression with seconds, like bridge schedules
+	TaskTimeout      time.Duration `mapstructure:"task_timeout"`       // Upper bound for a single task
+	RotateLogs       bool          `mapstructure:"rotate_logs"`        // Rotate the log file (when logging.file is set)
+	TalkLogRetention time.Duration `mapstructure:"talk_log_retention"` // Prune talk log entries older than this (0 = keep)
+	EventRetention   time.Duration `mapstructure:"event_retention"`    // Prune replayable events older than this (0 = keep)
+	IPRetention      time.Duration `mapstructure:"ip_retention"`       // Blank IP addresses of events older than this (0 = keep)
+}
+
+// AlertingConfig holds threshold alert configuration
+type AlertingConfig struct {
+	Enabled  bool              `mapstructure:"enabled"`
+	Interval time.Duration     `mapstructure:"interval"`               // How often rules are evaluated
+	Webhooks []string          `mapstructure:"webhooks" secret:"true"` // URLs that receive a JSON POST on every transition
+	Email    EmailConfig       `mapstructure:"email"`
+	Rules    []AlertRuleConfig `mapstructure:"rules"`
+}
+
+// EmailConfig holds SMTP notification email for alert transitions and
+// reflector start/stop
+type EmailConfig struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	Host        string        `mapstructure:"host"`
+	Port        int           `mapstructure:"port"`
+	Username    string        `mapstructure:"username"` // Optional; enables SMTP auth
+	Password    string        `mapstructure:"password" secret:"true"`
+	From        string        `mapstructure:"from"`
+	To          []string      `mapstructure:"to"`
+	TLS         string        `mapstructure:"tls"`          // starttls, tls or none
+	Subject     string        `mapstructure:"subject"`      // Go text/template; empty uses the built-in subject
+	Body        string        `mapstructure:"body"`         // Go text/template; empty uses the built-in body
+	MinInterval time.Duration `mapstructure:"min_interval"` // Minimum time between emails for the same event
+	Lifecycle   bool          `mapstructure:"lifecycle"`    // Email when the reflector starts and stops
+}
+
+// AlertRuleConfig defines a single threshold alert rule
+type AlertRuleConfig struct {
+	Name      string        `mapstructure:"name"`
+	Metric    string        `mapstructure:"metric"`    // active_repeaters, bridges_down, packet_error_rate, websocket_drop_rate, clock_offset_ms
+	Target    string        `mapstructure:"target"`    // Optional, e.g. a bridge name for bridges_down
+	Operator  string        `mapstructure:"operator"`  // <, <=, >, >=, ==, !=
+	Threshold float64       `mapstructure:"threshold"` // Value compared against the metric
+	For       time.Duration `mapstructure:"for"`       // How long the condition must hold before firing
+}
+
+// MirrorConfig holds traffic mirroring to an external analysis sink
+type MirrorConfig struct {
+	Enabled     bool   `mapstructure:"enabled"`      // Mirror from startup; can be toggled at runtime via the API
+	Target      string `mapstructure:"target"`       // udp://host:port or unix:///path/to.sock
+	SampleEvery int    `mapstructure:"sample_every"` // Mirror 1 in N received YSFD frames (1 = all)
+	RateLimit   int    `mapstructure:"rate_limit"`   // Max mirrored frames per second (0 = unlimited)
+}
+
+// SnapshotConfig holds the periodic JSON stats snapshot for external consumers
+type SnapshotConfig struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	Path        string        `mapstructure:"path"`          // File the snapshot is written to (atomically replaced)
+	Interval    time.Duration `mapstructure:"interval"`      // How often the snapshot is written
+	TalkLogTail int           `mapstructure:"talk_log_tail"` // Most recent talk log entries to include
+}
+
+// BridgeStatsConfig holds the persistence of cumulative bridge usage
+type BridgeStatsConfig struct {
+	File          string `mapstructure:"file"`           // JSON file the usage is kept in (empty = memory only)
+	RetentionDays int    `mapstructure:"retention_days"` // Days of per-day aggregates to keep (0 = all)
+	History       int    `mapstructure:"history"`        // Connections to keep per bridge
+}
+
+// BridgeDampeningConfig holds the link state change dampening of bridges. A
+// bridge changing state more than Transitions times within Window is
+// reported as flapping once instead of on every change.
+type BridgeDampeningConfig struct {
+	Transitions int           `mapstructure:"transitions"` // State changes allowed within the window (0 = no dampening)
+	Window      time.Duration `mapstructure:"window"`      // Period over which state changes are counted
+}
+
+// DemoConfig holds the demo traffic generator. Simulated repeaters link to
+// the reflector's own YSF port from the loopback address and take turns
+// transmitting, so the dashboard, events and integrations can be tried
+// without RF hardware.
+type DemoConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	Repeaters    int           `mapstructure:"repeaters"`     // Simulated repeaters, linked as DEMO01, DEMO02...
+	TalkInterval time.Duration `mapstructure:"talk_interval"` // Average pause between transmissions
+	TalkDuration time.Duration `mapstructure:"talk_duration"` // Average transmission length
+	Churn        time.Duration `mapstructure:"churn"`         // Average time a repeater stays linked before relinking (0 = stay linked)
+}
+
+// SNMPConfig holds the read-only SNMP agent for legacy monitoring systems
+type SNMPConfig struct {
+	Enabled   bool   `mapstructure:"enabled"`
+	Listen    string `mapstructure:"listen"`                  // UDP host:port the agent listens on
+	Community string `mapstructure:"community" secret:"true"` // v1/v2c community string
+	BaseOID   string `mapstructure:"base_oid"`                // OID the reflector MIB is rooted at
+}
+
+// GeoIPConfig holds MaxMind geo/ASN enrichment and the country policy for
+// new repeater connections
+type GeoIPConfig struct {
+	CountryDB       string   `mapstructure:"country_db"`        // GeoLite2/GeoIP2 Country or City .mmdb
+	ASNDB           string   `mapstructure:"asn_db"`            // GeoLite2/GeoIP2 ASN .mmdb
+	AllowCountries  []string `mapstructure:"allow_countries"`   // ISO codes; when set only these may connect
+	DenyCountries   []string `mapstructure:"deny_countries"`    // ISO codes that are rejected
+	AllowUnknown    bool     `mapstructure:"allow_unknown"`     // Admit addresses without a country when allow_countries is set
+	AlertNewCountry bool     `mapstructure:"alert_new_country"` // Emit new_country the first time a country connects after startup
+}
+
+// GroupConfig assigns repeaters to a named group by gateway callsign
+type GroupConfig struct {
+	Name      string   `mapstructure:"name"`
+	Callsigns []string `mapstructure:"callsigns"` // Shell patterns, e.g. "W1*"
+	NoBridge  bool     `mapstructure:"no_bridge"` // Keep members off the bridges in both directions
+}
+
+// DMRIDConfig holds local callsign/DMR ID mappings
+type DMRIDConfig struct {
+	// Overrides take precedence over any downloaded DMR ID database
+	Overrides []DMRIDOverride `mapstructure:"overrides"`
+}
+
+// DMRIDOverride maps a callsign to a DMR ID
+type DMRIDOverride struct {
+	Callsign string `mapstructure:"callsign"`
+	ID       uint32 `mapstructure:"id"`
+	Name     string `mapstructure:"name"`
+}
+
+// HealthConfig configures the runtime monitor that watches for goroutine
+// leaks, heap growth and queue backlogs
+type HealthConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	Interval      time.Duration `mapstructure:"interval"`
+	MaxGoroutines int           `mapstructure:"max_goroutines"` // 0 = no limit
+	MaxHeapMB     int           `mapstructure:"max_heap_mb"`    // 0 = no limit
+	// QueueThreshold is the fraction of a queue's capacity that counts as a backlog
+	QueueThreshold float64 `mapstructure:"queue_threshold"`
+	// HeapDumpDir receives a pprof heap profile when an anomaly is found ("" = off)
+	HeapDumpDir      string        `mapstructure:"heap_dump_dir"`
+	HeapDumpCooldown time.Duration `mapstructure:"heap_dump_cooldown"`
+}
+
+// ClockCheckConfig compares the system clock against NTP servers at startup
+// and periodically, and warns when it has drifted
+type ClockCheckConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Servers  []string      `mapstructure:"servers"` // host or host:port, tried in order
+	Interval time.Duration `mapstructure:"interval"`
+	// Threshold is the clock offset beyond which a clock_drift warning is raised
+	Threshold time.Duration `mapstructure:"threshold"`
+	Timeout   time.Duration `mapstructure:"timeout"` // Per server query
+}
+
+// PopulationConfig announces changes in the connected repeaters, e.g. for
+// club displays showing the live reflector population
+type PopulationConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Debounce collects connects and disconnects for this long before one
+	// population_changed event is sent
+	Debounce time.Duration `mapstructure:"debounce"`
+	Webhooks []string      `mapstructure:"webhooks" secret:"true"` // URLs that receive a JSON POST per announcement
+}
+
+// TalkExportConfig appends finished transmissions to external logs, e.g. to
+// keep net check-in records in a club spreadsheet
+type TalkExportConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	BatchSize     int           `mapstructure:"batch_size"`     // Records per append
+	FlushInterval time.Duration `mapstructure:"flush_interval"` // Longest wait for a batch to fill
+	RetryInterval time.Duration `mapstructure:"retry_interval"` // Wait after a failed append
+	MaxPending    int           `mapstructure:"max_pending"`    // Records kept while a destination fails (0 = unlimited)
+	MinDuration   time.Duration `mapstructure:"min_duration"`   // Skip shorter transmissions, e.g. kerchunks
+	// CSVWebhook receives each batch as a text/csv POST
+	CSVWebhook   string             `mapstructure:"csv_webhook" secret:"true"`
+	GoogleSheets GoogleSheetsConfig `mapstructure:"google_sheets"`
+}
+
+// GoogleSheetsConfig appends rows to a Google Sheet as a service account
+type GoogleSheetsConfig struct {
+	SpreadsheetID   string `mapstructure:"spreadsheet_id"`
+	Sheet           string `mapstructure:"sheet"`            // Sheet (tab) name
+	CredentialsFile string `mapstructure:"credentials_file"` // Service account JSON key
+}
+
+// ExternalLinkConfig declares a system linked to the reflector outside its
+// bridges, whose up/down status is polled from a status URL
+type ExternalLinkConfig struct {
+	Name      string        `mapstructure:"name"`
+	Type      string        `mapstructure:"type"`       // allstar, echolink or other
+	Node      string        `mapstructure:"node"`       // Node or conference number, for display
+	StatusURL string        `mapstructure:"status_url"` // Polled with GET; a 2xx response means up
+	Match     string        `mapstructure:"match"`      // If set, the response body must contain it to count as up
+	Interval  time.Duration `mapstructure:"interval"`   // Poll interval (0 = 1m)
+	Timeout   time.Duration `mapstructure:"timeout"`    // Request timeout (0 = 10s)
+}
+
+// KeepaliveConfig holds the thresholds for repeater keepalive warnings
+type KeepaliveConfig struct {
+	NATTimeout   time.Duration `mapstructure:"nat_timeout"`   // Warn when polls are this far apart on average (0 = off)
+	ErraticRatio float64       `mapstructure:"erratic_ratio"` // Warn when poll jitter exceeds this fraction of the interval (0 = off)
+}
+
 // Load loads configuration from file and environment variables
 func Load(configFile string) (*Config, error) {
 	// Set defaults
-	setDefaults()
+	setDefaults(viper.GetViper())
 
 	// Set config file
 	if configFile != "" {
@@ -136,6 +686,12 @@ func Load(configFile string) (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	// Merge bridge definitions from include files
+	config.File = viper.ConfigFileUsed()
+	if err := resolveBridges(&config, filepath.Dir(config.File)); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+
 	// Validate configuration
 	if err := validate(&config); err != nil {
 		return nil, fmt.Errorf("config validation failed: %w", err)
@@ -145,50 +701,166 @@ func Load(configFile string) (*Config, error) {
 }
 
 // setDefaults sets default configuration values
-func setDefaults() {
+func setDefaults(v *viper.Viper) {
 	// Server defaults
-	viper.SetDefault("server.host", "0.0.0.0")
-	viper.SetDefault("server.port", 42000)
-	viper.SetDefault("server.timeout", "5m")
-	viper.SetDefault("server.max_connections", 200)
-	viper.SetDefault("server.name", "YSF Nexus")
-	viper.SetDefault("server.description", "Go Reflector")
-	viper.SetDefault("server.talk_max_duration", "3m")
-	viper.SetDefault("server.unmute_after", "1m")
+	v.SetDefault("server.host", "0.0.0.0")
+	v.SetDefault("server.port", 42000)
+	v.SetDefault("server.timeout", "5m")
+	v.SetDefault("server.max_connections", 200)
+	v.SetDefault("server.max_connections_per_ip", 0)
+	v.SetDefault("server.follow_port_changes", false)
+	v.SetDefault("server.name", "YSF Nexus")
+	v.SetDefault("server.description", "Go Reflector")
+	v.SetDefault("server.talk_max_duration", "3m")
+	v.SetDefault("server.unmute_after", "1m")
+	v.SetDefault("server.bridge_talk_timeout", "3s")
+	v.SetDefault("server.simultaneous_bridge_streams", false)
+	v.SetDefault("server.strict_callsign_fields", false)
+	v.SetDefault("server.keepalive.nat_timeout", "30s")
+	v.SetDefault("server.transmit_quiet_period", "2s")
+	v.SetDefault("server.keepalive.erratic_ratio", 0.5)
+	v.SetDefault("server.drain_timeout", "5s")
+	v.SetDefault("server.status_replies.mode", StatusRepliesOpen)
+	v.SetDefault("server.welcome.enabled", false)
+	v.SetDefault("server.last_heard_query.enabled", false)
+	v.SetDefault("server.last_heard_query.queries", []string{"LH", "QRZ?"})
+	v.SetDefault("server.last_heard_query.count", 3)
+	v.SetDefault("server.hold.duration", "30m")
+	v.SetDefault("server.hold.max_duration", "4h")
+	v.SetDefault("server.anti_kerchunk.enabled", false)
+	v.SetDefault("server.anti_kerchunk.max_short_transmissions", 3)
+	v.SetDefault("server.anti_kerchunk.short_threshold", "2s")
+	v.SetDefault("server.anti_kerchunk.window", "5m")
+	v.SetDefault("server.anti_kerchunk.cooldown", "10m")
 
 	// Web defaults
-	viper.SetDefault("web.enabled", true)
-	viper.SetDefault("web.host", "0.0.0.0")
-	viper.SetDefault("web.port", 8080)
-	viper.SetDefault("web.auth_required", false)
+	v.SetDefault("web.enabled", true)
+	v.SetDefault("web.host", "0.0.0.0")
+	v.SetDefault("web.port", 8080)
+	v.SetDefault("web.auth_required", false)
+	v.SetDefault("web.auth.backend", AuthBackendLocal)
+	v.SetDefault("web.auth.default_role", RoleNone)
+	v.SetDefault("web.auth.proxy.user_header", "Remote-User")
+	v.SetDefault("web.auth.proxy.groups_header", "Remote-Groups")
+	v.SetDefault("web.auth.oidc.scopes", []string{"openid", "profile", "email", "groups"})
+	v.SetDefault("web.auth.oidc.username_claim", "preferred_username")
+	v.SetDefault("web.auth.oidc.groups_claim", "groups")
+	v.SetDefault("web.ip_masking", "partial")
+	v.SetDefault("web.heatmap_weeks", 4)
+	v.SetDefault("web.notifications.enabled", true)
+	v.SetDefault("web.notifications.events", []string{"talk_start"})
+	v.SetDefault("web.notifications.max_watch", 50)
 
 	// MQTT defaults
-	viper.SetDefault("mqtt.enabled", false)
-	viper.SetDefault("mqtt.broker", "tcp://localhost:1883")
-	viper.SetDefault("mqtt.topic_prefix", "ysf/reflector")
-	viper.SetDefault("mqtt.client_id", "ysf-nexus")
-	viper.SetDefault("mqtt.qos", 1)
-	viper.SetDefault("mqtt.retained", false)
+	v.SetDefault("mqtt.enabled", false)
+	v.SetDefault("mqtt.broker", "tcp://localhost:1883")
+	v.SetDefault("mqtt.topic_prefix", "ysf/reflector")
+	v.SetDefault("mqtt.client_id", "ysf-nexus")
+	v.SetDefault("mqtt.qos", 1)
+	v.SetDefault("mqtt.retained", false)
 
 	// Blocklist defaults
-	viper.SetDefault("blocklist.enabled", true)
+	v.SetDefault("blocklist.enabled", true)
+	v.SetDefault("blocklist.refresh_interval", "1h")
 
 	// Logging defaults
-	viper.SetDefault("logging.level", "info")
-	viper.SetDefault("logging.format", "text")
-	viper.SetDefault("logging.max_size", 100)
-	viper.SetDefault("logging.max_backups", 3)
-	viper.SetDefault("logging.max_age", 28)
+	v.SetDefault("logging.level", "info")
+	v.SetDefault("logging.format", "text")
+	v.SetDefault("logging.max_size", 100)
+	v.SetDefault("logging.max_backups", 3)
+	v.SetDefault("logging.max_age", 28)
 
 	// Metrics defaults
-	viper.SetDefault("metrics.enabled", true)
-	viper.SetDefault("metrics.prometheus.enabled", true)
-	viper.SetDefault("metrics.prometheus.port", 9090)
-	viper.SetDefault("metrics.prometheus.path", "/metrics")
+	v.SetDefault("metrics.enabled", true)
+	v.SetDefault("metrics.prometheus.enabled", true)
+	v.SetDefault("metrics.prometheus.port", 9090)
+	v.SetDefault("metrics.prometheus.path", "/metrics")
+
+	// Maintenance defaults
+	v.SetDefault("maintenance.enabled", false)
+	v.SetDefault("maintenance.schedule", "0 0 3 * * *") // 03:00 every day
+	v.SetDefault("maintenance.task_timeout", "5m")
+	v.SetDefault("maintenance.rotate_logs", true)
+	v.SetDefault("maintenance.talk_log_retention", "168h")
+	v.SetDefault("maintenance.event_retention", "0s")
+	v.SetDefault("maintenance.ip_retention", "0s")
+
+	// Alerting defaults
+	v.SetDefault("alerting.enabled", false)
+	v.SetDefault("alerting.interval", "30s")
+	v.SetDefault("alerting.email.enabled", false)
+	v.SetDefault("alerting.email.port", 587)
+	v.SetDefault("alerting.email.tls", "starttls")
+	v.SetDefault("alerting.email.min_interval", "15m")
+	v.SetDefault("alerting.email.lifecycle", true)
+
+	// Mirror defaults
+	v.SetDefault("mirror.enabled", false)
+	v.SetDefault("mirror.sample_every", 1)
+	v.SetDefault("mirror.rate_limit", 0)
+
+	// Snapshot defaults
+	v.SetDefault("snapshot.enabled", false)
+	v.SetDefault("snapshot.interval", "30s")
+	v.SetDefault("snapshot.talk_log_tail", 50)
+
+	// Bridge statistics defaults
+	v.SetDefault("bridge_stats.retention_days", 400)
+	v.SetDefault("bridge_stats.history", 100)
+
+	// Bridge dampening defaults
+	v.SetDefault("bridge_dampening.transitions", 6)
+	v.SetDefault("bridge_dampening.window", "10m")
+
+	// Demo traffic defaults
+	v.SetDefault("demo.enabled", false)
+	v.SetDefault("demo.repeaters", 5)
+	v.SetDefault("demo.talk_interval", "20s")
+	v.SetDefault("demo.talk_duration", "8s")
+	v.SetDefault("demo.churn", "10m")
+
+	// GeoIP defaults
+	v.SetDefault("geoip.allow_unknown", true)
+	v.SetDefault("geoip.alert_new_country", false)
+
+	// Population announcement defaults
+	v.SetDefault("population.enabled", false)
+	v.SetDefault("population.debounce", "10s")
+
+	// Talk log export defaults
+	v.SetDefault("talk_export.enabled", false)
+	v.SetDefault("talk_export.batch_size", 10)
+	v.SetDefault("talk_export.flush_interval", "1m")
+	v.SetDefault("talk_export.retry_interval", "30s")
+	v.SetDefault("talk_export.max_pending", 1000)
+	v.SetDefault("talk_export.min_duration", "0s")
+	v.SetDefault("talk_export.google_sheets.sheet", "Sheet1")
+
+	// Runtime monitor defaults
+	v.SetDefault("health.enabled", true)
+	v.SetDefault("health.interval", "30s")
+	v.SetDefault("health.max_goroutines", 0)
+	v.SetDefault("health.max_heap_mb", 0)
+	v.SetDefault("health.queue_threshold", 0.8)
+	v.SetDefault("health.heap_dump_dir", "")
+	v.SetDefault("health.heap_dump_cooldown", "1h")
+
+	// Clock check defaults
+	v.SetDefault("clock_check.enabled", true)
+	v.SetDefault("clock_check.servers", []string{"pool.ntp.org"})
+	v.SetDefault("clock_check.interval", "1h")
+	v.SetDefault("clock_check.threshold", "1s")
+	v.SetDefault("clock_check.timeout", "5s")
+
+	// SNMP defaults
+	v.SetDefault("snmp.enabled", false)
+	v.SetDefault("snmp.listen", "127.0.0.1:1161")
+	v.SetDefault("snmp.community", "public")
+	v.SetDefault("snmp.base_oid", "1.3.6.1.4.1.32473.1")
 
 	// Bridge defaults
-	viper.SetDefault("bridges.permanent", false)
-	viper.SetDefault("bridges.max_retries", 0)      // 0 = infinite retries
-	viper.SetDefault("bridges.retry_delay", "30s")  // Start with 30 second delay
-	viper.SetDefault("bridges.health_check", "60s") // Check connection every minute
+	v.SetDefault("bridges.permanent", false)
+	v.SetDefault("bridges.max_retries", 0)      // 0 = infinite retries
+	v.SetDefault("bridges.retry_delay", "30s")  // Start with 30 second delay
+	v.SetDefault("bridges.health_check", "60s") // Check connection every minute
 }
@@ -2,6 +2,9 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -230,6 +233,475 @@ mqtt:
 			expectErr: true,
 			errorMsg:  "invalid broker URL",
 		},
+		{
+			name: "Invalid listen address",
+			config: `
+web:
+  listen: ["127.0.0.1:8080", "tailscale:8080"]
+`,
+			expectErr: true,
+			errorMsg:  "host must be an IP address",
+		},
+		{
+			name: "Invalid status reply allow entry",
+			config: `
+server:
+  status_replies:
+    mode: "known"
+    allow: ["example.org"]
+`,
+			expectErr: true,
+			errorMsg:  "invalid allow entry",
+		},
+		{
+			name: "Negative drain timeout",
+			config: `
+server:
+  drain_timeout: "-1s"
+`,
+			expectErr: true,
+			errorMsg:  "drain_timeout cannot be negative",
+		},
+		{
+			name: "Reply to a packet variant other than YSFV",
+			config: `
+server:
+  packet_variants:
+    YSFO: "reply"
+`,
+			expectErr: true,
+			errorMsg:  "reply is only supported for YSFV",
+		},
+		{
+			name: "Callsign routes on a receive-only bridge",
+			config: `
+bridges:
+  - name: "DMR"
+    host: "dmr.example.org"
+    port: 42000
+    enabled: true
+    permanent: true
+    rx_only: true
+    callsigns: ["K5ABC"]
+`,
+			expectErr: true,
+			errorMsg:  "callsigns cannot be used with rx_only",
+		},
+		{
+			name: "Invalid SNMP base OID",
+			config: `
+snmp:
+  enabled: true
+  base_oid: "1.3.6.1.4.1.x"
+`,
+			expectErr: true,
+			errorMsg:  "invalid base_oid",
+		},
+		{
+			name: "Alert email without recipients",
+			config: `
+alerting:
+  enabled: true
+  email:
+    enabled: true
+    host: "smtp.example.org"
+    from: "nexus@example.org"
+`,
+			expectErr: true,
+			errorMsg:  "at least one recipient is required",
+		},
+		{
+			name: "Country policy without a country database",
+			config: `
+geoip:
+  deny_countries: ["RU"]
+`,
+			expectErr: true,
+			errorMsg:  "country_db is required",
+		},
+		{
+			name: "Duplicate repeater group",
+			config: `
+groups:
+  - name: "local"
+    callsigns: ["W1*"]
+  - name: "Local"
+    callsigns: ["N1*"]
+`,
+			expectErr: true,
+			errorMsg:  "duplicate group",
+		},
+		{
+			name: "Invalid population webhook",
+			config: `
+population:
+  enabled: true
+  webhooks: ["not a url"]
+`,
+			expectErr: true,
+			errorMsg:  "population config: invalid webhook URL",
+		},
+		{
+			name: "Negative keepalive ratio",
+			config: `
+server:
+  keepalive:
+    erratic_ratio: -1
+`,
+			expectErr: true,
+			errorMsg:  "keepalive nat_timeout and erratic_ratio cannot be negative",
+		},
+		{
+			name: "Invalid welcome template",
+			config: `
+server:
+  welcome:
+    enabled: true
+    message: "Welcome to {{.Reflector"
+`,
+			expectErr: true,
+			errorMsg:  "welcome: invalid message template",
+		},
+		{
+			name: "Talk export without destination",
+			config: `
+talk_export:
+  enabled: true
+`,
+			expectErr: true,
+			errorMsg:  "talk_export config: csv_webhook or google_sheets.spreadsheet_id is required",
+		},
+		{
+			name: "Talk export sheet without credentials",
+			config: `
+talk_export:
+  enabled: true
+  google_sheets:
+    spreadsheet_id: "abc123"
+`,
+			expectErr: true,
+			errorMsg:  "google_sheets.credentials_file is required",
+		},
+		{
+			name: "Bridge with schedule windows only",
+			config: `
+bridges:
+  - name: "sun"
+    host: "dx.ysf.net"
+    port: 42000
+    enabled: true
+    windows:
+      - schedule: "0 0 19 * * 1-5"
+        duration: "3h"
+      - schedule: "0 0 8 * * 0,6"
+        duration: "4h"
+`,
+			expectErr: false,
+		},
+		{
+			name: "Bridge window without duration",
+			config: `
+bridges:
+  - name: "sun"
+    host: "dx.ysf.net"
+    port: 42000
+    enabled: true
+    schedule: "0 0 19 * * 1-5"
+    duration: "3h"
+    windows:
+      - schedule: "0 0 8 * * 0,6"
+`,
+			expectErr: true,
+			errorMsg:  "duration must be positive for scheduled bridge",
+		},
+		{
+			name: "Bridge window with invalid schedule",
+			config: `
+bridges:
+  - name: "sun"
+    host: "dx.ysf.net"
+    port: 42000
+    enabled: true
+    windows:
+      - schedule: "0 8 * * *"
+        duration: "4h"
+`,
+			expectErr: true,
+			errorMsg:  "invalid schedule",
+		},
+		{
+			name: "Valid external link",
+			config: `
+external_links:
+  - name: "AllStar 2560"
+    type: "allstar"
+    node: "2560"
+    status_url: "https://stats.allstarlink.org/api/stats/2560"
+    match: "\"keyed\""
+    interval: "2m"
+`,
+			expectErr: false,
+		},
+		{
+			name: "External link with invalid status URL",
+			config: `
+external_links:
+  - name: "EchoLink"
+    type: "echolink"
+    status_url: "ftp://example.com/status"
+`,
+			expectErr: true,
+			errorMsg:  "invalid status_url",
+		},
+		{
+			name: "Duplicate external link",
+			config: `
+external_links:
+  - name: "hub"
+    status_url: "http://example.com/a"
+  - name: "HUB"
+    status_url: "http://example.com/b"
+`,
+			expectErr: true,
+			errorMsg:  "duplicate link",
+		},
+		{
+			name: "Room password with space",
+			config: `
+server:
+  password:
+    token: "two words"
+`,
+			expectErr: true,
+			errorMsg:  "password: token must be printable ASCII",
+		},
+		{
+			name: "Invalid room password exemption",
+			config: `
+server:
+  password:
+    token: "s3cret"
+    exempt: ["W1["]
+`,
+			expectErr: true,
+			errorMsg:  "password: invalid exempt callsign pattern",
+		},
+		{
+			name: "Clock check without servers",
+			config: `
+clock_check:
+  servers: []
+`,
+			expectErr: true,
+			errorMsg:  "at least one server is required",
+		},
+		{
+			name: "Invalid clock check threshold",
+			config: `
+clock_check:
+  threshold: "0s"
+`,
+			expectErr: true,
+			errorMsg:  "interval, threshold and timeout must be positive",
+		},
+		{
+			name: "Talk limit without a positive duration",
+			config: `
+server:
+  talk_limits:
+    - callsign: "W1NEWS"
+      talk_max_duration: "0s"
+`,
+			expectErr: true,
+			errorMsg:  "talk_limits[0]: talk_max_duration must be positive",
+		},
+		{
+			name: "Unknown bridge protocol",
+			config: `
+bridges:
+  - name: "DX"
+    host: "dx.ysf.net"
+    port: 42000
+    enabled: true
+    permanent: true
+    protocol: "mmdvm"
+`,
+			expectErr: true,
+			errorMsg:  "invalid protocol \"mmdvm\"",
+		},
+		{
+			name: "Negative bridge stats retention",
+			config: `
+bridge_stats:
+  file: "/tmp/bridge-stats.json"
+  retention_days: -1
+`,
+			expectErr: true,
+			errorMsg:  "bridge_stats config: retention_days cannot be negative",
+		},
+		{
+			name: "Negative IP retention",
+			config: `
+maintenance:
+  enabled: true
+  ip_retention: "-1h"
+`,
+			expectErr: true,
+			errorMsg:  "maintenance config: ip_retention cannot be negative",
+		},
+		{
+			name: "Unknown feature flag",
+			config: `
+features:
+  rooms: true
+  recordings: true
+`,
+			expectErr: true,
+			errorMsg:  "features config: unknown feature \"recordings\"",
+		},
+		{
+			name: "Negative heatmap weeks",
+			config: `
+web:
+  heatmap_weeks: -1
+`,
+			expectErr: true,
+			errorMsg:  "web config: heatmap_weeks must be non-negative",
+		},
+		{
+			name: "Last heard query count too large",
+			config: `
+server:
+  last_heard_query:
+    enabled: true
+    count: 21
+`,
+			expectErr: true,
+			errorMsg:  "last_heard_query: count must be between 1 and 20",
+		},
+		{
+			name: "Bridge dampening without window",
+			config: `
+bridge_dampening:
+  transitions: 4
+  window: "0s"
+`,
+			expectErr: true,
+			errorMsg:  "bridge_dampening config: window must be positive when dampening is enabled",
+		},
+		{
+			name: "Demo without repeaters",
+			config: `
+demo:
+  enabled: true
+  repeaters: 0
+`,
+			expectErr: true,
+			errorMsg:  "demo config: repeaters must be between 1 and 99",
+		},
+		{
+			name: "Proxy auth without trusted proxies",
+			config: `
+web:
+  auth_required: true
+  auth:
+    backend: "proxy"
+`,
+			expectErr: true,
+			errorMsg:  "web config: auth: proxy trusted_proxies is required",
+		},
+		{
+			name: "OIDC issuer over plain http",
+			config: `
+web:
+  auth_required: true
+  auth:
+    backend: "oidc"
+    oidc:
+      issuer: "http://auth.example.org/realms/ham"
+      client_id: "ysf-nexus"
+      redirect_url: "https://dashboard.example.org/api/auth/oidc/callback"
+`,
+			expectErr: true,
+			errorMsg:  "web config: auth: oidc issuer must use https",
+		},
+		{
+			name: "OIDC issuer over http on loopback",
+			config: `
+web:
+  auth_required: true
+  auth:
+    backend: "oidc"
+    oidc:
+      issuer: "http://127.0.0.1:8180/realms/ham"
+      client_id: "ysf-nexus"
+      redirect_url: "http://localhost:8080/api/auth/oidc/callback"
+`,
+			expectErr: false,
+		},
+		{
+			name: "Hold maximum shorter than default",
+			config: `
+server:
+  hold:
+    duration: "1h"
+    max_duration: "30m"
+`,
+			expectErr: true,
+			errorMsg:  "hold: max_duration cannot be shorter than duration",
+		},
+		{
+			name: "Invalid health queue threshold",
+			config: `
+health:
+  queue_threshold: 1.5
+`,
+			expectErr: true,
+			errorMsg:  "queue_threshold must be between 0 and 1",
+		},
+		{
+			name: "Invalid broadcast priority pattern",
+			config: `
+server:
+  broadcast_priority:
+    callsigns: ["W1["]
+`,
+			expectErr: true,
+			errorMsg:  "broadcast_priority: invalid callsign pattern",
+		},
+		{
+			name: "Duplicate DMR ID override",
+			config: `
+dmr_ids:
+  overrides:
+    - callsign: "W1AW"
+      id: 3100001
+    - callsign: "K1ABC"
+      id: 3100001
+`,
+			expectErr: true,
+			errorMsg:  "DMR ID 3100001 is mapped more than once",
+		},
+		{
+			name: "Invalid notification event",
+			config: `
+web:
+  notifications:
+    events: ["talk_start", "doubling"]
+`,
+			expectErr: true,
+			errorMsg:  "unsupported event",
+		},
+		{
+			name: "Invalid mirror target",
+			config: `
+mirror:
+  enabled: true
+  target: "tcp://127.0.0.1:9000"
+`,
+			expectErr: true,
+			errorMsg:  "must be udp://host:port or unix:///path",
+		},
 		{
 			name: "Valid config",
 			config: `
@@ -286,3 +758,210 @@ func containsSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestLoadBridgeIncludes(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "bridges.d"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	write("config.yaml", `
+bridge_includes: ["bridges.d/*.yaml"]
+bridges:
+  - name: "main"
+    host: "main.example.org"
+    port: 42000
+    permanent: true
+    enabled: true
+`)
+	// A single bridge per file
+	write("bridges.d/b.yaml", `
+name: "single"
+host: "single.example.org"
+port: 42000
+schedule: "0 0 20 * * 6"
+duration: "1h"
+enabled: true
+`)
+	// Or a list
+	write("bridges.d/a.yaml", `
+bridges:
+  - name: "listed"
+    host: "listed.example.org"
+    port: 42001
+    permanent: true
+    enabled: true
+`)
+
+	cfg, err := Load(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(cfg.Bridges) != 3 || cfg.Bridges[1].Name != "listed" || cfg.Bridges[2].Name != "single" {
+		t.Fatalf("expected main, listed and single bridges, got %+v", cfg.Bridges)
+	}
+	if cfg.Bridges[2].Duration != time.Hour {
+		t.Errorf("expected the included duration to be parsed, got %v", cfg.Bridges[2].Duration)
+	}
+
+	bridges, err := LoadBridges(cfg.File)
+	if err != nil || len(bridges) != 3 {
+		t.Fatalf("LoadBridges: %v (%d bridges)", err, len(bridges))
+	}
+
+	// Enabled bridges must have unique names across files
+	write("bridges.d/c.yaml", `
+name: "main"
+host: "other.example.org"
+port: 42000
+permanent: true
+enabled: true
+`)
+	if _, err := LoadBridges(cfg.File); err == nil || !strings.Contains(err.Error(), "duplicate bridge name") {
+		t.Errorf("expected a duplicate name error, got %v", err)
+	}
+}
+
+func TestConfigDiff(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`
+server:
+  port: 42000
+  timeout: 5m
+  password:
+    token: room-secret
+web:
+  password: secret
+`)
+	running, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	file, _ := LoadFile(path)
+	if changes := Diff(running, file); len(changes) != 0 {
+		t.Fatalf("expected no changes, got %+v", changes)
+	}
+
+	write(`
+server:
+  port: 42001
+  timeout: 10m
+  password:
+    token: new-room-secret
+web:
+  password: changed
+dmr_ids:
+  overrides:
+    - callsign: W1AW
+      id: 3100001
+`)
+	file, err = LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+
+	changes := make(map[string]Change)
+	for _, c := range Diff(running, file) {
+		changes[c.Key] = c
+	}
+	if len(changes) != 5 {
+		t.Fatalf("expected 5 changes, got %+v", changes)
+	}
+	if c := changes["server.port"]; c.Running != 42000 || c.File != 42001 || !c.RestartRequired {
+		t.Errorf("unexpected port change %+v", c)
+	}
+	if c := changes["server.timeout"]; c.Running != "5m0s" || c.File != "10m0s" {
+		t.Errorf("unexpected timeout change %+v", c)
+	}
+	if c := changes["web.password"]; c.Running != maskedValue || c.File != maskedValue {
+		t.Errorf("expected the password to be masked, got %+v", c)
+	}
+	if c := changes["server.password.token"]; c.Running != maskedValue || c.File != maskedValue {
+		t.Errorf("expected the room password to be masked, got %+v", c)
+	}
+	if c := changes["dmr_ids.overrides"]; c.RestartRequired {
+		t.Errorf("expected DMR ID overrides to apply without a restart, got %+v", c)
+	}
+
+	status := NewStatus(path, Diff(running, file), time.Now())
+	if status.InSync || !status.PendingRestart {
+		t.Errorf("unexpected status %+v", status)
+	}
+	if _, err := LoadFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+}
+
+func TestConfigDiffMasksSecrets(t *testing.T) {
+	running, file := &Config{}, &Config{}
+	running.SNMP.Community = "public"
+	file.SNMP.Community = "private"
+	file.Alerting.Webhooks = []string{"https://hooks.example.org/T000/secret"}
+	file.TalkExport.CSVWebhook = "https://example.org/log?key=secret"
+	file.Server.Password.Token = "room"
+
+	changes := make(map[string]Change)
+	for _, c := range Diff(running, file) {
+		changes[c.Key] = c
+	}
+	for _, key := range []string{"snmp.community", "alerting.webhooks", "talk_export.csv_webhook", "server.password.token"} {
+		c, ok := changes[key]
+		if !ok {
+			t.Errorf("missing change for %s", key)
+			continue
+		}
+		if c.File != maskedValue {
+			t.Errorf("expected %s to be masked, got %+v", key, c)
+		}
+	}
+	if c := changes["snmp.community"]; c.Running != maskedValue {
+		t.Errorf("expected the running community to be masked, got %+v", c)
+	}
+}
+
+// TestSecretSettingsTagged checks that settings named like credentials are
+// tagged secret, so they are masked in config drift output
+func TestSecretSettingsTagged(t *testing.T) {
+	credential := []string{"password", "secret", "token", "community", "webhook"}
+	var check func(prefix string, typ reflect.Type)
+	check = func(prefix string, typ reflect.Type) {
+		for typ.Kind() == reflect.Slice || typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct || typ.PkgPath() != reflect.TypeOf(Config{}).PkgPath() {
+			return
+		}
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			name := settingName(f)
+			if name == "" {
+				continue
+			}
+			ft := f.Type
+			for ft.Kind() == reflect.Slice || ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && ft != reflect.TypeOf(time.Time{}) {
+				check(prefix+name+".", f.Type)
+				continue
+			}
+			for _, word := range credential {
+				if strings.Contains(name, word) && !isSecret(f) {
+					t.Errorf("%s%s looks like a credential but is not tagged secret", prefix, name)
+				}
+			}
+		}
+	}
+	check("", reflect.TypeOf(Config{}))
+}
@@ -0,0 +1,208 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// maskedValue replaces secrets in diffs
+const maskedValue = "********"
+
+// liveSettings are the settings that can be applied without a restart: bridges
+// through a bridge reload, DMR ID overrides through the API. A change to any
+// other setting only takes effect after a restart.
+var liveSettings = []string{"bridges", "bridge_includes", "dmr_ids"}
+
+// Change is a setting whose running value differs from the config file
+type Change struct {
+	Key             string      `json:"key"`
+	Running         interface{} `json:"running"`
+	File            interface{} `json:"file"`
+	RestartRequired bool        `json:"restart_required"`
+}
+
+// Status compares the running configuration with the config file
+type Status struct {
+	File           string    `json:"file"`
+	InSync         bool      `json:"in_sync"`
+	PendingRestart bool      `json:"pending_restart"`
+	Changes        []Change  `json:"changes"`
+	Checked        time.Time `json:"checked"`
+}
+
+// NewStatus summarizes the changes between the running config and file
+func NewStatus(file string, changes []Change, now time.Time) Status {
+	status := Status{
+		File:    file,
+		InSync:  len(changes) == 0,
+		Changes: changes,
+		Checked: now,
+	}
+	if status.Changes == nil {
+		status.Changes = []Change{}
+	}
+	for _, c := range changes {
+		status.PendingRestart = status.PendingRestart || c.RestartRequired
+	}
+	return status
+}
+
+// LoadFile loads configuration from a config file without touching the
+// global configuration, so the file can be compared with what is running.
+// Unlike Load, the file must exist.
+func LoadFile(configFile string) (*Config, error) {
+	if _, err := os.Stat(configFile); err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	v := viper.New()
+	setDefaults(v)
+	v.SetConfigFile(configFile)
+	v.SetEnvPrefix("YSF")
+	v.AutomaticEnv()
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	cfg.File = configFile
+	if err := resolveBridges(&cfg, filepath.Dir(configFile)); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+	if err := validate(&cfg); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+	return &cfg, nil
+}
+
+// Diff returns the settings that differ between the running config and the
+// config file, keyed by their dotted config path. Lists are compared whole
+// and settings tagged secret:"true" are masked.
+func Diff(running, file *Config) []Change {
+	var changes []Change
+	diffStruct("", reflect.ValueOf(*running), reflect.ValueOf(*file), &changes)
+	return changes
+}
+
+// diffStruct appends the differing fields of two structs of the same type
+func diffStruct(prefix string, running, file reflect.Value, changes *[]Change) {
+	t := running.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := settingName(t.Field(i))
+		if name == "" {
+			continue
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		rv, fv := running.Field(i), file.Field(i)
+		if rv.Kind() == reflect.Struct {
+			diffStruct(key, rv, fv, changes)
+			continue
+		}
+		if sameSetting(rv, fv) {
+			continue
+		}
+
+		secret := isSecret(t.Field(i))
+		*changes = append(*changes, Change{
+			Key:             key,
+			Running:         displayValue(rv, secret),
+			File:            displayValue(fv, secret),
+			RestartRequired: !isLiveSetting(key),
+		})
+	}
+}
+
+// sameSetting reports whether two values of a setting are equal; a missing
+// list equals an empty one
+func sameSetting(running, file reflect.Value) bool {
+	switch running.Kind() {
+	case reflect.Slice, reflect.Map:
+		if running.Len() == 0 && file.Len() == 0 {
+			return true
+		}
+	}
+	return reflect.DeepEqual(running.Interface(), file.Interface())
+}
+
+// settingName returns the config key of a struct field, or "" if it is not
+// a setting
+func settingName(f reflect.StructField) string {
+	if !f.IsExported() {
+		return ""
+	}
+	name := strings.Split(f.Tag.Get("mapstructure"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		name = strings.ToLower(f.Name)
+	}
+	return name
+}
+
+// isSecret reports whether a setting holds a secret, such as a password or
+// a webhook URL carrying a token: its field is tagged secret:"true"
+func isSecret(f reflect.StructField) bool {
+	return f.Tag.Get("secret") == "true"
+}
+
+// isLiveSetting reports whether a setting can be applied without a restart
+func isLiveSetting(key string) bool {
+	for _, live := range liveSettings {
+		if key == live || strings.HasPrefix(key, live+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// displayValue renders a setting for a diff: durations as strings, structs
+// as maps keyed like the config file, secrets masked
+func displayValue(v reflect.Value, secret bool) interface{} {
+	if secret {
+		if v.IsZero() {
+			return ""
+		}
+		return maskedValue
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		fields := make(map[string]interface{})
+		for i := 0; i < v.NumField(); i++ {
+			name := settingName(v.Type().Field(i))
+			if name == "" {
+				continue
+			}
+			fields[name] = displayValue(v.Field(i), isSecret(v.Type().Field(i)))
+		}
+		return fields
+	case reflect.Slice:
+		if v.IsNil() {
+			return []interface{}{}
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = displayValue(v.Index(i), false)
+		}
+		return items
+	}
+
+	if d, ok := v.Interface().(time.Duration); ok {
+		return d.String()
+	}
+	return v.Interface()
+}
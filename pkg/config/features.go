@@ -0,0 +1,52 @@
+package config
+
+import "sort"
+
+// Feature flags gate experimental subsystems so they can ship dark and be
+// enabled per instance from the features section, without a rebuild. Every
+// flag is off unless set.
+const (
+	FeatureRooms      = "rooms"
+	FeatureRecording  = "recording"
+	FeatureClustering = "clustering"
+)
+
+// KnownFeatures describes each feature flag
+var KnownFeatures = map[string]string{
+	FeatureRooms:      "Virtual rooms that split the reflector into separate channels",
+	FeatureRecording:  "Recording of transmissions for later playback",
+	FeatureClustering: "Sharing state and traffic between reflector instances",
+}
+
+// Feature is a feature flag and whether it is enabled
+type Feature struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+}
+
+// FeatureEnabled reports whether a feature flag is enabled
+func (c *Config) FeatureEnabled(name string) bool {
+	return c.Features[name]
+}
+
+// FeatureList returns every known feature flag ordered by name
+func (c *Config) FeatureList() []Feature {
+	features := make([]Feature, 0, len(KnownFeatures))
+	for name, description := range KnownFeatures {
+		features = append(features, Feature{Name: name, Description: description, Enabled: c.Features[name]})
+	}
+	sort.Slice(features, func(i, j int) bool { return features[i].Name < features[j].Name })
+	return features
+}
+
+// EnabledFeatures returns the names of the enabled feature flags, ordered
+func (c *Config) EnabledFeatures() []string {
+	enabled := []string{}
+	for _, feature := range c.FeatureList() {
+		if feature.Enabled {
+			enabled = append(enabled, feature.Name)
+		}
+	}
+	return enabled
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/viper"
+)
+
+// loadBridgeIncludes reads the bridge definitions matched by the include
+// patterns, e.g. "bridges.d/*.yaml". Relative patterns are resolved against
+// baseDir. A file holds either a `bridges:` list or a single bridge.
+// Files are read in name order.
+func loadBridgeIncludes(patterns []string, baseDir string) ([]BridgeConfig, error) {
+	var bridges []BridgeConfig
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(baseDir, pattern)
+		}
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bridge include %q: %w", pattern, err)
+		}
+		sort.Strings(files)
+
+		for _, file := range files {
+			loaded, err := loadBridgeFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("bridge include %s: %w", file, err)
+			}
+			bridges = append(bridges, loaded...)
+		}
+	}
+	return bridges, nil
+}
+
+// loadBridgeFile reads the bridges defined in one include file
+func loadBridgeFile(file string) ([]BridgeConfig, error) {
+	v := viper.New()
+	v.SetConfigFile(file)
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	if v.IsSet("bridges") {
+		var bridges []BridgeConfig
+		if err := v.UnmarshalKey("bridges", &bridges); err != nil {
+			return nil, err
+		}
+		return bridges, nil
+	}
+
+	var bridge BridgeConfig
+	if err := v.Unmarshal(&bridge); err != nil {
+		return nil, err
+	}
+	if bridge.Name == "" {
+		return nil, fmt.Errorf("bridge name is required")
+	}
+	return []BridgeConfig{bridge}, nil
+}
+
+// LoadBridges reads the bridge definitions of a config file and its bridge
+// includes without touching the rest of the configuration, for reloads
+func LoadBridges(configFile string) ([]BridgeConfig, error) {
+	v := viper.New()
+	v.SetConfigFile(configFile)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	if err := resolveBridges(&cfg, filepath.Dir(configFile)); err != nil {
+		return nil, err
+	}
+	for i, bridge := range cfg.Bridges {
+		if err := validateBridge(&bridge); err != nil {
+			return nil, fmt.Errorf("bridge config[%d]: %w", i, err)
+		}
+	}
+	return cfg.Bridges, nil
+}
+
+// resolveBridges appends the bridges from the include files and rejects
+// enabled bridges that share a name
+func resolveBridges(cfg *Config, baseDir string) error {
+	included, err := loadBridgeIncludes(cfg.BridgeIncludes, baseDir)
+	if err != nil {
+		return err
+	}
+	cfg.Bridges = append(cfg.Bridges, included...)
+
+	seen := make(map[string]bool)
+	for _, bridge := range cfg.Bridges {
+		if !bridge.Enabled {
+			continue
+		}
+		if seen[bridge.Name] {
+			return fmt.Errorf("duplicate bridge name %q", bridge.Name)
+		}
+		seen[bridge.Name] = true
+	}
+	return nil
+}
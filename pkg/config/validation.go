@@ -2,8 +2,17 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/mail"
 	"net/url"
+	"path"
+	"sort"
+	"strconv"
 	"strings"
+	"text/template"
+	"time"
+
+	"github.com/robfig/cron/v3"
 )
 
 // validate validates the configuration
@@ -30,6 +39,11 @@ func validate(config *Config) error {
 		return fmt.Errorf("mqtt config: %w", err)
 	}
 
+	// Validate blocklist configuration
+	if err := validateBlocklist(&config.Blocklist); err != nil {
+		return fmt.Errorf("blocklist config: %w", err)
+	}
+
 	// Validate logging configuration
 	if err := validateLogging(&config.Logging); err != nil {
 		return fmt.Errorf("logging config: %w", err)
@@ -40,6 +54,105 @@ func validate(config *Config) error {
 		return fmt.Errorf("metrics config: %w", err)
 	}
 
+	// Validate maintenance configuration
+	if err := validateMaintenance(&config.Maintenance); err != nil {
+		return fmt.Errorf("maintenance config: %w", err)
+	}
+
+	// Validate alerting configuration
+	if err := validateAlerting(&config.Alerting); err != nil {
+		return fmt.Errorf("alerting config: %w", err)
+	}
+
+	// Validate mirror configuration
+	if err := validateMirror(&config.Mirror); err != nil {
+		return fmt.Errorf("mirror config: %w", err)
+	}
+
+	// Validate snapshot configuration
+	if err := validateSnapshot(&config.Snapshot); err != nil {
+		return fmt.Errorf("snapshot config: %w", err)
+	}
+
+	// Validate bridge statistics persistence
+	if err := validateBridgeStats(&config.BridgeStats); err != nil {
+		return fmt.Errorf("bridge_stats config: %w", err)
+	}
+
+	if err := validateBridgeDampening(&config.BridgeDampening); err != nil {
+		return fmt.Errorf("bridge_dampening config: %w", err)
+	}
+
+	// Validate demo traffic
+	if err := validateDemo(&config.Demo); err != nil {
+		return fmt.Errorf("demo config: %w", err)
+	}
+
+	// Validate SNMP configuration
+	if err := validateSNMP(&config.SNMP); err != nil {
+		return fmt.Errorf("snmp config: %w", err)
+	}
+
+	// Validate geoip configuration
+	if err := validateGeoIP(&config.GeoIP); err != nil {
+		return fmt.Errorf("geoip config: %w", err)
+	}
+
+	// Validate repeater groups
+	if err := validateGroups(config.Groups); err != nil {
+		return fmt.Errorf("groups config: %w", err)
+	}
+
+	// Validate runtime monitor configuration
+	if err := validateHealth(&config.Health); err != nil {
+		return fmt.Errorf("health config: %w", err)
+	}
+
+	// Validate clock check configuration
+	if err := validateClockCheck(&config.ClockCheck); err != nil {
+		return fmt.Errorf("clock_check config: %w", err)
+	}
+
+	// Validate population announcements
+	if err := validatePopulation(&config.Population); err != nil {
+		return fmt.Errorf("population config: %w", err)
+	}
+
+	// Validate talk log export
+	if err := validateTalkExport(&config.TalkExport); err != nil {
+		return fmt.Errorf("talk_export config: %w", err)
+	}
+
+	// Validate external links
+	if err := validateExternalLinks(config.ExternalLinks); err != nil {
+		return fmt.Errorf("external_links config: %w", err)
+	}
+
+	// Validate feature flags
+	if err := validateFeatures(config.Features); err != nil {
+		return fmt.Errorf("features config: %w", err)
+	}
+
+	// Validate DMR ID overrides
+	if err := validateDMRIDs(&config.DMRIDs); err != nil {
+		return fmt.Errorf("dmr_ids config: %w", err)
+	}
+
+	return nil
+}
+
+// validateFeatures rejects unknown feature flags, most likely typos
+func validateFeatures(features map[string]bool) error {
+	names := make([]string, 0, len(features))
+	for name := range features {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := KnownFeatures[name]; !ok {
+			return fmt.Errorf("unknown feature %q", name)
+		}
+	}
 	return nil
 }
 
@@ -49,10 +162,18 @@ func validateServer(config *ServerConfig) error {
 		return fmt.Errorf("invalid port: %d", config.Port)
 	}
 
+	if err := validateListen(config.Listen); err != nil {
+		return err
+	}
+
 	if config.MaxConnections < 1 {
 		return fmt.Errorf("max_connections must be at least 1")
 	}
 
+	if config.MaxConnectionsPerIP < 0 {
+		return fmt.Errorf("max_connections_per_ip cannot be negative")
+	}
+
 	if config.Timeout <= 0 {
 		return fmt.Errorf("timeout must be positive")
 	}
@@ -69,6 +190,16 @@ func validateServer(config *ServerConfig) error {
 		return fmt.Errorf("description too long (max 14 characters): %s", config.Description)
 	}
 
+	if config.ReflectorID != "" {
+		if len(config.ReflectorID) != 5 || strings.Trim(config.ReflectorID, "0123456789") != "" {
+			return fmt.Errorf("reflector_id must be 5 digits: %s", config.ReflectorID)
+		}
+	}
+
+	if err := validateBranding(&config.Branding); err != nil {
+		return fmt.Errorf("branding: %w", err)
+	}
+
 	if config.TalkMaxDuration <= 0 {
 		return fmt.Errorf("talk_max_duration must be positive")
 	}
@@ -77,6 +208,205 @@ func validateServer(config *ServerConfig) error {
 		return fmt.Errorf("unmute_after cannot be negative")
 	}
 
+	for i, limit := range config.TalkLimits {
+		if err := validateTalkLimit(&limit); err != nil {
+			return fmt.Errorf("talk_limits[%d]: %w", i, err)
+		}
+	}
+
+	if config.BridgeTalkTimeout <= 0 {
+		return fmt.Errorf("bridge_talk_timeout must be positive")
+	}
+
+	if config.DrainTimeout < 0 {
+		return fmt.Errorf("drain_timeout cannot be negative")
+	}
+
+	if config.TransmitQuietPeriod < 0 {
+		return fmt.Errorf("transmit_quiet_period cannot be negative")
+	}
+
+	if config.Keepalive.NATTimeout < 0 || config.Keepalive.ErraticRatio < 0 {
+		return fmt.Errorf("keepalive nat_timeout and erratic_ratio cannot be negative")
+	}
+
+	if err := validatePacketVariants(config.PacketVariants); err != nil {
+		return fmt.Errorf("packet_variants: %w", err)
+	}
+
+	if err := validateAntiKerchunk(&config.AntiKerchunk); err != nil {
+		return fmt.Errorf("anti_kerchunk: %w", err)
+	}
+
+	if err := validateStatusReplies(&config.StatusReplies); err != nil {
+		return fmt.Errorf("status_replies: %w", err)
+	}
+
+	for _, pattern := range config.BroadcastPriority.Callsigns {
+		if _, err := path.Match(strings.ToUpper(pattern), ""); err != nil {
+			return fmt.Errorf("broadcast_priority: invalid callsign pattern %q", pattern)
+		}
+	}
+
+	if err := validateHold(&config.Hold); err != nil {
+		return fmt.Errorf("hold: %w", err)
+	}
+
+	if err := validateWelcome(&config.Welcome); err != nil {
+		return fmt.Errorf("welcome: %w", err)
+	}
+
+	if err := validatePassword(&config.Password); err != nil {
+		return fmt.Errorf("password: %w", err)
+	}
+
+	if err := validateLastHeardQuery(&config.LastHeardQuery); err != nil {
+		return fmt.Errorf("last_heard_query: %w", err)
+	}
+
+	return nil
+}
+
+// validateWelcome validates the welcome message template and webhooks
+func validateWelcome(config *WelcomeConfig) error {
+	if !config.Enabled {
+		return nil
+	}
+	if _, err := template.New("welcome").Parse(config.Message); err != nil {
+		return fmt.Errorf("invalid message template: %w", err)
+	}
+	for _, hook := range config.Webhooks {
+		if u, err := url.Parse(hook); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid webhook URL %q", hook)
+		}
+	}
+	return nil
+}
+
+// validateLastHeardQuery validates the Wires-X last heard query
+func validateLastHeardQuery(config *LastHeardQueryConfig) error {
+	if !config.Enabled {
+		return nil
+	}
+	if config.Count < 1 || config.Count > 20 {
+		return fmt.Errorf("count must be between 1 and 20")
+	}
+	if len(config.Queries) == 0 {
+		return fmt.Errorf("at least one query is required")
+	}
+	for _, query := range config.Queries {
+		if query = strings.TrimSpace(query); query == "" || len(query) > 16 {
+			return fmt.Errorf("query %q must be 1 to 16 characters", query)
+		}
+	}
+	return nil
+}
+
+// validateTalkLimit validates a per-callsign talk limit override
+func validateTalkLimit(config *TalkLimitConfig) error {
+	if strings.TrimSpace(config.Callsign) == "" {
+		return fmt.Errorf("callsign cannot be empty")
+	}
+	if config.TalkMaxDuration != nil && *config.TalkMaxDuration <= 0 {
+		return fmt.Errorf("talk_max_duration must be positive")
+	}
+	if config.UnmuteAfter != nil && *config.UnmuteAfter < 0 {
+		return fmt.Errorf("unmute_after cannot be negative")
+	}
+	return nil
+}
+
+// validateHold validates the net control hold limits
+func validateHold(config *HoldConfig) error {
+	if config.Duration <= 0 {
+		return fmt.Errorf("duration must be positive")
+	}
+	if config.MaxDuration < config.Duration {
+		return fmt.Errorf("max_duration cannot be shorter than duration")
+	}
+	return nil
+}
+
+// validatePacketVariants validates the packet variant actions
+func validatePacketVariants(variants map[string]string) error {
+	for key, action := range variants {
+		packetType := strings.ToUpper(key)
+		switch packetType {
+		case "YSFV", "YSFO", "YSFI":
+		default:
+			return fmt.Errorf("unsupported packet type %q (use YSFV, YSFO or YSFI)", key)
+		}
+		switch action {
+		case VariantLog, VariantIgnore:
+		case VariantReply:
+			if packetType != "YSFV" {
+				return fmt.Errorf("%s: reply is only supported for YSFV", packetType)
+			}
+		default:
+			return fmt.Errorf("%s: invalid action %q (use log, ignore or reply)", packetType, action)
+		}
+	}
+	return nil
+}
+
+// validateStatusReplies validates the YSFS status response restrictions
+func validateStatusReplies(config *StatusRepliesConfig) error {
+	switch config.Mode {
+	case "", StatusRepliesOpen, StatusRepliesKnown, StatusRepliesOff:
+	default:
+		return fmt.Errorf("invalid mode %q (use open, known or off)", config.Mode)
+	}
+
+	for _, entry := range config.Allow {
+		if _, _, err := net.ParseCIDR(entry); err != nil && net.ParseIP(entry) == nil {
+			return fmt.Errorf("invalid allow entry %q (use an IP or CIDR)", entry)
+		}
+	}
+
+	return nil
+}
+
+// validateBranding validates dashboard branding fields
+func validateBranding(config *BrandingConfig) error {
+	for name, value := range map[string]string{"logo_url": config.LogoURL, "website": config.Website} {
+		if value == "" {
+			continue
+		}
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("%s must be an http or https URL: %s", name, value)
+		}
+	}
+
+	if config.ContactEmail != "" && !strings.Contains(config.ContactEmail, "@") {
+		return fmt.Errorf("invalid contact_email: %s", config.ContactEmail)
+	}
+
+	return nil
+}
+
+// validateAntiKerchunk validates the anti-kerchunk policy
+func validateAntiKerchunk(config *AntiKerchunkConfig) error {
+	if !config.Enabled {
+		return nil
+	}
+
+	if config.MaxShortTransmissions < 1 {
+		return fmt.Errorf("max_short_transmissions must be at least 1")
+	}
+
+	if config.ShortThreshold <= 0 {
+		return fmt.Errorf("short_threshold must be positive")
+	}
+
+	if config.Window <= 0 {
+		return fmt.Errorf("window must be positive")
+	}
+
+	if config.Cooldown <= 0 {
+		return fmt.Errorf("cooldown must be positive")
+	}
+
 	return nil
 }
 
@@ -90,15 +420,51 @@ func validateWeb(config *WebConfig) error {
 		return fmt.Errorf("invalid port: %d", config.Port)
 	}
 
+	if err := validateListen(config.Listen); err != nil {
+		return err
+	}
+
 	if config.AuthRequired {
-		if config.Username == "" {
-			return fmt.Errorf("username required when auth is enabled")
+		if err := validateAuth(config); err != nil {
+			return err
 		}
-		if config.Password == "" {
-			return fmt.Errorf("password required when auth is enabled")
+	}
+
+	switch config.IPMasking {
+	case "", "full", "partial", "none":
+	default:
+		return fmt.Errorf("invalid ip_masking %q (use full, partial or none)", config.IPMasking)
+	}
+
+	if config.HeatmapWeeks < 0 {
+		return fmt.Errorf("heatmap_weeks must be non-negative")
+	}
+
+	if err := validateNotifications(&config.Notifications); err != nil {
+		return fmt.Errorf("notifications: %w", err)
+	}
+
+	return nil
+}
+
+// validateNotifications validates the dashboard notification rules
+func validateNotifications(config *NotificationConfig) error {
+	if !config.Enabled {
+		return nil
+	}
+
+	for _, event := range config.Events {
+		switch event {
+		case "talk_start", "talk_end", "connect", "disconnect":
+		default:
+			return fmt.Errorf("unsupported event %q (use talk_start, talk_end, connect or disconnect)", event)
 		}
 	}
 
+	if config.MaxWatch < 0 {
+		return fmt.Errorf("max_watch cannot be negative")
+	}
+
 	return nil
 }
 
@@ -122,12 +488,28 @@ func validateBridge(config *BridgeConfig) error {
 
 	// Permanent bridges don't need schedule/duration
 	if !config.Permanent {
-		if config.Schedule == "" {
+		windows := config.ScheduleWindows()
+		if len(windows) == 0 {
 			return fmt.Errorf("schedule cannot be empty for non-permanent bridge")
 		}
 
-		if config.Duration <= 0 {
-			return fmt.Errorf("duration must be positive for scheduled bridge")
+		if config.Timezone != "" {
+			if _, err := time.LoadLocation(config.Timezone); err != nil {
+				return fmt.Errorf("invalid timezone %q: %w", config.Timezone, err)
+			}
+		}
+
+		parser := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+		for i, window := range windows {
+			if window.Schedule == "" {
+				return fmt.Errorf("schedule window %d: schedule cannot be empty", i+1)
+			}
+			if window.Duration <= 0 {
+				return fmt.Errorf("duration must be positive for scheduled bridge")
+			}
+			if _, err := parser.Parse(window.Schedule); err != nil {
+				return fmt.Errorf("invalid schedule %q (expected six fields: second minute hour day-of-month month day-of-week): %w", window.Schedule, err)
+			}
 		}
 	}
 
@@ -140,6 +522,21 @@ func validateBridge(config *BridgeConfig) error {
 		return fmt.Errorf("health_check cannot be negative")
 	}
 
+	switch config.Protocol {
+	case "", BridgeProtocolAuto, BridgeProtocolYSFReflector, BridgeProtocolPYSFReflector, BridgeProtocolGeneric:
+	default:
+		return fmt.Errorf("invalid protocol %q (expected auto, ysfreflector, pysfreflector or generic)", config.Protocol)
+	}
+
+	if len(config.Callsigns) > 0 && config.RxOnly {
+		return fmt.Errorf("callsigns cannot be used with rx_only")
+	}
+	for _, callsign := range config.Callsigns {
+		if strings.TrimSpace(callsign) == "" {
+			return fmt.Errorf("callsigns cannot contain an empty entry")
+		}
+	}
+
 	return nil
 }
 
@@ -173,6 +570,29 @@ func validateMQTT(config *MQTTConfig) error {
 	return nil
 }
 
+// validateBlocklist validates blocklist configuration
+func validateBlocklist(config *BlocklistConfig) error {
+	if !config.Enabled || len(config.Subscriptions) == 0 {
+		return nil
+	}
+
+	for _, sub := range config.Subscriptions {
+		u, err := url.Parse(sub)
+		if err != nil {
+			return fmt.Errorf("invalid subscription URL %q: %w", sub, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("subscription URL must be http or https: %s", sub)
+		}
+	}
+
+	if config.RefreshInterval < time.Minute {
+		return fmt.Errorf("refresh_interval must be at least 1m")
+	}
+
+	return nil
+}
+
 // validateLogging validates logging configuration
 func validateLogging(config *LoggingConfig) error {
 	validLevels := []string{"debug", "info", "warn", "error"}
@@ -225,6 +645,534 @@ func validateMetrics(config *MetricsConfig) error {
 	return nil
 }
 
+// validateMaintenance validates maintenance scheduler configuration
+func validateMaintenance(config *MaintenanceConfig) error {
+	if !config.Enabled {
+		return nil
+	}
+
+	if config.Schedule == "" {
+		return fmt.Errorf("schedule cannot be empty")
+	}
+
+	parser := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+	if _, err := parser.Parse(config.Schedule); err != nil {
+		return fmt.Errorf("invalid schedule %q: %w", config.Schedule, err)
+	}
+
+	if config.TaskTimeout < 0 {
+		return fmt.Errorf("task_timeout cannot be negative")
+	}
+
+	if config.TalkLogRetention < 0 {
+		return fmt.Errorf("talk_log_retention cannot be negative")
+	}
+
+	if config.EventRetention < 0 {
+		return fmt.Errorf("event_retention cannot be negative")
+	}
+
+	if config.IPRetention < 0 {
+		return fmt.Errorf("ip_retention cannot be negative")
+	}
+
+	return nil
+}
+
+// validateAlerting validates alerting configuration
+func validateAlerting(config *AlertingConfig) error {
+	if !config.Enabled {
+		return nil
+	}
+
+	if config.Interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+
+	for _, hook := range config.Webhooks {
+		if _, err := url.Parse(hook); err != nil {
+			return fmt.Errorf("invalid webhook URL %q: %w", hook, err)
+		}
+	}
+
+	if err := validateEmail(&config.Email); err != nil {
+		return fmt.Errorf("email: %w", err)
+	}
+
+	validOperators := []string{"<", "<=", ">", ">=", "==", "!="}
+	names := make(map[string]bool)
+	for i, rule := range config.Rules {
+		if rule.Name == "" {
+			return fmt.Errorf("rules[%d]: name cannot be empty", i)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("rules[%d]: duplicate rule name: %s", i, rule.Name)
+		}
+		names[rule.Name] = true
+
+		if rule.Metric == "" {
+			return fmt.Errorf("rules[%d]: metric cannot be empty", i)
+		}
+		if !contains(validOperators, rule.Operator) {
+			return fmt.Errorf("rules[%d]: invalid operator: %s (must be one of: %s)",
+				i, rule.Operator, strings.Join(validOperators, ", "))
+		}
+		if rule.For < 0 {
+			return fmt.Errorf("rules[%d]: for cannot be negative", i)
+		}
+	}
+
+	return nil
+}
+
+// validateEmail validates alert email configuration
+func validateEmail(config *EmailConfig) error {
+	if !config.Enabled {
+		return nil
+	}
+
+	if config.Host == "" {
+		return fmt.Errorf("host cannot be empty")
+	}
+	if config.Port < 1 || config.Port > 65535 {
+		return fmt.Errorf("invalid port: %d", config.Port)
+	}
+	if _, err := mail.ParseAddress(config.From); err != nil {
+		return fmt.Errorf("invalid from address %q: %w", config.From, err)
+	}
+	if len(config.To) == 0 {
+		return fmt.Errorf("at least one recipient is required")
+	}
+	for _, to := range config.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return fmt.Errorf("invalid recipient %q: %w", to, err)
+		}
+	}
+
+	validTLS := []string{"starttls", "tls", "none"}
+	if !contains(validTLS, config.TLS) {
+		return fmt.Errorf("invalid tls: %s (must be one of: %s)", config.TLS, strings.Join(validTLS, ", "))
+	}
+	if config.MinInterval < 0 {
+		return fmt.Errorf("min_interval cannot be negative")
+	}
+	if _, err := template.New("subject").Parse(config.Subject); err != nil {
+		return fmt.Errorf("invalid subject template: %w", err)
+	}
+	if _, err := template.New("body").Parse(config.Body); err != nil {
+		return fmt.Errorf("invalid body template: %w", err)
+	}
+
+	return nil
+}
+
+// validateMirror validates traffic mirroring configuration
+func validateMirror(config *MirrorConfig) error {
+	if config.Target == "" {
+		if config.Enabled {
+			return fmt.Errorf("target cannot be empty when enabled")
+		}
+		return nil
+	}
+
+	u, err := url.Parse(config.Target)
+	if err != nil {
+		return fmt.Errorf("invalid target %q: %w", config.Target, err)
+	}
+	switch {
+	case u.Scheme == "udp" && u.Host != "":
+	case u.Scheme == "unix" && u.Path != "":
+	default:
+		return fmt.Errorf("invalid target %q (must be udp://host:port or unix:///path)", config.Target)
+	}
+
+	if config.SampleEvery < 1 {
+		return fmt.Errorf("sample_every must be at least 1")
+	}
+	if config.RateLimit < 0 {
+		return fmt.Errorf("rate_limit cannot be negative")
+	}
+
+	return nil
+}
+
+// validateSnapshot validates the periodic stats snapshot configuration
+func validateSnapshot(config *SnapshotConfig) error {
+	if !config.Enabled {
+		return nil
+	}
+
+	if config.Path == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+	if config.Interval < time.Second {
+		return fmt.Errorf("interval must be at least 1s")
+	}
+	if config.TalkLogTail < 0 {
+		return fmt.Errorf("talk_log_tail cannot be negative")
+	}
+
+	return nil
+}
+
+// validateBridgeStats validates the bridge statistics persistence
+func validateBridgeStats(config *BridgeStatsConfig) error {
+	if config.RetentionDays < 0 {
+		return fmt.Errorf("retention_days cannot be negative")
+	}
+	if config.History < 1 {
+		return fmt.Errorf("history must be at least 1")
+	}
+
+	return nil
+}
+
+// validateBridgeDampening validates the bridge link state dampening
+func validateBridgeDampening(config *BridgeDampeningConfig) error {
+	if config.Transitions < 0 {
+		return fmt.Errorf("transitions cannot be negative")
+	}
+	if config.Transitions > 0 && config.Window <= 0 {
+		return fmt.Errorf("window must be positive when dampening is enabled")
+	}
+
+	return nil
+}
+
+// validateDemo validates the demo traffic generator
+func validateDemo(config *DemoConfig) error {
+	if !config.Enabled {
+		return nil
+	}
+	if config.Repeaters < 1 || config.Repeaters > 99 {
+		return fmt.Errorf("repeaters must be between 1 and 99")
+	}
+	if config.TalkInterval <= 0 || config.TalkDuration <= 0 {
+		return fmt.Errorf("talk_interval and talk_duration must be positive")
+	}
+	if config.Churn < 0 {
+		return fmt.Errorf("churn cannot be negative")
+	}
+
+	return nil
+}
+
+// validateSNMP validates the SNMP agent configuration
+func validateSNMP(config *SNMPConfig) error {
+	if !config.Enabled {
+		return nil
+	}
+
+	if err := validateListen([]string{config.Listen}); err != nil {
+		return err
+	}
+	if config.Community == "" {
+		return fmt.Errorf("community cannot be empty")
+	}
+	oid := strings.TrimPrefix(config.BaseOID, ".")
+	if oid == "" {
+		return fmt.Errorf("base_oid cannot be empty")
+	}
+	for _, arc := range strings.Split(oid, ".") {
+		if _, err := strconv.ParseUint(arc, 10, 32); err != nil {
+			return fmt.Errorf("invalid base_oid %q", config.BaseOID)
+		}
+	}
+
+	return nil
+}
+
+// validateGeoIP validates geo enrichment and the country policy
+func validateGeoIP(config *GeoIPConfig) error {
+	if len(config.AllowCountries) > 0 && len(config.DenyCountries) > 0 {
+		return fmt.Errorf("allow_countries and deny_countries cannot both be set")
+	}
+	policy := len(config.AllowCountries) > 0 || len(config.DenyCountries) > 0 || config.AlertNewCountry
+	if policy && config.CountryDB == "" {
+		return fmt.Errorf("country_db is required for country policies")
+	}
+
+	for _, list := range [][]string{config.AllowCountries, config.DenyCountries} {
+		for _, code := range list {
+			if len(code) != 2 || strings.ToUpper(code) == strings.ToLower(code) {
+				return fmt.Errorf("invalid country code %q (use ISO 3166-1 alpha-2, e.g. US)", code)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateGroups validates repeater group names and callsign patterns
+func validateGroups(groups []GroupConfig) error {
+	seen := make(map[string]bool)
+	for i, group := range groups {
+		name := strings.ToLower(strings.TrimSpace(group.Name))
+		if name == "" {
+			return fmt.Errorf("group[%d]: name is required", i)
+		}
+		if seen[name] {
+			return fmt.Errorf("duplicate group %q", group.Name)
+		}
+		seen[name] = true
+
+		for _, pattern := range group.Callsigns {
+			if _, err := path.Match(strings.ToUpper(pattern), ""); err != nil {
+				return fmt.Errorf("group %q: invalid callsign pattern %q", group.Name, pattern)
+			}
+		}
+	}
+	return nil
+}
+
+// validateExternalLinks validates the external link registry
+func validateExternalLinks(links []ExternalLinkConfig) error {
+	seen := make(map[string]bool)
+	for i, link := range links {
+		name := strings.ToLower(strings.TrimSpace(link.Name))
+		if name == "" {
+			return fmt.Errorf("link[%d]: name is required", i)
+		}
+		if seen[name] {
+			return fmt.Errorf("duplicate link %q", link.Name)
+		}
+		seen[name] = true
+
+		switch link.Type {
+		case "allstar", "echolink", "other", "":
+		default:
+			return fmt.Errorf("link %q: type must be allstar, echolink or other", link.Name)
+		}
+		if u, err := url.Parse(link.StatusURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("link %q: invalid status_url %q", link.Name, link.StatusURL)
+		}
+		if link.Interval < 0 || link.Timeout < 0 {
+			return fmt.Errorf("link %q: interval and timeout cannot be negative", link.Name)
+		}
+	}
+	return nil
+}
+
+// validateHealth validates runtime monitor configuration
+func validateHealth(config *HealthConfig) error {
+	if !config.Enabled {
+		return nil
+	}
+	if config.Interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+	if config.MaxGoroutines < 0 || config.MaxHeapMB < 0 {
+		return fmt.Errorf("max_goroutines and max_heap_mb cannot be negative")
+	}
+	if config.QueueThreshold < 0 || config.QueueThreshold > 1 {
+		return fmt.Errorf("queue_threshold must be between 0 and 1, got %v", config.QueueThreshold)
+	}
+	if config.HeapDumpCooldown < 0 {
+		return fmt.Errorf("heap_dump_cooldown cannot be negative")
+	}
+	return nil
+}
+
+// maxPasswordLength keeps a room password within a YSFO options field
+const maxPasswordLength = 32
+
+// validatePassword validates the room password and its exemptions
+func validatePassword(config *PasswordConfig) error {
+	if len(config.Token) > maxPasswordLength {
+		return fmt.Errorf("token cannot be longer than %d characters", maxPasswordLength)
+	}
+	for _, c := range config.Token {
+		if c <= 0x20 || c > 0x7E || c == ';' || c == ',' {
+			return fmt.Errorf("token must be printable ASCII without spaces, commas or semicolons")
+		}
+	}
+	for _, pattern := range config.Exempt {
+		if _, err := path.Match(strings.ToUpper(pattern), ""); err != nil {
+			return fmt.Errorf("invalid exempt callsign pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+// validateClockCheck validates NTP clock check configuration
+func validateClockCheck(config *ClockCheckConfig) error {
+	if !config.Enabled {
+		return nil
+	}
+	if len(config.Servers) == 0 {
+		return fmt.Errorf("at least one server is required")
+	}
+	for _, server := range config.Servers {
+		if strings.TrimSpace(server) == "" {
+			return fmt.Errorf("server cannot be empty")
+		}
+	}
+	if config.Interval <= 0 || config.Threshold <= 0 || config.Timeout <= 0 {
+		return fmt.Errorf("interval, threshold and timeout must be positive")
+	}
+	return nil
+}
+
+// validatePopulation validates population announcement configuration
+func validatePopulation(config *PopulationConfig) error {
+	if !config.Enabled {
+		return nil
+	}
+	if config.Debounce <= 0 {
+		return fmt.Errorf("debounce must be positive")
+	}
+	for _, hook := range config.Webhooks {
+		if u, err := url.Parse(hook); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid webhook URL %q", hook)
+		}
+	}
+	return nil
+}
+
+// validateTalkExport validates talk log export configuration
+func validateTalkExport(config *TalkExportConfig) error {
+	if !config.Enabled {
+		return nil
+	}
+	if config.BatchSize <= 0 {
+		return fmt.Errorf("batch_size must be positive")
+	}
+	if config.FlushInterval <= 0 || config.RetryInterval <= 0 {
+		return fmt.Errorf("flush_interval and retry_interval must be positive")
+	}
+	if config.MaxPending < 0 {
+		return fmt.Errorf("max_pending cannot be negative")
+	}
+	if config.CSVWebhook == "" && config.GoogleSheets.SpreadsheetID == "" {
+		return fmt.Errorf("csv_webhook or google_sheets.spreadsheet_id is required")
+	}
+	if config.CSVWebhook != "" {
+		if u, err := url.Parse(config.CSVWebhook); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid csv_webhook URL %q", config.CSVWebhook)
+		}
+	}
+	if config.GoogleSheets.SpreadsheetID != "" && config.GoogleSheets.CredentialsFile == "" {
+		return fmt.Errorf("google_sheets.credentials_file is required")
+	}
+	return nil
+}
+
+// validateDMRIDs validates the DMR ID overrides; a callsign and an ID may each
+// appear once
+func validateDMRIDs(config *DMRIDConfig) error {
+	callsigns := make(map[string]bool)
+	ids := make(map[uint32]bool)
+	for i, o := range config.Overrides {
+		callsign := strings.ToUpper(strings.TrimSpace(o.Callsign))
+		if callsign == "" {
+			return fmt.Errorf("override[%d]: callsign is required", i)
+		}
+		if o.ID == 0 || o.ID > 16777215 {
+			return fmt.Errorf("override %q: invalid DMR ID %d", o.Callsign, o.ID)
+		}
+		if callsigns[callsign] {
+			return fmt.Errorf("duplicate override for %q", o.Callsign)
+		}
+		if ids[o.ID] {
+			return fmt.Errorf("DMR ID %d is mapped more than once", o.ID)
+		}
+		callsigns[callsign] = true
+		ids[o.ID] = true
+	}
+	return nil
+}
+
+// validateAuth validates the authentication backend used when auth is enabled
+func validateAuth(config *WebConfig) error {
+	auth := &config.Auth
+	switch auth.Backend {
+	case "", AuthBackendLocal:
+		if config.Username == "" {
+			return fmt.Errorf("username required when auth is enabled")
+		}
+		if config.Password == "" {
+			return fmt.Errorf("password required when auth is enabled")
+		}
+		return nil
+	case AuthBackendProxy:
+		if auth.Proxy.UserHeader == "" {
+			return fmt.Errorf("auth: proxy user_header is required")
+		}
+		if len(auth.Proxy.TrustedProxies) == 0 {
+			return fmt.Errorf("auth: proxy trusted_proxies is required")
+		}
+		for _, entry := range auth.Proxy.TrustedProxies {
+			if _, _, err := net.ParseCIDR(entry); err != nil && net.ParseIP(entry) == nil {
+				return fmt.Errorf("auth: invalid trusted proxy %q (use an IP or CIDR)", entry)
+			}
+		}
+	case AuthBackendOIDC:
+		oidc := &auth.OIDC
+		if oidc.Issuer == "" || oidc.ClientID == "" || oidc.RedirectURL == "" {
+			return fmt.Errorf("auth: oidc issuer, client_id and redirect_url are required")
+		}
+		for name, raw := range map[string]string{"issuer": oidc.Issuer, "redirect_url": oidc.RedirectURL} {
+			u, err := url.Parse(raw)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("auth: oidc %s must be an http(s) URL", name)
+			}
+		}
+		if !SecureURL(oidc.Issuer) {
+			return fmt.Errorf("auth: oidc issuer must use https (http only on loopback)")
+		}
+	default:
+		return fmt.Errorf("auth: invalid backend %q (use local, proxy or oidc)", auth.Backend)
+	}
+
+	switch auth.DefaultRole {
+	case RoleAdmin, RoleViewer, RoleNone:
+	default:
+		return fmt.Errorf("auth: invalid default_role %q (use admin, viewer or none)", auth.DefaultRole)
+	}
+	return nil
+}
+
+// SecureURL reports whether raw is an https URL, or an http one to a loopback
+// host for trying out a provider locally
+func SecureURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	switch u.Scheme {
+	case "https":
+		return true
+	case "http":
+		host := u.Hostname()
+		ip := net.ParseIP(host)
+		return host == "localhost" || (ip != nil && ip.IsLoopback())
+	default:
+		return false
+	}
+}
+
+// validateListen validates a list of host:port listen addresses
+func validateListen(addrs []string) error {
+	seen := make(map[string]bool)
+	for _, addr := range addrs {
+		host, portStr, err := net.SplitHostPort(addr)
+		if err != nil {
+			return fmt.Errorf("invalid listen address %q: %w", addr, err)
+		}
+		if port, err := strconv.Atoi(portStr); err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("invalid listen address %q: invalid port", addr)
+		}
+		if host != "" && host != "localhost" && net.ParseIP(host) == nil {
+			return fmt.Errorf("invalid listen address %q: host must be an IP address", addr)
+		}
+		if seen[addr] {
+			return fmt.Errorf("duplicate listen address: %s", addr)
+		}
+		seen[addr] = true
+	}
+	return nil
+}
+
 // contains checks if a slice contains a string
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
@@ -0,0 +1,239 @@
+// Package demo generates simulated repeaters and traffic for previewing the
+// dashboard and testing integrations without RF hardware. The repeaters are
+// loopback UDP sockets that poll the reflector's own YSF port and take turns
+// sending transmissions, so everything downstream of the network server
+// (events, talk log, webhooks, MQTT) sees them like real traffic.
+package demo
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/network"
+)
+
+const (
+	// pollInterval is how often linked repeaters poll, as MMDVM gateways do
+	pollInterval = 5 * time.Second
+	// frameInterval is the length of one YSF voice frame
+	frameInterval = 100 * time.Millisecond
+	// relinkDelay is how long a churned repeater stays away before relinking
+	relinkDelay = 30 * time.Second
+	// defaultMinPause keeps transmissions apart; the reflector ends a local
+	// transmission only after three seconds without frames, and one
+	// starting sooner would be dropped as a doubling
+	defaultMinPause = 5 * time.Second
+)
+
+// talkers are the source callsigns of demo transmissions
+var talkers = []string{"N0CALL", "W1DEMO", "K2DEMO", "VE3DMO", "G4DEMO", "DL5DMO", "JA6DMO", "VK7DMO"}
+
+// station is a simulated repeater
+type station struct {
+	callsign string
+	conn     *net.UDPConn
+	linked   bool
+	relinkAt time.Time // When an unlinked station links again
+}
+
+// Generator links simulated repeaters to a reflector and transmits from them
+type Generator struct {
+	cfg    config.DemoConfig
+	target *net.UDPAddr
+	logger *logger.Logger
+	rand   *rand.Rand
+
+	minPause time.Duration // Shortest pause between transmissions
+}
+
+// New creates a generator sending to the reflector's YSF port at target
+func New(cfg config.DemoConfig, target *net.UDPAddr, log *logger.Logger) *Generator {
+	return &Generator{
+		cfg:    cfg,
+		target: target,
+		logger: log.WithComponent("demo"),
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+
+		minPause: defaultMinPause,
+	}
+}
+
+// Run links the simulated repeaters and generates traffic until ctx is
+// cancelled, then unlinks them
+func (g *Generator) Run(ctx context.Context) error {
+	stations, err := g.bind()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		for _, st := range stations {
+			if st.linked {
+				g.send(st, network.CreatePollPacket(network.PacketTypeUnlink, st.callsign))
+			}
+			_ = st.conn.Close()
+		}
+	}()
+
+	g.logger.Info("Demo traffic started",
+		logger.Int("repeaters", len(stations)),
+		logger.String("target", g.target.String()))
+	for _, st := range stations {
+		g.link(st)
+	}
+
+	pollTicker := time.NewTicker(pollInterval)
+	defer pollTicker.Stop()
+	talkTimer := time.NewTimer(g.pause())
+	defer talkTimer.Stop()
+	// Each repeater relinks about once per churn period
+	var churn <-chan time.Time
+	var churnTimer *time.Timer
+	if g.cfg.Churn > 0 {
+		churnTimer = time.NewTimer(g.churnDelay(len(stations)))
+		defer churnTimer.Stop()
+		churn = churnTimer.C
+	}
+
+	var talking *station
+	done := make(chan struct{})
+	for {
+		select {
+		case <-ctx.Done():
+			if talking != nil {
+				<-done
+			}
+			return nil
+
+		case now := <-pollTicker.C:
+			for _, st := range stations {
+				switch {
+				case st.linked:
+					g.send(st, network.CreatePollPacket(network.PacketTypePoll, st.callsign))
+				case !now.Before(st.relinkAt):
+					g.link(st)
+				}
+			}
+
+		case <-talkTimer.C:
+			// Re-armed once the transmission is done, so only one is on air
+			if talking = g.pick(stations, nil); talking == nil {
+				talkTimer.Reset(g.pause())
+				break
+			}
+			go func(st *station, source string, frames int) {
+				g.transmit(ctx, st, source, frames)
+				done <- struct{}{}
+			}(talking, talkers[g.rand.Intn(len(talkers))], int(g.jitter(g.cfg.TalkDuration)/frameInterval)+2)
+
+		case <-done:
+			talking = nil
+			talkTimer.Reset(g.pause())
+
+		case now := <-churn:
+			if st := g.pick(stations, talking); st != nil {
+				g.logger.Debug("Demo repeater relinking", logger.String("callsign", st.callsign))
+				g.send(st, network.CreatePollPacket(network.PacketTypeUnlink, st.callsign))
+				st.linked = false
+				st.relinkAt = now.Add(g.jitter(relinkDelay))
+			}
+			churnTimer.Reset(g.churnDelay(len(stations)))
+		}
+	}
+}
+
+// bind opens a socket for each simulated repeater, on the loopback address
+// of the target's family
+func (g *Generator) bind() ([]*station, error) {
+	local := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}
+	if g.target.IP.To4() == nil {
+		local = &net.UDPAddr{IP: net.IPv6loopback}
+	}
+
+	stations := make([]*station, 0, g.cfg.Repeaters)
+	for i := 1; i <= g.cfg.Repeaters; i++ {
+		conn, err := net.ListenUDP("udp", local)
+		if err != nil {
+			for _, st := range stations {
+				_ = st.conn.Close()
+			}
+			return nil, fmt.Errorf("bind demo repeater: %w", err)
+		}
+		st := &station{callsign: fmt.Sprintf("DEMO%02d", i), conn: conn}
+		stations = append(stations, st)
+		go discard(conn)
+	}
+	return stations, nil
+}
+
+// discard reads and drops what the reflector sends a station until its
+// socket is closed
+func discard(conn *net.UDPConn) {
+	buf := make([]byte, 1024)
+	for {
+		if _, _, err := conn.ReadFromUDP(buf); err != nil {
+			return
+		}
+	}
+}
+
+// link polls the reflector to link a station
+func (g *Generator) link(st *station) {
+	g.send(st, network.CreatePollPacket(network.PacketTypePoll, st.callsign))
+	st.linked = true
+}
+
+// send writes a packet from a station to the reflector
+func (g *Generator) send(st *station, data []byte) {
+	if _, err := st.conn.WriteToUDP(data, g.target); err != nil {
+		g.logger.Debug("Demo repeater send failed", logger.String("callsign", st.callsign), logger.Error(err))
+	}
+}
+
+// pick returns a random linked station other than skip, or nil if there is none
+func (g *Generator) pick(stations []*station, skip *station) *station {
+	var candidates []*station
+	for _, st := range stations {
+		if st.linked && st != skip {
+			candidates = append(candidates, st)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	return candidates[g.rand.Intn(len(candidates))]
+}
+
+// transmit sends a transmission of the given number of frames from a station
+// in real time: a header, voice frames and a terminator
+func (g *Generator) transmit(ctx context.Context, st *station, source string, frames int) {
+	ticker := time.NewTicker(frameInterval)
+	defer ticker.Stop()
+	for i := 0; i < frames; i++ {
+		last := i == frames-1 || ctx.Err() != nil
+		g.send(st, network.CreateDataPacket(st.callsign, source, i, last))
+		if last {
+			return
+		}
+		<-ticker.C
+	}
+}
+
+// jitter returns a random duration between half and one and a half times d
+func (g *Generator) jitter(d time.Duration) time.Duration {
+	return d/2 + time.Duration(g.rand.Int63n(int64(d)+1))
+}
+
+// pause returns the time until the next transmission
+func (g *Generator) pause() time.Duration {
+	return max(g.jitter(g.cfg.TalkInterval), g.minPause)
+}
+
+// churnDelay returns the time until the next repeater relinks
+func (g *Generator) churnDelay(stations int) time.Duration {
+	return g.jitter(g.cfg.Churn / time.Duration(stations))
+}
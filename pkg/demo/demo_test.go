@@ -0,0 +1,85 @@
+package demo
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/network"
+)
+
+func TestGeneratorLinksTransmitsAndUnlinks(t *testing.T) {
+	reflector, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer func() { _ = reflector.Close() }()
+
+	g := New(config.DemoConfig{
+		Enabled:      true,
+		Repeaters:    2,
+		TalkInterval: 50 * time.Millisecond,
+		TalkDuration: 300 * time.Millisecond,
+	}, reflector.LocalAddr().(*net.UDPAddr), logger.NewTestLogger(io.Discard))
+	g.minPause = 0
+
+	ctx, cancel := context.WithCancel(context.Background())
+	exited := make(chan error, 1)
+	go func() { exited <- g.Run(ctx) }()
+
+	polled := make(map[string]bool)
+	headers, terminators := 0, 0
+	buf := make([]byte, 1024)
+	_ = reflector.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for terminators == 0 {
+		n, _, err := reflector.ReadFromUDP(buf)
+		if err != nil {
+			t.Fatalf("polls %v, headers %d: %v", polled, headers, err)
+		}
+		packet, err := network.ParsePacket(buf[:n], nil)
+		if err != nil {
+			t.Fatalf("unparseable demo packet: %v", err)
+		}
+		switch packet.Type {
+		case network.PacketTypePoll:
+			polled[packet.Callsign] = true
+		case network.PacketTypeData:
+			if !strings.HasPrefix(packet.Callsign, "DEMO") {
+				t.Errorf("data from unexpected gateway %q", packet.Callsign)
+			}
+			fich, _ := network.DecodeFICH(buf[network.FrameOffset : network.FrameOffset+network.FrameSize])
+			switch {
+			case fich.FI == network.FIHeader:
+				headers++
+			case packet.IsTerminator():
+				terminators++
+			}
+		}
+	}
+	if !polled["DEMO01"] || !polled["DEMO02"] {
+		t.Errorf("expected both demo repeaters to poll, got %v", polled)
+	}
+	if headers != 1 {
+		t.Errorf("expected one header before the terminator, got %d", headers)
+	}
+
+	cancel()
+	if err := <-exited; err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	unlinked := make(map[string]bool)
+	for len(unlinked) < 2 {
+		n, _, err := reflector.ReadFromUDP(buf)
+		if err != nil {
+			t.Fatalf("unlinks %v: %v", unlinked, err)
+		}
+		if packet, err := network.ParsePacket(buf[:n], nil); err == nil && packet.Type == network.PacketTypeUnlink {
+			unlinked[packet.Callsign] = true
+		}
+	}
+}
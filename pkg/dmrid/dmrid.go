@@ -0,0 +1,166 @@
+// Package dmrid maps YSF callsigns to DMR IDs and back. Local overrides take
+// precedence over a downloaded database such as RadioID's, so club calls,
+// special event callsigns and users missing from the public database can
+// still be resolved.
+package dmrid
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MaxID is the largest DMR ID (24 bits)
+const MaxID = 16777215
+
+// Entry sources
+const (
+	SourceOverride = "override"
+	SourceDatabase = "database"
+)
+
+// Entry maps a callsign to a DMR ID
+type Entry struct {
+	Callsign string `json:"callsign"`
+	ID       uint32 `json:"id"`
+	Name     string `json:"name,omitempty"`
+	Source   string `json:"source,omitempty"`
+}
+
+// Database is a callsign/DMR ID database consulted when no override matches
+type Database interface {
+	ByCallsign(callsign string) (Entry, bool)
+	ByID(id uint32) (Entry, bool)
+}
+
+// Directory resolves callsigns and DMR IDs, overrides first
+type Directory struct {
+	mu         sync.RWMutex
+	byCallsign map[string]Entry
+	byID       map[uint32]string
+	database   Database
+}
+
+// New returns a directory with the given overrides
+func New(overrides []Entry) (*Directory, error) {
+	d := &Directory{
+		byCallsign: make(map[string]Entry),
+		byID:       make(map[uint32]string),
+	}
+	for _, e := range overrides {
+		if err := d.Put(e); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// SetDatabase sets the database consulted when no override matches (nil = none)
+func (d *Directory) SetDatabase(db Database) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.database = db
+}
+
+// Put adds an override or replaces the one for the same callsign. An ID may
+// belong to one callsign only.
+func (d *Directory) Put(e Entry) error {
+	e.Callsign = normalize(e.Callsign)
+	if e.Callsign == "" {
+		return fmt.Errorf("callsign is required")
+	}
+	if e.ID == 0 || e.ID > MaxID {
+		return fmt.Errorf("invalid DMR ID %d", e.ID)
+	}
+	e.Source = SourceOverride
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if owner, ok := d.byID[e.ID]; ok && owner != e.Callsign {
+		return fmt.Errorf("DMR ID %d is already mapped to %s", e.ID, owner)
+	}
+	if old, ok := d.byCallsign[e.Callsign]; ok {
+		delete(d.byID, old.ID)
+	}
+	d.byCallsign[e.Callsign] = e
+	d.byID[e.ID] = e.Callsign
+	return nil
+}
+
+// Delete removes the override for a callsign. It reports whether one existed.
+func (d *Directory) Delete(callsign string) bool {
+	callsign = normalize(callsign)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	e, ok := d.byCallsign[callsign]
+	if !ok {
+		return false
+	}
+	delete(d.byCallsign, callsign)
+	delete(d.byID, e.ID)
+	return true
+}
+
+// Overrides returns the overrides in callsign order
+func (d *Directory) Overrides() []Entry {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	entries := make([]Entry, 0, len(d.byCallsign))
+	for _, e := range d.byCallsign {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Callsign < entries[j].Callsign })
+	return entries
+}
+
+// ByCallsign returns the DMR ID of a callsign
+func (d *Directory) ByCallsign(callsign string) (Entry, bool) {
+	callsign = normalize(callsign)
+
+	d.mu.RLock()
+	e, ok := d.byCallsign[callsign]
+	db := d.database
+	d.mu.RUnlock()
+	if ok {
+		return e, true
+	}
+	return lookup(db, func(db Database) (Entry, bool) { return db.ByCallsign(callsign) })
+}
+
+// ByID returns the callsign of a DMR ID
+func (d *Directory) ByID(id uint32) (Entry, bool) {
+	d.mu.RLock()
+	callsign, ok := d.byID[id]
+	e := d.byCallsign[callsign]
+	db := d.database
+	d.mu.RUnlock()
+	if ok {
+		return e, true
+	}
+	return lookup(db, func(db Database) (Entry, bool) { return db.ByID(id) })
+}
+
+// lookup queries the database, if any, and marks the result's source
+func lookup(db Database, query func(Database) (Entry, bool)) (Entry, bool) {
+	if db == nil {
+		return Entry{}, false
+	}
+	e, ok := query(db)
+	if !ok {
+		return Entry{}, false
+	}
+	e.Source = SourceDatabase
+	return e, true
+}
+
+// normalize upper-cases a callsign and drops any suffix such as "-ND" or "/P"
+func normalize(callsign string) string {
+	callsign = strings.ToUpper(strings.TrimSpace(callsign))
+	if i := strings.IndexAny(callsign, "-/ "); i >= 0 {
+		callsign = callsign[:i]
+	}
+	return callsign
+}
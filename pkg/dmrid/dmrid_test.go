@@ -0,0 +1,69 @@
+package dmrid
+
+import "testing"
+
+// fakeDatabase is a fixed Database
+type fakeDatabase map[string]uint32
+
+func (f fakeDatabase) ByCallsign(callsign string) (Entry, bool) {
+	id, ok := f[callsign]
+	return Entry{Callsign: callsign, ID: id}, ok
+}
+
+func (f fakeDatabase) ByID(id uint32) (Entry, bool) {
+	for callsign, v := range f {
+		if v == id {
+			return Entry{Callsign: callsign, ID: id}, true
+		}
+	}
+	return Entry{}, false
+}
+
+func TestOverridesTakePrecedence(t *testing.T) {
+	d, err := New([]Entry{{Callsign: "w1aw", ID: 3100001, Name: "ARRL"}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	d.SetDatabase(fakeDatabase{"W1AW": 3199999, "K1ABC": 3100002})
+
+	if e, ok := d.ByCallsign("W1AW-ND"); !ok || e.ID != 3100001 || e.Source != SourceOverride {
+		t.Errorf("expected the override for W1AW, got %+v %v", e, ok)
+	}
+	if e, ok := d.ByID(3100001); !ok || e.Callsign != "W1AW" {
+		t.Errorf("expected W1AW for the override ID, got %+v %v", e, ok)
+	}
+	if e, ok := d.ByCallsign("K1ABC"); !ok || e.ID != 3100002 || e.Source != SourceDatabase {
+		t.Errorf("expected the database entry for K1ABC, got %+v %v", e, ok)
+	}
+	if _, ok := d.ByCallsign("N0CALL"); ok {
+		t.Errorf("expected no entry for an unknown callsign")
+	}
+}
+
+func TestPutAndDelete(t *testing.T) {
+	d, _ := New(nil)
+	if err := d.Put(Entry{Callsign: "W1AW", ID: 3100001}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := d.Put(Entry{Callsign: "K1ABC", ID: 3100001}); err == nil {
+		t.Errorf("expected an error mapping one ID to two callsigns")
+	}
+	if err := d.Put(Entry{Callsign: "W1AW", ID: MaxID + 1}); err == nil {
+		t.Errorf("expected an error for an out of range ID")
+	}
+
+	// Remapping a callsign frees its old ID
+	if err := d.Put(Entry{Callsign: "W1AW", ID: 3100005}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, ok := d.ByID(3100001); ok {
+		t.Errorf("expected the old ID to be released")
+	}
+
+	if !d.Delete("w1aw") || d.Delete("W1AW") {
+		t.Errorf("expected exactly one successful delete")
+	}
+	if len(d.Overrides()) != 0 {
+		t.Errorf("expected no overrides, got %+v", d.Overrides())
+	}
+}
@@ -0,0 +1,109 @@
+// Package geoip looks up the country and autonomous system of repeater
+// addresses in MaxMind databases (GeoLite2/GeoIP2 Country or City, and ASN).
+package geoip
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+// countryRecord is the part of a Country or City record that is used
+type countryRecord struct {
+	Country struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	// RegisteredCountry is used when the database has no country for the
+	// network, e.g. for some anycast ranges
+	RegisteredCountry struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"registered_country"`
+}
+
+// asnRecord is an ASN database record
+type asnRecord struct {
+	Number       uint   `maxminddb:"autonomous_system_number"`
+	Organization string `maxminddb:"autonomous_system_organization"`
+}
+
+// Reader looks addresses up in a country database, an ASN database or both
+type Reader struct {
+	country *maxminddb.Reader
+	asn     *maxminddb.Reader
+}
+
+// Open opens the databases at the given paths; either may be empty
+func Open(countryPath, asnPath string) (*Reader, error) {
+	if countryPath == "" && asnPath == "" {
+		return nil, errors.New("no geoip database configured")
+	}
+
+	r := &Reader{}
+	if countryPath != "" {
+		db, err := maxminddb.Open(countryPath)
+		if err != nil {
+			return nil, fmt.Errorf("open country database %s: %w", countryPath, err)
+		}
+		r.country = db
+	}
+	if asnPath != "" {
+		db, err := maxminddb.Open(asnPath)
+		if err != nil {
+			_ = r.Close()
+			return nil, fmt.Errorf("open ASN database %s: %w", asnPath, err)
+		}
+		r.asn = db
+	}
+	return r, nil
+}
+
+// Lookup returns the location of ip, and false when no database knows it.
+// It has the signature of repeater.GeoLookup.
+func (r *Reader) Lookup(ip net.IP) (repeater.Geo, bool) {
+	var geo repeater.Geo
+	found := false
+
+	if r.country != nil {
+		var rec countryRecord
+		if err := r.country.Lookup(ip, &rec); err == nil {
+			code, names := rec.Country.ISOCode, rec.Country.Names
+			if code == "" {
+				code, names = rec.RegisteredCountry.ISOCode, rec.RegisteredCountry.Names
+			}
+			if code != "" {
+				geo.Country = code
+				geo.CountryName = names["en"]
+				found = true
+			}
+		}
+	}
+
+	if r.asn != nil {
+		var rec asnRecord
+		if err := r.asn.Lookup(ip, &rec); err == nil && rec.Number != 0 {
+			geo.ASN = rec.Number
+			geo.ASOrg = rec.Organization
+			found = true
+		}
+	}
+
+	return geo, found
+}
+
+// Close releases the databases
+func (r *Reader) Close() error {
+	var errs []error
+	if r.country != nil {
+		errs = append(errs, r.country.Close())
+	}
+	if r.asn != nil {
+		errs = append(errs, r.asn.Close())
+	}
+	return errors.Join(errs...)
+}
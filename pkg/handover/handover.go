@@ -0,0 +1,276 @@
+// Package handover passes the reflector's bound sockets to a new process so
+// the binary can be upgraded without a gap in service. The running process
+// starts the new binary with its UDP sockets and HTTP listeners as inherited
+// file descriptors, sends it the state that lives only in memory (web
+// sessions and connected repeaters) over a pipe, and waits for it to report
+// ready before shutting down. Because the new process keeps the very same
+// sockets, repeaters behind NAT keep their mappings and nothing is ever
+// refused a connection.
+//
+// The inherited descriptors start at fd 3. EnvVar lists their kinds in order,
+// e.g. "udp,tcp,state,ready": a UDP socket, a TCP listener, then the state
+// pipe to read and the ready pipe to write.
+package handover
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EnvVar names the environment variable that describes the inherited
+// descriptors
+const EnvVar = "YSF_NEXUS_HANDOVER"
+
+// Descriptor kinds
+const (
+	kindUDP   = "udp"
+	kindTCP   = "tcp"
+	kindState = "state"
+	kindReady = "ready"
+)
+
+// firstFD is the first inherited descriptor after stdin, stdout and stderr
+const firstFD = 3
+
+// State is the in-memory state handed to the new process
+type State struct {
+	// Sessions maps web session tokens to their expiry
+	Sessions map[string]time.Time `json:"sessions,omitempty"`
+	// SessionUsers holds the user and role of each session. Sessions handed
+	// over by versions without it belong to the local administrator.
+	SessionUsers map[string]SessionUser `json:"session_users,omitempty"`
+	// Repeaters are the connected repeaters, so their traffic is accepted
+	// before they poll the new process
+	Repeaters []Repeater `json:"repeaters,omitempty"`
+}
+
+// SessionUser is the user logged in with a web session
+type SessionUser struct {
+	User string `json:"user"`
+	Role string `json:"role"`
+}
+
+// Repeater is a connected repeater
+type Repeater struct {
+	Callsign string `json:"callsign"`
+	Address  string `json:"address"`
+}
+
+// Inherited holds the sockets and state a process received from the process
+// it replaces. A nil *Inherited binds new sockets, so callers need not check
+// whether they were started by a handover.
+type Inherited struct {
+	mu    sync.Mutex
+	udp   []*net.UDPConn
+	tcp   []net.Listener
+	state State
+	ready *os.File
+}
+
+// FromEnvironment returns the sockets and state inherited from the previous
+// process, or nil if this process was not started by a handover. The
+// environment variable is cleared so it does not leak into a later upgrade.
+func FromEnvironment() (*Inherited, error) {
+	spec, ok := os.LookupEnv(EnvVar)
+	if !ok {
+		return nil, nil
+	}
+	_ = os.Unsetenv(EnvVar)
+
+	h := &Inherited{}
+	var stateFile *os.File
+	for i, kind := range strings.Split(spec, ",") {
+		fd := uintptr(firstFD + i)
+		f := os.NewFile(fd, fmt.Sprintf("handover-%s-%d", kind, fd))
+		if f == nil {
+			h.Close()
+			return nil, fmt.Errorf("invalid inherited descriptor %d", fd)
+		}
+
+		switch kind {
+		case kindUDP:
+			pc, err := net.FilePacketConn(f)
+			_ = f.Close()
+			if err != nil {
+				h.Close()
+				return nil, fmt.Errorf("inherited UDP socket %d: %w", fd, err)
+			}
+			conn, ok := pc.(*net.UDPConn)
+			if !ok {
+				_ = pc.Close()
+				h.Close()
+				return nil, fmt.Errorf("inherited descriptor %d is not a UDP socket", fd)
+			}
+			h.udp = append(h.udp, conn)
+		case kindTCP:
+			l, err := net.FileListener(f)
+			_ = f.Close()
+			if err != nil {
+				h.Close()
+				return nil, fmt.Errorf("inherited TCP listener %d: %w", fd, err)
+			}
+			h.tcp = append(h.tcp, l)
+		case kindState:
+			stateFile = f
+		case kindReady:
+			h.ready = f
+		default:
+			_ = f.Close()
+			h.Close()
+			return nil, fmt.Errorf("unknown inherited descriptor kind %q", kind)
+		}
+	}
+
+	if stateFile != nil {
+		err := json.NewDecoder(stateFile).Decode(&h.state)
+		_ = stateFile.Close()
+		if err != nil && !errors.Is(err, io.EOF) {
+			h.Close()
+			return nil, fmt.Errorf("failed to read handover state: %w", err)
+		}
+	}
+	return h, nil
+}
+
+// FromFiles returns the given UDP sockets and TCP listeners as inherited
+// ones, for sockets passed other than through the environment. The files
+// are duplicated, so the caller still closes them.
+func FromFiles(udp, tcp []*os.File) (*Inherited, error) {
+	h := &Inherited{}
+	for _, f := range udp {
+		pc, err := net.FilePacketConn(f)
+		if err != nil {
+			h.Close()
+			return nil, fmt.Errorf("UDP socket %s: %w", f.Name(), err)
+		}
+		conn, ok := pc.(*net.UDPConn)
+		if !ok {
+			_ = pc.Close()
+			h.Close()
+			return nil, fmt.Errorf("%s is not a UDP socket", f.Name())
+		}
+		h.udp = append(h.udp, conn)
+	}
+	for _, f := range tcp {
+		l, err := net.FileListener(f)
+		if err != nil {
+			h.Close()
+			return nil, fmt.Errorf("TCP listener %s: %w", f.Name(), err)
+		}
+		h.tcp = append(h.tcp, l)
+	}
+	return h, nil
+}
+
+// ListenUDP returns the inherited UDP socket bound to address, or binds a
+// new one if none was inherited
+func (h *Inherited) ListenUDP(address string) (*net.UDPConn, error) {
+	addr, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve UDP address %s: %w", address, err)
+	}
+
+	if h != nil {
+		h.mu.Lock()
+		for i, conn := range h.udp {
+			if local, ok := conn.LocalAddr().(*net.UDPAddr); ok && sameAddr(local.IP, local.Port, addr.IP, addr.Port) {
+				h.udp = append(h.udp[:i], h.udp[i+1:]...)
+				h.mu.Unlock()
+				return conn, nil
+			}
+		}
+		h.mu.Unlock()
+	}
+
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start UDP server on %s: %w", address, err)
+	}
+	return conn, nil
+}
+
+// Listen returns the inherited TCP listener bound to address, or listens
+// anew if none was inherited
+func (h *Inherited) Listen(address string) (net.Listener, error) {
+	if h != nil {
+		if addr, err := net.ResolveTCPAddr("tcp", address); err == nil {
+			h.mu.Lock()
+			for i, l := range h.tcp {
+				if local, ok := l.Addr().(*net.TCPAddr); ok && sameAddr(local.IP, local.Port, addr.IP, addr.Port) {
+					h.tcp = append(h.tcp[:i], h.tcp[i+1:]...)
+					h.mu.Unlock()
+					return l, nil
+				}
+			}
+			h.mu.Unlock()
+		}
+	}
+	return net.Listen("tcp", address)
+}
+
+// State returns the state handed over by the previous process
+func (h *Inherited) State() State {
+	if h == nil {
+		return State{}
+	}
+	return h.state
+}
+
+// Ready tells the previous process that this one is serving, so it can shut
+// down. Inherited sockets that were not claimed, e.g. after a listen address
+// was removed from the configuration, are closed.
+func (h *Inherited) Ready() error {
+	if h == nil {
+		return nil
+	}
+	h.Close()
+
+	h.mu.Lock()
+	ready := h.ready
+	h.ready = nil
+	h.mu.Unlock()
+	if ready == nil {
+		return nil
+	}
+	defer func() { _ = ready.Close() }()
+	if _, err := ready.Write([]byte{1}); err != nil {
+		return fmt.Errorf("failed to report ready: %w", err)
+	}
+	return nil
+}
+
+// Close closes the inherited sockets that were not claimed
+func (h *Inherited) Close() {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, conn := range h.udp {
+		_ = conn.Close()
+	}
+	for _, l := range h.tcp {
+		_ = l.Close()
+	}
+	h.udp, h.tcp = nil, nil
+}
+
+// sameAddr reports whether two socket addresses are the same: equal ports,
+// and equal IPs or both unspecified
+func sameAddr(ip1 net.IP, port1 int, ip2 net.IP, port2 int) bool {
+	if port1 != port2 {
+		return false
+	}
+	unspecified := func(ip net.IP) bool { return ip == nil || ip.IsUnspecified() }
+	if unspecified(ip1) || unspecified(ip2) {
+		return unspecified(ip1) && unspecified(ip2)
+	}
+	return ip1.Equal(ip2)
+}
@@ -0,0 +1,170 @@
+//go:build !windows
+
+package handover
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+// TestMain runs the test binary as the new process when Upgrade starts it:
+// it takes over the sockets, answers one datagram on the UDP socket with the
+// number of sessions it received, and reports ready.
+func TestMain(m *testing.M) {
+	if _, ok := os.LookupEnv(EnvVar); !ok {
+		os.Exit(m.Run())
+	}
+
+	h, err := FromEnvironment()
+	if err != nil || h == nil || len(h.udp) != 1 {
+		os.Exit(2)
+	}
+	conn, err := h.ListenUDP(h.udp[0].LocalAddr().String())
+	if err != nil {
+		os.Exit(2)
+	}
+	if err := h.Ready(); err != nil {
+		os.Exit(3)
+	}
+	buf := make([]byte, 64)
+	_ = conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	_, from, err := conn.ReadFromUDP(buf)
+	if err != nil {
+		os.Exit(4)
+	}
+	_, _ = conn.WriteToUDP([]byte{byte(len(h.State().Sessions))}, from)
+	os.Exit(0)
+}
+
+func TestUpgradeHandsOverSocket(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := conn.File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := conn.LocalAddr().(*net.UDPAddr)
+	// The new process owns the socket from here on
+	_ = conn.Close()
+
+	state := State{Sessions: map[string]time.Time{"a": time.Now().Add(time.Hour), "b": time.Now().Add(time.Hour)}}
+	pid, err := Upgrade(context.Background(), []*os.File{f}, nil, state, 10*time.Second)
+	_ = f.Close()
+	if err != nil {
+		t.Fatalf("Upgrade: %v", err)
+	}
+	if pid == 0 {
+		t.Error("expected the new process ID")
+	}
+
+	client, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if _, err := client.Write([]byte("YSFP")); err != nil {
+		t.Fatal(err)
+	}
+	_ = client.SetReadDeadline(time.Now().Add(5 * time.Second))
+	reply := make([]byte, 8)
+	n, err := client.Read(reply)
+	if err != nil {
+		t.Fatalf("no reply from the new process on the handed over socket: %v", err)
+	}
+	if n != 1 || reply[0] != 2 {
+		t.Errorf("new process received %v sessions, want 2", reply[:n])
+	}
+}
+
+func TestInheritedListenUDP(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &Inherited{udp: []*net.UDPConn{conn}}
+
+	got, err := h.ListenUDP(conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != conn {
+		t.Fatal("expected the inherited socket")
+	}
+	defer got.Close()
+
+	// Claimed once only; another address binds anew
+	other, err := h.ListenUDP("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	if other == conn {
+		t.Error("inherited socket handed out twice")
+	}
+}
+
+func TestInheritedReadyClosesUnclaimed(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, write, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer read.Close()
+	h := &Inherited{tcp: []net.Listener{l}, ready: write}
+
+	if err := h.Ready(); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 1)
+	if n, err := read.Read(buf); err != nil || n != 1 {
+		t.Fatalf("ready not reported: %d, %v", n, err)
+	}
+	if _, err := l.Accept(); err == nil {
+		t.Error("unclaimed listener still open")
+	}
+}
+
+func TestNilInheritedBinds(t *testing.T) {
+	var h *Inherited
+	conn, err := h.ListenUDP("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	l, err := h.Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if err := h.Ready(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSameAddr(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"0.0.0.0:42000", ":42000", true},
+		{"[::]:42000", "0.0.0.0:42000", true},
+		{"127.0.0.1:42000", "127.0.0.1:42000", true},
+		{"127.0.0.1:42000", "127.0.0.1:42001", false},
+		{"127.0.0.1:42000", "0.0.0.0:42000", false},
+	}
+	for _, tt := range tests {
+		a, _ := net.ResolveUDPAddr("udp", tt.a)
+		b, _ := net.ResolveUDPAddr("udp", tt.b)
+		if got := sameAddr(a.IP, a.Port, b.IP, b.Port); got != tt.want {
+			t.Errorf("sameAddr(%s, %s) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
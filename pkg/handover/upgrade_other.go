@@ -0,0 +1,113 @@
+//go:build !windows
+
+package handover
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Upgrade starts the current executable again with the same arguments,
+// handing it the sockets and state, and waits up to timeout for it to report
+// ready. It returns the new process ID; the caller should then shut down
+// without unlinking the peers of the shared sockets. On failure the new
+// process is killed and the caller keeps serving. The files are not closed.
+func Upgrade(ctx context.Context, udp, tcp []*os.File, state State, timeout time.Duration) (int, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return 0, fmt.Errorf("failed to find executable: %w", err)
+	}
+
+	stateRead, stateWrite, err := os.Pipe()
+	if err != nil {
+		return 0, fmt.Errorf("failed to create state pipe: %w", err)
+	}
+	defer func() { _ = stateRead.Close() }()
+	readyRead, readyWrite, err := os.Pipe()
+	if err != nil {
+		_ = stateWrite.Close()
+		return 0, fmt.Errorf("failed to create ready pipe: %w", err)
+	}
+	defer func() { _ = readyRead.Close() }()
+
+	kinds := make([]string, 0, len(udp)+len(tcp)+2)
+	files := make([]*os.File, 0, cap(kinds))
+	for _, f := range udp {
+		kinds = append(kinds, kindUDP)
+		files = append(files, f)
+	}
+	for _, f := range tcp {
+		kinds = append(kinds, kindTCP)
+		files = append(files, f)
+	}
+	kinds = append(kinds, kindState, kindReady)
+	files = append(files, stateRead, readyWrite)
+
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = files
+	cmd.Env = append(environWithout(EnvVar), EnvVar+"="+strings.Join(kinds, ","))
+
+	err = cmd.Start()
+	_ = readyWrite.Close() // The child holds the write end now
+	if err != nil {
+		_ = stateWrite.Close()
+		return 0, fmt.Errorf("failed to start %s: %w", exe, err)
+	}
+
+	// The state can exceed the pipe buffer, so write it while waiting
+	go func() {
+		_ = json.NewEncoder(stateWrite).Encode(state)
+		_ = stateWrite.Close()
+	}()
+
+	ready := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 1)
+		if _, err := readyRead.Read(buf); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = errors.New("new process exited before it was ready")
+			}
+			ready <- err
+			return
+		}
+		ready <- nil
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err = <-ready:
+	case <-timer.C:
+		err = fmt.Errorf("new process not ready after %s", timeout)
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return 0, err
+	}
+	return cmd.Process.Pid, nil
+}
+
+// environWithout returns the environment without the named variable
+func environWithout(name string) []string {
+	env := os.Environ()
+	kept := env[:0]
+	for _, kv := range env {
+		if !strings.HasPrefix(kv, name+"=") {
+			kept = append(kept, kv)
+		}
+	}
+	return kept
+}
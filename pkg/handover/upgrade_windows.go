@@ -0,0 +1,14 @@
+package handover
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+)
+
+// Upgrade is not supported on Windows, which cannot pass sockets to a child
+// process as inherited descriptors
+func Upgrade(ctx context.Context, udp, tcp []*os.File, state State, timeout time.Duration) (int, error) {
+	return 0, errors.New("binary upgrade is only available on Unix")
+}
@@ -0,0 +1,307 @@
+// Package health samples the process runtime — goroutines, heap and the
+// backlog of internal queues — to catch goroutine leaks and stalled consumers
+// before they take the reflector down.
+package health
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/clock"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// Anomaly kinds
+const (
+	// AnomalyGoroutines: more goroutines than MaxGoroutines
+	AnomalyGoroutines = "goroutines"
+	// AnomalyGoroutinesRising: the goroutine count grew on every one of the
+	// last risingSamples samples, which usually means a leak
+	AnomalyGoroutinesRising = "goroutines_rising"
+	// AnomalyHeap: more heap in use than MaxHeapMB
+	AnomalyHeap = "heap"
+	// AnomalyQueueBacklog: a queue is filled beyond QueueThreshold of its capacity
+	AnomalyQueueBacklog = "queue_backlog"
+)
+
+// risingSamples is how many consecutive increases make a goroutine leak
+const risingSamples = 10
+
+// Options configures a Monitor
+type Options struct {
+	Interval       time.Duration // Time between samples
+	MaxGoroutines  int           // 0 = no limit
+	MaxHeapMB      int           // 0 = no limit
+	QueueThreshold float64       // Fraction of a queue's capacity that is a backlog (0 = off)
+	DumpDir        string        // Write a heap profile here on an anomaly ("" = never)
+	DumpCooldown   time.Duration // Minimum time between heap profiles
+}
+
+// QueueFunc returns the current length and the capacity of a queue
+type QueueFunc func() (length, capacity int)
+
+// QueueStatus is the backlog of one queue
+type QueueStatus struct {
+	Name     string `json:"name"`
+	Length   int    `json:"length"`
+	Capacity int    `json:"capacity"`
+}
+
+// Anomaly is a threshold exceeded on the last check
+type Anomaly struct {
+	Kind    string    `json:"kind"`
+	Subject string    `json:"subject,omitempty"` // Queue name for queue backlogs
+	Message string    `json:"message"`
+	Since   time.Time `json:"since"`
+}
+
+// Snapshot is one runtime sample
+type Snapshot struct {
+	Time         time.Time     `json:"time"`
+	Goroutines   int           `json:"goroutines"`
+	HeapAllocMB  float64       `json:"heap_alloc_mb"`
+	HeapInuseMB  float64       `json:"heap_inuse_mb"`
+	SysMB        float64       `json:"sys_mb"`
+	NumGC        uint32        `json:"num_gc"`
+	Queues       []QueueStatus `json:"queues"`
+	Anomalies    []Anomaly     `json:"anomalies"`
+	HeapDumps    uint64        `json:"heap_dumps"`
+	LastHeapDump string        `json:"last_heap_dump,omitempty"`
+}
+
+// namedQueue is a registered queue
+type namedQueue struct {
+	name string
+	fn   QueueFunc
+}
+
+// Monitor samples the runtime on an interval and reports anomalies
+type Monitor struct {
+	opts   Options
+	logger *logger.Logger
+	clock  clock.Clock
+
+	mu         sync.Mutex
+	queues     []namedQueue
+	anomalies  map[string]Anomaly // key: kind + subject
+	previous   int                // goroutines on the previous check
+	rising     int                // consecutive checks with more goroutines
+	dumps      uint64
+	lastDump   time.Time
+	lastDumped string
+}
+
+// New creates a monitor
+func New(opts Options, log *logger.Logger) *Monitor {
+	if opts.Interval <= 0 {
+		opts.Interval = 30 * time.Second
+	}
+	return &Monitor{
+		opts:      opts,
+		logger:    log.WithComponent("health"),
+		clock:     clock.Real{},
+		anomalies: make(map[string]Anomaly),
+	}
+}
+
+// SetClock replaces the time source for samples and the dump cooldown
+func (m *Monitor) SetClock(c clock.Clock) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clock = c
+}
+
+// AddQueue registers a queue whose backlog is sampled
+func (m *Monitor) AddQueue(name string, fn QueueFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queues = append(m.queues, namedQueue{name: name, fn: fn})
+}
+
+// Run checks the runtime every interval until ctx is cancelled
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Check()
+		}
+	}
+}
+
+// Status returns a fresh sample with the anomalies of the last check
+func (m *Monitor) Status() Snapshot {
+	snap := m.sample()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.describe(&snap)
+	return snap
+}
+
+// Check takes a sample, logs anomalies that appeared or cleared since the
+// last check and writes a heap profile if one is due
+func (m *Monitor) Check() Snapshot {
+	snap := m.sample()
+	found := m.evaluate(snap)
+
+	m.mu.Lock()
+	for key, a := range found {
+		if old, ok := m.anomalies[key]; ok {
+			a.Since = old.Since
+			found[key] = a
+			continue
+		}
+		m.logger.Warn("Runtime anomaly",
+			logger.String("kind", a.Kind),
+			logger.String("subject", a.Subject),
+			logger.String("message", a.Message))
+	}
+	for key, a := range m.anomalies {
+		if _, ok := found[key]; !ok {
+			m.logger.Info("Runtime anomaly cleared",
+				logger.String("kind", a.Kind),
+				logger.String("subject", a.Subject))
+		}
+	}
+	m.anomalies = found
+
+	dump := len(found) > 0 && m.opts.DumpDir != "" &&
+		(m.lastDump.IsZero() || snap.Time.Sub(m.lastDump) >= m.opts.DumpCooldown)
+	if dump {
+		m.lastDump = snap.Time
+	}
+	m.mu.Unlock()
+
+	if dump {
+		path, err := writeHeapProfile(m.opts.DumpDir, snap.Time)
+		if err != nil {
+			m.logger.Error("Failed to write heap profile", logger.Error(err))
+		} else {
+			m.logger.Warn("Heap profile written", logger.String("path", path))
+			m.mu.Lock()
+			m.dumps++
+			m.lastDumped = path
+			m.mu.Unlock()
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.describe(&snap)
+	return snap
+}
+
+// sample reads the runtime and queue state
+func (m *Monitor) sample() Snapshot {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	m.mu.Lock()
+	now := m.clock.Now()
+	queues := append([]namedQueue(nil), m.queues...)
+	m.mu.Unlock()
+
+	snap := Snapshot{
+		Time:        now,
+		Goroutines:  runtime.NumGoroutine(),
+		HeapAllocMB: megabytes(mem.HeapAlloc),
+		HeapInuseMB: megabytes(mem.HeapInuse),
+		SysMB:       megabytes(mem.Sys),
+		NumGC:       mem.NumGC,
+		Queues:      make([]QueueStatus, 0, len(queues)),
+	}
+	for _, q := range queues {
+		length, capacity := q.fn()
+		snap.Queues = append(snap.Queues, QueueStatus{Name: q.name, Length: length, Capacity: capacity})
+	}
+	return snap
+}
+
+// evaluate returns the anomalies in a sample and tracks goroutine growth
+func (m *Monitor) evaluate(snap Snapshot) map[string]Anomaly {
+	found := make(map[string]Anomaly)
+	add := func(kind, subject, message string) {
+		found[kind+"/"+subject] = Anomaly{Kind: kind, Subject: subject, Message: message, Since: snap.Time}
+	}
+
+	if max := m.opts.MaxGoroutines; max > 0 && snap.Goroutines > max {
+		add(AnomalyGoroutines, "", fmt.Sprintf("%d goroutines exceed the limit of %d", snap.Goroutines, max))
+	}
+
+	m.mu.Lock()
+	if m.previous > 0 && snap.Goroutines > m.previous {
+		m.rising++
+	} else {
+		m.rising = 0
+	}
+	m.previous = snap.Goroutines
+	rising := m.rising
+	m.mu.Unlock()
+	if rising >= risingSamples {
+		add(AnomalyGoroutinesRising, "", fmt.Sprintf("goroutines grew on %d consecutive samples to %d", rising, snap.Goroutines))
+	}
+
+	if max := m.opts.MaxHeapMB; max > 0 && snap.HeapInuseMB > float64(max) {
+		add(AnomalyHeap, "", fmt.Sprintf("%.1f MB heap in use exceeds the limit of %d MB", snap.HeapInuseMB, max))
+	}
+
+	if threshold := m.opts.QueueThreshold; threshold > 0 {
+		for _, q := range snap.Queues {
+			if q.Capacity > 0 && float64(q.Length) >= threshold*float64(q.Capacity) {
+				add(AnomalyQueueBacklog, q.Name, fmt.Sprintf("%s holds %d of %d", q.Name, q.Length, q.Capacity))
+			}
+		}
+	}
+	return found
+}
+
+// describe adds the current anomalies and dump counters to a snapshot.
+// Callers hold m.mu.
+func (m *Monitor) describe(snap *Snapshot) {
+	snap.Anomalies = make([]Anomaly, 0, len(m.anomalies))
+	for _, a := range m.anomalies {
+		snap.Anomalies = append(snap.Anomalies, a)
+	}
+	sort.Slice(snap.Anomalies, func(i, j int) bool {
+		if snap.Anomalies[i].Kind != snap.Anomalies[j].Kind {
+			return snap.Anomalies[i].Kind < snap.Anomalies[j].Kind
+		}
+		return snap.Anomalies[i].Subject < snap.Anomalies[j].Subject
+	})
+	snap.HeapDumps = m.dumps
+	snap.LastHeapDump = m.lastDumped
+}
+
+// writeHeapProfile writes a pprof heap profile into dir
+func writeHeapProfile(dir string, now time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "heap-"+now.UTC().Format("20060102-150405")+".pprof")
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		_ = f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
+
+// megabytes converts bytes to MB
+func megabytes(b uint64) float64 {
+	return float64(b) / (1024 * 1024)
+}
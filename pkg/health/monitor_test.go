@@ -0,0 +1,87 @@
+package health
+
+import (
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/clock"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+func TestQueueBacklogAnomaly(t *testing.T) {
+	m := New(Options{QueueThreshold: 0.8}, logger.NewTestLogger(io.Discard))
+	length := 10
+	m.AddQueue("events", func() (int, int) { return length, 100 })
+
+	if snap := m.Check(); len(snap.Anomalies) != 0 {
+		t.Fatalf("expected no anomalies, got %+v", snap.Anomalies)
+	}
+
+	length = 85
+	snap := m.Check()
+	if len(snap.Anomalies) != 1 || snap.Anomalies[0].Kind != AnomalyQueueBacklog || snap.Anomalies[0].Subject != "events" {
+		t.Fatalf("expected an events backlog, got %+v", snap.Anomalies)
+	}
+	if len(snap.Queues) != 1 || snap.Queues[0].Length != 85 {
+		t.Errorf("unexpected queues %+v", snap.Queues)
+	}
+
+	// Status reports the anomalies of the last check
+	if status := m.Status(); len(status.Anomalies) != 1 {
+		t.Errorf("expected the anomaly in the status, got %+v", status.Anomalies)
+	}
+
+	length = 0
+	if snap := m.Check(); len(snap.Anomalies) != 0 {
+		t.Errorf("expected the backlog to clear, got %+v", snap.Anomalies)
+	}
+}
+
+func TestGoroutineLimitAndRise(t *testing.T) {
+	m := New(Options{MaxGoroutines: 1}, logger.NewTestLogger(io.Discard))
+	snap := m.Check()
+	if len(snap.Anomalies) != 1 || snap.Anomalies[0].Kind != AnomalyGoroutines {
+		t.Fatalf("expected a goroutine limit anomaly, got %+v", snap.Anomalies)
+	}
+
+	m = New(Options{}, logger.NewTestLogger(io.Discard))
+	stop := make(chan struct{})
+	defer close(stop)
+	for i := 0; i <= risingSamples; i++ {
+		go func() { <-stop }()
+		snap = m.Check()
+	}
+	found := false
+	for _, a := range snap.Anomalies {
+		found = found || a.Kind == AnomalyGoroutinesRising
+	}
+	if !found {
+		t.Errorf("expected a rising goroutine anomaly, got %+v", snap.Anomalies)
+	}
+}
+
+func TestHeapDumpCooldown(t *testing.T) {
+	dir := t.TempDir()
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	m := New(Options{MaxGoroutines: 1, DumpDir: dir, DumpCooldown: time.Hour}, logger.NewTestLogger(io.Discard))
+	m.SetClock(clk)
+
+	snap := m.Check()
+	if snap.HeapDumps != 1 || snap.LastHeapDump == "" {
+		t.Fatalf("expected a heap profile, got %+v", snap)
+	}
+	if _, err := os.Stat(snap.LastHeapDump); err != nil {
+		t.Fatalf("heap profile missing: %v", err)
+	}
+
+	clk.Advance(time.Minute)
+	if snap := m.Check(); snap.HeapDumps != 1 {
+		t.Errorf("expected no profile within the cooldown, got %d", snap.HeapDumps)
+	}
+	clk.Advance(time.Hour)
+	if snap := m.Check(); snap.HeapDumps != 2 {
+		t.Errorf("expected a second profile after the cooldown, got %d", snap.HeapDumps)
+	}
+}
@@ -0,0 +1,220 @@
+// Package links keeps the registry of external links: systems connected to
+// the reflector outside its bridges, such as an AllStar node or an EchoLink
+// conference. Each link's status URL is polled on its own interval and the
+// result is reported next to the bridges, so the dashboard shows the whole
+// interconnect.
+package links
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// Poll defaults for links that do not set them
+const (
+	DefaultInterval = time.Minute
+	DefaultTimeout  = 10 * time.Second
+)
+
+// maxBodySize limits how much of a status response is searched for the match
+const maxBodySize = 64 * 1024
+
+// Link states
+const (
+	StateUnknown = "unknown" // Not polled yet
+	StateUp      = "up"
+	StateDown    = "down"
+)
+
+// Status is the last known state of a link
+type Status struct {
+	Name       string    `json:"name"`
+	Type       string    `json:"type"`
+	Node       string    `json:"node,omitempty"`
+	State      string    `json:"state"`
+	LastCheck  time.Time `json:"last_check,omitempty"`
+	LastChange time.Time `json:"last_change,omitempty"` // When the state last changed
+	LatencyMS  int64     `json:"latency_ms,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// link is a registered link and its state
+type link struct {
+	config config.ExternalLinkConfig
+	status Status
+}
+
+// Registry polls the status of the external links
+type Registry struct {
+	client *http.Client
+	logger *logger.Logger
+
+	mu    sync.RWMutex
+	links []*link
+}
+
+// New creates a registry for the configured links
+func New(configs []config.ExternalLinkConfig, log *logger.Logger) *Registry {
+	r := &Registry{
+		client: &http.Client{},
+		logger: log.WithComponent("links"),
+	}
+	for _, c := range configs {
+		if c.Type == "" {
+			c.Type = "other"
+		}
+		if c.Interval <= 0 {
+			c.Interval = DefaultInterval
+		}
+		if c.Timeout <= 0 {
+			c.Timeout = DefaultTimeout
+		}
+		r.links = append(r.links, &link{
+			config: c,
+			status: Status{Name: c.Name, Type: c.Type, Node: c.Node, State: StateUnknown},
+		})
+	}
+	return r
+}
+
+// Run polls every link on its interval until ctx is cancelled
+func (r *Registry) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, l := range r.links {
+		wg.Add(1)
+		go func(l *link) {
+			defer wg.Done()
+			r.poll(ctx, l)
+		}(l)
+	}
+	wg.Wait()
+}
+
+// poll checks one link at once and then on its interval
+func (r *Registry) poll(ctx context.Context, l *link) {
+	ticker := time.NewTicker(l.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		r.Check(ctx, l.config.Name)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check polls a link now and returns its new status; it returns false for
+// an unknown link
+func (r *Registry) Check(ctx context.Context, name string) (Status, bool) {
+	l := r.find(name)
+	if l == nil {
+		return Status{}, false
+	}
+
+	start := time.Now()
+	err := r.probe(ctx, l.config)
+	if ctx.Err() != nil {
+		// Shutting down; a cancelled request says nothing about the link
+		return r.status(l), true
+	}
+	now := time.Now()
+
+	r.mu.Lock()
+	previous := l.status.State
+	l.status.LastCheck = now
+	l.status.LatencyMS = now.Sub(start).Milliseconds()
+	l.status.State = StateUp
+	l.status.Error = ""
+	if err != nil {
+		l.status.State = StateDown
+		l.status.Error = err.Error()
+	}
+	if l.status.State != previous {
+		l.status.LastChange = now
+	}
+	status := l.status
+	r.mu.Unlock()
+
+	if status.State != previous {
+		if err != nil {
+			r.logger.Warn("External link down",
+				logger.String("link", status.Name),
+				logger.String("type", status.Type),
+				logger.Error(err))
+		} else {
+			r.logger.Info("External link up",
+				logger.String("link", status.Name),
+				logger.String("type", status.Type))
+		}
+	}
+	return status, true
+}
+
+// probe fetches a link's status URL and reports why the link is down, or nil
+func (r *Registry) probe(ctx context.Context, c config.ExternalLinkConfig) error {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.StatusURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("status URL returned %s", resp.Status)
+	}
+	if c.Match == "" {
+		return nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(string(body), c.Match) {
+		return fmt.Errorf("status response does not contain %q", c.Match)
+	}
+	return nil
+}
+
+// Statuses returns the status of every link in configured order
+func (r *Registry) Statuses() []Status {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	statuses := make([]Status, 0, len(r.links))
+	for _, l := range r.links {
+		statuses = append(statuses, l.status)
+	}
+	return statuses
+}
+
+// find returns the link with the given name
+func (r *Registry) find(name string) *link {
+	for _, l := range r.links {
+		if strings.EqualFold(l.config.Name, name) {
+			return l
+		}
+	}
+	return nil
+}
+
+// status returns a link's current status
+func (r *Registry) status(l *link) Status {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return l.status
+}
@@ -0,0 +1,58 @@
+package links
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+func TestRegistryTracksLinkState(t *testing.T) {
+	var keyed atomic.Bool
+	keyed.Store(true)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/allstar":
+			if keyed.Load() {
+				_, _ = w.Write([]byte(`{"node":"2560","status":"online"}`))
+			} else {
+				_, _ = w.Write([]byte(`{"node":"2560","status":"offline"}`))
+			}
+		default:
+			http.Error(w, "gone", http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	r := New([]config.ExternalLinkConfig{
+		{Name: "AllStar 2560", Type: "allstar", Node: "2560", StatusURL: srv.URL + "/allstar", Match: `"online"`},
+		{Name: "Conference", Type: "echolink", StatusURL: srv.URL + "/echolink"},
+	}, logger.NewTestLogger(os.Stdout))
+
+	statuses := r.Statuses()
+	if len(statuses) != 2 || statuses[0].State != StateUnknown {
+		t.Fatalf("expected unpolled links, got %+v", statuses)
+	}
+
+	ctx := context.Background()
+	if s, _ := r.Check(ctx, "AllStar 2560"); s.State != StateUp || s.LastChange.IsZero() {
+		t.Errorf("expected allstar up, got %+v", s)
+	}
+	if s, _ := r.Check(ctx, "conference"); s.State != StateDown || s.Error == "" {
+		t.Errorf("expected echolink down, got %+v", s)
+	}
+
+	// A 2xx response without the match counts as down
+	keyed.Store(false)
+	if s, _ := r.Check(ctx, "AllStar 2560"); s.State != StateDown {
+		t.Errorf("expected allstar down without match, got %+v", s)
+	}
+	if _, ok := r.Check(ctx, "missing"); ok {
+		t.Errorf("expected unknown link to be reported")
+	}
+}
@@ -0,0 +1,62 @@
+package logger
+
+import "go.uber.org/zap/zapcore"
+
+// eventID is the event ID used for all entries; the source is registered
+// with EventCreate.exe, which accepts IDs 1 to 1000
+const eventID = 1
+
+// eventWriter is an event log source, an *eventlog.Log on Windows
+type eventWriter interface {
+	Info(eid uint32, msg string) error
+	Warning(eid uint32, msg string) error
+	Error(eid uint32, msg string) error
+}
+
+// eventLogCore writes log entries to the Windows event log
+type eventLogCore struct {
+	zapcore.LevelEnabler
+	enc zapcore.Encoder
+	log eventWriter
+}
+
+// With implements zapcore.Core
+func (c *eventLogCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	return &eventLogCore{LevelEnabler: c.LevelEnabler, enc: enc, log: c.log}
+}
+
+// Check implements zapcore.Core
+func (c *eventLogCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write implements zapcore.Core, mapping zap levels to event types
+func (c *eventLogCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	msg := buf.String()
+	buf.Free()
+
+	switch {
+	case ent.Level >= zapcore.ErrorLevel:
+		return c.log.Error(eventID, msg)
+	case ent.Level == zapcore.WarnLevel:
+		return c.log.Warning(eventID, msg)
+	default:
+		return c.log.Info(eventID, msg)
+	}
+}
+
+// Sync implements zapcore.Core; event log writes are not buffered
+func (c *eventLogCore) Sync() error {
+	return nil
+}
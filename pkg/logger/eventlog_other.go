@@ -0,0 +1,14 @@
+//go:build !windows
+
+package logger
+
+import (
+	"errors"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// newEventLogCore is only supported on Windows
+func newEventLogCore(source string, enc zapcore.Encoder, level zapcore.LevelEnabler) (zapcore.Core, error) {
+	return nil, errors.New("the event log is only available on Windows")
+}
@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// fakeEventLog records event log entries by type
+type fakeEventLog struct {
+	entries []string
+}
+
+func (l *fakeEventLog) Info(eid uint32, msg string) error    { return l.add("info", msg) }
+func (l *fakeEventLog) Warning(eid uint32, msg string) error { return l.add("warning", msg) }
+func (l *fakeEventLog) Error(eid uint32, msg string) error   { return l.add("error", msg) }
+
+func (l *fakeEventLog) add(kind, msg string) error {
+	l.entries = append(l.entries, kind+": "+strings.TrimSpace(msg))
+	return nil
+}
+
+func TestEventLogCore(t *testing.T) {
+	events := &fakeEventLog{}
+	enc := zapcore.NewConsoleEncoder(zapcore.EncoderConfig{MessageKey: "msg"})
+	log := zap.New(&eventLogCore{LevelEnabler: zapcore.InfoLevel, enc: enc, log: events})
+
+	log.Debug("not logged")
+	log.Info("started")
+	log.With(zap.String("bridge", "net")).Warn("link lost")
+	log.Error("failed")
+
+	want := []string{"info: started", `warning: link lost	{"bridge": "net"}`, "error: failed"}
+	if strings.Join(events.entries, "\n") != strings.Join(want, "\n") {
+		t.Errorf("event log entries %q, want %q", events.entries, want)
+	}
+}
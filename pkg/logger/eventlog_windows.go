@@ -0,0 +1,18 @@
+//go:build windows
+
+package logger
+
+import (
+	"golang.org/x/sys/windows/svc/eventlog"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// newEventLogCore opens the event log source and returns a core writing to it
+func newEventLogCore(source string, enc zapcore.Encoder, level zapcore.LevelEnabler) (zapcore.Core, error) {
+	log, err := eventlog.Open(source)
+	if err != nil {
+		return nil, err
+	}
+	return &eventLogCore{LevelEnabler: level, enc: enc, log: log}, nil
+}
@@ -15,7 +15,8 @@ import (
 // Logger wraps zap.Logger with additional functionality
 type Logger struct {
 	*zap.Logger
-	config Config
+	config  Config
+	rotator *lumberjack.Logger
 }
 
 // Config holds logger configuration
@@ -27,6 +28,9 @@ type Config struct {
 	MaxBackups  int
 	MaxAge      int
 	Development bool
+	// EventLog is a Windows event log source that info and higher entries are
+	// also written to, e.g. when running as a Windows service
+	EventLog string
 }
 
 // New creates a new logger with the given configuration
@@ -48,10 +52,20 @@ func New(config Config) (*Logger, error) {
 	}
 
 	// Create writer
-	writer := getWriter(config)
+	writer, rotator := getWriter(config)
 
 	// Create core
 	core := zapcore.NewCore(encoder, writer, level)
+	if config.EventLog != "" {
+		eventLevel := zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+			return l >= level && l >= zapcore.InfoLevel
+		})
+		eventCore, err := newEventLogCore(config.EventLog, encoder.Clone(), eventLevel)
+		if err != nil {
+			return nil, fmt.Errorf("open event log: %w", err)
+		}
+		core = zapcore.NewTee(core, eventCore)
+	}
 
 	// Create logger
 	var logger *zap.Logger
@@ -62,8 +76,9 @@ func New(config Config) (*Logger, error) {
 	}
 
 	return &Logger{
-		Logger: logger,
-		config: config,
+		Logger:  logger,
+		config:  config,
+		rotator: rotator,
 	}, nil
 }
 
@@ -80,18 +95,19 @@ func getEncoderConfig(development bool) zapcore.EncoderConfig {
 	return config
 }
 
-// getWriter creates the appropriate writer based on configuration
-func getWriter(config Config) zapcore.WriteSyncer {
+// getWriter creates the appropriate writer based on configuration.
+// The returned rotator is nil when logging to the console only.
+func getWriter(config Config) (zapcore.WriteSyncer, *lumberjack.Logger) {
 	if config.File == "" {
 		// Console only
-		return zapcore.AddSync(os.Stdout)
+		return zapcore.AddSync(os.Stdout), nil
 	}
 
 	// Ensure directory exists
 	dir := filepath.Dir(config.File)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		// Fallback to console if directory creation fails
-		return zapcore.AddSync(os.Stdout)
+		return zapcore.AddSync(os.Stdout), nil
 	}
 
 	// File with rotation
@@ -104,7 +120,7 @@ func getWriter(config Config) zapcore.WriteSyncer {
 	}
 
 	// Write to both console and file
-	return zapcore.AddSync(io.MultiWriter(os.Stdout, fileWriter))
+	return zapcore.AddSync(io.MultiWriter(os.Stdout, fileWriter)), fileWriter
 }
 
 // Sync flushes any buffered log entries
@@ -112,6 +128,20 @@ func (l *Logger) Sync() {
 	_ = l.Logger.Sync()
 }
 
+// Rotate closes the current log file and starts a new one.
+// It is a no-op when logging to the console only.
+func (l *Logger) Rotate() error {
+	if l.rotator == nil {
+		return nil
+	}
+	return l.rotator.Rotate()
+}
+
+// HasFile reports whether the logger writes to a rotatable log file
+func (l *Logger) HasFile() bool {
+	return l.rotator != nil
+}
+
 // WithFields returns a logger with additional fields
 func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
 	var zapFields []zap.Field
@@ -120,24 +150,27 @@ func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
 	}
 
 	return &Logger{
-		Logger: l.With(zapFields...),
-		config: l.config,
+		Logger:  l.With(zapFields...),
+		config:  l.config,
+		rotator: l.rotator,
 	}
 }
 
 // WithComponent returns a logger with a component field
 func (l *Logger) WithComponent(component string) *Logger {
 	return &Logger{
-		Logger: l.With(zap.String("component", component)),
-		config: l.config,
+		Logger:  l.With(zap.String("component", component)),
+		config:  l.config,
+		rotator: l.rotator,
 	}
 }
 
 // WithError returns a logger with an error field
 func (l *Logger) WithError(err error) *Logger {
 	return &Logger{
-		Logger: l.With(zap.Error(err)),
-		config: l.config,
+		Logger:  l.With(zap.Error(err)),
+		config:  l.config,
+		rotator: l.rotator,
 	}
 }
 
@@ -0,0 +1,190 @@
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+	"github.com/robfig/cron/v3"
+)
+
+// TaskFunc performs a maintenance task and returns a short human-readable summary
+type TaskFunc func(ctx context.Context) (string, error)
+
+// Task is a named maintenance task
+type Task struct {
+	Name string
+	Run  TaskFunc
+}
+
+// TaskResult holds the outcome of a single task run
+type TaskResult struct {
+	Name     string        `json:"name"`
+	Summary  string        `json:"summary,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Report holds the outcome of a maintenance run
+type Report struct {
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt time.Time     `json:"finished_at"`
+	Results    []TaskResult  `json:"results"`
+	Failed     int           `json:"failed"`
+	Duration   time.Duration `json:"duration"`
+}
+
+// Scheduler runs registered maintenance tasks on a cron schedule
+type Scheduler struct {
+	schedule string
+	timeout  time.Duration
+	logger   *logger.Logger
+	events   chan<- repeater.Event
+	cron     *cron.Cron
+
+	mu         sync.Mutex
+	tasks      []Task
+	lastReport *Report
+	running    bool
+}
+
+// NewScheduler creates a maintenance scheduler. schedule uses the same
+// six-field (with seconds) cron format as bridge schedules.
+func NewScheduler(schedule string, timeout time.Duration, events chan<- repeater.Event, log *logger.Logger) *Scheduler {
+	return &Scheduler{
+		schedule: schedule,
+		timeout:  timeout,
+		logger:   log.WithComponent("maintenance"),
+		events:   events,
+		cron:     cron.New(cron.WithSeconds()),
+	}
+}
+
+// Register adds a task to the maintenance run. Tasks run in registration order.
+func (s *Scheduler) Register(name string, run TaskFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tasks = append(s.tasks, Task{Name: name, Run: run})
+}
+
+// Start schedules maintenance runs until ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context) error {
+	if _, err := s.cron.AddFunc(s.schedule, func() { s.RunNow(ctx) }); err != nil {
+		return fmt.Errorf("invalid maintenance schedule %q: %w", s.schedule, err)
+	}
+
+	s.cron.Start()
+	s.logger.Info("Maintenance scheduler started",
+		logger.String("schedule", s.schedule),
+		logger.Int("tasks", len(s.tasks)))
+
+	<-ctx.Done()
+	<-s.cron.Stop().Done()
+	return nil
+}
+
+// RunNow runs all registered tasks immediately and emits a maintenance report event.
+// Concurrent runs are skipped.
+func (s *Scheduler) RunNow(ctx context.Context) *Report {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		s.logger.Warn("Maintenance run already in progress, skipping")
+		return nil
+	}
+	s.running = true
+	tasks := make([]Task, len(s.tasks))
+	copy(tasks, s.tasks)
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+	}()
+
+	report := &Report{StartedAt: time.Now()}
+	for _, task := range tasks {
+		result := s.runTask(ctx, task)
+		if result.Error != "" {
+			report.Failed++
+		}
+		report.Results = append(report.Results, result)
+	}
+	report.FinishedAt = time.Now()
+	report.Duration = report.FinishedAt.Sub(report.StartedAt)
+
+	s.mu.Lock()
+	s.lastReport = report
+	s.mu.Unlock()
+
+	s.logger.Info("Maintenance run completed",
+		logger.Int("tasks", len(report.Results)),
+		logger.Int("failed", report.Failed),
+		logger.Duration("duration", report.Duration))
+
+	s.emitReport(report)
+	return report
+}
+
+// LastReport returns the most recent maintenance report, if any
+func (s *Scheduler) LastReport() *Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastReport
+}
+
+// runTask runs a single task with the configured timeout
+func (s *Scheduler) runTask(ctx context.Context, task Task) TaskResult {
+	taskCtx := ctx
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		taskCtx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	summary, err := task.Run(taskCtx)
+	result := TaskResult{
+		Name:     task.Name,
+		Summary:  summary,
+		Duration: time.Since(start),
+	}
+	if err != nil {
+		result.Error = err.Error()
+		s.logger.Error("Maintenance task failed",
+			logger.String("task", task.Name),
+			logger.Error(err))
+	} else {
+		s.logger.Debug("Maintenance task completed",
+			logger.String("task", task.Name),
+			logger.String("summary", summary))
+	}
+	return result
+}
+
+// emitReport sends the report on the event channel without blocking
+func (s *Scheduler) emitReport(report *Report) {
+	if s.events == nil {
+		return
+	}
+
+	event := repeater.Event{
+		Type:      repeater.EventMaintenance,
+		Timestamp: report.FinishedAt,
+		Duration:  report.Duration,
+		Data: map[string]interface{}{
+			"results": report.Results,
+			"failed":  report.Failed,
+		},
+	}
+
+	select {
+	case s.events <- event:
+	default:
+		s.logger.Warn("Event channel full, dropping maintenance report")
+	}
+}
@@ -0,0 +1,73 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+func TestRunNowProducesReport(t *testing.T) {
+	events := make(chan repeater.Event, 1)
+	s := NewScheduler("0 0 3 * * *", time.Second, events, logger.NewTestLogger(os.Stdout))
+
+	var order []string
+	s.Register("first", func(ctx context.Context) (string, error) {
+		order = append(order, "first")
+		return "ok", nil
+	})
+	s.Register("second", func(ctx context.Context) (string, error) {
+		order = append(order, "second")
+		return "", errors.New("boom")
+	})
+
+	report := s.RunNow(context.Background())
+	if report == nil {
+		t.Fatalf("expected a report")
+	}
+	if len(order) != 2 || order[0] != "first" || order[1] != "second" {
+		t.Errorf("tasks ran out of order: %v", order)
+	}
+	if report.Failed != 1 {
+		t.Errorf("expected 1 failed task, got %d", report.Failed)
+	}
+	if report.Results[0].Summary != "ok" || report.Results[1].Error != "boom" {
+		t.Errorf("unexpected results: %+v", report.Results)
+	}
+	if s.LastReport() != report {
+		t.Errorf("expected LastReport to return the latest report")
+	}
+
+	select {
+	case ev := <-events:
+		if ev.Type != repeater.EventMaintenance {
+			t.Errorf("expected %s event, got %s", repeater.EventMaintenance, ev.Type)
+		}
+	default:
+		t.Errorf("expected a maintenance report event")
+	}
+}
+
+func TestTaskTimeout(t *testing.T) {
+	s := NewScheduler("0 0 3 * * *", 20*time.Millisecond, nil, logger.NewTestLogger(os.Stdout))
+	s.Register("slow", func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+
+	report := s.RunNow(context.Background())
+	if report.Failed != 1 {
+		t.Errorf("expected the slow task to time out, got %+v", report.Results)
+	}
+}
+
+func TestInvalidSchedule(t *testing.T) {
+	s := NewScheduler("not a cron", time.Second, nil, logger.NewTestLogger(os.Stdout))
+	if err := s.Start(context.Background()); err == nil {
+		t.Errorf("expected an error for an invalid schedule")
+	}
+}
@@ -0,0 +1,217 @@
+// Package metrics serves metrics over HTTP in the Prometheus text format and,
+// for scrapers that ask for it, the OpenMetrics format. It carries no client
+// library: callers build the metric families on every scrape, which suits
+// values that already live elsewhere, such as bridge schedules.
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// Content types of the two exposition formats
+const (
+	textContentType        = "text/plain; version=0.0.4; charset=utf-8"
+	openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+// Type is the type of a metric family
+type Type string
+
+const (
+	Gauge   Type = "gauge"
+	Counter Type = "counter"
+)
+
+// Label is a metric label
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample is one value of a metric family
+type Sample struct {
+	Labels []Label
+	Value  float64
+}
+
+// Family is a named group of samples. Counter names omit the _total suffix,
+// which is added to their samples.
+type Family struct {
+	Name    string
+	Help    string
+	Type    Type
+	Samples []Sample
+}
+
+// Format is an exposition format
+type Format int
+
+const (
+	FormatText        Format = iota // Prometheus text format 0.0.4
+	FormatOpenMetrics               // OpenMetrics 1.0
+)
+
+// Negotiate picks the exposition format for an Accept header
+func Negotiate(accept string) Format {
+	if strings.Contains(accept, "application/openmetrics-text") {
+		return FormatOpenMetrics
+	}
+	return FormatText
+}
+
+// ContentType returns the Content-Type header of a format
+func (f Format) ContentType() string {
+	if f == FormatOpenMetrics {
+		return openMetricsContentType
+	}
+	return textContentType
+}
+
+// Write writes the families in the given format
+func Write(w io.Writer, families []Family, format Format) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		sampleName := f.Name
+		familyName := f.Name
+		if f.Type == Counter {
+			sampleName += "_total"
+			if format == FormatText {
+				familyName = sampleName
+			}
+		}
+
+		if f.Help != "" {
+			fmt.Fprintf(bw, "# HELP %s %s\n", familyName, escapeHelp(f.Help))
+		}
+		fmt.Fprintf(bw, "# TYPE %s %s\n", familyName, f.Type)
+		for _, s := range f.Samples {
+			bw.WriteString(sampleName)
+			writeLabels(bw, s.Labels)
+			bw.WriteByte(' ')
+			bw.WriteString(formatValue(s.Value))
+			bw.WriteByte('\n')
+		}
+	}
+	if format == FormatOpenMetrics {
+		bw.WriteString("# EOF\n")
+	}
+	return bw.Flush()
+}
+
+// writeLabels writes a label set, if any
+func writeLabels(w *bufio.Writer, labels []Label) {
+	if len(labels) == 0 {
+		return
+	}
+	w.WriteByte('{')
+	for i, l := range labels {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		w.WriteString(l.Name)
+		w.WriteString(`="`)
+		w.WriteString(escapeLabel(l.Value))
+		w.WriteByte('"')
+	}
+	w.WriteByte('}')
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+// escapeLabel escapes a label value
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
+
+// escapeHelp escapes a HELP text
+func escapeHelp(v string) string {
+	return helpEscaper.Replace(v)
+}
+
+// formatValue renders a sample value
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// Handler serves the families returned by source on every request
+func Handler(source func() []Family) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		format := Negotiate(r.Header.Get("Accept"))
+		w.Header().Set("Content-Type", format.ContentType())
+		_ = Write(w, source(), format)
+	})
+}
+
+// Server serves metrics on their own HTTP listener
+type Server struct {
+	listen string
+	path   string
+	source func() []Family
+	logger *logger.Logger
+}
+
+// NewServer creates a metrics server listening on listen (host:port) that
+// serves the families from source at path
+func NewServer(listen, path string, source func() []Family, log *logger.Logger) *Server {
+	return &Server{
+		listen: listen,
+		path:   path,
+		source: source,
+		logger: log.WithComponent("metrics"),
+	}
+}
+
+// Start serves metrics until ctx is cancelled
+func (s *Server) Start(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.listen)
+	if err != nil {
+		return fmt.Errorf("metrics listen on %s: %w", s.listen, err)
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve serves metrics on a listener opened by the caller, e.g. one inherited
+// from a previous process, until ctx is cancelled. The listener is closed then.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	mux := http.NewServeMux()
+	mux.Handle(s.path, Handler(s.source))
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	s.logger.Info("Metrics server listening",
+		logger.String("address", ln.Addr().String()),
+		logger.String("path", s.path))
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func testFamilies() []Family {
+	return []Family{
+		{
+			Name: "ysf_bridge_state",
+			Help: "Current state.",
+			Type: Gauge,
+			Samples: []Sample{
+				{Labels: []Label{{Name: "bridge", Value: `Net "A"\1`}, {Name: "state", Value: "connected"}}, Value: 1},
+				{Value: math.Inf(1)},
+			},
+		},
+		{
+			Name:    "ysf_bridge_schedule_missed_windows",
+			Help:    "Missed windows.",
+			Type:    Counter,
+			Samples: []Sample{{Labels: []Label{{Name: "bridge", Value: "b"}}, Value: 3}},
+		},
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, testFamilies(), FormatText); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP ysf_bridge_state Current state.
+# TYPE ysf_bridge_state gauge
+ysf_bridge_state{bridge="Net \"A\"\\1",state="connected"} 1
+ysf_bridge_state +Inf
+# HELP ysf_bridge_schedule_missed_windows_total Missed windows.
+# TYPE ysf_bridge_schedule_missed_windows_total counter
+ysf_bridge_schedule_missed_windows_total{bridge="b"} 3
+`
+	if buf.String() != want {
+		t.Errorf("unexpected text exposition:\n%s", buf.String())
+	}
+}
+
+func TestWriteOpenMetrics(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, testFamilies(), FormatOpenMetrics); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	// Counter families are named without the suffix their samples carry
+	if !strings.Contains(out, "# TYPE ysf_bridge_schedule_missed_windows counter\n") ||
+		!strings.Contains(out, "ysf_bridge_schedule_missed_windows_total{bridge=\"b\"} 3\n") {
+		t.Errorf("unexpected counter exposition:\n%s", out)
+	}
+	if !strings.HasSuffix(out, "# EOF\n") {
+		t.Errorf("expected # EOF terminator:\n%s", out)
+	}
+}
+
+func TestHandlerNegotiatesFormat(t *testing.T) {
+	srv := httptest.NewServer(Handler(testFamilies))
+	defer srv.Close()
+
+	for accept, want := range map[string]string{
+		"":                                   textContentType,
+		"text/plain;version=0.0.4;q=0.5,*/*": textContentType,
+		"application/openmetrics-text;version=1.0.0,text/plain;version=0.0.4;q=0.5": openMetricsContentType,
+	} {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if got := resp.Header.Get("Content-Type"); got != want {
+			t.Errorf("Accept %q: content type %q, want %q", accept, got, want)
+		}
+		if !bytes.Contains(body, []byte("ysf_bridge_state")) {
+			t.Errorf("Accept %q: missing metrics in %s", accept, body)
+		}
+	}
+}
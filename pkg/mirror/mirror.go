@@ -0,0 +1,264 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/network"
+)
+
+// queueSize bounds the frames waiting to be written to the sink
+const queueSize = 256
+
+// Options configures a Mirror
+type Options struct {
+	Target      string // udp://host:port or unix:///path/to.sock
+	SampleEvery int    // Mirror 1 in N frames (<= 1 mirrors every frame)
+	RateLimit   int    // Max frames per second (0 = unlimited)
+	Enabled     bool   // Initial state; can be toggled at runtime
+}
+
+// Status reports the mirror configuration and counters
+type Status struct {
+	Enabled     bool   `json:"enabled"`
+	Target      string `json:"target"`
+	SampleEvery int    `json:"sample_every"`
+	RateLimit   int    `json:"rate_limit"`
+	Mirrored    uint64 `json:"mirrored"`     // Frames written to the sink
+	Sampled     uint64 `json:"sampled_out"`  // Frames skipped by sampling
+	RateLimited uint64 `json:"rate_limited"` // Frames skipped by the rate limit
+	Dropped     uint64 `json:"dropped"`      // Frames dropped because the sink was slow
+	Errors      uint64 `json:"errors"`       // Failed writes or connection attempts
+}
+
+// Mirror copies received YSFD frames to a secondary UDP or Unix datagram
+// socket for external analysis tools. Frames are copied as received.
+//
+// It is attached to the network server as a plugin pre-handle hook, so it sees
+// every data frame, including frames later dropped by the reflector.
+type Mirror struct {
+	network string
+	address string
+	target  string
+	sample  int
+	rate    int
+	logger  *logger.Logger
+	queue   chan []byte
+
+	mu      sync.Mutex
+	enabled bool
+	seen    uint64
+	tokens  float64
+	refill  time.Time
+	failing bool // Suppresses repeated sink error logs until a write succeeds
+	status  Status
+}
+
+// New creates a mirror for the given options
+func New(opts Options, log *logger.Logger) (*Mirror, error) {
+	netw, addr, err := ParseTarget(opts.Target)
+	if err != nil {
+		return nil, err
+	}
+	if opts.SampleEvery < 1 {
+		opts.SampleEvery = 1
+	}
+
+	return &Mirror{
+		network: netw,
+		address: addr,
+		target:  opts.Target,
+		sample:  opts.SampleEvery,
+		rate:    opts.RateLimit,
+		logger:  log.WithComponent("mirror"),
+		queue:   make(chan []byte, queueSize),
+		enabled: opts.Enabled,
+	}, nil
+}
+
+// ParseTarget splits a mirror target URL into a dial network and address
+func ParseTarget(target string) (string, string, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid mirror target %q: %w", target, err)
+	}
+
+	switch u.Scheme {
+	case "udp":
+		if u.Host == "" {
+			return "", "", fmt.Errorf("invalid mirror target %q: missing host:port", target)
+		}
+		return "udp", u.Host, nil
+	case "unix":
+		if u.Path == "" {
+			return "", "", fmt.Errorf("invalid mirror target %q: missing socket path", target)
+		}
+		return "unixgram", u.Path, nil
+	default:
+		return "", "", fmt.Errorf("invalid mirror target %q: scheme must be udp or unix", target)
+	}
+}
+
+// Name implements network.Plugin
+func (m *Mirror) Name() string {
+	return "mirror"
+}
+
+// BeforeHandle implements network.PreHandleHook. It never drops packets.
+func (m *Mirror) BeforeHandle(packet *network.Packet) bool {
+	if packet.Type == network.PacketTypeData {
+		m.frame(packet.Data, time.Now())
+	}
+	return true
+}
+
+// SetEnabled turns mirroring on or off
+func (m *Mirror) SetEnabled(enabled bool) {
+	m.mu.Lock()
+	changed := m.enabled != enabled
+	m.enabled = enabled
+	m.mu.Unlock()
+
+	if changed {
+		m.logger.Info("Traffic mirroring toggled",
+			logger.String("target", m.target),
+			logger.Any("enabled", enabled))
+	}
+}
+
+// Backlog returns the frames waiting to be written and the queue size
+func (m *Mirror) Backlog() (int, int) {
+	return len(m.queue), cap(m.queue)
+}
+
+// Status returns the mirror configuration and counters
+func (m *Mirror) Status() Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	status := m.status
+	status.Enabled = m.enabled
+	status.Target = m.target
+	status.SampleEvery = m.sample
+	status.RateLimit = m.rate
+	return status
+}
+
+// Start writes queued frames to the sink until ctx is cancelled
+func (m *Mirror) Start(ctx context.Context) {
+	var conn net.Conn
+	defer func() {
+		if conn != nil {
+			_ = conn.Close()
+		}
+	}()
+
+	m.logger.Info("Traffic mirror started",
+		logger.String("target", m.target),
+		logger.Int("sample_every", m.sample),
+		logger.Int("rate_limit", m.rate))
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case data := <-m.queue:
+			if conn == nil {
+				var err error
+				if conn, err = net.Dial(m.network, m.address); err != nil {
+					m.countError("Failed to connect to mirror target", err)
+					conn = nil
+					continue
+				}
+			}
+
+			if _, err := conn.Write(data); err != nil {
+				// Redial on the next frame; the sink may have restarted
+				m.countError("Failed to write mirrored frame", err)
+				_ = conn.Close()
+				conn = nil
+				continue
+			}
+
+			m.mu.Lock()
+			m.status.Mirrored++
+			m.failing = false
+			m.mu.Unlock()
+		}
+	}
+}
+
+// frame applies sampling and rate limiting and queues a copy of data
+func (m *Mirror) frame(data []byte, now time.Time) {
+	m.mu.Lock()
+	if !m.enabled {
+		m.mu.Unlock()
+		return
+	}
+
+	m.seen++
+	if (m.seen-1)%uint64(m.sample) != 0 {
+		m.status.Sampled++
+		m.mu.Unlock()
+		return
+	}
+	if !m.allow(now) {
+		m.status.RateLimited++
+		m.mu.Unlock()
+		return
+	}
+	m.mu.Unlock()
+
+	// The packet buffer belongs to the caller
+	frame := append([]byte(nil), data...)
+	select {
+	case m.queue <- frame:
+	default:
+		m.mu.Lock()
+		m.status.Dropped++
+		m.mu.Unlock()
+	}
+}
+
+// allow is a token bucket allowing rate frames per second with a one second burst.
+// The caller holds m.mu.
+func (m *Mirror) allow(now time.Time) bool {
+	if m.rate <= 0 {
+		return true
+	}
+
+	capacity := float64(m.rate)
+	if m.refill.IsZero() {
+		m.tokens = capacity
+	} else if elapsed := now.Sub(m.refill).Seconds(); elapsed > 0 {
+		m.tokens += elapsed * capacity
+		if m.tokens > capacity {
+			m.tokens = capacity
+		}
+	}
+	m.refill = now
+
+	if m.tokens < 1 {
+		return false
+	}
+	m.tokens--
+	return true
+}
+
+// countError records a sink failure, logging only the first of a streak
+func (m *Mirror) countError(msg string, err error) {
+	m.mu.Lock()
+	m.status.Errors++
+	first := !m.failing
+	m.failing = true
+	m.mu.Unlock()
+
+	if first {
+		m.logger.Warn(msg, logger.String("target", m.target), logger.Error(err))
+	}
+}
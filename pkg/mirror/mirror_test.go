@@ -0,0 +1,96 @@
+package mirror
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/network"
+)
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		target, network, address string
+		ok                       bool
+	}{
+		{"udp://127.0.0.1:9000", "udp", "127.0.0.1:9000", true},
+		{"unix:///run/ysf/mirror.sock", "unixgram", "/run/ysf/mirror.sock", true},
+		{"tcp://127.0.0.1:9000", "", "", false},
+		{"udp://", "", "", false},
+	}
+	for _, tt := range tests {
+		netw, addr, err := ParseTarget(tt.target)
+		if (err == nil) != tt.ok || netw != tt.network || addr != tt.address {
+			t.Errorf("ParseTarget(%q) = %q, %q, %v", tt.target, netw, addr, err)
+		}
+	}
+}
+
+func TestMirrorForwardsDataFrames(t *testing.T) {
+	sink, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	defer func() { _ = sink.Close() }()
+
+	m, err := New(Options{Target: "udp://" + sink.LocalAddr().String(), Enabled: true}, logger.NewTestLogger(os.Stdout))
+	if err != nil {
+		t.Fatalf("new mirror: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go m.Start(ctx)
+
+	frame := make([]byte, network.DataPacketSize)
+	copy(frame, network.PacketTypeData)
+	poll := make([]byte, network.PollPacketSize)
+	copy(poll, network.PacketTypePoll)
+
+	if !m.BeforeHandle(&network.Packet{Type: network.PacketTypePoll, Data: poll}) {
+		t.Fatalf("mirror must never drop packets")
+	}
+	m.BeforeHandle(&network.Packet{Type: network.PacketTypeData, Data: frame})
+
+	_ = sink.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, 256)
+	n, _, err := sink.ReadFromUDP(buf)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if n != network.DataPacketSize || string(buf[:4]) != network.PacketTypeData {
+		t.Errorf("expected the YSFD frame only, got %d bytes %q", n, buf[:4])
+	}
+}
+
+func TestMirrorSamplingAndRateLimit(t *testing.T) {
+	m, err := New(Options{Target: "udp://127.0.0.1:9", SampleEvery: 2, RateLimit: 2}, logger.NewTestLogger(os.Stdout))
+	if err != nil {
+		t.Fatalf("new mirror: %v", err)
+	}
+
+	now := time.Now()
+	m.frame([]byte("YSFD"), now)
+	if len(m.queue) != 0 {
+		t.Fatalf("disabled mirror must not queue frames")
+	}
+
+	m.SetEnabled(true)
+	for i := 0; i < 8; i++ {
+		m.frame([]byte("YSFD"), now)
+	}
+
+	// 4 of 8 frames survive sampling; a burst of 2 per second passes the limit
+	status := m.Status()
+	if status.Sampled != 4 || status.RateLimited != 2 || len(m.queue) != 2 {
+		t.Errorf("unexpected counters: %+v, queued %d", status, len(m.queue))
+	}
+
+	// One second later the bucket has refilled
+	m.frame([]byte("YSFD"), now.Add(time.Second))
+	if len(m.queue) != 3 {
+		t.Errorf("expected refilled bucket to pass a frame, queued %d", len(m.queue))
+	}
+}
@@ -0,0 +1,109 @@
+package network
+
+// Data channel (DCH) coding for YSF data FR mode frames.
+//
+// In data FR mode (FICH data type DTDataFR) the 90 payload bytes after the
+// FICH hold two 20-byte data blocks. Each block is whitened, protected by a
+// CRC-CCITT and a K=5 rate 1/2 convolutional code, interleaved across 360
+// bits and spread over five 9-byte slots: the first block in the first half
+// of each 18-byte payload group, the second in the other half. This is the
+// mode Wires-X commands and replies travel in; the layout and codes match
+// MMDVMHost's YSFPayload implementation.
+
+// FICH data type values
+const (
+	DTVoiceData1 = 0 // V/D mode type 1
+	DTDataFR     = 1 // Data FR mode
+	DTVoiceData2 = 2 // V/D mode type 2
+	DTVoiceFR    = 3 // Voice FR mode
+)
+
+const (
+	// DataBlockSize is the size of a data FR mode data block
+	DataBlockSize = 20
+	// fichSize is the size of the encoded FICH after the sync pattern
+	fichSize = 25
+	// payloadOffset is the offset of the payload within a YSF frame
+	payloadOffset = syncSize + fichSize
+)
+
+// SyncBytes is the sync pattern starting every YSF frame
+var SyncBytes = [syncSize]byte{0xD4, 0x71, 0xC9, 0x63, 0x4D}
+
+// dataWhitening is XORed over data blocks (the PN9 sequence x^9 + x^5 + 1)
+var dataWhitening = [DataBlockSize]byte{
+	0x93, 0xD7, 0x51, 0x21, 0x9C, 0x2F, 0x6C, 0xD0, 0xEF, 0x0F,
+	0xF8, 0x3D, 0xF1, 0x73, 0x20, 0x94, 0xED, 0x1E, 0x7C, 0xD8,
+}
+
+// dchInterleave returns the bit position of the i-th interleaved dibit
+func dchInterleave(i int) int {
+	return (i%9)*40 + (i/9)*2
+}
+
+// DecodeDataFR decodes data block 1 or 2 from a 120-byte data FR mode frame.
+// It returns false when the frame is too short or the CRC does not match.
+func DecodeDataFR(frame []byte, block int) ([]byte, bool) {
+	if len(frame) < FrameSize || (block != 1 && block != 2) {
+		return nil, false
+	}
+
+	var dch [45]byte
+	for i := 0; i < 5; i++ {
+		start := payloadOffset + i*18 + (block-1)*9
+		copy(dch[i*9:], frame[start:start+9])
+	}
+
+	var dibits [180][2]uint8
+	for i := range dibits {
+		n := dchInterleave(i)
+		dibits[i][0] = readBit(dch[:], n)
+		dibits[i][1] = readBit(dch[:], n+1)
+	}
+	decoded := viterbiDecode(dibits[:], 176)
+
+	if crcCCITT(decoded[:DataBlockSize]) != uint16(decoded[20])<<8|uint16(decoded[21]) {
+		return nil, false
+	}
+	data := make([]byte, DataBlockSize)
+	for i := range data {
+		data[i] = decoded[i] ^ dataWhitening[i]
+	}
+	return data, true
+}
+
+// EncodeDataFR writes data block 1 or 2 into a 120-byte data FR mode frame.
+// Data shorter than DataBlockSize is padded with zeros.
+func EncodeDataFR(data []byte, frame []byte, block int) {
+	if len(frame) < FrameSize || (block != 1 && block != 2) {
+		return
+	}
+
+	// 20 data bytes and the CRC followed by 4 zero tail bits
+	var raw [23]byte
+	copy(raw[:DataBlockSize], data)
+	for i := 0; i < DataBlockSize; i++ {
+		raw[i] ^= dataWhitening[i]
+	}
+	crc := crcCCITT(raw[:DataBlockSize])
+	raw[20] = byte(crc >> 8)
+	raw[21] = byte(crc)
+
+	var dch [45]byte
+	var d1, d2, d3, d4 uint8
+	for i := 0; i < 180; i++ {
+		d := readBit(raw[:], i)
+		g1 := (d + d3 + d4) & 1
+		g2 := (d + d1 + d2 + d4) & 1
+		d4, d3, d2, d1 = d3, d2, d1, d
+
+		n := dchInterleave(i)
+		writeBit(dch[:], n, g1)
+		writeBit(dch[:], n+1, g2)
+	}
+
+	for i := 0; i < 5; i++ {
+		start := payloadOffset + i*18 + (block-1)*9
+		copy(frame[start:start+9], dch[i*9:i*9+9])
+	}
+}
@@ -0,0 +1,45 @@
+package network
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDataFRRoundTrip(t *testing.T) {
+	frame := make([]byte, FrameSize)
+	EncodeFICH(FICH{FI: FICommunications, FN: 1, FT: 2, DT: DTDataFR}, frame)
+	block1 := []byte("*****00001YSF NEXUS ")
+	block2 := []byte("W1AW")
+	EncodeDataFR(block1, frame, 1)
+	EncodeDataFR(block2, frame, 2)
+
+	got, ok := DecodeDataFR(frame, 1)
+	if !ok || !bytes.Equal(got, block1) {
+		t.Errorf("block 1 = %q (%v)", got, ok)
+	}
+	got, ok = DecodeDataFR(frame, 2)
+	if !ok || !bytes.Equal(got, append([]byte("W1AW"), make([]byte, 16)...)) {
+		t.Errorf("block 2 = %q (%v)", got, ok)
+	}
+
+	// The payload leaves the FICH alone
+	if fich, ok := DecodeFICH(frame); !ok || fich.DT != DTDataFR || fich.FN != 1 {
+		t.Errorf("FICH = %+v (%v)", fich, ok)
+	}
+
+	// A few bit errors are corrected; a ruined block fails its CRC
+	frame[payloadOffset+2] ^= 0x10
+	frame[payloadOffset+40] ^= 0x01
+	if got, ok := DecodeDataFR(frame, 1); !ok || !bytes.Equal(got, block1) {
+		t.Errorf("block 1 after bit errors = %q (%v)", got, ok)
+	}
+	for i := payloadOffset; i < FrameSize; i++ {
+		frame[i] = ^frame[i]
+	}
+	if _, ok := DecodeDataFR(frame, 2); ok {
+		t.Error("expected a CRC failure for a ruined block")
+	}
+	if _, ok := DecodeDataFR(frame, 3); ok {
+		t.Error("expected no block 3")
+	}
+}
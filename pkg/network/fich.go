@@ -0,0 +1,299 @@
+package network
+
+// FICH (Frame Information CHannel) decoding for YSF frames.
+//
+// Every 120-byte YSF frame carried inside a YSFD packet starts with a 5-byte
+// sync pattern followed by the FICH: 4 information bytes protected by a
+// CRC-CCITT, Golay(24,12) and a K=5 rate 1/2 convolutional code, then
+// interleaved across 200 bits. The layout and codes match MMDVMHost's
+// YSFFICH implementation so frames from real gateways decode correctly.
+
+// YSF frame layout inside a YSFD packet
+const (
+	// FrameCounterOffset is the offset of the frame counter / end-of-stream byte
+	FrameCounterOffset = 34
+	// FrameOffset is the offset of the 120-byte YSF frame
+	FrameOffset = 35
+	// FrameSize is the size of a YSF frame
+	FrameSize = 120
+	// syncSize is the size of the sync pattern at the start of a frame
+	syncSize = 5
+)
+
+// FICH frame indicator values
+const (
+	FIHeader         = 0
+	FICommunications = 1
+	FITerminator     = 2
+	FITest           = 3
+)
+
+// FICH represents a decoded Frame Information CHannel
+type FICH struct {
+	FI   uint8 // Frame indicator (header, communications, terminator, test)
+	CS   uint8 // Callsign information
+	CM   uint8 // Call mode
+	BN   uint8 // Block number
+	BT   uint8 // Block total
+	FN   uint8 // Frame number
+	FT   uint8 // Frame total
+	DT   uint8 // Data type
+	MR   uint8 // Message route
+	Dev  bool  // Deviation
+	DGID uint8 // Digital group ID
+}
+
+// golayGenPoly is the Golay(23,12) generator polynomial
+const golayGenPoly = 0xC75
+
+// golayDecodeTable maps a Golay(23,12) syndrome to its correctable error pattern
+var golayDecodeTable = buildGolayDecodeTable()
+
+// fichInterleave returns the bit position of the i-th interleaved dibit
+func fichInterleave(i int) int {
+	return (i%5)*40 + (i/5)*2
+}
+
+// DecodeFICH decodes the FICH from a 120-byte YSF frame.
+// It returns false when the frame is too short or the CRC does not match.
+func DecodeFICH(frame []byte) (FICH, bool) {
+	if len(frame) < FrameSize {
+		return FICH{}, false
+	}
+	bits := frame[syncSize:]
+
+	var dibits [100][2]uint8
+	for i := 0; i < 100; i++ {
+		n := fichInterleave(i)
+		dibits[i][0] = readBit(bits, n)
+		dibits[i][1] = readBit(bits, n+1)
+	}
+
+	decoded := viterbiDecode(dibits[:], 96)
+
+	var raw [6]byte
+	b0 := golayDecode24128(uint32(decoded[0])<<16 | uint32(decoded[1])<<8 | uint32(decoded[2]))
+	b1 := golayDecode24128(uint32(decoded[3])<<16 | uint32(decoded[4])<<8 | uint32(decoded[5]))
+	b2 := golayDecode24128(uint32(decoded[6])<<16 | uint32(decoded[7])<<8 | uint32(decoded[8]))
+	b3 := golayDecode24128(uint32(decoded[9])<<16 | uint32(decoded[10])<<8 | uint32(decoded[11]))
+
+	raw[0] = byte(b0 >> 4)
+	raw[1] = byte(b0<<4) | byte((b1>>8)&0x0F)
+	raw[2] = byte(b1)
+	raw[3] = byte(b2 >> 4)
+	raw[4] = byte(b2<<4) | byte((b3>>8)&0x0F)
+	raw[5] = byte(b3)
+
+	if crcCCITT(raw[:4]) != uint16(raw[4])<<8|uint16(raw[5]) {
+		return FICH{}, false
+	}
+
+	return FICH{
+		FI:   (raw[0] >> 6) & 0x03,
+		CS:   (raw[0] >> 4) & 0x03,
+		CM:   (raw[0] >> 2) & 0x03,
+		BN:   raw[0] & 0x03,
+		BT:   (raw[1] >> 6) & 0x03,
+		FN:   (raw[1] >> 3) & 0x07,
+		FT:   raw[1] & 0x07,
+		DT:   raw[2] & 0x03,
+		MR:   (raw[2] >> 3) & 0x03,
+		Dev:  raw[2]&0x40 == 0x40,
+		DGID: raw[3] & 0x7F,
+	}, true
+}
+
+// EncodeFICH writes the encoded FICH into a 120-byte YSF frame
+func EncodeFICH(f FICH, frame []byte) {
+	if len(frame) < FrameSize {
+		return
+	}
+
+	var raw [6]byte
+	raw[0] = (f.FI&0x03)<<6 | (f.CS&0x03)<<4 | (f.CM&0x03)<<2 | f.BN&0x03
+	raw[1] = (f.BT&0x03)<<6 | (f.FN&0x07)<<3 | f.FT&0x07
+	raw[2] = (f.MR&0x03)<<3 | f.DT&0x03
+	if f.Dev {
+		raw[2] |= 0x40
+	}
+	raw[3] = f.DGID & 0x7F
+	crc := crcCCITT(raw[:4])
+	raw[4] = byte(crc >> 8)
+	raw[5] = byte(crc)
+
+	words := [4]uint32{
+		uint32(raw[0])<<4 | uint32(raw[1])>>4,
+		uint32(raw[1]&0x0F)<<8 | uint32(raw[2]),
+		uint32(raw[3])<<4 | uint32(raw[4])>>4,
+		uint32(raw[4]&0x0F)<<8 | uint32(raw[5]),
+	}
+
+	// 96 Golay bits followed by 4 zero tail bits for the convolutional encoder
+	var golay [13]byte
+	for i, w := range words {
+		c := golayEncode24128(w)
+		golay[i*3] = byte(c >> 16)
+		golay[i*3+1] = byte(c >> 8)
+		golay[i*3+2] = byte(c)
+	}
+
+	bits := frame[syncSize:]
+	var d1, d2, d3, d4 uint8
+	for i := 0; i < 100; i++ {
+		d := readBit(golay[:], i)
+		g1 := (d + d3 + d4) & 1
+		g2 := (d + d1 + d2 + d4) & 1
+		d4, d3, d2, d1 = d3, d2, d1, d
+
+		n := fichInterleave(i)
+		writeBit(bits, n, g1)
+		writeBit(bits, n+1, g2)
+	}
+}
+
+// viterbiDecode performs hard-decision Viterbi decoding of the YSF K=5 rate 1/2
+// convolutional code and returns the first nBits decoded bits packed MSB first.
+func viterbiDecode(dibits [][2]uint8, nBits int) []byte {
+	const states = 16
+	const inf = 1 << 30
+
+	metrics := make([]int, states)
+	for s := 1; s < states; s++ {
+		metrics[s] = inf
+	}
+	history := make([][states]uint8, len(dibits))
+
+	for t, sym := range dibits {
+		next := make([]int, states)
+		for s := range next {
+			next[s] = inf
+		}
+		for s := 0; s < states; s++ {
+			if metrics[s] >= inf {
+				continue
+			}
+			d1, d2, d3, d4 := uint8(s>>3)&1, uint8(s>>2)&1, uint8(s>>1)&1, uint8(s)&1
+			for d := uint8(0); d < 2; d++ {
+				g1 := (d + d3 + d4) & 1
+				g2 := (d + d1 + d2 + d4) & 1
+				cost := metrics[s]
+				if g1 != sym[0] {
+					cost++
+				}
+				if g2 != sym[1] {
+					cost++
+				}
+				ns := int(d)<<3 | int(d1)<<2 | int(d2)<<1 | int(d3)
+				if cost < next[ns] {
+					next[ns] = cost
+					// Remember the dropped bit (d4) so the predecessor can be rebuilt
+					history[t][ns] = d4
+				}
+			}
+		}
+		metrics = next
+	}
+
+	// The encoder is flushed with zero tail bits, so trace back from state 0
+	out := make([]byte, (len(dibits)+7)/8)
+	state := 0
+	for t := len(dibits) - 1; t >= 0; t-- {
+		bit := uint8(state>>3) & 1
+		writeBit(out, t, bit)
+		state = (state<<1)&0x0F | int(history[t][state])
+	}
+
+	return out[:(nBits+7)/8]
+}
+
+// golayEncode23127 returns the systematic Golay(23,12) codeword for 12 data bits
+func golayEncode23127(data uint32) uint32 {
+	code := (data & 0xFFF) << 11
+	return code | golaySyndrome23127(code)
+}
+
+// golayEncode24128 returns the extended Golay(24,12) codeword with an even parity bit
+func golayEncode24128(data uint32) uint32 {
+	code := golayEncode23127(data) << 1
+	if parity(code) {
+		code |= 1
+	}
+	return code
+}
+
+// golayDecode24128 decodes a Golay(24,12) codeword, correcting up to 3 bit errors
+func golayDecode24128(code uint32) uint32 {
+	c := (code >> 1) & 0x7FFFFF
+	c ^= golayDecodeTable[golaySyndrome23127(c)]
+	return c >> 11
+}
+
+// golaySyndrome23127 computes the remainder of a 23-bit pattern divided by the generator
+func golaySyndrome23127(pattern uint32) uint32 {
+	for bit := 22; bit >= 11; bit-- {
+		if pattern&(1<<uint(bit)) != 0 {
+			pattern ^= golayGenPoly << uint(bit-11)
+		}
+	}
+	return pattern
+}
+
+// buildGolayDecodeTable enumerates every error pattern of weight <= 3. Golay(23,12)
+// is a perfect code, so these patterns cover all 2048 syndromes exactly once.
+func buildGolayDecodeTable() [2048]uint32 {
+	var table [2048]uint32
+	for i := 0; i < 23; i++ {
+		p := uint32(1) << uint(i)
+		table[golaySyndrome23127(p)] = p
+		for j := i + 1; j < 23; j++ {
+			q := p | uint32(1)<<uint(j)
+			table[golaySyndrome23127(q)] = q
+			for k := j + 1; k < 23; k++ {
+				r := q | uint32(1)<<uint(k)
+				table[golaySyndrome23127(r)] = r
+			}
+		}
+	}
+	return table
+}
+
+// crcCCITT computes the CRC-CCITT (poly 0x1021, init 0, inverted) used by the FICH
+func crcCCITT(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return ^crc
+}
+
+// parity reports whether v has an odd number of set bits
+func parity(v uint32) bool {
+	v ^= v >> 16
+	v ^= v >> 8
+	v ^= v >> 4
+	v ^= v >> 2
+	v ^= v >> 1
+	return v&1 == 1
+}
+
+// readBit reads bit i (MSB first) from b
+func readBit(b []byte, i int) uint8 {
+	return (b[i>>3] >> uint(7-i&7)) & 1
+}
+
+// writeBit writes bit i (MSB first) in b
+func writeBit(b []byte, i int, v uint8) {
+	mask := byte(0x80) >> uint(i&7)
+	if v != 0 {
+		b[i>>3] |= mask
+	} else {
+		b[i>>3] &^= mask
+	}
+}
@@ -0,0 +1,113 @@
+package network
+
+import (
+	"net"
+	"testing"
+)
+
+func TestFICHRoundTrip(t *testing.T) {
+	tests := []FICH{
+		{FI: FIHeader, CS: 2, CM: 0, BN: 0, BT: 0, FN: 0, FT: 6, DT: 2, MR: 0, Dev: false, DGID: 0},
+		{FI: FICommunications, CS: 2, CM: 0, BT: 1, FN: 3, FT: 6, DT: 2, MR: 2, Dev: true, DGID: 42},
+		{FI: FITerminator, CS: 1, CM: 3, BN: 3, BT: 3, FN: 7, FT: 7, DT: 3, MR: 3, DGID: 127},
+	}
+
+	for _, want := range tests {
+		frame := make([]byte, FrameSize)
+		EncodeFICH(want, frame)
+
+		got, ok := DecodeFICH(frame)
+		if !ok {
+			t.Fatalf("DecodeFICH failed CRC for %+v", want)
+		}
+		if got != want {
+			t.Errorf("round trip mismatch: got %+v, want %+v", got, want)
+		}
+	}
+}
+
+func TestFICHCorrectsBitErrors(t *testing.T) {
+	want := FICH{FI: FICommunications, CS: 2, FN: 5, FT: 6, DT: 2, DGID: 10}
+	frame := make([]byte, FrameSize)
+	EncodeFICH(want, frame)
+
+	// Flip a few spread-out bits inside the FICH region
+	for _, bit := range []int{syncSize*8 + 3, syncSize*8 + 77, syncSize*8 + 150} {
+		frame[bit>>3] ^= 0x80 >> uint(bit&7)
+	}
+
+	got, ok := DecodeFICH(frame)
+	if !ok {
+		t.Fatalf("expected bit errors to be corrected")
+	}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestFICHDetectsGarbage(t *testing.T) {
+	frame := make([]byte, FrameSize)
+	for i := range frame {
+		frame[i] = byte(i*37 + 11)
+	}
+	if _, ok := DecodeFICH(frame); ok {
+		t.Errorf("expected CRC failure for garbage frame")
+	}
+	if _, ok := DecodeFICH(frame[:10]); ok {
+		t.Errorf("expected failure for short frame")
+	}
+}
+
+func TestPacketFrameCounterAndEOT(t *testing.T) {
+	addr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 42000}
+	data := make([]byte, DataPacketSize)
+	copy(data[0:4], PacketTypeData)
+	data[FrameCounterOffset] = 17<<1 | 0x01
+	EncodeFICH(FICH{FI: FITerminator, FT: 6}, data[FrameOffset:FrameOffset+FrameSize])
+
+	p, err := ParsePacket(data, addr)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	if fc, ok := p.FrameCounter(); !ok || fc != 17 {
+		t.Errorf("expected frame counter 17, got %d (ok=%v)", fc, ok)
+	}
+	if !p.IsEndOfStream() {
+		t.Errorf("expected end-of-stream flag")
+	}
+	if f, ok := p.FICH(); !ok || f.FI != FITerminator {
+		t.Errorf("expected terminator FICH, got %+v (ok=%v)", f, ok)
+	}
+
+	// The FICH is decoded once per packet
+	if allocs := testing.AllocsPerRun(10, func() { p.FICH(); p.IsTerminator() }); allocs != 0 {
+		t.Errorf("expected the decoded FICH to be reused, got %v allocations", allocs)
+	}
+}
+
+func TestCreateTerminator(t *testing.T) {
+	addr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 42000}
+	data := make([]byte, DataPacketSize)
+	copy(data[0:4], PacketTypeData)
+	data[FrameCounterOffset] = 9 << 1
+	EncodeFICH(FICH{FI: FICommunications, FN: 3, FT: 6, DGID: 10}, data[FrameOffset:FrameOffset+FrameSize])
+
+	p, err := ParsePacket(CreateTerminator(data), addr)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !p.IsTerminator() || !p.IsEndOfStream() {
+		t.Errorf("expected a terminator with the end-of-stream flag")
+	}
+	if f, ok := p.FICH(); !ok || f.FN != 3 || f.DGID != 10 {
+		t.Errorf("expected FICH fields to be kept, got %+v (ok=%v)", f, ok)
+	}
+	if data[FrameCounterOffset] != 9<<1 {
+		t.Errorf("expected the original packet to be left unchanged")
+	}
+
+	if CreateTerminator(data[:FrameOffset]) != nil {
+		t.Errorf("expected nil for a short packet")
+	}
+}
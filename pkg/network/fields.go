@@ -0,0 +1,100 @@
+package network
+
+import (
+	"fmt"
+	"strings"
+)
+
+// YSF callsign fields are 10 bytes, left-aligned and padded with spaces. Not
+// every gateway follows that: some pad with NULs, some NUL-terminate and leave
+// stale bytes behind the terminator, some right-align or let control bytes
+// slip in. All callsign fields are read here so the rules live in one place.
+//
+// In tolerant mode (the default) such fields are repaired: the field ends at
+// the first NUL, control bytes are dropped and surrounding spaces trimmed. In
+// strict mode a YSFD field that deviates from the spec is an error and the
+// packet is rejected; other packet types are always read tolerantly.
+
+// Callsign field layout
+const (
+	CallsignFieldSize  = 10
+	GatewayFieldOffset = 4  // Gateway/repeater callsign, in every packet type that carries one
+	SourceFieldOffset  = 14 // YSFD source callsign
+	DestFieldOffset    = 24 // YSFD destination callsign
+)
+
+// DataFields are the callsign fields of a YSFD packet
+type DataFields struct {
+	Gateway string
+	Source  string
+	Dest    string
+}
+
+// ParseDataFields reads the callsign fields of a YSFD packet. Missing fields
+// of a short packet are left empty.
+func ParseDataFields(data []byte, strict bool) (DataFields, error) {
+	var fields DataFields
+	var err error
+	if fields.Gateway, err = callsignFieldAt(data, GatewayFieldOffset, strict); err != nil {
+		return fields, fmt.Errorf("gateway callsign: %w", err)
+	}
+	if fields.Source, err = callsignFieldAt(data, SourceFieldOffset, strict); err != nil {
+		return fields, fmt.Errorf("source callsign: %w", err)
+	}
+	if fields.Dest, err = callsignFieldAt(data, DestFieldOffset, strict); err != nil {
+		return fields, fmt.Errorf("destination callsign: %w", err)
+	}
+	return fields, nil
+}
+
+// callsignFieldAt reads the callsign field at offset, or "" when the packet
+// is too short to hold it
+func callsignFieldAt(data []byte, offset int, strict bool) (string, error) {
+	if len(data) < offset+CallsignFieldSize {
+		return "", nil
+	}
+	return ReadCallsignField(data[offset:offset+CallsignFieldSize], strict)
+}
+
+// ReadCallsignField decodes one callsign field
+func ReadCallsignField(field []byte, strict bool) (string, error) {
+	if strict {
+		return readStrictField(field)
+	}
+
+	var b strings.Builder
+	for _, c := range field {
+		if c == 0 {
+			break
+		}
+		if c < 0x20 || c > 0x7e {
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// readStrictField decodes a field that must be printable ASCII, left-aligned
+// and padded with spaces or NULs only
+func readStrictField(field []byte) (string, error) {
+	end := len(field)
+	for i, c := range field {
+		if c == ' ' || c == 0 {
+			end = i
+			break
+		}
+		if c < 0x20 || c > 0x7e {
+			return "", fmt.Errorf("invalid byte 0x%02x at %d", c, i)
+		}
+	}
+	for i := end; i < len(field); i++ {
+		if field[i] != ' ' && field[i] != 0 {
+			if end == 0 {
+				return "", fmt.Errorf("field is not left-aligned")
+			}
+			return "", fmt.Errorf("unexpected byte 0x%02x after padding at %d", field[i], i)
+		}
+	}
+	return string(field[:end]), nil
+}
@@ -0,0 +1,118 @@
+package network
+
+import (
+	"net"
+	"testing"
+)
+
+// ysfdHeader builds a 155-byte YSFD packet with the given 10-byte gateway,
+// source and destination fields
+func ysfdHeader(gateway, source, dest string) []byte {
+	data := make([]byte, DataPacketSize)
+	copy(data[0:4], PacketTypeData)
+	copy(data[GatewayFieldOffset:], gateway)
+	copy(data[SourceFieldOffset:], source)
+	copy(data[DestFieldOffset:], dest)
+	return data
+}
+
+func TestParseDataFields(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      []byte
+		expected  DataFields
+		strictErr bool
+	}{
+		{
+			name:     "space padded",
+			data:     ysfdHeader("W1AW      ", "K1ABC     ", "ALL       "),
+			expected: DataFields{Gateway: "W1AW", Source: "K1ABC", Dest: "ALL"},
+		},
+		{
+			name:     "NUL padded",
+			data:     ysfdHeader("W1AW\x00\x00\x00\x00\x00\x00", "K1ABC\x00\x00\x00\x00\x00", "ALL\x00\x00\x00\x00\x00\x00\x00"),
+			expected: DataFields{Gateway: "W1AW", Source: "K1ABC", Dest: "ALL"},
+		},
+		{
+			name:     "suffix kept",
+			data:     ysfdHeader("KF8S-DAVE ", "N8ZA/CHUCK", "          "),
+			expected: DataFields{Gateway: "KF8S-DAVE", Source: "N8ZA/CHUCK"},
+		},
+		{
+			name:      "NUL terminated with stale bytes",
+			data:      ysfdHeader("W1AW      ", "K1ABC\x00N0CA", "ALL       "),
+			expected:  DataFields{Gateway: "W1AW", Source: "K1ABC", Dest: "ALL"},
+			strictErr: true,
+		},
+		{
+			name:      "right aligned",
+			data:      ysfdHeader("      W1AW", "K1ABC     ", "ALL       "),
+			expected:  DataFields{Gateway: "W1AW", Source: "K1ABC", Dest: "ALL"},
+			strictErr: true,
+		},
+		{
+			name:      "control byte",
+			data:      ysfdHeader("W1AW      ", "K1\x01ABC    ", "ALL       "),
+			expected:  DataFields{Gateway: "W1AW", Source: "K1ABC", Dest: "ALL"},
+			strictErr: true,
+		},
+		{
+			name:      "high bit set",
+			data:      ysfdHeader("W1AW      ", "K1ABC     ", "ALL\xff      "),
+			expected:  DataFields{Gateway: "W1AW", Source: "K1ABC", Dest: "ALL"},
+			strictErr: true,
+		},
+		{
+			name:     "blank source and destination",
+			data:     ysfdHeader("W1AW      ", "          ", "\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"),
+			expected: DataFields{Gateway: "W1AW"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, err := ParseDataFields(tt.data, false)
+			if err != nil {
+				t.Fatalf("tolerant parse: %v", err)
+			}
+			if fields != tt.expected {
+				t.Errorf("tolerant parse = %+v, want %+v", fields, tt.expected)
+			}
+
+			fields, err = ParseDataFields(tt.data, true)
+			if tt.strictErr {
+				if err == nil {
+					t.Errorf("expected strict parse to fail, got %+v", fields)
+				}
+				return
+			}
+			if err != nil || fields != tt.expected {
+				t.Errorf("strict parse = %+v, %v, want %+v", fields, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParsePacketStrictness(t *testing.T) {
+	addr := &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 42000}
+	data := ysfdHeader("W1AW      ", "K1ABC\x00N0CA", "ALL       ")
+
+	packet, err := ParsePacket(data, addr)
+	if err != nil {
+		t.Fatalf("ParsePacket: %v", err)
+	}
+	if packet.Callsign != "W1AW" || packet.SourceCS != "K1ABC" || packet.DestCS != "ALL" {
+		t.Errorf("unexpected callsigns %q %q %q", packet.Callsign, packet.SourceCS, packet.DestCS)
+	}
+
+	if _, err := ParsePacketStrict(data, addr); err == nil {
+		t.Errorf("expected the strict parser to reject the packet")
+	}
+
+	// The gateway field of other packet types is always read tolerantly
+	poll := make([]byte, PollPacketSize)
+	copy(poll, "YSFPW1AW\x00GARBA")
+	if packet, err := ParsePacketStrict(poll, addr); err != nil || packet.Callsign != "W1AW" {
+		t.Errorf("unexpected poll parse: %+v %v", packet, err)
+	}
+}
@@ -0,0 +1,141 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Mux dispatches received packets to the components sharing the server.
+//
+// Each packet type has at most one owner, whose handler runs last. Claiming
+// a type another component owns is an error rather than silently replacing
+// its handler, so the outcome never depends on registration order. Taps
+// subscribe to packet types with a priority and see a packet before its
+// owner, highest priority first (ties in subscription order). A tap that
+// consumes a packet stops the dispatch: lower taps and the owner never see it.
+type Mux struct {
+	mu     sync.RWMutex
+	owners map[string]muxOwner
+	taps   []*muxTap
+	seq    uint64
+}
+
+// Tap sees a packet before the owner of its type. It returns true to consume
+// the packet.
+type Tap func(packet *Packet) bool
+
+// ErrPacketTypeOwned is returned when claiming a packet type another component owns
+var ErrPacketTypeOwned = errors.New("packet type already owned")
+
+// muxOwner is the component handling a packet type
+type muxOwner struct {
+	name    string
+	handler PacketHandler
+}
+
+// muxTap is a tap subscription; an empty types set matches every type
+type muxTap struct {
+	name     string
+	priority int
+	types    map[string]bool
+	tap      Tap
+	seq      uint64
+}
+
+// NewMux creates an empty packet multiplexer
+func NewMux() *Mux {
+	return &Mux{owners: make(map[string]muxOwner)}
+}
+
+// Own makes owner the handler of a packet type. An owner may replace its own
+// handler; a type owned by another component is refused with ErrPacketTypeOwned.
+func (m *Mux) Own(owner, packetType string, handler PacketHandler) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if current, ok := m.owners[packetType]; ok && current.name != owner {
+		return fmt.Errorf("%w: %s is owned by %s", ErrPacketTypeOwned, packetType, current.name)
+	}
+	m.owners[packetType] = muxOwner{name: owner, handler: handler}
+	return nil
+}
+
+// Release gives up ownership of a packet type. It reports whether owner held it.
+func (m *Mux) Release(owner, packetType string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if current, ok := m.owners[packetType]; !ok || current.name != owner {
+		return false
+	}
+	delete(m.owners, packetType)
+	return true
+}
+
+// Owner returns the owner of a packet type, or "" if it has none
+func (m *Mux) Owner(packetType string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.owners[packetType].name
+}
+
+// Subscribe adds a tap for the given packet types (none = all types). The
+// returned function removes it.
+func (m *Mux) Subscribe(name string, priority int, types []string, tap Tap) func() {
+	sub := &muxTap{name: name, priority: priority, tap: tap}
+	if len(types) > 0 {
+		sub.types = make(map[string]bool, len(types))
+		for _, packetType := range types {
+			sub.types[packetType] = true
+		}
+	}
+
+	m.mu.Lock()
+	m.seq++
+	sub.seq = m.seq
+	m.taps = append(m.taps, sub)
+	sort.Slice(m.taps, func(i, j int) bool {
+		if m.taps[i].priority != m.taps[j].priority {
+			return m.taps[i].priority > m.taps[j].priority
+		}
+		return m.taps[i].seq < m.taps[j].seq
+	})
+	m.mu.Unlock()
+
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for i, t := range m.taps {
+			if t == sub {
+				m.taps = append(m.taps[:i:i], m.taps[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Dispatch runs the taps and then the owner of the packet's type. It reports
+// whether anything handled the packet, and returns the owner's error.
+func (m *Mux) Dispatch(packet *Packet) (bool, error) {
+	m.mu.RLock()
+	var taps []Tap
+	for _, t := range m.taps {
+		if t.types == nil || t.types[packet.Type] {
+			taps = append(taps, t.tap)
+		}
+	}
+	owner, owned := m.owners[packet.Type]
+	m.mu.RUnlock()
+
+	for _, tap := range taps {
+		if tap(packet) {
+			return true, nil
+		}
+	}
+	if !owned {
+		return len(taps) > 0, nil
+	}
+	return true, owner.handler(packet)
+}
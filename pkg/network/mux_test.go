@@ -0,0 +1,70 @@
+package network
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMuxOwnership(t *testing.T) {
+	m := NewMux()
+	handled := ""
+	if err := m.Own("reflector", PacketTypePoll, func(*Packet) error { handled = "reflector"; return nil }); err != nil {
+		t.Fatalf("Own: %v", err)
+	}
+	// Another component cannot take the type over
+	err := m.Own("plugin", PacketTypePoll, func(*Packet) error { handled = "plugin"; return nil })
+	if !errors.Is(err, ErrPacketTypeOwned) {
+		t.Fatalf("expected ErrPacketTypeOwned, got %v", err)
+	}
+	// The owner may replace its own handler
+	if err := m.Own("reflector", PacketTypePoll, func(*Packet) error { handled = "reflector v2"; return nil }); err != nil {
+		t.Fatalf("Own by the same owner: %v", err)
+	}
+
+	if ok, err := m.Dispatch(&Packet{Type: PacketTypePoll}); !ok || err != nil || handled != "reflector v2" {
+		t.Errorf("Dispatch = %v, %v; handled by %q", ok, err, handled)
+	}
+	if ok, _ := m.Dispatch(&Packet{Type: PacketTypeStatus}); ok {
+		t.Error("expected an unowned type to go unhandled")
+	}
+
+	if m.Release("plugin", PacketTypePoll) || !m.Release("reflector", PacketTypePoll) || m.Owner(PacketTypePoll) != "" {
+		t.Error("only the owner may release a type")
+	}
+	if err := m.Own("plugin", PacketTypePoll, func(*Packet) error { return nil }); err != nil {
+		t.Errorf("expected a released type to be claimable, got %v", err)
+	}
+}
+
+func TestMuxTapPriority(t *testing.T) {
+	m := NewMux()
+	var order []string
+	_ = m.Own("reflector", PacketTypeData, func(*Packet) error { order = append(order, "owner"); return nil })
+
+	tap := func(name string, consume bool) Tap {
+		return func(*Packet) bool { order = append(order, name); return consume }
+	}
+	m.Subscribe("low", 0, nil, tap("low", false))
+	m.Subscribe("bridges", 100, []string{PacketTypeData}, tap("bridges", false))
+	m.Subscribe("poll-only", 200, []string{PacketTypePoll}, tap("poll-only", true))
+	m.Subscribe("low-2", 0, nil, tap("low-2", false))
+
+	_, _ = m.Dispatch(&Packet{Type: PacketTypeData})
+	if got := strings.Join(order, ","); got != "bridges,low,low-2,owner" {
+		t.Errorf("dispatch order = %s", got)
+	}
+
+	// A consuming tap stops the dispatch; unsubscribing removes it
+	order = nil
+	cancel := m.Subscribe("consumer", 50, nil, tap("consumer", true))
+	if ok, _ := m.Dispatch(&Packet{Type: PacketTypeData}); !ok || strings.Join(order, ",") != "bridges,consumer" {
+		t.Errorf("dispatch with consumer = %v, %s", ok, strings.Join(order, ","))
+	}
+	cancel()
+	order = nil
+	_, _ = m.Dispatch(&Packet{Type: PacketTypeData})
+	if got := strings.Join(order, ","); got != "bridges,low,low-2,owner" {
+		t.Errorf("dispatch after unsubscribe = %s", got)
+	}
+}
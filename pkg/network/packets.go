@@ -10,12 +10,13 @@ import (
 
 // YSF packet types
 const (
-	PacketTypePoll   = "YSFP"
-	PacketTypeData   = "YSFD"
-	PacketTypeUnlink = "YSFU"
-	PacketTypeStatus = "YSFS"
-	PacketTypeOption = "YSFO"
-	PacketTypeInfo   = "YSFI"
+	PacketTypePoll    = "YSFP"
+	PacketTypeData    = "YSFD"
+	PacketTypeUnlink  = "YSFU"
+	PacketTypeStatus  = "YSFS"
+	PacketTypeOption  = "YSFO"
+	PacketTypeInfo    = "YSFI"
+	PacketTypeVersion = "YSFV"
 )
 
 // Packet sizes
@@ -23,6 +24,9 @@ const (
 	DataPacketSize   = 155
 	PollPacketSize   = 14
 	StatusPacketSize = 42
+	// MaxPollExtension is the most bytes a poll may carry after the gateway
+	// callsign, e.g. a room password
+	MaxPollExtension = 64
 )
 
 // Packet represents a YSF network packet
@@ -34,6 +38,16 @@ type Packet struct {
 	Callsign  string // Gateway/repeater callsign (bytes 4-14)
 	SourceCS  string // Source callsign for data packets (bytes 14-24)
 	DestCS    string // Destination callsign for data packets (bytes 24-34)
+
+	// fich caches the decoded FICH, which takes a Golay and Viterbi pass and
+	// is needed several times per frame
+	fich *decodedFICH
+}
+
+// decodedFICH is the result of decoding a packet's FICH
+type decodedFICH struct {
+	fich FICH
+	ok   bool
 }
 
 // YSFHeader represents the common YSF packet header
@@ -69,8 +83,19 @@ type StatusResponse struct {
 	Count       [3]byte  // Connection count
 }
 
-// ParsePacket parses a raw UDP packet into a structured Packet
+// ParsePacket parses a raw UDP packet into a structured Packet, repairing
+// callsign fields that deviate from the spec (see fields.go)
 func ParsePacket(data []byte, addr *net.UDPAddr) (*Packet, error) {
+	return parsePacket(data, addr, false)
+}
+
+// ParsePacketStrict parses a raw UDP packet like ParsePacket but rejects data
+// packets whose callsign fields deviate from the spec
+func ParsePacketStrict(data []byte, addr *net.UDPAddr) (*Packet, error) {
+	return parsePacket(data, addr, true)
+}
+
+func parsePacket(data []byte, addr *net.UDPAddr, strict bool) (*Packet, error) {
 	if len(data) < 4 {
 		return nil, fmt.Errorf("packet too small: %d bytes", len(data))
 	}
@@ -82,35 +107,24 @@ func ParsePacket(data []byte, addr *net.UDPAddr) (*Packet, error) {
 		Timestamp: time.Now(),
 	}
 
-	// Extract gateway/repeater callsign (bytes 4-14) if present
-	if len(data) >= 14 {
-		callsign := strings.TrimSpace(string(data[4:14]))
-		packet.Callsign = strings.TrimRight(callsign, "\x00")
-	}
-
-	// Extract source and destination callsigns for data packets
-	if packet.Type == PacketTypeData && len(data) >= 34 {
-		// Source callsign (bytes 14-24)
-		sourceCS := strings.TrimSpace(string(data[14:24]))
-		sourceCS = strings.TrimRight(sourceCS, "\x00")
-		// Only set if not all spaces (which means no source callsign)
-		if sourceCS != "" && sourceCS != "          " {
-			packet.SourceCS = sourceCS
-		}
-
-		// Destination callsign (bytes 24-34)
-		destCS := strings.TrimSpace(string(data[24:34]))
-		destCS = strings.TrimRight(destCS, "\x00")
-		// Only set if not all spaces (which means no destination callsign)
-		if destCS != "" && destCS != "          " {
-			packet.DestCS = destCS
+	// Extract the gateway callsign and, for data packets, the source and
+	// destination callsigns; an all-blank field means no callsign
+	if packet.Type == PacketTypeData {
+		fields, err := ParseDataFields(data, strict)
+		if err != nil {
+			return nil, fmt.Errorf("invalid data packet: %w", err)
 		}
+		packet.Callsign = fields.Gateway
+		packet.SourceCS = fields.Source
+		packet.DestCS = fields.Dest
+	} else {
+		packet.Callsign, _ = callsignFieldAt(data, GatewayFieldOffset, false)
 	}
 
 	// Validate packet type and size
 	switch packet.Type {
 	case PacketTypePoll:
-		if len(data) != PollPacketSize {
+		if len(data) < PollPacketSize || len(data) > PollPacketSize+MaxPollExtension {
 			return nil, fmt.Errorf("invalid poll packet size: %d", len(data))
 		}
 	case PacketTypeData:
@@ -126,9 +140,11 @@ func ParsePacket(data []byte, addr *net.UDPAddr) (*Packet, error) {
 		if len(data) < 4 {
 			return nil, fmt.Errorf("invalid status packet size: %d", len(data))
 		}
-	case PacketTypeOption, PacketTypeInfo:
-		// These are typically discarded
-		return nil, fmt.Errorf("unsupported packet type: %s", packet.Type)
+	case PacketTypeOption, PacketTypeInfo, PacketTypeVersion:
+		// Variants sent by some gateways; YSFV may be a bare 4-byte query
+		if packet.Type != PacketTypeVersion && len(data) < PollPacketSize {
+			return nil, fmt.Errorf("invalid %s packet size: %d", packet.Type, len(data))
+		}
 	default:
 		return nil, fmt.Errorf("unknown packet type: %s", packet.Type)
 	}
@@ -151,16 +167,85 @@ func CreatePollResponse() []byte {
 	return packet
 }
 
+// MaxInfoText is the longest text sent in a YSFI info packet
+const MaxInfoText = 128
+
+// CreateInfoPacket creates a YSFI packet carrying an informational text.
+// Characters outside printable ASCII are dropped and the text is cut to
+// MaxInfoText bytes.
+func CreateInfoPacket(text string) []byte {
+	packet := []byte(PacketTypeInfo)
+	for i := 0; i < len(text) && len(packet) < len(PacketTypeInfo)+MaxInfoText; i++ {
+		if c := text[i]; c >= 0x20 && c <= 0x7e {
+			packet = append(packet, c)
+		}
+	}
+	return packet
+}
+
+// CreatePollPacket creates a poll or unlink packet, as sent by a gateway
+func CreatePollPacket(packetType, callsign string) []byte {
+	packet := make([]byte, PollPacketSize)
+	copy(packet[0:4], packetType)
+	copy(packet[4:14], fmt.Sprintf("%-10s", callsign))
+	return packet
+}
+
+// dataFrameCycle is the number of frames in a FICH frame number cycle of
+// the packets built by CreateDataPacket
+const dataFrameCycle = 7
+
+// CreateDataPacket creates frame n of a transmission from source through
+// gateway to ALL. Frame 0 is the header and the last frame the terminator;
+// the frames carry no voice.
+func CreateDataPacket(gateway, source string, n int, last bool) []byte {
+	fi := uint8(FICommunications)
+	switch {
+	case n == 0:
+		fi = FIHeader
+	case last:
+		fi = FITerminator
+	}
+
+	packet := make([]byte, DataPacketSize)
+	copy(packet[0:4], PacketTypeData)
+	copy(packet[GatewayFieldOffset:], fmt.Sprintf("%-10s", gateway))
+	copy(packet[SourceFieldOffset:], fmt.Sprintf("%-10s", source))
+	copy(packet[DestFieldOffset:], fmt.Sprintf("%-10s", "ALL"))
+	packet[FrameCounterOffset] = uint8(n&0x7f) << 1
+	if last {
+		packet[FrameCounterOffset] |= 0x01
+	}
+	EncodeFICH(FICH{FI: fi, FN: uint8(n % dataFrameCycle), FT: dataFrameCycle - 1},
+		packet[FrameOffset:FrameOffset+FrameSize])
+	return packet
+}
+
 // CreateStatusResponse creates a status response packet
 func CreateStatusResponse(name, description string, count int) []byte {
+	return CreateStatusResponseWithID("", name, description, count)
+}
+
+// ReflectorID returns the 5-digit reflector ID: the registered id, or a hash
+// of the name when id is empty
+func ReflectorID(id, name string) string {
+	if id == "" {
+		id = fmt.Sprintf("%05d", simpleHash(name)%100000)
+	}
+	return id
+}
+
+// CreateStatusResponseWithID creates a status response packet announcing the given
+// 5-digit reflector ID, as registered on the YSF host list. An empty ID falls back
+// to a hash of the name.
+func CreateStatusResponseWithID(id, name, description string, count int) []byte {
 	packet := make([]byte, StatusPacketSize)
 
 	// Type
 	copy(packet[0:4], PacketTypeStatus)
 
-	// Hash (5-digit hash based on name) - some implementations expect this to be more specific
-	hash := fmt.Sprintf("%05d", simpleHash(name)%100000)
-	copy(packet[4:9], hash)
+	// ID (5 digits) - registered reflector ID, or a hash based on name
+	copy(packet[4:9], ReflectorID(id, name))
 
 	// Name (16 bytes, space-padded like pYSFReflector)
 	nameBytes := make([]byte, 16)
@@ -185,6 +270,25 @@ func CreateStatusResponse(name, description string, count int) []byte {
 	return packet
 }
 
+// CreateTerminator turns a copy of the last data packet of a transmission into
+// its terminator: the FICH frame indicator and the end-of-stream flag are set,
+// the other FICH fields are kept. It returns nil if data is too short.
+func CreateTerminator(data []byte) []byte {
+	if len(data) < FrameOffset+FrameSize {
+		return nil
+	}
+	packet := make([]byte, len(data))
+	copy(packet, data)
+
+	frame := packet[FrameOffset : FrameOffset+FrameSize]
+	fich, _ := DecodeFICH(frame)
+	fich.FI = FITerminator
+	EncodeFICH(fich, frame)
+	packet[FrameCounterOffset] |= 0x01
+
+	return packet
+}
+
 // IsDataPacket checks if the packet is a data packet
 func (p *Packet) IsDataPacket() bool {
 	return p.Type == PacketTypeData
@@ -214,6 +318,44 @@ func (p *Packet) GetSequence() uint32 {
 	return binary.BigEndian.Uint32(p.Data[14:18])
 }
 
+// FrameCounter returns the 7-bit network frame counter of a data packet (byte 34)
+func (p *Packet) FrameCounter() (uint8, bool) {
+	if !p.IsDataPacket() || len(p.Data) <= FrameCounterOffset {
+		return 0, false
+	}
+	return p.Data[FrameCounterOffset] >> 1, true
+}
+
+// IsEndOfStream reports whether the end-of-transmission flag is set on a data packet
+func (p *Packet) IsEndOfStream() bool {
+	if !p.IsDataPacket() || len(p.Data) <= FrameCounterOffset {
+		return false
+	}
+	return p.Data[FrameCounterOffset]&0x01 == 0x01
+}
+
+// FICH decodes the Frame Information CHannel of a data packet.
+// It returns false if the packet is not a data packet or the FICH CRC fails.
+// The result is decoded once and cached, so Data must not change afterwards.
+func (p *Packet) FICH() (FICH, bool) {
+	if p.fich == nil {
+		p.fich = &decodedFICH{}
+		if p.IsDataPacket() && len(p.Data) >= FrameOffset+FrameSize {
+			p.fich.fich, p.fich.ok = DecodeFICH(p.Data[FrameOffset : FrameOffset+FrameSize])
+		}
+	}
+	return p.fich.fich, p.fich.ok
+}
+
+// IsTerminator reports whether a data packet carries the terminator frame that
+// ends a transmission, going by the FICH with the end-of-stream flag as fallback
+func (p *Packet) IsTerminator() bool {
+	if fich, ok := p.FICH(); ok && fich.FI == FITerminator {
+		return true
+	}
+	return p.IsEndOfStream()
+}
+
 // String returns a string representation of the packet
 func (p *Packet) String() string {
 	if p.SourceCS != "" {
@@ -274,49 +416,17 @@ func SanitizeDataPacket(data []byte) []byte {
 	sanitized := make([]byte, len(data))
 	copy(sanitized, data)
 
-	// Sanitize gateway callsign (bytes 4-14)
-	if len(sanitized) >= 14 {
-		gatewayCS := strings.TrimSpace(string(sanitized[4:14]))
-		gatewayCS = strings.TrimRight(gatewayCS, "\x00")
-		if gatewayCS != "" {
-			cleaned := SanitizeCallsign(gatewayCS)
-			// Pad to 10 bytes with spaces
-			padded := fmt.Sprintf("%-10s", cleaned)
-			if len(padded) > 10 {
-				padded = padded[:10]
-			}
-			copy(sanitized[4:14], padded)
-		}
-	}
-
-	// Sanitize source callsign (bytes 14-24)
-	if len(sanitized) >= 24 {
-		sourceCS := strings.TrimSpace(string(sanitized[14:24]))
-		sourceCS = strings.TrimRight(sourceCS, "\x00")
-		if sourceCS != "" && sourceCS != "          " {
-			cleaned := SanitizeCallsign(sourceCS)
-			// Pad to 10 bytes with spaces
-			padded := fmt.Sprintf("%-10s", cleaned)
-			if len(padded) > 10 {
-				padded = padded[:10]
-			}
-			copy(sanitized[14:24], padded)
-		}
-	}
-
-	// Sanitize destination callsign (bytes 24-34)
-	if len(sanitized) >= 34 {
-		destCS := strings.TrimSpace(string(sanitized[24:34]))
-		destCS = strings.TrimRight(destCS, "\x00")
-		if destCS != "" && destCS != "          " {
-			cleaned := SanitizeCallsign(destCS)
-			// Pad to 10 bytes with spaces
-			padded := fmt.Sprintf("%-10s", cleaned)
-			if len(padded) > 10 {
-				padded = padded[:10]
-			}
-			copy(sanitized[24:34], padded)
+	// Sanitize the gateway, source and destination callsigns; blank fields
+	// are left as they are
+	for _, offset := range []int{GatewayFieldOffset, SourceFieldOffset, DestFieldOffset} {
+		field := sanitized[offset : offset+CallsignFieldSize]
+		callsign, _ := ReadCallsignField(field, false)
+		if callsign == "" {
+			continue
 		}
+		// Pad to 10 bytes with spaces
+		padded := fmt.Sprintf("%-10s", SanitizeCallsign(callsign))
+		copy(field, padded[:CallsignFieldSize])
 	}
 
 	return sanitized
@@ -2,6 +2,7 @@ package network
 
 import (
 	"net"
+	"strings"
 	"testing"
 	"time"
 )
@@ -26,6 +27,12 @@ func TestParsePacket(t *testing.T) {
 			data:      []byte("YSF"),
 			expectErr: true,
 		},
+		{
+			name:       "Poll packet with extension",
+			data:       []byte("YSFPW1ABC     secret"),
+			expectErr:  false,
+			expectType: PacketTypePoll,
+		},
 		{
 			name:      "Invalid poll packet size",
 			data:      []byte("YSFPW1ABC"),
@@ -103,6 +110,65 @@ func TestCreateStatusResponse(t *testing.T) {
 	}
 }
 
+func TestCreateStatusResponseWithID(t *testing.T) {
+	response := CreateStatusResponseWithID("12345", "Test Reflector", "Test Desc", 1)
+	if id := string(response[4:9]); id != "12345" {
+		t.Errorf("Expected reflector ID '12345', got '%s'", id)
+	}
+
+	hashed := CreateStatusResponseWithID("", "Test Reflector", "Test Desc", 1)
+	if string(hashed[4:9]) != string(CreateStatusResponse("Test Reflector", "Test Desc", 1)[4:9]) {
+		t.Errorf("Expected empty ID to fall back to the name hash")
+	}
+}
+
+func TestCreateInfoPacket(t *testing.T) {
+	if got := string(CreateInfoPacket("Welcome\r\n to NEXUS")); got != "YSFIWelcome to NEXUS" {
+		t.Errorf("Expected control characters to be dropped, got %q", got)
+	}
+
+	long := CreateInfoPacket(strings.Repeat("x", 300))
+	if len(long) != len(PacketTypeInfo)+MaxInfoText {
+		t.Errorf("Expected the text to be cut to %d bytes, got %d", MaxInfoText, len(long)-len(PacketTypeInfo))
+	}
+}
+
+func TestCreatePollPacket(t *testing.T) {
+	packet := CreatePollPacket(PacketTypeUnlink, "W1AW")
+	if string(packet) != "YSFUW1AW      " {
+		t.Errorf("Unexpected unlink packet %q", packet)
+	}
+}
+
+func TestCreateDataPacket(t *testing.T) {
+	addr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 42000}
+	for _, tt := range []struct {
+		n    int
+		last bool
+		fi   uint8
+	}{
+		{0, false, FIHeader},
+		{8, false, FICommunications},
+		{9, true, FITerminator},
+	} {
+		data := CreateDataPacket("GW1", "N0CALL", tt.n, tt.last)
+		packet, err := ParsePacket(data, addr)
+		if err != nil {
+			t.Fatalf("frame %d: %v", tt.n, err)
+		}
+		if packet.Callsign != "GW1" || packet.SourceCS != "N0CALL" || packet.DestCS != "ALL" {
+			t.Errorf("frame %d: unexpected callsigns %q %q %q", tt.n, packet.Callsign, packet.SourceCS, packet.DestCS)
+		}
+		fich, ok := DecodeFICH(data[FrameOffset : FrameOffset+FrameSize])
+		if !ok || fich.FI != tt.fi || int(fich.FN) != tt.n%7 || fich.FT != 6 {
+			t.Errorf("frame %d: unexpected FICH %+v", tt.n, fich)
+		}
+		if end := data[FrameCounterOffset]&0x01 == 1; end != tt.last {
+			t.Errorf("frame %d: end of stream = %v", tt.n, end)
+		}
+	}
+}
+
 func TestPacketMethods(t *testing.T) {
 	addr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 42000}
 
@@ -0,0 +1,189 @@
+package network
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// Plugin extends the server without patching the reflector. Plugins are
+// registered at build time, typically from an init function in a file that
+// only exists in a downstream fork:
+//
+//	func init() { network.RegisterPlugin(&myPlugin{}) }
+//
+// A plugin implements Plugin plus any of the optional hook interfaces below.
+type Plugin interface {
+	Name() string
+}
+
+// PacketTypePlugin handles packet types the core server does not parse. The
+// plugin owns those types in the server's Mux; a type another component
+// already owns is refused. Handlers for the core types (YSFP, YSFD, YSFU,
+// YSFS) are owned by the reflector.
+type PacketTypePlugin interface {
+	Plugin
+	PacketHandlers() map[string]PacketHandler
+}
+
+// PreHandleHook sees every packet before its handler runs.
+// Returning false drops the packet.
+type PreHandleHook interface {
+	Plugin
+	BeforeHandle(packet *Packet) bool
+}
+
+// PreBroadcastHook may rewrite data before it is broadcast.
+// Returning nil suppresses the broadcast.
+type PreBroadcastHook interface {
+	Plugin
+	BeforeBroadcast(data []byte, source *net.UDPAddr) []byte
+}
+
+// PostBroadcastHook is told how many destinations received a broadcast
+type PostBroadcastHook interface {
+	Plugin
+	AfterBroadcast(data []byte, source *net.UDPAddr, sent int)
+}
+
+var (
+	pluginsMu sync.Mutex
+	plugins   []Plugin
+)
+
+// RegisterPlugin registers a plugin for every server created afterwards
+func RegisterPlugin(p Plugin) {
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+	plugins = append(plugins, p)
+}
+
+// RegisteredPlugins returns the plugins registered with RegisterPlugin
+func RegisteredPlugins() []Plugin {
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+	return append([]Plugin(nil), plugins...)
+}
+
+// AddPlugin attaches a plugin to this server and registers its packet handlers
+func (s *Server) AddPlugin(p Plugin) {
+	s.mu.Lock()
+	s.plugins = append(s.plugins, p)
+	s.mu.Unlock()
+
+	if pt, ok := p.(PacketTypePlugin); ok {
+		for packetType, handler := range pt.PacketHandlers() {
+			if err := s.mux.Own(p.Name(), packetType, handler); err != nil {
+				if s.logger != nil {
+					s.logger.Error("Plugin packet handler refused",
+						logger.String("plugin", p.Name()), logger.Error(err))
+				}
+				continue
+			}
+			s.mu.Lock()
+			s.pluginTypes[packetType] = true
+			s.mu.Unlock()
+		}
+	}
+
+	if s.logger != nil {
+		s.logger.Info("Plugin attached", logger.String("plugin", p.Name()))
+	}
+}
+
+// Plugins returns the names of the attached plugins
+func (s *Server) Plugins() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.plugins))
+	for _, p := range s.plugins {
+		names = append(names, p.Name())
+	}
+	return names
+}
+
+// pluginPacket builds a raw packet for a type handled by a plugin, or nil
+// if no plugin claims the type. Core parsing rejects such packets as unknown.
+func (s *Server) pluginPacket(data []byte, addr *net.UDPAddr) *Packet {
+	if len(data) < 4 {
+		return nil
+	}
+
+	s.mu.RLock()
+	claimed := s.pluginTypes[string(data[:4])]
+	s.mu.RUnlock()
+	if !claimed {
+		return nil
+	}
+
+	return &Packet{
+		Type:      string(data[:4]),
+		Data:      data,
+		Source:    addr,
+		Timestamp: time.Now(),
+	}
+}
+
+// attachedPlugins returns a snapshot of the attached plugins
+func (s *Server) attachedPlugins() []Plugin {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.plugins
+}
+
+// runPreHandleHooks reports whether the packet should be handled
+func (s *Server) runPreHandleHooks(packet *Packet) bool {
+	for _, p := range s.attachedPlugins() {
+		hook, ok := p.(PreHandleHook)
+		if !ok {
+			continue
+		}
+		keep := true
+		s.guardPlugin(p, func() { keep = hook.BeforeHandle(packet) })
+		if !keep {
+			return false
+		}
+	}
+	return true
+}
+
+// runPreBroadcastHooks returns the data to broadcast, or nil to suppress it
+func (s *Server) runPreBroadcastHooks(data []byte, source *net.UDPAddr) []byte {
+	for _, p := range s.attachedPlugins() {
+		hook, ok := p.(PreBroadcastHook)
+		if !ok {
+			continue
+		}
+		out := data
+		s.guardPlugin(p, func() { out = hook.BeforeBroadcast(data, source) })
+		if out == nil {
+			return nil
+		}
+		data = out
+	}
+	return data
+}
+
+// runPostBroadcastHooks notifies plugins of a completed broadcast
+func (s *Server) runPostBroadcastHooks(data []byte, source *net.UDPAddr, sent int) {
+	for _, p := range s.attachedPlugins() {
+		if hook, ok := p.(PostBroadcastHook); ok {
+			s.guardPlugin(p, func() { hook.AfterBroadcast(data, source, sent) })
+		}
+	}
+}
+
+// guardPlugin runs fn and recovers from a plugin panic so it cannot take down the server
+func (s *Server) guardPlugin(p Plugin, fn func()) {
+	defer func() {
+		if r := recover(); r != nil && s.logger != nil {
+			s.logger.Error("Plugin panicked",
+				logger.String("plugin", p.Name()),
+				logger.Any("panic", r))
+		}
+	}()
+	fn()
+}
@@ -0,0 +1,128 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+type testPlugin struct {
+	handled   chan *Packet
+	dropType  string
+	rewrite   []byte
+	broadcast int
+}
+
+func (p *testPlugin) Name() string { return "test" }
+
+func (p *testPlugin) PacketHandlers() map[string]PacketHandler {
+	return map[string]PacketHandler{
+		"XTST": func(packet *Packet) error {
+			p.handled <- packet
+			return nil
+		},
+	}
+}
+
+func (p *testPlugin) BeforeHandle(packet *Packet) bool {
+	return packet.Type != p.dropType
+}
+
+func (p *testPlugin) BeforeBroadcast(data []byte, source *net.UDPAddr) []byte {
+	return p.rewrite
+}
+
+func (p *testPlugin) AfterBroadcast(data []byte, source *net.UDPAddr, sent int) {
+	p.broadcast = sent
+}
+
+func TestPluginPacketTypeAndPreHandle(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewServerWithLogger("127.0.0.1", 0, logger.NewTestLogger(&buf))
+	plugin := &testPlugin{handled: make(chan *Packet, 2), dropType: PacketTypePoll}
+	s.AddPlugin(plugin)
+
+	polled := false
+	s.RegisterHandler(PacketTypePoll, func(*Packet) error {
+		polled = true
+		return nil
+	})
+
+	addr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 40000}
+
+	// Unknown to the core parser but claimed by the plugin
+	s.handlePacket([]byte("XTSTpayload"), addr)
+	select {
+	case packet := <-plugin.handled:
+		if string(packet.Data) != "XTSTpayload" {
+			t.Errorf("unexpected packet data: %q", packet.Data)
+		}
+	default:
+		t.Fatalf("expected plugin handler to receive XTST packet")
+	}
+	if got := s.GetMetrics().PacketErrors; got != 0 {
+		t.Errorf("claimed packet type must not count as error, got %d", got)
+	}
+
+	// Dropped by the pre-handle hook
+	poll := make([]byte, PollPacketSize)
+	copy(poll, PacketTypePoll)
+	s.handlePacket(poll, addr)
+	if polled {
+		t.Errorf("expected pre-handle hook to drop the poll")
+	}
+
+	if names := s.Plugins(); len(names) != 1 || names[0] != "test" {
+		t.Errorf("unexpected plugin names: %v", names)
+	}
+}
+
+func TestPluginBroadcastHooks(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewServerWithLogger("127.0.0.1", 43021, logger.NewTestLogger(&buf))
+	plugin := &testPlugin{handled: make(chan *Packet, 1), rewrite: []byte("rewritten")}
+	s.AddPlugin(plugin)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = s.Start(ctx) }()
+	time.Sleep(50 * time.Millisecond)
+
+	listener, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+	dest := listener.LocalAddr().(*net.UDPAddr)
+
+	if err := s.BroadcastData([]byte("original"), []*net.UDPAddr{dest}, nil); err != nil {
+		t.Fatalf("broadcast failed: %v", err)
+	}
+
+	_ = listener.SetReadDeadline(time.Now().Add(time.Second))
+	got := make([]byte, 64)
+	n, _, err := listener.ReadFromUDP(got)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if string(got[:n]) != "rewritten" {
+		t.Errorf("expected rewritten payload, got %q", got[:n])
+	}
+	if plugin.broadcast != 1 {
+		t.Errorf("expected post-broadcast hook to see 1 send, got %d", plugin.broadcast)
+	}
+
+	// A nil rewrite suppresses the broadcast
+	plugin.rewrite = nil
+	plugin.broadcast = -1
+	if err := s.BroadcastData([]byte("original"), []*net.UDPAddr{dest}, nil); err != nil {
+		t.Fatalf("broadcast failed: %v", err)
+	}
+	if plugin.broadcast != -1 {
+		t.Errorf("suppressed broadcast must not run post hooks")
+	}
+}
@@ -0,0 +1,141 @@
+package network
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"time"
+)
+
+// BroadcastRank returns the send rank of a broadcast destination. Lower ranks
+// are sent first; destinations of equal rank keep their order.
+type BroadcastRank func(addr *net.UDPAddr) int
+
+// latencyTTL is how long a destination keeps its latency after its last send
+const latencyTTL = 10 * time.Minute
+
+// latencySmoothing is the weight of a new sample in the smoothed latency
+const latencySmoothing = 0.125
+
+// SendLatency is the delay a destination sees within a broadcast: the time
+// from the start of the broadcast until its packet was written
+type SendLatency struct {
+	Address  string    `json:"address"`
+	Callsign string    `json:"callsign,omitempty"` // Filled in by the reflector
+	Rank     int       `json:"rank"`
+	Sends    uint64    `json:"sends"`
+	Last     float64   `json:"last_ms"`
+	Smoothed float64   `json:"smoothed_ms"`
+	Max      float64   `json:"max_ms"`
+	LastSend time.Time `json:"last_send"`
+}
+
+// broadcastOrder holds the destination ranking and per-destination latency
+type broadcastOrder struct {
+	mu      sync.RWMutex
+	rank    BroadcastRank
+	measure bool
+	latency map[string]*SendLatency
+}
+
+// SetBroadcastRank orders broadcast destinations by rank, e.g. to send to net
+// control first. A nil rank keeps the callers' order.
+func (s *Server) SetBroadcastRank(rank BroadcastRank) {
+	s.order.mu.Lock()
+	defer s.order.mu.Unlock()
+	s.order.rank = rank
+}
+
+// SetSendLatency turns per-destination send latency measurement on or off
+func (s *Server) SetSendLatency(enabled bool) {
+	s.order.mu.Lock()
+	defer s.order.mu.Unlock()
+	s.order.measure = enabled
+	if enabled && s.order.latency == nil {
+		s.order.latency = make(map[string]*SendLatency)
+	}
+	if !enabled {
+		s.order.latency = nil
+	}
+}
+
+// SendLatencies returns the measured destinations by rank, then address.
+// Destinations not sent to for ten minutes are dropped.
+func (s *Server) SendLatencies() []SendLatency {
+	s.order.mu.Lock()
+	defer s.order.mu.Unlock()
+
+	now := time.Now()
+	latencies := make([]SendLatency, 0, len(s.order.latency))
+	for key, l := range s.order.latency {
+		if now.Sub(l.LastSend) > latencyTTL {
+			delete(s.order.latency, key)
+			continue
+		}
+		latencies = append(latencies, *l)
+	}
+	sort.Slice(latencies, func(i, j int) bool {
+		if latencies[i].Rank != latencies[j].Rank {
+			return latencies[i].Rank < latencies[j].Rank
+		}
+		return latencies[i].Address < latencies[j].Address
+	})
+	return latencies
+}
+
+// plan returns the destinations in send order with their ranks, and whether
+// latency is measured
+func (o *broadcastOrder) plan(addresses []*net.UDPAddr) ([]*net.UDPAddr, []int, bool) {
+	o.mu.RLock()
+	rank := o.rank
+	measure := o.measure
+	o.mu.RUnlock()
+
+	if rank == nil {
+		return addresses, nil, measure
+	}
+
+	type ranked struct {
+		addr *net.UDPAddr
+		rank int
+	}
+	order := make([]ranked, len(addresses))
+	for i, addr := range addresses {
+		order[i] = ranked{addr: addr, rank: rank(addr)}
+	}
+	sort.SliceStable(order, func(i, j int) bool { return order[i].rank < order[j].rank })
+
+	sorted := make([]*net.UDPAddr, len(order))
+	ranks := make([]int, len(order))
+	for i, r := range order {
+		sorted[i] = r.addr
+		ranks[i] = r.rank
+	}
+	return sorted, ranks, measure
+}
+
+// observe records the send latency of one destination
+func (o *broadcastOrder) observe(addr *net.UDPAddr, rank int, latency time.Duration, now time.Time) {
+	ms := float64(latency) / float64(time.Millisecond)
+	key := addr.String()
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.latency == nil {
+		return
+	}
+
+	l, ok := o.latency[key]
+	if !ok {
+		l = &SendLatency{Address: key, Smoothed: ms}
+		o.latency[key] = l
+	}
+	l.Rank = rank
+	l.Sends++
+	l.Last = ms
+	l.Smoothed += latencySmoothing * (ms - l.Smoothed)
+	if ms > l.Max {
+		l.Max = ms
+	}
+	l.LastSend = now
+}
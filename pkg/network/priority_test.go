@@ -0,0 +1,70 @@
+package network
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+func TestBroadcastOrderRanksDestinations(t *testing.T) {
+	a := &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 42000}
+	b := &net.UDPAddr{IP: net.ParseIP("192.0.2.2"), Port: 42000}
+	c := &net.UDPAddr{IP: net.ParseIP("192.0.2.3"), Port: 42000}
+	d := &net.UDPAddr{IP: net.ParseIP("192.0.2.4"), Port: 42000}
+
+	var o broadcastOrder
+	if got, ranks, _ := o.plan([]*net.UDPAddr{a, b}); got[0] != a || ranks != nil {
+		t.Fatalf("expected the callers' order without a rank")
+	}
+
+	// c is net control, b a priority station; a and d keep their order
+	o.rank = func(addr *net.UDPAddr) int {
+		switch addr {
+		case c:
+			return 0
+		case b:
+			return 1
+		}
+		return 2
+	}
+	got, ranks, _ := o.plan([]*net.UDPAddr{a, b, c, d})
+	want := []*net.UDPAddr{c, b, a, d}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("unexpected order %v", got)
+		}
+	}
+	if ranks[0] != 0 || ranks[1] != 1 || ranks[3] != 2 {
+		t.Errorf("unexpected ranks %v", ranks)
+	}
+}
+
+func TestSendLatencies(t *testing.T) {
+	s := NewServerWithLogger("127.0.0.1", 0, logger.NewTestLogger(io.Discard))
+	addr := &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 42000}
+	now := time.Now()
+
+	// Nothing is recorded until measurement is enabled
+	s.order.observe(addr, 0, time.Millisecond, now)
+	if len(s.SendLatencies()) != 0 {
+		t.Fatalf("expected no latencies while disabled")
+	}
+
+	s.SetSendLatency(true)
+	s.order.observe(addr, 1, 4*time.Millisecond, now)
+	s.order.observe(addr, 1, 2*time.Millisecond, now)
+	stale := &net.UDPAddr{IP: net.ParseIP("192.0.2.2"), Port: 42000}
+	s.order.observe(stale, 0, time.Millisecond, now.Add(-time.Hour))
+
+	latencies := s.SendLatencies()
+	if len(latencies) != 1 {
+		t.Fatalf("expected the stale destination to be dropped, got %+v", latencies)
+	}
+	l := latencies[0]
+	if l.Sends != 2 || l.Rank != 1 || l.Last != 2 || l.Max != 4 || l.Smoothed != 3.75 {
+		t.Errorf("unexpected latency %+v", l)
+	}
+}
@@ -0,0 +1,85 @@
+package network
+
+import (
+	"sync"
+	"time"
+)
+
+// Per-second traffic rates for live graphs. Each second of the last
+// RateWindow seconds has a bucket in a ring indexed by Unix second; a bucket
+// whose second does not match is stale and reads as zero, so idle periods
+// need no ticker to clear them.
+
+// RateWindow is how many one-second buckets are kept
+const RateWindow = 120
+
+// RateSample holds the traffic of one second
+type RateSample struct {
+	Time       time.Time `json:"time"`
+	PacketsIn  uint64    `json:"packets_in"`
+	PacketsOut uint64    `json:"packets_out"`
+	BytesIn    uint64    `json:"bytes_in"`
+	BytesOut   uint64    `json:"bytes_out"`
+}
+
+// rateBucket is one second of traffic in the ring
+type rateBucket struct {
+	second     int64
+	packetsIn  uint64
+	packetsOut uint64
+	bytesIn    uint64
+	bytesOut   uint64
+}
+
+// rateRing is a ring of per-second buckets
+type rateRing struct {
+	mu      sync.Mutex
+	buckets [RateWindow]rateBucket
+}
+
+// record adds a packet of size bytes to the bucket for now
+func (r *rateRing) record(now time.Time, received bool, size int) {
+	second := now.Unix()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b := &r.buckets[second%RateWindow]
+	if b.second != second {
+		*b = rateBucket{second: second}
+	}
+	if received {
+		b.packetsIn++
+		b.bytesIn += uint64(size)
+	} else {
+		b.packetsOut++
+		b.bytesOut += uint64(size)
+	}
+}
+
+// samples returns the RateWindow complete seconds before now, oldest first
+func (r *rateRing) samples(now time.Time) []RateSample {
+	last := now.Unix() - 1
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	samples := make([]RateSample, RateWindow)
+	for i := range samples {
+		second := last - int64(RateWindow-1-i)
+		samples[i].Time = time.Unix(second, 0).UTC()
+		if b := r.buckets[second%RateWindow]; b.second == second {
+			samples[i].PacketsIn = b.packetsIn
+			samples[i].PacketsOut = b.packetsOut
+			samples[i].BytesIn = b.bytesIn
+			samples[i].BytesOut = b.bytesOut
+		}
+	}
+	return samples
+}
+
+// PacketRates returns per-second packet and byte counts for the last
+// RateWindow complete seconds, oldest first
+func (s *Server) PacketRates() []RateSample {
+	return s.rates.samples(time.Now())
+}
@@ -0,0 +1,39 @@
+package network
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateRingSamples(t *testing.T) {
+	var ring rateRing
+	now := time.Unix(1_000_000, 0)
+
+	ring.record(now.Add(-3*time.Second), true, 155)
+	ring.record(now.Add(-3*time.Second), true, 155)
+	ring.record(now.Add(-3*time.Second), false, 14)
+	ring.record(now.Add(-time.Second), false, 155)
+	// The current second is incomplete and not reported yet
+	ring.record(now, true, 155)
+
+	samples := ring.samples(now)
+	if len(samples) != RateWindow {
+		t.Fatalf("expected %d samples, got %d", RateWindow, len(samples))
+	}
+	if last := samples[RateWindow-1]; !last.Time.Equal(now.Add(-time.Second)) || last.PacketsOut != 1 || last.PacketsIn != 0 {
+		t.Errorf("unexpected last sample: %+v", last)
+	}
+	if s := samples[RateWindow-3]; s.PacketsIn != 2 || s.BytesIn != 310 || s.PacketsOut != 1 || s.BytesOut != 14 {
+		t.Errorf("unexpected sample three seconds ago: %+v", s)
+	}
+
+	// A bucket reused after a full window only holds the new second
+	later := now.Add(RateWindow * time.Second)
+	ring.record(later.Add(-3*time.Second), true, 155)
+	if s := ring.samples(later)[RateWindow-3]; s.PacketsIn != 1 || s.PacketsOut != 0 {
+		t.Errorf("expected a stale bucket to be reset, got %+v", s)
+	}
+	if s := ring.samples(later)[RateWindow-1]; s.PacketsOut != 0 {
+		t.Errorf("expected stale buckets to read as zero, got %+v", s)
+	}
+}
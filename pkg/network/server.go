@@ -2,8 +2,11 @@ package network
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -16,26 +19,73 @@ type PacketHandler func(*Packet) error
 
 // Server represents the UDP server for YSF communication
 type Server struct {
-	host     string
-	port     int
-	conn     *net.UDPConn
-	handlers map[string]PacketHandler
-	metrics  *Metrics
-	debug    bool
-	mu       sync.RWMutex
-	running  bool
-	logger   *logger.Logger
+	host    string
+	port    int
+	conn    *net.UDPConn
+	mux     *Mux
+	metrics *Metrics
+	debug   bool
+	mu      sync.RWMutex
+	running bool
+	logger  *logger.Logger
+
+	// plugins are build-time extensions; pluginTypes lists packet types they claim
+	plugins     []Plugin
+	pluginTypes map[string]bool
+
+	// listen overrides host:port with one or more addresses; conns holds a
+	// socket per address and routes the socket each peer last arrived on
+	listen []string
+	conns  []*net.UDPConn
+	routes sync.Map // map[string]route
+
+	// ready is closed once Start has bound every socket; Stop replaces it for the next run
+	ready chan struct{}
+
+	// variants counts packet variants and unrecognized YSF* types
+	variants variantCounters
+
+	// rates holds per-second traffic for the last RateWindow seconds
+	rates rateRing
+
+	// order ranks broadcast destinations and measures their send latency
+	order broadcastOrder
+
+	// strictFields rejects data packets with malformed callsign fields
+	// instead of repairing them (see fields.go)
+	strictFields bool
+
+	// tracer follows individual peer addresses (see trace.go)
+	tracer packetTracer
+
+	// onRestart is told about sockets recreated after a fatal error (see sockerr.go)
+	onRestart func(ListenerRestart)
+
+	// listenFunc binds the socket for a listen address; nil uses listenUDP
+	listenFunc func(address string) (*net.UDPConn, error)
 }
 
+// route is the socket a peer was last heard on
+type route struct {
+	conn *net.UDPConn
+	seen time.Time
+}
+
+// routeTTL is how long a peer keeps its socket after it was last heard
+const routeTTL = 10 * time.Minute
+
 // Metrics holds server metrics
 type Metrics struct {
-	PacketsReceived map[string]int64
-	PacketsSent     map[string]int64
-	BytesReceived   int64
-	BytesSent       int64
-	Connections     int64
-	Uptime          time.Time
-	mu              sync.RWMutex
+	PacketsReceived  map[string]int64
+	PacketsSent      map[string]int64
+	BytesReceived    int64
+	BytesSent        int64
+	PacketErrors     int64 // Packets that failed to parse or whose handler returned an error
+	SocketErrors     int64 // Failed socket reads and writes
+	ListenerRestarts int64 // Sockets recreated after a fatal error
+	Connections      int64
+	Uptime           time.Time
+	mu               sync.RWMutex
 }
 
 // NewServer creates a new UDP server
@@ -47,24 +97,89 @@ func NewServer(host string, port int) *Server {
 // NewServerWithLogger creates a new UDP server and attaches the provided logger.
 func NewServerWithLogger(host string, port int, log *logger.Logger) *Server {
 	s := &Server{
-		host:     host,
-		port:     port,
-		handlers: make(map[string]PacketHandler),
+		host: host,
+		port: port,
+		mux:  NewMux(),
 		metrics: &Metrics{
 			PacketsReceived: make(map[string]int64),
 			PacketsSent:     make(map[string]int64),
 			Uptime:          time.Now(),
 		},
-		logger: log.WithComponent("network"),
+		logger:      log.WithComponent("network"),
+		pluginTypes: make(map[string]bool),
+		ready:       make(chan struct{}),
+	}
+	for _, p := range RegisteredPlugins() {
+		s.AddPlugin(p)
 	}
 	return s
 }
 
-// RegisterHandler registers a packet handler for a specific packet type
+// defaultOwner owns the packet types registered with RegisterHandler
+const defaultOwner = "server"
+
+// Mux returns the multiplexer that dispatches received packets
+func (s *Server) Mux() *Mux {
+	return s.mux
+}
+
+// RegisterHandler makes the server's default owner handle a packet type. A
+// type owned by another component, e.g. a plugin, keeps its owner and the
+// conflict is logged. Components sharing the server should use Mux().Own,
+// which names the owner and returns the conflict.
 func (s *Server) RegisterHandler(packetType string, handler PacketHandler) {
+	if err := s.mux.Own(defaultOwner, packetType, handler); err != nil && s.logger != nil {
+		s.logger.Error("Failed to register packet handler", logger.Error(err))
+	}
+}
+
+// SetListenAddresses binds one socket per host:port address instead of the
+// single host and port. Must be called before Start.
+func (s *Server) SetListenAddresses(addrs []string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.handlers[packetType] = handler
+	s.listen = addrs
+}
+
+// SetListenFunc replaces how the socket for each listen address is bound,
+// e.g. to take over a socket inherited from a previous process. Must be
+// called before Start.
+func (s *Server) SetListenFunc(fn func(address string) (*net.UDPConn, error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listenFunc = fn
+}
+
+// Files returns duplicates of the bound sockets' file descriptors, in listen
+// order, for handing them to another process. The caller closes them.
+func (s *Server) Files() ([]*os.File, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	files := make([]*os.File, 0, len(s.conns))
+	for _, conn := range s.conns {
+		f, err := conn.File()
+		if err != nil {
+			for _, opened := range files {
+				_ = opened.Close()
+			}
+			return nil, fmt.Errorf("failed to duplicate socket %s: %w", conn.LocalAddr(), err)
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// GetListenAddresses returns the local addresses of the bound sockets
+func (s *Server) GetListenAddresses() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	addrs := make([]string, 0, len(s.conns))
+	for _, conn := range s.conns {
+		addrs = append(addrs, conn.LocalAddr().String())
+	}
+	return addrs
 }
 
 // SetDebug enables or disables debug logging
@@ -72,28 +187,55 @@ func (s *Server) SetDebug(debug bool) {
 	s.debug = debug
 }
 
+// SetStrictFields sets whether data packets with malformed callsign fields
+// are rejected rather than repaired. Call before Start.
+func (s *Server) SetStrictFields(strict bool) {
+	s.strictFields = strict
+}
+
 // Start starts the UDP server
 func (s *Server) Start(ctx context.Context) error {
-	addr, err := net.ResolveUDPAddr("udp", fmt.Sprintf("%s:%d", s.host, s.port))
-	if err != nil {
-		return fmt.Errorf("failed to resolve UDP address: %w", err)
+	s.mu.RLock()
+	listen := s.listen
+	ready := s.ready
+	bind := s.listenFunc
+	s.mu.RUnlock()
+	if bind == nil {
+		bind = listenUDP
+	}
+	if len(listen) == 0 {
+		listen = []string{net.JoinHostPort(s.host, strconv.Itoa(s.port))}
 	}
 
-	s.conn, err = net.ListenUDP("udp", addr)
-	if err != nil {
-		return fmt.Errorf("failed to start UDP server: %w", err)
+	conns := make([]*net.UDPConn, 0, len(listen))
+	for _, address := range listen {
+		conn, err := bind(address)
+		if err != nil {
+			for _, c := range conns {
+				_ = c.Close()
+			}
+			return err
+		}
+		conns = append(conns, conn)
 	}
 
 	s.mu.Lock()
+	s.conns = conns
+	s.conn = conns[0] // Default socket for peers not heard from yet, e.g. bridges
 	s.running = true
 	s.mu.Unlock()
+	close(ready)
 
-	if s.logger != nil {
-		s.logger.Info("YSF server listening", logger.String("host", s.host), logger.Int("port", s.port))
+	// Start a packet processing goroutine per socket
+	for _, conn := range conns {
+		if s.logger != nil {
+			s.logger.Info("YSF server listening", logger.String("address", conn.LocalAddr().String()))
+		}
+		go s.processPackets(ctx, conn)
+	}
+	if len(conns) > 1 {
+		go s.pruneRoutes(ctx)
 	}
-
-	// Start packet processing goroutine
-	go s.processPackets(ctx)
 
 	// Wait for context cancellation
 	<-ctx.Done()
@@ -101,6 +243,14 @@ func (s *Server) Start(ctx context.Context) error {
 	return s.Stop()
 }
 
+// Ready returns a channel that is closed once the current or next Start has
+// bound its sockets and packets can be sent
+func (s *Server) Ready() <-chan struct{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ready
+}
+
 // Stop stops the UDP server
 func (s *Server) Stop() error {
 	s.mu.Lock()
@@ -111,17 +261,67 @@ func (s *Server) Stop() error {
 	}
 
 	s.running = false
+	s.ready = make(chan struct{})
 
-	if s.conn != nil {
-		return s.conn.Close()
+	var firstErr error
+	for _, conn := range s.conns {
+		// A socket being recreated may already be closed
+		if err := conn.Close(); err != nil && !errors.Is(err, net.ErrClosed) && firstErr == nil {
+			firstErr = err
+		}
 	}
+	return firstErr
+}
 
-	return nil
+// listenUDP binds a UDP socket to a host:port address
+func listenUDP(address string) (*net.UDPConn, error) {
+	addr, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve UDP address %s: %w", address, err)
+	}
+
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start UDP server on %s: %w", address, err)
+	}
+	return conn, nil
+}
+
+// connFor returns the socket a peer was last heard on, or the default socket
+func (s *Server) connFor(addr *net.UDPAddr) *net.UDPConn {
+	if r, ok := s.routes.Load(addr.String()); ok {
+		return r.(route).conn
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.conn
+}
+
+// pruneRoutes forgets peers that have not been heard from for routeTTL
+func (s *Server) pruneRoutes(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.routes.Range(func(key, value interface{}) bool {
+				if now.Sub(value.(route).seen) > routeTTL {
+					s.routes.Delete(key)
+				}
+				return true
+			})
+		}
+	}
 }
 
-// processPackets processes incoming UDP packets
-func (s *Server) processPackets(ctx context.Context) {
+// processPackets processes incoming UDP packets on one socket, recreating
+// the socket if it fails (see sockerr.go)
+func (s *Server) processPackets(ctx context.Context, conn *net.UDPConn) {
 	buffer := make([]byte, 1024)
+	failures := 0
 
 	for {
 		select {
@@ -129,27 +329,58 @@ func (s *Server) processPackets(ctx context.Context) {
 			return
 		default:
 			// Set read timeout to allow periodic context checking
-			if err := s.conn.SetReadDeadline(time.Now().Add(1 * time.Second)); err != nil {
+			if err := conn.SetReadDeadline(time.Now().Add(1 * time.Second)); err != nil {
 				if s.isRunning() && s.logger != nil {
 					s.logger.Warn("SetReadDeadline failed", logger.Error(err))
 				}
 			}
 
-			n, addr, err := s.conn.ReadFromUDP(buffer)
+			n, addr, err := conn.ReadFromUDP(buffer)
 			if err != nil {
 				if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
 					continue // Timeout is expected, continue
 				}
-				if s.isRunning() && s.logger != nil {
-					s.logger.Error("Error reading UDP packet", logger.Error(err))
+				if !s.isRunning() || ctx.Err() != nil {
+					return // Closed by Stop
+				}
+				s.recordSocketError()
+
+				switch class := classifySocketError(err); {
+				case class == socketErrorPacket:
+					if s.debug && s.logger != nil {
+						s.logger.Debug("Skipping failed UDP read", logger.Error(err))
+					}
+				case class == socketErrorFatal || failures+1 >= maxSocketFailures:
+					if conn = s.restartListener(ctx, conn, err); conn == nil {
+						return
+					}
+					failures = 0
+				default:
+					failures++
+					backoff := socketBackoff(failures, minSocketBackoff, maxSocketBackoff)
+					if s.logger != nil {
+						s.logger.Warn("Error reading UDP packet, backing off",
+							logger.Error(err),
+							logger.Int("failures", failures),
+							logger.Duration("backoff", backoff))
+					}
+					if !sleepContext(ctx, backoff) {
+						return
+					}
 				}
 				continue
 			}
+			failures = 0
 
 			// Create packet copy
 			data := make([]byte, n)
 			copy(data, buffer[:n])
 
+			// Reply on the socket the peer used, so it sees the address it sent to
+			if len(s.conns) > 1 {
+				s.routes.Store(addr.String(), route{conn: conn, seen: time.Now()})
+			}
+
 			// Process packet in goroutine to avoid blocking
 			go s.handlePacket(data, addr)
 		}
@@ -160,6 +391,7 @@ func (s *Server) processPackets(ctx context.Context) {
 func (s *Server) handlePacket(data []byte, addr *net.UDPAddr) {
 	// Update metrics
 	s.updateMetrics(data, true)
+	s.tracePacket(TraceRx, data, addr)
 
 	// Determine packet type for logging (use first 4 bytes when available)
 	pktType := ""
@@ -184,8 +416,15 @@ func (s *Server) handlePacket(data []byte, addr *net.UDPAddr) {
 	}
 
 	// Parse packet
-	packet, err := ParsePacket(data, addr)
+	packet, err := parsePacket(data, addr, s.strictFields)
 	if err != nil {
+		packet = s.pluginPacket(data, addr)
+	}
+	if packet == nil {
+		s.recordPacketError()
+		if isYSF && len(data) >= 4 && !isCoreType(pktType) && !IsVariantType(pktType) {
+			s.recordVariant(pktType, false, addr, "")
+		}
 		if s.debug {
 			if s.logger != nil {
 				s.logger.Debug("Failed to parse packet", logger.String("from", addr.String()), logger.Error(err))
@@ -212,20 +451,25 @@ func (s *Server) handlePacket(data []byte, addr *net.UDPAddr) {
 		s.infoRxLog("", packet, nil, 0)
 	}
 
-	// Find handler for packet type
-	s.mu.RLock()
-	handler, exists := s.handlers[packet.Type]
-	s.mu.RUnlock()
+	if IsVariantType(packet.Type) {
+		s.recordVariant(packet.Type, true, addr, packet.Callsign)
+	}
+
+	// Give plugins a chance to drop the packet
+	if !s.runPreHandleHooks(packet) {
+		return
+	}
 
-	if !exists {
+	// Dispatch to the taps and owner of the packet type
+	handled, err := s.mux.Dispatch(packet)
+	if !handled {
 		if s.debug && s.logger != nil {
 			s.logger.Debug("No handler for packet type", logger.String("type", packet.Type))
 		}
 		return
 	}
-
-	// Call handler
-	if err := handler(packet); err != nil {
+	if err != nil {
+		s.recordPacketError()
 		if s.logger != nil {
 			s.logger.Error("Handler error for packet type", logger.String("type", packet.Type), logger.Error(err))
 		}
@@ -238,13 +482,21 @@ func (s *Server) SendPacket(data []byte, addr *net.UDPAddr) error {
 		return fmt.Errorf("server not running")
 	}
 
-	n, err := s.conn.WriteToUDP(data, addr)
+	n, err := s.connFor(addr).WriteToUDP(data, addr)
+	if err != nil && classifySocketError(err) == socketErrorTransient {
+		// A full send buffer (ENOBUFS) usually drains within a moment
+		s.recordSocketError()
+		time.Sleep(sendRetryDelay)
+		n, err = s.connFor(addr).WriteToUDP(data, addr)
+	}
 	if err != nil {
+		s.recordSocketError()
 		return fmt.Errorf("failed to send packet: %w", err)
 	}
 
 	// Update metrics
 	s.updateMetrics(data, false)
+	s.tracePacket(TraceTx, data, addr)
 
 	// YSF TX logging
 	if len(data) >= 3 && string(data[:3]) == "YSF" {
@@ -284,8 +536,16 @@ func (s *Server) BroadcastData(data []byte, addresses []*net.UDPAddr, exclude *n
 		return fmt.Errorf("server not running")
 	}
 
+	data = s.runPreBroadcastHooks(data, exclude)
+	if data == nil {
+		return nil
+	}
+
+	addresses, ranks, measure := s.order.plan(addresses)
+	start := time.Now()
+
 	sent := 0
-	for _, addr := range addresses {
+	for i, addr := range addresses {
 		if exclude != nil && addr.String() == exclude.String() {
 			continue
 		}
@@ -297,12 +557,23 @@ func (s *Server) BroadcastData(data []byte, addresses []*net.UDPAddr, exclude *n
 			continue
 		}
 		sent++
+
+		if measure {
+			rank := 0
+			if ranks != nil {
+				rank = ranks[i]
+			}
+			now := time.Now()
+			s.order.observe(addr, rank, now.Sub(start), now)
+		}
 	}
 
 	if s.debug && s.logger != nil {
 		s.logger.Debug("Broadcast completed", logger.Int("sent", sent))
 	}
 
+	s.runPostBroadcastHooks(data, exclude, sent)
+
 	return nil
 }
 
@@ -313,12 +584,15 @@ func (s *Server) GetMetrics() *Metrics {
 
 	// Create a copy to avoid race conditions
 	metrics := &Metrics{
-		PacketsReceived: make(map[string]int64),
-		PacketsSent:     make(map[string]int64),
-		BytesReceived:   s.metrics.BytesReceived,
-		BytesSent:       s.metrics.BytesSent,
-		Connections:     s.metrics.Connections,
-		Uptime:          s.metrics.Uptime,
+		PacketsReceived:  make(map[string]int64),
+		PacketsSent:      make(map[string]int64),
+		BytesReceived:    s.metrics.BytesReceived,
+		BytesSent:        s.metrics.BytesSent,
+		PacketErrors:     s.metrics.PacketErrors,
+		SocketErrors:     s.metrics.SocketErrors,
+		ListenerRestarts: s.metrics.ListenerRestarts,
+		Connections:      s.metrics.Connections,
+		Uptime:           s.metrics.Uptime,
 	}
 
 	for k, v := range s.metrics.PacketsReceived {
@@ -341,6 +615,8 @@ func (s *Server) isRunning() bool {
 
 // updateMetrics updates server metrics
 func (s *Server) updateMetrics(data []byte, received bool) {
+	s.rates.record(time.Now(), received, len(data))
+
 	s.metrics.mu.Lock()
 	defer s.metrics.mu.Unlock()
 
@@ -356,6 +632,13 @@ func (s *Server) updateMetrics(data []byte, received bool) {
 	}
 }
 
+// recordPacketError counts a packet that could not be processed
+func (s *Server) recordPacketError() {
+	s.metrics.mu.Lock()
+	defer s.metrics.mu.Unlock()
+	s.metrics.PacketErrors++
+}
+
 // infoRxLog emits a concise INFO-level RX log line.
 // If packet is non-nil, it uses fields from the parsed packet (Type, Source, Callsign, Data).
 // Otherwise it falls back to pktType, addr and dataLen provided by the caller.
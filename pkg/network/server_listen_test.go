@@ -0,0 +1,61 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+func TestServerRepliesOnSocketPeerUsed(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewServerWithLogger("127.0.0.1", 0, logger.NewTestLogger(&buf))
+	s.SetListenAddresses([]string{"127.0.0.1:0", "127.0.0.1:0"})
+	s.RegisterHandler(PacketTypePoll, func(p *Packet) error {
+		return s.SendPacket(CreatePollResponse(), p.Source)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = s.Start(ctx) }()
+
+	var addrs []string
+	for i := 0; i < 50 && len(addrs) < 2; i++ {
+		time.Sleep(10 * time.Millisecond)
+		addrs = s.GetListenAddresses()
+	}
+	if len(addrs) != 2 || addrs[0] == addrs[1] {
+		t.Fatalf("expected two distinct sockets, got %v", addrs)
+	}
+
+	// Poll the second socket; the reply must come from it, not the default one
+	remote, err := net.ResolveUDPAddr("udp", addrs[1])
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	client, err := net.DialUDP("udp", nil, remote)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	poll := make([]byte, PollPacketSize)
+	copy(poll, "YSFPW1AW      ")
+	if _, err := client.Write(poll); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	// A connected UDP socket only accepts datagrams from the address it dialled
+	_ = client.SetReadDeadline(time.Now().Add(time.Second))
+	reply := make([]byte, 64)
+	n, err := client.Read(reply)
+	if err != nil {
+		t.Fatalf("no reply from the socket that was polled: %v", err)
+	}
+	if string(reply[:4]) != PacketTypePoll || n != PollPacketSize {
+		t.Errorf("unexpected reply %q", reply[:n])
+	}
+}
@@ -0,0 +1,221 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// Socket errors are sorted into three kinds. Transient errors such as
+// ENOBUFS clear up on their own, so the socket is kept and reads back off
+// briefly. Packet errors concern one datagram only (EMSGSIZE, or the ICMP
+// port unreachable some platforms report on the next read) and are skipped.
+// Fatal errors mean the socket is gone, for example after the interface was
+// removed during a VPS migration: the socket is closed and bound again, with
+// backoff, until it succeeds. A run of transient errors that does not clear
+// is treated as fatal too, so a wedged socket cannot keep the reader in an
+// error loop.
+
+// socketErrorClass is the kind of a socket error
+type socketErrorClass int
+
+const (
+	socketErrorTransient socketErrorClass = iota // Keep the socket, back off
+	socketErrorPacket                            // Skip the datagram
+	socketErrorFatal                             // Recreate the socket
+)
+
+// Socket error handling limits
+const (
+	// maxSocketFailures is the number of consecutive transient errors after
+	// which the socket is recreated
+	maxSocketFailures = 20
+	// minSocketBackoff and maxSocketBackoff bound the wait after a transient
+	// read error; it doubles with each consecutive error
+	minSocketBackoff = 10 * time.Millisecond
+	maxSocketBackoff = 2 * time.Second
+	// maxRebindBackoff bounds the wait between attempts to bind a new socket
+	maxRebindBackoff = 5 * time.Second
+	// sendRetryDelay is the pause before a send that failed transiently is retried
+	sendRetryDelay = 2 * time.Millisecond
+)
+
+// ListenerRestart describes a socket that had to be recreated
+type ListenerRestart struct {
+	Address  string        // Local address of the socket
+	Cause    string        // Error that made the old socket unusable
+	Attempts int           // Binds tried until one succeeded
+	Downtime time.Duration // Time from the failure until the new socket was bound
+}
+
+// SocketStats counts socket failures
+type SocketStats struct {
+	Errors           int64 `json:"errors"`
+	ListenerRestarts int64 `json:"listener_restarts"`
+}
+
+// SocketStats returns the socket error and restart counts
+func (s *Server) SocketStats() SocketStats {
+	s.metrics.mu.RLock()
+	defer s.metrics.mu.RUnlock()
+	return SocketStats{Errors: s.metrics.SocketErrors, ListenerRestarts: s.metrics.ListenerRestarts}
+}
+
+// SetListenerRestartHandler sets the function called after a socket was
+// recreated. It is called from the socket's reader and must not block.
+func (s *Server) SetListenerRestartHandler(handler func(ListenerRestart)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onRestart = handler
+}
+
+// classifySocketError sorts a read or write error
+func classifySocketError(err error) socketErrorClass {
+	switch {
+	case errors.Is(err, net.ErrClosed),
+		errors.Is(err, syscall.EBADF),
+		errors.Is(err, syscall.ENETDOWN),
+		errors.Is(err, syscall.EADDRNOTAVAIL),
+		matchesAny(err, platformFatalErrors):
+		return socketErrorFatal
+	case errors.Is(err, syscall.EMSGSIZE),
+		errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.ECONNRESET),
+		matchesAny(err, platformPacketErrors):
+		return socketErrorPacket
+	}
+	return socketErrorTransient
+}
+
+// matchesAny reports whether err is one of targets
+func matchesAny(err error, targets []error) bool {
+	for _, target := range targets {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// socketBackoff returns the wait after the given number of consecutive
+// failures, doubling from min up to max
+func socketBackoff(failures int, min, max time.Duration) time.Duration {
+	d := min
+	for i := 1; i < failures && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d
+}
+
+// sleepContext waits for d, returning false if ctx is cancelled first
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// recordSocketError counts a read or write error on a socket
+func (s *Server) recordSocketError() {
+	s.metrics.mu.Lock()
+	defer s.metrics.mu.Unlock()
+	s.metrics.SocketErrors++
+}
+
+// restartListener closes a failed socket and binds a new one to the same
+// local address, retrying with backoff. It returns the new socket, or nil
+// if the server stopped or the socket was already replaced.
+func (s *Server) restartListener(ctx context.Context, old *net.UDPConn, cause error) *net.UDPConn {
+	failed := time.Now()
+	local, _ := old.LocalAddr().(*net.UDPAddr)
+	if s.logger != nil {
+		s.logger.Error("UDP listener failed, recreating socket",
+			logger.String("address", old.LocalAddr().String()),
+			logger.Error(cause))
+	}
+	_ = old.Close()
+
+	for attempt := 1; ; attempt++ {
+		conn, err := net.ListenUDP("udp", local)
+		if err == nil {
+			if !s.replaceConn(old, conn) {
+				_ = conn.Close()
+				return nil
+			}
+
+			s.metrics.mu.Lock()
+			s.metrics.ListenerRestarts++
+			s.metrics.mu.Unlock()
+
+			restart := ListenerRestart{
+				Address:  conn.LocalAddr().String(),
+				Cause:    cause.Error(),
+				Attempts: attempt,
+				Downtime: time.Since(failed),
+			}
+			if s.logger != nil {
+				s.logger.Info("UDP listener restarted",
+					logger.String("address", restart.Address),
+					logger.Int("attempts", attempt),
+					logger.Duration("downtime", restart.Downtime))
+			}
+			s.mu.RLock()
+			handler := s.onRestart
+			s.mu.RUnlock()
+			if handler != nil {
+				handler(restart)
+			}
+			return conn
+		}
+
+		if s.logger != nil && attempt == 1 {
+			s.logger.Warn("Failed to bind UDP socket, retrying",
+				logger.String("address", local.String()),
+				logger.Error(err))
+		}
+		if !sleepContext(ctx, socketBackoff(attempt, minSocketBackoff, maxRebindBackoff)) || !s.isRunning() {
+			return nil
+		}
+	}
+}
+
+// replaceConn swaps a recreated socket in for the old one, moving the peers
+// routed to it. It reports false if the server stopped or no longer uses old.
+func (s *Server) replaceConn(old, conn *net.UDPConn) bool {
+	s.mu.Lock()
+	replaced := false
+	if s.running {
+		for i, c := range s.conns {
+			if c == old {
+				s.conns[i] = conn
+				replaced = true
+			}
+		}
+		if replaced && s.conn == old {
+			s.conn = conn
+		}
+	}
+	s.mu.Unlock()
+	if !replaced {
+		return false
+	}
+
+	s.routes.Range(func(key, value interface{}) bool {
+		if r := value.(route); r.conn == old {
+			s.routes.Store(key, route{conn: conn, seen: r.seen})
+		}
+		return true
+	})
+	return true
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package network
+
+// Other platforms report the POSIX errnos checked in classifySocketError
+var (
+	platformPacketErrors []error
+	platformFatalErrors  []error
+)
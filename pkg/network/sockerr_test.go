@@ -0,0 +1,93 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+func TestClassifySocketError(t *testing.T) {
+	wrap := func(err error) error {
+		return &net.OpError{Op: "read", Net: "udp", Err: os.NewSyscallError("recvfrom", err)}
+	}
+	cases := []struct {
+		err  error
+		want socketErrorClass
+	}{
+		{wrap(syscall.ENOBUFS), socketErrorTransient},
+		{wrap(syscall.EMSGSIZE), socketErrorPacket},
+		{wrap(syscall.ECONNREFUSED), socketErrorPacket},
+		{wrap(syscall.ENETDOWN), socketErrorFatal},
+		{fmt.Errorf("read: %w", net.ErrClosed), socketErrorFatal},
+	}
+	for _, c := range cases {
+		if got := classifySocketError(c.err); got != c.want {
+			t.Errorf("classify %v = %d, want %d", c.err, got, c.want)
+		}
+	}
+
+	if d := socketBackoff(1, minSocketBackoff, maxSocketBackoff); d != minSocketBackoff {
+		t.Errorf("first backoff = %s", d)
+	}
+	if d := socketBackoff(50, minSocketBackoff, maxSocketBackoff); d != maxSocketBackoff {
+		t.Errorf("backoff not bounded: %s", d)
+	}
+}
+
+func TestServerRecreatesFailedSocket(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewServerWithLogger("127.0.0.1", 0, logger.NewTestLogger(&buf))
+	s.RegisterHandler(PacketTypePoll, func(p *Packet) error {
+		return s.SendPacket(CreatePollResponse(), p.Source)
+	})
+	restarts := make(chan ListenerRestart, 1)
+	s.SetListenerRestartHandler(func(r ListenerRestart) { restarts <- r })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = s.Start(ctx) }()
+	<-s.Ready()
+	address := s.GetListenAddresses()[0]
+
+	// Close the socket behind the server's back, as a vanished interface would
+	s.mu.RLock()
+	_ = s.conns[0].Close()
+	s.mu.RUnlock()
+
+	select {
+	case r := <-restarts:
+		if r.Address != address || r.Attempts < 1 {
+			t.Errorf("unexpected restart %+v", r)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("socket was not recreated")
+	}
+	if m := s.GetMetrics(); m.ListenerRestarts != 1 || m.SocketErrors < 1 {
+		t.Errorf("unexpected metrics: restarts=%d errors=%d", m.ListenerRestarts, m.SocketErrors)
+	}
+
+	// The new socket answers on the same address
+	remote, _ := net.ResolveUDPAddr("udp", address)
+	client, err := net.DialUDP("udp", nil, remote)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+	poll := make([]byte, PollPacketSize)
+	copy(poll, "YSFPW1AW      ")
+	if _, err := client.Write(poll); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	_ = client.SetReadDeadline(time.Now().Add(time.Second))
+	reply := make([]byte, 64)
+	if _, err := client.Read(reply); err != nil {
+		t.Fatalf("no reply from the recreated socket: %v", err)
+	}
+}
@@ -0,0 +1,20 @@
+//go:build windows
+
+package network
+
+import "syscall"
+
+// Winsock reports socket errors with its own codes rather than the POSIX
+// errnos checked in classifySocketError
+var (
+	platformPacketErrors = []error{
+		syscall.Errno(10040), // WSAEMSGSIZE: datagram larger than the buffer
+		syscall.Errno(10054), // WSAECONNRESET: ICMP port unreachable for an earlier send
+		syscall.Errno(10061), // WSAECONNREFUSED
+	}
+	platformFatalErrors = []error{
+		syscall.Errno(10038), // WSAENOTSOCK
+		syscall.Errno(10049), // WSAEADDRNOTAVAIL
+		syscall.Errno(10050), // WSAENETDOWN
+	}
+)
@@ -0,0 +1,274 @@
+package network
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// A packet trace follows one peer address for a limited time: every packet
+// from or to it is decoded, logged at debug and handed to the trace sink, so
+// a misbehaving gateway can be examined without turning on debug logging for
+// the whole reflector. Expired traces are removed when their address is next
+// seen or the traces are listed.
+
+// MaxTraceDuration is the longest a trace can run
+const MaxTraceDuration = 10 * time.Minute
+
+// Trace directions
+const (
+	TraceRx = "rx"
+	TraceTx = "tx"
+)
+
+// TracedPacket is a packet seen by a trace
+type TracedPacket struct {
+	Time      time.Time              `json:"time"`
+	Direction string                 `json:"direction"` // TraceRx or TraceTx
+	Address   string                 `json:"address"`
+	Type      string                 `json:"type"`
+	Size      int                    `json:"size"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+	Hex       string                 `json:"hex"`
+}
+
+// TraceInfo describes an active trace
+type TraceInfo struct {
+	Address string    `json:"address"`
+	Started time.Time `json:"started"`
+	Expires time.Time `json:"expires"`
+	Packets uint64    `json:"packets"`
+}
+
+// packetTracer holds the active traces
+type packetTracer struct {
+	active atomic.Int32 // Number of traces, checked before taking the lock
+
+	mu     sync.Mutex
+	traces map[string]*TraceInfo
+	sink   func(TracedPacket)
+}
+
+// StartTrace traces the packets from and to addr (host:port) for d. Starting
+// a trace on an address that is already traced restarts its timer.
+func (s *Server) StartTrace(addr string, d time.Duration) (TraceInfo, error) {
+	if d <= 0 || d > MaxTraceDuration {
+		return TraceInfo{}, fmt.Errorf("trace duration must be between 0 and %s", MaxTraceDuration)
+	}
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return TraceInfo{}, fmt.Errorf("invalid address %q: %w", addr, err)
+	}
+	key := udpAddr.String()
+	now := time.Now()
+
+	t := &s.tracer
+	t.mu.Lock()
+	if t.traces == nil {
+		t.traces = make(map[string]*TraceInfo)
+	}
+	info, ok := t.traces[key]
+	if !ok {
+		info = &TraceInfo{Address: key, Started: now}
+		t.traces[key] = info
+		t.active.Add(1)
+	}
+	info.Expires = now.Add(d)
+	started := *info
+	t.mu.Unlock()
+
+	if s.logger != nil {
+		s.logger.Info("Packet trace started",
+			logger.String("address", key),
+			logger.Duration("duration", d))
+	}
+	return started, nil
+}
+
+// StopTrace ends the trace on addr, reporting whether there was one
+func (s *Server) StopTrace(addr string) bool {
+	key := addr
+	if udpAddr, err := net.ResolveUDPAddr("udp", addr); err == nil {
+		key = udpAddr.String()
+	}
+
+	t := &s.tracer
+	t.mu.Lock()
+	info, ok := t.traces[key]
+	if ok {
+		delete(t.traces, key)
+		t.active.Add(-1)
+	}
+	t.mu.Unlock()
+
+	if ok {
+		s.logTraceEnded(*info, "stopped")
+	}
+	return ok
+}
+
+// Traces returns the active traces ordered by address
+func (s *Server) Traces() []TraceInfo {
+	now := time.Now()
+	var ended []TraceInfo
+
+	t := &s.tracer
+	t.mu.Lock()
+	traces := make([]TraceInfo, 0, len(t.traces))
+	for key, info := range t.traces {
+		if !now.Before(info.Expires) {
+			delete(t.traces, key)
+			t.active.Add(-1)
+			ended = append(ended, *info)
+			continue
+		}
+		traces = append(traces, *info)
+	}
+	t.mu.Unlock()
+
+	for _, info := range ended {
+		s.logTraceEnded(info, "expired")
+	}
+	sort.Slice(traces, func(i, j int) bool { return traces[i].Address < traces[j].Address })
+	return traces
+}
+
+// SetTraceSink sets the function traced packets are handed to. It is called
+// on the packet path and must not block.
+func (s *Server) SetTraceSink(sink func(TracedPacket)) {
+	s.tracer.mu.Lock()
+	s.tracer.sink = sink
+	s.tracer.mu.Unlock()
+}
+
+// tracePacket logs a packet and hands it to the sink if its address is traced
+func (s *Server) tracePacket(direction string, data []byte, addr *net.UDPAddr) {
+	t := &s.tracer
+	if t.active.Load() == 0 || addr == nil {
+		return
+	}
+	key := addr.String()
+	now := time.Now()
+
+	t.mu.Lock()
+	info, ok := t.traces[key]
+	var expired *TraceInfo
+	if ok && !now.Before(info.Expires) {
+		delete(t.traces, key)
+		t.active.Add(-1)
+		expired = info
+		ok = false
+	}
+	if ok {
+		info.Packets++
+	}
+	sink := t.sink
+	t.mu.Unlock()
+
+	if expired != nil {
+		s.logTraceEnded(*expired, "expired")
+	}
+	if !ok {
+		return
+	}
+
+	packet := TracedPacket{
+		Time:      now,
+		Direction: direction,
+		Address:   key,
+		Type:      packetTypeOf(data),
+		Size:      len(data),
+		Fields:    DecodeFields(data),
+		Hex:       hex.EncodeToString(data),
+	}
+	if s.logger != nil {
+		s.logger.Debug("Packet trace",
+			logger.String("direction", direction),
+			logger.String("address", key),
+			logger.String("type", packet.Type),
+			logger.Int("size", packet.Size),
+			logger.Any("fields", packet.Fields),
+			logger.String("hexdump", hexdumpSideBySide(data)))
+	}
+	if sink != nil {
+		sink(packet)
+	}
+}
+
+// logTraceEnded logs the end of a trace
+func (s *Server) logTraceEnded(info TraceInfo, reason string) {
+	if s.logger == nil {
+		return
+	}
+	s.logger.Info("Packet trace ended",
+		logger.String("address", info.Address),
+		logger.String("reason", reason),
+		logger.Uint64("packets", info.Packets))
+}
+
+// packetTypeOf returns the type of a raw packet: its first four bytes, or
+// fewer for a runt
+func packetTypeOf(data []byte) string {
+	return strings.ToValidUTF8(string(data[:min(len(data), 4)]), "?")
+}
+
+// DecodeFields decodes the fields of a raw YSF packet for display: callsigns
+// of every packet that carries them, and for data packets the frame counter,
+// end-of-stream flag and FICH. It returns nil for packets it cannot decode.
+func DecodeFields(data []byte) map[string]interface{} {
+	switch packetTypeOf(data) {
+	case PacketTypePoll, PacketTypeUnlink:
+		fields := map[string]interface{}{}
+		if gateway, err := callsignFieldAt(data, GatewayFieldOffset, false); err == nil && gateway != "" {
+			fields["gateway"] = gateway
+		}
+		return fields
+	case PacketTypeStatus:
+		if len(data) < StatusPacketSize {
+			return map[string]interface{}{"request": true}
+		}
+		return map[string]interface{}{
+			"id":          strings.TrimSpace(string(data[4:9])),
+			"name":        strings.TrimSpace(string(data[9:25])),
+			"description": strings.TrimSpace(string(data[25:39])),
+			"count":       strings.TrimSpace(string(data[39:42])),
+		}
+	case PacketTypeData:
+	default:
+		return nil
+	}
+
+	fields := map[string]interface{}{}
+	if callsigns, err := ParseDataFields(data, false); err == nil {
+		fields["gateway"] = callsigns.Gateway
+		fields["source"] = callsigns.Source
+		fields["dest"] = callsigns.Dest
+	}
+	p := &Packet{Type: PacketTypeData, Data: data}
+	if counter, ok := p.FrameCounter(); ok {
+		fields["frame_counter"] = counter
+		fields["end_of_stream"] = p.IsEndOfStream()
+	}
+	if fich, ok := p.FICH(); ok {
+		fields["fich"] = map[string]interface{}{
+			"fi":   fich.FI,
+			"dt":   fich.DT,
+			"fn":   fich.FN,
+			"ft":   fich.FT,
+			"bn":   fich.BN,
+			"bt":   fich.BT,
+			"cm":   fich.CM,
+			"mr":   fich.MR,
+			"dev":  fich.Dev,
+			"dgid": fich.DGID,
+		}
+	}
+	return fields
+}
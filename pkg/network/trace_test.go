@@ -0,0 +1,51 @@
+package network
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+func TestTraceFollowsOneAddress(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewServerWithLogger("127.0.0.1", 0, logger.NewTestLogger(&buf))
+
+	var traced []TracedPacket
+	s.SetTraceSink(func(p TracedPacket) { traced = append(traced, p) })
+
+	if _, err := s.StartTrace("192.0.2.1:42000", MaxTraceDuration+time.Second); err == nil {
+		t.Errorf("expected a trace longer than the maximum to be rejected")
+	}
+	if _, err := s.StartTrace("192.0.2.1:42000", time.Minute); err != nil {
+		t.Fatalf("start trace: %v", err)
+	}
+
+	data := make([]byte, DataPacketSize)
+	copy(data, "YSFDGATEWAY   N0CALL    ALL       ")
+	data[FrameCounterOffset] = 5<<1 | 1
+
+	s.handlePacket(data, &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 42000})
+	s.handlePacket(data, &net.UDPAddr{IP: net.ParseIP("192.0.2.2"), Port: 42000})
+
+	if len(traced) != 1 {
+		t.Fatalf("expected 1 traced packet, got %d", len(traced))
+	}
+	p := traced[0]
+	if p.Direction != TraceRx || p.Type != PacketTypeData || p.Size != DataPacketSize {
+		t.Errorf("unexpected trace %+v", p)
+	}
+	if p.Fields["source"] != "N0CALL" || p.Fields["frame_counter"] != uint8(5) || p.Fields["end_of_stream"] != true {
+		t.Errorf("unexpected fields %v", p.Fields)
+	}
+
+	traces := s.Traces()
+	if len(traces) != 1 || traces[0].Packets != 1 {
+		t.Errorf("unexpected traces %+v", traces)
+	}
+	if !s.StopTrace("192.0.2.1:42000") || len(s.Traces()) != 0 {
+		t.Errorf("expected the trace to stop")
+	}
+}
@@ -0,0 +1,105 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Packet variants. Besides the core YSFP/YSFD/YSFU/YSFS packets, some gateways
+// send YSFV version queries, YSFO options (e.g. DG-ID selection) and YSFI
+// information packets. They are parsed so handlers can act on them, and they
+// are counted per type together with unrecognized YSF* types so operators can
+// see what unusual clients send.
+
+// VariantPacketTypes lists the packet variants ParsePacket accepts
+var VariantPacketTypes = []string{PacketTypeVersion, PacketTypeOption, PacketTypeInfo}
+
+// IsVariantType reports whether packetType is one of VariantPacketTypes
+func IsVariantType(packetType string) bool {
+	for _, t := range VariantPacketTypes {
+		if t == packetType {
+			return true
+		}
+	}
+	return false
+}
+
+// isCoreType reports whether packetType is one of the core YSF packet types
+func isCoreType(packetType string) bool {
+	switch packetType {
+	case PacketTypePoll, PacketTypeData, PacketTypeUnlink, PacketTypeStatus:
+		return true
+	}
+	return false
+}
+
+// VariantStat counts the packets received of one variant or unrecognized type
+type VariantStat struct {
+	Type         string    `json:"type"`
+	Known        bool      `json:"known"` // false for types ParsePacket does not recognize
+	Count        uint64    `json:"count"`
+	LastSource   string    `json:"last_source"`
+	LastCallsign string    `json:"last_callsign,omitempty"`
+	LastSeen     time.Time `json:"last_seen"`
+}
+
+// variantCounters holds the per-type variant counts
+type variantCounters struct {
+	mu     sync.Mutex
+	byType map[string]*VariantStat
+}
+
+// recordVariant counts a variant or unrecognized packet
+func (s *Server) recordVariant(packetType string, known bool, source *net.UDPAddr, callsign string) {
+	s.variants.mu.Lock()
+	defer s.variants.mu.Unlock()
+
+	if s.variants.byType == nil {
+		s.variants.byType = make(map[string]*VariantStat)
+	}
+	stat, ok := s.variants.byType[packetType]
+	if !ok {
+		stat = &VariantStat{Type: packetType, Known: known}
+		s.variants.byType[packetType] = stat
+	}
+	stat.Count++
+	stat.LastSource = source.String()
+	stat.LastCallsign = callsign
+	stat.LastSeen = time.Now()
+}
+
+// VariantStats returns the variant and unrecognized packet counts by type
+func (s *Server) VariantStats() []VariantStat {
+	s.variants.mu.Lock()
+	defer s.variants.mu.Unlock()
+
+	stats := make([]VariantStat, 0, len(s.variants.byType))
+	for _, stat := range s.variants.byType {
+		stats = append(stats, *stat)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Type < stats[j].Type })
+	return stats
+}
+
+// Payload returns the printable text following the callsign of a variant
+// packet, e.g. the options string of a YSFO packet
+func (p *Packet) Payload() string {
+	if len(p.Data) <= PollPacketSize {
+		return ""
+	}
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7E {
+			return -1
+		}
+		return r
+	}, string(p.Data[PollPacketSize:])))
+}
+
+// CreateVersionResponse creates a YSFV reply naming the reflector software
+func CreateVersionResponse(software, version string) []byte {
+	return []byte(fmt.Sprintf("%s%s %s", PacketTypeVersion, software, version))
+}
@@ -0,0 +1,53 @@
+package network
+
+import (
+	"net"
+	"testing"
+)
+
+func TestParseVariantPackets(t *testing.T) {
+	addr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 42000}
+
+	if p, err := ParsePacket([]byte("YSFV"), addr); err != nil || p.Type != PacketTypeVersion {
+		t.Errorf("expected a bare YSFV query to parse, got %v", err)
+	}
+
+	p, err := ParsePacket([]byte("YSFOW1AW      DGID=20\x00"), addr)
+	if err != nil {
+		t.Fatalf("parse YSFO: %v", err)
+	}
+	if p.Callsign != "W1AW" || p.Payload() != "DGID=20" {
+		t.Errorf("unexpected YSFO fields: callsign %q, payload %q", p.Callsign, p.Payload())
+	}
+
+	if _, err := ParsePacket([]byte("YSFIW1"), addr); err == nil {
+		t.Errorf("expected a truncated YSFI packet to fail")
+	}
+}
+
+func TestServerCountsVariantPackets(t *testing.T) {
+	s := NewServer("127.0.0.1", 0)
+	addr := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 10), Port: 42000}
+
+	s.handlePacket([]byte("YSFOW1AW      DGID=20"), addr)
+	s.handlePacket([]byte("YSFOW1AW      DGID=30"), addr)
+	s.handlePacket([]byte("YSFXW1AW      "), addr)
+	s.handlePacket([]byte("YSFP"), addr) // malformed core packet, not a variant
+
+	stats := s.VariantStats()
+	if len(stats) != 2 {
+		t.Fatalf("expected YSFO and YSFX counts, got %+v", stats)
+	}
+	if stats[0].Type != "YSFO" || !stats[0].Known || stats[0].Count != 2 || stats[0].LastCallsign != "W1AW" {
+		t.Errorf("unexpected YSFO stat: %+v", stats[0])
+	}
+	if stats[1].Type != "YSFX" || stats[1].Known || stats[1].Count != 1 || stats[1].LastSource != addr.String() {
+		t.Errorf("unexpected YSFX stat: %+v", stats[1])
+	}
+}
+
+func TestCreateVersionResponse(t *testing.T) {
+	if got := string(CreateVersionResponse("YSF-Nexus", "1.2.3")); got != "YSFVYSF-Nexus 1.2.3" {
+		t.Errorf("unexpected version response %q", got)
+	}
+}
@@ -0,0 +1,250 @@
+// Package ntpcheck compares the system clock against NTP servers. Cross-mode
+// networks misbehave when the reflector's clock drifts: bridge schedules fire
+// at the wrong time and time-based authentication hashes stop matching. The
+// reflector does not set the clock; it only measures the offset with a
+// single SNTP query and warns when it exceeds a threshold.
+package ntpcheck
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+// ntpEpochOffset is the number of seconds from 1900, the NTP epoch, to 1970
+const ntpEpochOffset = 2208988800
+
+// packetSize is the size of an SNTP packet without extensions
+const packetSize = 48
+
+// Status is the result of the last clock check
+type Status struct {
+	Enabled   bool      `json:"enabled"`
+	Checked   bool      `json:"checked"` // A server has answered at least once
+	Server    string    `json:"server,omitempty"`
+	OffsetMS  float64   `json:"offset_ms"` // Positive when the local clock is behind
+	RTTMS     float64   `json:"rtt_ms"`
+	Threshold string    `json:"threshold"`
+	Drifted   bool      `json:"drifted"`
+	LastCheck time.Time `json:"last_check,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Checker periodically measures the clock offset
+type Checker struct {
+	config config.ClockCheckConfig
+	events chan<- repeater.Event
+	logger *logger.Logger
+
+	mu     sync.RWMutex
+	status Status
+}
+
+// New creates a checker. Drift warnings are sent as clock_drift events on
+// events, which may be nil.
+func New(cfg config.ClockCheckConfig, events chan<- repeater.Event, log *logger.Logger) *Checker {
+	return &Checker{
+		config: cfg,
+		events: events,
+		logger: log.WithComponent("ntpcheck"),
+		status: Status{Enabled: true, Threshold: cfg.Threshold.String()},
+	}
+}
+
+// Run checks the clock at once and then on the configured interval until
+// ctx is cancelled
+func (c *Checker) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		c.Check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check queries the servers in order until one answers and records the offset
+func (c *Checker) Check(ctx context.Context) Status {
+	var errs []error
+	for _, server := range c.config.Servers {
+		offset, rtt, err := Query(ctx, server, c.config.Timeout)
+		if ctx.Err() != nil {
+			return c.Status()
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", server, err))
+			continue
+		}
+		return c.record(server, offset, rtt)
+	}
+
+	err := errors.Join(errs...)
+	c.mu.Lock()
+	c.status.LastCheck = time.Now()
+	c.status.Error = err.Error()
+	status := c.status
+	c.mu.Unlock()
+	c.logger.Warn("Clock check failed, no NTP server answered", logger.Error(err))
+	return status
+}
+
+// record stores a measured offset and warns when the clock has drifted
+func (c *Checker) record(server string, offset, rtt time.Duration) Status {
+	drifted := offset.Abs() > c.config.Threshold
+
+	c.mu.Lock()
+	wasDrifted := c.status.Drifted
+	c.status.Checked = true
+	c.status.Server = server
+	c.status.OffsetMS = durationMS(offset)
+	c.status.RTTMS = durationMS(rtt)
+	c.status.Drifted = drifted
+	c.status.LastCheck = time.Now()
+	c.status.Error = ""
+	status := c.status
+	c.mu.Unlock()
+
+	switch {
+	case drifted:
+		c.logger.Warn("System clock has drifted",
+			logger.String("server", server),
+			logger.Duration("offset", offset),
+			logger.Duration("threshold", c.config.Threshold))
+		if !wasDrifted {
+			c.sendDrift(status)
+		}
+	case wasDrifted:
+		c.logger.Info("System clock back in sync",
+			logger.String("server", server),
+			logger.Duration("offset", offset))
+	default:
+		c.logger.Debug("Clock check",
+			logger.String("server", server),
+			logger.Duration("offset", offset),
+			logger.Duration("rtt", rtt))
+	}
+	return status
+}
+
+// sendDrift emits a clock_drift event without blocking
+func (c *Checker) sendDrift(status Status) {
+	if c.events == nil {
+		return
+	}
+	event := repeater.Event{
+		Type:      repeater.EventClockDrift,
+		Timestamp: status.LastCheck,
+		Data: map[string]interface{}{
+			"server":    status.Server,
+			"offset_ms": status.OffsetMS,
+			"threshold": status.Threshold,
+		},
+	}
+	select {
+	case c.events <- event:
+	default:
+		c.logger.Warn("Event channel full, dropping clock drift event")
+	}
+}
+
+// Status returns the result of the last check
+func (c *Checker) Status() Status {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.status
+}
+
+// OffsetMS returns the absolute offset of the last successful check in
+// milliseconds, for alert rules
+func (c *Checker) OffsetMS() float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return math.Abs(c.status.OffsetMS)
+}
+
+// Query sends one SNTP request to server (host or host:port, default port
+// 123) and returns the local clock's offset and the round-trip delay
+func Query(ctx context.Context, server string, timeout time.Duration) (offset, rtt time.Duration, err error) {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "123")
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", server)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() { _ = conn.Close() }()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	req := make([]byte, packetSize)
+	req[0] = 0x23 // LI 0, version 4, mode 3 (client)
+	sent := time.Now()
+	putTimestamp(req[40:], sent)
+	if _, err := conn.Write(req); err != nil {
+		return 0, 0, err
+	}
+
+	resp := make([]byte, packetSize)
+	n, err := conn.Read(resp)
+	received := time.Now()
+	if err != nil {
+		return 0, 0, err
+	}
+	if n < packetSize {
+		return 0, 0, fmt.Errorf("short response of %d bytes", n)
+	}
+	if mode := resp[0] & 0x07; mode != 4 {
+		return 0, 0, fmt.Errorf("unexpected mode %d in response", mode)
+	}
+	if resp[1] == 0 {
+		return 0, 0, fmt.Errorf("server sent kiss code %q", resp[12:16])
+	}
+	if binary.BigEndian.Uint64(resp[24:32]) != binary.BigEndian.Uint64(req[40:48]) {
+		return 0, 0, fmt.Errorf("response does not match request")
+	}
+
+	serverReceived := timestamp(resp[32:40])
+	serverSent := timestamp(resp[40:48])
+	// sent and received carry monotonic readings, so the local interval is exact
+	offset = (serverReceived.Sub(sent) + serverSent.Sub(received)) / 2
+	rtt = received.Sub(sent) - serverSent.Sub(serverReceived)
+	return offset, rtt, nil
+}
+
+// putTimestamp writes t as a 64-bit NTP timestamp
+func putTimestamp(b []byte, t time.Time) {
+	secs := uint64(t.Unix() + ntpEpochOffset)
+	frac := uint64(t.Nanosecond()) << 32 / 1e9
+	binary.BigEndian.PutUint64(b, secs<<32|frac)
+}
+
+// timestamp reads a 64-bit NTP timestamp
+func timestamp(b []byte) time.Time {
+	v := binary.BigEndian.Uint64(b)
+	secs := int64(v>>32) - ntpEpochOffset
+	nanos := int64((v & 0xffffffff) * 1e9 >> 32)
+	return time.Unix(secs, nanos)
+}
+
+// durationMS converts a duration to fractional milliseconds
+func durationMS(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
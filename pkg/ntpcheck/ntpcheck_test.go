@@ -0,0 +1,154 @@
+package ntpcheck
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+// fakeServer answers SNTP requests with its clock shifted by skew
+type fakeServer struct {
+	conn *net.UDPConn
+	skew atomic.Int64
+	kiss atomic.Bool
+}
+
+func newFakeServer(t *testing.T, skew time.Duration) *fakeServer {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeServer{conn: conn}
+	s.skew.Store(int64(skew))
+	t.Cleanup(func() { _ = conn.Close() })
+
+	go func() {
+		buf := make([]byte, 128)
+		for {
+			n, from, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			if n < packetSize {
+				continue
+			}
+			now := time.Now().Add(time.Duration(s.skew.Load()))
+			resp := make([]byte, packetSize)
+			resp[0] = 0x24 // version 4, mode 4 (server)
+			resp[1] = 2    // stratum
+			if s.kiss.Load() {
+				resp[1] = 0
+				copy(resp[12:16], "RATE")
+			}
+			copy(resp[24:32], buf[40:48])
+			putTimestamp(resp[32:], now)
+			putTimestamp(resp[40:], now)
+			_, _ = conn.WriteToUDP(resp, from)
+		}
+	}()
+	return s
+}
+
+func (s *fakeServer) addr() string {
+	return s.conn.LocalAddr().String()
+}
+
+func TestQueryMeasuresOffset(t *testing.T) {
+	server := newFakeServer(t, 5*time.Second)
+
+	offset, rtt, err := Query(context.Background(), server.addr(), time.Second)
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if diff := offset - 5*time.Second; diff.Abs() > 100*time.Millisecond {
+		t.Errorf("offset = %s, want about 5s", offset)
+	}
+	if rtt < 0 || rtt > time.Second {
+		t.Errorf("rtt = %s", rtt)
+	}
+}
+
+func TestQueryRejectsKissCode(t *testing.T) {
+	server := newFakeServer(t, 0)
+	server.kiss.Store(true)
+
+	if _, _, err := Query(context.Background(), server.addr(), time.Second); err == nil {
+		t.Fatal("expected an error for a kiss-o'-death response")
+	}
+}
+
+func TestCheckerWarnsOnDrift(t *testing.T) {
+	server := newFakeServer(t, -3*time.Second)
+	events := make(chan repeater.Event, 10)
+	c := New(config.ClockCheckConfig{
+		Enabled:   true,
+		Servers:   []string{"127.0.0.1:1", server.addr()}, // The first never answers
+		Interval:  time.Hour,
+		Threshold: time.Second,
+		Timeout:   200 * time.Millisecond,
+	}, events, logger.NewTestLogger(io.Discard))
+
+	status := c.Check(context.Background())
+	if !status.Checked || !status.Drifted || status.Server != server.addr() {
+		t.Fatalf("status = %+v, want drifted via the second server", status)
+	}
+	if c.OffsetMS() < 2900 {
+		t.Errorf("OffsetMS = %v, want about 3000", c.OffsetMS())
+	}
+
+	select {
+	case event := <-events:
+		if event.Type != repeater.EventClockDrift {
+			t.Errorf("event type = %s", event.Type)
+		}
+	default:
+		t.Fatal("expected a clock_drift event")
+	}
+
+	// Still drifted: no second event
+	c.Check(context.Background())
+	if len(events) != 0 {
+		t.Error("clock_drift sent again while still drifted")
+	}
+
+	server.skew.Store(0)
+	if status := c.Check(context.Background()); status.Drifted {
+		t.Errorf("status = %+v, want back in sync", status)
+	}
+}
+
+func TestCheckerReportsUnreachableServers(t *testing.T) {
+	c := New(config.ClockCheckConfig{
+		Enabled:   true,
+		Servers:   []string{"127.0.0.1:1"},
+		Interval:  time.Hour,
+		Threshold: time.Second,
+		Timeout:   100 * time.Millisecond,
+	}, nil, logger.NewTestLogger(io.Discard))
+
+	status := c.Check(context.Background())
+	if status.Checked || status.Error == "" {
+		t.Errorf("status = %+v, want an error and no result", status)
+	}
+}
+
+func TestTimestampRoundTrip(t *testing.T) {
+	now := time.Now().Truncate(time.Microsecond)
+	b := make([]byte, 8)
+	putTimestamp(b, now)
+	if got := timestamp(b); got.Sub(now).Abs() > time.Microsecond {
+		t.Errorf("timestamp round trip = %s, want %s", got, now)
+	}
+	if secs := binary.BigEndian.Uint32(b); int64(secs) != now.Unix()+ntpEpochOffset {
+		t.Errorf("seconds = %d", secs)
+	}
+}
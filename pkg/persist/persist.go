@@ -0,0 +1,46 @@
+// Package persist keeps in-memory state on disk: files are replaced
+// atomically and saved periodically while the service runs.
+package persist
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WriteFile writes data to a temporary file next to path and renames it
+// into place, so a crash never leaves a truncated file. pattern names the
+// temporary file as in os.CreateTemp.
+func WriteFile(path, pattern string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), pattern)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Every calls save each interval until ctx is done. It does not save on
+// return; callers that want a final save make it themselves.
+func Every(ctx context.Context, interval time.Duration, save func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			save()
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package reflector
+
+import (
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/network"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+// bridgeFrame builds a bridge data packet with the given frame indicator
+func bridgeFrame(t *testing.T, fi uint8, counter uint8) *network.Packet {
+	t.Helper()
+	data := make([]byte, network.DataPacketSize)
+	copy(data[0:4], network.PacketTypeData)
+	copy(data[4:14], "REMOTE    ")
+	copy(data[14:24], "K1ABC     ")
+	data[network.FrameCounterOffset] = counter << 1
+	network.EncodeFICH(network.FICH{FI: fi}, data[network.FrameOffset:network.FrameOffset+network.FrameSize])
+
+	packet, err := network.ParsePacket(data, &net.UDPAddr{IP: net.ParseIP("192.0.2.10"), Port: 42000})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	return packet
+}
+
+// collectEvents counts the event types queued on the reflector's event channel.
+// Bridge events are enqueued asynchronously, so it waits up to wait for more.
+func collectEvents(r *Reflector, wait time.Duration) map[string]int {
+	counts := make(map[string]int)
+	timeout := time.After(wait)
+	for {
+		select {
+		case ev := <-r.eventChan:
+			counts[ev.Type]++
+		case <-timeout:
+			return counts
+		}
+	}
+}
+
+func TestBridgeTalkerEndsOnTerminator(t *testing.T) {
+	r := New(&config.Config{}, logger.NewTestLogger(os.Stdout))
+
+	r.processBridgeTalker(bridgeFrame(t, network.FIHeader, 0), "Regional")
+	r.processBridgeTalker(bridgeFrame(t, network.FICommunications, 1), "Regional")
+	r.processBridgeTalker(bridgeFrame(t, network.FITerminator, 2), "Regional")
+
+	got := collectEvents(r, 100*time.Millisecond)
+	if got[repeater.EventTalkStart] != 1 || got[repeater.EventTalkEnd] != 1 {
+		t.Fatalf("expected one talk_start and one talk_end, got %v", got)
+	}
+	if r.GetCurrentBridgeTalker() != nil {
+		t.Errorf("expected no current bridge talker after the terminator")
+	}
+
+	// A repeated terminator must not start a new transmission
+	r.processBridgeTalker(bridgeFrame(t, network.FITerminator, 3), "Regional")
+	if got := collectEvents(r, 50*time.Millisecond); len(got) != 0 {
+		t.Errorf("expected no events for a repeated terminator, got %v", got)
+	}
+}
+
+func TestBridgeTalkerTimeoutIsConfigurable(t *testing.T) {
+	cfg := &config.Config{Server: config.ServerConfig{BridgeTalkTimeout: 20 * time.Millisecond}}
+	r := New(cfg, logger.NewTestLogger(os.Stdout))
+
+	r.processBridgeTalker(bridgeFrame(t, network.FICommunications, 1), "Regional")
+	r.checkBridgeTalkerTimeouts()
+	if r.GetCurrentBridgeTalker() == nil {
+		t.Fatalf("talker must not end before the timeout")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	r.checkBridgeTalkerTimeouts()
+	if r.GetCurrentBridgeTalker() != nil {
+		t.Errorf("expected talker to end after the configured timeout")
+	}
+	if got := collectEvents(r, 100*time.Millisecond); got[repeater.EventTalkEnd] != 1 {
+		t.Errorf("expected one talk_end, got %v", got)
+	}
+}
+
+func TestBridgeTalkerReportsLoss(t *testing.T) {
+	r := New(&config.Config{}, logger.NewTestLogger(os.Stdout))
+
+	// Frames 2 and 3 never arrive
+	r.processBridgeTalker(bridgeFrame(t, network.FIHeader, 0), "Regional")
+	r.processBridgeTalker(bridgeFrame(t, network.FICommunications, 1), "Regional")
+	r.processBridgeTalker(bridgeFrame(t, network.FICommunications, 4), "Regional")
+	r.processBridgeTalker(bridgeFrame(t, network.FITerminator, 5), "Regional")
+
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case ev := <-r.eventChan:
+			if ev.Type != repeater.EventTalkEnd {
+				continue
+			}
+			if ev.Quality == nil || ev.Quality.LostFrames != 2 || ev.Quality.ExpectedFrames != 6 || ev.Quality.Missing() != 2 {
+				t.Fatalf("expected 2 of 6 frames lost, got %+v", ev.Quality)
+			}
+			return
+		case <-timeout:
+			t.Fatal("no talk_end event")
+		}
+	}
+}
+
+func TestBridgeTalkerReportsOrigin(t *testing.T) {
+	r := New(&config.Config{}, logger.NewTestLogger(os.Stdout))
+
+	r.processBridgeTalker(bridgeFrame(t, network.FIHeader, 0), "Regional")
+	r.processBridgeTalker(bridgeFrame(t, network.FITerminator, 1), "Regional")
+
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case ev := <-r.eventChan:
+			if ev.Type != repeater.EventTalkEnd {
+				continue
+			}
+			o := ev.Origin
+			if o == nil || o.SourceType != repeater.SourceBridge || o.DGID == nil || *o.DGID != 0 || o.Recipients != 0 {
+				t.Fatalf("unexpected origin %+v", o)
+			}
+			return
+		case <-timeout:
+			t.Fatal("no talk_end event")
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package reflector
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/dmrid"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// ConfigStatus compares the running configuration with the config file on
+// disk. Settings changed at runtime (reloaded bridges, DMR ID overrides set
+// through the API) count as running; file changes that only a restart can
+// apply are flagged.
+func (r *Reflector) ConfigStatus() (config.Status, error) {
+	if r.config.File == "" {
+		return config.Status{}, fmt.Errorf("no config file to compare")
+	}
+
+	file, err := config.LoadFile(r.config.File)
+	if err != nil {
+		return config.Status{}, err
+	}
+	running := r.runningConfig()
+	file.DMRIDs.Overrides = normalizeOverrides(file.DMRIDs.Overrides)
+
+	return config.NewStatus(r.config.File, config.Diff(running, file), time.Now()), nil
+}
+
+// runningConfig returns the configuration in effect, including the settings
+// changed at runtime
+func (r *Reflector) runningConfig() *config.Config {
+	running := *r.config
+
+	r.mu.RLock()
+	if r.reloadedBridges != nil {
+		running.Bridges = r.reloadedBridges
+	}
+	r.mu.RUnlock()
+
+	if r.dmrIDs != nil {
+		running.DMRIDs.Overrides = nil
+		for _, e := range r.dmrIDs.Overrides() {
+			running.DMRIDs.Overrides = append(running.DMRIDs.Overrides,
+				config.DMRIDOverride{Callsign: e.Callsign, ID: e.ID, Name: e.Name})
+		}
+	}
+	return &running
+}
+
+// normalizeOverrides puts DMR ID overrides from the file in the form the
+// directory keeps them, so ordering and callsign case are not reported as
+// changes
+func normalizeOverrides(overrides []config.DMRIDOverride) []config.DMRIDOverride {
+	entries := make([]dmrid.Entry, 0, len(overrides))
+	for _, o := range overrides {
+		entries = append(entries, dmrid.Entry{Callsign: o.Callsign, ID: o.ID, Name: o.Name})
+	}
+	d, err := dmrid.New(entries)
+	if err != nil {
+		return overrides
+	}
+
+	var normalized []config.DMRIDOverride
+	for _, e := range d.Overrides() {
+		normalized = append(normalized, config.DMRIDOverride{Callsign: e.Callsign, ID: e.ID, Name: e.Name})
+	}
+	return normalized
+}
+
+// logPendingRestart warns about config file changes that are not running
+func (r *Reflector) logPendingRestart() {
+	status, err := r.ConfigStatus()
+	if err != nil || !status.PendingRestart {
+		return
+	}
+
+	var keys []string
+	for _, c := range status.Changes {
+		if c.RestartRequired {
+			keys = append(keys, c.Key)
+		}
+	}
+	r.logger.Warn("Config file has changes that need a restart",
+		logger.Any("settings", keys))
+}
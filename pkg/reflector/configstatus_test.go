@@ -0,0 +1,63 @@
+package reflector
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/dmrid"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+func TestConfigStatus(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`
+server:
+  port: 42000
+dmr_ids:
+  overrides:
+    - callsign: w1aw
+      id: 3100001
+    - callsign: K1ABC
+      id: 3100002
+`)
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	r := New(cfg, logger.NewTestLogger(io.Discard))
+
+	status, err := r.ConfigStatus()
+	if err != nil {
+		t.Fatalf("ConfigStatus: %v", err)
+	}
+	if !status.InSync {
+		t.Fatalf("expected the config to be in sync, got %+v", status.Changes)
+	}
+
+	// An override set through the API drifts from the file without a restart
+	if err := r.DMRIDs().Put(dmrid.Entry{Callsign: "N0CALL", ID: 3100003}); err != nil {
+		t.Fatal(err)
+	}
+	status, _ = r.ConfigStatus()
+	if status.InSync || status.PendingRestart || len(status.Changes) != 1 || status.Changes[0].Key != "dmr_ids.overrides" {
+		t.Fatalf("unexpected status after API change %+v", status)
+	}
+
+	// A new UDP port in the file needs a restart
+	write(`
+server:
+  port: 42001
+`)
+	status, _ = r.ConfigStatus()
+	if !status.PendingRestart {
+		t.Errorf("expected a pending restart, got %+v", status)
+	}
+}
@@ -0,0 +1,33 @@
+package reflector
+
+import (
+	"context"
+	"net"
+
+	"github.com/dbehnke/ysf-nexus/pkg/demo"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// runDemo generates demo traffic against the reflector's own YSF socket
+// until ctx is cancelled
+func (r *Reflector) runDemo(ctx context.Context) {
+	target := r.server.GetListenAddress()
+	if target == nil {
+		r.logger.Error("Demo traffic disabled: no YSF listen address")
+		return
+	}
+	// Repeaters on a wildcard address are reached over loopback
+	if target.IP == nil || target.IP.IsUnspecified() {
+		ip := net.IPv4(127, 0, 0, 1)
+		if target.IP != nil && target.IP.To4() == nil {
+			ip = net.IPv6loopback
+		}
+		target = &net.UDPAddr{IP: ip, Port: target.Port}
+	}
+
+	r.logger.Warn("Demo mode enabled: simulated repeaters will link and transmit",
+		logger.Int("repeaters", r.config.Demo.Repeaters))
+	if err := demo.New(r.config.Demo, target, r.logger).Run(ctx); err != nil {
+		r.logger.Error("Demo traffic error", logger.Error(err))
+	}
+}
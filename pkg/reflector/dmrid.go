@@ -0,0 +1,30 @@
+package reflector
+
+import (
+	"github.com/dbehnke/ysf-nexus/pkg/dmrid"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// setupDMRIDs loads the configured DMR ID overrides and hands the directory
+// to the web server
+func (r *Reflector) setupDMRIDs() {
+	overrides := make([]dmrid.Entry, 0, len(r.config.DMRIDs.Overrides))
+	for _, o := range r.config.DMRIDs.Overrides {
+		overrides = append(overrides, dmrid.Entry{Callsign: o.Callsign, ID: o.ID, Name: o.Name})
+	}
+
+	d, err := dmrid.New(overrides)
+	if err != nil {
+		r.logger.Error("Failed to load DMR ID overrides", logger.Error(err))
+		d, _ = dmrid.New(nil)
+	} else if len(overrides) > 0 {
+		r.logger.Info("DMR ID overrides loaded", logger.Int("overrides", len(overrides)))
+	}
+	r.dmrIDs = d
+	r.webServer.SetDMRIDs(d)
+}
+
+// DMRIDs returns the callsign/DMR ID directory
+func (r *Reflector) DMRIDs() *dmrid.Directory {
+	return r.dmrIDs
+}
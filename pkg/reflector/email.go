@@ -0,0 +1,57 @@
+package reflector
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/alerting"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// setupEmail creates the alert emailer and attaches it to the alert manager
+func (r *Reflector) setupEmail() {
+	ec := r.config.Alerting.Email
+	emailer, err := alerting.NewEmailer(alerting.EmailOptions{
+		Host:        ec.Host,
+		Port:        ec.Port,
+		Username:    ec.Username,
+		Password:    ec.Password,
+		From:        ec.From,
+		To:          ec.To,
+		TLS:         ec.TLS,
+		Subject:     ec.Subject,
+		Body:        ec.Body,
+		MinInterval: ec.MinInterval,
+		Reflector:   r.config.Server.Name,
+	}, r.logger)
+	if err != nil {
+		r.logger.Error("Invalid alert email configuration", logger.Error(err))
+		return
+	}
+
+	r.emailer = emailer
+	r.alerts.SetEmailer(emailer)
+}
+
+// notifyLifecycle emails a reflector start or stop when lifecycle emails are
+// enabled. It blocks until the email has been sent.
+func (r *Reflector) notifyLifecycle(event, message string) {
+	if r.emailer == nil || !r.config.Alerting.Email.Lifecycle {
+		return
+	}
+
+	title := "Reflector started"
+	if event == alerting.EmailReflectorStopped {
+		title = "Reflector stopped"
+	}
+
+	err := r.emailer.Notify(event, alerting.EmailData{
+		Event:   event,
+		Title:   title,
+		Message: fmt.Sprintf("%s (version %s)", message, r.version),
+		Time:    time.Now(),
+	})
+	if err != nil {
+		r.logger.Warn("Lifecycle email failed", logger.String("event", event), logger.Error(err))
+	}
+}
@@ -0,0 +1,30 @@
+package reflector
+
+import (
+	"github.com/dbehnke/ysf-nexus/pkg/geoip"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+// setupGeoIP opens the MaxMind databases and applies the country policy to
+// new repeater connections
+func (r *Reflector) setupGeoIP() {
+	gc := r.config.GeoIP
+	reader, err := geoip.Open(gc.CountryDB, gc.ASNDB)
+	if err != nil {
+		r.logger.Error("Failed to open geoip databases", logger.Error(err))
+		return
+	}
+
+	r.repeaterManager.SetGeo(reader.Lookup, repeater.GeoPolicy{
+		AllowCountries:  gc.AllowCountries,
+		DenyCountries:   gc.DenyCountries,
+		AllowUnknown:    gc.AllowUnknown,
+		AlertNewCountry: gc.AlertNewCountry,
+	})
+	r.logger.Info("Geo enrichment enabled",
+		logger.String("country_db", gc.CountryDB),
+		logger.String("asn_db", gc.ASNDB),
+		logger.Int("allow_countries", len(gc.AllowCountries)),
+		logger.Int("deny_countries", len(gc.DenyCountries)))
+}
@@ -0,0 +1,49 @@
+package reflector
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+// setupGroups loads the configured repeater groups
+func (r *Reflector) setupGroups(groups []config.GroupConfig) {
+	list := make([]repeater.Group, 0, len(groups))
+	for _, g := range groups {
+		list = append(list, repeater.Group{Name: g.Name, Callsigns: g.Callsigns, NoBridge: g.NoBridge})
+	}
+	if err := r.repeaterManager.SetGroups(list); err != nil {
+		r.logger.Error("Failed to load repeater groups", logger.Error(err))
+		return
+	}
+	r.logger.Info("Repeater groups loaded", logger.Int("groups", len(list)))
+}
+
+// BroadcastToGroup sends a data packet to the connected members of a group
+// only, e.g. for announcements meant for part of the network
+func (r *Reflector) BroadcastToGroup(data []byte, group string) error {
+	addresses, ok := r.repeaterManager.GroupAddresses(group)
+	if !ok {
+		return fmt.Errorf("unknown group %q", group)
+	}
+	if len(addresses) == 0 {
+		return nil
+	}
+	if err := r.server.BroadcastData(data, addresses, nil); err != nil {
+		return err
+	}
+	for _, addr := range addresses {
+		r.repeaterManager.ProcessTransmit(addr, len(data))
+	}
+	return nil
+}
+
+// bridgeable reports whether traffic from a local repeater may be forwarded
+// to bridges
+func (r *Reflector) bridgeable(addr *net.UDPAddr) bool {
+	rep := r.repeaterManager.GetRepeater(addr)
+	return rep == nil || !r.repeaterManager.IsNoBridge(rep.Callsign())
+}
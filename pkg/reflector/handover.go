@@ -0,0 +1,157 @@
+package reflector
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/handover"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/web"
+)
+
+// A binary upgrade hands the bound sockets to a new process (see package
+// handover). The new process adopts them instead of binding, restores the
+// web sessions and repeaters, and reports ready once it has started. The old
+// process then closes its copies of the sockets first, so every packet from
+// that moment goes to the new process, and shuts down without draining: the
+// stream in flight carries on through the new process, and the bridges'
+// unlinks go nowhere, leaving the remote reflectors linked.
+
+// handoverTimeout bounds how long the old process waits for the new one
+const handoverTimeout = 30 * time.Second
+
+// AdoptHandover makes the reflector take over the sockets and state of the
+// process it replaces. Must be called before Start.
+func (r *Reflector) AdoptHandover(h *handover.Inherited) {
+	if h == nil {
+		return
+	}
+	r.inherited = h
+	r.server.SetListenFunc(h.ListenUDP)
+	r.webServer.SetListenFunc(h.Listen)
+
+	state := h.State()
+	sessions := make(map[string]web.Session, len(state.Sessions))
+	for token, expiry := range state.Sessions {
+		user, ok := state.SessionUsers[token]
+		if !ok {
+			user = handover.SessionUser{Role: config.RoleAdmin}
+		}
+		sessions[token] = web.Session{User: user.User, Role: user.Role, Expiry: expiry}
+	}
+	r.webServer.RestoreSessions(sessions)
+	restored := 0
+	for _, rep := range state.Repeaters {
+		addr, err := net.ResolveUDPAddr("udp", rep.Address)
+		if err != nil {
+			continue
+		}
+		if r.password != nil {
+			// Admitted by the previous process
+			r.password.admit(addr.String(), time.Now())
+		}
+		if _, added := r.repeaterManager.AddRepeater(rep.Callsign, addr); added {
+			restored++
+		}
+	}
+	r.logger.Info("Taking over from previous process",
+		logger.Int("sessions", len(state.Sessions)),
+		logger.Int("repeaters", restored))
+}
+
+// Upgrade starts the current executable again and hands it the sockets and
+// state. It returns once the new process is serving; the caller then stops
+// this reflector. If the new process fails to start, this one keeps serving.
+func (r *Reflector) Upgrade(ctx context.Context) error {
+	r.mu.RLock()
+	running := r.running
+	r.mu.RUnlock()
+	if !running {
+		return fmt.Errorf("reflector not running")
+	}
+
+	udp, tcp, err := r.handoverFiles()
+	if err != nil {
+		return err
+	}
+	defer closeFiles(udp)
+	defer closeFiles(tcp)
+
+	sessions := r.webServer.Sessions()
+	state := handover.State{
+		Sessions:     make(map[string]time.Time, len(sessions)),
+		SessionUsers: make(map[string]handover.SessionUser, len(sessions)),
+	}
+	for token, sess := range sessions {
+		state.Sessions[token] = sess.Expiry
+		state.SessionUsers[token] = handover.SessionUser{User: sess.User, Role: sess.Role}
+	}
+	for _, rep := range r.repeaterManager.GetAllRepeaters() {
+		state.Repeaters = append(state.Repeaters, handover.Repeater{
+			Callsign: rep.Callsign(),
+			Address:  rep.Address().String(),
+		})
+	}
+
+	r.logger.Info("Handing sockets over to new process",
+		logger.Int("udp_sockets", len(udp)),
+		logger.Int("tcp_listeners", len(tcp)),
+		logger.Int("sessions", len(state.Sessions)),
+		logger.Int("repeaters", len(state.Repeaters)))
+
+	pid, err := handover.Upgrade(ctx, udp, tcp, state, handoverTimeout)
+	if err != nil {
+		return fmt.Errorf("handover failed: %w", err)
+	}
+	r.handedOver.Store(true)
+	r.logger.Info("New process is serving, shutting down", logger.Int("pid", pid))
+	return nil
+}
+
+// handoverFiles duplicates the sockets to hand to a new process: the YSF
+// sockets and the SNMP socket, and the web and metrics listeners
+func (r *Reflector) handoverFiles() (udp, tcp []*os.File, err error) {
+	udp, err = r.server.Files()
+	if err != nil {
+		return nil, nil, err
+	}
+	tcp, err = r.webServer.ListenerFiles()
+	if err != nil {
+		closeFiles(udp)
+		return nil, nil, err
+	}
+
+	r.mu.RLock()
+	snmpConn, metricsListener := r.snmpConn, r.metricsListener
+	r.mu.RUnlock()
+	if snmpConn != nil {
+		f, err := snmpConn.File()
+		if err != nil {
+			closeFiles(udp)
+			closeFiles(tcp)
+			return nil, nil, fmt.Errorf("failed to duplicate SNMP socket: %w", err)
+		}
+		udp = append(udp, f)
+	}
+	if tl, ok := metricsListener.(interface{ File() (*os.File, error) }); ok {
+		f, err := tl.File()
+		if err != nil {
+			closeFiles(udp)
+			closeFiles(tcp)
+			return nil, nil, fmt.Errorf("failed to duplicate metrics listener: %w", err)
+		}
+		tcp = append(tcp, f)
+	}
+	return udp, tcp, nil
+}
+
+// closeFiles closes duplicated descriptors once they were handed over
+func closeFiles(files []*os.File) {
+	for _, f := range files {
+		_ = f.Close()
+	}
+}
@@ -0,0 +1,115 @@
+//go:build !windows
+
+package reflector
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/handover"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// freePort returns a TCP port that was free a moment ago
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func TestHandoverKeepsMetricsListener(t *testing.T) {
+	metricsPort := freePort(t)
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Name:              "NEXUS",
+			Host:              "127.0.0.1",
+			Port:              freePort(t),
+			Timeout:           time.Minute,
+			MaxConnections:    10,
+			TalkMaxDuration:   time.Minute,
+			BridgeTalkTimeout: 3 * time.Second,
+		},
+		Metrics: config.MetricsConfig{
+			Enabled:    true,
+			Prometheus: config.PrometheusConfig{Enabled: true, Port: metricsPort, Path: "/metrics"},
+		},
+	}
+
+	// The old process serves metrics, then hands its sockets over
+	old := New(cfg, logger.NewTestLogger(io.Discard))
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		_ = old.Start(ctx)
+	}()
+	t.Cleanup(func() { cancel(); <-stopped })
+	<-old.server.Ready()
+	waitMetrics(t, metricsPort)
+
+	udp, tcp, err := old.handoverFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeFiles(udp)
+	defer closeFiles(tcp)
+	h, err := handover.FromFiles(udp, tcp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old.handedOver.Store(true)
+	cancel()
+	<-stopped
+
+	// The new process adopts the listener instead of failing to bind
+	adopted := New(cfg, logger.NewTestLogger(io.Discard))
+	adopted.AdoptHandover(h)
+	newCtx, newCancel := context.WithCancel(context.Background())
+	newStopped := make(chan struct{})
+	go func() {
+		defer close(newStopped)
+		_ = adopted.Start(newCtx)
+	}()
+	t.Cleanup(func() { newCancel(); <-newStopped })
+	<-adopted.server.Ready()
+	waitMetrics(t, metricsPort)
+
+	adopted.mu.RLock()
+	ln := adopted.metricsListener
+	adopted.mu.RUnlock()
+	if ln == nil {
+		t.Fatal("new process has no metrics listener")
+	}
+}
+
+// waitMetrics waits for the metrics endpoint on port to answer
+func waitMetrics(t *testing.T, port int) {
+	t.Helper()
+	url := fmt.Sprintf("http://127.0.0.1:%d/metrics", port)
+	client := &http.Client{Timeout: time.Second}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := client.Get(url)
+		if err == nil {
+			_ = resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return
+			}
+			err = fmt.Errorf("status %d", resp.StatusCode)
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("metrics not served on port %d: %v", port, err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
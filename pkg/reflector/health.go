@@ -0,0 +1,29 @@
+package reflector
+
+import (
+	"github.com/dbehnke/ysf-nexus/pkg/health"
+)
+
+// setupHealth creates the runtime monitor and registers the internal queues
+// whose backlog it watches
+func (r *Reflector) setupHealth() {
+	hc := r.config.Health
+	m := health.New(health.Options{
+		Interval:       hc.Interval,
+		MaxGoroutines:  hc.MaxGoroutines,
+		MaxHeapMB:      hc.MaxHeapMB,
+		QueueThreshold: hc.QueueThreshold,
+		DumpDir:        hc.HeapDumpDir,
+		DumpCooldown:   hc.HeapDumpCooldown,
+	}, r.logger)
+
+	m.AddQueue("events", func() (int, int) { return len(r.eventChan), cap(r.eventChan) })
+	m.AddQueue("event_subscribers", r.eventBus.Backlog)
+	m.AddQueue("websocket_broadcast", r.webServer.BroadcastBacklog)
+	if r.mirror != nil {
+		m.AddQueue("mirror", r.mirror.Backlog)
+	}
+
+	r.health = m
+	r.webServer.SetRuntimeMonitor(m)
+}
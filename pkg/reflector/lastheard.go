@@ -0,0 +1,171 @@
+package reflector
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/network"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+	"github.com/dbehnke/ysf-nexus/pkg/wiresx"
+)
+
+// The last heard query lets users without dashboard access ask what has been
+// happening from their radio: a Wires-X room search for one of the
+// configured queries (e.g. "LH") is answered with a search result listing
+// the last heard callsigns, sent to the asking repeater only once the
+// channel is free.
+
+// wiresxTapPriority places the Wires-X tap after the bridge tap; it only
+// watches frames and never consumes them
+const wiresxTapPriority = 50
+
+// lastHeardQueryExpiry is how long a partially received command is kept
+const lastHeardQueryExpiry = 10 * time.Second
+
+// heardStation is a callsign in the last heard list
+type heardStation struct {
+	callsign string
+	via      string // Bridge or gateway the transmission came through
+	at       time.Time
+}
+
+// lastHeardQuery holds the last heard list and the commands being received
+type lastHeardQuery struct {
+	queries  map[string]bool // Upper-cased search texts
+	count    int
+	node     wiresx.Node
+	commands *wiresx.Reassembler
+
+	mu    sync.Mutex
+	heard []heardStation // Newest first, at most count+1 so the asker can be left out
+	seq   byte
+}
+
+// setupLastHeardQuery answers Wires-X searches for the configured queries
+func (r *Reflector) setupLastHeardQuery() {
+	lc := r.config.Server.LastHeardQuery
+	queries := make(map[string]bool, len(lc.Queries))
+	for _, query := range lc.Queries {
+		queries[strings.ToUpper(strings.TrimSpace(query))] = true
+	}
+
+	name := strings.ToUpper(r.config.Server.Name)
+	if len(name) > 10 {
+		name = name[:10]
+	}
+	r.lastHeard = &lastHeardQuery{
+		queries: queries,
+		count:   lc.Count,
+		node: wiresx.Node{
+			ID:       network.ReflectorID(r.config.Server.ReflectorID, r.config.Server.Name),
+			Name:     name,
+			Callsign: name,
+		},
+		commands: wiresx.NewReassembler(),
+	}
+	r.server.Mux().Subscribe("wiresx", wiresxTapPriority, []string{network.PacketTypeData}, r.tapWiresX)
+}
+
+// runLastHeardQuery keeps the last heard list from talk_end events and drops
+// stale partial commands until ctx is cancelled
+func (r *Reflector) runLastHeardQuery(ctx context.Context) {
+	sub := r.eventBus.Subscribe(100, time.Time{})
+	defer sub.Close()
+
+	ticker := time.NewTicker(lastHeardQueryExpiry)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			r.lastHeard.commands.Expire(now.Add(-lastHeardQueryExpiry))
+		case event, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if event.Type == repeater.EventTalkEnd {
+				r.lastHeard.record(event)
+			}
+		}
+	}
+}
+
+// record puts the talker of a finished transmission at the top of the list
+func (l *lastHeardQuery) record(event repeater.Event) {
+	callsign := strings.ToUpper(strings.TrimSpace(event.Callsign))
+	if callsign == "" {
+		return
+	}
+	via := event.Bridge
+	if via == "" {
+		via = event.Gateway
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	heard := []heardStation{{callsign: callsign, via: via, at: event.Timestamp}}
+	for _, station := range l.heard {
+		if station.callsign != callsign && len(heard) <= l.count {
+			heard = append(heard, station)
+		}
+	}
+	l.heard = heard
+}
+
+// reply builds the search result for a query from callsign: the last heard
+// stations other than the asker, numbered from 00001
+func (l *lastHeardQuery) reply(callsign string) []byte {
+	callsign = strings.ToUpper(strings.TrimSpace(callsign))
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entries := make([]wiresx.Entry, 0, l.count)
+	for _, station := range l.heard {
+		if station.callsign == callsign || len(entries) == l.count {
+			continue
+		}
+		entries = append(entries, wiresx.Entry{
+			ID:          fmt.Sprintf("%05d", len(entries)+1),
+			Name:        station.callsign,
+			Description: strings.TrimSpace(station.at.Local().Format("15:04") + " " + station.via),
+		})
+	}
+	reply := wiresx.SearchReply(l.seq, l.node, entries)
+	l.seq++
+	return reply
+}
+
+// tapWiresX watches data frames from linked repeaters for last heard queries.
+// The frames are relayed as usual.
+func (r *Reflector) tapWiresX(packet *network.Packet) bool {
+	if len(packet.Data) < network.FrameOffset+network.FrameSize || r.repeaterManager.GetRepeater(packet.Source) == nil {
+		return false
+	}
+	frame := packet.Data[network.FrameOffset : network.FrameOffset+network.FrameSize]
+	cmd, ok := r.lastHeard.commands.Add(packet.Source.String(), frame, time.Now())
+	if !ok || cmd.Type != wiresx.CommandSearch || !r.lastHeard.queries[strings.ToUpper(cmd.Search)] {
+		return false
+	}
+	r.answerLastHeardQuery(packet.SourceCS, packet.Source)
+	return false
+}
+
+// answerLastHeardQuery queues the last heard list for the repeater that asked
+func (r *Reflector) answerLastHeardQuery(callsign string, addr *net.UDPAddr) {
+	l := r.lastHeard
+	frames := wiresx.Frames(l.node, l.reply(callsign))
+	if _, err := r.QueueTransmission(Transmission{Name: "LAST-HEARD", Frames: frames, Address: addr}); err != nil {
+		r.logger.Warn("Failed to queue last heard reply", logger.Error(err))
+		return
+	}
+	r.logger.Info("Last heard query answered",
+		logger.String("callsign", callsign),
+		logger.String("source", addr.String()))
+}
@@ -0,0 +1,160 @@
+package reflector
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/network"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+	"github.com/dbehnke/ysf-nexus/pkg/wiresx"
+)
+
+// searchFrames builds the frames of a Wires-X room search for text sent by callsign
+func searchFrames(callsign, text string) [][]byte {
+	data := append([]byte{0x00, 0x5D, 0x66, 0x5F, 0x26}, fmt.Sprintf("11001%-16s", text)...)
+	data = append(data, 0x03)
+	var sum byte
+	for _, b := range data {
+		sum += b
+	}
+	data = append(data, sum)
+
+	frames := wiresx.Frames(wiresx.Node{ID: "00000", Name: callsign, Callsign: callsign}, data)
+	for _, frame := range frames {
+		copy(frame[network.GatewayFieldOffset:], fmt.Sprintf("%-10s", callsign))
+		copy(frame[network.SourceFieldOffset:], fmt.Sprintf("%-10s", callsign))
+	}
+	return frames
+}
+
+func TestLastHeardQuery(t *testing.T) {
+	free, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	port := free.LocalAddr().(*net.UDPAddr).Port
+	_ = free.Close()
+
+	cfg := &config.Config{Server: config.ServerConfig{
+		Name:                "NEXUS",
+		Host:                "127.0.0.1",
+		Port:                port,
+		Timeout:             time.Minute,
+		MaxConnections:      10,
+		TalkMaxDuration:     time.Minute,
+		BridgeTalkTimeout:   3 * time.Second,
+		TransmitQuietPeriod: 100 * time.Millisecond,
+		LastHeardQuery:      config.LastHeardQueryConfig{Enabled: true, Queries: []string{"lh"}, Count: 2},
+	}}
+	r := New(cfg, logger.NewTestLogger(io.Discard))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = r.Start(ctx) }()
+
+	server := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: port}
+	asker := dialClient(t, server, "W1AW")
+	other := dialClient(t, server, "K8ABC")
+
+	now := time.Now()
+	for i, callsign := range []string{"N0CALL", "W1AW", "KD8XYZ", "K8ABC"} {
+		r.lastHeard.record(repeater.Event{Type: repeater.EventTalkEnd, Callsign: callsign, Gateway: "GW", Timestamp: now.Add(time.Duration(i) * time.Second)})
+	}
+
+	// Paced like a radio: each packet is handled in its own goroutine, so a
+	// burst could reach the reassembler out of order
+	for _, frame := range searchFrames("W1AW", "LH") {
+		if _, err := asker.Write(frame); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// Collect the reply sent to the asker once the query stream has timed out
+	var data []byte
+	buf := make([]byte, 512)
+	for done := false; !done; {
+		_ = asker.SetReadDeadline(time.Now().Add(6 * time.Second))
+		n, err := asker.Read(buf)
+		if err != nil {
+			t.Fatalf("expected a reply: %v", err)
+		}
+		packet := buf[:n]
+		if n < network.DataPacketSize || string(packet[:4]) != network.PacketTypeData || strings.TrimSpace(string(packet[4:14])) != "NEXUS" {
+			continue
+		}
+		frame := packet[network.FrameOffset : network.FrameOffset+network.FrameSize]
+		fich, ok := network.DecodeFICH(frame)
+		if !ok {
+			t.Fatal("reply frame failed its FICH CRC")
+		}
+		block1, _ := network.DecodeDataFR(frame, 1)
+		block2, _ := network.DecodeDataFR(frame, 2)
+		switch {
+		case fich.FI == network.FITerminator:
+			done = true
+		case fich.FI != network.FICommunications || fich.FN == 0:
+		case fich.FN > 1:
+			data = append(data, block1...)
+			data = append(data, block2...)
+		case fich.BN == 0:
+			data = append(data, block2...)
+		default:
+			data = append(data, block2[1:]...)
+		}
+	}
+
+	// The two most recent stations other than the asker, newest first
+	if len(data) < 125 || string(data[23:25]) != "02" {
+		t.Fatalf("unexpected reply %q", data)
+	}
+	if name := strings.TrimSpace(string(data[31:47])); name != "K8ABC" {
+		t.Errorf("first entry = %q", name)
+	}
+	if name := strings.TrimSpace(string(data[81:97])); name != "KD8XYZ" {
+		t.Errorf("second entry = %q", name)
+	}
+
+	// Only the asker gets the reply
+	for {
+		_ = other.SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+		n, err := other.Read(buf)
+		if err != nil {
+			break
+		}
+		if n >= network.DataPacketSize && strings.TrimSpace(string(buf[4:14])) == "NEXUS" {
+			t.Fatal("reply sent to another repeater")
+		}
+	}
+}
+
+func TestLastHeardQueryListsLocalTalker(t *testing.T) {
+	events := make(chan repeater.Event, 10)
+	m := repeater.NewManager(5*time.Second, 10, events, 180*time.Second, 0)
+	addr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 42000}
+	m.AddRepeater("GW1", addr)
+	m.ProcessPacket("N0CALL", addr, network.PacketTypeData, network.DataPacketSize)
+	m.RemoveRepeater(addr, repeater.DisconnectUnlink)
+
+	l := &lastHeardQuery{count: 2, node: wiresx.Node{ID: "00000", Name: "NEXUS", Callsign: "NEXUS"}}
+	for len(events) > 0 {
+		if event := <-events; event.Type == repeater.EventTalkEnd {
+			l.record(event)
+		}
+	}
+
+	data := l.reply("W1AW")
+	if len(data) < 75 || string(data[23:25]) != "01" {
+		t.Fatalf("unexpected reply %q", data)
+	}
+	if name := strings.TrimSpace(string(data[31:47])); name != "N0CALL" {
+		t.Errorf("entry = %q, want the source callsign N0CALL", name)
+	}
+}
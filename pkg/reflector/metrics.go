@@ -0,0 +1,144 @@
+package reflector
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/bridge"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/metrics"
+)
+
+// bridgeStates are the states reported by the ysf_bridge_state metric
+var bridgeStates = []bridge.BridgeState{
+	bridge.StateDisconnected,
+	bridge.StateConnecting,
+	bridge.StateConnected,
+	bridge.StateFailed,
+	bridge.StateScheduled,
+	bridge.StateDraining,
+}
+
+// setupMetrics creates the Prometheus/OpenMetrics server
+func (r *Reflector) setupMetrics() {
+	r.metricsServer = metrics.NewServer(r.metricsAddress(), r.config.Metrics.Prometheus.Path, r.bridgeMetrics, r.logger)
+}
+
+// metricsAddress is the listen address of the metrics server
+func (r *Reflector) metricsAddress() string {
+	return fmt.Sprintf(":%d", r.config.Metrics.Prometheus.Port)
+}
+
+// listenMetrics opens the metrics server's listener, taking over the one a
+// previous process handed over if there is one
+func (r *Reflector) listenMetrics() (net.Listener, error) {
+	ln, err := r.inherited.Listen(r.metricsAddress())
+	if err != nil {
+		return nil, fmt.Errorf("metrics listen on %s: %w", r.metricsAddress(), err)
+	}
+	r.mu.Lock()
+	r.metricsListener = ln
+	r.mu.Unlock()
+	return ln, nil
+}
+
+// runMetrics serves metrics on ln until ctx is cancelled
+func (r *Reflector) runMetrics(ctx context.Context, ln net.Listener) {
+	if err := r.metricsServer.Serve(ctx, ln); err != nil {
+		r.logger.Error("Metrics server error", logger.Error(err))
+	}
+}
+
+// bridgeMetrics collects the state of every bridge and the schedule of every
+// bridge window, so alerts such as "bridge X has not run in 25h" can be
+// written against them
+func (r *Reflector) bridgeMetrics() []metrics.Family {
+	state := metrics.Family{
+		Name: "ysf_bridge_state",
+		Help: "Current state of the bridge (1 for the current state, 0 otherwise).",
+		Type: metrics.Gauge,
+	}
+	statuses := r.bridgeManager.GetStatus()
+	names := make([]string, 0, len(statuses))
+	for name := range statuses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		current := statuses[name].State
+		for _, s := range bridgeStates {
+			value := 0.0
+			if s == current {
+				value = 1
+			}
+			state.Samples = append(state.Samples, metrics.Sample{
+				Labels: []metrics.Label{{Name: "bridge", Value: name}, {Name: "state", Value: string(s)}},
+				Value:  value,
+			})
+		}
+	}
+
+	info := metrics.Family{
+		Name: "ysf_bridge_schedule_window_info",
+		Help: "Schedule window of a bridge, with its cron expression and time zone.",
+		Type: metrics.Gauge,
+	}
+	duration := metrics.Family{
+		Name: "ysf_bridge_schedule_window_duration_seconds",
+		Help: "Configured length of a bridge schedule window.",
+		Type: metrics.Gauge,
+	}
+	next := metrics.Family{
+		Name: "ysf_bridge_schedule_next_run_timestamp_seconds",
+		Help: "Unix time of the next scheduled start of a bridge window.",
+		Type: metrics.Gauge,
+	}
+	last := metrics.Family{
+		Name: "ysf_bridge_schedule_last_run_timestamp_seconds",
+		Help: "Unix time the last run of a bridge window ended.",
+		Type: metrics.Gauge,
+	}
+	lastDuration := metrics.Family{
+		Name: "ysf_bridge_schedule_last_run_duration_seconds",
+		Help: "How long the last run of a bridge window kept the bridge linked.",
+		Type: metrics.Gauge,
+	}
+	missed := metrics.Family{
+		Name: "ysf_bridge_schedule_missed_windows",
+		Help: "Bridge windows that were missed and recovered.",
+		Type: metrics.Counter,
+	}
+
+	for _, sched := range r.bridgeManager.GetSchedules() {
+		for _, w := range sched.Windows {
+			labels := []metrics.Label{{Name: "bridge", Value: sched.Name}, {Name: "window", Value: strconv.Itoa(w.Window)}}
+
+			info.Samples = append(info.Samples, metrics.Sample{
+				Labels: append(append([]metrics.Label(nil), labels...),
+					metrics.Label{Name: "schedule", Value: w.Schedule},
+					metrics.Label{Name: "timezone", Value: sched.Timezone}),
+				Value: 1,
+			})
+			duration.Samples = append(duration.Samples, metrics.Sample{Labels: labels, Value: w.Duration.Seconds()})
+			if w.NextExecution != nil {
+				next.Samples = append(next.Samples, metrics.Sample{Labels: labels, Value: unixSeconds(*w.NextExecution)})
+			}
+			if w.LastExecution != nil {
+				last.Samples = append(last.Samples, metrics.Sample{Labels: labels, Value: unixSeconds(*w.LastExecution)})
+				lastDuration.Samples = append(lastDuration.Samples, metrics.Sample{Labels: labels, Value: w.LastDuration.Seconds()})
+			}
+			missed.Samples = append(missed.Samples, metrics.Sample{Labels: labels, Value: float64(w.MissedWindows)})
+		}
+	}
+
+	return []metrics.Family{state, info, duration, next, last, lastDuration, missed}
+}
+
+// unixSeconds returns t as fractional Unix seconds
+func unixSeconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / 1e9
+}
@@ -0,0 +1,53 @@
+package reflector
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/metrics"
+)
+
+func TestBridgeMetrics(t *testing.T) {
+	cfg := &config.Config{Bridges: []config.BridgeConfig{{
+		Name:     "Net",
+		Host:     "127.0.0.1",
+		Port:     42000,
+		Enabled:  true,
+		Schedule: "0 0 3 1 1 *", // Far enough away not to run during the test
+		Duration: time.Hour,
+		Windows:  []config.ScheduleWindow{{Schedule: "0 0 4 1 1 *", Duration: 2 * time.Hour}},
+	}}}
+	r := New(cfg, logger.NewTestLogger(io.Discard))
+	if err := r.bridgeManager.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer r.bridgeManager.Stop()
+
+	var buf bytes.Buffer
+	if err := metrics.Write(&buf, r.bridgeMetrics(), metrics.FormatText); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`ysf_bridge_state{bridge="Net",state="disconnected"} 1`,
+		`ysf_bridge_state{bridge="Net",state="connected"} 0`,
+		`ysf_bridge_state{bridge="Net",state="draining"} 0`,
+		`ysf_bridge_schedule_window_info{bridge="Net",window="1",schedule="0 0 4 1 1 *",timezone=""} 1`,
+		`ysf_bridge_schedule_window_duration_seconds{bridge="Net",window="1"} 7200`,
+		`ysf_bridge_schedule_next_run_timestamp_seconds{bridge="Net",window="0"} `,
+		`ysf_bridge_schedule_missed_windows_total{bridge="Net",window="0"} 0`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	// Windows that have not run yet have no last-run samples
+	if strings.Contains(out, "ysf_bridge_schedule_last_run_timestamp_seconds{") {
+		t.Errorf("unexpected last-run sample:\n%s", out)
+	}
+}
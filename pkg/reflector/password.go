@@ -0,0 +1,255 @@
+package reflector
+
+import (
+	"crypto/subtle"
+	"net"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/network"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+// Room passwords. With server.password.token set, a repeater is admitted only
+// after it presents the token, either after the gateway callsign of its YSFP
+// poll or in the options string of a YSFO packet, which YSFGateway sends
+// with its polls. Admission belongs to the address and lasts as long as the
+// repeater keeps polling within server.timeout; with server.follow_port_changes
+// it moves along with a session whose source port changed. A gateway without the token
+// gets no poll reply, so it never links, and a YSFI message saying why;
+// callsigns on the exempt list are admitted without one.
+
+// Rejection reasons
+const (
+	passwordMissing = "missing"
+	passwordInvalid = "invalid"
+)
+
+// passwordNotifyInterval limits rejection messages, events and log entries
+// to one per address in this window, since a rejected gateway keeps polling
+const passwordNotifyInterval = time.Minute
+
+// passwordGate admits repeaters that presented the room password
+type passwordGate struct {
+	token  []byte
+	exempt []string // Upper-case gateway callsign patterns
+	ttl    time.Duration
+
+	mu       sync.Mutex
+	admitted map[string]time.Time // address -> last poll
+	notified map[string]time.Time // address -> last rejection notice
+}
+
+// setupPassword turns on the room password
+func (r *Reflector) setupPassword() {
+	pc := r.config.Server.Password
+	exempt := make([]string, 0, len(pc.Exempt))
+	for _, pattern := range pc.Exempt {
+		exempt = append(exempt, strings.ToUpper(strings.TrimSpace(pattern)))
+	}
+	r.password = &passwordGate{
+		token:    []byte(pc.Token),
+		exempt:   exempt,
+		ttl:      r.config.Server.Timeout,
+		admitted: make(map[string]time.Time),
+		notified: make(map[string]time.Time),
+	}
+	r.logger.Info("Room password required for new repeaters",
+		logger.Int("exempt_patterns", len(exempt)))
+}
+
+// checkPoll reports whether a poll may register its repeater. A token after
+// the callsign admits the address; otherwise it must have been admitted
+// before. The reason is set when the poll is rejected.
+func (g *passwordGate) checkPoll(packet *network.Packet, now time.Time) (bool, string) {
+	if g.isExempt(packet.Callsign) {
+		return true, ""
+	}
+	key := packet.Source.String()
+	if offered := packet.Payload(); offered != "" {
+		if !g.matches(offered) {
+			return false, passwordInvalid
+		}
+		g.admit(key, now)
+		return true, ""
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	last, ok := g.admitted[key]
+	if !ok || (g.ttl > 0 && now.Sub(last) > g.ttl) {
+		delete(g.admitted, key)
+		return false, passwordMissing
+	}
+	g.admitted[key] = now
+	return true, ""
+}
+
+// checkOptions looks for the token in a YSFO options string, either as a
+// field of its own or as pw=<token>. It reports whether the options carried
+// a password at all and whether it was right; a right one admits the address.
+func (g *passwordGate) checkOptions(packet *network.Packet, now time.Time) (offered, valid bool) {
+	fields := strings.FieldsFunc(packet.Payload(), func(r rune) bool {
+		return r == ';' || r == ',' || r == ' '
+	})
+	for _, field := range fields {
+		value := field
+		if len(field) > 3 && strings.EqualFold(field[:3], "pw=") {
+			value = field[3:]
+			offered = true
+		}
+		if g.matches(value) {
+			g.admit(packet.Source.String(), now)
+			return true, true
+		}
+	}
+	return offered, false
+}
+
+// admit lets an address register its repeater. Admissions that have expired
+// are dropped here, since a repeater that times out or changes port never
+// unlinks from its old address.
+func (g *passwordGate) admit(key string, now time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.ttl > 0 {
+		for k, last := range g.admitted {
+			if now.Sub(last) > g.ttl {
+				delete(g.admitted, k)
+			}
+		}
+	}
+	g.admitted[key] = now
+	delete(g.notified, key)
+}
+
+// carry moves an admission that is still current to a new address, for a
+// repeater whose source port changed. It reports whether there was one.
+func (g *passwordGate) carry(from, to string, now time.Time) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	last, ok := g.admitted[from]
+	if !ok || (g.ttl > 0 && now.Sub(last) > g.ttl) {
+		return false
+	}
+	delete(g.admitted, from)
+	g.admitted[to] = now
+	delete(g.notified, to)
+	return true
+}
+
+// forget ends an address's admission, e.g. when it unlinks
+func (g *passwordGate) forget(addr *net.UDPAddr) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.admitted, addr.String())
+}
+
+// shouldNotify reports whether a rejection of an address is due a notice
+func (g *passwordGate) shouldNotify(key string, now time.Time) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for k, at := range g.notified {
+		if now.Sub(at) > passwordNotifyInterval {
+			delete(g.notified, k)
+		}
+	}
+	if _, recent := g.notified[key]; recent {
+		return false
+	}
+	g.notified[key] = now
+	return true
+}
+
+// matches compares an offered password with the token in constant time
+func (g *passwordGate) matches(offered string) bool {
+	return subtle.ConstantTimeCompare([]byte(offered), g.token) == 1
+}
+
+// isExempt reports whether a gateway callsign needs no password
+func (g *passwordGate) isExempt(callsign string) bool {
+	callsign = strings.ToUpper(strings.TrimSpace(callsign))
+	for _, pattern := range g.exempt {
+		if ok, _ := path.Match(pattern, callsign); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// rejectPassword turns a repeater away: it gets a YSFI message saying why,
+// and a password_rejected event is sent, at most once a minute per address
+func (r *Reflector) rejectPassword(packet *network.Packet, reason string) {
+	now := time.Now()
+	if !r.password.shouldNotify(packet.Source.String(), now) {
+		return
+	}
+
+	r.logger.Warn("Repeater rejected, room password "+reason,
+		logger.String("callsign", packet.Callsign),
+		logger.String("source", packet.Source.String()))
+
+	message := "Password required"
+	if reason == passwordInvalid {
+		message = "Invalid password"
+	}
+	if err := r.server.SendPacket(network.CreateInfoPacket(message), packet.Source); err != nil {
+		r.logger.Debug("Failed to send password rejection", logger.Error(err))
+	}
+
+	event := repeater.Event{
+		Type:      repeater.EventPasswordRejected,
+		Callsign:  packet.Callsign,
+		Address:   packet.Source.String(),
+		Timestamp: now,
+		Data:      map[string]interface{}{"reason": reason},
+	}
+	select {
+	case r.eventChan <- event:
+	default:
+		r.logger.Warn("Event channel full, dropping password rejection event")
+	}
+}
+
+// admitPortChange carries the admission of a linked repeater to the new
+// source port of its poll, when port following is about to move its session
+// there. It reports whether the poll is admitted that way.
+func (r *Reflector) admitPortChange(packet *network.Packet) bool {
+	previous := r.repeaterManager.PortChangeCandidate(packet.Callsign, packet.Source)
+	if previous == nil {
+		return false
+	}
+	return r.password.carry(previous.Address().String(), packet.Source.String(), time.Now())
+}
+
+// handlePasswordOptions admits a repeater whose YSFO packet carries the room
+// password and registers it as if it had polled, unless it is linked
+// already. It reports whether the packet was handled.
+func (r *Reflector) handlePasswordOptions(packet *network.Packet) bool {
+	if r.bridgeManager.IsBridgeAddress(packet.Source) {
+		return false
+	}
+	offered, valid := r.password.checkOptions(packet, time.Now())
+	if !valid {
+		if offered {
+			r.rejectPassword(packet, passwordInvalid)
+			return true
+		}
+		return false
+	}
+
+	if r.repeaterManager.GetRepeater(packet.Source) != nil {
+		// Already linked; its polls are answered as usual
+		return true
+	}
+	poll := *packet
+	poll.Type = network.PacketTypePoll
+	poll.Data = packet.Data[:network.PollPacketSize]
+	if err := r.handlePollPacket(&poll); err != nil {
+		r.logger.Debug("Failed to register repeater after password", logger.Error(err))
+	}
+	return true
+}
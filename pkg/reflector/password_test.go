@@ -0,0 +1,240 @@
+package reflector
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/network"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+// startPasswordReflector starts a reflector with a room password on a free
+// port, applying any further server options
+func startPasswordReflector(t *testing.T, pc config.PasswordConfig, opts ...func(*config.ServerConfig)) (*Reflector, *net.UDPAddr) {
+	t.Helper()
+	free, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := free.LocalAddr().(*net.UDPAddr)
+	_ = free.Close()
+
+	cfg := &config.Config{Server: config.ServerConfig{
+		Name:              "NEXUS",
+		Host:              "127.0.0.1",
+		Port:              addr.Port,
+		Timeout:           time.Minute,
+		MaxConnections:    10,
+		TalkMaxDuration:   time.Minute,
+		BridgeTalkTimeout: 3 * time.Second,
+		Password:          pc,
+	}}
+	for _, opt := range opts {
+		opt(&cfg.Server)
+	}
+	r := New(cfg, logger.NewTestLogger(io.Discard))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() { _ = r.Start(ctx) }()
+	<-r.server.Ready()
+	return r, addr
+}
+
+// exchange sends a packet and returns the replies that arrive within a short wait
+func exchange(t *testing.T, conn *net.UDPConn, packet string) []string {
+	t.Helper()
+	if _, err := conn.Write([]byte(packet)); err != nil {
+		t.Fatal(err)
+	}
+	var replies []string
+	buf := make([]byte, 512)
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		n, err := conn.Read(buf)
+		if err != nil {
+			return replies
+		}
+		replies = append(replies, string(buf[:n]))
+	}
+}
+
+func hasPollReply(replies []string) bool {
+	for _, reply := range replies {
+		if len(reply) == network.PollPacketSize && reply[:4] == network.PacketTypePoll {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPasswordRejectsPollWithoutToken(t *testing.T) {
+	r, addr := startPasswordReflector(t, config.PasswordConfig{Token: "s3cret"})
+	conn, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	replies := exchange(t, conn, fmt.Sprintf("%s%-10s", network.PacketTypePoll, "W1AW"))
+	if hasPollReply(replies) {
+		t.Fatal("poll without the password was answered")
+	}
+	if len(replies) != 1 || replies[0] != network.PacketTypeInfo+"Password required" {
+		t.Fatalf("replies = %q, want a password required message", replies)
+	}
+	if r.repeaterManager.Count() != 0 {
+		t.Error("repeater registered without the password")
+	}
+
+	// A wrong token is rejected too
+	replies = exchange(t, conn, fmt.Sprintf("%s%-10s%s", network.PacketTypePoll, "W1AW", "guess"))
+	if hasPollReply(replies) {
+		t.Fatal("poll with a wrong password was answered")
+	}
+}
+
+func TestPasswordAdmitsPollExtension(t *testing.T) {
+	r, addr := startPasswordReflector(t, config.PasswordConfig{Token: "s3cret"})
+	conn, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if !hasPollReply(exchange(t, conn, fmt.Sprintf("%s%-10s%s", network.PacketTypePoll, "W1AW", "s3cret"))) {
+		t.Fatal("poll with the password was not answered")
+	}
+	// Later plain polls from the admitted address are answered
+	if !hasPollReply(exchange(t, conn, fmt.Sprintf("%s%-10s", network.PacketTypePoll, "W1AW"))) {
+		t.Fatal("plain poll after admission was not answered")
+	}
+	if r.repeaterManager.Count() != 1 {
+		t.Errorf("Count = %d, want 1", r.repeaterManager.Count())
+	}
+}
+
+func TestPasswordFollowsPortChange(t *testing.T) {
+	r, addr := startPasswordReflector(t, config.PasswordConfig{Token: "s3cret"}, func(sc *config.ServerConfig) {
+		sc.FollowPortChanges = true
+	})
+	before, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer before.Close()
+	if !hasPollReply(exchange(t, before, fmt.Sprintf("%s%-10s%s", network.PacketTypePoll, "W1AW", "s3cret"))) {
+		t.Fatal("poll with the password was not answered")
+	}
+
+	// The NAT moves the gateway to a new source port; its plain polls go on
+	after, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer after.Close()
+	if !hasPollReply(exchange(t, after, fmt.Sprintf("%s%-10s", network.PacketTypePoll, "W1AW"))) {
+		t.Fatal("plain poll from the new port was not answered")
+	}
+	newAddr := after.LocalAddr().(*net.UDPAddr)
+	if r.repeaterManager.Count() != 1 || r.repeaterManager.GetRepeater(newAddr) == nil {
+		t.Fatalf("expected the session to move to %s, have %d repeaters", newAddr, r.repeaterManager.Count())
+	}
+
+	// The admission moved with it
+	r.password.mu.Lock()
+	_, atOld := r.password.admitted[before.LocalAddr().String()]
+	_, atNew := r.password.admitted[newAddr.String()]
+	r.password.mu.Unlock()
+	if atOld || !atNew {
+		t.Errorf("expected the admission only at the new port, old=%v new=%v", atOld, atNew)
+	}
+
+	// Another callsign from the same IP still needs the password
+	other, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	if hasPollReply(exchange(t, other, fmt.Sprintf("%s%-10s", network.PacketTypePoll, "K1ABC"))) {
+		t.Error("poll from another callsign was admitted by the moved session")
+	}
+}
+
+func TestPasswordAdmitsOptions(t *testing.T) {
+	r, addr := startPasswordReflector(t, config.PasswordConfig{Token: "s3cret"})
+	conn, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	replies := exchange(t, conn, fmt.Sprintf("%s%-10s%s", network.PacketTypeOption, "W1AW", "10;pw=s3cret"))
+	if !hasPollReply(replies) {
+		t.Fatalf("options with the password did not link the repeater: %q", replies)
+	}
+	if r.repeaterManager.Count() != 1 {
+		t.Errorf("Count = %d, want 1", r.repeaterManager.Count())
+	}
+}
+
+func TestPasswordExemptCallsign(t *testing.T) {
+	_, addr := startPasswordReflector(t, config.PasswordConfig{Token: "s3cret", Exempt: []string{"w1*"}})
+	conn, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if !hasPollReply(exchange(t, conn, fmt.Sprintf("%s%-10s", network.PacketTypePoll, "W1AW"))) {
+		t.Fatal("exempt callsign was not answered")
+	}
+}
+
+func TestPasswordRejectionEvent(t *testing.T) {
+	events := make(chan repeater.Event, 10)
+	r := &Reflector{eventChan: events, logger: logger.NewTestLogger(io.Discard), server: network.NewServerWithLogger("127.0.0.1", 0, logger.NewTestLogger(io.Discard))}
+	r.config = &config.Config{Server: config.ServerConfig{Timeout: time.Minute, Password: config.PasswordConfig{Token: "x"}}}
+	r.setupPassword()
+
+	source := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 42000}
+	packet := &network.Packet{Type: network.PacketTypePoll, Callsign: "N0CALL", Source: source}
+	r.rejectPassword(packet, passwordMissing)
+	r.rejectPassword(packet, passwordMissing) // Within the notify interval
+
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	event := <-events
+	if event.Type != repeater.EventPasswordRejected || event.Callsign != "N0CALL" || event.Data["reason"] != passwordMissing {
+		t.Errorf("event = %+v", event)
+	}
+}
+
+func TestPasswordExpiredAdmissionsPruned(t *testing.T) {
+	g := &passwordGate{
+		token:    []byte("secret"),
+		ttl:      time.Minute,
+		admitted: make(map[string]time.Time),
+		notified: make(map[string]time.Time),
+	}
+	now := time.Now()
+
+	// A repeater that timed out without unlinking, and one still polling
+	g.admit("192.0.2.1:40001", now)
+	g.admit("192.0.2.2:40001", now.Add(30*time.Second))
+
+	g.admit("192.0.2.3:40001", now.Add(90*time.Second))
+	if _, ok := g.admitted["192.0.2.1:40001"]; ok {
+		t.Error("expected the expired admission to be dropped")
+	}
+	if len(g.admitted) != 2 {
+		t.Errorf("admitted = %v, want the two current addresses", g.admitted)
+	}
+}
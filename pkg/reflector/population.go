@@ -0,0 +1,174 @@
+package reflector
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+// populationAnnouncer aggregates repeater connects and disconnects into
+// population_changed events. The first change opens a window; when it closes
+// the connected repeaters are compared with the last announcement, so a
+// reconnect flood produces one event instead of hundreds.
+type populationAnnouncer struct {
+	window   time.Duration
+	webhooks []string
+	client   *http.Client
+	last     []string // Callsigns at the last announcement, sorted
+}
+
+// setupPopulation creates the population announcer
+func (r *Reflector) setupPopulation() {
+	pc := r.config.Population
+	r.population = &populationAnnouncer{
+		window:   pc.Debounce,
+		webhooks: pc.Webhooks,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		last:     []string{},
+	}
+}
+
+// runPopulation announces population changes until ctx is cancelled
+func (r *Reflector) runPopulation(ctx context.Context) {
+	sub := r.eventBus.Subscribe(100, time.Time{})
+	defer sub.Close()
+
+	var window <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if window == nil && (event.Type == repeater.EventConnect || event.Type == repeater.EventDisconnect) {
+				window = time.After(r.population.window)
+			}
+		case <-window:
+			window = nil
+			r.announcePopulation(time.Now())
+		}
+	}
+}
+
+// announcePopulation emits a population_changed event and posts the
+// webhooks if the connected repeaters differ from the last announcement
+func (r *Reflector) announcePopulation(now time.Time) {
+	var callsigns []string
+	for _, rep := range r.repeaterManager.GetAllRepeaters() {
+		callsigns = append(callsigns, rep.Callsign())
+	}
+
+	event, changed := r.population.diff(callsigns, now)
+	if !changed {
+		return
+	}
+	r.logger.Info("Repeater population changed",
+		logger.Any("count", event.Data["count"]),
+		logger.Any("delta", event.Data["delta"]))
+
+	select {
+	case r.eventChan <- event:
+	default:
+		r.logger.Warn("Event channel full, dropping population event")
+	}
+	for _, url := range r.population.webhooks {
+		go r.population.postWebhook(r.logger, url, event)
+	}
+}
+
+// diff compares the connected callsigns with the last announcement and
+// returns the event describing the change
+func (p *populationAnnouncer) diff(callsigns []string, now time.Time) (repeater.Event, bool) {
+	current := append([]string(nil), callsigns...)
+	sort.Strings(current)
+
+	var joined, left []string
+	i, j := 0, 0
+	for i < len(p.last) || j < len(current) {
+		switch {
+		case j == len(current) || (i < len(p.last) && p.last[i] < current[j]):
+			left = append(left, p.last[i])
+			i++
+		case i == len(p.last) || current[j] < p.last[i]:
+			joined = append(joined, current[j])
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+	if len(joined) == 0 && len(left) == 0 {
+		return repeater.Event{}, false
+	}
+
+	delta := len(current) - len(p.last)
+	p.last = current
+	return repeater.Event{
+		Type:      repeater.EventPopulationChanged,
+		Timestamp: now,
+		Data: map[string]interface{}{
+			"count":  len(current),
+			"delta":  delta,
+			"digest": populationDigest(current),
+			"joined": nonNil(joined),
+			"left":   nonNil(left),
+		},
+	}, true
+}
+
+// populationDigest identifies a set of callsigns, so displays can tell
+// whether their list is current without comparing it
+func populationDigest(sorted []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(sorted, ",")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// nonNil returns an empty slice for nil, so JSON shows [] rather than null
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
+
+// postWebhook delivers a population event to a webhook URL
+func (p *populationAnnouncer) postWebhook(log *logger.Logger, url string, event repeater.Event) {
+	postEventWebhook(p.client, log, url, event)
+}
+
+// postEventWebhook POSTs an event as JSON to a webhook URL, logging failures
+func postEventWebhook(client *http.Client, log *logger.Logger, url string, event repeater.Event) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Error("Failed to marshal event webhook", logger.String("type", event.Type), logger.Error(err))
+		return
+	}
+
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Warn("Event webhook failed",
+			logger.String("type", event.Type),
+			logger.String("url", url),
+			logger.Error(err))
+		return
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		log.Warn("Event webhook rejected",
+			logger.String("type", event.Type),
+			logger.String("url", url),
+			logger.Int("status", resp.StatusCode))
+	}
+}
@@ -0,0 +1,99 @@
+package reflector
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+func TestPopulationDiff(t *testing.T) {
+	p := &populationAnnouncer{last: []string{}}
+	now := time.Now()
+
+	event, changed := p.diff([]string{"W1AW", "K1ABC"}, now)
+	if !changed || event.Data["count"] != 2 || event.Data["delta"] != 2 {
+		t.Fatalf("unexpected first announcement %+v", event.Data)
+	}
+	digest := event.Data["digest"]
+
+	if _, changed := p.diff([]string{"K1ABC", "W1AW"}, now); changed {
+		t.Errorf("expected no announcement for the same population")
+	}
+
+	// One repeater swapped for another: same count, new digest
+	event, changed = p.diff([]string{"W1AW", "N0CALL"}, now)
+	if !changed || event.Data["delta"] != 0 || event.Data["digest"] == digest {
+		t.Fatalf("unexpected swap announcement %+v", event.Data)
+	}
+	joined := event.Data["joined"].([]string)
+	left := event.Data["left"].([]string)
+	if len(joined) != 1 || joined[0] != "N0CALL" || len(left) != 1 || left[0] != "K1ABC" {
+		t.Errorf("unexpected joined %v, left %v", joined, left)
+	}
+}
+
+func TestPopulationDebounce(t *testing.T) {
+	posted := make(chan repeater.Event, 4)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event repeater.Event
+		_ = json.NewDecoder(r.Body).Decode(&event)
+		posted <- event
+	}))
+	defer hook.Close()
+
+	cfg := &config.Config{
+		Server:     config.ServerConfig{MaxConnections: 10},
+		Population: config.PopulationConfig{Enabled: true, Debounce: 100 * time.Millisecond, Webhooks: []string{hook.URL}},
+	}
+	r := New(cfg, logger.NewTestLogger(io.Discard))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.eventBus.Run(ctx, r.eventChan)
+	go r.runPopulation(ctx)
+	sub := r.eventBus.Subscribe(100, time.Time{})
+	defer sub.Close()
+	for r.eventBus.Subscribers() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// A burst of connects is announced once
+	for i, callsign := range []string{"W1AW", "K1ABC", "N0CALL"} {
+		r.repeaterManager.AddRepeater(callsign, &net.UDPAddr{IP: net.ParseIP("192.0.2.10"), Port: 42000 + i})
+	}
+
+	var announced []repeater.Event
+	deadline := time.After(time.Second)
+	for len(announced) < 2 {
+		select {
+		case event := <-sub.C:
+			if event.Type == repeater.EventPopulationChanged {
+				announced = append(announced, event)
+			}
+			continue
+		case <-deadline:
+		}
+		break
+	}
+	if len(announced) != 1 || announced[0].Data["count"] != 3 {
+		t.Fatalf("expected one announcement of 3 repeaters, got %+v", announced)
+	}
+
+	select {
+	case event := <-posted:
+		if event.Type != repeater.EventPopulationChanged || event.Data["count"] != float64(3) {
+			t.Errorf("unexpected webhook body %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("webhook was not posted")
+	}
+}
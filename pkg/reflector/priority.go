@@ -0,0 +1,55 @@
+package reflector
+
+import (
+	"net"
+	"path"
+	"strings"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/network"
+)
+
+// setupBroadcastPriority sends broadcasts to repeaters matching the priority
+// patterns first, in pattern order, and enables send latency measurement
+func (r *Reflector) setupBroadcastPriority() {
+	bp := r.config.Server.BroadcastPriority
+	patterns := make([]string, 0, len(bp.Callsigns))
+	for _, pattern := range bp.Callsigns {
+		patterns = append(patterns, strings.ToUpper(strings.TrimSpace(pattern)))
+	}
+
+	if len(patterns) > 0 {
+		r.server.SetBroadcastRank(func(addr *net.UDPAddr) int {
+			rep := r.repeaterManager.GetRepeater(addr)
+			if rep == nil {
+				return len(patterns)
+			}
+			callsign := strings.ToUpper(rep.Callsign())
+			for i, pattern := range patterns {
+				if ok, _ := path.Match(pattern, callsign); ok {
+					return i
+				}
+			}
+			return len(patterns)
+		})
+	}
+	r.server.SetSendLatency(bp.MeasureLatency)
+
+	r.logger.Info("Broadcast priority enabled",
+		logger.Int("patterns", len(patterns)),
+		logger.Any("measure_latency", bp.MeasureLatency))
+}
+
+// SendLatencies returns the per-destination broadcast send latency, with the
+// callsign of each connected repeater
+func (r *Reflector) SendLatencies() []network.SendLatency {
+	latencies := r.server.SendLatencies()
+	for i := range latencies {
+		if addr, err := net.ResolveUDPAddr("udp", latencies[i].Address); err == nil {
+			if rep := r.repeaterManager.GetRepeater(addr); rep != nil {
+				latencies[i].Callsign = rep.Callsign()
+			}
+		}
+	}
+	return latencies
+}
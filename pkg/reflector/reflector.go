@@ -2,15 +2,30 @@ package reflector
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/dbehnke/ysf-nexus/pkg/alerting"
 	"github.com/dbehnke/ysf-nexus/pkg/bridge"
 	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/dmrid"
+	"github.com/dbehnke/ysf-nexus/pkg/handover"
+	"github.com/dbehnke/ysf-nexus/pkg/health"
+	"github.com/dbehnke/ysf-nexus/pkg/links"
 	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/maintenance"
+	"github.com/dbehnke/ysf-nexus/pkg/metrics"
+	"github.com/dbehnke/ysf-nexus/pkg/mirror"
 	"github.com/dbehnke/ysf-nexus/pkg/network"
+	"github.com/dbehnke/ysf-nexus/pkg/ntpcheck"
 	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+	"github.com/dbehnke/ysf-nexus/pkg/snmp"
+	"github.com/dbehnke/ysf-nexus/pkg/talklog"
+	"github.com/dbehnke/ysf-nexus/pkg/version"
 	"github.com/dbehnke/ysf-nexus/pkg/web"
 )
 
@@ -19,10 +34,13 @@ type bridgeTalker struct {
 	callsign     string
 	bridgeName   string
 	bridgeAddr   string
+	gateway      string // Remote gateway callsign from YSFD bytes 4-14
 	startTime    time.Time
 	lastSeen     time.Time
 	isTalking    bool
 	lastSequence uint32
+	quality      *repeater.StreamQuality
+	origin       *repeater.OriginTracker
 }
 
 // GetCallsign returns the callsign of the bridge talker
@@ -35,6 +53,11 @@ func (bt *bridgeTalker) GetBridgeName() string {
 	return bt.bridgeName
 }
 
+// GetGateway returns the remote gateway the transmission came through
+func (bt *bridgeTalker) GetGateway() string {
+	return bt.gateway
+}
+
 // GetTalkDuration returns how long the bridge talker has been talking
 func (bt *bridgeTalker) GetTalkDuration() time.Duration {
 	return time.Since(bt.startTime)
@@ -48,38 +71,75 @@ type Reflector struct {
 	repeaterManager *repeater.Manager
 	bridgeManager   *bridge.Manager
 	webServer       *web.Server
+	maintenance     *maintenance.Scheduler
+	blocklistSub    *repeater.BlocklistSubscription
+	alerts          *alerting.Manager
+	emailer         *alerting.Emailer
+	mirror          *mirror.Mirror
+	dmrIDs          *dmrid.Directory
+	health          *health.Monitor
+	population      *populationAnnouncer
+	talkExport      *talklog.Exporter
+	externalLinks   *links.Registry
+	clockCheck      *ntpcheck.Checker
+	welcome         *welcomer
+	password        *passwordGate
+	lastHeard       *lastHeardQuery
+	transmit        *txScheduler
+	snmpAgent       *snmp.Agent
+	metricsServer   *metrics.Server
 	eventChan       chan repeater.Event
+	eventBus        *repeater.EventBus
 	running         bool
 	mu              sync.RWMutex
+	reloadedBridges []config.BridgeConfig // Bridges from the last reload, nil until then
 	version         string
 	buildTime       string
 
 	// Bridge talker tracking
 	bridgeTalkers map[string]*bridgeTalker // key: callsign+bridge_name
 	talkersMu     sync.RWMutex
+
+	// Last forwarded frame of the active stream, terminated on shutdown
+	inFlight *inFlightStream
+	streamMu sync.Mutex
+
+	// inherited holds sockets taken over from a previous process; handedOver
+	// is set once a new process took them over (see handover.go)
+	inherited  *handover.Inherited
+	handedOver atomic.Bool
+
+	// Sockets of the SNMP agent and metrics server, handed over along with
+	// the others
+	snmpConn        *net.UDPConn
+	metricsListener net.Listener
 }
 
-// New creates a new YSF reflector
+// New creates a new YSF reflector reporting the version the binary was built with
 func New(cfg *config.Config, log *logger.Logger) *Reflector {
-	return NewWithVersion(cfg, log, "dev", "unknown")
+	return NewWithVersion(cfg, log, version.Version, version.BuildTime)
 }
 
 // NewWithVersion creates a new YSF reflector with version information
-func NewWithVersion(cfg *config.Config, log *logger.Logger, version, buildTime string) *Reflector {
+func NewWithVersion(cfg *config.Config, log *logger.Logger, softwareVersion, buildTime string) *Reflector {
 	eventChan := make(chan repeater.Event, 1000)
 
 	r := &Reflector{
 		config:        cfg,
 		logger:        log.WithComponent("reflector"),
 		eventChan:     eventChan,
+		eventBus:      repeater.NewEventBus(500, log),
 		bridgeTalkers: make(map[string]*bridgeTalker),
-		version:       version,
+		version:       softwareVersion,
 		buildTime:     buildTime,
 	}
 
 	// Initialize network server
-	r.server = network.NewServer(cfg.Server.Host, cfg.Server.Port)
+	r.server = network.NewServerWithLogger(cfg.Server.Host, cfg.Server.Port, log)
+	r.server.SetListenAddresses(cfg.Server.ListenAddresses())
 	r.server.SetDebug(cfg.Logging.Level == "debug")
+	r.server.SetStrictFields(cfg.Server.StrictCallsignFields)
+	r.server.SetListenerRestartHandler(r.listenerRestarted)
 
 	// Initialize repeater manager
 	r.repeaterManager = repeater.NewManagerWithLogger(
@@ -91,11 +151,69 @@ func NewWithVersion(cfg *config.Config, log *logger.Logger, version, buildTime s
 		r.logger,
 	)
 
+	if cfg.Server.MaxConnectionsPerIP > 0 {
+		r.repeaterManager.SetMaxConnectionsPerIP(cfg.Server.MaxConnectionsPerIP)
+	}
+	r.repeaterManager.SetFollowPortChanges(cfg.Server.FollowPortChanges)
+	r.repeaterManager.SetSimultaneousBridgeStreams(cfg.Server.SimultaneousBridgeStreams)
+	r.repeaterManager.SetKeepalivePolicy(repeater.KeepalivePolicy{
+		NATTimeout:   cfg.Server.Keepalive.NATTimeout,
+		ErraticRatio: cfg.Server.Keepalive.ErraticRatio,
+	})
+
+	// Locally originated streams wait for the channel to clear
+	r.transmit = newTxScheduler(cfg.Server.TransmitQuietPeriod)
+
 	// Initialize bridge manager
 	r.bridgeManager = bridge.NewManager(cfg.Bridges, r.server, r.logger)
+	r.bridgeManager.SetEventChannel(eventChan)
+	r.bridgeManager.SetFlapDampening(cfg.BridgeDampening.Transitions, cfg.BridgeDampening.Window)
+	if err := r.bridgeManager.SetUsageStore(cfg.BridgeStats.File, cfg.BridgeStats.RetentionDays, cfg.BridgeStats.History); err != nil {
+		log.Warn("Failed to load bridge statistics", logger.Error(err))
+	}
 
 	// Initialize web server
-	r.webServer = web.NewServer(cfg, log, r.repeaterManager, eventChan, r.bridgeManager, r, version, buildTime)
+	r.webServer = web.NewServer(cfg, log, r.repeaterManager, r.eventBus, r.bridgeManager, r, softwareVersion, buildTime)
+	r.server.SetTraceSink(r.webServer.PublishTrace)
+
+	if bp := cfg.Server.BroadcastPriority; len(bp.Callsigns) > 0 || bp.MeasureLatency {
+		r.setupBroadcastPriority()
+	}
+
+	if len(cfg.Groups) > 0 {
+		r.setupGroups(cfg.Groups)
+	}
+
+	if lo := cfg.Server.ListenOnly; len(lo.Callsigns) > 0 || lo.Notify {
+		r.repeaterManager.SetListenOnly(lo.Callsigns, lo.Notify)
+	}
+
+	if len(cfg.Server.TalkLimits) > 0 {
+		r.setupTalkLimits(cfg.Server.TalkLimits)
+	}
+
+	r.repeaterManager.SetHoldPolicy(repeater.HoldPolicy{
+		Duration:    cfg.Server.Hold.Duration,
+		MaxDuration: cfg.Server.Hold.MaxDuration,
+		Message:     cfg.Server.Hold.Message,
+	})
+
+	// Set up anti-kerchunk policy if configured
+	if ak := cfg.Server.AntiKerchunk; ak.Enabled {
+		r.repeaterManager.SetKerchunkPolicy(repeater.KerchunkPolicy{
+			Enabled:               true,
+			MaxShortTransmissions: ak.MaxShortTransmissions,
+			ShortThreshold:        ak.ShortThreshold,
+			Window:                ak.Window,
+			Cooldown:              ak.Cooldown,
+			Exempt:                ak.Exempt,
+		})
+		r.logger.Info("Anti-kerchunk policy enabled",
+			logger.Int("max_short_transmissions", ak.MaxShortTransmissions),
+			logger.Duration("short_threshold", ak.ShortThreshold),
+			logger.Duration("window", ak.Window),
+			logger.Duration("cooldown", ak.Cooldown))
+	}
 
 	// Set up blocklist if configured
 	if cfg.Blocklist.Enabled && len(cfg.Blocklist.Callsigns) > 0 {
@@ -103,6 +221,104 @@ func NewWithVersion(cfg *config.Config, log *logger.Logger, version, buildTime s
 		r.logger.Info("Blocklist configured",
 			logger.Int("blocked_callsigns", len(cfg.Blocklist.Callsigns)))
 	}
+	if cfg.Blocklist.Enabled && len(cfg.Blocklist.Subscriptions) > 0 {
+		r.blocklistSub = repeater.NewBlocklistSubscription(
+			r.repeaterManager.GetBlocklist(),
+			cfg.Blocklist.Subscriptions,
+			cfg.Blocklist.RefreshInterval,
+			r.logger,
+		)
+		r.logger.Info("Blocklist subscriptions configured",
+			logger.Int("sources", len(cfg.Blocklist.Subscriptions)),
+			logger.Duration("refresh_interval", cfg.Blocklist.RefreshInterval))
+	}
+
+	// Set up nightly maintenance if configured
+	if cfg.Maintenance.Enabled {
+		r.setupMaintenance()
+	}
+
+	// Check the system clock against NTP if enabled
+	if cfg.ClockCheck.Enabled {
+		r.clockCheck = ntpcheck.New(cfg.ClockCheck, eventChan, r.logger)
+		r.webServer.SetClockCheck(r.clockCheck)
+	}
+
+	// Set up threshold alerting if configured
+	if cfg.Alerting.Enabled {
+		r.setupAlerting()
+	}
+
+	// Set up traffic mirroring if a target is configured; it can be enabled at runtime
+	if mc := cfg.Mirror; mc.Target != "" {
+		m, err := mirror.New(mirror.Options{
+			Target:      mc.Target,
+			SampleEvery: mc.SampleEvery,
+			RateLimit:   mc.RateLimit,
+			Enabled:     mc.Enabled,
+		}, r.logger)
+		if err != nil {
+			r.logger.Error("Invalid mirror configuration", logger.Error(err))
+		} else {
+			r.mirror = m
+			r.server.AddPlugin(m)
+			r.webServer.SetMirror(m)
+		}
+	}
+
+	// Load the DMR ID overrides; more can be added through the API
+	r.setupDMRIDs()
+
+	// Set up the runtime monitor if enabled
+	if cfg.Health.Enabled {
+		r.setupHealth()
+	}
+
+	// Set up population change announcements if enabled
+	if cfg.Population.Enabled {
+		r.setupPopulation()
+	}
+
+	// Set up the welcome message for new repeaters if enabled
+	if cfg.Server.Welcome.Enabled {
+		r.setupWelcome()
+	}
+
+	// Require the room password from new repeaters if one is set
+	if cfg.Server.Password.Token != "" {
+		r.setupPassword()
+	}
+
+	// Answer Wires-X last heard queries if enabled
+	if cfg.Server.LastHeardQuery.Enabled {
+		r.setupLastHeardQuery()
+	}
+
+	// Set up talk log export if enabled
+	if cfg.TalkExport.Enabled {
+		r.setupTalkExport()
+	}
+
+	// Poll the external links shown next to the bridges
+	if len(cfg.ExternalLinks) > 0 {
+		r.externalLinks = links.New(cfg.ExternalLinks, r.logger)
+		r.webServer.SetExternalLinks(r.externalLinks)
+	}
+
+	// Set up geo enrichment if a database is configured
+	if cfg.GeoIP.CountryDB != "" || cfg.GeoIP.ASNDB != "" {
+		r.setupGeoIP()
+	}
+
+	// Set up the SNMP agent if enabled
+	if cfg.SNMP.Enabled {
+		r.setupSNMP()
+	}
+
+	// Set up the Prometheus/OpenMetrics endpoint if enabled
+	if cfg.Metrics.Enabled && cfg.Metrics.Prometheus.Enabled {
+		r.setupMetrics()
+	}
 
 	// Register packet handlers
 	r.registerHandlers()
@@ -110,6 +326,141 @@ func NewWithVersion(cfg *config.Config, log *logger.Logger, version, buildTime s
 	return r
 }
 
+// setupAlerting creates the alert manager and registers the metrics rules can use
+func (r *Reflector) setupAlerting() {
+	ac := r.config.Alerting
+
+	rules := make([]alerting.Rule, 0, len(ac.Rules))
+	for _, rc := range ac.Rules {
+		rules = append(rules, alerting.Rule{
+			Name:      rc.Name,
+			Metric:    rc.Metric,
+			Target:    rc.Target,
+			Operator:  rc.Operator,
+			Threshold: rc.Threshold,
+			For:       rc.For,
+		})
+	}
+
+	r.alerts = alerting.NewManager(rules, ac.Interval, r.eventChan, r.logger)
+	r.alerts.SetWebhooks(ac.Webhooks)
+
+	r.alerts.RegisterMetric("active_repeaters", func(string) (float64, error) {
+		return float64(r.repeaterManager.Count()), nil
+	})
+
+	r.alerts.RegisterMetric("bridges_down", func(target string) (float64, error) {
+		statuses := r.bridgeManager.GetStatus()
+		if target != "" {
+			status, ok := statuses[target]
+			if !ok {
+				return 0, fmt.Errorf("unknown bridge: %s", target)
+			}
+			if status.State.Linked() {
+				return 0, nil
+			}
+			return 1, nil
+		}
+
+		// Scheduled bridges are expected to be down outside their window,
+		// so only permanent bridges count without an explicit target
+		down := 0
+		for _, bc := range r.bridgeManager.Configs() {
+			if !bc.Enabled || !bc.Permanent {
+				continue
+			}
+			if status, ok := statuses[bc.Name]; !ok || !status.State.Linked() {
+				down++
+			}
+		}
+		return float64(down), nil
+	})
+
+	var lastReceived, lastErrors int64
+	r.alerts.RegisterMetric("packet_error_rate", func(string) (float64, error) {
+		metrics := r.server.GetMetrics()
+		var received int64
+		for _, count := range metrics.PacketsReceived {
+			received += count
+		}
+
+		deltaReceived := received - lastReceived
+		deltaErrors := metrics.PacketErrors - lastErrors
+		lastReceived, lastErrors = received, metrics.PacketErrors
+
+		if deltaReceived <= 0 {
+			return 0, nil
+		}
+		return float64(deltaErrors) / float64(deltaReceived) * 100, nil
+	})
+
+	var lastQueued, lastDropped uint64
+	r.alerts.RegisterMetric("websocket_drop_rate", func(string) (float64, error) {
+		stats := r.webServer.WebSocketStats()
+		deltaQueued := stats.MessagesQueued - lastQueued
+		deltaDropped := stats.MessagesDropped - lastDropped
+		lastQueued, lastDropped = stats.MessagesQueued, stats.MessagesDropped
+
+		if deltaQueued+deltaDropped == 0 {
+			return 0, nil
+		}
+		return float64(deltaDropped) / float64(deltaQueued+deltaDropped) * 100, nil
+	})
+
+	r.alerts.RegisterMetric("clock_offset_ms", func(string) (float64, error) {
+		if r.clockCheck == nil {
+			return 0, fmt.Errorf("clock check disabled")
+		}
+		return r.clockCheck.OffsetMS(), nil
+	})
+
+	if err := r.alerts.Validate(); err != nil {
+		r.logger.Error("Invalid alert configuration", logger.Error(err))
+	}
+
+	if ac.Email.Enabled {
+		r.setupEmail()
+	}
+
+	r.webServer.SetAlertManager(r.alerts)
+}
+
+// setupMaintenance creates the maintenance scheduler and registers its tasks
+func (r *Reflector) setupMaintenance() {
+	mc := r.config.Maintenance
+	r.maintenance = maintenance.NewScheduler(mc.Schedule, mc.TaskTimeout, r.eventChan, r.logger)
+
+	if mc.RotateLogs && r.logger.HasFile() {
+		r.maintenance.Register("rotate_logs", func(ctx context.Context) (string, error) {
+			if err := r.logger.Rotate(); err != nil {
+				return "", fmt.Errorf("failed to rotate log file: %w", err)
+			}
+			return "log file rotated", nil
+		})
+	}
+
+	if mc.TalkLogRetention > 0 {
+		r.maintenance.Register("prune_talk_logs", func(ctx context.Context) (string, error) {
+			removed := r.webServer.PruneTalkLogs(mc.TalkLogRetention)
+			return fmt.Sprintf("removed %d talk log entries older than %s", removed, mc.TalkLogRetention), nil
+		})
+	}
+
+	if mc.EventRetention > 0 {
+		r.maintenance.Register("prune_events", func(ctx context.Context) (string, error) {
+			removed := r.webServer.PruneEvents(mc.EventRetention)
+			return fmt.Sprintf("removed %d events older than %s", removed, mc.EventRetention), nil
+		})
+	}
+
+	if mc.IPRetention > 0 {
+		r.maintenance.Register("scrub_ip_addresses", func(ctx context.Context) (string, error) {
+			scrubbed := r.webServer.ScrubAddresses(mc.IPRetention)
+			return fmt.Sprintf("scrubbed IP addresses from %d events older than %s", scrubbed, mc.IPRetention), nil
+		})
+	}
+}
+
 // Start starts the reflector
 func (r *Reflector) Start(ctx context.Context) error {
 	r.mu.Lock()
@@ -121,85 +472,229 @@ func (r *Reflector) Start(ctx context.Context) error {
 	r.mu.Unlock()
 
 	r.logger.Info("Starting YSF Nexus reflector",
-		logger.String("host", r.config.Server.Host),
-		logger.Int("port", r.config.Server.Port),
+		logger.Any("listen", r.config.Server.ListenAddresses()),
 		logger.String("name", r.config.Server.Name),
 		logger.Int("max_connections", r.config.Server.MaxConnections))
+	if features := r.config.EnabledFeatures(); len(features) > 0 {
+		r.logger.Info("Experimental features enabled", logger.Any("features", features))
+	}
 
+	// Components run until runCtx ends; a failed startup cancels it. It is
+	// detached from ctx so the network server stays up while shutdown drains.
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
 	var wg sync.WaitGroup
+	stop := func() {
+		cancel()
+		wg.Wait()
+		r.mu.Lock()
+		r.running = false
+		r.mu.Unlock()
+	}
 
-	// NOTE: Event processor removed - web server handles events directly from the repeater manager
-	// The reflector was consuming events from the channel, preventing the web server from receiving them
-
-	// Start repeater cleanup
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		r.repeaterManager.StartCleanup(ctx)
-	}()
-
-	// Start periodic stats logging
+	// Phase 1: the network server, which everything else sends through
+	networkExit := make(chan error, 1)
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		r.logStats(ctx)
+		networkExit <- r.server.Start(runCtx)
 	}()
-
-	// Start web server
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		if err := r.webServer.Start(ctx); err != nil {
-			r.logger.Error("Web server error", logger.Error(err))
+	if err := awaitReady(ctx, "network", r.server.Ready(), networkExit, componentStartTimeout); err != nil {
+		stop()
+		if ctx.Err() != nil {
+			return nil
 		}
-	}()
+		r.logger.Error("Startup failed", logger.Error(err))
+		return err
+	}
 
-	// Start bridge manager
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		if err := r.bridgeManager.Start(); err != nil {
-			r.logger.Error("Bridge manager error", logger.Error(err))
-		}
-	}()
+	// Phase 2: managers and background tasks
+	r.startManagers(runCtx, &wg)
 
-	// Start bridge talker cleanup
-	wg.Add(1)
+	// Phases 3 and 4: bridges, then the web server. Neither is required to
+	// relay traffic, so their failures are reported together and the
+	// reflector keeps running without them.
+	var degraded []error
+
+	bridgesExit := make(chan error, 1)
 	go func() {
-		defer wg.Done()
-		r.cleanupBridgeTalkers(ctx)
+		bridgesExit <- r.bridgeManager.Start()
 	}()
+	if err := awaitReady(ctx, "bridges", nil, bridgesExit, componentStartTimeout); err != nil {
+		degraded = append(degraded, err)
+	}
 
-	// Start network server (blocking)
-	serverErr := make(chan error, 1)
+	webExit := make(chan error, 1)
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if err := r.server.Start(ctx); err != nil {
-			serverErr <- err
+		err := r.webServer.Start(runCtx)
+		if err != nil {
+			r.logger.Error("Web server error", logger.Error(err))
 		}
+		webExit <- err
 	}()
+	if err := awaitReady(ctx, "web", r.webServer.Ready(), webExit, componentStartTimeout); err != nil {
+		degraded = append(degraded, err)
+	}
+
+	if len(degraded) > 0 && ctx.Err() == nil {
+		r.logger.Warn("Reflector started with failed components", logger.Error(errors.Join(degraded...)))
+		go r.notifyLifecycle(alerting.EmailReflectorStarted, fmt.Sprintf("Started with failed components: %v", errors.Join(degraded...)))
+	} else {
+		r.logger.Info("Reflector started")
+		go r.notifyLifecycle(alerting.EmailReflectorStarted, "Started normally")
+	}
+	if err := r.inherited.Ready(); err != nil {
+		r.logger.Error("Failed to complete handover", logger.Error(err))
+	}
 
 	// Wait for either context cancellation or server error
 	select {
-	case err := <-serverErr:
+	case err := <-networkExit:
+		if ctx.Err() != nil {
+			r.logger.Info("Shutdown signal received")
+			break
+		}
+		if err == nil {
+			err = fmt.Errorf("network server stopped unexpectedly")
+		}
 		r.logger.Error("Server error", logger.Error(err))
+		stop()
 		return err
 	case <-ctx.Done():
 		r.logger.Info("Shutdown signal received")
 	}
 
-	// Wait for all goroutines to finish
-	wg.Wait()
+	if r.handedOver.Load() {
+		// The new process serves the sockets now; stop using them at once
+		_ = r.server.Stop()
+		r.bridgeManager.Shutdown(r.config.Server.DrainTimeout)
+		r.notifyLifecycle(alerting.EmailReflectorStopped, "Stopped after handing over to upgraded binary")
+	} else {
+		// Let an active transmission finish and unlink bridges before the
+		// network server goes down
+		r.drain(r.config.Server.DrainTimeout)
 
-	r.mu.Lock()
-	r.running = false
-	r.mu.Unlock()
+		r.notifyLifecycle(alerting.EmailReflectorStopped, "Stopped on shutdown signal")
+	}
+
+	// Wait for all goroutines to finish
+	stop()
 
 	r.logger.Info("YSF Nexus reflector stopped")
 	return nil
 }
 
+// startManagers starts the event bus, managers and periodic background tasks
+func (r *Reflector) startManagers(ctx context.Context, wg *sync.WaitGroup) {
+	run := func(fn func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn()
+		}()
+	}
+
+	// Fan events out to subscribers; the web server subscribes on each start
+	run(func() { r.eventBus.Run(ctx, r.eventChan) })
+
+	// Start repeater cleanup
+	run(func() { r.repeaterManager.StartCleanup(ctx) })
+
+	// Start periodic stats logging
+	run(func() { r.logStats(ctx) })
+
+	// Start maintenance scheduler
+	if r.maintenance != nil {
+		run(func() {
+			if err := r.maintenance.Start(ctx); err != nil {
+				r.logger.Error("Maintenance scheduler error", logger.Error(err))
+			}
+		})
+	}
+
+	// Start traffic mirror
+	if r.mirror != nil {
+		run(func() { r.mirror.Start(ctx) })
+	}
+
+	// Start runtime monitoring
+	if r.health != nil {
+		run(func() { r.health.Run(ctx) })
+	}
+
+	// Play queued announcements when the channel is free
+	run(func() { r.runTransmitScheduler(ctx) })
+
+	// Start population change announcements
+	if r.population != nil {
+		run(func() { r.runPopulation(ctx) })
+	}
+
+	// Start talk log export
+	if r.talkExport != nil {
+		run(func() { r.runTalkExport(ctx) })
+	}
+
+	// Track the last heard list for Wires-X queries
+	if r.lastHeard != nil {
+		run(func() { r.runLastHeardQuery(ctx) })
+	}
+
+	// Start external link polling
+	if r.externalLinks != nil {
+		run(func() { r.externalLinks.Run(ctx) })
+	}
+
+	if r.clockCheck != nil {
+		run(func() { r.clockCheck.Run(ctx) })
+	}
+
+	// Start periodic stats snapshot
+	if r.config.Snapshot.Enabled {
+		run(func() { r.runSnapshots(ctx) })
+	}
+
+	// Start remote blocklist refresh
+	if r.blocklistSub != nil {
+		run(func() { r.blocklistSub.Start(ctx) })
+	}
+
+	// Start alert evaluation
+	if r.alerts != nil {
+		run(func() { r.alerts.Start(ctx) })
+	}
+
+	// Start SNMP agent. Its socket is bound here rather than in the
+	// goroutine so one handed over by a previous process is claimed before
+	// the handover completes and closes what was left unclaimed.
+	if r.snmpAgent != nil {
+		if conn, err := r.listenSNMP(); err != nil {
+			r.logger.Error("SNMP agent error", logger.Error(err))
+		} else {
+			run(func() { r.runSNMP(ctx, conn) })
+		}
+	}
+
+	// Start metrics server, binding it here for the same reason
+	if r.metricsServer != nil {
+		if ln, err := r.listenMetrics(); err != nil {
+			r.logger.Error("Metrics server error", logger.Error(err))
+		} else {
+			run(func() { r.runMetrics(ctx, ln) })
+		}
+	}
+
+	// Start bridge talker cleanup
+	run(func() { r.cleanupBridgeTalkers(ctx) })
+
+	// Generate demo traffic once the YSF socket is up
+	if r.config.Demo.Enabled {
+		run(func() { r.runDemo(ctx) })
+	}
+}
+
 // IsRunning returns whether the reflector is running
 func (r *Reflector) IsRunning() bool {
 	r.mu.RLock()
@@ -221,10 +716,23 @@ func (r *Reflector) GetStats() *Stats {
 		PacketsSent:      networkMetrics.PacketsSent,
 		BytesReceived:    networkMetrics.BytesReceived,
 		BytesSent:        networkMetrics.BytesSent,
+		SocketErrors:     networkMetrics.SocketErrors,
+		ListenerRestarts: networkMetrics.ListenerRestarts,
 		RepeaterStats:    managerStats,
+		PacketVariants:   r.server.VariantStats(),
 	}
 }
 
+// SocketStats returns the UDP socket error and restart counts
+func (r *Reflector) SocketStats() network.SocketStats {
+	return r.server.SocketStats()
+}
+
+// PacketRates returns per-second packet and byte counts for the last two minutes
+func (r *Reflector) PacketRates() []network.RateSample {
+	return r.server.PacketRates()
+}
+
 // Stats represents reflector statistics
 type Stats struct {
 	Uptime           time.Duration         `json:"uptime"`
@@ -235,15 +743,52 @@ type Stats struct {
 	PacketsSent      map[string]int64      `json:"packets_sent"`
 	BytesReceived    int64                 `json:"bytes_received"`
 	BytesSent        int64                 `json:"bytes_sent"`
+	SocketErrors     int64                 `json:"socket_errors"`
+	ListenerRestarts int64                 `json:"listener_restarts"`
 	RepeaterStats    repeater.ManagerStats `json:"repeater_stats"`
+	// PacketVariants counts YSFV/YSFO/YSFI and unrecognized packet types
+	PacketVariants []network.VariantStat `json:"packet_variants"`
 }
 
-// registerHandlers registers packet handlers with the network server
+// handlerOwner owns the core packet types in the network server's Mux
+const handlerOwner = "reflector"
+
+// bridgeTapPriority puts the bridge tap ahead of any other tap, so a linked
+// reflector's packets reach its bridge first
+const bridgeTapPriority = 100
+
+// registerHandlers claims the core packet types in the network server's Mux
+// and taps them for bridge traffic
 func (r *Reflector) registerHandlers() {
-	r.server.RegisterHandler(network.PacketTypePoll, r.handlePollPacket)
-	r.server.RegisterHandler(network.PacketTypeData, r.handleDataPacket)
-	r.server.RegisterHandler(network.PacketTypeUnlink, r.handleUnlinkPacket)
-	r.server.RegisterHandler(network.PacketTypeStatus, r.handleStatusPacket)
+	mux := r.server.Mux()
+	handlers := map[string]network.PacketHandler{
+		network.PacketTypePoll:   r.handlePollPacket,
+		network.PacketTypeData:   r.handleDataPacket,
+		network.PacketTypeUnlink: r.handleUnlinkPacket,
+		network.PacketTypeStatus: r.handleStatusPacket,
+	}
+	for _, packetType := range network.VariantPacketTypes {
+		handlers[packetType] = r.handleVariantPacket
+	}
+	for packetType, handler := range handlers {
+		if err := mux.Own(handlerOwner, packetType, handler); err != nil {
+			r.logger.Error("Failed to claim packet type", logger.Error(err))
+		}
+	}
+
+	bridgeTypes := append([]string{network.PacketTypePoll, network.PacketTypeData}, network.VariantPacketTypes...)
+	mux.Subscribe("bridges", bridgeTapPriority, bridgeTypes, r.tapBridgePacket)
+}
+
+// tapBridgePacket hands packets from linked reflectors to their bridge. Poll
+// replies and version replies end there; data frames go on to
+// handleDataPacket, which forwards them like any bridge stream.
+func (r *Reflector) tapBridgePacket(packet *network.Packet) bool {
+	r.bridgeManager.HandleIncomingPacket(packet.Data, packet.Source)
+	if !r.bridgeManager.IsBridgeAddress(packet.Source) {
+		return false
+	}
+	return packet.Type != network.PacketTypeData
 }
 
 // handlePollPacket handles YSFP (poll) packets
@@ -252,15 +797,14 @@ func (r *Reflector) handlePollPacket(packet *network.Packet) error {
 		logger.String("callsign", packet.Callsign),
 		logger.String("source", packet.Source.String()))
 
-	// Check if this packet is from a bridge connection
-	r.bridgeManager.HandleIncomingPacket(packet.Data, packet.Source)
-
-	// Don't treat bridge connections as repeaters - bridges handle their own responses
-	if r.bridgeManager.IsBridgeAddress(packet.Source) {
-		r.logger.Debug("Ignoring poll from bridge connection",
-			logger.String("callsign", packet.Callsign),
-			logger.String("source", packet.Source.String()))
-		return nil
+	// Without the room password the poll goes unanswered
+	if r.password != nil {
+		if ok, reason := r.password.checkPoll(packet, time.Now()); !ok {
+			if reason != passwordMissing || !r.admitPortChange(packet) {
+				r.rejectPassword(packet, reason)
+				return nil
+			}
+		}
 	}
 
 	// Add or update repeater
@@ -286,6 +830,7 @@ func (r *Reflector) handlePollPacket(packet *network.Packet) error {
 
 	// Process packet for statistics
 	r.repeaterManager.ProcessPacket(packet.Callsign, packet.Source, packet.Type, len(packet.Data))
+	r.repeaterManager.ObservePoll(packet.Source, packet.Data)
 
 	// Send poll response
 	response := network.CreatePollResponse()
@@ -297,6 +842,11 @@ func (r *Reflector) handlePollPacket(packet *network.Packet) error {
 	}
 
 	r.repeaterManager.ProcessTransmit(packet.Source, len(response))
+
+	// Greet new repeaters once they have their poll response
+	if isNew && r.welcome != nil {
+		r.sendWelcome(rep)
+	}
 	return nil
 }
 
@@ -315,24 +865,40 @@ func (r *Reflector) handleDataPacket(packet *network.Packet) error {
 		logger.String("addr", packet.Source.String()),
 		logger.Uint32("sequence", packet.GetSequence()))
 
-	// Check if this packet is from a bridge connection
-	r.bridgeManager.HandleIncomingPacket(packet.Data, packet.Source)
-
-	// Handle bridge data packets differently
+	// Handle bridge data packets differently; tapBridgePacket has already
+	// handed them to their bridge
 	if r.bridgeManager.IsBridgeAddress(packet.Source) {
 		r.logger.Debug("Received data from bridge connection",
 			logger.String("gateway", packet.Callsign),
 			logger.String("source_cs", effectiveCallsign),
 			logger.String("addr", packet.Source.String()))
 
+		// A bridge stream is subject to the same single-active-talker rule as
+		// local repeaters; frames that lose arbitration are not forwarded
+		bridgeName := r.getBridgeNameByAddress(packet.Source.String())
+		if !r.repeaterManager.ClaimBridgeStream(packet.Source, effectiveCallsign, packet.Callsign, bridgeName) {
+			r.logger.Debug("Dropping bridge data while another stream holds the channel",
+				logger.String("source_cs", effectiveCallsign),
+				logger.String("bridge", bridgeName))
+			return nil
+		}
+
 		// Track bridge talker activity
-		r.processBridgeTalker(packet)
+		r.processBridgeTalker(packet, bridgeName)
+
+		// A terminator ends the stream without waiting for the talk timeout;
+		// the terminator itself is still forwarded below
+		if packet.IsTerminator() {
+			r.repeaterManager.ReleaseBridgeStream(packet.Source)
+		}
 
 		// Sanitize callsigns before forwarding to local repeaters
 		sanitizedData := network.SanitizeDataPacket(packet.Data)
+		r.trackStream(sanitizedData, packet.Source, effectiveCallsign, true, packet.IsTerminator())
 
 		// Forward bridge data to all local repeaters (bridge acts as special repeater)
-		addresses := r.repeaterManager.GetAllAddresses()
+		// except members of no-bridge groups
+		addresses := r.repeaterManager.BridgedAddresses()
 		if len(addresses) > 0 {
 			if err := r.server.BroadcastData(sanitizedData, addresses, packet.Source); err != nil {
 				r.logger.Error("Failed to forward bridge data to repeaters",
@@ -366,6 +932,45 @@ func (r *Reflector) handleDataPacket(packet *network.Packet) error {
 	// Process packet for statistics and state tracking using the effective callsign
 	r.repeaterManager.ProcessPacket(effectiveCallsign, packet.Source, packet.Type, len(packet.Data))
 
+	// Drop traffic from callsigns muted by policy (e.g. anti-kerchunk)
+	if r.repeaterManager.IsCallsignMuted(effectiveCallsign) {
+		r.logger.Debug("Dropping data from policy-muted callsign",
+			logger.String("source_cs", effectiveCallsign),
+			logger.String("addr", packet.Source.String()))
+		return nil
+	}
+
+	// Drop traffic from listen-only callsigns (counted by the manager)
+	if r.repeaterManager.IsListenOnly(effectiveCallsign) {
+		r.logger.Debug("Dropping data from listen-only callsign",
+			logger.String("source_cs", effectiveCallsign),
+			logger.String("addr", packet.Source.String()))
+		return nil
+	}
+
+	// During a net control hold only cleared callsigns are forwarded
+	// (counted by the manager)
+	if r.repeaterManager.IsHeld(effectiveCallsign) {
+		r.logger.Debug("Dropping data during net control hold",
+			logger.String("source_cs", effectiveCallsign),
+			logger.String("addr", packet.Source.String()))
+		return nil
+	}
+
+	// Only the stream holding the channel is forwarded; a doubling local
+	// repeater, a muted one, or any repeater during a bridge stream is dropped
+	if !r.repeaterManager.HoldsChannel(packet.Source) {
+		r.logger.Debug("Dropping data while another stream holds the channel",
+			logger.String("source_cs", effectiveCallsign),
+			logger.String("addr", packet.Source.String()))
+		return nil
+	}
+
+	// Track per-transmission quality (frame loss, gaps, FICH errors)
+	seq, hasSeq := packet.FrameCounter()
+	fich, fichOK := packet.FICH()
+	r.repeaterManager.RecordFrame(packet.Source, seq, hasSeq, fichOK, packet.Timestamp)
+
 	// Sanitize callsigns in the packet before broadcasting
 	sanitizedData := network.SanitizeDataPacket(packet.Data)
 
@@ -379,21 +984,29 @@ func (r *Reflector) handleDataPacket(packet *network.Packet) error {
 	}
 
 	// Update transmit statistics for all recipients
+	recipients := 0
 	for _, addr := range addresses {
 		if addr.String() != packet.Source.String() {
 			r.repeaterManager.ProcessTransmit(addr, len(packet.Data))
+			recipients++
 		}
 	}
 
 	// Forward local repeater traffic to all bridges (bidirectional bridge forwarding)
+	// unless the repeater is in a no-bridge group.
 	// Use already sanitized data to avoid sending suffixes to bridges
-	r.forwardToBridges(sanitizedData, effectiveCallsign)
+	if !r.repeaterManager.IsNoBridge(rep.Callsign()) {
+		recipients += r.forwardToBridges(sanitizedData, effectiveCallsign)
+	}
+	r.repeaterManager.RecordOrigin(packet.Source, fich.DGID, fichOK, packet.DestCS, recipients)
+	r.trackStream(sanitizedData, packet.Source, effectiveCallsign, false, packet.IsTerminator())
 
 	return nil
 }
 
-// processBridgeTalker tracks bridge talker state and sends events
-func (r *Reflector) processBridgeTalker(packet *network.Packet) {
+// processBridgeTalker tracks bridge talker state and sends events.
+// bridgeName is the configured bridge the packet arrived on, or empty if unknown.
+func (r *Reflector) processBridgeTalker(packet *network.Packet, bridgeName string) {
 	// Use source callsign if available (actual transmitter), otherwise gateway callsign
 	effectiveCallsign := packet.Callsign
 	if packet.SourceCS != "" {
@@ -418,8 +1031,6 @@ func (r *Reflector) processBridgeTalker(packet *network.Packet) {
 		return
 	}
 
-	// Get bridge name for this address
-	bridgeName := r.getBridgeNameByAddress(packet.Source.String())
 	if bridgeName == "" {
 		bridgeName = "bridge:" + packet.Source.String()
 		r.logger.Info("processBridgeTalker: no bridge name found, using fallback",
@@ -429,9 +1040,18 @@ func (r *Reflector) processBridgeTalker(packet *network.Packet) {
 			logger.String("bridge_name", bridgeName))
 	}
 
+	// Remember who last used this link for the bridge status
+	if b := r.bridgeManager.GetBridge(bridgeName); b != nil {
+		b.RecordTalker(effectiveCallsign)
+	}
+
 	talkerKey := effectiveCallsign + ":" + bridgeName
 	sequence := packet.GetSequence()
 	now := time.Now()
+	frameSeq, hasFrameSeq := packet.FrameCounter()
+	fich, fichOK := packet.FICH()
+	terminator := packet.IsTerminator()
+	recipients := len(r.repeaterManager.BridgedAddresses())
 
 	r.talkersMu.Lock()
 	defer r.talkersMu.Unlock()
@@ -442,31 +1062,43 @@ func (r *Reflector) processBridgeTalker(packet *network.Packet) {
 		logger.Any("exists", exists),
 		logger.Int("total_bridge_talkers", len(r.bridgeTalkers)))
 
+	if !exists && terminator {
+		// A repeated terminator of a stream that has already ended
+		return
+	}
+
 	if !exists {
 		// New talker
 		talker = &bridgeTalker{
 			callsign:     effectiveCallsign,
 			bridgeName:   bridgeName,
 			bridgeAddr:   packet.Source.String(),
+			gateway:      packet.Callsign,
 			startTime:    now,
 			lastSeen:     now,
 			isTalking:    true,
 			lastSequence: sequence,
+			quality:      repeater.NewStreamQuality(),
+			origin:       repeater.NewOriginTracker(repeater.SourceBridge),
 		}
+		talker.quality.Record(frameSeq, hasFrameSeq, fichOK, packet.Timestamp)
+		talker.origin.Record(fich.DGID, fichOK, packet.DestCS, recipients)
 		r.bridgeTalkers[talkerKey] = talker
 
 		// Send talk start event
 		r.logger.Info("processBridgeTalker: new talker detected",
 			logger.String("callsign", effectiveCallsign),
 			logger.String("bridge", bridgeName),
+			logger.String("gateway", packet.Callsign),
 			logger.String("addr", packet.Source.String()),
 			logger.Uint32("sequence", sequence))
 
-		r.sendBridgeEvent(repeater.EventTalkStart, effectiveCallsign, bridgeName, 0)
+		r.sendBridgeEvent(repeater.EventTalkStart, effectiveCallsign, bridgeName, packet.Callsign, 0, nil, nil)
 
 		r.logger.Info("Bridge talker started",
 			logger.String("callsign", effectiveCallsign),
-			logger.String("bridge", bridgeName))
+			logger.String("bridge", bridgeName),
+			logger.String("gateway", packet.Callsign))
 	} else {
 		// Existing talker - update last seen
 		r.logger.Info("processBridgeTalker: existing talker update",
@@ -476,6 +1108,16 @@ func (r *Reflector) processBridgeTalker(packet *network.Packet) {
 			logger.Uint32("last_sequence", talker.lastSequence))
 		talker.lastSeen = now
 		talker.lastSequence = sequence
+		if talker.gateway == "" {
+			talker.gateway = packet.Callsign
+		}
+		talker.quality.Record(frameSeq, hasFrameSeq, fichOK, packet.Timestamp)
+		talker.origin.Record(fich.DGID, fichOK, packet.DestCS, recipients)
+	}
+
+	// End the talker on its terminator frame rather than waiting for the timeout
+	if terminator {
+		r.endBridgeTalker(talkerKey, talker, now)
 	}
 }
 
@@ -536,7 +1178,7 @@ func (r *Reflector) getBridgeNameByAddress(addr string) (result string) {
 }
 
 // sendBridgeEvent sends an event to the event channel for bridge activities
-func (r *Reflector) sendBridgeEvent(eventType, callsign, bridgeIdentifier string, duration time.Duration) {
+func (r *Reflector) sendBridgeEvent(eventType, callsign, bridgeIdentifier, gateway string, duration time.Duration, quality *repeater.QualityReport, origin *repeater.TalkOrigin) {
 	if r.eventChan == nil {
 		r.logger.Warn("sendBridgeEvent: eventChan is nil",
 			logger.String("event_type", eventType),
@@ -551,12 +1193,17 @@ func (r *Reflector) sendBridgeEvent(eventType, callsign, bridgeIdentifier string
 		Address:   bridgeIdentifier, // Use bridge name/identifier as address
 		Timestamp: time.Now(),
 		Duration:  duration,
+		Quality:   quality,
+		Origin:    origin,
+		Gateway:   gateway,
+		Bridge:    bridgeIdentifier,
 	}
 
 	r.logger.Info("sendBridgeEvent: attempting to send",
 		logger.String("event_type", eventType),
 		logger.String("callsign", callsign),
 		logger.String("bridge", bridgeIdentifier),
+		logger.String("gateway", gateway),
 		logger.Int("channel_len", len(r.eventChan)),
 		logger.Int("channel_cap", cap(r.eventChan)))
 
@@ -583,7 +1230,7 @@ func (r *Reflector) sendBridgeEvent(eventType, callsign, bridgeIdentifier string
 
 // cleanupBridgeTalkers periodically checks for inactive bridge talkers
 func (r *Reflector) cleanupBridgeTalkers(ctx context.Context) {
-	ticker := time.NewTicker(5 * time.Second) // Check every 5 seconds
+	ticker := time.NewTicker(time.Second)
 	defer ticker.Stop()
 
 	for {
@@ -599,29 +1246,52 @@ func (r *Reflector) cleanupBridgeTalkers(ctx context.Context) {
 // checkBridgeTalkerTimeouts checks for bridge talkers that have timed out
 func (r *Reflector) checkBridgeTalkerTimeouts() {
 	now := time.Now()
-	talkTimeout := 3 * time.Second // Consider talkers inactive after 3 seconds of no packets (matches repeater timeout)
+	talkTimeout := r.bridgeTalkTimeout()
 
 	r.talkersMu.Lock()
 	defer r.talkersMu.Unlock()
 
 	for key, talker := range r.bridgeTalkers {
 		if talker.isTalking && now.Sub(talker.lastSeen) > talkTimeout {
-			// Talker has timed out
-			duration := now.Sub(talker.startTime)
-			talker.isTalking = false
+			r.endBridgeTalker(key, talker, now)
+		}
+	}
+}
 
-			// Send talk end event
-			r.sendBridgeEvent(repeater.EventTalkEnd, talker.callsign, talker.bridgeName, duration)
+// bridgeTalkTimeout returns how long a bridge talker may go without frames
+func (r *Reflector) bridgeTalkTimeout() time.Duration {
+	if r.config.Server.BridgeTalkTimeout > 0 {
+		return r.config.Server.BridgeTalkTimeout
+	}
+	return 3 * time.Second
+}
 
-			r.logger.Info("Bridge talker ended",
-				logger.String("callsign", talker.callsign),
-				logger.String("bridge", talker.bridgeName),
-				logger.Duration("duration", duration))
+// endBridgeTalker sends talk_end for a bridge talker and stops tracking it.
+// The caller must hold talkersMu.
+func (r *Reflector) endBridgeTalker(key string, talker *bridgeTalker, now time.Time) {
+	duration := now.Sub(talker.startTime)
+	talker.isTalking = false
 
-			// Remove from active talkers
-			delete(r.bridgeTalkers, key)
-		}
+	// Send talk end event
+	quality := talker.quality.Report()
+	origin := talker.origin.Origin()
+	r.sendBridgeEvent(repeater.EventTalkEnd, talker.callsign, talker.bridgeName, talker.gateway, duration, &quality, &origin)
+
+	// Loss on the link shows in the bridge status, apart from local loss
+	if b := r.bridgeManager.GetBridge(talker.bridgeName); b != nil {
+		b.RecordTransmission(quality)
 	}
+
+	r.logger.Info("Bridge talker ended",
+		logger.String("callsign", talker.callsign),
+		logger.String("bridge", talker.bridgeName),
+		logger.String("gateway", talker.gateway),
+		logger.Duration("duration", duration),
+		logger.Any("loss_percent", quality.LossPercent),
+		logger.Any("missing_percent", quality.MissingPercent))
+
+	// Remove from active talkers
+	delete(r.bridgeTalkers, key)
 }
 
 // GetCurrentBridgeTalker returns the currently active bridge talker, if any
@@ -638,14 +1308,76 @@ func (r *Reflector) GetCurrentBridgeTalker() interface{} {
 	return nil
 }
 
+// ProbeRepeater measures round-trip time and jitter to a connected repeater
+// by sending it polls and timing the packets it sends back
+func (r *Reflector) ProbeRepeater(ctx context.Context, callsign string, count int) (repeater.ProbeResult, error) {
+	send := func(data []byte, addr *net.UDPAddr) error {
+		if err := r.server.SendPacket(data, addr); err != nil {
+			return err
+		}
+		r.repeaterManager.ProcessTransmit(addr, len(data))
+		return nil
+	}
+	return r.repeaterManager.Probe(ctx, callsign, repeater.ProbeOptions{Count: count}, network.CreatePollResponse(), send)
+}
+
+// listenerRestarted reports a recreated UDP socket as an event. Repeaters
+// stay connected; they are only dropped if they time out while it was down.
+func (r *Reflector) listenerRestarted(restart network.ListenerRestart) {
+	event := repeater.Event{
+		Type:      repeater.EventListenerRestarted,
+		Timestamp: time.Now(),
+		Data: map[string]interface{}{
+			"address":     restart.Address,
+			"cause":       restart.Cause,
+			"attempts":    restart.Attempts,
+			"downtime_ms": restart.Downtime.Milliseconds(),
+		},
+	}
+	select {
+	case r.eventChan <- event:
+	default:
+		r.logger.Warn("Event channel full, dropping listener restart event")
+	}
+}
+
+// StartPacketTrace traces the packets from and to a repeater for d. The
+// target is the callsign of a connected repeater or a host:port address.
+func (r *Reflector) StartPacketTrace(target string, d time.Duration) (network.TraceInfo, error) {
+	return r.server.StartTrace(r.traceAddress(target), d)
+}
+
+// StopPacketTrace ends the trace on a repeater callsign or address
+func (r *Reflector) StopPacketTrace(target string) bool {
+	return r.server.StopTrace(r.traceAddress(target))
+}
+
+// PacketTraces returns the active packet traces
+func (r *Reflector) PacketTraces() []network.TraceInfo {
+	return r.server.Traces()
+}
+
+// traceAddress resolves a trace target: a connected repeater's callsign
+// becomes its address, anything else is taken as an address
+func (r *Reflector) traceAddress(target string) string {
+	if rep := r.repeaterManager.FindByCallsign(target); rep != nil {
+		return rep.Address().String()
+	}
+	return target
+}
+
 // handleUnlinkPacket handles YSFU (unlink) packets
 func (r *Reflector) handleUnlinkPacket(packet *network.Packet) error {
 	r.logger.Info("Received unlink packet",
 		logger.String("callsign", packet.Callsign),
 		logger.String("source", packet.Source.String()))
 
+	if r.password != nil {
+		r.password.forget(packet.Source)
+	}
+
 	// Remove repeater
-	if r.repeaterManager.RemoveRepeater(packet.Source) {
+	if r.repeaterManager.RemoveRepeater(packet.Source, repeater.DisconnectUnlink) {
 		r.logger.Info("Repeater unlinked",
 			logger.String("callsign", packet.Callsign),
 			logger.String("source", packet.Source.String()))
@@ -668,19 +1400,34 @@ func (r *Reflector) handleStatusPacket(packet *network.Packet) error {
 	r.logger.Info("Received status request",
 		logger.String("source", packet.Source.String()),
 		logger.String("callsign", packet.Callsign))
+	r.repeaterManager.ObserveStatusRequest(packet.Source)
+
+	replies := r.config.Server.StatusReplies
+	if !r.statusReplyAllowed(packet.Source) {
+		r.logger.Debug("Status request not answered",
+			logger.String("source", packet.Source.String()),
+			logger.String("mode", replies.Mode))
+		return nil
+	}
 
 	// Create status response
 	count := r.repeaterManager.Count()
-	response := network.CreateStatusResponse(
+	description := r.config.Server.Description
+	if replies.Reduced {
+		count = 0
+		description = ""
+	}
+	response := network.CreateStatusResponseWithID(
+		r.config.Server.ReflectorID,
 		r.config.Server.Name,
-		r.config.Server.Description,
+		description,
 		count,
 	)
 
 	r.logger.Debug("Sending status response",
 		logger.String("source", packet.Source.String()),
 		logger.String("name", r.config.Server.Name),
-		logger.String("description", r.config.Server.Description),
+		logger.String("description", description),
 		logger.Int("count", count),
 		logger.Int("response_size", len(response)))
 
@@ -698,14 +1445,39 @@ func (r *Reflector) handleStatusPacket(packet *network.Packet) error {
 	return nil
 }
 
+// statusReplyAllowed applies the status reply mode to the source of a status request
+func (r *Reflector) statusReplyAllowed(source *net.UDPAddr) bool {
+	replies := r.config.Server.StatusReplies
+	switch replies.Mode {
+	case config.StatusRepliesOff:
+		return false
+	case config.StatusRepliesKnown:
+		if replies.Allows(source.IP) || r.bridgeManager.IsBridgeAddress(source) {
+			return true
+		}
+		for _, rp := range r.repeaterManager.GetAllRepeaters() {
+			if rp.Address().IP.Equal(source.IP) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
 // processEvents was removed because events are forwarded/consumed elsewhere. If needed,
 // reintroduce with careful event channel ownership semantics.
 
 // forwardToBridges forwards local repeater traffic to all connected bridges
-func (r *Reflector) forwardToBridges(data []byte, callsign string) {
-	// Get bridge addresses from bridge manager
-	bridgeAddresses := r.bridgeManager.GetConnectedAddresses()
-
+// except receive-only ones and those routing other callsigns. It returns the
+// number of bridges the data was sent to.
+func (r *Reflector) forwardToBridges(data []byte, callsign string) int {
+	// Get bridge addresses from bridge manager; bridges with callsign routes
+	// only take traffic from their listed callsigns
+	bridgeAddresses := r.bridgeManager.GetForwardAddressesFor(callsign)
+
+	sent := 0
 	if len(bridgeAddresses) > 0 {
 		// Forward data to all connected bridges
 		for _, addr := range bridgeAddresses {
@@ -715,6 +1487,7 @@ func (r *Reflector) forwardToBridges(data []byte, callsign string) {
 					logger.String("bridge", addr.String()),
 					logger.Error(err))
 			} else {
+				sent++
 				r.logger.Debug("Forwarded local data to bridge",
 					logger.String("callsign", callsign),
 					logger.String("bridge", addr.String()))
@@ -725,6 +1498,7 @@ func (r *Reflector) forwardToBridges(data []byte, callsign string) {
 			logger.String("callsign", callsign),
 			logger.Int("bridges", len(bridgeAddresses)))
 	}
+	return sent
 }
 
 // logStats periodically logs statistics
@@ -0,0 +1,31 @@
+package reflector
+
+import (
+	"fmt"
+
+	"github.com/dbehnke/ysf-nexus/pkg/bridge"
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// ReloadBridges re-reads the bridge definitions from the config file and its
+// bridge includes and applies them without a restart. Other settings are not
+// reloaded; changes to them are logged as pending a restart.
+func (r *Reflector) ReloadBridges() (bridge.ReloadResult, error) {
+	if r.config.File == "" {
+		return bridge.ReloadResult{}, fmt.Errorf("no config file to reload")
+	}
+
+	bridges, err := config.LoadBridges(r.config.File)
+	if err != nil {
+		r.logger.Error("Bridge reload failed; keeping current bridges", logger.Error(err))
+		return bridge.ReloadResult{}, err
+	}
+	result := r.bridgeManager.Reload(bridges)
+
+	r.mu.Lock()
+	r.reloadedBridges = bridges
+	r.mu.Unlock()
+	r.logPendingRestart()
+	return result, nil
+}
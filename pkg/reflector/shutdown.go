@@ -0,0 +1,93 @@
+package reflector
+
+import (
+	"net"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/network"
+)
+
+// Shutdown drains in-flight traffic before bridges go away: the stream holding
+// the channel gets up to server.drain_timeout to finish, after which it is
+// ended with a synthesized terminator so receivers don't hang on a stream that
+// never closes. Only then are the bridges stopped, which sends their YSFU.
+
+// drainPollInterval is how often the drain checks whether the channel is free
+const drainPollInterval = 50 * time.Millisecond
+
+// inFlightStream is the last forwarded frame of the stream holding the channel
+type inFlightStream struct {
+	frame      []byte
+	source     *net.UDPAddr
+	callsign   string
+	fromBridge bool
+}
+
+// trackStream records the last forwarded frame of a stream, or forgets it
+// once its terminator has been forwarded
+func (r *Reflector) trackStream(data []byte, source *net.UDPAddr, callsign string, fromBridge, terminator bool) {
+	r.streamMu.Lock()
+	defer r.streamMu.Unlock()
+
+	if terminator {
+		r.inFlight = nil
+		return
+	}
+	frame := make([]byte, len(data))
+	copy(frame, data)
+	r.inFlight = &inFlightStream{frame: frame, source: source, callsign: callsign, fromBridge: fromBridge}
+}
+
+// drain waits up to timeout for the channel to become free, terminates a
+// stream still in flight, then stops the bridges. It runs while the network
+// server is still up so the terminator and unlink packets can be sent.
+func (r *Reflector) drain(timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for r.streamInFlight() && time.Now().Before(deadline) {
+		time.Sleep(drainPollInterval)
+	}
+
+	if r.streamInFlight() {
+		r.terminateInFlight()
+	}
+
+	r.bridgeManager.Shutdown(timeout)
+}
+
+// streamInFlight reports whether a stream holds the channel and has not yet
+// forwarded its terminator
+func (r *Reflector) streamInFlight() bool {
+	r.streamMu.Lock()
+	tracked := r.inFlight != nil
+	r.streamMu.Unlock()
+	return tracked && r.repeaterManager.ChannelBusy()
+}
+
+// terminateInFlight sends a terminator for the stream holding the channel to
+// everyone it was being forwarded to
+func (r *Reflector) terminateInFlight() {
+	r.streamMu.Lock()
+	stream := r.inFlight
+	r.inFlight = nil
+	r.streamMu.Unlock()
+
+	if stream == nil {
+		return
+	}
+	terminator := network.CreateTerminator(stream.frame)
+	if terminator == nil {
+		return
+	}
+
+	r.logger.Info("Terminating in-flight stream for shutdown",
+		logger.String("source", stream.source.String()),
+		logger.Any("from_bridge", stream.fromBridge))
+
+	if err := r.server.BroadcastData(terminator, r.repeaterManager.GetAllAddresses(), stream.source); err != nil {
+		r.logger.Warn("Failed to send terminator to repeaters", logger.Error(err))
+	}
+	if !stream.fromBridge && r.bridgeable(stream.source) {
+		r.forwardToBridges(terminator, stream.callsign)
+	}
+}
@@ -0,0 +1,115 @@
+package reflector
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/network"
+)
+
+func TestShutdownTerminatesInFlightStream(t *testing.T) {
+	free, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	port := free.LocalAddr().(*net.UDPAddr).Port
+	_ = free.Close()
+
+	cfg := &config.Config{Server: config.ServerConfig{
+		Host:              "127.0.0.1",
+		Port:              port,
+		Timeout:           time.Minute,
+		MaxConnections:    10,
+		TalkMaxDuration:   time.Minute,
+		BridgeTalkTimeout: 3 * time.Second,
+		DrainTimeout:      200 * time.Millisecond,
+	}}
+	r := New(cfg, logger.NewTestLogger(os.Stdout))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- r.Start(ctx) }()
+	defer cancel()
+
+	server := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: port}
+	talker := dialClient(t, server, "TALKER")
+	listener := dialClient(t, server, "LISTENER")
+
+	// One voice frame of a transmission that never sends its terminator
+	data := make([]byte, network.DataPacketSize)
+	copy(data[0:4], network.PacketTypeData)
+	copy(data[4:14], fmt.Sprintf("%-10s", "TALKER"))
+	copy(data[14:24], fmt.Sprintf("%-10s", "W1AW"))
+	copy(data[24:34], fmt.Sprintf("%-10s", "ALL"))
+	data[network.FrameCounterOffset] = 1 << 1
+	network.EncodeFICH(network.FICH{FI: network.FICommunications, FN: 1, FT: 6}, data[network.FrameOffset:network.FrameOffset+network.FrameSize])
+	if _, err := talker.Write(data); err != nil {
+		t.Fatalf("send data: %v", err)
+	}
+	readData(t, listener, false)
+
+	cancel()
+	readData(t, listener, true)
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected a clean shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("reflector did not stop")
+	}
+}
+
+// dialClient connects a repeater to the reflector and waits for its poll reply
+func dialClient(t *testing.T, server *net.UDPAddr, callsign string) *net.UDPConn {
+	t.Helper()
+	conn, err := net.DialUDP("udp", nil, server)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	poll := []byte(fmt.Sprintf("%s%-10s", network.PacketTypePoll, callsign))
+	buf := make([]byte, 512)
+	for attempt := 0; attempt < 20; attempt++ {
+		if _, err := conn.Write(poll); err != nil {
+			t.Fatalf("send poll: %v", err)
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		if n, err := conn.Read(buf); err == nil && string(buf[:4]) == network.PacketTypePoll && n == network.PollPacketSize {
+			return conn
+		}
+		// The reflector may still be starting
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("no poll reply for %s", callsign)
+	return nil
+}
+
+// readData reads the next data packet and checks whether it is a terminator
+func readData(t *testing.T, conn *net.UDPConn, terminator bool) {
+	t.Helper()
+	buf := make([]byte, 512)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatalf("read data: %v", err)
+		}
+		p, err := network.ParsePacket(buf[:n], conn.LocalAddr().(*net.UDPAddr))
+		if err != nil || !p.IsDataPacket() {
+			continue
+		}
+		if p.IsTerminator() != terminator {
+			t.Fatalf("expected terminator=%v, got %v", terminator, p.IsTerminator())
+		}
+		return
+	}
+}
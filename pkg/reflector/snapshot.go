@@ -0,0 +1,106 @@
+package reflector
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/bridge"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+	"github.com/dbehnke/ysf-nexus/pkg/version"
+	"github.com/dbehnke/ysf-nexus/pkg/web"
+)
+
+// Snapshot is a point-in-time view of the reflector for external monitoring
+// scripts and static dashboards
+type Snapshot struct {
+	GeneratedAt time.Time                      `json:"generated_at"`
+	Name        string                         `json:"name"`
+	Description string                         `json:"description"`
+	Version     string                         `json:"version"`
+	Build       version.Info                   `json:"build"`
+	Stats       *Stats                         `json:"stats"`
+	Repeaters   []repeater.RepeaterStats       `json:"repeaters"`
+	Bridges     map[string]bridge.BridgeStatus `json:"bridges"`
+	TalkLog     []web.TalkLogEntry             `json:"talk_log"`
+}
+
+// Snapshot returns the current reflector state including the last tail talk log entries
+func (r *Reflector) Snapshot(tail int) Snapshot {
+	repeaters := r.repeaterManager.GetAllRepeaters()
+	stats := make([]repeater.RepeaterStats, 0, len(repeaters))
+	for _, rep := range repeaters {
+		stats = append(stats, rep.Stats())
+	}
+
+	talkLog := r.webServer.RecentTalkLogs(tail)
+
+	return Snapshot{
+		GeneratedAt: time.Now(),
+		Name:        r.config.Server.Name,
+		Description: r.config.Server.Description,
+		Version:     r.version,
+		Build:       r.BuildInfo(),
+		Stats:       r.GetStats(),
+		Repeaters:   stats,
+		Bridges:     r.bridgeManager.GetStatus(),
+		TalkLog:     talkLog,
+	}
+}
+
+// writeSnapshot writes the snapshot to path, replacing it atomically
+func (r *Reflector) writeSnapshot(path string, tail int) error {
+	data, err := json.MarshalIndent(r.Snapshot(tail), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file and rename so readers never see a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".snapshot-*.json")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	// CreateTemp uses 0600; the snapshot is meant to be read by other tools
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// runSnapshots writes the stats snapshot every interval and once more on shutdown
+func (r *Reflector) runSnapshots(ctx context.Context) {
+	sc := r.config.Snapshot
+	ticker := time.NewTicker(sc.Interval)
+	defer ticker.Stop()
+
+	r.logger.Info("Stats snapshot enabled",
+		logger.String("path", sc.Path),
+		logger.Duration("interval", sc.Interval))
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := r.writeSnapshot(sc.Path, sc.TalkLogTail); err != nil {
+				r.logger.Error("Failed to write stats snapshot", logger.Error(err))
+			}
+			return
+		case <-ticker.C:
+			if err := r.writeSnapshot(sc.Path, sc.TalkLogTail); err != nil {
+				r.logger.Error("Failed to write stats snapshot", logger.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package reflector
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+func TestWriteSnapshot(t *testing.T) {
+	cfg := &config.Config{Server: config.ServerConfig{Name: "Test Reflector", Port: 42000, MaxConnections: 10}}
+	r := New(cfg, logger.NewTestLogger(os.Stdout))
+	r.repeaterManager.AddRepeater("W1AW", &net.UDPAddr{IP: net.ParseIP("192.168.1.10"), Port: 42000})
+
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := r.writeSnapshot(path, 10); err != nil {
+		t.Fatalf("write snapshot: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read snapshot: %v", err)
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		t.Fatalf("parse snapshot: %v", err)
+	}
+
+	if snap.Name != "Test Reflector" || len(snap.Repeaters) != 1 || snap.Repeaters[0].Callsign != "W1AW" {
+		t.Errorf("unexpected snapshot: %+v", snap)
+	}
+	if snap.Stats == nil || snap.TalkLog == nil {
+		t.Errorf("expected stats and an empty talk log, got %+v", snap)
+	}
+
+	// Only the final file remains
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("expected only the snapshot file, found %d entries", len(entries))
+	}
+}
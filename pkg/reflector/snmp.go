@@ -0,0 +1,92 @@
+package reflector
+
+import (
+	"context"
+	"net"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/snmp"
+)
+
+// setupSNMP creates the SNMP agent for legacy monitoring systems
+func (r *Reflector) setupSNMP() {
+	sc := r.config.SNMP
+	agent, err := snmp.New(snmp.Options{
+		Listen:    sc.Listen,
+		Community: sc.Community,
+		BaseOID:   sc.BaseOID,
+	}, r.snmpValues, r.logger)
+	if err != nil {
+		r.logger.Error("Invalid SNMP configuration", logger.Error(err))
+		return
+	}
+	r.snmpAgent = agent
+}
+
+// listenSNMP binds the SNMP agent's socket, taking over the one a previous
+// process handed over if there is one
+func (r *Reflector) listenSNMP() (*net.UDPConn, error) {
+	conn, err := r.inherited.ListenUDP(r.config.SNMP.Listen)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.snmpConn = conn
+	r.mu.Unlock()
+	return conn, nil
+}
+
+// runSNMP serves SNMP requests on conn until ctx is cancelled
+func (r *Reflector) runSNMP(ctx context.Context, conn *net.UDPConn) {
+	if err := r.snmpAgent.Serve(ctx, conn); err != nil {
+		r.logger.Error("SNMP agent error", logger.Error(err))
+	}
+}
+
+// snmpValues collects the values served by the SNMP agent
+func (r *Reflector) snmpValues() snmp.Values {
+	stats := r.GetStats()
+
+	v := snmp.Values{
+		Uptime:       stats.Uptime,
+		Repeaters:    stats.ActiveRepeaters,
+		ActiveTalker: r.activeTalker(stats),
+		BytesIn:      uint64(stats.BytesReceived),
+		BytesOut:     uint64(stats.BytesSent),
+	}
+	for _, n := range stats.PacketsReceived {
+		v.PacketsIn += uint64(n)
+	}
+	for _, n := range stats.PacketsSent {
+		v.PacketsOut += uint64(n)
+	}
+
+	for name, status := range r.bridgeManager.GetStatus() {
+		v.Bridges = append(v.Bridges, snmp.Bridge{
+			Name:      name,
+			State:     string(status.State),
+			PacketsRx: status.PacketsRx,
+			PacketsTx: status.PacketsTx,
+		})
+	}
+	return v
+}
+
+// activeTalker returns the callsign of the local or bridge station currently
+// talking, or "" when the channel is idle
+func (r *Reflector) activeTalker(stats *Stats) string {
+	for _, rep := range stats.RepeaterStats.Repeaters {
+		if rep.IsTalking {
+			return rep.Callsign
+		}
+	}
+
+	r.talkersMu.RLock()
+	defer r.talkersMu.RUnlock()
+	for _, talker := range r.bridgeTalkers {
+		if talker.isTalking {
+			return talker.callsign
+		}
+	}
+	return ""
+}
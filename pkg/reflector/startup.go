@@ -0,0 +1,37 @@
+package reflector
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Start brings the reflector up in phases: the network server first, since
+// bridges and handlers send through it, then the managers and background
+// tasks, then bridges and finally the web server. Each phase waits for its
+// component to report readiness before the next one starts.
+
+// componentStartTimeout bounds how long a startup phase waits for its component
+const componentStartTimeout = 10 * time.Second
+
+// awaitReady waits until ready is closed or the component exits. A component
+// that exits without an error during startup (e.g. a disabled web server) is
+// treated as ready. A nil ready channel waits for the exit alone.
+func awaitReady(ctx context.Context, name string, ready <-chan struct{}, exited <-chan error, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-ready:
+		return nil
+	case err := <-exited:
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("%s: not ready after %s", name, timeout)
+	case <-ctx.Done():
+		return fmt.Errorf("%s: %w", name, ctx.Err())
+	}
+}
@@ -0,0 +1,69 @@
+package reflector
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+func TestAwaitReady(t *testing.T) {
+	ctx := context.Background()
+
+	ready := make(chan struct{})
+	close(ready)
+	if err := awaitReady(ctx, "ready", ready, nil, time.Second); err != nil {
+		t.Errorf("expected ready component, got %v", err)
+	}
+
+	exited := make(chan error, 1)
+	exited <- errors.New("bind failed")
+	if err := awaitReady(ctx, "failed", nil, exited, time.Second); err == nil || !strings.Contains(err.Error(), "failed: bind failed") {
+		t.Errorf("expected exit error, got %v", err)
+	}
+
+	exited <- nil
+	if err := awaitReady(ctx, "disabled", nil, exited, time.Second); err != nil {
+		t.Errorf("expected a clean exit to count as ready, got %v", err)
+	}
+
+	if err := awaitReady(ctx, "slow", make(chan struct{}), nil, 10*time.Millisecond); err == nil || !strings.Contains(err.Error(), "not ready") {
+		t.Errorf("expected timeout, got %v", err)
+	}
+}
+
+func TestStartFailsWhenNetworkCannotBind(t *testing.T) {
+	taken, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer func() { _ = taken.Close() }()
+
+	cfg := &config.Config{Server: config.ServerConfig{
+		Host:           "127.0.0.1",
+		Port:           taken.LocalAddr().(*net.UDPAddr).Port,
+		Timeout:        time.Minute,
+		MaxConnections: 10,
+	}}
+	r := New(cfg, logger.NewTestLogger(os.Stdout))
+
+	done := make(chan error, 1)
+	go func() { done <- r.Start(context.Background()) }()
+	select {
+	case err := <-done:
+		if err == nil || !strings.HasPrefix(err.Error(), "network:") {
+			t.Errorf("expected network startup error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Start did not fail on a port in use")
+	}
+	if r.IsRunning() {
+		t.Errorf("reflector must not report running after a failed start")
+	}
+}
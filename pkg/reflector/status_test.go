@@ -0,0 +1,41 @@
+package reflector
+
+import (
+	"net"
+	"os"
+	"testing"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+func TestStatusReplyModes(t *testing.T) {
+	connected := &net.UDPAddr{IP: net.ParseIP("192.0.2.10"), Port: 42000}
+	allowed := &net.UDPAddr{IP: net.ParseIP("198.51.100.7"), Port: 42000}
+	stranger := &net.UDPAddr{IP: net.ParseIP("203.0.113.1"), Port: 42000}
+
+	tests := []struct {
+		mode string
+		want map[*net.UDPAddr]bool
+	}{
+		{"", map[*net.UDPAddr]bool{connected: true, allowed: true, stranger: true}},
+		{config.StatusRepliesKnown, map[*net.UDPAddr]bool{connected: true, allowed: true, stranger: false}},
+		{config.StatusRepliesOff, map[*net.UDPAddr]bool{connected: false, allowed: false, stranger: false}},
+	}
+
+	for _, tt := range tests {
+		cfg := &config.Config{Server: config.ServerConfig{
+			MaxConnections: 10,
+			StatusReplies:  config.StatusRepliesConfig{Mode: tt.mode, Allow: []string{"198.51.100.0/24"}},
+		}}
+		r := New(cfg, logger.NewTestLogger(os.Stdout))
+		// A status request from another port of a connected repeater's IP is known
+		r.repeaterManager.AddRepeater("W1AW", &net.UDPAddr{IP: connected.IP, Port: 42001})
+
+		for addr, want := range tt.want {
+			if got := r.statusReplyAllowed(addr); got != want {
+				t.Errorf("mode %q, %s: got %v, want %v", tt.mode, addr, got, want)
+			}
+		}
+	}
+}
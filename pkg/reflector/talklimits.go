@@ -0,0 +1,21 @@
+package reflector
+
+import (
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+// setupTalkLimits loads the per-callsign talk limit overrides
+func (r *Reflector) setupTalkLimits(limits []config.TalkLimitConfig) {
+	list := make([]repeater.TalkLimit, 0, len(limits))
+	for _, l := range limits {
+		list = append(list, repeater.TalkLimit{
+			Callsign:        l.Callsign,
+			TalkMaxDuration: l.TalkMaxDuration,
+			UnmuteAfter:     l.UnmuteAfter,
+		})
+	}
+	r.repeaterManager.SetTalkLimits(list)
+	r.logger.Info("Talk limit overrides loaded", logger.Int("callsigns", len(list)))
+}
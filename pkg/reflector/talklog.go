@@ -0,0 +1,73 @@
+package reflector
+
+import (
+	"context"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+	"github.com/dbehnke/ysf-nexus/pkg/talklog"
+)
+
+// setupTalkExport creates the talk log exporter for the configured destinations
+func (r *Reflector) setupTalkExport() {
+	tc := r.config.TalkExport
+
+	var sinks []talklog.Sink
+	if tc.CSVWebhook != "" {
+		sinks = append(sinks, talklog.NewCSVWebhook(tc.CSVWebhook))
+	}
+	if gs := tc.GoogleSheets; gs.SpreadsheetID != "" {
+		sheet, err := talklog.NewGoogleSheet(gs.SpreadsheetID, gs.Sheet, gs.CredentialsFile)
+		if err != nil {
+			r.logger.Error("Invalid Google Sheets talk export configuration", logger.Error(err))
+		} else {
+			sinks = append(sinks, sheet)
+		}
+	}
+	if len(sinks) == 0 {
+		return
+	}
+
+	r.talkExport = talklog.New(talklog.Options{
+		BatchSize:     tc.BatchSize,
+		FlushInterval: tc.FlushInterval,
+		RetryInterval: tc.RetryInterval,
+		MaxPending:    tc.MaxPending,
+		MinDuration:   tc.MinDuration,
+	}, sinks, r.logger)
+}
+
+// runTalkExport feeds talk_end events to the exporter until ctx is cancelled
+func (r *Reflector) runTalkExport(ctx context.Context) {
+	sub := r.eventBus.Subscribe(100, time.Time{})
+	defer sub.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.talkExport.Run(ctx)
+	}()
+	defer func() { <-done }()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if event.Type != repeater.EventTalkEnd {
+				continue
+			}
+			r.talkExport.Add(talklog.Record{
+				Time:     event.Timestamp,
+				Callsign: event.Callsign,
+				Duration: event.Duration,
+				Gateway:  event.Gateway,
+				Bridge:   event.Bridge,
+			})
+		}
+	}
+}
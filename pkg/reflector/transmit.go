@@ -0,0 +1,227 @@
+package reflector
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// The transmit scheduler plays streams the reflector originates itself, such
+// as announcements or parrot playback, without talking over anyone: queued
+// transmissions wait until the channel has been free for the quiet period,
+// then hold the channel while their frames are sent to local repeaters.
+
+// transmitPollInterval is how often the scheduler checks the channel
+const transmitPollInterval = 100 * time.Millisecond
+
+// transmitFrameInterval is the spacing of transmitted frames, the YSF frame rate
+const transmitFrameInterval = 100 * time.Millisecond
+
+// Transmission is a locally originated stream
+type Transmission struct {
+	Name   string   // Shown as the talker in logs and doublings
+	Frames [][]byte // YSFD frames, sent in order
+	Group  string   // Send to this repeater group only ("" = all repeaters)
+	// Address sends to this repeater only, such as the sender of a query (overrides Group)
+	Address *net.UDPAddr
+}
+
+// QueuedTransmission is a transmission waiting for the channel
+type QueuedTransmission struct {
+	ID     uint64    `json:"id"`
+	Name   string    `json:"name"`
+	Frames int       `json:"frames"`
+	Group  string    `json:"group,omitempty"`
+	Queued time.Time `json:"queued"`
+}
+
+// scheduledTransmission is a queue entry
+type scheduledTransmission struct {
+	Transmission
+	id     uint64
+	queued time.Time
+}
+
+// txScheduler holds the transmit queue
+type txScheduler struct {
+	quiet time.Duration
+	frame time.Duration
+
+	mu     sync.Mutex
+	queue  []*scheduledTransmission
+	nextID uint64
+	wake   chan struct{}
+}
+
+// newTxScheduler creates a transmit scheduler with the given quiet period
+func newTxScheduler(quiet time.Duration) *txScheduler {
+	return &txScheduler{
+		quiet: quiet,
+		frame: transmitFrameInterval,
+		wake:  make(chan struct{}, 1),
+	}
+}
+
+// QueueTransmission queues a locally originated stream. It is played once the
+// channel has been free for the quiet period; the returned ID identifies it
+// in PendingTransmissions.
+func (r *Reflector) QueueTransmission(tx Transmission) (uint64, error) {
+	if len(tx.Frames) == 0 {
+		return 0, fmt.Errorf("transmission has no frames")
+	}
+	if tx.Name == "" {
+		tx.Name = "REFLECTOR"
+	}
+	if tx.Group != "" {
+		if _, ok := r.repeaterManager.GroupAddresses(tx.Group); !ok {
+			return 0, fmt.Errorf("unknown group %q", tx.Group)
+		}
+	}
+
+	s := r.transmit
+	s.mu.Lock()
+	s.nextID++
+	id := s.nextID
+	s.queue = append(s.queue, &scheduledTransmission{Transmission: tx, id: id, queued: time.Now()})
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	r.logger.Info("Transmission queued",
+		logger.String("name", tx.Name),
+		logger.Int("frames", len(tx.Frames)),
+		logger.Uint64("id", id))
+	return id, nil
+}
+
+// PendingTransmissions returns the queued transmissions in play order
+func (r *Reflector) PendingTransmissions() []QueuedTransmission {
+	s := r.transmit
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := make([]QueuedTransmission, 0, len(s.queue))
+	for _, tx := range s.queue {
+		pending = append(pending, QueuedTransmission{
+			ID:     tx.id,
+			Name:   tx.Name,
+			Frames: len(tx.Frames),
+			Group:  tx.Group,
+			Queued: tx.queued,
+		})
+	}
+	return pending
+}
+
+// runTransmitScheduler plays queued transmissions until ctx is cancelled
+func (r *Reflector) runTransmitScheduler(ctx context.Context) {
+	s := r.transmit
+	ticker := time.NewTicker(transmitPollInterval)
+	defer ticker.Stop()
+
+	var idleSince time.Time
+	for {
+		s.mu.Lock()
+		var next *scheduledTransmission
+		if len(s.queue) > 0 {
+			next = s.queue[0]
+		}
+		s.mu.Unlock()
+
+		if next == nil {
+			// Nothing to play; the quiet period starts over for the next one
+			idleSince = time.Time{}
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.wake:
+			}
+			continue
+		}
+
+		now := time.Now()
+		switch {
+		case r.repeaterManager.ChannelBusy():
+			idleSince = time.Time{}
+		case idleSince.IsZero():
+			idleSince = now
+		case now.Sub(idleSince) >= s.quiet:
+			if r.playTransmission(ctx, next) {
+				s.mu.Lock()
+				s.queue = s.queue[1:]
+				s.mu.Unlock()
+			}
+			idleSince = time.Time{}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// playTransmission holds the channel and sends the frames of tx. It reports
+// whether tx was played; it is not if another stream took the channel first.
+func (r *Reflector) playTransmission(ctx context.Context, tx *scheduledTransmission) bool {
+	if !r.repeaterManager.ClaimReflectorStream(tx.Name) {
+		return false
+	}
+	defer r.repeaterManager.ReleaseReflectorStream(tx.Name)
+
+	r.logger.Info("Transmission started",
+		logger.String("name", tx.Name),
+		logger.Uint64("id", tx.id),
+		logger.Duration("waited", time.Since(tx.queued)))
+
+	for i, frame := range tx.Frames {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return true
+			case <-time.After(r.transmit.frame):
+			}
+		}
+		r.repeaterManager.ClaimReflectorStream(tx.Name)
+
+		addresses := r.transmitAddresses(tx.Transmission)
+		if len(addresses) == 0 {
+			continue
+		}
+		if err := r.server.BroadcastData(frame, addresses, nil); err != nil {
+			r.logger.Warn("Failed to send transmission frame",
+				logger.String("name", tx.Name),
+				logger.Error(err))
+			continue
+		}
+		for _, addr := range addresses {
+			r.repeaterManager.ProcessTransmit(addr, len(frame))
+		}
+	}
+
+	r.logger.Info("Transmission finished", logger.String("name", tx.Name), logger.Uint64("id", tx.id))
+	return true
+}
+
+// transmitAddresses returns the repeaters a transmission is sent to
+func (r *Reflector) transmitAddresses(tx Transmission) []*net.UDPAddr {
+	if tx.Address != nil {
+		if r.repeaterManager.GetRepeater(tx.Address) == nil {
+			return nil
+		}
+		return []*net.UDPAddr{tx.Address}
+	}
+	if tx.Group == "" {
+		return r.repeaterManager.GetAllAddresses()
+	}
+	addresses, _ := r.repeaterManager.GroupAddresses(tx.Group)
+	return addresses
+}
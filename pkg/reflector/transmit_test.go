@@ -0,0 +1,53 @@
+package reflector
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+func TestTransmissionWaitsForQuietChannel(t *testing.T) {
+	cfg := &config.Config{Server: config.ServerConfig{MaxConnections: 10, TransmitQuietPeriod: 200 * time.Millisecond}}
+	r := New(cfg, logger.NewTestLogger(io.Discard))
+	bridgeAddr := &net.UDPAddr{IP: net.ParseIP("192.0.2.10"), Port: 42000}
+
+	if _, err := r.QueueTransmission(Transmission{Name: "ID"}); err == nil {
+		t.Errorf("expected an error for a transmission without frames")
+	}
+	if _, err := r.QueueTransmission(Transmission{Frames: [][]byte{make([]byte, 155)}, Group: "nope"}); err == nil {
+		t.Errorf("expected an error for an unknown group")
+	}
+
+	// A bridge talker holds the channel
+	r.repeaterManager.ClaimBridgeStream(bridgeAddr, "K1ABC", "REMOTE", "Regional")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.runTransmitScheduler(ctx)
+
+	if _, err := r.QueueTransmission(Transmission{Name: "ID", Frames: [][]byte{make([]byte, 155), make([]byte, 155)}}); err != nil {
+		t.Fatalf("QueueTransmission: %v", err)
+	}
+	time.Sleep(400 * time.Millisecond)
+	if pending := r.PendingTransmissions(); len(pending) != 1 || pending[0].Frames != 2 {
+		t.Fatalf("expected the transmission to wait for the talker, got %+v", pending)
+	}
+
+	// Once the talker is done and the channel stays quiet, it is played
+	r.repeaterManager.ReleaseBridgeStream(bridgeAddr)
+	deadline := time.Now().Add(2 * time.Second)
+	for len(r.PendingTransmissions()) > 0 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if pending := r.PendingTransmissions(); len(pending) != 0 {
+		t.Fatalf("expected the transmission to be played, got %+v", pending)
+	}
+	if r.repeaterManager.ChannelBusy() {
+		t.Errorf("expected the channel to be released after the transmission")
+	}
+}
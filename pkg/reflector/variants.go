@@ -0,0 +1,53 @@
+package reflector
+
+import (
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/network"
+	"github.com/dbehnke/ysf-nexus/pkg/version"
+)
+
+// softwareName identifies the reflector in YSFV replies
+const softwareName = "YSF-Nexus"
+
+// handleVariantPacket handles YSFV, YSFO and YSFI packets with the action
+// configured in server.packet_variants. The network server has already
+// counted the packet; a linked reflector's reply to a bridge's version query
+// never gets here (see tapBridgePacket).
+func (r *Reflector) handleVariantPacket(packet *network.Packet) error {
+	// A YSFO packet may carry the room password
+	if packet.Type == network.PacketTypeOption && r.password != nil && r.handlePasswordOptions(packet) {
+		return nil
+	}
+
+	switch r.config.Server.VariantAction(packet.Type) {
+	case config.VariantIgnore:
+		return nil
+	case config.VariantReply:
+		if packet.Type == network.PacketTypeVersion {
+			return r.server.SendPacket(network.CreateVersionResponse(softwareName, r.BuildInfo().Semver()), packet.Source)
+		}
+	}
+
+	r.logger.Info("Ignoring packet variant",
+		logger.String("type", packet.Type),
+		logger.String("callsign", packet.Callsign),
+		logger.String("source", packet.Source.String()),
+		logger.String("payload", packet.Payload()))
+	return nil
+}
+
+// PacketVariants returns the counts of packet variants and unrecognized
+// packet types received
+func (r *Reflector) PacketVariants() []network.VariantStat {
+	return r.server.VariantStats()
+}
+
+// BuildInfo returns the build metadata of the binary with the version the
+// reflector was created with
+func (r *Reflector) BuildInfo() version.Info {
+	info := version.Get()
+	info.Version = r.version
+	info.BuildTime = r.buildTime
+	return info
+}
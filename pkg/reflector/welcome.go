@@ -0,0 +1,95 @@
+package reflector
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/network"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+// DefaultWelcomeMessage is used when no welcome message template is configured
+const DefaultWelcomeMessage = "Welcome to {{.Reflector}}{{if .RulesURL}} - rules: {{.RulesURL}}{{end}}"
+
+// WelcomeData is the data passed to the welcome message template
+type WelcomeData struct {
+	Reflector   string
+	Description string
+	Callsign    string // Gateway callsign of the new repeater
+	RulesURL    string
+	Repeaters   int // Connected repeaters, including the new one
+}
+
+// welcomer greets newly connected repeaters
+type welcomer struct {
+	message  *template.Template
+	webhooks []string
+	client   *http.Client
+}
+
+// setupWelcome parses the welcome message template
+func (r *Reflector) setupWelcome() {
+	wc := r.config.Server.Welcome
+	text := wc.Message
+	if text == "" {
+		text = DefaultWelcomeMessage
+	}
+	message, err := template.New("welcome").Parse(text)
+	if err != nil {
+		r.logger.Error("Invalid welcome message template", logger.Error(err))
+		return
+	}
+
+	r.welcome = &welcomer{
+		message:  message,
+		webhooks: wc.Webhooks,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// sendWelcome sends the welcome message to a newly connected repeater as a
+// YSFI info packet and reports it as a repeater_welcome event
+func (r *Reflector) sendWelcome(rep *repeater.Repeater) {
+	var buf bytes.Buffer
+	err := r.welcome.message.Execute(&buf, WelcomeData{
+		Reflector:   r.config.Server.Name,
+		Description: r.config.Server.Description,
+		Callsign:    rep.Callsign(),
+		RulesURL:    r.config.Server.Welcome.RulesURL,
+		Repeaters:   r.repeaterManager.Count(),
+	})
+	if err != nil {
+		r.logger.Error("Failed to render welcome message", logger.Error(err))
+		return
+	}
+	message := strings.TrimSpace(buf.String())
+
+	packet := network.CreateInfoPacket(message)
+	if err := r.server.SendPacket(packet, rep.Address()); err != nil {
+		r.logger.Warn("Failed to send welcome message",
+			logger.String("callsign", rep.Callsign()),
+			logger.Error(err))
+		return
+	}
+	r.repeaterManager.ProcessTransmit(rep.Address(), len(packet))
+
+	event := repeater.Event{
+		Type:      repeater.EventWelcome,
+		Callsign:  rep.Callsign(),
+		Address:   rep.Address().String(),
+		Timestamp: time.Now(),
+		Data:      map[string]interface{}{"message": message},
+	}
+	select {
+	case r.eventChan <- event:
+	default:
+		r.logger.Warn("Event channel full, dropping welcome event")
+	}
+	for _, url := range r.welcome.webhooks {
+		go postEventWebhook(r.welcome.client, r.logger, url, event)
+	}
+}
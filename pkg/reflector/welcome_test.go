@@ -0,0 +1,70 @@
+package reflector
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/network"
+)
+
+func TestWelcomeMessageOnFirstConnect(t *testing.T) {
+	free, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	port := free.LocalAddr().(*net.UDPAddr).Port
+	_ = free.Close()
+
+	cfg := &config.Config{Server: config.ServerConfig{
+		Name:              "NEXUS",
+		Host:              "127.0.0.1",
+		Port:              port,
+		Timeout:           time.Minute,
+		MaxConnections:    10,
+		TalkMaxDuration:   time.Minute,
+		BridgeTalkTimeout: 3 * time.Second,
+		Welcome: config.WelcomeConfig{
+			Enabled:  true,
+			Message:  "Hi {{.Callsign}}, welcome to {{.Reflector}}{{if .RulesURL}} - rules: {{.RulesURL}}{{end}}",
+			RulesURL: "https://example.org/rules",
+		},
+	}}
+	r := New(cfg, logger.NewTestLogger(io.Discard))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = r.Start(ctx) }()
+
+	conn := dialClient(t, &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: port}, "W1AW")
+	buf := make([]byte, 512)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("expected a welcome packet: %v", err)
+	}
+	want := network.PacketTypeInfo + "Hi W1AW, welcome to NEXUS - rules: https://example.org/rules"
+	if string(buf[:n]) != want {
+		t.Fatalf("welcome = %q, want %q", buf[:n], want)
+	}
+
+	// Later polls only get the poll reply
+	if _, err := conn.Write([]byte(fmt.Sprintf("%s%-10s", network.PacketTypePoll, "W1AW"))); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+		n, err := conn.Read(buf)
+		if err != nil {
+			break
+		}
+		if string(buf[:4]) == network.PacketTypeInfo {
+			t.Fatalf("unexpected second welcome %q", buf[:n])
+		}
+	}
+}
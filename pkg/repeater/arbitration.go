@@ -0,0 +1,176 @@
+package repeater
+
+import (
+	"net"
+	"strings"
+	"time"
+)
+
+// Stream arbitration. One stream holds the channel at a time, whether it comes
+// from a local repeater or a bridge: the first stream to start wins and keeps
+// the channel until it has been quiet for the talk timeout. Frames from any
+// other stream are not forwarded and are recorded as a doubling.
+//
+// activeKey is the repeater address for local streams, bridgeKeyPrefix plus
+// the bridge address for bridge streams, or reflectorKeyPrefix plus a name for
+// streams the reflector transmits itself, such as announcements.
+//
+// With simultaneous bridge streams allowed (SetSimultaneousBridgeStreams), a
+// bridge stream is not blocked by another bridge stream: both are forwarded,
+// and only the first holds the channel against local repeaters.
+
+// bridgeKeyPrefix marks activeKey values that belong to bridge streams
+const bridgeKeyPrefix = "bridge:"
+
+// reflectorKeyPrefix marks activeKey values of streams the reflector transmits
+const reflectorKeyPrefix = "reflector:"
+
+// bridgeStream is a bridge-originated stream holding the channel
+type bridgeStream struct {
+	key       string
+	callsign  string
+	gateway   string
+	bridge    string
+	lastFrame time.Time
+}
+
+// SetSimultaneousBridgeStreams sets whether frames from a bridge are forwarded
+// while another bridge stream holds the channel
+func (m *Manager) SetSimultaneousBridgeStreams(allowed bool) {
+	m.activeMu.Lock()
+	defer m.activeMu.Unlock()
+	m.bridgeOverlap = allowed
+}
+
+// ClaimBridgeStream arbitrates a data frame received from a bridge. It reports
+// whether the frame holds the channel and may be forwarded to local repeaters.
+func (m *Manager) ClaimBridgeStream(addr *net.UDPAddr, callsign, gateway, bridge string) bool {
+	key := bridgeKeyPrefix + addr.String()
+	now := m.clock.Now()
+
+	m.activeMu.Lock()
+	switch m.activeKey {
+	case "":
+		m.activeKey = key
+		m.activeBridge = &bridgeStream{key: key, callsign: callsign, gateway: gateway, bridge: bridge, lastFrame: now}
+		m.activeMu.Unlock()
+
+		// A bridge stream that was blocked until now has ended its doubling
+		if double, ok := m.doublings.finish(key); ok {
+			m.sendDoubling(double, addr.String())
+		}
+		m.recordClaim(key, now)
+		return true
+	case key:
+		m.activeBridge.lastFrame = now
+		m.activeMu.Unlock()
+		return true
+	}
+	active := m.activeKey
+	overlap := m.bridgeOverlap && strings.HasPrefix(active, bridgeKeyPrefix)
+	m.activeMu.Unlock()
+	if overlap {
+		return true
+	}
+
+	double := Doubling{Callsign: callsign, Gateway: gateway, Bridge: bridge}
+	m.describeActive(&double, active)
+	if started, open := m.doublings.observe(key, double, now); started {
+		m.recordRejection(key, now, open+1)
+	}
+	return false
+}
+
+// HoldsChannel reports whether the repeater at addr holds the channel, i.e.
+// whether its data frames may be forwarded
+func (m *Manager) HoldsChannel(addr *net.UDPAddr) bool {
+	m.activeMu.Lock()
+	defer m.activeMu.Unlock()
+	return m.activeKey == addr.String()
+}
+
+// ChannelBusy reports whether a local or bridge stream currently holds the channel
+func (m *Manager) ChannelBusy() bool {
+	m.activeMu.Lock()
+	defer m.activeMu.Unlock()
+	return m.activeKey != ""
+}
+
+// ClaimReflectorStream takes the channel for a stream the reflector transmits
+// itself, e.g. an announcement, and keeps it while the stream sends frames.
+// It reports whether the stream holds the channel.
+func (m *Manager) ClaimReflectorStream(name string) bool {
+	key := reflectorKeyPrefix + name
+	now := m.clock.Now()
+
+	m.activeMu.Lock()
+	defer m.activeMu.Unlock()
+	switch m.activeKey {
+	case "":
+		m.activeKey = key
+		m.activeBridge = &bridgeStream{key: key, callsign: name, lastFrame: now}
+		return true
+	case key:
+		m.activeBridge.lastFrame = now
+		return true
+	}
+	return false
+}
+
+// ReleaseReflectorStream frees the channel held by a reflector stream
+func (m *Manager) ReleaseReflectorStream(name string) {
+	m.releaseStream(reflectorKeyPrefix + name)
+}
+
+// ReleaseBridgeStream frees the channel held by the bridge stream from addr,
+// e.g. once the stream has sent its terminator frame
+func (m *Manager) ReleaseBridgeStream(addr *net.UDPAddr) {
+	m.releaseStream(bridgeKeyPrefix + addr.String())
+}
+
+// releaseStream frees the channel held by the bridge or reflector stream with key
+func (m *Manager) releaseStream(key string) {
+	m.activeMu.Lock()
+	defer m.activeMu.Unlock()
+
+	if m.activeBridge == nil || m.activeBridge.key != key {
+		return
+	}
+	if m.activeKey == key {
+		m.activeKey = ""
+	}
+	m.activeBridge = nil
+}
+
+// describeActive fills in the stream that holds the channel on a doubling
+func (m *Manager) describeActive(double *Doubling, activeKey string) {
+	if v, ok := m.repeaters.Load(activeKey); ok {
+		active := v.(*Repeater)
+		double.ActiveCallsign = active.Talker()
+		double.ActiveGateway = active.Callsign()
+		return
+	}
+
+	m.activeMu.Lock()
+	defer m.activeMu.Unlock()
+	if m.activeBridge != nil && m.activeBridge.key == activeKey {
+		double.ActiveCallsign = m.activeBridge.callsign
+		double.ActiveGateway = m.activeBridge.gateway
+		double.ActiveBridge = m.activeBridge.bridge
+	}
+}
+
+// releaseIdleBridgeStream frees the channel once the bridge stream holding it
+// has been quiet for timeout
+func (m *Manager) releaseIdleBridgeStream(now time.Time, timeout time.Duration) {
+	m.activeMu.Lock()
+	defer m.activeMu.Unlock()
+
+	if m.activeBridge == nil || now.Sub(m.activeBridge.lastFrame) <= timeout {
+		return
+	}
+	if m.activeKey == m.activeBridge.key {
+		m.activeKey = ""
+	}
+	m.activeBridge = nil
+}
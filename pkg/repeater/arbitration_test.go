@@ -0,0 +1,140 @@
+package repeater
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/clock"
+)
+
+func TestBridgeStreamBlocksLocalAndViceVersa(t *testing.T) {
+	events := make(chan Event, 20)
+	m := NewManager(5*time.Second, 10, events, 180*time.Second, 0)
+	local := mustAddr(t, "127.0.0.1:45020")
+	bridgeAddr := mustAddr(t, "192.0.2.10:42000")
+	m.AddRepeater("W1AW", local)
+
+	// The bridge stream starts first and holds the channel
+	if !m.ClaimBridgeStream(bridgeAddr, "K1ABC", "REMOTE", "Regional") {
+		t.Fatalf("expected bridge to claim the free channel")
+	}
+	m.ProcessPacket("W1AW", local, "YSFD", 155)
+	if m.HoldsChannel(local) {
+		t.Fatalf("local repeater must not take the channel from a bridge stream")
+	}
+
+	// Once the bridge goes quiet, the channel is free again
+	m.releaseIdleBridgeStream(time.Now().Add(4*time.Second), 3*time.Second)
+	m.ProcessPacket("W1AW", local, "YSFD", 155)
+	if !m.HoldsChannel(local) {
+		t.Fatalf("expected local repeater to claim the released channel")
+	}
+
+	// The local doubling during the bridge stream names the bridge talker
+	recent, _, _ := m.GetDoublings(0)
+	if len(recent) != 1 || recent[0].ActiveCallsign != "K1ABC" || recent[0].ActiveBridge != "Regional" {
+		t.Fatalf("unexpected doublings: %+v", recent)
+	}
+
+	// Now the bridge is blocked by the local stream
+	if m.ClaimBridgeStream(bridgeAddr, "K1ABC", "REMOTE", "Regional") {
+		t.Errorf("bridge must not take the channel from a local stream")
+	}
+}
+
+func TestChannelBusy(t *testing.T) {
+	m := NewManager(5*time.Second, 10, make(chan Event, 20), 180*time.Second, 0)
+	bridgeAddr := mustAddr(t, "192.0.2.10:42000")
+
+	if m.ChannelBusy() {
+		t.Fatalf("expected a free channel")
+	}
+	m.ClaimBridgeStream(bridgeAddr, "K1ABC", "REMOTE", "Regional")
+	if !m.ChannelBusy() {
+		t.Fatalf("expected the bridge stream to hold the channel")
+	}
+	m.ReleaseBridgeStream(bridgeAddr)
+	if m.ChannelBusy() {
+		t.Errorf("expected the channel to be free after the terminator")
+	}
+}
+
+func TestIdleBridgeStreamReleasedOnFakeClock(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 10, 3, 12, 0, 0, 0, time.UTC))
+	m := NewManager(5*time.Second, 10, make(chan Event, 20), 180*time.Second, 0)
+	m.SetClock(fake)
+	bridgeAddr := mustAddr(t, "192.0.2.10:42000")
+
+	m.ClaimBridgeStream(bridgeAddr, "K1ABC", "REMOTE", "Regional")
+	fake.Advance(talkIdleTimeout)
+	m.checkTalkTimeouts()
+	if !m.ChannelBusy() {
+		t.Fatalf("expected the stream to keep the channel until the talk timeout has passed")
+	}
+
+	fake.Advance(time.Second)
+	m.checkTalkTimeouts()
+	if m.ChannelBusy() {
+		t.Errorf("expected the idle bridge stream to be released")
+	}
+}
+
+func TestSimultaneousBridgeStreams(t *testing.T) {
+	m := NewManager(5*time.Second, 10, make(chan Event, 20), 180*time.Second, 0)
+	local := mustAddr(t, "127.0.0.1:45021")
+	first := mustAddr(t, "192.0.2.10:42000")
+	second := mustAddr(t, "192.0.2.11:42000")
+	m.AddRepeater("W1AW", local)
+
+	// By default the first bridge stream wins and the second is a doubling
+	if !m.ClaimBridgeStream(first, "K1ABC", "REMOTE", "Regional") {
+		t.Fatalf("expected first bridge to claim the free channel")
+	}
+	if m.ClaimBridgeStream(second, "N0CALL", "OTHER", "National") {
+		t.Fatalf("second bridge must not play over the first")
+	}
+	m.ReleaseBridgeStream(first)
+	if !m.ClaimBridgeStream(second, "N0CALL", "OTHER", "National") {
+		t.Fatalf("expected second bridge to claim the released channel")
+	}
+	if _, _, total := m.GetDoublings(0); total != 1 {
+		t.Fatalf("expected one doubling, got %d", total)
+	}
+	m.ReleaseBridgeStream(second)
+
+	m.SetSimultaneousBridgeStreams(true)
+	if !m.ClaimBridgeStream(first, "K1ABC", "REMOTE", "Regional") {
+		t.Fatalf("expected first bridge to claim the free channel")
+	}
+	if !m.ClaimBridgeStream(second, "N0CALL", "OTHER", "National") {
+		t.Fatalf("expected second bridge to be forwarded alongside the first")
+	}
+
+	// Local repeaters are still blocked, and the second bridge's terminator
+	// does not free the channel held by the first
+	m.ReleaseBridgeStream(second)
+	m.ProcessPacket("W1AW", local, "YSFD", 155)
+	if m.HoldsChannel(local) {
+		t.Fatalf("local repeater must not take the channel from a bridge stream")
+	}
+}
+
+func TestReflectorStreamHoldsChannel(t *testing.T) {
+	m := NewManager(5*time.Second, 10, make(chan Event, 20), 180*time.Second, 0)
+	local := mustAddr(t, "127.0.0.1:45022")
+	bridgeAddr := mustAddr(t, "192.0.2.10:42000")
+	m.AddRepeater("W1AW", local)
+
+	if !m.ClaimReflectorStream("ID") || !m.ClaimReflectorStream("ID") {
+		t.Fatalf("expected the reflector stream to claim and keep the free channel")
+	}
+	m.ProcessPacket("W1AW", local, "YSFD", 155)
+	if m.HoldsChannel(local) || m.ClaimBridgeStream(bridgeAddr, "K1ABC", "REMOTE", "Regional") {
+		t.Fatalf("nothing may take the channel from a reflector stream")
+	}
+
+	m.ReleaseReflectorStream("ID")
+	if m.ChannelBusy() {
+		t.Errorf("expected the channel to be free after release")
+	}
+}
@@ -1,13 +1,24 @@
 package repeater
 
 import (
+	"sort"
 	"strings"
 	"sync"
 )
 
+// LocalBlocklistSource is the source name reported for locally configured entries
+const LocalBlocklistSource = "local"
+
+// BlocklistEntry describes a blocked callsign and the sources that list it
+type BlocklistEntry struct {
+	Callsign string   `json:"callsign"`
+	Sources  []string `json:"sources"`
+}
+
 // Blocklist manages blocked callsigns
 type Blocklist struct {
 	blocked map[string]bool
+	remote  map[string]map[string]bool // source -> callsigns
 	mu      sync.RWMutex
 }
 
@@ -15,17 +26,26 @@ type Blocklist struct {
 func NewBlocklist() *Blocklist {
 	return &Blocklist{
 		blocked: make(map[string]bool),
+		remote:  make(map[string]map[string]bool),
 	}
 }
 
-// IsBlocked checks if a callsign is blocked
+// IsBlocked checks if a callsign is blocked locally or by any remote source
 func (b *Blocklist) IsBlocked(callsign string) bool {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
 	// Normalize callsign for comparison
 	normalized := strings.ToUpper(strings.TrimSpace(callsign))
-	return b.blocked[normalized]
+	if b.blocked[normalized] {
+		return true
+	}
+	for _, entries := range b.remote {
+		if entries[normalized] {
+			return true
+		}
+	}
+	return false
 }
 
 // Block adds a callsign to the blocklist
@@ -63,28 +83,81 @@ func (b *Blocklist) SetBlocked(callsigns []string) {
 	}
 }
 
-// GetBlocked returns all blocked callsigns
+// SetSource replaces the callsigns contributed by a remote source
+func (b *Blocklist) SetSource(source string, callsigns []string) {
+	entries := make(map[string]bool, len(callsigns))
+	for _, callsign := range callsigns {
+		normalized := strings.ToUpper(strings.TrimSpace(callsign))
+		if normalized != "" {
+			entries[normalized] = true
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.remote[source] = entries
+}
+
+// RemoveSource drops all callsigns contributed by a remote source
+func (b *Blocklist) RemoveSource(source string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.remote, source)
+}
+
+// GetBlocked returns all blocked callsigns, local and remote
 func (b *Blocklist) GetBlocked() []string {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
 	var blocked []string
-	for callsign := range b.blocked {
+	for callsign := range b.merged() {
 		blocked = append(blocked, callsign)
 	}
 	return blocked
 }
 
-// Count returns the number of blocked callsigns
+// GetEntries returns all blocked callsigns with the sources that list them, sorted by callsign
+func (b *Blocklist) GetEntries() []BlocklistEntry {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	merged := b.merged()
+	entries := make([]BlocklistEntry, 0, len(merged))
+	for callsign, sources := range merged {
+		sort.Strings(sources)
+		entries = append(entries, BlocklistEntry{Callsign: callsign, Sources: sources})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Callsign < entries[j].Callsign
+	})
+	return entries
+}
+
+// Count returns the number of distinct blocked callsigns
 func (b *Blocklist) Count() int {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
-	return len(b.blocked)
+	return len(b.merged())
 }
 
-// Clear removes all entries from the blocklist
+// Clear removes all local entries from the blocklist. Remote sources are kept.
 func (b *Blocklist) Clear() {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 	b.blocked = make(map[string]bool)
 }
+
+// merged maps every blocked callsign to its sources. Caller must hold the lock.
+func (b *Blocklist) merged() map[string][]string {
+	merged := make(map[string][]string, len(b.blocked))
+	for callsign := range b.blocked {
+		merged[callsign] = append(merged[callsign], LocalBlocklistSource)
+	}
+	for source, entries := range b.remote {
+		for callsign := range entries {
+			merged[callsign] = append(merged[callsign], source)
+		}
+	}
+	return merged
+}
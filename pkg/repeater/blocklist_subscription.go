@@ -0,0 +1,147 @@
+package repeater
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// maxBlocklistSize caps the size of a remote blocklist body; a larger one is
+// rejected rather than cut off
+const maxBlocklistSize = 4 << 20
+
+// BlocklistSubscription periodically merges remote blocklists into a Blocklist.
+// Each URL becomes its own source so entries can be attributed.
+type BlocklistSubscription struct {
+	blocklist *Blocklist
+	urls      []string
+	interval  time.Duration
+	client    *http.Client
+	logger    *logger.Logger
+
+	mu    sync.Mutex
+	etags map[string]string
+}
+
+// NewBlocklistSubscription creates a subscription that fetches urls every interval
+func NewBlocklistSubscription(blocklist *Blocklist, urls []string, interval time.Duration, log *logger.Logger) *BlocklistSubscription {
+	return &BlocklistSubscription{
+		blocklist: blocklist,
+		urls:      urls,
+		interval:  interval,
+		client:    &http.Client{Timeout: 30 * time.Second},
+		logger:    log.WithComponent("blocklist"),
+		etags:     make(map[string]string),
+	}
+}
+
+// Start fetches all sources immediately and then on every interval until ctx is cancelled
+func (s *BlocklistSubscription) Start(ctx context.Context) {
+	s.RefreshAll(ctx)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.RefreshAll(ctx)
+		}
+	}
+}
+
+// RefreshAll fetches every source. A failed fetch keeps the previously loaded entries.
+func (s *BlocklistSubscription) RefreshAll(ctx context.Context) {
+	for _, url := range s.urls {
+		updated, count, err := s.Refresh(ctx, url)
+		if err != nil {
+			s.logger.Warn("Failed to fetch remote blocklist",
+				logger.String("source", url),
+				logger.Error(err))
+			continue
+		}
+		if updated {
+			s.logger.Info("Remote blocklist updated",
+				logger.String("source", url),
+				logger.Int("callsigns", count))
+		}
+	}
+}
+
+// Refresh fetches a single source, using its ETag to skip unchanged lists.
+// It reports whether the blocklist was updated and how many callsigns the source now lists.
+func (s *BlocklistSubscription) Refresh(ctx context.Context, url string) (bool, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, 0, fmt.Errorf("invalid request: %w", err)
+	}
+
+	s.mu.Lock()
+	etag := s.etags[url]
+	s.mu.Unlock()
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return false, 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return false, 0, nil
+	case http.StatusOK:
+	default:
+		return false, 0, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBlocklistSize+1))
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to read blocklist: %w", err)
+	}
+	if len(body) > maxBlocklistSize {
+		return false, 0, fmt.Errorf("blocklist larger than %d bytes", maxBlocklistSize)
+	}
+	callsigns, err := parseBlocklist(bytes.NewReader(body))
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to read blocklist: %w", err)
+	}
+
+	s.blocklist.SetSource(url, callsigns)
+
+	s.mu.Lock()
+	s.etags[url] = resp.Header.Get("ETag")
+	s.mu.Unlock()
+
+	return true, len(callsigns), nil
+}
+
+// parseBlocklist reads one callsign per line. Blank lines and lines starting
+// with '#' are ignored, as is anything after the first whitespace or comma.
+func parseBlocklist(r io.Reader) ([]string, error) {
+	var callsigns []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if i := strings.IndexAny(line, " \t,"); i >= 0 {
+			line = line[:i]
+		}
+		callsigns = append(callsigns, line)
+	}
+	return callsigns, scanner.Err()
+}
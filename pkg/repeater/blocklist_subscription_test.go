@@ -0,0 +1,109 @@
+package repeater
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+func TestBlocklistSubscriptionETag(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("# community list\nbad1 spammer\n\nBAD2,reason\n"))
+	}))
+	defer srv.Close()
+
+	bl := NewBlocklist()
+	bl.SetBlocked([]string{"BAD1", "LOCAL1"})
+	sub := NewBlocklistSubscription(bl, []string{srv.URL}, time.Hour, logger.NewTestLogger(os.Stdout))
+
+	updated, count, err := sub.Refresh(context.Background(), srv.URL)
+	if err != nil || !updated || count != 2 {
+		t.Fatalf("first refresh: updated=%v count=%d err=%v", updated, count, err)
+	}
+
+	updated, _, err = sub.Refresh(context.Background(), srv.URL)
+	if err != nil || updated {
+		t.Fatalf("second refresh should be not-modified: updated=%v err=%v", updated, err)
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+
+	if !bl.IsBlocked("bad2") {
+		t.Errorf("expected BAD2 to be blocked by remote source")
+	}
+	if bl.Count() != 3 {
+		t.Errorf("expected 3 distinct callsigns, got %d", bl.Count())
+	}
+
+	entries := bl.GetEntries()
+	if entries[0].Callsign != "BAD1" || len(entries[0].Sources) != 2 {
+		t.Errorf("expected BAD1 attributed to local and remote, got %+v", entries[0])
+	}
+
+	bl.RemoveSource(srv.URL)
+	if bl.IsBlocked("BAD2") || !bl.IsBlocked("BAD1") {
+		t.Errorf("removing the remote source should keep local entries only")
+	}
+}
+
+func TestBlocklistSubscriptionKeepsEntriesOnError(t *testing.T) {
+	fail := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte("BAD1\n"))
+	}))
+	defer srv.Close()
+
+	bl := NewBlocklist()
+	sub := NewBlocklistSubscription(bl, []string{srv.URL}, time.Hour, logger.NewTestLogger(os.Stdout))
+	sub.RefreshAll(context.Background())
+
+	fail = true
+	if _, _, err := sub.Refresh(context.Background(), srv.URL); err == nil {
+		t.Errorf("expected an error on 500")
+	}
+	if !bl.IsBlocked("BAD1") {
+		t.Errorf("previously fetched entries should survive a failed fetch")
+	}
+}
+
+func TestBlocklistSubscriptionRejectsOversizedList(t *testing.T) {
+	oversized := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if oversized {
+			_, _ = w.Write([]byte(strings.Repeat("BAD2\n", maxBlocklistSize/5+1)))
+			return
+		}
+		_, _ = w.Write([]byte("BAD1\n"))
+	}))
+	defer srv.Close()
+
+	bl := NewBlocklist()
+	sub := NewBlocklistSubscription(bl, []string{srv.URL}, time.Hour, logger.NewTestLogger(os.Stdout))
+	sub.RefreshAll(context.Background())
+
+	oversized = true
+	if _, _, err := sub.Refresh(context.Background(), srv.URL); err == nil {
+		t.Error("expected an error for a list over the size limit")
+	}
+	if !bl.IsBlocked("BAD1") || bl.IsBlocked("BAD2") {
+		t.Error("expected the previous list to stay in place")
+	}
+}
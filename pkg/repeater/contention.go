@@ -0,0 +1,151 @@
+package repeater
+
+import (
+	"sync"
+	"time"
+)
+
+// Contention statistics. Only one stream holds the channel, but stations
+// still key up over each other; these numbers show how often that happens
+// and how long stations wait, so an operator can tell a busy reflector from
+// a saturated one. A stream is rejected when its first frame arrives while
+// another stream holds the channel. Its wait ends when it takes the channel
+// or, if it gave up, when the channel is next seen free (checked every
+// second).
+
+// contentionHours is how many hourly rejection counts are kept
+const contentionHours = 24
+
+// ContentionStats summarizes overlapping stream attempts
+type ContentionStats struct {
+	// PeakConcurrent is the most streams attempted at once, the active one included
+	PeakConcurrent int        `json:"peak_concurrent"`
+	PeakAt         *time.Time `json:"peak_at,omitempty"`
+	// Waiting is the number of rejected streams still waiting for the channel
+	Waiting    int    `json:"waiting"`
+	Rejections uint64 `json:"rejections"`
+	// RejectionsPerHour covers the last 24 hours that had rejections, oldest first
+	RejectionsPerHour []HourlyRejections `json:"rejections_per_hour"`
+	Waits             uint64             `json:"waits"` // Finished waits
+	AverageWaitMS     int64              `json:"average_wait_ms"`
+	MaxWaitMS         int64              `json:"max_wait_ms"`
+}
+
+// HourlyRejections is the number of streams rejected in one hour
+type HourlyRejections struct {
+	Hour       time.Time `json:"hour"`
+	Rejections uint64    `json:"rejections"`
+}
+
+// contentionTracker accumulates contention statistics
+type contentionTracker struct {
+	mu         sync.Mutex
+	peak       int
+	peakAt     time.Time
+	rejections uint64
+	hourly     []HourlyRejections   // oldest first
+	waiting    map[string]time.Time // stream key -> first rejected frame
+	waits      uint64
+	totalWait  time.Duration
+	maxWait    time.Duration
+}
+
+// recordRejection counts a stream rejected while concurrent streams, the
+// active one included, were attempted
+func (m *Manager) recordRejection(key string, now time.Time, concurrent int) {
+	c := &m.contention
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.rejections++
+	if concurrent > c.peak {
+		c.peak = concurrent
+		c.peakAt = now
+	}
+
+	c.pruneLocked(now)
+	hour := now.Truncate(time.Hour)
+	if n := len(c.hourly); n > 0 && c.hourly[n-1].Hour.Equal(hour) {
+		c.hourly[n-1].Rejections++
+	} else {
+		c.hourly = append(c.hourly, HourlyRejections{Hour: hour, Rejections: 1})
+	}
+
+	if c.waiting == nil {
+		c.waiting = make(map[string]time.Time)
+	}
+	if _, ok := c.waiting[key]; !ok {
+		c.waiting[key] = now
+	}
+}
+
+// recordClaim ends the wait of a stream that took the channel
+func (m *Manager) recordClaim(key string, now time.Time) {
+	c := &m.contention
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if since, ok := c.waiting[key]; ok {
+		c.finishWaitLocked(now.Sub(since))
+		delete(c.waiting, key)
+	}
+}
+
+// sampleContention ends the waits of streams that gave up once the channel
+// is free
+func (m *Manager) sampleContention(now time.Time) {
+	if m.ChannelBusy() {
+		return
+	}
+
+	c := &m.contention
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, since := range c.waiting {
+		c.finishWaitLocked(now.Sub(since))
+		delete(c.waiting, key)
+	}
+}
+
+// finishWaitLocked records a finished wait (assumes mutex is locked)
+func (c *contentionTracker) finishWaitLocked(wait time.Duration) {
+	c.waits++
+	c.totalWait += wait
+	if wait > c.maxWait {
+		c.maxWait = wait
+	}
+}
+
+// pruneLocked drops hourly counts older than the window (assumes mutex is locked)
+func (c *contentionTracker) pruneLocked(now time.Time) {
+	oldest := now.Truncate(time.Hour).Add(-(contentionHours - 1) * time.Hour)
+	for len(c.hourly) > 0 && c.hourly[0].Hour.Before(oldest) {
+		c.hourly = c.hourly[1:]
+	}
+}
+
+// GetContention returns the contention statistics
+func (m *Manager) GetContention() ContentionStats {
+	now := m.clock.Now()
+	c := &m.contention
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pruneLocked(now)
+	stats := ContentionStats{
+		PeakConcurrent:    c.peak,
+		Waiting:           len(c.waiting),
+		Rejections:        c.rejections,
+		RejectionsPerHour: append([]HourlyRejections{}, c.hourly...),
+		Waits:             c.waits,
+		MaxWaitMS:         c.maxWait.Milliseconds(),
+	}
+	if !c.peakAt.IsZero() {
+		peakAt := c.peakAt
+		stats.PeakAt = &peakAt
+	}
+	if c.waits > 0 {
+		stats.AverageWaitMS = (c.totalWait / time.Duration(c.waits)).Milliseconds()
+	}
+	return stats
+}
@@ -0,0 +1,63 @@
+package repeater
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/clock"
+)
+
+func TestContentionStats(t *testing.T) {
+	events := make(chan Event, 50)
+	m := NewManager(5*time.Second, 10, events, 180*time.Second, 0)
+	clk := clock.NewFake(time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC))
+	m.SetClock(clk)
+	a := mustAddr(t, "127.0.0.1:45060")
+	b := mustAddr(t, "127.0.0.1:45061")
+	c := mustAddr(t, "127.0.0.1:45062")
+	m.AddRepeater("GW-A", a)
+	m.AddRepeater("GW-B", b)
+	m.AddRepeater("GW-C", c)
+
+	m.ProcessPacket("W1AW", a, "YSFD", 155)
+	// Two stations key up over the active one; repeated frames count once
+	m.ProcessPacket("K2XX", b, "YSFD", 155)
+	m.ProcessPacket("K2XX", b, "YSFD", 155)
+	m.ProcessPacket("N3YY", c, "YSFD", 155)
+
+	stats := m.GetContention()
+	if stats.PeakConcurrent != 3 || stats.Rejections != 2 || stats.Waiting != 2 {
+		t.Fatalf("unexpected stats after overlap: %+v", stats)
+	}
+	if len(stats.RejectionsPerHour) != 1 || stats.RejectionsPerHour[0].Rejections != 2 ||
+		!stats.RejectionsPerHour[0].Hour.Equal(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected hourly rejections: %+v", stats.RejectionsPerHour)
+	}
+
+	// The channel clears and K2XX takes it after waiting 2s
+	clk.Advance(2 * time.Second)
+	m.ClearActive()
+	m.ProcessPacket("K2XX", b, "YSFD", 155)
+	if !m.HoldsChannel(b) {
+		t.Fatal("expected K2XX to take the channel")
+	}
+	m.sampleContention(clk.Now())
+	if stats := m.GetContention(); stats.Waits != 1 || stats.Waiting != 1 || stats.MaxWaitMS != 2000 {
+		t.Errorf("unexpected stats after claim: %+v", stats)
+	}
+
+	// N3YY gave up; its wait ends when the channel is next seen free
+	clk.Advance(3 * time.Second)
+	m.ClearActive()
+	m.sampleContention(clk.Now())
+	stats = m.GetContention()
+	if stats.Waits != 2 || stats.Waiting != 0 || stats.AverageWaitMS != 3500 || stats.MaxWaitMS != 5000 {
+		t.Errorf("unexpected stats after the channel cleared: %+v", stats)
+	}
+
+	// Hourly counts fall out of the 24 hour window
+	clk.Advance(24 * time.Hour)
+	if stats := m.GetContention(); len(stats.RejectionsPerHour) != 0 || stats.Rejections != 2 {
+		t.Errorf("unexpected stats a day later: %+v", stats)
+	}
+}
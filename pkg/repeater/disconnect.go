@@ -0,0 +1,25 @@
+package repeater
+
+// Disconnect reasons, reported in disconnect events and counted in the
+// manager statistics so operators can tell churn from repeaters leaving on
+// their own apart from repeaters dropped by the reflector
+const (
+	DisconnectUnlink  = "unlink"  // The repeater sent an unlink packet
+	DisconnectTimeout = "timeout" // No poll within the timeout
+	DisconnectKicked  = "kicked"  // Removed by an operator
+	DisconnectBlocked = "blocked" // The callsign was blocked after connecting
+)
+
+// KickRepeater disconnects every repeater with the given callsign
+// (case-insensitive) and returns how many were removed. A kicked repeater
+// may link again on its next poll unless it is also blocked.
+func (m *Manager) KickRepeater(callsign string) int {
+	callsign = normalizeCallsign(callsign)
+	kicked := 0
+	for _, r := range m.GetAllRepeaters() {
+		if normalizeCallsign(r.Callsign()) == callsign && m.RemoveRepeater(r.Address(), DisconnectKicked) {
+			kicked++
+		}
+	}
+	return kicked
+}
@@ -0,0 +1,87 @@
+package repeater
+
+import (
+	"testing"
+	"time"
+)
+
+// disconnectReasons returns the reasons of the disconnect events waiting on
+// the channel, and of talk_end events that carry one
+func disconnectReasons(events chan Event) (disconnects, talkEnds []string) {
+	for len(events) > 0 {
+		switch ev := <-events; ev.Type {
+		case EventDisconnect:
+			disconnects = append(disconnects, ev.Reason)
+		case EventTalkEnd:
+			if ev.Reason != "" {
+				talkEnds = append(talkEnds, ev.Reason)
+			}
+		}
+	}
+	return disconnects, talkEnds
+}
+
+func TestDisconnectReasons(t *testing.T) {
+	events := make(chan Event, 50)
+	m := NewManager(5*time.Second, 10, events, 180*time.Second, 0)
+
+	unlinked := mustAddr(t, "10.0.0.1:40001")
+	m.AddRepeater("R1", unlinked)
+	m.RemoveRepeater(unlinked, DisconnectUnlink)
+
+	// Two entries for one callsign are both kicked
+	m.AddRepeater("r2", mustAddr(t, "10.0.0.2:40001"))
+	m.AddRepeater("R2", mustAddr(t, "10.0.0.2:40002"))
+	if kicked := m.KickRepeater("R2"); kicked != 2 {
+		t.Errorf("expected 2 kicked repeaters, got %d", kicked)
+	}
+	if kicked := m.KickRepeater("R2"); kicked != 0 {
+		t.Errorf("expected nothing left to kick, got %d", kicked)
+	}
+
+	// Blocked after connecting: dropped on the next poll
+	blocked := mustAddr(t, "10.0.0.3:40001")
+	m.AddRepeater("R3", blocked)
+	m.GetBlocklist().Block("R3")
+	if r, _ := m.AddRepeater("R3", blocked); r != nil || m.GetRepeater(blocked) != nil {
+		t.Error("expected the blocked repeater to be removed")
+	}
+
+	disconnects, _ := disconnectReasons(events)
+	want := []string{DisconnectUnlink, DisconnectKicked, DisconnectKicked, DisconnectBlocked}
+	if len(disconnects) != len(want) {
+		t.Fatalf("disconnect reasons = %v, want %v", disconnects, want)
+	}
+	for i := range want {
+		if disconnects[i] != want[i] {
+			t.Fatalf("disconnect reasons = %v, want %v", disconnects, want)
+		}
+	}
+
+	stats := m.GetStats().Disconnects
+	if stats[DisconnectUnlink] != 1 || stats[DisconnectKicked] != 2 || stats[DisconnectBlocked] != 1 {
+		t.Errorf("disconnect counts = %v", stats)
+	}
+}
+
+func TestTimeoutDisconnectEndsTalk(t *testing.T) {
+	events := make(chan Event, 10)
+	m := NewManager(10*time.Millisecond, 10, events, 180*time.Second, 0)
+	addr := mustAddr(t, "10.0.0.1:40001")
+
+	m.AddRepeater("R1", addr)
+	m.ProcessPacket("N0CALL", addr, "YSFD", 155)
+	time.Sleep(20 * time.Millisecond)
+	m.cleanupTimedOut()
+
+	disconnects, talkEnds := disconnectReasons(events)
+	if len(disconnects) != 1 || disconnects[0] != DisconnectTimeout {
+		t.Errorf("disconnect reasons = %v, want [timeout]", disconnects)
+	}
+	if len(talkEnds) != 1 || talkEnds[0] != DisconnectTimeout {
+		t.Errorf("talk_end reasons = %v, want [timeout]", talkEnds)
+	}
+	if got := m.GetStats().Disconnects[DisconnectTimeout]; got != 1 {
+		t.Errorf("timeout disconnects = %d, want 1", got)
+	}
+}
@@ -0,0 +1,121 @@
+package repeater
+
+import (
+	"sync"
+	"time"
+)
+
+// maxRecentDoublings is how many finished doublings are kept for the API
+const maxRecentDoublings = 100
+
+// Doubling records a transmission that was suppressed because another
+// repeater or bridge stream already held the channel
+type Doubling struct {
+	Callsign       string        `json:"callsign"` // Suppressed talker
+	Gateway        string        `json:"gateway"`  // Repeater the suppressed talker keyed
+	ActiveCallsign string        `json:"active_callsign"`
+	ActiveGateway  string        `json:"active_gateway"`
+	Start          time.Time     `json:"start"`
+	Duration       time.Duration `json:"duration"` // How long the suppressed stream lasted
+
+	// Bridge and ActiveBridge name the bridge a stream arrived on, for bridge streams
+	Bridge       string `json:"bridge,omitempty"`
+	ActiveBridge string `json:"active_bridge,omitempty"`
+}
+
+// doublingTracker follows suppressed streams per repeater address
+type doublingTracker struct {
+	mu     sync.Mutex
+	open   map[string]*Doubling // address -> suppressed stream in progress
+	last   map[string]time.Time // address -> last suppressed frame
+	recent []Doubling           // finished doublings, newest first
+	counts map[string]uint64    // suppressed talker -> doublings
+	total  uint64
+}
+
+func newDoublingTracker() *doublingTracker {
+	return &doublingTracker{
+		open:   make(map[string]*Doubling),
+		last:   make(map[string]time.Time),
+		counts: make(map[string]uint64),
+	}
+}
+
+// observe records a suppressed frame, starting a new doubling if needed. It
+// reports whether a doubling started and how many are now open.
+func (d *doublingTracker) observe(address string, double Doubling, now time.Time) (bool, int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	_, ok := d.open[address]
+	if !ok {
+		double.Start = now
+		d.open[address] = &double
+	}
+	d.last[address] = now
+	return !ok, len(d.open)
+}
+
+// finish closes the doubling for an address, if one is open
+func (d *doublingTracker) finish(address string) (Doubling, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.finishLocked(address)
+}
+
+// expire closes doublings whose suppressed stream has been quiet for gap
+func (d *doublingTracker) expire(now time.Time, gap time.Duration) []Doubling {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var finished []Doubling
+	for address, last := range d.last {
+		if now.Sub(last) > gap {
+			if double, ok := d.finishLocked(address); ok {
+				finished = append(finished, double)
+			}
+		}
+	}
+	return finished
+}
+
+func (d *doublingTracker) finishLocked(address string) (Doubling, bool) {
+	double, ok := d.open[address]
+	if !ok {
+		return Doubling{}, false
+	}
+	double.Duration = d.last[address].Sub(double.Start)
+	delete(d.open, address)
+	delete(d.last, address)
+
+	d.total++
+	d.counts[normalizeCallsign(double.Callsign)]++
+	d.recent = append([]Doubling{*double}, d.recent...)
+	if len(d.recent) > maxRecentDoublings {
+		d.recent = d.recent[:maxRecentDoublings]
+	}
+	return *double, true
+}
+
+// snapshot returns recent doublings (at most limit, 0 = all), the per-callsign counts and the total
+func (d *doublingTracker) snapshot(limit int) ([]Doubling, map[string]uint64, uint64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	recent := d.recent
+	if limit > 0 && len(recent) > limit {
+		recent = recent[:limit]
+	}
+	counts := make(map[string]uint64, len(d.counts))
+	for cs, n := range d.counts {
+		counts[cs] = n
+	}
+	return append([]Doubling(nil), recent...), counts, d.total
+}
+
+// count returns the number of finished doublings
+func (d *doublingTracker) count() uint64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.total
+}
@@ -0,0 +1,219 @@
+package repeater
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// EventBus fans events from the producer channel out to any number of
+// subscribers. Subscribers can come and go at runtime (e.g. a restarted web
+// server) and may replay the events published while they were away.
+type EventBus struct {
+	mu      sync.RWMutex
+	subs    map[*Subscription]struct{}
+	history []busEvent // Recent events, oldest first
+	size    int
+	logger  *logger.Logger
+}
+
+// busEvent is an event with the time the bus received it
+type busEvent struct {
+	event      Event
+	receivedAt time.Time
+}
+
+// Subscription receives events from an EventBus until closed
+type Subscription struct {
+	C       <-chan Event
+	ch      chan Event
+	bus     *EventBus
+	dropped uint64
+	once    sync.Once
+}
+
+// NewEventBus creates an event bus that keeps the last historySize events for replay
+func NewEventBus(historySize int, log *logger.Logger) *EventBus {
+	return &EventBus{
+		subs:   make(map[*Subscription]struct{}),
+		size:   historySize,
+		logger: log.WithComponent("events"),
+	}
+}
+
+// Run publishes every event read from in until ctx is cancelled
+func (b *EventBus) Run(ctx context.Context, in <-chan Event) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-in:
+			b.Publish(event)
+		}
+	}
+}
+
+// Publish delivers an event to all subscribers without blocking.
+// A subscriber whose buffer is full misses the event.
+func (b *EventBus) Publish(event Event) {
+	// Hold the write lock throughout so a concurrent Subscribe sees each event
+	// either in the replayed history or live, never both
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.size > 0 {
+		b.history = append(b.history, busEvent{event: event, receivedAt: time.Now()})
+		if len(b.history) > b.size {
+			b.history = b.history[len(b.history)-b.size:]
+		}
+	}
+	for sub := range b.subs {
+		sub.deliver(event)
+	}
+}
+
+// Subscribe returns a subscription with the given buffer size. Events received
+// by the bus after since are replayed first; a zero since replays nothing.
+func (b *EventBus) Subscribe(buffer int, since time.Time) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var replay []Event
+	if !since.IsZero() {
+		for _, h := range b.history {
+			if h.receivedAt.After(since) {
+				replay = append(replay, h.event)
+			}
+		}
+	}
+	if buffer < len(replay) {
+		buffer = len(replay)
+	}
+
+	ch := make(chan Event, buffer)
+	for _, event := range replay {
+		ch <- event
+	}
+
+	sub := &Subscription{C: ch, ch: ch, bus: b}
+	b.subs[sub] = struct{}{}
+	return sub
+}
+
+// Subscribers returns the number of active subscriptions
+func (b *EventBus) Subscribers() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs)
+}
+
+// deliver sends an event without blocking; the caller holds the bus lock
+func (s *Subscription) deliver(event Event) {
+	select {
+	case s.ch <- event:
+	default:
+		if atomic.AddUint64(&s.dropped, 1) == 1 && s.bus.logger != nil {
+			s.bus.logger.Warn("Event subscriber is not keeping up, dropping events",
+				logger.String("type", event.Type))
+		}
+	}
+}
+
+// Backlog returns the queued events and buffer size of the subscriber
+// furthest behind, relative to its buffer
+func (b *EventBus) Backlog() (int, int) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	length, capacity := 0, 0
+	for sub := range b.subs {
+		l, c := len(sub.ch), cap(sub.ch)
+		if capacity == 0 || l*capacity > length*c {
+			length, capacity = l, c
+		}
+	}
+	return length, capacity
+}
+
+// Prune drops replayable events the bus received more than maxAge ago and
+// returns how many were dropped
+func (b *EventBus) Prune(maxAge time.Duration) int {
+	cutoff := time.Now().Add(-maxAge)
+	return b.filter(func(h *busEvent) bool { return !h.receivedAt.Before(cutoff) })
+}
+
+// ScrubAddresses blanks the IP addresses of replayable events the bus
+// received more than maxAge ago and returns how many were scrubbed
+func (b *EventBus) ScrubAddresses(maxAge time.Duration) int {
+	cutoff := time.Now().Add(-maxAge)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	scrubbed := 0
+	for i := range b.history {
+		h := &b.history[i]
+		if !h.receivedAt.Before(cutoff) {
+			break
+		}
+		if h.event.Address == "" && h.event.Data["address"] == nil {
+			continue
+		}
+		h.event.Address = ""
+		if h.event.Data["address"] != nil {
+			data := make(map[string]interface{}, len(h.event.Data))
+			for k, v := range h.event.Data {
+				if k != "address" {
+					data[k] = v
+				}
+			}
+			h.event.Data = data
+		}
+		scrubbed++
+	}
+	return scrubbed
+}
+
+// Forget drops the replayable events about a callsign and returns how many were dropped
+func (b *EventBus) Forget(callsign string) int {
+	key := normalizeCallsign(callsign)
+	if key == "" {
+		return 0
+	}
+	return b.filter(func(h *busEvent) bool { return normalizeCallsign(h.event.Callsign) != key })
+}
+
+// filter keeps the replayable events for which keep returns true and returns
+// how many were dropped
+func (b *EventBus) filter(keep func(h *busEvent) bool) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	kept := b.history[:0]
+	for i := range b.history {
+		if keep(&b.history[i]) {
+			kept = append(kept, b.history[i])
+		}
+	}
+	dropped := len(b.history) - len(kept)
+	clear(b.history[len(kept):])
+	b.history = kept
+	return dropped
+}
+
+// Dropped returns how many events this subscription missed because its buffer was full
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Close unsubscribes. Events already buffered remain readable from C.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.bus.mu.Lock()
+		delete(s.bus.subs, s)
+		s.bus.mu.Unlock()
+	})
+}
@@ -0,0 +1,87 @@
+package repeater
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+func TestEventBusFanOutAndReplay(t *testing.T) {
+	bus := NewEventBus(10, logger.NewTestLogger(os.Stdout))
+
+	a := bus.Subscribe(4, time.Time{})
+	b := bus.Subscribe(4, time.Time{})
+	bus.Publish(Event{Type: EventConnect, Callsign: "W1AW"})
+
+	for name, sub := range map[string]*Subscription{"a": a, "b": b} {
+		select {
+		case ev := <-sub.C:
+			if ev.Callsign != "W1AW" {
+				t.Errorf("%s: unexpected event %+v", name, ev)
+			}
+		default:
+			t.Fatalf("%s: expected event", name)
+		}
+	}
+
+	b.Close()
+	if bus.Subscribers() != 1 {
+		t.Fatalf("expected 1 subscriber after close, got %d", bus.Subscribers())
+	}
+
+	// Events published after the unsubscribe are replayed on resubscribe
+	gone := time.Now()
+	time.Sleep(time.Millisecond)
+	bus.Publish(Event{Type: EventTalkStart, Callsign: "K1ABC"})
+	bus.Publish(Event{Type: EventTalkEnd, Callsign: "K1ABC"})
+
+	b = bus.Subscribe(1, gone)
+	if len(b.C) != 2 {
+		t.Fatalf("expected 2 replayed events, got %d", len(b.C))
+	}
+	if ev := <-b.C; ev.Type != EventTalkStart {
+		t.Errorf("expected replay in order, got %s first", ev.Type)
+	}
+
+	// A full subscriber drops instead of blocking the bus
+	for i := 0; i < 10; i++ {
+		bus.Publish(Event{Type: EventTalkStart})
+	}
+	if a.Dropped() == 0 {
+		t.Errorf("expected drops on a full subscription")
+	}
+}
+
+func TestEventBusRetention(t *testing.T) {
+	bus := NewEventBus(10, logger.NewTestLogger(os.Stdout))
+	bus.Publish(Event{Type: EventConnect, Callsign: "W1AW", Address: "192.0.2.1:42000"})
+	bus.Publish(Event{Type: EventConnect, Callsign: "K8ABC", Address: "192.0.2.2:42000",
+		Data: map[string]interface{}{"address": "192.0.2.2:42000", "reason": "test"}})
+	bus.Publish(Event{Type: EventTalkStart, Callsign: "N0CALL", Address: "192.0.2.3:42000"})
+
+	// The first two events are a day old
+	bus.history[0].receivedAt = time.Now().Add(-24 * time.Hour)
+	bus.history[1].receivedAt = time.Now().Add(-24 * time.Hour)
+
+	if n := bus.ScrubAddresses(time.Hour); n != 2 {
+		t.Errorf("ScrubAddresses = %d, want 2", n)
+	}
+	if bus.history[1].event.Address != "" || bus.history[1].event.Data["address"] != nil || bus.history[1].event.Data["reason"] != "test" {
+		t.Errorf("unexpected scrubbed event %+v", bus.history[1].event)
+	}
+	if bus.history[2].event.Address == "" {
+		t.Error("expected the recent event to keep its address")
+	}
+
+	if n := bus.Forget("n0call"); n != 1 {
+		t.Errorf("Forget = %d, want 1", n)
+	}
+	if n := bus.Prune(time.Hour); n != 2 {
+		t.Errorf("Prune = %d, want 2", n)
+	}
+	if len(bus.history) != 0 {
+		t.Errorf("expected an empty history, got %d events", len(bus.history))
+	}
+}
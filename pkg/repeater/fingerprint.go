@@ -0,0 +1,150 @@
+package repeater
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Poll padding styles observed in the callsign field of YSFP packets
+const (
+	PaddingSpace = "space"
+	PaddingNull  = "null"
+	PaddingMixed = "mixed"
+	PaddingNone  = "none" // Callsign fills all 10 bytes
+)
+
+// Fingerprint summarizes client behaviour that hints at the repeater's software.
+// Client is a best-effort guess for troubleshooting, not an identification.
+type Fingerprint struct {
+	Client         string  `json:"client"`
+	Padding        string  `json:"padding,omitempty"`
+	PollInterval   float64 `json:"poll_interval,omitempty"`  // Average seconds between polls
+	PollJitter     float64 `json:"poll_jitter_ms,omitempty"` // Average deviation from the poll interval
+	MaxPollGap     float64 `json:"max_poll_gap,omitempty"`   // Longest seconds between two polls
+	Polls          uint64  `json:"polls"`
+	StatusRequests uint64  `json:"status_requests"`
+
+	// KeepaliveWarning is set when the poll cadence is likely to lose the
+	// repeater's NAT mapping (see keepalive.go)
+	KeepaliveWarning string `json:"keepalive_warning,omitempty"`
+}
+
+// fingerprintState accumulates observations for a repeater
+type fingerprintState struct {
+	mu             sync.Mutex
+	padding        string
+	lastPoll       time.Time
+	avgInterval    time.Duration
+	avgJitter      time.Duration
+	maxGap         time.Duration
+	warning        string
+	polls          uint64
+	statusRequests uint64
+}
+
+// observePoll records the padding style and cadence of a poll packet
+func (f *fingerprintState) observePoll(data []byte, at time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(data) >= 14 {
+		f.padding = pollPadding(data[4:14])
+	}
+
+	if !f.lastPoll.IsZero() {
+		interval := at.Sub(f.lastPoll)
+		if interval > f.maxGap {
+			f.maxGap = interval
+		}
+		if f.avgInterval == 0 {
+			f.avgInterval = interval
+		} else {
+			deviation := interval - f.avgInterval
+			if deviation < 0 {
+				deviation = -deviation
+			}
+			f.avgJitter = (f.avgJitter*7 + deviation) / 8
+
+			// Exponential moving average; polls are periodic so this settles quickly
+			f.avgInterval = (f.avgInterval*7 + interval) / 8
+		}
+	}
+	f.lastPoll = at
+	f.polls++
+}
+
+// observeStatusRequest records a YSFS status request from the repeater's address
+func (f *fingerprintState) observeStatusRequest() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.statusRequests++
+}
+
+// snapshot returns the fingerprint, or nil if nothing has been observed yet
+func (f *fingerprintState) snapshot() *Fingerprint {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.polls == 0 && f.statusRequests == 0 {
+		return nil
+	}
+
+	fp := &Fingerprint{
+		Padding:        f.padding,
+		PollInterval:   math.Round(f.avgInterval.Seconds()*10) / 10,
+		PollJitter:     millis(f.avgJitter),
+		MaxPollGap:     math.Round(f.maxGap.Seconds()*10) / 10,
+		Polls:          f.polls,
+		StatusRequests: f.statusRequests,
+
+		KeepaliveWarning: f.warning,
+	}
+	fp.Client = classifyClient(fp)
+	return fp
+}
+
+// pollPadding classifies the bytes following the callsign in a 10-byte field
+func pollPadding(field []byte) string {
+	end := len(field)
+	for end > 0 && (field[end-1] == ' ' || field[end-1] == 0) {
+		end--
+	}
+	if end == len(field) {
+		return PaddingNone
+	}
+
+	spaces, nulls := 0, 0
+	for _, b := range field[end:] {
+		if b == ' ' {
+			spaces++
+		} else {
+			nulls++
+		}
+	}
+	switch {
+	case nulls == 0:
+		return PaddingSpace
+	case spaces == 0:
+		return PaddingNull
+	default:
+		return PaddingMixed
+	}
+}
+
+// classifyClient guesses the client software from the observed behaviour.
+// MMDVM YSFGateway (used by Pi-Star and WPSD) space-pads its callsign and polls every 5 seconds.
+func classifyClient(fp *Fingerprint) string {
+	switch {
+	case fp.Padding == PaddingSpace && fp.PollInterval >= 4 && fp.PollInterval <= 6:
+		return "YSFGateway (MMDVMHost/Pi-Star/WPSD)"
+	case fp.Padding == PaddingSpace:
+		return "YSFGateway-compatible"
+	case fp.Padding == PaddingNull:
+		return "non-MMDVM client (NUL-padded poll)"
+	case fp.Padding == PaddingMixed:
+		return "non-standard client (mixed padding)"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,51 @@
+package repeater
+
+import (
+	"testing"
+	"time"
+)
+
+func pollPacket(field string) []byte {
+	data := make([]byte, 14)
+	copy(data, "YSFP")
+	copy(data[4:], field)
+	return data
+}
+
+func TestPollPadding(t *testing.T) {
+	tests := map[string]string{
+		"W1AW      ":                   PaddingSpace,
+		"W1AW\x00\x00\x00\x00\x00\x00": PaddingNull,
+		"W1AW \x00\x00\x00\x00\x00":    PaddingMixed,
+		"W1AW-12345":                   PaddingNone,
+	}
+	for field, want := range tests {
+		if got := pollPadding(pollPacket(field)[4:14]); got != want {
+			t.Errorf("pollPadding(%q) = %s, want %s", field, got, want)
+		}
+	}
+}
+
+func TestFingerprintClassifiesYSFGateway(t *testing.T) {
+	r := NewRepeater("W1AW", mustAddr(t, "127.0.0.1:45001"))
+	if r.Fingerprint() != nil {
+		t.Fatalf("expected no fingerprint before any poll")
+	}
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		r.ObservePoll(pollPacket("W1AW      "), start.Add(time.Duration(i)*5*time.Second))
+	}
+	r.ObserveStatusRequest()
+
+	fp := r.Fingerprint()
+	if fp.Client != "YSFGateway (MMDVMHost/Pi-Star/WPSD)" {
+		t.Errorf("unexpected client guess %q", fp.Client)
+	}
+	if fp.PollInterval != 5 || fp.Polls != 4 || fp.StatusRequests != 1 {
+		t.Errorf("unexpected fingerprint %+v", fp)
+	}
+	if r.Stats().Fingerprint == nil {
+		t.Errorf("expected fingerprint in repeater stats")
+	}
+}
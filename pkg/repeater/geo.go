@@ -0,0 +1,185 @@
+package repeater
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// Geo is the country and network an address belongs to
+type Geo struct {
+	Country     string `json:"country,omitempty"` // ISO 3166-1 alpha-2 code
+	CountryName string `json:"country_name,omitempty"`
+	ASN         uint   `json:"asn,omitempty"`
+	ASOrg       string `json:"as_org,omitempty"`
+}
+
+// GeoLookup returns the location of ip, and false when nothing is known
+type GeoLookup func(ip net.IP) (Geo, bool)
+
+// GeoPolicy restricts new connections by country
+type GeoPolicy struct {
+	// AllowCountries, when set, admits only these countries
+	AllowCountries []string
+	// DenyCountries rejects these countries
+	DenyCountries []string
+	// AllowUnknown admits addresses without a country when AllowCountries is set
+	AllowUnknown bool
+	// AlertNewCountry emits a new_country event the first time a country
+	// connects after startup
+	AlertNewCountry bool
+}
+
+// geoState holds the lookup, the policy and the countries seen so far
+type geoState struct {
+	mu       sync.Mutex
+	lookup   GeoLookup
+	allow    map[string]bool
+	deny     map[string]bool
+	unknown  bool
+	alertNew bool
+	seen     map[string]bool
+	// notified maps IP -> last geo_blocked event time, to avoid flooding
+	// events. Entries older than a minute are pruned on insert.
+	notified map[string]time.Time
+}
+
+// SetGeo enables geo enrichment of new repeaters and applies policy to them
+func (m *Manager) SetGeo(lookup GeoLookup, policy GeoPolicy) {
+	m.geo.mu.Lock()
+	defer m.geo.mu.Unlock()
+
+	m.geo.lookup = lookup
+	m.geo.allow = countrySet(policy.AllowCountries)
+	m.geo.deny = countrySet(policy.DenyCountries)
+	m.geo.unknown = policy.AllowUnknown
+	m.geo.alertNew = policy.AlertNewCountry
+	m.geo.seen = make(map[string]bool)
+	m.geo.notified = make(map[string]time.Time)
+}
+
+// countrySet builds an upper-case set of country codes
+func countrySet(codes []string) map[string]bool {
+	set := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		set[strings.ToUpper(code)] = true
+	}
+	return set
+}
+
+// checkGeo looks up addr and applies the country policy. It returns the
+// location (nil without a lookup or result) and whether the connection may
+// proceed.
+func (m *Manager) checkGeo(callsign string, addr *net.UDPAddr) (*Geo, bool) {
+	m.geo.mu.Lock()
+	lookup := m.geo.lookup
+	m.geo.mu.Unlock()
+	if lookup == nil {
+		return nil, true
+	}
+
+	var geo *Geo
+	if g, ok := lookup(addr.IP); ok {
+		geo = &g
+	}
+	country := ""
+	if geo != nil {
+		country = strings.ToUpper(geo.Country)
+	}
+
+	m.geo.mu.Lock()
+	allowed := !m.geo.deny[country] || country == ""
+	if allowed && len(m.geo.allow) > 0 {
+		allowed = m.geo.allow[country] || (country == "" && m.geo.unknown)
+	}
+	m.geo.mu.Unlock()
+
+	if !allowed {
+		m.rejectGeo(callsign, addr, geo)
+	}
+	return geo, allowed
+}
+
+// rejectGeo records a connection rejected by the country policy.
+// The geo_blocked event is sent at most once per minute per IP.
+func (m *Manager) rejectGeo(callsign string, addr *net.UDPAddr, geo *Geo) {
+	m.mu.Lock()
+	m.metrics.GeoRejections++
+	m.mu.Unlock()
+
+	ip := addr.IP.String()
+	now := m.clock.Now()
+	m.geo.mu.Lock()
+	for k, at := range m.geo.notified {
+		if now.Sub(at) >= time.Minute {
+			delete(m.geo.notified, k)
+		}
+	}
+	if _, recent := m.geo.notified[ip]; recent {
+		m.geo.mu.Unlock()
+		return
+	}
+	m.geo.notified[ip] = now
+	m.geo.mu.Unlock()
+
+	if m.logger != nil {
+		m.logger.Warn("Connection rejected by country policy",
+			logger.String("callsign", callsign),
+			logger.String("from", addr.String()),
+			logger.Any("geo", geo))
+	}
+	m.emit(Event{
+		Type:      EventGeoBlocked,
+		Callsign:  callsign,
+		Address:   addr.String(),
+		Timestamp: now,
+		Data:      geoData(geo),
+	})
+}
+
+// observeCountry emits a new_country event the first time an accepted
+// repeater connects from a country
+func (m *Manager) observeCountry(callsign string, addr *net.UDPAddr, geo *Geo) {
+	if geo == nil || geo.Country == "" {
+		return
+	}
+	country := strings.ToUpper(geo.Country)
+
+	m.geo.mu.Lock()
+	first := !m.geo.seen[country]
+	m.geo.seen[country] = true
+	alert := m.geo.alertNew
+	m.geo.mu.Unlock()
+
+	if !first || !alert {
+		return
+	}
+	if m.logger != nil {
+		m.logger.Info("First connection from a new country",
+			logger.String("country", country),
+			logger.String("callsign", callsign))
+	}
+	m.emit(Event{
+		Type:      EventNewCountry,
+		Callsign:  callsign,
+		Address:   addr.String(),
+		Timestamp: m.clock.Now(),
+		Data:      geoData(geo),
+	})
+}
+
+// geoData returns the event data for a location
+func geoData(geo *Geo) map[string]interface{} {
+	if geo == nil {
+		return map[string]interface{}{"country": ""}
+	}
+	return map[string]interface{}{
+		"country":      geo.Country,
+		"country_name": geo.CountryName,
+		"asn":          geo.ASN,
+		"as_org":       geo.ASOrg,
+	}
+}
@@ -0,0 +1,154 @@
+package repeater
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/clock"
+)
+
+// testGeo maps the first octet of an address to a country
+func testGeo(ip net.IP) (Geo, bool) {
+	switch ip.To4()[0] {
+	case 10:
+		return Geo{Country: "US", CountryName: "United States", ASN: 64500, ASOrg: "Example Net"}, true
+	case 20:
+		return Geo{Country: "CA"}, true
+	case 30:
+		return Geo{Country: "RU"}, true
+	}
+	return Geo{}, false
+}
+
+func TestGeoEnrichment(t *testing.T) {
+	m := NewManager(5*time.Second, 10, nil, 180*time.Second, 0)
+	m.SetGeo(testGeo, GeoPolicy{})
+
+	r, ok := m.AddRepeater("W1AW", mustAddr(t, "10.0.0.1:42000"))
+	if !ok {
+		t.Fatal("expected repeater to be added")
+	}
+	geo := r.Stats().Geo
+	if geo == nil || geo.Country != "US" || geo.ASN != 64500 {
+		t.Fatalf("unexpected geo %+v", geo)
+	}
+
+	r, ok = m.AddRepeater("N0CALL", mustAddr(t, "192.0.2.1:42000"))
+	if !ok {
+		t.Fatal("expected repeater without location to be added")
+	}
+	if r.Stats().Geo != nil {
+		t.Errorf("expected no geo for unknown address, got %+v", r.Stats().Geo)
+	}
+}
+
+func TestGeoAllowPolicy(t *testing.T) {
+	events := make(chan Event, 10)
+	m := NewManager(5*time.Second, 10, events, 180*time.Second, 0)
+	m.SetGeo(testGeo, GeoPolicy{AllowCountries: []string{"us", "CA"}})
+
+	if _, ok := m.AddRepeater("W1AW", mustAddr(t, "10.0.0.1:42000")); !ok {
+		t.Error("expected allowed country to connect")
+	}
+	if _, ok := m.AddRepeater("VE3ABC", mustAddr(t, "20.0.0.1:42000")); !ok {
+		t.Error("expected allowed country to connect")
+	}
+	if _, ok := m.AddRepeater("R1ABC", mustAddr(t, "30.0.0.1:42000")); ok {
+		t.Error("expected country outside the allow list to be rejected")
+	}
+	if _, ok := m.AddRepeater("N0CALL", mustAddr(t, "192.0.2.1:42000")); ok {
+		t.Error("expected unknown country to be rejected without allow_unknown")
+	}
+	// A repeat attempt from the same IP does not send another event
+	if _, ok := m.AddRepeater("R1ABC", mustAddr(t, "30.0.0.1:42001")); ok {
+		t.Error("expected country outside the allow list to be rejected")
+	}
+
+	if got := m.GetStats().GeoRejections; got != 3 {
+		t.Errorf("expected 3 geo rejections, got %d", got)
+	}
+	blocked := 0
+	for len(events) > 0 {
+		if ev := <-events; ev.Type == EventGeoBlocked {
+			blocked++
+		}
+	}
+	if blocked != 2 {
+		t.Errorf("expected 2 geo_blocked events, got %d", blocked)
+	}
+}
+
+func TestGeoDenyPolicyAndUnknown(t *testing.T) {
+	m := NewManager(5*time.Second, 10, nil, 180*time.Second, 0)
+	m.SetGeo(testGeo, GeoPolicy{DenyCountries: []string{"RU"}})
+
+	if _, ok := m.AddRepeater("R1ABC", mustAddr(t, "30.0.0.1:42000")); ok {
+		t.Error("expected denied country to be rejected")
+	}
+	if _, ok := m.AddRepeater("W1AW", mustAddr(t, "10.0.0.1:42000")); !ok {
+		t.Error("expected other countries to connect")
+	}
+	if _, ok := m.AddRepeater("N0CALL", mustAddr(t, "192.0.2.1:42000")); !ok {
+		t.Error("expected unknown country to connect with a deny list")
+	}
+
+	m.SetGeo(testGeo, GeoPolicy{AllowCountries: []string{"US"}, AllowUnknown: true})
+	if _, ok := m.AddRepeater("N0CALL", mustAddr(t, "192.0.2.2:42000")); !ok {
+		t.Error("expected unknown country to connect with allow_unknown")
+	}
+}
+
+func TestGeoNewCountryEvent(t *testing.T) {
+	events := make(chan Event, 10)
+	m := NewManager(5*time.Second, 10, events, 180*time.Second, 0)
+	m.SetGeo(testGeo, GeoPolicy{AlertNewCountry: true})
+
+	m.AddRepeater("W1AW", mustAddr(t, "10.0.0.1:42000"))
+	m.AddRepeater("K1ABC", mustAddr(t, "10.0.0.2:42000"))
+	m.AddRepeater("VE3ABC", mustAddr(t, "20.0.0.1:42000"))
+
+	var countries []interface{}
+	for len(events) > 0 {
+		if ev := <-events; ev.Type == EventNewCountry {
+			countries = append(countries, ev.Data["country"])
+		}
+	}
+	if len(countries) != 2 || countries[0] != "US" || countries[1] != "CA" {
+		t.Errorf("expected new_country for US then CA, got %v", countries)
+	}
+}
+
+func TestGeoNotifiedPruned(t *testing.T) {
+	events := make(chan Event, 10)
+	m := NewManager(5*time.Second, 10, events, 180*time.Second, 0)
+	clk := clock.NewFake(time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC))
+	m.SetClock(clk)
+	m.SetGeo(testGeo, GeoPolicy{DenyCountries: []string{"RU"}})
+
+	for i := 1; i <= 3; i++ {
+		m.AddRepeater("R1ABC", mustAddr(t, fmt.Sprintf("30.0.0.%d:42000", i)))
+	}
+	clk.Advance(time.Minute)
+	m.AddRepeater("R1ABC", mustAddr(t, "30.0.0.9:42000"))
+
+	m.geo.mu.Lock()
+	notified := len(m.geo.notified)
+	m.geo.mu.Unlock()
+	if notified != 1 {
+		t.Errorf("expected addresses older than a minute to be pruned, %d left", notified)
+	}
+
+	// A pruned address is notified again
+	m.AddRepeater("R1ABC", mustAddr(t, "30.0.0.1:42000"))
+	blocked := 0
+	for len(events) > 0 {
+		if ev := <-events; ev.Type == EventGeoBlocked {
+			blocked++
+		}
+	}
+	if blocked != 5 {
+		t.Errorf("expected 5 geo_blocked events, got %d", blocked)
+	}
+}
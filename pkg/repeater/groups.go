@@ -0,0 +1,196 @@
+package repeater
+
+import (
+	"fmt"
+	"net"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Group is a named set of repeaters selected by gateway callsign
+type Group struct {
+	Name string `json:"name"`
+	// Callsigns are upper-case shell patterns (e.g. "W1*") matched against
+	// the gateway callsign a repeater connected with
+	Callsigns []string `json:"callsigns"`
+	// NoBridge keeps members off the bridges: their traffic is not forwarded
+	// to bridges and bridge traffic is not sent to them
+	NoBridge bool `json:"no_bridge"`
+}
+
+// GroupStatus is a group with its connected members
+type GroupStatus struct {
+	Group
+	Members []string `json:"members"`
+}
+
+// groupList holds the repeater groups keyed by lower-case name
+type groupList struct {
+	mu     sync.RWMutex
+	groups map[string]Group
+}
+
+// normalizeGroup validates g and upper-cases its patterns
+func normalizeGroup(g Group) (Group, error) {
+	g.Name = strings.TrimSpace(g.Name)
+	if g.Name == "" {
+		return Group{}, fmt.Errorf("group name is required")
+	}
+
+	patterns := make([]string, 0, len(g.Callsigns))
+	for _, pattern := range g.Callsigns {
+		pattern = normalizeCallsign(pattern)
+		if pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return Group{}, fmt.Errorf("invalid callsign pattern %q: %w", pattern, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	g.Callsigns = patterns
+	return g, nil
+}
+
+// SetGroups replaces all repeater groups
+func (m *Manager) SetGroups(groups []Group) error {
+	set := make(map[string]Group, len(groups))
+	for _, g := range groups {
+		g, err := normalizeGroup(g)
+		if err != nil {
+			return err
+		}
+		set[strings.ToLower(g.Name)] = g
+	}
+
+	m.groups.mu.Lock()
+	m.groups.groups = set
+	m.groups.mu.Unlock()
+	m.invalidateRoster()
+	return nil
+}
+
+// PutGroup adds a group or replaces the one with the same name
+func (m *Manager) PutGroup(g Group) error {
+	g, err := normalizeGroup(g)
+	if err != nil {
+		return err
+	}
+
+	m.groups.mu.Lock()
+	if m.groups.groups == nil {
+		m.groups.groups = make(map[string]Group)
+	}
+	m.groups.groups[strings.ToLower(g.Name)] = g
+	m.groups.mu.Unlock()
+	m.invalidateRoster()
+	return nil
+}
+
+// DeleteGroup removes a group. It reports whether the group existed.
+func (m *Manager) DeleteGroup(name string) bool {
+	key := strings.ToLower(strings.TrimSpace(name))
+
+	m.groups.mu.Lock()
+	_, ok := m.groups.groups[key]
+	delete(m.groups.groups, key)
+	m.groups.mu.Unlock()
+	if ok {
+		m.invalidateRoster()
+	}
+	return ok
+}
+
+// GetGroups returns the groups in name order with their connected members
+func (m *Manager) GetGroups() []GroupStatus {
+	m.groups.mu.RLock()
+	groups := make([]GroupStatus, 0, len(m.groups.groups))
+	for _, g := range m.groups.groups {
+		groups = append(groups, GroupStatus{Group: g, Members: []string{}})
+	}
+	m.groups.mu.RUnlock()
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+
+	m.repeaters.Range(func(key, value interface{}) bool {
+		if r, ok := value.(*Repeater); ok {
+			for i := range groups {
+				if groupMatches(groups[i].Group, r.Callsign()) {
+					groups[i].Members = append(groups[i].Members, r.Callsign())
+				}
+			}
+		}
+		return true
+	})
+	for i := range groups {
+		sort.Strings(groups[i].Members)
+	}
+	return groups
+}
+
+// GroupsOf returns the names of the groups a gateway callsign belongs to, in order
+func (m *Manager) GroupsOf(callsign string) []string {
+	m.groups.mu.RLock()
+	defer m.groups.mu.RUnlock()
+
+	var names []string
+	for _, g := range m.groups.groups {
+		if groupMatches(g, callsign) {
+			names = append(names, g.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsNoBridge reports whether a gateway callsign belongs to a group that is
+// kept off the bridges
+func (m *Manager) IsNoBridge(callsign string) bool {
+	m.groups.mu.RLock()
+	defer m.groups.mu.RUnlock()
+
+	for _, g := range m.groups.groups {
+		if g.NoBridge && groupMatches(g, callsign) {
+			return true
+		}
+	}
+	return false
+}
+
+// GroupAddresses returns the addresses of the connected members of a group,
+// and false when there is no such group
+func (m *Manager) GroupAddresses(name string) ([]*net.UDPAddr, bool) {
+	m.groups.mu.RLock()
+	g, ok := m.groups.groups[strings.ToLower(strings.TrimSpace(name))]
+	m.groups.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+
+	var addresses []*net.UDPAddr
+	for _, r := range m.currentRoster().repeaters {
+		if groupMatches(g, r.Callsign()) {
+			addresses = append(addresses, r.Address())
+		}
+	}
+	return addresses, true
+}
+
+// BridgedAddresses returns the addresses of all repeaters that may receive
+// bridge traffic, leaving out members of no-bridge groups. The slice is
+// shared with other callers and must not be modified.
+func (m *Manager) BridgedAddresses() []*net.UDPAddr {
+	return m.currentRoster().bridged
+}
+
+// groupMatches reports whether a gateway callsign matches one of g's patterns
+func groupMatches(g Group, callsign string) bool {
+	callsign = normalizeCallsign(callsign)
+	for _, pattern := range g.Callsigns {
+		if ok, _ := path.Match(pattern, callsign); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package repeater
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGroupsMatchConnectedRepeaters(t *testing.T) {
+	events := make(chan Event, 20)
+	m := NewManager(5*time.Second, 10, events, 180*time.Second, 0)
+	if err := m.SetGroups([]Group{
+		{Name: "local", Callsigns: []string{"w1*"}, NoBridge: true},
+		{Name: "club", Callsigns: []string{"W1AW", "N1ABC"}},
+	}); err != nil {
+		t.Fatalf("SetGroups: %v", err)
+	}
+	w1aw := mustAddr(t, "127.0.0.1:45060")
+	w1xyz := mustAddr(t, "127.0.0.1:45061")
+	n1abc := mustAddr(t, "127.0.0.1:45062")
+	k2xx := mustAddr(t, "127.0.0.1:45063")
+	m.AddRepeater("W1AW", w1aw)
+	m.AddRepeater("W1XYZ", w1xyz)
+	m.AddRepeater("N1ABC", n1abc)
+	m.AddRepeater("K2XX", k2xx)
+
+	if got := m.GroupsOf("W1AW"); len(got) != 2 || got[0] != "club" || got[1] != "local" {
+		t.Errorf("expected W1AW in club and local, got %v", got)
+	}
+	if !m.IsNoBridge("w1xyz") || m.IsNoBridge("N1ABC") {
+		t.Errorf("expected only local members to be no-bridge")
+	}
+
+	addresses, ok := m.GroupAddresses("CLUB")
+	if !ok || len(addresses) != 2 {
+		t.Fatalf("expected 2 club addresses, got %v (%v)", addresses, ok)
+	}
+	if _, ok := m.GroupAddresses("missing"); ok {
+		t.Errorf("expected unknown group to be reported")
+	}
+
+	bridged := m.BridgedAddresses()
+	if len(bridged) != 2 {
+		t.Fatalf("expected 2 bridged addresses, got %v", bridged)
+	}
+	for _, addr := range bridged {
+		if addr.String() == w1aw.String() || addr.String() == w1xyz.String() {
+			t.Errorf("no-bridge member %s must not receive bridge traffic", addr)
+		}
+	}
+
+	groups := m.GetGroups()
+	if len(groups) != 2 || groups[1].Name != "local" || len(groups[1].Members) != 2 {
+		t.Errorf("unexpected groups %+v", groups)
+	}
+
+	page, err := m.QueryRepeaters(RepeaterQuery{Group: "local"})
+	if err != nil || page.Total != 2 {
+		t.Fatalf("expected 2 local repeaters, got %d (%v)", page.Total, err)
+	}
+	if got := page.Repeaters[0].Groups; len(got) != 2 {
+		t.Errorf("expected stats to list the groups, got %v", got)
+	}
+	if _, err := m.QueryRepeaters(RepeaterQuery{Group: "missing"}); err == nil {
+		t.Errorf("expected an error for an unknown group")
+	}
+}
+
+func TestGroupsRuntimeChanges(t *testing.T) {
+	m := NewManager(5*time.Second, 10, make(chan Event, 10), 180*time.Second, 0)
+
+	if err := m.PutGroup(Group{Name: " ", Callsigns: []string{"W1*"}}); err == nil {
+		t.Errorf("expected an error for an empty name")
+	}
+	if err := m.PutGroup(Group{Name: "bad", Callsigns: []string{"W1["}}); err == nil {
+		t.Errorf("expected an error for an invalid pattern")
+	}
+	if err := m.PutGroup(Group{Name: "east", Callsigns: []string{"W1*"}}); err != nil {
+		t.Fatalf("PutGroup: %v", err)
+	}
+	if err := m.PutGroup(Group{Name: "East", Callsigns: []string{"W2*"}, NoBridge: true}); err != nil {
+		t.Fatalf("PutGroup: %v", err)
+	}
+	if groups := m.GetGroups(); len(groups) != 1 || groups[0].Callsigns[0] != "W2*" {
+		t.Fatalf("expected the group to be replaced, got %+v", groups)
+	}
+	if !m.IsNoBridge("W2ABC") || m.IsNoBridge("W1ABC") {
+		t.Errorf("expected the replaced patterns to apply")
+	}
+
+	if !m.DeleteGroup("EAST") {
+		t.Fatalf("expected the group to be deleted")
+	}
+	if m.DeleteGroup("east") || m.IsNoBridge("W2ABC") {
+		t.Errorf("expected the group to be gone")
+	}
+}
@@ -0,0 +1,252 @@
+package repeater
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// Hold events: a net control hold started or ended, and a transmission muted
+// because its callsign is not cleared to talk during the hold
+const (
+	EventHoldStarted = "hold_started"
+	EventHoldEnded   = "hold_ended"
+	EventHoldMuted   = "hold_muted"
+)
+
+// DefaultHoldMessage is sent with hold_muted events unless configured otherwise
+const DefaultHoldMessage = "The net is holding for priority traffic, please stand by"
+
+// ErrHoldDuration is returned for a hold longer than the configured maximum
+var ErrHoldDuration = errors.New("hold duration exceeds the maximum")
+
+// HoldStatus describes the current hold for net control
+type HoldStatus struct {
+	Active    bool      `json:"active"`
+	Callsigns []string  `json:"callsigns,omitempty"` // Callsigns cleared to talk
+	Reason    string    `json:"reason,omitempty"`
+	StartedAt time.Time `json:"started_at,omitzero"`
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
+	Muted     uint64    `json:"muted"` // Transmissions muted during this hold
+}
+
+// HoldPolicy sets how long holds last and what muted stations are told
+type HoldPolicy struct {
+	Duration    time.Duration // Used when a hold is started without one
+	MaxDuration time.Duration // 0 = no limit
+	Message     string
+}
+
+// holdState is the net control hold. While it is active only the cleared
+// callsigns may claim the channel; other transmissions are dropped with one
+// hold_muted event each. It ends on request or when it expires.
+type holdState struct {
+	mu        sync.Mutex
+	policy    HoldPolicy
+	active    bool
+	callsigns map[string]bool
+	reason    string
+	startedAt time.Time
+	expiresAt time.Time
+	muted     uint64
+	// lastDrop maps callsign -> last dropped frame, to emit one event per transmission
+	lastDrop map[string]time.Time
+}
+
+// SetHoldPolicy sets the default and maximum hold duration and the message
+// sent to muted stations
+func (m *Manager) SetHoldPolicy(policy HoldPolicy) {
+	m.hold.mu.Lock()
+	defer m.hold.mu.Unlock()
+	m.hold.policy = policy
+}
+
+// StartHold puts the reflector on hold for priority traffic: only the given
+// callsigns may transmit until the hold ends or expires. A zero duration uses
+// the policy default. Starting a hold while one is active replaces it.
+func (m *Manager) StartHold(callsigns []string, duration time.Duration, reason string) (HoldStatus, error) {
+	m.hold.mu.Lock()
+	if duration <= 0 {
+		duration = m.hold.policy.Duration
+	}
+	if max := m.hold.policy.MaxDuration; max > 0 && duration > max {
+		m.hold.mu.Unlock()
+		return HoldStatus{}, ErrHoldDuration
+	}
+
+	now := m.clock.Now()
+	m.hold.active = true
+	m.hold.callsigns = make(map[string]bool, len(callsigns))
+	for _, callsign := range callsigns {
+		if key := normalizeCallsign(callsign); key != "" {
+			m.hold.callsigns[key] = true
+		}
+	}
+	m.hold.reason = reason
+	m.hold.startedAt = now
+	m.hold.expiresAt = time.Time{}
+	if duration > 0 {
+		m.hold.expiresAt = now.Add(duration)
+	}
+	m.hold.muted = 0
+	m.hold.lastDrop = make(map[string]time.Time)
+	status := m.hold.statusLocked()
+	m.hold.mu.Unlock()
+
+	if m.logger != nil {
+		m.logger.Info("Net control hold started",
+			logger.Any("callsigns", status.Callsigns),
+			logger.Duration("duration", duration),
+			logger.String("reason", reason))
+	}
+	m.emit(Event{
+		Type:      EventHoldStarted,
+		Timestamp: now,
+		Duration:  duration,
+		Data: map[string]interface{}{
+			"callsigns":  status.Callsigns,
+			"reason":     reason,
+			"expires_at": status.ExpiresAt,
+		},
+	})
+	return status, nil
+}
+
+// EndHold lifts the hold. It reports whether a hold was active.
+func (m *Manager) EndHold() bool {
+	return m.endHold("ended")
+}
+
+// GetHold returns the current hold
+func (m *Manager) GetHold() HoldStatus {
+	m.expireHold()
+
+	m.hold.mu.Lock()
+	defer m.hold.mu.Unlock()
+	return m.hold.statusLocked()
+}
+
+// IsHeld reports whether a callsign is muted by the hold
+func (m *Manager) IsHeld(callsign string) bool {
+	m.hold.mu.Lock()
+	active := m.hold.active
+	expired := active && !m.hold.expiresAt.IsZero() && !m.clock.Now().Before(m.hold.expiresAt)
+	cleared := m.hold.callsigns[normalizeCallsign(callsign)]
+	m.hold.mu.Unlock()
+
+	if expired {
+		m.endHold("expired")
+		return false
+	}
+	return active && !cleared
+}
+
+// expireHold ends the hold once it has run its time
+func (m *Manager) expireHold() {
+	m.hold.mu.Lock()
+	expired := m.hold.active && !m.hold.expiresAt.IsZero() && !m.clock.Now().Before(m.hold.expiresAt)
+	m.hold.mu.Unlock()
+
+	if expired {
+		m.endHold("expired")
+	}
+}
+
+// endHold lifts the hold and sends hold_ended with the given outcome
+func (m *Manager) endHold(outcome string) bool {
+	m.hold.mu.Lock()
+	if !m.hold.active {
+		m.hold.mu.Unlock()
+		return false
+	}
+	now := m.clock.Now()
+	duration := now.Sub(m.hold.startedAt)
+	muted := m.hold.muted
+	reason := m.hold.reason
+	m.hold.active = false
+	m.hold.callsigns = nil
+	m.hold.lastDrop = nil
+	m.hold.mu.Unlock()
+
+	if m.logger != nil {
+		m.logger.Info("Net control hold "+outcome,
+			logger.Duration("duration", duration),
+			logger.Uint64("muted", muted))
+	}
+	m.emit(Event{
+		Type:      EventHoldEnded,
+		Timestamp: now,
+		Duration:  duration,
+		Data: map[string]interface{}{
+			"outcome": outcome,
+			"reason":  reason,
+			"muted":   muted,
+		},
+	})
+	return true
+}
+
+// dropHeld counts a dropped frame from a callsign muted by the hold and
+// emits a hold_muted event for the first frame of a transmission
+func (m *Manager) dropHeld(callsign string, repeater *Repeater, now time.Time) {
+	key := normalizeCallsign(callsign)
+	m.hold.mu.Lock()
+	last, seen := m.hold.lastDrop[key]
+	if m.hold.lastDrop != nil {
+		m.hold.lastDrop[key] = now
+	}
+	notify := !seen || now.Sub(last) > talkIdleTimeout
+	if notify {
+		m.hold.muted++
+	}
+	message := m.hold.policy.Message
+	reason := m.hold.reason
+	m.hold.mu.Unlock()
+
+	if !notify {
+		return
+	}
+	if message == "" {
+		message = DefaultHoldMessage
+	}
+
+	if m.logger != nil {
+		m.logger.Info("Muting transmission during net control hold",
+			logger.String("callsign", callsign),
+			logger.String("gateway", repeater.Callsign()))
+	}
+	m.emit(Event{
+		Type:      EventHoldMuted,
+		Callsign:  callsign,
+		Address:   repeater.Address().String(),
+		Gateway:   repeater.Callsign(),
+		Timestamp: now,
+		Data: map[string]interface{}{
+			"message": message,
+			"reason":  reason,
+		},
+	})
+}
+
+// statusLocked returns the hold status; the caller holds mu
+func (h *holdState) statusLocked() HoldStatus {
+	if !h.active {
+		return HoldStatus{}
+	}
+	callsigns := make([]string, 0, len(h.callsigns))
+	for callsign := range h.callsigns {
+		callsigns = append(callsigns, callsign)
+	}
+	sort.Strings(callsigns)
+	return HoldStatus{
+		Active:    true,
+		Callsigns: callsigns,
+		Reason:    h.reason,
+		StartedAt: h.startedAt,
+		ExpiresAt: h.expiresAt,
+		Muted:     h.muted,
+	}
+}
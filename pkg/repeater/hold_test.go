@@ -0,0 +1,70 @@
+package repeater
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/clock"
+)
+
+func TestHoldMutesUnclearedCallsigns(t *testing.T) {
+	events := make(chan Event, 20)
+	m := NewManager(5*time.Second, 10, events, 180*time.Second, 0)
+	clk := clock.NewFake(time.Now())
+	m.SetClock(clk)
+	m.SetHoldPolicy(HoldPolicy{Duration: 10 * time.Minute, MaxDuration: time.Hour})
+	netGW := mustAddr(t, "127.0.0.1:45050")
+	other := mustAddr(t, "127.0.0.1:45051")
+	m.AddRepeater("NETGW", netGW)
+	m.AddRepeater("GATEWAY", other)
+	<-events
+	<-events // connects
+
+	if _, err := m.StartHold([]string{"w1nc"}, 2*time.Hour, ""); err != ErrHoldDuration {
+		t.Fatalf("expected ErrHoldDuration, got %v", err)
+	}
+	status, err := m.StartHold([]string{"w1nc"}, 0, "emergency traffic")
+	if err != nil {
+		t.Fatalf("StartHold: %v", err)
+	}
+	if !status.Active || status.Callsigns[0] != "W1NC" || !status.ExpiresAt.Equal(clk.Now().Add(10*time.Minute)) {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	if ev := <-events; ev.Type != EventHoldStarted {
+		t.Fatalf("expected hold_started, got %+v", ev)
+	}
+
+	for i := 0; i < 3; i++ {
+		m.ProcessPacket("K2XX", other, "YSFD", 155)
+	}
+	if m.HoldsChannel(other) {
+		t.Fatal("muted callsign must not take the channel")
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected one hold_muted event per transmission, got %d", len(events))
+	}
+	if ev := <-events; ev.Type != EventHoldMuted || ev.Callsign != "K2XX" || ev.Data["message"] != DefaultHoldMessage {
+		t.Errorf("unexpected event %+v", ev)
+	}
+
+	m.ProcessPacket("W1NC", netGW, "YSFD", 155)
+	if !m.HoldsChannel(netGW) {
+		t.Error("cleared callsign must be able to talk")
+	}
+	<-events // talk_start
+	if got := m.GetHold().Muted; got != 1 {
+		t.Errorf("Muted = %d, want 1", got)
+	}
+
+	clk.Advance(10 * time.Minute)
+	m.expireHold()
+	if ev := <-events; ev.Type != EventHoldEnded || ev.Data["outcome"] != "expired" {
+		t.Errorf("expected hold_ended on expiry, got %+v", ev)
+	}
+	if m.GetHold().Active || m.IsHeld("K2XX") {
+		t.Error("hold must be lifted after it expires")
+	}
+	if m.EndHold() {
+		t.Error("EndHold must report no active hold")
+	}
+}
@@ -0,0 +1,109 @@
+package repeater
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// Keepalive assessment. The poll cadence measured for fingerprinting also
+// tells whether a repeater keeps its NAT mapping open: a hotspot that polls
+// less often than a typical router keeps UDP mappings, or whose polls arrive
+// erratically, will keep dropping. Each repeater carries its current warning
+// in its fingerprint, and a keepalive_warning event is sent when it changes.
+
+// Keepalive warnings
+const (
+	// KeepaliveNATTimeout: polls are further apart than the NAT timeout
+	KeepaliveNATTimeout = "nat_timeout"
+	// KeepaliveErratic: the deviation between polls is large relative to the interval
+	KeepaliveErratic = "erratic"
+)
+
+// keepaliveMinIntervals is how many poll intervals are measured before a
+// repeater is assessed
+const keepaliveMinIntervals = 4
+
+// KeepalivePolicy sets when a repeater's poll cadence is reported
+type KeepalivePolicy struct {
+	NATTimeout   time.Duration // Warn when polls are this far apart on average (0 = off)
+	ErraticRatio float64       // Warn when poll jitter exceeds this fraction of the interval (0 = off)
+}
+
+// keepaliveState holds the keepalive policy
+type keepaliveState struct {
+	mu     sync.RWMutex
+	policy KeepalivePolicy
+}
+
+// SetKeepalivePolicy sets the thresholds for keepalive warnings
+func (m *Manager) SetKeepalivePolicy(policy KeepalivePolicy) {
+	m.keepalive.mu.Lock()
+	defer m.keepalive.mu.Unlock()
+	m.keepalive.policy = policy
+}
+
+// assessKeepalive updates the keepalive warning of a repeater after a poll and
+// sends an event when a new warning appears
+func (m *Manager) assessKeepalive(r *Repeater) {
+	m.keepalive.mu.RLock()
+	policy := m.keepalive.policy
+	m.keepalive.mu.RUnlock()
+
+	warning, changed := r.fingerprint.assessKeepalive(policy)
+	if !changed {
+		return
+	}
+	fp := r.Fingerprint()
+	if warning == "" {
+		if m.logger != nil {
+			m.logger.Info("Repeater keepalive back to normal",
+				logger.String("callsign", r.Callsign()),
+				logger.Any("poll_interval", fp.PollInterval))
+		}
+		return
+	}
+
+	if m.logger != nil {
+		m.logger.Warn("Repeater keepalive warning",
+			logger.String("callsign", r.Callsign()),
+			logger.String("warning", warning),
+			logger.Any("poll_interval", fp.PollInterval),
+			logger.Any("poll_jitter_ms", fp.PollJitter))
+	}
+	m.emit(Event{
+		Type:      EventKeepaliveWarning,
+		Callsign:  r.Callsign(),
+		Address:   r.Address().String(),
+		Timestamp: m.clock.Now(),
+		Data: map[string]interface{}{
+			"warning":        warning,
+			"poll_interval":  fp.PollInterval,
+			"poll_jitter_ms": fp.PollJitter,
+			"max_poll_gap":   fp.MaxPollGap,
+		},
+	})
+}
+
+// assessKeepalive applies policy to the measured cadence. It returns the
+// warning and whether it changed.
+func (f *fingerprintState) assessKeepalive(policy KeepalivePolicy) (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	warning := ""
+	if f.polls > keepaliveMinIntervals {
+		switch {
+		case policy.NATTimeout > 0 && f.avgInterval >= policy.NATTimeout:
+			warning = KeepaliveNATTimeout
+		case policy.ErraticRatio > 0 && float64(f.avgJitter) > policy.ErraticRatio*float64(f.avgInterval):
+			warning = KeepaliveErratic
+		}
+	}
+	if warning == f.warning {
+		return warning, false
+	}
+	f.warning = warning
+	return warning, true
+}
@@ -0,0 +1,73 @@
+package repeater
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/clock"
+)
+
+func TestKeepaliveWarnings(t *testing.T) {
+	events := make(chan Event, 20)
+	m := NewManager(5*time.Minute, 10, events, 180*time.Second, 0)
+	clk := clock.NewFake(time.Now())
+	m.SetClock(clk)
+	m.SetKeepalivePolicy(KeepalivePolicy{NATTimeout: 30 * time.Second, ErraticRatio: 0.5})
+	addr := mustAddr(t, "127.0.0.1:45030")
+	m.AddRepeater("W1AW", addr)
+
+	poll := func(gap time.Duration) {
+		clk.Advance(gap)
+		m.ObservePoll(addr, pollPacket("W1AW      "))
+	}
+	keepaliveEvents := func() []Event {
+		var found []Event
+		for {
+			select {
+			case e := <-events:
+				if e.Type == EventKeepaliveWarning {
+					found = append(found, e)
+				}
+			default:
+				return found
+			}
+		}
+	}
+
+	// A regular 5 second cadence raises nothing
+	for i := 0; i < 6; i++ {
+		poll(5 * time.Second)
+	}
+	if got := keepaliveEvents(); len(got) != 0 {
+		t.Fatalf("unexpected keepalive warnings %+v", got)
+	}
+
+	// Polls spread out beyond the NAT timeout; the change of cadence may look
+	// erratic first, but the interval settles on the NAT warning
+	for i := 0; i < 20; i++ {
+		poll(40 * time.Second)
+	}
+	got := keepaliveEvents()
+	if len(got) == 0 || got[len(got)-1].Data["warning"] != KeepaliveNATTimeout {
+		t.Fatalf("expected a nat_timeout warning, got %+v", got)
+	}
+	fp := m.GetRepeater(addr).Fingerprint()
+	if fp.KeepaliveWarning != KeepaliveNATTimeout || fp.MaxPollGap != 40 {
+		t.Errorf("unexpected fingerprint %+v", fp)
+	}
+}
+
+func TestKeepaliveErratic(t *testing.T) {
+	f := &fingerprintState{}
+	at := time.Now()
+	var warning string
+	// Polls alternate between 1 and 9 seconds apart
+	for i := 0; i < 30; i++ {
+		at = at.Add(time.Duration(1+8*(i%2)) * time.Second)
+		f.observePoll(pollPacket("W1AW      "), at)
+		warning, _ = f.assessKeepalive(KeepalivePolicy{NATTimeout: 30 * time.Second, ErraticRatio: 0.5})
+	}
+	if warning != KeepaliveErratic {
+		t.Errorf("expected an erratic warning, got %q", warning)
+	}
+}
@@ -0,0 +1,95 @@
+package repeater
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// KerchunkPolicy configures automatic muting of callsigns that repeatedly key up briefly
+type KerchunkPolicy struct {
+	Enabled bool
+	// MaxShortTransmissions is the number of short transmissions tolerated within Window
+	MaxShortTransmissions int
+	// ShortThreshold is the duration under which a transmission counts as a kerchunk
+	ShortThreshold time.Duration
+	// Window is the sliding window in which short transmissions are counted
+	Window time.Duration
+	// Cooldown is how long an offending callsign stays muted
+	Cooldown time.Duration
+	// Exempt lists callsigns that are never auto-muted
+	Exempt []string
+}
+
+// kerchunkTracker tracks short transmissions per callsign
+type kerchunkTracker struct {
+	mu      sync.Mutex
+	policy  KerchunkPolicy
+	exempt  map[string]bool
+	history map[string][]time.Time
+}
+
+func newKerchunkTracker(policy KerchunkPolicy) *kerchunkTracker {
+	exempt := make(map[string]bool, len(policy.Exempt))
+	for _, cs := range policy.Exempt {
+		if normalized := normalizeCallsign(cs); normalized != "" {
+			exempt[normalized] = true
+		}
+	}
+	return &kerchunkTracker{
+		policy:  policy,
+		exempt:  exempt,
+		history: make(map[string][]time.Time),
+	}
+}
+
+// observe records a finished transmission and reports whether the callsign
+// has now exceeded the allowed number of short transmissions.
+func (k *kerchunkTracker) observe(callsign string, duration time.Duration, now time.Time) bool {
+	if !k.policy.Enabled || duration >= k.policy.ShortThreshold {
+		return false
+	}
+
+	cs := normalizeCallsign(callsign)
+	if cs == "" || k.exempt[cs] {
+		return false
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	cutoff := now.Add(-k.policy.Window)
+	recent := k.history[cs][:0]
+	for _, t := range k.history[cs] {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+	recent = append(recent, now)
+
+	if len(recent) > k.policy.MaxShortTransmissions {
+		delete(k.history, cs)
+		return true
+	}
+
+	k.history[cs] = recent
+	return false
+}
+
+// prune drops history entries that fell out of the window
+func (k *kerchunkTracker) prune(now time.Time) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	cutoff := now.Add(-k.policy.Window)
+	for cs, times := range k.history {
+		if len(times) == 0 || !times[len(times)-1].After(cutoff) {
+			delete(k.history, cs)
+		}
+	}
+}
+
+// normalizeCallsign upper-cases and trims a callsign for map lookups
+func normalizeCallsign(callsign string) string {
+	return strings.ToUpper(strings.TrimSpace(callsign))
+}
@@ -0,0 +1,77 @@
+package repeater
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKerchunkAutoMute(t *testing.T) {
+	events := make(chan Event, 20)
+	m := NewManager(5*time.Minute, 10, events, time.Minute, 0)
+	m.SetKerchunkPolicy(KerchunkPolicy{
+		Enabled:               true,
+		MaxShortTransmissions: 2,
+		ShortThreshold:        time.Second,
+		Window:                time.Minute,
+		Cooldown:              time.Hour,
+		Exempt:                []string{"n0net"},
+	})
+
+	addr := mustAddr(t, "127.0.0.1:42101")
+	r, _ := m.AddRepeater("GW1", addr)
+
+	for i := 0; i < 3; i++ {
+		m.ProcessPacket("K1ABC", addr, "YSFD", 155)
+		if !r.IsTalking() {
+			t.Fatalf("transmission %d: expected repeater to be talking", i)
+		}
+		m.sendTalkEnd(r, addr.String(), r.StopTalking(), "")
+		m.ClearActive()
+	}
+
+	if !m.IsCallsignMuted("k1abc") {
+		t.Fatalf("expected K1ABC to be muted after repeated kerchunks")
+	}
+
+	// Muted callsign must not become the active talker
+	m.ProcessPacket("K1ABC", addr, "YSFD", 155)
+	if r.IsTalking() {
+		t.Errorf("expected muted callsign not to start talking")
+	}
+
+	found := false
+	for len(events) > 0 {
+		if ev := <-events; ev.Type == EventKerchunk && ev.Callsign == "K1ABC" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected %s event", EventKerchunk)
+	}
+}
+
+func TestKerchunkExemptAndLongTransmissions(t *testing.T) {
+	k := newKerchunkTracker(KerchunkPolicy{
+		Enabled:               true,
+		MaxShortTransmissions: 1,
+		ShortThreshold:        time.Second,
+		Window:                time.Minute,
+		Cooldown:              time.Minute,
+		Exempt:                []string{"N0NET"},
+	})
+	now := time.Now()
+
+	for i := 0; i < 5; i++ {
+		if k.observe("N0NET", 100*time.Millisecond, now) {
+			t.Fatalf("exempt callsign should never trip the policy")
+		}
+		if k.observe("W1AW", 10*time.Second, now) {
+			t.Fatalf("long transmissions should never trip the policy")
+		}
+	}
+
+	// Short transmissions spread beyond the window don't accumulate
+	if k.observe("K2XYZ", 0, now) || k.observe("K2XYZ", 0, now.Add(2*time.Minute)) {
+		t.Fatalf("short transmissions outside the window should not trip the policy")
+	}
+}
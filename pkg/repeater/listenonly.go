@@ -0,0 +1,122 @@
+package repeater
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// EventListenOnly is emitted when a transmission from a listen-only callsign is dropped
+const EventListenOnly = "listen_only_dropped"
+
+// listenOnlyList holds callsigns that may listen but not talk. Their YSFD
+// frames are counted and dropped before they can claim the channel.
+type listenOnlyList struct {
+	mu        sync.RWMutex
+	callsigns map[string]bool
+	// notify emits an EventListenOnly for each dropped transmission
+	notify bool
+	// lastDrop maps callsign -> last dropped frame, to emit one event per transmission
+	lastDrop map[string]time.Time
+}
+
+// SetListenOnly replaces the listen-only callsigns. With notify set, an
+// EventListenOnly is emitted once per dropped transmission.
+func (m *Manager) SetListenOnly(callsigns []string, notify bool) {
+	m.listenOnly.mu.Lock()
+	defer m.listenOnly.mu.Unlock()
+
+	m.listenOnly.callsigns = make(map[string]bool, len(callsigns))
+	for _, callsign := range callsigns {
+		if key := normalizeCallsign(callsign); key != "" {
+			m.listenOnly.callsigns[key] = true
+		}
+	}
+	m.listenOnly.notify = notify
+}
+
+// AddListenOnly makes a callsign listen-only
+func (m *Manager) AddListenOnly(callsign string) {
+	key := normalizeCallsign(callsign)
+	if key == "" {
+		return
+	}
+
+	m.listenOnly.mu.Lock()
+	defer m.listenOnly.mu.Unlock()
+	if m.listenOnly.callsigns == nil {
+		m.listenOnly.callsigns = make(map[string]bool)
+	}
+	m.listenOnly.callsigns[key] = true
+}
+
+// RemoveListenOnly lets a callsign talk again. It reports whether the callsign was listen-only.
+func (m *Manager) RemoveListenOnly(callsign string) bool {
+	key := normalizeCallsign(callsign)
+
+	m.listenOnly.mu.Lock()
+	defer m.listenOnly.mu.Unlock()
+	if !m.listenOnly.callsigns[key] {
+		return false
+	}
+	delete(m.listenOnly.callsigns, key)
+	delete(m.listenOnly.lastDrop, key)
+	return true
+}
+
+// IsListenOnly reports whether a callsign may only listen
+func (m *Manager) IsListenOnly(callsign string) bool {
+	m.listenOnly.mu.RLock()
+	defer m.listenOnly.mu.RUnlock()
+	return m.listenOnly.callsigns[normalizeCallsign(callsign)]
+}
+
+// GetListenOnly returns the listen-only callsigns in order
+func (m *Manager) GetListenOnly() []string {
+	m.listenOnly.mu.RLock()
+	defer m.listenOnly.mu.RUnlock()
+
+	callsigns := make([]string, 0, len(m.listenOnly.callsigns))
+	for callsign := range m.listenOnly.callsigns {
+		callsigns = append(callsigns, callsign)
+	}
+	sort.Strings(callsigns)
+	return callsigns
+}
+
+// dropListenOnly counts a dropped frame from a listen-only callsign and, when
+// notifications are enabled, emits an event for the first frame of a transmission
+func (m *Manager) dropListenOnly(callsign string, repeater *Repeater, now time.Time) {
+	m.mu.Lock()
+	m.metrics.ListenOnlyDrops++
+	m.mu.Unlock()
+
+	key := normalizeCallsign(callsign)
+	m.listenOnly.mu.Lock()
+	if m.listenOnly.lastDrop == nil {
+		m.listenOnly.lastDrop = make(map[string]time.Time)
+	}
+	last, seen := m.listenOnly.lastDrop[key]
+	m.listenOnly.lastDrop[key] = now
+	notify := m.listenOnly.notify && (!seen || now.Sub(last) > talkIdleTimeout)
+	m.listenOnly.mu.Unlock()
+
+	if !notify {
+		return
+	}
+
+	if m.logger != nil {
+		m.logger.Info("Dropping transmission from listen-only callsign",
+			logger.String("callsign", callsign),
+			logger.String("gateway", repeater.Callsign()))
+	}
+	m.emit(Event{
+		Type:      EventListenOnly,
+		Callsign:  callsign,
+		Address:   repeater.Address().String(),
+		Gateway:   repeater.Callsign(),
+		Timestamp: now,
+	})
+}
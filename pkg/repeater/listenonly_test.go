@@ -0,0 +1,41 @@
+package repeater
+
+import (
+	"testing"
+	"time"
+)
+
+func TestListenOnlyDropsTransmissions(t *testing.T) {
+	events := make(chan Event, 20)
+	m := NewManager(5*time.Second, 10, events, 180*time.Second, 0)
+	m.SetListenOnly([]string{"w1aw"}, true)
+	addr := mustAddr(t, "127.0.0.1:45040")
+	m.AddRepeater("GATEWAY", addr)
+	<-events // connect
+
+	for i := 0; i < 3; i++ {
+		m.ProcessPacket("W1AW", addr, "YSFD", 155)
+	}
+
+	if m.GetRepeater(addr).IsTalking() || m.HoldsChannel(addr) {
+		t.Fatalf("listen-only callsign must not become the active talker")
+	}
+	if got := m.GetStats().ListenOnlyDrops; got != 3 {
+		t.Errorf("expected 3 dropped frames, got %d", got)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected one event per transmission, got %d", len(events))
+	}
+	if ev := <-events; ev.Type != EventListenOnly || ev.Callsign != "W1AW" || ev.Gateway != "GATEWAY" {
+		t.Errorf("unexpected event %+v", ev)
+	}
+
+	// Once removed, the callsign can talk again
+	if !m.RemoveListenOnly("W1AW") {
+		t.Fatalf("expected W1AW to be removed")
+	}
+	m.ProcessPacket("W1AW", addr, "YSFD", 155)
+	if !m.GetRepeater(addr).IsTalking() {
+		t.Errorf("expected W1AW to talk after removal")
+	}
+}
@@ -3,9 +3,11 @@ package repeater
 import (
 	"context"
 	"net"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/dbehnke/ysf-nexus/pkg/clock"
 	"github.com/dbehnke/ysf-nexus/pkg/logger"
 )
 
@@ -13,9 +15,15 @@ import (
 type Manager struct {
 	repeaters sync.Map
 	timeout   time.Duration
+	// clock is the time source for manager-level state (see SetClock)
+	clock clock.Clock
 	// activeKey holds the address string of the currently active (allowed) repeater
 	activeKey string
 	activeMu  sync.Mutex
+	// activeBridge is the bridge stream holding the channel, if any (guarded by activeMu)
+	activeBridge *bridgeStream
+	// bridgeOverlap lets bridge streams play over each other (guarded by activeMu)
+	bridgeOverlap bool
 	// muted repeaters map address -> unmute until time (zero means muted until they stop)
 	muted sync.Map // map[string]time.Time
 	// maximum allowed continuous talk duration before muting
@@ -28,6 +36,36 @@ type Manager struct {
 	mu           sync.RWMutex
 	metrics      ManagerMetrics
 	logger       *logger.Logger
+	// kerchunk tracks short transmissions for the anti-kerchunk policy
+	kerchunk *kerchunkTracker
+	// mutedCallsigns maps callsign -> unmute time for policy-based (callsign) mutes
+	mutedCallsigns sync.Map
+	// maxPerIP caps repeater entries from a single IP (0 = unlimited)
+	maxPerIP int
+	// followPorts matches repeaters by callsign and IP (see portchange.go)
+	followPorts bool
+	// ipLimitNotified maps IP -> last ip_limit event time, to avoid flooding events
+	ipLimitNotified sync.Map
+	// doublings tracks transmissions suppressed by the single active stream rule
+	doublings *doublingTracker
+	// contention counts overlapping stream attempts and waits for the channel
+	contention contentionTracker
+	// watchdog holds the stream watchdog state between audits
+	watchdog streamWatchdog
+	// listenOnly holds callsigns whose transmissions are dropped
+	listenOnly listenOnlyList
+	// hold is the net control hold for priority traffic
+	hold holdState
+	// talkLimits holds per-callsign talk_max_duration and unmute_after overrides
+	talkLimits talkLimitList
+	// geo holds the geo lookup and country policy for new connections
+	geo geoState
+	// groups holds the named repeater groups
+	groups groupList
+	// keepalive holds the thresholds for poll cadence warnings
+	keepalive keepaliveState
+	// roster caches the connected repeaters for per-frame readers
+	roster roster
 }
 
 // ManagerMetrics holds manager statistics
@@ -36,18 +74,38 @@ type ManagerMetrics struct {
 	ActiveConnections  uint64
 	BlockedConnections uint64
 	TimeoutConnections uint64
+	IPLimitRejections  uint64
+	GeoRejections      uint64
 	TotalPackets       uint64
 	TotalBytesRx       uint64
 	TotalBytesTx       uint64
+	StreamAnomalies    uint64
+	ListenOnlyDrops    uint64
+	PortChanges        uint64
+	// Disconnects counts removed repeaters by disconnect reason
+	Disconnects map[string]uint64
 }
 
 // Event represents a repeater event
 type Event struct {
-	Type      string        `json:"type"`
-	Callsign  string        `json:"callsign"`
-	Address   string        `json:"address"`
-	Timestamp time.Time     `json:"timestamp"`
-	Duration  time.Duration `json:"duration,omitempty"`
+	Type      string         `json:"type"`
+	Callsign  string         `json:"callsign"`
+	Address   string         `json:"address"`
+	Timestamp time.Time      `json:"timestamp"`
+	Duration  time.Duration  `json:"duration,omitempty"`
+	Quality   *QualityReport `json:"quality,omitempty"`
+	// Origin is the source type, DG-ID, talkgroup and reach of a transmission
+	// (talk_end only)
+	Origin *TalkOrigin `json:"origin,omitempty"`
+	// Gateway is the repeater/gateway the transmission came through, and Bridge
+	// the bridge it arrived on (bridge traffic only)
+	Gateway string `json:"gateway,omitempty"`
+	Bridge  string `json:"bridge,omitempty"`
+	// Reason says why a repeater disconnected (see disconnect.go); talk_end
+	// carries it too when the disconnect cut the transmission off
+	Reason string `json:"reason,omitempty"`
+	// Data carries event-specific details for event types without dedicated fields
+	Data map[string]interface{} `json:"data,omitempty"`
 }
 
 // Event types
@@ -58,6 +116,46 @@ const (
 	EventTalkEnd    = "talk_end"
 	EventTimeout    = "timeout"
 	EventBlocked    = "blocked"
+	EventKerchunk   = "kerchunk_muted"
+	EventIPLimit    = "ip_limit"
+	EventDoubling   = "doubling"
+	// EventMaintenance reports the outcome of a maintenance run
+	EventMaintenance = "maintenance_report"
+	// EventAlertFiring and EventAlertResolved report alert rule transitions
+	EventAlertFiring   = "alert_firing"
+	EventAlertResolved = "alert_resolved"
+	// EventBridgeAddressChanged reports that a bridge host resolved to a new address
+	EventBridgeAddressChanged = "bridge_address_changed"
+	// EventBridgeConnected and EventBridgeDisconnected report bridge link
+	// state changes; while a bridge flaps they are replaced by a single
+	// EventBridgeFlapping, and EventBridgeStable reports it has settled
+	EventBridgeConnected    = "bridge_connected"
+	EventBridgeDisconnected = "bridge_disconnected"
+	EventBridgeFlapping     = "bridge_flapping"
+	EventBridgeStable       = "bridge_stable"
+	// EventStreamAnomaly reports inconsistent stream state repaired by the watchdog
+	EventStreamAnomaly = "stream_anomaly"
+	// EventGeoBlocked reports a connection rejected by the country policy, and
+	// EventNewCountry the first connection from a country since startup
+	EventGeoBlocked = "geo_blocked"
+	EventNewCountry = "new_country"
+	// EventPopulationChanged reports the connected repeaters after a debounced
+	// batch of connects and disconnects
+	EventPopulationChanged = "population_changed"
+	// EventKeepaliveWarning reports a repeater whose poll cadence is likely
+	// to lose its NAT mapping
+	EventKeepaliveWarning = "keepalive_warning"
+	// EventWelcome reports the welcome message sent to a newly connected repeater
+	EventWelcome = "repeater_welcome"
+	// EventListenerRestarted reports a UDP socket recreated after it failed
+	EventListenerRestarted = "listener_restarted"
+	// EventClockDrift reports a system clock that has drifted from NTP time
+	EventClockDrift = "clock_drift"
+	// EventPasswordRejected reports a repeater turned away for a missing or
+	// wrong room password
+	EventPasswordRejected = "password_rejected"
+	// EventPortChanged reports a repeater session moved to a new source port
+	EventPortChanged = "repeater_port_changed"
 )
 
 // NewManager creates a new repeater manager
@@ -76,15 +174,40 @@ func NewManagerWithLogger(timeout time.Duration, maxRepeaters int, eventChan cha
 		talkMaxDuration: talkMaxDuration,
 		unmuteAfter:     unmuteAfter,
 		logger:          log.WithComponent("manager"),
+		doublings:       newDoublingTracker(),
+		clock:           clock.Real{},
 	}
 }
 
+// SetClock replaces the time source for mutes, doublings, stream arbitration
+// and event timestamps, so tests can drive them with a fake clock. It must be
+// called before the manager is used.
+func (m *Manager) SetClock(clk clock.Clock) {
+	m.clock = clk
+}
+
+// SetKerchunkPolicy configures the anti-kerchunk policy
+func (m *Manager) SetKerchunkPolicy(policy KerchunkPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.kerchunk = newKerchunkTracker(policy)
+}
+
+// SetMaxConnectionsPerIP caps the number of repeater entries a single IP may hold (0 = unlimited)
+func (m *Manager) SetMaxConnectionsPerIP(limit int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maxPerIP = limit
+}
+
 // AddRepeater adds or updates a repeater
 func (m *Manager) AddRepeater(callsign string, addr *net.UDPAddr) (*Repeater, bool) {
 	// Check blocklist
 	if m.blocklist.IsBlocked(callsign) {
 		m.metrics.BlockedConnections++
 		m.sendEvent(EventBlocked, callsign, addr.String(), 0)
+		// A repeater blocked since it connected is dropped on its next poll
+		m.RemoveRepeater(addr, DisconnectBlocked)
 		return nil, false
 	}
 
@@ -95,6 +218,9 @@ func (m *Manager) AddRepeater(callsign string, addr *net.UDPAddr) (*Repeater, bo
 		repeater.UpdateLastSeen()
 		return repeater, false // Existing repeater
 	}
+	if repeater := m.followPortChange(callsign, addr); repeater != nil {
+		return repeater, false // Existing repeater on a new port
+	}
 
 	// Check max connections
 	count := m.Count()
@@ -106,9 +232,26 @@ func (m *Manager) AddRepeater(callsign string, addr *net.UDPAddr) (*Repeater, bo
 		return nil, false
 	}
 
+	// Check per-IP connections
+	m.mu.RLock()
+	maxPerIP := m.maxPerIP
+	m.mu.RUnlock()
+	if maxPerIP > 0 && m.countByIP(addr.IP) >= maxPerIP {
+		m.rejectIPLimit(callsign, addr, maxPerIP)
+		return nil, false
+	}
+
+	// Check country policy
+	geo, allowed := m.checkGeo(callsign, addr)
+	if !allowed {
+		return nil, false
+	}
+
 	// Create new repeater
 	repeater := NewRepeater(callsign, addr)
+	repeater.SetGeo(geo)
 	m.repeaters.Store(key, repeater)
+	m.invalidateRoster()
 
 	m.mu.Lock()
 	m.metrics.TotalConnections++
@@ -119,10 +262,87 @@ func (m *Manager) AddRepeater(callsign string, addr *net.UDPAddr) (*Repeater, bo
 	if m.logger != nil {
 		m.logger.Info("New repeater connected", logger.String("callsign", callsign), logger.String("from", addr.String()))
 	}
+	m.observeCountry(callsign, addr, geo)
 
 	return repeater, true // New repeater
 }
 
+// countByIP returns how many repeater entries share the given IP
+func (m *Manager) countByIP(ip net.IP) int {
+	count := 0
+	m.repeaters.Range(func(key, value interface{}) bool {
+		if value.(*Repeater).Address().IP.Equal(ip) {
+			count++
+		}
+		return true
+	})
+	return count
+}
+
+// rejectIPLimit records a connection rejected by the per-IP cap.
+// The ip_limit event is sent at most once per minute per IP.
+func (m *Manager) rejectIPLimit(callsign string, addr *net.UDPAddr, limit int) {
+	m.mu.Lock()
+	m.metrics.IPLimitRejections++
+	m.mu.Unlock()
+
+	ip := addr.IP.String()
+	now := m.clock.Now()
+	if last, ok := m.ipLimitNotified.Load(ip); ok && now.Sub(last.(time.Time)) < time.Minute {
+		return
+	}
+	m.ipLimitNotified.Store(ip, now)
+
+	if m.logger != nil {
+		m.logger.Warn("Per-IP connection limit reached, rejecting",
+			logger.Int("limit", limit),
+			logger.String("callsign", callsign),
+			logger.String("from", addr.String()))
+	}
+	m.sendEvent(EventIPLimit, callsign, addr.String(), 0)
+}
+
+// IPSummary aggregates repeater entries that share an IP
+type IPSummary struct {
+	IP        string   `json:"ip"`
+	Count     int      `json:"count"`
+	Callsigns []string `json:"callsigns"`
+
+	// rawIP is the unmasked IP, for Masked
+	rawIP string
+}
+
+// GetIPSummary groups connected repeaters by IP, most entries first.
+// IPs are masked the same way as repeater addresses.
+func (m *Manager) GetIPSummary() []IPSummary {
+	byIP := make(map[string]*IPSummary)
+	m.repeaters.Range(func(key, value interface{}) bool {
+		repeater := value.(*Repeater)
+		ip := repeater.Address().IP.String()
+		summary, ok := byIP[ip]
+		if !ok {
+			summary = &IPSummary{IP: maskIPAddress(ip), rawIP: ip}
+			byIP[ip] = summary
+		}
+		summary.Count++
+		summary.Callsigns = append(summary.Callsigns, repeater.Callsign())
+		return true
+	})
+
+	summaries := make([]IPSummary, 0, len(byIP))
+	for _, summary := range byIP {
+		sort.Strings(summary.Callsigns)
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Count != summaries[j].Count {
+			return summaries[i].Count > summaries[j].Count
+		}
+		return summaries[i].rawIP < summaries[j].rawIP
+	})
+	return summaries
+}
+
 // GetRepeater retrieves a repeater by address
 func (m *Manager) GetRepeater(addr *net.UDPAddr) *Repeater {
 	if repeater, ok := m.repeaters.Load(addr.String()); ok {
@@ -131,11 +351,12 @@ func (m *Manager) GetRepeater(addr *net.UDPAddr) *Repeater {
 	return nil
 }
 
-// RemoveRepeater removes a repeater
-func (m *Manager) RemoveRepeater(addr *net.UDPAddr) bool {
+// RemoveRepeater removes a repeater, reporting why it disconnected
+func (m *Manager) RemoveRepeater(addr *net.UDPAddr, reason string) bool {
 	key := addr.String()
 	if repeater, ok := m.repeaters.LoadAndDelete(key); ok {
 		r := repeater.(*Repeater)
+		m.invalidateRoster()
 
 		// Stop talking if active
 		if r.IsTalking() {
@@ -148,16 +369,31 @@ func (m *Manager) RemoveRepeater(addr *net.UDPAddr) bool {
 			m.activeMu.Unlock()
 			// Ensure unmuted
 			m.muted.Delete(addr.String())
-			m.sendEvent(EventTalkEnd, r.Callsign(), addr.String(), duration)
+			m.sendTalkEnd(r, addr.String(), duration, reason)
 		}
 
 		m.mu.Lock()
 		m.metrics.ActiveConnections--
+		if m.metrics.Disconnects == nil {
+			m.metrics.Disconnects = make(map[string]uint64)
+		}
+		m.metrics.Disconnects[reason]++
 		m.mu.Unlock()
 
-		m.sendEvent(EventDisconnect, r.Callsign(), addr.String(), 0)
+		m.emit(Event{
+			Type:      EventDisconnect,
+			Callsign:  r.Callsign(),
+			Address:   addr.String(),
+			Timestamp: m.clock.Now(),
+			Duration:  r.Uptime(),
+			Reason:    reason,
+		})
 		if m.logger != nil {
-			m.logger.Info("Repeater disconnected", logger.String("callsign", r.Callsign()), logger.String("from", addr.String()), logger.String("uptime", r.Uptime().String()))
+			m.logger.Info("Repeater disconnected",
+				logger.String("callsign", r.Callsign()),
+				logger.String("from", addr.String()),
+				logger.String("reason", reason),
+				logger.String("uptime", r.Uptime().String()))
 		}
 
 		return true
@@ -167,36 +403,22 @@ func (m *Manager) RemoveRepeater(addr *net.UDPAddr) bool {
 
 // GetAllRepeaters returns all active repeaters
 func (m *Manager) GetAllRepeaters() []*Repeater {
-	var repeaters []*Repeater
-	m.repeaters.Range(func(key, value interface{}) bool {
-		if repeater, ok := value.(*Repeater); ok {
-			repeaters = append(repeaters, repeater)
-		}
-		return true
-	})
-	return repeaters
+	snap := m.currentRoster()
+	if len(snap.repeaters) == 0 {
+		return nil
+	}
+	return append([]*Repeater(nil), snap.repeaters...)
 }
 
-// GetAllAddresses returns all repeater addresses
+// GetAllAddresses returns all repeater addresses. The slice is shared with
+// other callers and must not be modified.
 func (m *Manager) GetAllAddresses() []*net.UDPAddr {
-	var addresses []*net.UDPAddr
-	m.repeaters.Range(func(key, value interface{}) bool {
-		if repeater, ok := value.(*Repeater); ok {
-			addresses = append(addresses, repeater.Address())
-		}
-		return true
-	})
-	return addresses
+	return m.currentRoster().addresses
 }
 
 // Count returns the number of active repeaters
 func (m *Manager) Count() int {
-	count := 0
-	m.repeaters.Range(func(key, value interface{}) bool {
-		count++
-		return true
-	})
-	return count
+	return len(m.currentRoster().repeaters)
 }
 
 // ProcessPacket processes a packet and updates repeater state
@@ -209,6 +431,7 @@ func (m *Manager) ProcessPacket(callsign string, addr *net.UDPAddr, packetType s
 	repeater.UpdateLastSeen()
 	repeater.IncrementPacketCount()
 	repeater.AddBytesReceived(uint64(dataSize))
+	repeater.probe.observe(m.clock.Now())
 
 	m.mu.Lock()
 	m.metrics.TotalPackets++
@@ -217,14 +440,32 @@ func (m *Manager) ProcessPacket(callsign string, addr *net.UDPAddr, packetType s
 
 	// Handle talk state changes for data packets
 	if packetType == "YSFD" {
+		// Callsigns muted by policy never become the active talker
+		if m.IsCallsignMuted(callsign) {
+			return
+		}
+
+		// Listen-only callsigns are counted and dropped
+		if m.IsListenOnly(callsign) {
+			m.dropListenOnly(callsign, repeater, m.clock.Now())
+			return
+		}
+
+		// During a net control hold only cleared callsigns may talk
+		if m.IsHeld(callsign) {
+			m.dropHeld(callsign, repeater, m.clock.Now())
+			return
+		}
+
 		// If this repeater is muted, check if mute expired
 		if v, muted := m.muted.Load(addr.String()); muted {
 			if until, ok := v.(time.Time); ok {
-				if !until.IsZero() && time.Now().After(until) {
+				if !until.IsZero() && m.clock.Now().After(until) {
 					// unmute automatically
 					m.muted.Delete(addr.String())
 				} else {
 					// still muted
+					repeater.markMutedFrame(m.clock.Now())
 					return
 				}
 			} else {
@@ -239,15 +480,28 @@ func (m *Manager) ProcessPacket(callsign string, addr *net.UDPAddr, packetType s
 		if currentActive == "" {
 			// no active repeater yet
 			if !repeater.IsTalking() {
+				// A stream that was suppressed until now has ended its doubling
+				if double, ok := m.doublings.finish(addr.String()); ok {
+					m.sendDoubling(double, addr.String())
+				}
+				m.recordClaim(addr.String(), m.clock.Now())
 				repeater.StartTalking()
+				repeater.setTalker(callsign)
 				m.activeKey = addr.String()
-				m.sendEvent(EventTalkStart, callsign, addr.String(), 0)
+				m.emit(Event{
+					Type:      EventTalkStart,
+					Callsign:  callsign,
+					Address:   addr.String(),
+					Gateway:   repeater.Callsign(),
+					Timestamp: m.clock.Now(),
+				})
 				if m.logger != nil {
 					m.logger.Info("Repeater started talking", logger.String("callsign", callsign))
 				}
 			} else {
-				// already talking
+				// already talking; reclaim the free channel
 				repeater.UpdateTalkData()
+				m.activeKey = addr.String()
 			}
 			m.activeMu.Unlock()
 		} else if currentActive == addr.String() {
@@ -255,13 +509,14 @@ func (m *Manager) ProcessPacket(callsign string, addr *net.UDPAddr, packetType s
 			m.activeMu.Unlock()
 			repeater.UpdateTalkData()
 			// If they've been talking too long, mute them
-			if repeater.TalkDuration() > m.talkMaxDuration {
+			maxDuration, unmuteAfter := m.talkLimitsFor(callsign, repeater.Callsign())
+			if repeater.TalkDuration() > maxDuration {
 				// mute and stop talking
 				repeater.StopTalking()
 				// compute unmute time (zero means muted until they stop)
 				var unmuteUntil time.Time
-				if m.unmuteAfter > 0 {
-					unmuteUntil = time.Now().Add(m.unmuteAfter)
+				if unmuteAfter > 0 {
+					unmuteUntil = m.clock.Now().Add(unmuteAfter)
 				}
 				m.muted.Store(addr.String(), unmuteUntil)
 				m.activeMu.Lock()
@@ -273,14 +528,48 @@ func (m *Manager) ProcessPacket(callsign string, addr *net.UDPAddr, packetType s
 				}
 			}
 		} else {
-			// Another repeater is currently active; ignore this talk start
+			// Another repeater or a bridge is currently active; ignore this talk start
 			m.activeMu.Unlock()
-			// Optionally update last talk data timestamp to show activity but do not start talking
+			double := Doubling{Callsign: callsign, Gateway: repeater.Callsign()}
+			m.describeActive(&double, currentActive)
+			now := m.clock.Now()
+			if started, open := m.doublings.observe(addr.String(), double, now); started {
+				m.recordRejection(addr.String(), now, open+1)
+			}
 			return
 		}
 	}
 }
 
+// ObservePoll records poll packet details used to fingerprint the client software
+func (m *Manager) ObservePoll(addr *net.UDPAddr, data []byte) {
+	if repeater := m.GetRepeater(addr); repeater != nil {
+		repeater.ObservePoll(data, m.clock.Now())
+		m.assessKeepalive(repeater)
+	}
+}
+
+// ObserveStatusRequest records a status request from a connected repeater's address
+func (m *Manager) ObserveStatusRequest(addr *net.UDPAddr) {
+	if repeater := m.GetRepeater(addr); repeater != nil {
+		repeater.ObserveStatusRequest()
+	}
+}
+
+// RecordFrame records quality information for a data frame from the active talker
+func (m *Manager) RecordFrame(addr *net.UDPAddr, seq uint8, hasSeq bool, fichOK bool, at time.Time) {
+	if repeater := m.GetRepeater(addr); repeater != nil {
+		repeater.RecordFrame(seq, hasSeq, fichOK, at)
+	}
+}
+
+// RecordOrigin records a forwarded frame in the repeater's origin tracker
+func (m *Manager) RecordOrigin(addr *net.UDPAddr, dgid uint8, hasDGID bool, dest string, recipients int) {
+	if repeater := m.GetRepeater(addr); repeater != nil {
+		repeater.RecordOrigin(dgid, hasDGID, dest, recipients)
+	}
+}
+
 // ProcessTransmit updates transmit statistics
 func (m *Manager) ProcessTransmit(addr *net.UDPAddr, dataSize int) {
 	repeater := m.GetRepeater(addr)
@@ -299,8 +588,8 @@ func (m *Manager) StartCleanup(ctx context.Context) {
 	cleanupTicker := time.NewTicker(30 * time.Second)
 	defer cleanupTicker.Stop()
 
-	// Check for talk timeouts every 2 seconds for responsiveness
-	talkTicker := time.NewTicker(2 * time.Second)
+	// Check for talk timeouts and audit stream state every second
+	talkTicker := time.NewTicker(time.Second)
 	defer talkTicker.Stop()
 
 	for {
@@ -309,8 +598,11 @@ func (m *Manager) StartCleanup(ctx context.Context) {
 			return
 		case <-cleanupTicker.C:
 			m.cleanupTimedOut()
-		case <-talkTicker.C:
+		case now := <-talkTicker.C:
 			m.checkTalkTimeouts()
+			m.auditStreams(now)
+			m.expireHold()
+			m.sampleContention(m.clock.Now())
 		}
 	}
 }
@@ -329,38 +621,51 @@ func (m *Manager) cleanupTimedOut() {
 
 	for _, addr := range toRemove {
 		if repeater := m.GetRepeater(addr); repeater != nil {
-			// Handle ongoing talk
-			if repeater.IsTalking() {
-				duration := repeater.StopTalking()
-				// If this was the active repeater, clear activeKey
-				m.activeMu.Lock()
-				if m.activeKey == addr.String() {
-					m.activeKey = ""
-				}
-				m.activeMu.Unlock()
-				// Unmute if previously muted
-				m.muted.Delete(addr.String())
-				m.sendEvent(EventTalkEnd, repeater.Callsign(), addr.String(), duration)
-			}
-
 			m.mu.Lock()
 			m.metrics.TimeoutConnections++
 			m.mu.Unlock()
 
 			m.sendEvent(EventTimeout, repeater.Callsign(), addr.String(), 0)
 		}
-		m.RemoveRepeater(addr)
+		// Ends any ongoing talk as well
+		m.RemoveRepeater(addr, DisconnectTimeout)
 	}
 
 	if len(toRemove) > 0 && m.logger != nil {
 		m.logger.Info("Cleaned up timed-out repeaters", logger.Int("count", len(toRemove)))
 	}
+
+	// Expire policy mutes, stale kerchunk history and per-IP limit notices
+	now := m.clock.Now()
+	m.ipLimitNotified.Range(func(key, value interface{}) bool {
+		if now.Sub(value.(time.Time)) >= time.Minute {
+			m.ipLimitNotified.Delete(key)
+		}
+		return true
+	})
+	m.mutedCallsigns.Range(func(key, value interface{}) bool {
+		if until, ok := value.(time.Time); !ok || !now.Before(until) {
+			m.mutedCallsigns.Delete(key)
+		}
+		return true
+	})
+	m.mu.RLock()
+	tracker := m.kerchunk
+	m.mu.RUnlock()
+	if tracker != nil {
+		tracker.prune(now)
+	}
 }
 
 // checkTalkTimeouts checks for and handles talk session timeouts
 func (m *Manager) checkTalkTimeouts() {
 	// Talk timeout duration (3 seconds without data packets)
-	talkTimeout := 3 * time.Second
+	talkTimeout := talkIdleTimeout
+
+	for _, double := range m.doublings.expire(m.clock.Now(), talkTimeout) {
+		m.sendDoubling(double, "")
+	}
+	m.releaseIdleBridgeStream(m.clock.Now(), talkTimeout)
 
 	m.repeaters.Range(func(key, value interface{}) bool {
 		repeater := value.(*Repeater)
@@ -379,7 +684,7 @@ func (m *Manager) checkTalkTimeouts() {
 					if until.IsZero() {
 						// muted until stop -> clear
 						m.muted.Delete(addrStr)
-					} else if time.Now().After(until) {
+					} else if m.clock.Now().After(until) {
 						// unmute expired
 						m.muted.Delete(addrStr)
 					}
@@ -388,7 +693,7 @@ func (m *Manager) checkTalkTimeouts() {
 					m.muted.Delete(addrStr)
 				}
 			}
-			m.sendEvent(EventTalkEnd, repeater.Callsign(), addrStr, duration)
+			m.sendTalkEnd(repeater, addrStr, duration, "")
 			if m.logger != nil {
 				m.logger.Info("Repeater stopped talking (timeout)", logger.String("callsign", repeater.Callsign()), logger.Duration("duration", duration))
 			}
@@ -402,22 +707,33 @@ func (m *Manager) GetStats() ManagerStats {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	doublings := m.doublings.count()
+	disconnects := make(map[string]uint64, len(m.metrics.Disconnects))
+	for reason, count := range m.metrics.Disconnects {
+		disconnects[reason] = count
+	}
+
 	var repeaterStats []RepeaterStats
-	m.repeaters.Range(func(key, value interface{}) bool {
-		if repeater, ok := value.(*Repeater); ok {
-			repeaterStats = append(repeaterStats, repeater.Stats())
-		}
-		return true
-	})
+	for _, repeater := range m.currentRoster().repeaters {
+		repeaterStats = append(repeaterStats, repeater.Stats())
+	}
 
 	return ManagerStats{
 		ActiveRepeaters:       len(repeaterStats),
 		TotalConnections:      m.metrics.TotalConnections,
 		BlockedConnections:    m.metrics.BlockedConnections,
 		TimeoutConnections:    m.metrics.TimeoutConnections,
+		IPLimitRejections:     m.metrics.IPLimitRejections,
+		GeoRejections:         m.metrics.GeoRejections,
+		Doublings:             doublings,
 		TotalPackets:          m.metrics.TotalPackets,
 		TotalBytesReceived:    m.metrics.TotalBytesRx,
 		TotalBytesTransmitted: m.metrics.TotalBytesTx,
+		StreamAnomalies:       m.metrics.StreamAnomalies,
+		ListenOnlyDrops:       m.metrics.ListenOnlyDrops,
+		PortChanges:           m.metrics.PortChanges,
+		Disconnects:           disconnects,
+		Contention:            m.GetContention(),
 		Repeaters:             repeaterStats,
 	}
 }
@@ -428,10 +744,20 @@ type ManagerStats struct {
 	TotalConnections      uint64          `json:"total_connections"`
 	BlockedConnections    uint64          `json:"blocked_connections"`
 	TimeoutConnections    uint64          `json:"timeout_connections"`
+	IPLimitRejections     uint64          `json:"ip_limit_rejections"`
+	GeoRejections         uint64          `json:"geo_rejections"`
+	Doublings             uint64          `json:"doublings"`
 	TotalPackets          uint64          `json:"total_packets"`
 	TotalBytesReceived    uint64          `json:"total_bytes_received"`
 	TotalBytesTransmitted uint64          `json:"total_bytes_transmitted"`
+	StreamAnomalies       uint64          `json:"stream_anomalies"`
+	ListenOnlyDrops       uint64          `json:"listen_only_drops"`
+	PortChanges           uint64          `json:"port_changes"`
+	Contention            ContentionStats `json:"contention"`
 	Repeaters             []RepeaterStats `json:"repeaters"`
+
+	// Disconnects counts removed repeaters by disconnect reason
+	Disconnects map[string]uint64 `json:"disconnects"`
 }
 
 // GetBlocklist returns the blocklist
@@ -439,6 +765,86 @@ func (m *Manager) GetBlocklist() *Blocklist {
 	return m.blocklist
 }
 
+// checkKerchunk applies the anti-kerchunk policy to a finished transmission
+func (m *Manager) checkKerchunk(callsign, address string, duration time.Duration) {
+	m.mu.RLock()
+	tracker := m.kerchunk
+	m.mu.RUnlock()
+
+	if tracker == nil {
+		return
+	}
+
+	now := m.clock.Now()
+	if !tracker.observe(callsign, duration, now) {
+		return
+	}
+
+	cooldown := tracker.policy.Cooldown
+	m.mutedCallsigns.Store(normalizeCallsign(callsign), now.Add(cooldown))
+	m.sendEvent(EventKerchunk, callsign, address, cooldown)
+	if m.logger != nil {
+		m.logger.Warn("Callsign auto-muted for kerchunking",
+			logger.String("callsign", callsign),
+			logger.Duration("cooldown", cooldown))
+	}
+}
+
+// sendDoubling reports a finished doubling
+func (m *Manager) sendDoubling(double Doubling, address string) {
+	m.emit(Event{
+		Type:      EventDoubling,
+		Callsign:  double.Callsign,
+		Address:   address,
+		Timestamp: double.Start,
+		Duration:  double.Duration,
+		Gateway:   double.Gateway,
+		Bridge:    double.Bridge,
+		Data: map[string]interface{}{
+			"active_callsign": double.ActiveCallsign,
+			"active_gateway":  double.ActiveGateway,
+			"active_bridge":   double.ActiveBridge,
+		},
+	})
+	if m.logger != nil {
+		m.logger.Info("Doubling detected",
+			logger.String("callsign", double.Callsign),
+			logger.String("active_callsign", double.ActiveCallsign),
+			logger.Duration("suppressed", double.Duration))
+	}
+}
+
+// GetDoublings returns recent doublings (newest first, at most limit; 0 = all),
+// the number of doublings per suppressed callsign and the total count
+func (m *Manager) GetDoublings(limit int) ([]Doubling, map[string]uint64, uint64) {
+	return m.doublings.snapshot(limit)
+}
+
+// IsCallsignMuted reports whether a callsign is muted by policy (e.g. anti-kerchunk)
+func (m *Manager) IsCallsignMuted(callsign string) bool {
+	key := normalizeCallsign(callsign)
+	if v, ok := m.mutedCallsigns.Load(key); ok {
+		if until, ok2 := v.(time.Time); ok2 && m.clock.Now().Before(until) {
+			return true
+		}
+		m.mutedCallsigns.Delete(key)
+	}
+	return false
+}
+
+// GetMutedCallsigns returns policy-muted callsigns and when they will be unmuted
+func (m *Manager) GetMutedCallsigns() map[string]time.Time {
+	muted := make(map[string]time.Time)
+	now := m.clock.Now()
+	m.mutedCallsigns.Range(func(key, value interface{}) bool {
+		if until, ok := value.(time.Time); ok && now.Before(until) {
+			muted[key.(string)] = until
+		}
+		return true
+	})
+	return muted
+}
+
 // IsMuted reports whether the repeater at the given address is currently muted.
 // Exported so tests and callers can check mute state without accessing internal fields.
 func (m *Manager) IsMuted(addr *net.UDPAddr) bool {
@@ -447,7 +853,7 @@ func (m *Manager) IsMuted(addr *net.UDPAddr) bool {
 			if until.IsZero() {
 				return true
 			}
-			return time.Now().Before(until)
+			return m.clock.Now().Before(until)
 		}
 		// unknown type stored, treat as muted
 		return true
@@ -479,12 +885,37 @@ func (m *Manager) sendEvent(eventType, callsign, address string, duration time.D
 		return
 	}
 
-	event := Event{
+	m.emit(Event{
 		Type:      eventType,
 		Callsign:  callsign,
 		Address:   address,
-		Timestamp: time.Now(),
+		Timestamp: m.clock.Now(),
 		Duration:  duration,
+	})
+}
+
+// sendTalkEnd sends a talk_end event including the transmission quality
+// report; reason is the disconnect that cut the transmission off, if any
+func (m *Manager) sendTalkEnd(r *Repeater, address string, duration time.Duration, reason string) {
+	m.emit(Event{
+		Type:      EventTalkEnd,
+		Callsign:  r.Talker(),
+		Address:   address,
+		Timestamp: m.clock.Now(),
+		Duration:  duration,
+		Quality:   r.TalkQuality(),
+		Origin:    r.TalkOrigin(),
+		Gateway:   r.Callsign(),
+		Reason:    reason,
+	})
+
+	m.checkKerchunk(r.Talker(), address, duration)
+}
+
+// emit delivers an event without blocking
+func (m *Manager) emit(event Event) {
+	if m.events == nil {
+		return
 	}
 
 	select {
@@ -85,3 +85,121 @@ func TestMuteOnTalkMaxDuration(t *testing.T) {
 	cancel()
 	_ = ctx
 }
+
+func TestMaxConnectionsPerIP(t *testing.T) {
+	events := make(chan Event, 10)
+	m := NewManager(5*time.Second, 10, events, 180*time.Second, 0)
+	m.SetMaxConnectionsPerIP(2)
+
+	if _, ok := m.AddRepeater("R1", mustAddr(t, "10.0.0.1:40001")); !ok {
+		t.Fatalf("expected first repeater to be accepted")
+	}
+	if _, ok := m.AddRepeater("R2", mustAddr(t, "10.0.0.1:40002")); !ok {
+		t.Fatalf("expected second repeater to be accepted")
+	}
+	if r, ok := m.AddRepeater("R3", mustAddr(t, "10.0.0.1:40003")); ok || r != nil {
+		t.Fatalf("expected third repeater from the same IP to be rejected")
+	}
+	if _, ok := m.AddRepeater("R4", mustAddr(t, "10.0.0.2:40001")); !ok {
+		t.Fatalf("expected repeater from another IP to be accepted")
+	}
+
+	// A repeat attempt within a minute must not emit another event
+	m.AddRepeater("R3", mustAddr(t, "10.0.0.1:40003"))
+
+	limitEvents := 0
+	for len(events) > 0 {
+		if ev := <-events; ev.Type == EventIPLimit {
+			limitEvents++
+		}
+	}
+	if limitEvents != 1 {
+		t.Errorf("expected 1 ip_limit event, got %d", limitEvents)
+	}
+	if got := m.GetStats().IPLimitRejections; got != 2 {
+		t.Errorf("expected 2 rejections, got %d", got)
+	}
+
+	summary := m.GetIPSummary()
+	if len(summary) != 2 || summary[0].Count != 2 || summary[0].IP != "10.0.**" {
+		t.Errorf("unexpected IP summary: %+v", summary)
+	}
+}
+
+func TestTalkEventsIncludeGateway(t *testing.T) {
+	events := make(chan Event, 10)
+	m := NewManager(5*time.Second, 10, events, 180*time.Second, 0)
+	addr := mustAddr(t, "127.0.0.1:40010")
+
+	m.AddRepeater("GW1", addr)
+	<-events // connect
+
+	m.ProcessPacket("N0CALL", addr, "YSFD", 155)
+	ev := <-events
+	if ev.Type != EventTalkStart || ev.Gateway != "GW1" {
+		t.Fatalf("expected talk_start through GW1, got %+v", ev)
+	}
+}
+
+func TestTalkEndNamesSourceCallsign(t *testing.T) {
+	events := make(chan Event, 10)
+	m := NewManager(5*time.Second, 10, events, 180*time.Second, 0)
+	addr := mustAddr(t, "127.0.0.1:40010")
+
+	m.AddRepeater("GW1", addr)
+	<-events // connect
+
+	m.ProcessPacket("N0CALL", addr, "YSFD", 155)
+	start := <-events
+	m.RemoveRepeater(addr, DisconnectUnlink)
+	end := <-events
+	if start.Type != EventTalkStart || end.Type != EventTalkEnd {
+		t.Fatalf("expected talk_start and talk_end, got %s and %s", start.Type, end.Type)
+	}
+	if end.Callsign != start.Callsign || end.Callsign != "N0CALL" || end.Gateway != "GW1" {
+		t.Errorf("talk_end names %q via %q, talk_start %q via %q", end.Callsign, end.Gateway, start.Callsign, start.Gateway)
+	}
+}
+
+func TestDoublingDetected(t *testing.T) {
+	events := make(chan Event, 10)
+	m := NewManager(5*time.Second, 10, events, 180*time.Second, 0)
+
+	addr1 := mustAddr(t, "127.0.0.1:44001")
+	addr2 := mustAddr(t, "127.0.0.1:44002")
+	m.AddRepeater("GW1", addr1)
+	m.AddRepeater("GW2", addr2)
+
+	m.ProcessPacket("W1AW", addr1, "YSFD", 155)
+	for len(events) > 0 {
+		<-events // connect and talk_start
+	}
+
+	// The second repeater doubles while the first holds the channel
+	m.ProcessPacket("K8ABC", addr2, "YSFD", 155)
+	m.ProcessPacket("K8ABC", addr2, "YSFD", 155)
+	if len(events) != 0 {
+		t.Fatalf("doubling must only be reported once the suppressed stream ends")
+	}
+
+	// Expire the suppressed stream
+	for _, double := range m.doublings.expire(time.Now().Add(5*time.Second), 3*time.Second) {
+		m.sendDoubling(double, addr2.String())
+	}
+
+	ev := <-events
+	if ev.Type != EventDoubling || ev.Callsign != "K8ABC" || ev.Gateway != "GW2" {
+		t.Fatalf("unexpected event: %+v", ev)
+	}
+	if ev.Data["active_callsign"] != "W1AW" || ev.Data["active_gateway"] != "GW1" {
+		t.Errorf("unexpected active talker data: %v", ev.Data)
+	}
+
+	recent, counts, total := m.GetDoublings(0)
+	if total != 1 || len(recent) != 1 || counts["K8ABC"] != 1 {
+		t.Errorf("unexpected doubling stats: total=%d recent=%d counts=%v", total, len(recent), counts)
+	}
+	if m.GetStats().Doublings != 1 {
+		t.Errorf("expected doublings counter in stats")
+	}
+}
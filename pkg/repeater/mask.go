@@ -0,0 +1,53 @@
+package repeater
+
+import (
+	"net"
+	"regexp"
+)
+
+// IP masking levels for addresses shown on the dashboard and API
+const (
+	MaskFull    = "full"    // hide the IP, keep the port
+	MaskPartial = "partial" // hide the last two octets of an IPv4 address
+	MaskNone    = "none"    // show the address as is
+)
+
+var partialMask = regexp.MustCompile(`^(\d+\.\d+\.)\d+\.\d+(:\d+)?$`)
+
+// maskIPAddress masks the last two octets of an IP address for privacy
+// Example: 192.168.1.100:42000 -> 192.168.**:42000
+func maskIPAddress(address string) string {
+	return partialMask.ReplaceAllString(address, "${1}**${2}")
+}
+
+// MaskAddress masks an IP or IP:port at the given level. Unknown levels mask partially.
+// Example: 192.168.1.100:42000 -> **:42000 (full), 192.168.**:42000 (partial)
+func MaskAddress(address, level string) string {
+	switch level {
+	case MaskNone:
+		return address
+	case MaskFull:
+		if _, port, err := net.SplitHostPort(address); err == nil {
+			return "**:" + port
+		}
+		return "**"
+	default:
+		return maskIPAddress(address)
+	}
+}
+
+// Masked returns the statistics with the address masked at the given level
+func (s RepeaterStats) Masked(level string) RepeaterStats {
+	if s.rawAddress != "" {
+		s.Address = MaskAddress(s.rawAddress, level)
+	}
+	return s
+}
+
+// Masked returns the summary with the IP masked at the given level
+func (s IPSummary) Masked(level string) IPSummary {
+	if s.rawIP != "" {
+		s.IP = MaskAddress(s.rawIP, level)
+	}
+	return s
+}
@@ -0,0 +1,38 @@
+package repeater
+
+import "testing"
+
+func TestMaskAddress(t *testing.T) {
+	tests := []struct {
+		address, level, want string
+	}{
+		{"192.168.1.100:42000", MaskPartial, "192.168.**:42000"},
+		{"192.168.1.100", "", "192.168.**"},
+		{"192.168.1.100:42000", MaskFull, "**:42000"},
+		{"[2001:db8::1]:42000", MaskFull, "**:42000"},
+		{"192.168.1.100", MaskFull, "**"},
+		{"192.168.1.100:42000", MaskNone, "192.168.1.100:42000"},
+	}
+	for _, tt := range tests {
+		if got := MaskAddress(tt.address, tt.level); got != tt.want {
+			t.Errorf("MaskAddress(%q, %q) = %q, want %q", tt.address, tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestRepeaterStatsMasked(t *testing.T) {
+	m := NewManager(5, 10, make(chan Event, 10), 180, 0)
+	addr := mustAddr(t, "192.0.2.10:42000")
+	m.AddRepeater("W1AW", addr)
+
+	stats := m.GetRepeater(addr).Stats()
+	if stats.Address != "192.0.**:42000" {
+		t.Errorf("expected partial mask by default, got %q", stats.Address)
+	}
+	if got := stats.Masked(MaskNone).Address; got != "192.0.2.10:42000" {
+		t.Errorf("expected unmasked address, got %q", got)
+	}
+	if got := m.GetIPSummary()[0].Masked(MaskFull).IP; got != "**" {
+		t.Errorf("expected fully masked IP, got %q", got)
+	}
+}
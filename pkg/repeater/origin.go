@@ -0,0 +1,85 @@
+package repeater
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Transmission source types
+const (
+	SourceRepeater = "repeater" // A local repeater or hotspot
+	SourceBridge   = "bridge"   // Another reflector over a bridge
+	SourceYSF2DMR  = "ysf2dmr"  // Cross-mode traffic addressed to a DMR talkgroup
+)
+
+// TalkOrigin describes where a transmission came from and how far it reached
+type TalkOrigin struct {
+	SourceType string `json:"source_type"`
+	// DGID is the DG-ID of the first frame whose FICH decoded
+	DGID *uint8 `json:"dgid,omitempty"`
+	// TalkGroup is the DMR talkgroup named in the destination field, e.g. "TG91"
+	TalkGroup uint32 `json:"talkgroup,omitempty"`
+	// Recipients is the most repeaters and bridges one frame was sent to
+	Recipients int `json:"recipients"`
+}
+
+// OriginTracker collects the origin of a transmission frame by frame
+type OriginTracker struct {
+	mu     sync.Mutex
+	origin TalkOrigin
+}
+
+// NewOriginTracker creates a tracker for a transmission from sourceType
+func NewOriginTracker(sourceType string) *OriginTracker {
+	return &OriginTracker{origin: TalkOrigin{SourceType: sourceType}}
+}
+
+// Record notes one frame: the DG-ID from its FICH if that decoded, its
+// destination callsign field and the number of destinations it was sent to.
+// A local stream addressed to a talkgroup is reported as ysf2dmr.
+func (t *OriginTracker) Record(dgid uint8, hasDGID bool, dest string, recipients int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if hasDGID && t.origin.DGID == nil {
+		t.origin.DGID = &dgid
+	}
+	if t.origin.TalkGroup == 0 {
+		if tg, ok := parseTalkGroup(dest); ok {
+			t.origin.TalkGroup = tg
+			if t.origin.SourceType == SourceRepeater {
+				t.origin.SourceType = SourceYSF2DMR
+			}
+		}
+	}
+	if recipients > t.origin.Recipients {
+		t.origin.Recipients = recipients
+	}
+}
+
+// Origin returns the origin collected so far
+func (t *OriginTracker) Origin() TalkOrigin {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	origin := t.origin
+	if origin.DGID != nil {
+		dgid := *origin.DGID
+		origin.DGID = &dgid
+	}
+	return origin
+}
+
+// parseTalkGroup reads a DMR talkgroup from a destination callsign field
+// such as "TG91" or "TG 3100"
+func parseTalkGroup(dest string) (uint32, bool) {
+	dest = strings.ToUpper(strings.TrimSpace(dest))
+	if !strings.HasPrefix(dest, "TG") {
+		return 0, false
+	}
+	tg, err := strconv.ParseUint(strings.TrimSpace(dest[2:]), 10, 32)
+	if err != nil || tg == 0 {
+		return 0, false
+	}
+	return uint32(tg), true
+}
@@ -0,0 +1,39 @@
+package repeater
+
+import "testing"
+
+func TestOriginTracker(t *testing.T) {
+	o := NewOriginTracker(SourceRepeater)
+	o.Record(0, false, "ALL", 3)
+	o.Record(12, true, "ALL", 5)
+	o.Record(20, true, "ALL", 4)
+
+	origin := o.Origin()
+	if origin.SourceType != SourceRepeater || origin.DGID == nil || *origin.DGID != 12 || origin.Recipients != 5 || origin.TalkGroup != 0 {
+		t.Errorf("unexpected origin %+v", origin)
+	}
+
+	// A talkgroup in the destination marks cross-mode traffic
+	o = NewOriginTracker(SourceRepeater)
+	o.Record(0, false, "TG 3100", 1)
+	if origin := o.Origin(); origin.SourceType != SourceYSF2DMR || origin.TalkGroup != 3100 {
+		t.Errorf("expected ysf2dmr on TG 3100, got %+v", origin)
+	}
+
+	// Bridge traffic stays bridge traffic
+	o = NewOriginTracker(SourceBridge)
+	o.Record(0, false, "TG91", 1)
+	if origin := o.Origin(); origin.SourceType != SourceBridge || origin.TalkGroup != 91 {
+		t.Errorf("expected bridge on TG 91, got %+v", origin)
+	}
+}
+
+func TestParseTalkGroup(t *testing.T) {
+	cases := map[string]uint32{"TG91": 91, "tg 3100": 3100, "ALL": 0, "TG": 0, "TG0": 0, "TGX": 0}
+	for dest, want := range cases {
+		got, ok := parseTalkGroup(dest)
+		if got != want || ok != (want != 0) {
+			t.Errorf("parseTalkGroup(%q) = %d, %v", dest, got, ok)
+		}
+	}
+}
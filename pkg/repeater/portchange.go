@@ -0,0 +1,101 @@
+package repeater
+
+import (
+	"net"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// Port changes. Repeaters are matched by source address, so a NAT that
+// rewrites the source port mid-session makes the next poll look like a new
+// repeater while the old entry lingers until it times out. With port
+// following enabled, a poll from a known callsign and IP on a new port moves
+// the existing session to that port instead, keeping its uptime, counters,
+// mute and any transmission in progress.
+
+// SetFollowPortChanges enables matching repeaters by callsign and IP, so a
+// source port change moves the session instead of creating a new entry. It
+// should stay off when several hotspots with one callsign link from behind
+// the same NAT, as they would take the session from each other.
+func (m *Manager) SetFollowPortChanges(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.followPorts = enabled
+}
+
+// PortChangeCandidate returns the connected repeater whose session a poll
+// from callsign at addr would move to addr, or nil if port following is off
+// or there is no such repeater. With several candidates the most recently
+// seen is the one moved; the others time out as before.
+func (m *Manager) PortChangeCandidate(callsign string, addr *net.UDPAddr) *Repeater {
+	m.mu.RLock()
+	follow := m.followPorts
+	m.mu.RUnlock()
+	if !follow {
+		return nil
+	}
+
+	callsign = normalizeCallsign(callsign)
+	key := addr.String()
+	var previous *Repeater
+	m.repeaters.Range(func(k, value interface{}) bool {
+		r := value.(*Repeater)
+		if k.(string) != key && normalizeCallsign(r.Callsign()) == callsign && r.Address().IP.Equal(addr.IP) &&
+			(previous == nil || r.LastSeen().After(previous.LastSeen())) {
+			previous = r
+		}
+		return true
+	})
+	return previous
+}
+
+// followPortChange moves the session of the repeater PortChangeCandidate
+// finds to addr, returning it, or nil if there is none.
+func (m *Manager) followPortChange(callsign string, addr *net.UDPAddr) *Repeater {
+	previous := m.PortChangeCandidate(callsign, addr)
+	if previous == nil {
+		return nil
+	}
+
+	oldAddr := previous.Address()
+	oldKey, newKey := oldAddr.String(), addr.String()
+	if _, ok := m.repeaters.Load(oldKey); !ok {
+		return nil // Removed meanwhile
+	}
+	// Store under the new key before dropping the old one, so packets from
+	// either port find the session throughout the move
+	previous.setAddress(addr)
+	previous.UpdateLastSeen()
+	m.repeaters.Store(newKey, previous)
+	m.repeaters.CompareAndDelete(oldKey, previous)
+	m.invalidateRoster()
+
+	// Per-address stream state follows the session
+	m.activeMu.Lock()
+	if m.activeKey == oldKey {
+		m.activeKey = newKey
+	}
+	m.activeMu.Unlock()
+	if until, ok := m.muted.LoadAndDelete(oldKey); ok {
+		m.muted.Store(newKey, until)
+	}
+
+	m.mu.Lock()
+	m.metrics.PortChanges++
+	m.mu.Unlock()
+
+	if m.logger != nil {
+		m.logger.Info("Repeater source port changed",
+			logger.String("callsign", previous.Callsign()),
+			logger.String("from", oldKey),
+			logger.String("to", newKey))
+	}
+	m.emit(Event{
+		Type:      EventPortChanged,
+		Callsign:  previous.Callsign(),
+		Address:   newKey,
+		Timestamp: m.clock.Now(),
+		Data:      map[string]interface{}{"previous_port": oldAddr.Port},
+	})
+	return previous
+}
@@ -0,0 +1,113 @@
+package repeater
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFollowPortChange(t *testing.T) {
+	events := make(chan Event, 50)
+	m := NewManager(5*time.Second, 10, events, 180*time.Second, 0)
+	oldAddr := mustAddr(t, "10.0.0.1:40001")
+	newAddr := mustAddr(t, "10.0.0.1:40002")
+
+	// Strict matching by default: a new port is a new repeater
+	m.AddRepeater("R1", oldAddr)
+	if _, added := m.AddRepeater("R1", newAddr); !added || m.Count() != 2 {
+		t.Fatalf("expected a second entry without port following, have %d", m.Count())
+	}
+	m.RemoveRepeater(newAddr, DisconnectUnlink)
+
+	m.SetFollowPortChanges(true)
+	m.ProcessPacket("N0CALL", oldAddr, "YSFD", 155)
+	original := m.GetRepeater(oldAddr)
+	for len(events) > 0 {
+		<-events
+	}
+
+	r, added := m.AddRepeater("r1", newAddr)
+	if added || r != original {
+		t.Fatalf("expected the existing session to move, got added=%v", added)
+	}
+	if m.Count() != 1 || m.GetRepeater(oldAddr) != nil || m.GetRepeater(newAddr) != original {
+		t.Fatal("expected the session to be found only under the new port")
+	}
+	if r.Address().Port != 40002 || !r.IsTalking() {
+		t.Errorf("expected the talking session on port 40002, got %s talking=%v", r.Address(), r.IsTalking())
+	}
+
+	// The transmission in progress continues from the new port
+	m.ProcessPacket("N0CALL", newAddr, "YSFD", 155)
+	m.activeMu.Lock()
+	active := m.activeKey
+	m.activeMu.Unlock()
+	if active != newAddr.String() || !r.IsTalking() {
+		t.Errorf("expected the new port to keep the channel, active %q", active)
+	}
+
+	var moved *Event
+	for len(events) > 0 {
+		ev := <-events
+		switch ev.Type {
+		case EventPortChanged:
+			moved = &ev
+		case EventConnect, EventDisconnect, EventTalkEnd:
+			t.Errorf("unexpected %s event", ev.Type)
+		}
+	}
+	if moved == nil || moved.Address != newAddr.String() || moved.Data["previous_port"] != 40001 {
+		t.Errorf("unexpected port change event %+v", moved)
+	}
+	if got := m.GetStats().PortChanges; got != 1 {
+		t.Errorf("port changes = %d, want 1", got)
+	}
+
+	// Other callsigns and other IPs are still new repeaters
+	if _, added := m.AddRepeater("R2", mustAddr(t, "10.0.0.1:40003")); !added {
+		t.Error("expected another callsign on the same IP to be a new repeater")
+	}
+	if _, added := m.AddRepeater("R1", mustAddr(t, "10.0.0.9:40002")); !added {
+		t.Error("expected the callsign on another IP to be a new repeater")
+	}
+}
+
+// TestFollowPortChangeConcurrentStats moves a session back and forth while
+// stats are read; run with -race to check the address is read safely.
+func TestFollowPortChangeConcurrentStats(t *testing.T) {
+	m := NewManager(5*time.Second, 10, nil, 180*time.Second, 0)
+	m.SetFollowPortChanges(true)
+	ports := []string{"10.0.0.1:40001", "10.0.0.1:40002"}
+	m.AddRepeater("R1", mustAddr(t, ports[0]))
+
+	stop, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			for _, stats := range m.GetStats().Repeaters {
+				if stats.Callsign != "R1" {
+					t.Errorf("unexpected repeater %q", stats.Callsign)
+				}
+			}
+			for _, r := range m.GetAllRepeaters() {
+				_ = r.Address().String()
+			}
+		}
+	}()
+	for i := 1; i <= 200; i++ {
+		m.AddRepeater("R1", mustAddr(t, ports[i%2]))
+	}
+	close(stop)
+	<-done
+
+	if m.Count() != 1 {
+		t.Errorf("expected one session after the moves, have %d", m.Count())
+	}
+	if got := m.GetStats().PortChanges; got != 200 {
+		t.Errorf("port changes = %d, want 200", got)
+	}
+}
@@ -0,0 +1,256 @@
+package repeater
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// Probe defaults
+const (
+	DefaultProbeCount    = 10
+	MaxProbeCount        = 50
+	DefaultProbeInterval = 200 * time.Millisecond
+	DefaultProbeTimeout  = 500 * time.Millisecond
+)
+
+// ErrProbeInProgress is returned when a repeater is already being probed
+var ErrProbeInProgress = errors.New("probe already in progress")
+
+// ProbeOptions controls a latency probe. Zero values use the defaults.
+type ProbeOptions struct {
+	Count    int
+	Interval time.Duration // Pause between probes
+	Timeout  time.Duration // How long to wait for each reply
+}
+
+// ProbeResult is the outcome of a latency probe. Times are in milliseconds.
+//
+// A probe sends a poll to the repeater and times the next packet from its
+// address. Clients that do not answer reflector polls show high loss, and the
+// few samples they return are their own periodic polls rather than replies.
+type ProbeResult struct {
+	Callsign   string    `json:"callsign"`
+	StartedAt  time.Time `json:"started_at"`
+	Sent       int       `json:"sent"`
+	Received   int       `json:"received"`
+	Loss       float64   `json:"loss"` // Percent of probes without a reply
+	MinRTT     float64   `json:"min_rtt_ms"`
+	AvgRTT     float64   `json:"avg_rtt_ms"`
+	MaxRTT     float64   `json:"max_rtt_ms"`
+	Jitter     float64   `json:"jitter_ms"`      // Mean difference between consecutive round trips
+	PollJitter float64   `json:"poll_jitter_ms"` // Deviation of the repeater's own poll cadence
+}
+
+// probeState tracks an in-flight probe and the last result for a repeater
+type probeState struct {
+	mu     sync.Mutex
+	active bool
+	reply  chan time.Time // Non-nil while waiting for a reply
+	last   *ProbeResult
+}
+
+// begin marks a probe as running, failing if one already is
+func (p *probeState) begin() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.active {
+		return ErrProbeInProgress
+	}
+	p.active = true
+	return nil
+}
+
+// end stores the result and clears the running flag
+func (p *probeState) end(result *ProbeResult) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.active = false
+	p.reply = nil
+	if result != nil {
+		p.last = result
+	}
+}
+
+// arm returns a channel that receives the arrival time of the next packet
+func (p *probeState) arm() <-chan time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.reply = make(chan time.Time, 1)
+	return p.reply
+}
+
+// disarm stops waiting for a reply
+func (p *probeState) disarm() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.reply = nil
+}
+
+// observe hands a packet arrival to a waiting probe
+func (p *probeState) observe(at time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.reply != nil {
+		p.reply <- at
+		p.reply = nil
+	}
+}
+
+// lastResult returns the most recent probe result, or nil if never probed
+func (p *probeState) lastResult() *ProbeResult {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.last == nil {
+		return nil
+	}
+	result := *p.last
+	return &result
+}
+
+// FindByCallsign returns the first connected repeater with the given callsign, or nil
+func (m *Manager) FindByCallsign(callsign string) *Repeater {
+	callsign = strings.TrimSpace(callsign)
+	for _, repeater := range m.GetAllRepeaters() {
+		if strings.EqualFold(strings.TrimSpace(repeater.Callsign()), callsign) {
+			return repeater
+		}
+	}
+	return nil
+}
+
+// Probe measures round-trip time and jitter to a connected repeater by sending
+// packet with send and timing the next packet received from its address.
+// The result is also kept on the repeater and reported in its stats.
+func (m *Manager) Probe(ctx context.Context, callsign string, opts ProbeOptions, packet []byte, send func([]byte, *net.UDPAddr) error) (ProbeResult, error) {
+	if opts.Count <= 0 {
+		opts.Count = DefaultProbeCount
+	}
+	if opts.Count > MaxProbeCount {
+		opts.Count = MaxProbeCount
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultProbeInterval
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultProbeTimeout
+	}
+
+	repeater := m.FindByCallsign(callsign)
+	if repeater == nil {
+		return ProbeResult{}, fmt.Errorf("repeater %s not connected", callsign)
+	}
+	if err := repeater.probe.begin(); err != nil {
+		return ProbeResult{}, err
+	}
+
+	result := ProbeResult{Callsign: repeater.Callsign(), StartedAt: time.Now()}
+	var rtts []time.Duration
+	var err error
+
+	for i := 0; i < opts.Count; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				err = ctx.Err()
+			case <-time.After(opts.Interval):
+			}
+			if err != nil {
+				break
+			}
+		}
+
+		reply := repeater.probe.arm()
+		sent := time.Now()
+		if err = send(packet, repeater.Address()); err != nil {
+			break
+		}
+		result.Sent++
+
+		select {
+		case at := <-reply:
+			rtts = append(rtts, at.Sub(sent))
+		case <-time.After(opts.Timeout):
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+		repeater.probe.disarm()
+		if err != nil {
+			break
+		}
+	}
+
+	if err != nil {
+		repeater.probe.end(nil)
+		return ProbeResult{}, err
+	}
+
+	result.summarize(rtts)
+	if fp := repeater.Fingerprint(); fp != nil {
+		result.PollJitter = fp.PollJitter
+	}
+	repeater.probe.end(&result)
+
+	m.logger.Info("Repeater probe finished",
+		logger.String("callsign", result.Callsign),
+		logger.Int("sent", result.Sent),
+		logger.Int("received", result.Received),
+		logger.Any("avg_rtt_ms", result.AvgRTT),
+		logger.Any("jitter_ms", result.Jitter))
+
+	return result, nil
+}
+
+// summarize fills in the loss, round-trip and jitter figures from the samples
+func (p *ProbeResult) summarize(rtts []time.Duration) {
+	p.Received = len(rtts)
+	if p.Sent > 0 {
+		p.Loss = roundTenth(float64(p.Sent-p.Received) / float64(p.Sent) * 100)
+	}
+	if len(rtts) == 0 {
+		return
+	}
+
+	minRTT, maxRTT, total := rtts[0], rtts[0], time.Duration(0)
+	var jitter time.Duration
+	for i, rtt := range rtts {
+		total += rtt
+		if rtt < minRTT {
+			minRTT = rtt
+		}
+		if rtt > maxRTT {
+			maxRTT = rtt
+		}
+		if i > 0 {
+			diff := rtt - rtts[i-1]
+			if diff < 0 {
+				diff = -diff
+			}
+			jitter += diff
+		}
+	}
+
+	p.MinRTT = millis(minRTT)
+	p.MaxRTT = millis(maxRTT)
+	p.AvgRTT = millis(total / time.Duration(len(rtts)))
+	if len(rtts) > 1 {
+		p.Jitter = millis(jitter / time.Duration(len(rtts)-1))
+	}
+}
+
+// millis converts a duration to milliseconds rounded to a tenth
+func millis(d time.Duration) float64 {
+	return roundTenth(float64(d) / float64(time.Millisecond))
+}
+
+// roundTenth rounds to one decimal place
+func roundTenth(v float64) float64 {
+	return math.Round(v*10) / 10
+}
@@ -0,0 +1,66 @@
+package repeater
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+func TestProbeMeasuresRoundTripAndLoss(t *testing.T) {
+	events := make(chan Event, 10)
+	m := NewManagerWithLogger(time.Minute, 10, events, time.Minute, 0, logger.NewTestLogger(os.Stdout))
+	addr := mustAddr(t, "127.0.0.1:45010")
+	m.AddRepeater("W1AW", addr)
+
+	// Answer every other probe after a short delay
+	sent := 0
+	send := func(data []byte, to *net.UDPAddr) error {
+		sent++
+		if sent%2 == 1 {
+			go func() {
+				time.Sleep(5 * time.Millisecond)
+				m.ProcessPacket("W1AW", to, "YSFP", len(data))
+			}()
+		}
+		return nil
+	}
+
+	opts := ProbeOptions{Count: 4, Interval: time.Millisecond, Timeout: 100 * time.Millisecond}
+	result, err := m.Probe(context.Background(), "w1aw", opts, []byte("YSFP"), send)
+	if err != nil {
+		t.Fatalf("probe failed: %v", err)
+	}
+
+	if result.Sent != 4 || result.Received != 2 || result.Loss != 50 {
+		t.Errorf("unexpected counts: %+v", result)
+	}
+	if result.MinRTT < 5 || result.AvgRTT < result.MinRTT || result.MaxRTT < result.AvgRTT {
+		t.Errorf("unexpected round trips: %+v", result)
+	}
+	if m.GetRepeater(addr).Stats().LastProbe == nil {
+		t.Errorf("expected probe result in repeater stats")
+	}
+
+	if _, err := m.Probe(context.Background(), "N0CALL", opts, nil, send); err == nil {
+		t.Errorf("expected error for unknown repeater")
+	}
+}
+
+func TestProbeRejectsConcurrentRun(t *testing.T) {
+	events := make(chan Event, 10)
+	m := NewManagerWithLogger(time.Minute, 10, events, time.Minute, 0, logger.NewTestLogger(os.Stdout))
+	m.AddRepeater("W1AW", mustAddr(t, "127.0.0.1:45011"))
+
+	if err := m.FindByCallsign("W1AW").probe.begin(); err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	_, err := m.Probe(context.Background(), "W1AW", ProbeOptions{}, nil, func([]byte, *net.UDPAddr) error { return nil })
+	if !errors.Is(err, ErrProbeInProgress) {
+		t.Errorf("expected ErrProbeInProgress, got %v", err)
+	}
+}
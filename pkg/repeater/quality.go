@@ -0,0 +1,138 @@
+package repeater
+
+import (
+	"sync"
+	"time"
+)
+
+// Quality tracking constants
+const (
+	// frameInterval is the nominal spacing between YSF network frames
+	frameInterval = 100 * time.Millisecond
+	// gapThreshold is the inter-frame delay considered a gap in the stream
+	gapThreshold = 3 * frameInterval
+	// frameCounterModulo is the wrap-around of the 7-bit network frame counter
+	frameCounterModulo = 128
+)
+
+// StreamQuality accumulates quality indicators for a single transmission
+type StreamQuality struct {
+	mu         sync.Mutex
+	frames     uint32
+	lostFrames uint32
+	fichErrors uint32
+	gaps       uint32
+	lastSeq    int
+	firstFrame time.Time
+	lastFrame  time.Time
+}
+
+// QualityReport summarises the quality of a transmission
+type QualityReport struct {
+	Frames      uint32  `json:"frames"`
+	LostFrames  uint32  `json:"lost_frames"`
+	FICHErrors  uint32  `json:"fich_errors"`
+	Gaps        uint32  `json:"gaps"`
+	LossPercent float64 `json:"loss_percent"`
+	Score       int     `json:"score"` // 0 (unusable) to 100 (perfect)
+	// ExpectedFrames is the number of frames the transmission should have
+	// had, by the nominal frame cadence from its first to its last frame or
+	// by the frame counter, whichever shows more
+	ExpectedFrames uint32 `json:"expected_frames"`
+	// MissingPercent is the share of expected frames that never arrived.
+	// Unlike LossPercent it also catches losses the 7-bit frame counter
+	// cannot, such as long dropouts or streams without a counter.
+	MissingPercent float64 `json:"missing_percent"`
+}
+
+// NewStreamQuality creates an empty quality tracker
+func NewStreamQuality() *StreamQuality {
+	return &StreamQuality{lastSeq: -1}
+}
+
+// Record accounts for one received frame. seq is the 7-bit network frame counter
+// (ignored when hasSeq is false) and fichOK reports whether the FICH CRC passed.
+func (q *StreamQuality) Record(seq uint8, hasSeq bool, fichOK bool, at time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if hasSeq && q.lastSeq >= 0 {
+		delta := (int(seq) - q.lastSeq + frameCounterModulo) % frameCounterModulo
+		if delta == 0 {
+			// Duplicate frame; don't count it twice
+			return
+		}
+		// Large jumps are treated as reordering rather than loss
+		if delta > 1 && delta < frameCounterModulo/2 {
+			q.lostFrames += uint32(delta - 1)
+		}
+	}
+	if hasSeq {
+		q.lastSeq = int(seq)
+	}
+
+	if !q.lastFrame.IsZero() && at.Sub(q.lastFrame) > gapThreshold {
+		q.gaps++
+	}
+	if q.firstFrame.IsZero() {
+		q.firstFrame = at
+	}
+	q.lastFrame = at
+
+	q.frames++
+	if !fichOK {
+		q.fichErrors++
+	}
+}
+
+// Report returns a snapshot of the accumulated quality indicators
+func (q *StreamQuality) Report() QualityReport {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	report := QualityReport{
+		Frames:     q.frames,
+		LostFrames: q.lostFrames,
+		FICHErrors: q.fichErrors,
+		Gaps:       q.gaps,
+	}
+
+	expected := q.frames + q.lostFrames
+	if expected == 0 {
+		return report
+	}
+
+	// One frame every frameInterval from the first frame to the last; frames
+	// arriving early make up for late ones, so only the span matters
+	span := q.lastFrame.Sub(q.firstFrame)
+	report.ExpectedFrames = uint32((span+frameInterval/2)/frameInterval) + 1
+	if report.ExpectedFrames < expected {
+		report.ExpectedFrames = expected
+	}
+	report.MissingPercent = float64(report.Missing()) / float64(report.ExpectedFrames) * 100
+
+	lossRatio := float64(q.lostFrames) / float64(expected)
+	report.LossPercent = lossRatio * 100
+
+	var fichRatio float64
+	if q.frames > 0 {
+		fichRatio = float64(q.fichErrors) / float64(q.frames)
+	}
+
+	// Loss and FICH errors scale the score; each gap costs a couple of points
+	score := 100*(1-lossRatio)*(1-fichRatio) - 2*float64(q.gaps)
+	if score < 0 {
+		score = 0
+	}
+	report.Score = int(score + 0.5)
+
+	return report
+}
+
+// Missing returns how many frames of the transmission never arrived
+func (r QualityReport) Missing() uint32 {
+	if r.ExpectedFrames < r.Frames {
+		return 0
+	}
+	return r.ExpectedFrames - r.Frames
+}
@@ -0,0 +1,93 @@
+package repeater
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStreamQualityPerfectStream(t *testing.T) {
+	q := NewStreamQuality()
+	start := time.Now()
+	for i := 0; i < 200; i++ {
+		q.Record(uint8(i%128), true, true, start.Add(time.Duration(i)*frameInterval))
+	}
+
+	r := q.Report()
+	if r.Frames != 200 || r.LostFrames != 0 || r.Gaps != 0 || r.FICHErrors != 0 {
+		t.Fatalf("unexpected report: %+v", r)
+	}
+	if r.Score != 100 {
+		t.Errorf("expected score 100, got %d", r.Score)
+	}
+	if r.ExpectedFrames != 200 || r.MissingPercent != 0 {
+		t.Errorf("expected no missing frames, got %+v", r)
+	}
+}
+
+func TestStreamQualityLossGapsAndFICH(t *testing.T) {
+	q := NewStreamQuality()
+	start := time.Now()
+
+	q.Record(0, true, true, start)
+	q.Record(1, true, true, start.Add(frameInterval))
+	// frames 2-4 lost, arriving late enough to count as a gap
+	q.Record(5, true, false, start.Add(6*frameInterval))
+	// duplicate should be ignored
+	q.Record(5, true, true, start.Add(6*frameInterval))
+	q.Record(6, true, true, start.Add(7*frameInterval))
+
+	r := q.Report()
+	if r.Frames != 4 {
+		t.Errorf("expected 4 frames, got %d", r.Frames)
+	}
+	if r.LostFrames != 3 {
+		t.Errorf("expected 3 lost frames, got %d", r.LostFrames)
+	}
+	if r.Gaps != 1 {
+		t.Errorf("expected 1 gap, got %d", r.Gaps)
+	}
+	if r.FICHErrors != 1 {
+		t.Errorf("expected 1 FICH error, got %d", r.FICHErrors)
+	}
+	if r.Score >= 100 || r.Score <= 0 {
+		t.Errorf("expected degraded score, got %d", r.Score)
+	}
+}
+
+func TestStreamQualityCounterWrap(t *testing.T) {
+	q := NewStreamQuality()
+	start := time.Now()
+	q.Record(126, true, true, start)
+	q.Record(127, true, true, start.Add(frameInterval))
+	q.Record(1, true, true, start.Add(2*frameInterval))
+
+	if r := q.Report(); r.LostFrames != 1 {
+		t.Errorf("expected 1 lost frame across wrap, got %d", r.LostFrames)
+	}
+}
+
+func TestStreamQualityCadenceLoss(t *testing.T) {
+	q := NewStreamQuality()
+	start := time.Now()
+
+	// A 20 second stream whose counter shows no loss because the middle
+	// 12.8 seconds (one full counter cycle) never arrived, with jitter
+	for i := 0; i < 200; i++ {
+		if i >= 50 && i < 178 {
+			continue
+		}
+		jitter := time.Duration(i%3-1) * 20 * time.Millisecond
+		q.Record(uint8(i%128), true, true, start.Add(time.Duration(i)*frameInterval+jitter))
+	}
+
+	r := q.Report()
+	if r.LostFrames != 0 {
+		t.Errorf("expected the counter to miss the dropout, got %d lost", r.LostFrames)
+	}
+	if r.ExpectedFrames != 200 {
+		t.Errorf("expected 200 frames at cadence, got %d", r.ExpectedFrames)
+	}
+	if r.MissingPercent != 64 {
+		t.Errorf("expected 64%% missing, got %.1f", r.MissingPercent)
+	}
+}
@@ -0,0 +1,115 @@
+package repeater
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Sort keys accepted by QueryRepeaters
+const (
+	SortByCallsign  = "callsign"
+	SortByLastHeard = "last_heard"
+	SortByTalkTime  = "talk_time"
+)
+
+// RepeaterQuery selects a page of repeaters
+type RepeaterQuery struct {
+	Search string // Case-insensitive substring of callsign or masked address
+	Group  string // Only members of this group, when set
+	Sort   string // One of the SortBy constants; defaults to callsign
+	Desc   bool
+	Page   int // 1-based page number
+	Limit  int // Page size; 0 returns all matches
+}
+
+// RepeaterPage is one page of a repeater query
+type RepeaterPage struct {
+	Repeaters []RepeaterStats `json:"repeaters"`
+	Total     int             `json:"total"` // Matches before paging
+	Page      int             `json:"page"`
+	Limit     int             `json:"limit"`
+}
+
+// QueryRepeaters filters, sorts and pages the connected repeaters.
+// Stats are only built for the repeaters on the returned page.
+func (m *Manager) QueryRepeaters(q RepeaterQuery) (RepeaterPage, error) {
+	var less func(a, b *Repeater) bool
+	switch q.Sort {
+	case "", SortByCallsign:
+		less = func(a, b *Repeater) bool { return a.Callsign() < b.Callsign() }
+	case SortByLastHeard:
+		less = func(a, b *Repeater) bool { return a.LastSeen().Before(b.LastSeen()) }
+	case SortByTalkTime:
+		less = func(a, b *Repeater) bool { return a.TotalTalkTime() < b.TotalTalkTime() }
+	default:
+		return RepeaterPage{}, fmt.Errorf("unknown sort key %q", q.Sort)
+	}
+
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.Limit < 0 {
+		q.Limit = 0
+	}
+	search := strings.ToUpper(strings.TrimSpace(q.Search))
+
+	var group *Group
+	if name := strings.ToLower(strings.TrimSpace(q.Group)); name != "" {
+		m.groups.mu.RLock()
+		g, ok := m.groups.groups[name]
+		m.groups.mu.RUnlock()
+		if !ok {
+			return RepeaterPage{}, fmt.Errorf("unknown group %q", q.Group)
+		}
+		group = &g
+	}
+
+	var matches []*Repeater
+	m.repeaters.Range(func(key, value interface{}) bool {
+		r, ok := value.(*Repeater)
+		if !ok {
+			return true
+		}
+		if group != nil && !groupMatches(*group, r.Callsign()) {
+			return true
+		}
+		if search == "" ||
+			strings.Contains(strings.ToUpper(r.Callsign()), search) ||
+			strings.Contains(maskIPAddress(r.Address().String()), search) {
+			matches = append(matches, r)
+		}
+		return true
+	})
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if q.Desc {
+			return less(matches[j], matches[i])
+		}
+		return less(matches[i], matches[j])
+	})
+
+	page := RepeaterPage{Total: len(matches), Page: q.Page, Limit: q.Limit}
+	if q.Limit > 0 {
+		// Bounds are compared before multiplying or adding so huge page
+		// numbers and limits cannot overflow
+		start := len(matches)
+		if q.Page-1 <= len(matches)/q.Limit {
+			start = (q.Page - 1) * q.Limit
+		}
+		end := len(matches)
+		if q.Limit < end-start {
+			end = start + q.Limit
+		}
+		matches = matches[start:end]
+	}
+
+	page.Repeaters = make([]RepeaterStats, 0, len(matches))
+	for _, r := range matches {
+		stats := r.Stats()
+		stats.Groups = m.GroupsOf(r.Callsign())
+		stats.TalkLimit = m.TalkLimitOf(r.Callsign())
+		page.Repeaters = append(page.Repeaters, stats)
+	}
+	return page, nil
+}
@@ -0,0 +1,70 @@
+package repeater
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueryRepeaters(t *testing.T) {
+	m := NewManager(time.Minute, 10, make(chan Event, 10), time.Minute, 0)
+
+	now := time.Now()
+	for i, cs := range []string{"W1AW", "K8ABC", "N8XYZ", "K8DEF"} {
+		r, _ := m.AddRepeater(cs, mustAddr(t, "10.0.0.1:4200"+string(rune('0'+i))))
+		r.lastSeen = now.Add(time.Duration(i) * time.Second)
+		r.talkTotal = time.Duration(10-i) * time.Second
+	}
+
+	page, err := m.QueryRepeaters(RepeaterQuery{})
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if page.Total != 4 || callsigns(page) != "K8ABC,K8DEF,N8XYZ,W1AW" {
+		t.Errorf("default sort: got %d %s", page.Total, callsigns(page))
+	}
+
+	page, _ = m.QueryRepeaters(RepeaterQuery{Search: "k8", Sort: SortByLastHeard, Desc: true})
+	if page.Total != 2 || callsigns(page) != "K8DEF,K8ABC" {
+		t.Errorf("search by last heard: got %d %s", page.Total, callsigns(page))
+	}
+
+	page, _ = m.QueryRepeaters(RepeaterQuery{Sort: SortByTalkTime, Page: 2, Limit: 3})
+	if page.Total != 4 || callsigns(page) != "W1AW" {
+		t.Errorf("second page by talk time: got %d %s", page.Total, callsigns(page))
+	}
+
+	page, _ = m.QueryRepeaters(RepeaterQuery{Page: 5, Limit: 3})
+	if len(page.Repeaters) != 0 {
+		t.Errorf("expected empty page past the end, got %s", callsigns(page))
+	}
+
+	// Extreme values must not overflow the page bounds
+	const maxInt = int(^uint(0) >> 1)
+	for _, q := range []RepeaterQuery{
+		{Page: 2, Limit: maxInt},
+		{Page: maxInt, Limit: maxInt},
+		{Page: maxInt, Limit: 2},
+	} {
+		if page, err := m.QueryRepeaters(q); err != nil || len(page.Repeaters) != 0 {
+			t.Errorf("page %d limit %d: got %s, %v", q.Page, q.Limit, callsigns(page), err)
+		}
+	}
+	if page, _ := m.QueryRepeaters(RepeaterQuery{Page: 1, Limit: maxInt}); len(page.Repeaters) != 4 {
+		t.Errorf("expected every repeater on a huge first page, got %s", callsigns(page))
+	}
+
+	if _, err := m.QueryRepeaters(RepeaterQuery{Sort: "bogus"}); err == nil {
+		t.Errorf("expected error for unknown sort key")
+	}
+}
+
+func callsigns(page RepeaterPage) string {
+	out := ""
+	for i, r := range page.Repeaters {
+		if i > 0 {
+			out += ","
+		}
+		out += r.Callsign
+	}
+	return out
+}
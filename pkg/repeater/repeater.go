@@ -3,30 +3,39 @@ package repeater
 import (
 	"fmt"
 	"net"
-	"regexp"
+	"sync"
 	"sync/atomic"
 	"time"
 )
 
-// maskIPAddress masks the last two octets of an IP address for privacy
-// Example: 192.168.1.100:42000 -> 192.168.**:42000
-func maskIPAddress(address string) string {
-	re := regexp.MustCompile(`^(\d+\.\d+\.)\d+\.\d+(:\d+)?$`)
-	return re.ReplaceAllString(address, "${1}**${2}")
-}
-
 // Repeater represents a connected YSF repeater
 type Repeater struct {
-	callsign     string
-	address      *net.UDPAddr
-	connected    time.Time
-	lastSeen     time.Time
+	callsign    string
+	connected   time.Time
+	packetCount uint64
+	bytesRx     uint64
+	bytesTx     uint64
+	isActive    bool
+	fingerprint fingerprintState
+	probe       probeState
+	geo         *Geo // Location of the address, when geo lookup is enabled
+
+	// Address and last poll, written on the packet path and when the session
+	// follows a source port change
+	mu       sync.RWMutex
+	address  *net.UDPAddr
+	lastSeen time.Time
+
+	// Talk state, written on the packet path and by the talk timeout cleanup
+	talkMu       sync.RWMutex
 	talkStart    *time.Time
-	lastTalkData *time.Time // Last time we received a data packet while talking
-	packetCount  uint64
-	bytesRx      uint64
-	bytesTx      uint64
-	isActive     bool
+	lastTalkData *time.Time     // Last time we received a data packet while talking
+	quality      *StreamQuality // Quality of the current (or last) transmission
+	origin       *OriginTracker // Origin of the current (or last) transmission
+	talker       string         // Source callsign of the current (or last) transmission
+	talkTotal    time.Duration  // Accumulated duration of completed transmissions
+	// lastMutedFrame is when a data frame was last dropped because the repeater was muted
+	lastMutedFrame time.Time
 }
 
 // NewRepeater creates a new repeater instance
@@ -48,9 +57,18 @@ func (r *Repeater) Callsign() string {
 
 // Address returns the repeater's network address
 func (r *Repeater) Address() *net.UDPAddr {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	return r.address
 }
 
+// setAddress moves the repeater to a new network address
+func (r *Repeater) setAddress(addr *net.UDPAddr) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.address = addr
+}
+
 // Connected returns when the repeater connected
 func (r *Repeater) Connected() time.Time {
 	return r.connected
@@ -58,6 +76,8 @@ func (r *Repeater) Connected() time.Time {
 
 // LastSeen returns when the repeater was last seen
 func (r *Repeater) LastSeen() time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	return r.lastSeen
 }
 
@@ -76,6 +96,24 @@ func (r *Repeater) BytesTransmitted() uint64 {
 	return atomic.LoadUint64(&r.bytesTx)
 }
 
+// Talker returns the source callsign of the current or most recent transmission,
+// falling back to the repeater callsign when unknown
+func (r *Repeater) Talker() string {
+	r.talkMu.RLock()
+	defer r.talkMu.RUnlock()
+	if r.talker != "" {
+		return r.talker
+	}
+	return r.callsign
+}
+
+// setTalker records the source callsign of the current transmission
+func (r *Repeater) setTalker(callsign string) {
+	r.talkMu.Lock()
+	defer r.talkMu.Unlock()
+	r.talker = callsign
+}
+
 // IsActive returns whether the repeater is currently active
 func (r *Repeater) IsActive() bool {
 	return r.isActive
@@ -83,11 +121,20 @@ func (r *Repeater) IsActive() bool {
 
 // IsTalking returns whether the repeater is currently transmitting
 func (r *Repeater) IsTalking() bool {
+	r.talkMu.RLock()
+	defer r.talkMu.RUnlock()
 	return r.talkStart != nil
 }
 
 // TalkDuration returns how long the repeater has been talking
 func (r *Repeater) TalkDuration() time.Duration {
+	r.talkMu.RLock()
+	defer r.talkMu.RUnlock()
+	return r.talkDurationLocked()
+}
+
+// talkDurationLocked returns the current talk duration; talkMu must be held
+func (r *Repeater) talkDurationLocked() time.Duration {
 	if r.talkStart == nil {
 		return 0
 	}
@@ -96,6 +143,8 @@ func (r *Repeater) TalkDuration() time.Duration {
 
 // UpdateLastSeen updates the last seen timestamp
 func (r *Repeater) UpdateLastSeen() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.lastSeen = time.Now()
 }
 
@@ -116,34 +165,140 @@ func (r *Repeater) AddBytesTransmitted(bytes uint64) {
 
 // StartTalking marks the repeater as starting to talk
 func (r *Repeater) StartTalking() {
+	r.talkMu.Lock()
+	defer r.talkMu.Unlock()
 	now := time.Now()
 	r.talkStart = &now
 	r.lastTalkData = &now
+	r.quality = NewStreamQuality()
+	r.origin = NewOriginTracker(SourceRepeater)
+}
+
+// markMutedFrame records a data frame dropped while the repeater was muted
+func (r *Repeater) markMutedFrame(at time.Time) {
+	r.talkMu.Lock()
+	defer r.talkMu.Unlock()
+	r.lastMutedFrame = at
+}
+
+// LastMutedFrame returns when a data frame was last dropped because the repeater was muted
+func (r *Repeater) LastMutedFrame() time.Time {
+	r.talkMu.RLock()
+	defer r.talkMu.RUnlock()
+	return r.lastMutedFrame
 }
 
 // UpdateTalkData updates the last talk data timestamp
 func (r *Repeater) UpdateTalkData() {
-	if r.IsTalking() {
+	r.talkMu.Lock()
+	defer r.talkMu.Unlock()
+	if r.talkStart != nil {
 		now := time.Now()
 		r.lastTalkData = &now
 	}
 }
 
+// RecordFrame records a received frame in the current transmission's quality tracker
+func (r *Repeater) RecordFrame(seq uint8, hasSeq bool, fichOK bool, at time.Time) {
+	r.talkMu.RLock()
+	q, talking := r.quality, r.talkStart != nil
+	r.talkMu.RUnlock()
+	if q != nil && talking {
+		q.Record(seq, hasSeq, fichOK, at)
+	}
+}
+
+// RecordOrigin records a forwarded frame in the current transmission's origin tracker
+func (r *Repeater) RecordOrigin(dgid uint8, hasDGID bool, dest string, recipients int) {
+	r.talkMu.RLock()
+	o, talking := r.origin, r.talkStart != nil
+	r.talkMu.RUnlock()
+	if o != nil && talking {
+		o.Record(dgid, hasDGID, dest, recipients)
+	}
+}
+
+// TalkOrigin returns the origin of the current or most recent transmission
+func (r *Repeater) TalkOrigin() *TalkOrigin {
+	r.talkMu.RLock()
+	o := r.origin
+	r.talkMu.RUnlock()
+	if o == nil {
+		return nil
+	}
+	origin := o.Origin()
+	return &origin
+}
+
+// TalkQuality returns the quality report for the current or most recent transmission
+func (r *Repeater) TalkQuality() *QualityReport {
+	r.talkMu.RLock()
+	q := r.quality
+	r.talkMu.RUnlock()
+	if q == nil {
+		return nil
+	}
+	report := q.Report()
+	return &report
+}
+
 // StopTalking marks the repeater as stopping to talk and returns the talk duration
 func (r *Repeater) StopTalking() time.Duration {
+	r.talkMu.Lock()
+	defer r.talkMu.Unlock()
 	if r.talkStart == nil {
 		return 0
 	}
 
 	duration := time.Since(*r.talkStart)
+	r.talkTotal += duration
 	r.talkStart = nil
 	r.lastTalkData = nil
 	return duration
 }
 
+// TotalTalkTime returns the accumulated talk time, including any current transmission
+func (r *Repeater) TotalTalkTime() time.Duration {
+	r.talkMu.RLock()
+	defer r.talkMu.RUnlock()
+	return r.talkTotal + r.talkDurationLocked()
+}
+
+// ObservePoll records client behaviour from a poll packet for fingerprinting
+func (r *Repeater) ObservePoll(data []byte, at time.Time) {
+	r.fingerprint.observePoll(data, at)
+}
+
+// ObserveStatusRequest records a status request sent from the repeater's address
+func (r *Repeater) ObserveStatusRequest() {
+	r.fingerprint.observeStatusRequest()
+}
+
+// SetGeo records the location of the repeater's address
+func (r *Repeater) SetGeo(geo *Geo) {
+	r.geo = geo
+}
+
+// Geo returns the location of the repeater's address, or nil if unknown
+func (r *Repeater) Geo() *Geo {
+	return r.geo
+}
+
+// Fingerprint returns the client fingerprint, or nil if nothing was observed yet
+func (r *Repeater) Fingerprint() *Fingerprint {
+	return r.fingerprint.snapshot()
+}
+
+// LastProbe returns the most recent latency probe result, or nil if never probed
+func (r *Repeater) LastProbe() *ProbeResult {
+	return r.probe.lastResult()
+}
+
 // IsTalkTimedOut checks if the talk session has timed out
 func (r *Repeater) IsTalkTimedOut(timeout time.Duration) bool {
-	if !r.IsTalking() || r.lastTalkData == nil {
+	r.talkMu.RLock()
+	defer r.talkMu.RUnlock()
+	if r.talkStart == nil || r.lastTalkData == nil {
 		return false
 	}
 	return time.Since(*r.lastTalkData) > timeout
@@ -156,7 +311,7 @@ func (r *Repeater) SetActive(active bool) {
 
 // IsTimedOut checks if the repeater has timed out
 func (r *Repeater) IsTimedOut(timeout time.Duration) bool {
-	return time.Since(r.lastSeen) > timeout
+	return time.Since(r.LastSeen()) > timeout
 }
 
 // Uptime returns how long the repeater has been connected
@@ -166,18 +321,24 @@ func (r *Repeater) Uptime() time.Duration {
 
 // Stats returns a snapshot of repeater statistics
 func (r *Repeater) Stats() RepeaterStats {
+	address := r.Address().String()
 	return RepeaterStats{
 		Callsign:         r.callsign,
-		Address:          maskIPAddress(r.address.String()),
+		Address:          maskIPAddress(address),
+		rawAddress:       address,
 		Connected:        r.connected,
-		LastSeen:         r.lastSeen,
+		LastSeen:         r.LastSeen(),
 		PacketCount:      r.PacketCount(),
 		BytesReceived:    r.BytesReceived(),
 		BytesTransmitted: r.BytesTransmitted(),
 		IsActive:         r.isActive,
 		IsTalking:        r.IsTalking(),
 		TalkDuration:     int(r.TalkDuration().Seconds()),
+		TotalTalkTime:    int(r.TotalTalkTime().Seconds()),
 		Uptime:           int(r.Uptime().Seconds()),
+		Fingerprint:      r.Fingerprint(),
+		LastProbe:        r.LastProbe(),
+		Geo:              r.geo,
 	}
 }
 
@@ -192,8 +353,27 @@ type RepeaterStats struct {
 	BytesTransmitted uint64    `json:"bytes_transmitted"`
 	IsActive         bool      `json:"is_active"`
 	IsTalking        bool      `json:"is_talking"`
-	TalkDuration     int       `json:"talk_duration"` // in seconds
-	Uptime           int       `json:"uptime"`        // in seconds
+	TalkDuration     int       `json:"talk_duration"`   // in seconds
+	TotalTalkTime    int       `json:"total_talk_time"` // in seconds
+	Uptime           int       `json:"uptime"`          // in seconds
+
+	// Fingerprint hints at the client software, when anything was observed
+	Fingerprint *Fingerprint `json:"fingerprint,omitempty"`
+
+	// LastProbe is the most recent on-demand latency probe
+	LastProbe *ProbeResult `json:"last_probe,omitempty"`
+
+	// Geo is the country and network of the address, when geo lookup is enabled
+	Geo *Geo `json:"geo,omitempty"`
+
+	// Groups are the repeater groups the gateway callsign belongs to
+	Groups []string `json:"groups,omitempty"`
+
+	// TalkLimit is the talk limit override of the gateway callsign, if any
+	TalkLimit *TalkLimit `json:"talk_limit,omitempty"`
+
+	// rawAddress is the unmasked address, for Masked
+	rawAddress string
 }
 
 // String returns a string representation of the repeater
@@ -207,5 +387,5 @@ func (r *Repeater) String() string {
 	}
 
 	return fmt.Sprintf("Repeater{Callsign: %s, Address: %s, Status: %s, Uptime: %v}",
-		r.callsign, r.address.String(), status, r.Uptime())
+		r.callsign, r.Address().String(), status, r.Uptime())
 }
@@ -0,0 +1,56 @@
+package repeater
+
+import (
+	"net"
+	"sync/atomic"
+)
+
+// The roster is a read-mostly snapshot of the connected repeaters. Every data
+// frame is broadcast to all repeaters, so with hundreds connected walking the
+// sync.Map per frame dominates; the snapshot is rebuilt only after a repeater
+// connects or disconnects (or groups change) and is shared by all readers
+// until then.
+
+// roster holds the current snapshot. A generation counter is bumped on every
+// change; a snapshot built for an older generation is stale.
+type roster struct {
+	gen  atomic.Uint64
+	snap atomic.Pointer[rosterSnapshot]
+}
+
+// rosterSnapshot is an immutable view of the connected repeaters
+type rosterSnapshot struct {
+	gen       uint64
+	repeaters []*Repeater
+	addresses []*net.UDPAddr
+	bridged   []*net.UDPAddr // Addresses outside no-bridge groups
+}
+
+// invalidateRoster marks the snapshot stale. It must be called after the
+// change it reports is visible in m.repeaters.
+func (m *Manager) invalidateRoster() {
+	m.roster.gen.Add(1)
+}
+
+// currentRoster returns a snapshot of the connected repeaters, rebuilding it
+// if it is stale
+func (m *Manager) currentRoster() *rosterSnapshot {
+	gen := m.roster.gen.Load()
+	if snap := m.roster.snap.Load(); snap != nil && snap.gen == gen {
+		return snap
+	}
+
+	snap := &rosterSnapshot{gen: gen}
+	m.repeaters.Range(func(key, value interface{}) bool {
+		if r, ok := value.(*Repeater); ok {
+			snap.repeaters = append(snap.repeaters, r)
+			snap.addresses = append(snap.addresses, r.Address())
+			if !m.IsNoBridge(r.Callsign()) {
+				snap.bridged = append(snap.bridged, r.Address())
+			}
+		}
+		return true
+	})
+	m.roster.snap.Store(snap)
+	return snap
+}
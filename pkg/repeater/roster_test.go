@@ -0,0 +1,108 @@
+package repeater
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+func TestRosterFollowsChanges(t *testing.T) {
+	m := NewManager(5*time.Second, 10, nil, 180*time.Second, 0)
+	a := mustAddr(t, "127.0.0.1:40001")
+	b := mustAddr(t, "127.0.0.1:40002")
+
+	m.AddRepeater("W1AW", a)
+	if got := m.GetAllAddresses(); len(got) != 1 || got[0].String() != a.String() {
+		t.Fatalf("unexpected addresses %v", got)
+	}
+	m.AddRepeater("K1ABC", b)
+	if m.Count() != 2 || len(m.GetAllAddresses()) != 2 || len(m.BridgedAddresses()) != 2 {
+		t.Fatalf("expected two repeaters, got %v", m.GetAllAddresses())
+	}
+
+	// A no-bridge group takes its members off the bridged list
+	if err := m.PutGroup(Group{Name: "local", Callsigns: []string{"K1ABC"}, NoBridge: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.BridgedAddresses(); len(got) != 1 || got[0].String() != a.String() {
+		t.Errorf("unexpected bridged addresses %v", got)
+	}
+	m.DeleteGroup("local")
+	if got := m.BridgedAddresses(); len(got) != 2 {
+		t.Errorf("expected both repeaters bridged again, got %v", got)
+	}
+
+	m.RemoveRepeater(a, DisconnectUnlink)
+	if got := m.GetAllAddresses(); len(got) != 1 || got[0].String() != b.String() {
+		t.Errorf("unexpected addresses after removal %v", got)
+	}
+	if stats := m.GetStats(); stats.ActiveRepeaters != 1 {
+		t.Errorf("expected one active repeater, got %d", stats.ActiveRepeaters)
+	}
+}
+
+// benchManager returns a manager with n connected repeaters
+func benchManager(b *testing.B, n int) *Manager {
+	b.Helper()
+	m := NewManagerWithLogger(5*time.Second, n, nil, 180*time.Second, 0, logger.NewTestLogger(io.Discard))
+	for i := 0; i < n; i++ {
+		addr := &net.UDPAddr{IP: net.IPv4(10, 0, byte(i>>8), byte(i)), Port: 42000}
+		if _, ok := m.AddRepeater(fmt.Sprintf("N%dABC", i), addr); !ok {
+			b.Fatalf("failed to add repeater %d", i)
+		}
+	}
+	return m
+}
+
+// BenchmarkGetAllAddresses is the per-frame broadcast lookup with 500
+// repeaters connected
+func BenchmarkGetAllAddresses(b *testing.B) {
+	m := benchManager(b, 500)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if len(m.GetAllAddresses()) != 500 {
+			b.Fatal("wrong address count")
+		}
+	}
+}
+
+// BenchmarkGetAllAddressesUncached rebuilds the roster on every call, the
+// cost of walking the repeater map per frame
+func BenchmarkGetAllAddressesUncached(b *testing.B) {
+	m := benchManager(b, 500)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.invalidateRoster()
+		if len(m.GetAllAddresses()) != 500 {
+			b.Fatal("wrong address count")
+		}
+	}
+}
+
+// BenchmarkGetAllAddressesParallel reads the roster from many goroutines, as
+// concurrent streams and dashboard polls do
+func BenchmarkGetAllAddressesParallel(b *testing.B) {
+	m := benchManager(b, 500)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = m.GetAllAddresses()
+		}
+	})
+}
+
+func BenchmarkGetStats(b *testing.B) {
+	m := benchManager(b, 500)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = m.GetStats()
+	}
+}
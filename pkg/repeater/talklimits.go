@@ -0,0 +1,114 @@
+package repeater
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// TalkLimit overrides the global talk limits for one callsign, e.g. a club
+// news broadcast that runs longer than talk_max_duration. The callsign is
+// matched against the talker first and then the gateway.
+type TalkLimit struct {
+	Callsign string `json:"callsign"`
+	// TalkMaxDuration replaces the maximum continuous transmission; nil keeps the global value
+	TalkMaxDuration *time.Duration `json:"talk_max_duration,omitempty"`
+	// UnmuteAfter replaces how long a station muted for talking too long
+	// stays muted (0 = until it stops); nil keeps the global value
+	UnmuteAfter *time.Duration `json:"unmute_after,omitempty"`
+}
+
+// talkLimitList holds the per-callsign talk limit overrides
+type talkLimitList struct {
+	mu     sync.RWMutex
+	limits map[string]TalkLimit
+}
+
+// SetTalkLimits replaces the talk limit overrides
+func (m *Manager) SetTalkLimits(limits []TalkLimit) {
+	m.talkLimits.mu.Lock()
+	defer m.talkLimits.mu.Unlock()
+
+	m.talkLimits.limits = make(map[string]TalkLimit, len(limits))
+	for _, limit := range limits {
+		if key := normalizeCallsign(limit.Callsign); key != "" {
+			limit.Callsign = key
+			m.talkLimits.limits[key] = limit
+		}
+	}
+}
+
+// PutTalkLimit adds or replaces the talk limit override of a callsign
+func (m *Manager) PutTalkLimit(limit TalkLimit) {
+	key := normalizeCallsign(limit.Callsign)
+	if key == "" {
+		return
+	}
+	limit.Callsign = key
+
+	m.talkLimits.mu.Lock()
+	defer m.talkLimits.mu.Unlock()
+	if m.talkLimits.limits == nil {
+		m.talkLimits.limits = make(map[string]TalkLimit)
+	}
+	m.talkLimits.limits[key] = limit
+}
+
+// RemoveTalkLimit drops the override of a callsign. It reports whether there was one.
+func (m *Manager) RemoveTalkLimit(callsign string) bool {
+	key := normalizeCallsign(callsign)
+
+	m.talkLimits.mu.Lock()
+	defer m.talkLimits.mu.Unlock()
+	if _, ok := m.talkLimits.limits[key]; !ok {
+		return false
+	}
+	delete(m.talkLimits.limits, key)
+	return true
+}
+
+// GetTalkLimits returns the talk limit overrides ordered by callsign
+func (m *Manager) GetTalkLimits() []TalkLimit {
+	m.talkLimits.mu.RLock()
+	defer m.talkLimits.mu.RUnlock()
+
+	limits := make([]TalkLimit, 0, len(m.talkLimits.limits))
+	for _, limit := range m.talkLimits.limits {
+		limits = append(limits, limit)
+	}
+	sort.Slice(limits, func(i, j int) bool { return limits[i].Callsign < limits[j].Callsign })
+	return limits
+}
+
+// TalkLimitOf returns the override of a callsign, if any
+func (m *Manager) TalkLimitOf(callsign string) *TalkLimit {
+	m.talkLimits.mu.RLock()
+	defer m.talkLimits.mu.RUnlock()
+
+	if limit, ok := m.talkLimits.limits[normalizeCallsign(callsign)]; ok {
+		return &limit
+	}
+	return nil
+}
+
+// talkLimitsFor returns the maximum talk duration and unmute delay for a
+// talker on a gateway: the talker's override, then the gateway's, then the
+// global limits
+func (m *Manager) talkLimitsFor(talker, gateway string) (maxDuration, unmuteAfter time.Duration) {
+	maxDuration, unmuteAfter = m.talkMaxDuration, m.unmuteAfter
+
+	limit := m.TalkLimitOf(talker)
+	if limit == nil {
+		limit = m.TalkLimitOf(gateway)
+	}
+	if limit == nil {
+		return maxDuration, unmuteAfter
+	}
+	if limit.TalkMaxDuration != nil {
+		maxDuration = *limit.TalkMaxDuration
+	}
+	if limit.UnmuteAfter != nil {
+		unmuteAfter = *limit.UnmuteAfter
+	}
+	return maxDuration, unmuteAfter
+}
@@ -0,0 +1,48 @@
+package repeater
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTalkLimitOverride(t *testing.T) {
+	events := make(chan Event, 10)
+	m := NewManager(5*time.Second, 10, events, 100*time.Millisecond, 0)
+	long := time.Hour
+	unmute := time.Minute
+	m.SetTalkLimits([]TalkLimit{{Callsign: "w1news", TalkMaxDuration: &long}})
+	m.PutTalkLimit(TalkLimit{Callsign: "GATEWAY", UnmuteAfter: &unmute})
+
+	news := mustAddr(t, "127.0.0.1:41101")
+	other := mustAddr(t, "127.0.0.1:41102")
+	m.AddRepeater("CLUB", news)
+	m.AddRepeater("GATEWAY", other)
+
+	// The talker's override keeps the news broadcast going
+	m.ProcessPacket("W1NEWS", news, "YSFD", 100)
+	time.Sleep(150 * time.Millisecond)
+	m.ProcessPacket("W1NEWS", news, "YSFD", 100)
+	if m.IsMuted(news) {
+		t.Fatal("override talk_max_duration must keep W1NEWS talking")
+	}
+	m.ClearActive()
+
+	// The gateway's override only changes unmute_after; the global maximum applies
+	m.ProcessPacket("K2XX", other, "YSFD", 100)
+	time.Sleep(150 * time.Millisecond)
+	m.ProcessPacket("K2XX", other, "YSFD", 100)
+	if !m.IsMuted(other) {
+		t.Fatal("expected K2XX to be muted after the global talk_max_duration")
+	}
+	v, _ := m.muted.Load(other.String())
+	if until := v.(time.Time); until.IsZero() {
+		t.Error("expected the gateway's unmute_after to set an unmute time")
+	}
+
+	if limits := m.GetTalkLimits(); len(limits) != 2 || limits[0].Callsign != "GATEWAY" || limits[1].Callsign != "W1NEWS" {
+		t.Errorf("unexpected overrides %+v", limits)
+	}
+	if !m.RemoveTalkLimit("w1news") || m.TalkLimitOf("W1NEWS") != nil {
+		t.Error("expected the W1NEWS override to be removed")
+	}
+}
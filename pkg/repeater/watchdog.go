@@ -0,0 +1,256 @@
+package repeater
+
+import (
+	"strings"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// The stream watchdog audits talker, mute and channel state once a second and
+// repairs combinations the packet path should never leave behind, such as a
+// channel held by a repeater that is no longer talking. An inconsistency must
+// be seen on two consecutive audits before it is repaired, so the short windows
+// in which the packet path updates talk state and activeKey separately are
+// never reported. Every repair is logged and emitted as a stream_anomaly event.
+
+// Stream anomaly kinds
+const (
+	// AnomalyOrphanedChannel: the channel is held by a repeater or bridge stream that no longer exists
+	AnomalyOrphanedChannel = "orphaned_channel"
+	// AnomalyIdleChannel: the channel is held by a repeater that is not talking
+	AnomalyIdleChannel = "idle_channel"
+	// AnomalyMutedHolder: the channel is held by a muted repeater
+	AnomalyMutedHolder = "muted_holder"
+	// AnomalyStrayTalker: a repeater is talking without holding the channel
+	AnomalyStrayTalker = "stray_talker"
+	// AnomalyStaleMute: a mute is recorded for a repeater that has disconnected
+	AnomalyStaleMute = "stale_mute"
+	// AnomalyStuckMute: a mute lasting until the stream stops outlived the stream
+	AnomalyStuckMute = "stuck_mute"
+)
+
+// talkIdleTimeout is how long a stream may go without data before it has ended
+const talkIdleTimeout = 3 * time.Second
+
+// streamWatchdog holds the watchdog state between audits. It is only used
+// from the cleanup loop.
+type streamWatchdog struct {
+	// suspects are the inconsistencies seen on the previous audit
+	suspects map[string]bool
+}
+
+// streamAnomaly describes a repaired inconsistency
+type streamAnomaly struct {
+	kind     string
+	callsign string
+	address  string
+	repair   string
+}
+
+// auditStreams checks talker, mute and channel state and repairs
+// inconsistencies that persisted since the previous audit
+func (m *Manager) auditStreams(now time.Time) {
+	seen := make(map[string]bool)
+	var repaired []streamAnomaly
+
+	// confirm records an inconsistency and reports whether it should be repaired now
+	confirm := func(kind, address string) bool {
+		id := kind + " " + address
+		if m.watchdog.suspects[id] {
+			return true
+		}
+		seen[id] = true
+		return false
+	}
+
+	if a, ok := m.auditChannel(confirm); ok {
+		repaired = append(repaired, a)
+	}
+	repaired = append(repaired, m.auditTalkers(confirm)...)
+	repaired = append(repaired, m.auditMutes(now, confirm)...)
+
+	m.watchdog.suspects = seen
+
+	for _, a := range repaired {
+		m.reportAnomaly(a, now)
+	}
+}
+
+// auditChannel checks that the channel holder exists, is talking and is not muted
+func (m *Manager) auditChannel(confirm func(kind, address string) bool) (streamAnomaly, bool) {
+	m.activeMu.Lock()
+	key := m.activeKey
+	if key == "" {
+		m.activeMu.Unlock()
+		return streamAnomaly{}, false
+	}
+
+	// Bridge and reflector streams are tracked in activeBridge
+	if m.activeBridge != nil && m.activeBridge.key == key {
+		m.activeMu.Unlock()
+		return streamAnomaly{}, false
+	}
+
+	if strings.HasPrefix(key, bridgeKeyPrefix) {
+		defer m.activeMu.Unlock()
+		address := strings.TrimPrefix(key, bridgeKeyPrefix)
+		if !confirm(AnomalyOrphanedChannel, address) {
+			return streamAnomaly{}, false
+		}
+		m.activeKey = ""
+		return streamAnomaly{
+			kind:    AnomalyOrphanedChannel,
+			address: address,
+			repair:  "released channel held by a bridge stream that no longer exists",
+		}, true
+	}
+
+	v, ok := m.repeaters.Load(key)
+	if !ok {
+		defer m.activeMu.Unlock()
+		if !confirm(AnomalyOrphanedChannel, key) {
+			return streamAnomaly{}, false
+		}
+		m.activeKey = ""
+		return streamAnomaly{
+			kind:    AnomalyOrphanedChannel,
+			address: key,
+			repair:  "released channel held by a disconnected repeater",
+		}, true
+	}
+
+	repeater := v.(*Repeater)
+	if !repeater.IsTalking() {
+		defer m.activeMu.Unlock()
+		if !confirm(AnomalyIdleChannel, key) {
+			return streamAnomaly{}, false
+		}
+		m.activeKey = ""
+		return streamAnomaly{
+			kind:     AnomalyIdleChannel,
+			callsign: repeater.Callsign(),
+			address:  key,
+			repair:   "released channel held by a repeater that is not talking",
+		}, true
+	}
+	m.activeMu.Unlock()
+
+	if !m.IsMuted(repeater.Address()) || !confirm(AnomalyMutedHolder, key) {
+		return streamAnomaly{}, false
+	}
+
+	m.activeMu.Lock()
+	if m.activeKey != key {
+		m.activeMu.Unlock()
+		return streamAnomaly{}, false
+	}
+	duration := repeater.StopTalking()
+	m.activeKey = ""
+	m.activeMu.Unlock()
+	m.sendTalkEnd(repeater, key, duration, "")
+
+	return streamAnomaly{
+		kind:     AnomalyMutedHolder,
+		callsign: repeater.Callsign(),
+		address:  key,
+		repair:   "ended transmission of a muted repeater and released the channel",
+	}, true
+}
+
+// auditTalkers ends transmissions of repeaters that are talking without holding the channel
+func (m *Manager) auditTalkers(confirm func(kind, address string) bool) []streamAnomaly {
+	var repaired []streamAnomaly
+
+	m.repeaters.Range(func(key, value interface{}) bool {
+		repeater := value.(*Repeater)
+		address := key.(string)
+
+		m.activeMu.Lock()
+		if !repeater.IsTalking() || m.activeKey == address || !confirm(AnomalyStrayTalker, address) {
+			m.activeMu.Unlock()
+			return true
+		}
+		duration := repeater.StopTalking()
+		m.activeMu.Unlock()
+
+		m.sendTalkEnd(repeater, address, duration, "")
+		repaired = append(repaired, streamAnomaly{
+			kind:     AnomalyStrayTalker,
+			callsign: repeater.Callsign(),
+			address:  address,
+			repair:   "ended transmission of a repeater that did not hold the channel",
+		})
+		return true
+	})
+
+	return repaired
+}
+
+// auditMutes removes mutes that can no longer be lifted by the packet path
+func (m *Manager) auditMutes(now time.Time, confirm func(kind, address string) bool) []streamAnomaly {
+	var repaired []streamAnomaly
+
+	m.muted.Range(func(key, value interface{}) bool {
+		address := key.(string)
+
+		v, ok := m.repeaters.Load(address)
+		if !ok {
+			if confirm(AnomalyStaleMute, address) {
+				m.muted.Delete(address)
+				repaired = append(repaired, streamAnomaly{
+					kind:    AnomalyStaleMute,
+					address: address,
+					repair:  "removed mute of a disconnected repeater",
+				})
+			}
+			return true
+		}
+
+		// A mute until the stream stops is normally lifted by the talk timeout,
+		// which never fires once the muted stream is no longer talking
+		repeater := v.(*Repeater)
+		if until, ok := value.(time.Time); !ok || !until.IsZero() || repeater.IsTalking() {
+			return true
+		}
+		if now.Sub(repeater.LastMutedFrame()) <= talkIdleTimeout || !confirm(AnomalyStuckMute, address) {
+			return true
+		}
+		m.muted.Delete(address)
+		repaired = append(repaired, streamAnomaly{
+			kind:     AnomalyStuckMute,
+			callsign: repeater.Callsign(),
+			address:  address,
+			repair:   "lifted mute of a stream that has stopped",
+		})
+		return true
+	})
+
+	return repaired
+}
+
+// reportAnomaly logs a repaired inconsistency and emits a stream_anomaly event
+func (m *Manager) reportAnomaly(a streamAnomaly, now time.Time) {
+	m.mu.Lock()
+	m.metrics.StreamAnomalies++
+	m.mu.Unlock()
+
+	if m.logger != nil {
+		m.logger.Warn("Stream watchdog repaired inconsistent state",
+			logger.String("anomaly", a.kind),
+			logger.String("callsign", a.callsign),
+			logger.String("address", a.address),
+			logger.String("repair", a.repair))
+	}
+
+	m.emit(Event{
+		Type:      EventStreamAnomaly,
+		Callsign:  a.callsign,
+		Address:   a.address,
+		Timestamp: now,
+		Data: map[string]interface{}{
+			"anomaly": a.kind,
+			"repair":  a.repair,
+		},
+	})
+}
@@ -0,0 +1,156 @@
+package repeater
+
+import (
+	"testing"
+	"time"
+)
+
+// anomalies drains the event channel and returns the stream_anomaly kinds
+func anomalies(events chan Event) []string {
+	var kinds []string
+	for {
+		select {
+		case ev := <-events:
+			if ev.Type == EventStreamAnomaly {
+				kinds = append(kinds, ev.Data["anomaly"].(string))
+			}
+		default:
+			return kinds
+		}
+	}
+}
+
+func TestWatchdogReleasesOrphanedChannel(t *testing.T) {
+	events := make(chan Event, 20)
+	m := NewManager(5*time.Second, 10, events, 180*time.Second, 0)
+
+	m.activeMu.Lock()
+	m.activeKey = "127.0.0.1:45030"
+	m.activeMu.Unlock()
+
+	// The first audit only records the inconsistency
+	now := time.Now()
+	m.auditStreams(now)
+	if got := anomalies(events); len(got) != 0 {
+		t.Fatalf("expected no repair on first sighting, got %v", got)
+	}
+
+	m.auditStreams(now.Add(time.Second))
+	if got := anomalies(events); len(got) != 1 || got[0] != AnomalyOrphanedChannel {
+		t.Fatalf("expected orphaned_channel anomaly, got %v", got)
+	}
+	if m.activeKey != "" {
+		t.Errorf("expected channel to be released")
+	}
+	if got := m.GetStats().StreamAnomalies; got != 1 {
+		t.Errorf("expected 1 counted anomaly, got %d", got)
+	}
+}
+
+func TestWatchdogIgnoresTransientInconsistency(t *testing.T) {
+	events := make(chan Event, 20)
+	m := NewManager(5*time.Second, 10, events, 180*time.Second, 0)
+	addr := mustAddr(t, "127.0.0.1:45031")
+	m.AddRepeater("W1AW", addr)
+	m.ProcessPacket("W1AW", addr, "YSFD", 155)
+
+	// Talking without the channel, but fixed before the next audit
+	m.ClearActive()
+	now := time.Now()
+	m.auditStreams(now)
+	m.ProcessPacket("W1AW", addr, "YSFD", 155)
+	m.auditStreams(now.Add(time.Second))
+
+	if got := anomalies(events); len(got) != 0 {
+		t.Errorf("expected no anomaly for a transient inconsistency, got %v", got)
+	}
+	if !m.HoldsChannel(addr) || !m.GetRepeater(addr).IsTalking() {
+		t.Errorf("expected repeater to keep talking")
+	}
+}
+
+func TestWatchdogEndsStrayTalker(t *testing.T) {
+	events := make(chan Event, 20)
+	m := NewManager(5*time.Second, 10, events, 180*time.Second, 0)
+	addr := mustAddr(t, "127.0.0.1:45032")
+	m.AddRepeater("W1AW", addr)
+	m.ProcessPacket("W1AW", addr, "YSFD", 155)
+	m.ClearActive()
+	anomalies(events)
+
+	now := time.Now()
+	m.auditStreams(now)
+	m.auditStreams(now.Add(time.Second))
+
+	if m.GetRepeater(addr).IsTalking() {
+		t.Errorf("expected stray transmission to be ended")
+	}
+	var talkEnd, anomaly bool
+	for len(events) > 0 {
+		ev := <-events
+		talkEnd = talkEnd || ev.Type == EventTalkEnd
+		anomaly = anomaly || (ev.Type == EventStreamAnomaly && ev.Data["anomaly"] == AnomalyStrayTalker)
+	}
+	if !talkEnd || !anomaly {
+		t.Errorf("expected talk_end and stray_talker events, got talk_end=%v anomaly=%v", talkEnd, anomaly)
+	}
+}
+
+func TestWatchdogLiftsStuckAndStaleMutes(t *testing.T) {
+	events := make(chan Event, 20)
+	m := NewManager(5*time.Second, 10, events, 180*time.Second, 0)
+	addr := mustAddr(t, "127.0.0.1:45033")
+	m.AddRepeater("W1AW", addr)
+
+	// Muted until the stream stops, with the muted stream still sending
+	m.muted.Store(addr.String(), time.Time{})
+	m.ProcessPacket("W1AW", addr, "YSFD", 155)
+	// Mute left behind by a repeater that is gone
+	m.muted.Store("127.0.0.1:45034", time.Time{})
+
+	now := time.Now()
+	m.auditStreams(now)
+	m.auditStreams(now.Add(time.Second))
+	if got := anomalies(events); len(got) != 1 || got[0] != AnomalyStaleMute {
+		t.Fatalf("expected only stale_mute while the muted stream is live, got %v", got)
+	}
+	if !m.IsMuted(addr) {
+		t.Fatalf("mute must hold while the muted stream is still sending")
+	}
+
+	// The muted stream stops
+	m.auditStreams(now.Add(5 * time.Second))
+	m.auditStreams(now.Add(6 * time.Second))
+	if got := anomalies(events); len(got) != 1 || got[0] != AnomalyStuckMute {
+		t.Fatalf("expected stuck_mute anomaly, got %v", got)
+	}
+	if m.IsMuted(addr) {
+		t.Errorf("expected mute to be lifted once the stream stopped")
+	}
+}
+
+// TestWatchdogAuditsMutesDuringMutedFrames audits mutes while a muted stream
+// keeps sending; run with -race to check the muted frame time is read safely.
+func TestWatchdogAuditsMutesDuringMutedFrames(t *testing.T) {
+	m := NewManager(5*time.Second, 10, nil, 180*time.Second, 0)
+	addr := mustAddr(t, "127.0.0.1:45035")
+	m.AddRepeater("W1AW", addr)
+	m.muted.Store(addr.String(), time.Time{})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			m.ProcessPacket("W1AW", addr, "YSFD", 155)
+		}
+	}()
+	never := func(kind, address string) bool { return false }
+	for i := 0; i < 200; i++ {
+		m.auditMutes(time.Now(), never)
+	}
+	<-done
+
+	if !m.IsMuted(addr) {
+		t.Error("expected the mute to hold while the muted stream is sending")
+	}
+}
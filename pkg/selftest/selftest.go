@@ -0,0 +1,317 @@
+// Package selftest runs a scripted scenario against an in-process reflector
+// bound to ephemeral loopback ports. Two simulated repeaters and a mock remote
+// reflector, reached through a loopback bridge, exercise polls, status
+// requests, data streams in both bridge directions and unlinks.
+package selftest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/network"
+	"github.com/dbehnke/ysf-nexus/pkg/reflector"
+)
+
+const (
+	reflectorName = "SELFTEST"
+	bridgeName    = "selftest-loopback"
+	// streamFrames is the number of data packets in a scripted transmission
+	streamFrames = 5
+	// replyTimeout bounds how long a check waits for the reflector
+	replyTimeout = 2 * time.Second
+)
+
+// Check is the outcome of one step of the scenario
+type Check struct {
+	Name     string        `json:"name"`
+	Passed   bool          `json:"passed"`
+	Detail   string        `json:"detail,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Report is the outcome of a self-test run
+type Report struct {
+	Checks []Check `json:"checks"`
+}
+
+// Failed returns the number of failed checks
+func (r Report) Failed() int {
+	failed := 0
+	for _, c := range r.Checks {
+		if !c.Passed {
+			failed++
+		}
+	}
+	return failed
+}
+
+// harness holds the sockets and reflector used by a run
+type harness struct {
+	refl      *reflector.Reflector
+	target    *net.UDPAddr
+	remote    *net.UDPConn // mock remote reflector the loopback bridge connects to
+	repeaters [2]*net.UDPConn
+}
+
+// Run starts a reflector, runs the scenario against it and stops it again.
+// Every check runs even if an earlier one failed; the error is only set when
+// the scenario could not be set up.
+func Run(ctx context.Context, log *logger.Logger) (Report, error) {
+	h, err := newHarness(log)
+	if err != nil {
+		return Report{}, err
+	}
+	defer h.close()
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() { done <- h.refl.Start(runCtx) }()
+	defer func() {
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			log.Warn("Self-test reflector did not stop in time")
+		}
+	}()
+
+	var report Report
+	steps := []struct {
+		name string
+		fn   func() error
+	}{
+		{"poll", func() error { return h.poll(0, "SELFTST1") }},
+		{"status request", h.status},
+		{"second repeater", func() error { return h.poll(1, "SELFTST2") }},
+		{"bridge handshake", h.bridgeHandshake},
+		{"bridge to local stream", h.bridgeToLocal},
+		{"local stream and bridge forward", h.localStream},
+		{"unlink", h.unlink},
+	}
+	for _, step := range steps {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		start := time.Now()
+		check := Check{Name: step.name, Passed: true}
+		if err := step.fn(); err != nil {
+			check.Passed = false
+			check.Detail = err.Error()
+		}
+		check.Duration = time.Since(start)
+		report.Checks = append(report.Checks, check)
+	}
+
+	return report, nil
+}
+
+// newHarness binds the client sockets and creates a reflector on a free port
+func newHarness(log *logger.Logger) (*harness, error) {
+	port, err := freeUDPPort()
+	if err != nil {
+		return nil, fmt.Errorf("reserve reflector port: %w", err)
+	}
+
+	h := &harness{target: &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: port}}
+	if h.remote, err = listenLoopback(); err != nil {
+		return nil, fmt.Errorf("bind mock remote reflector: %w", err)
+	}
+	for i := range h.repeaters {
+		if h.repeaters[i], err = listenLoopback(); err != nil {
+			h.close()
+			return nil, fmt.Errorf("bind simulated repeater: %w", err)
+		}
+	}
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:              "127.0.0.1",
+			Port:              port,
+			Timeout:           time.Minute,
+			MaxConnections:    10,
+			Name:              reflectorName,
+			Description:       "Self-test",
+			TalkMaxDuration:   time.Minute,
+			BridgeTalkTimeout: 3 * time.Second,
+		},
+		Bridges: []config.BridgeConfig{{
+			Name:       bridgeName,
+			Host:       "127.0.0.1",
+			Port:       h.remote.LocalAddr().(*net.UDPAddr).Port,
+			Enabled:    true,
+			Permanent:  true,
+			RetryDelay: time.Second,
+		}},
+	}
+	h.refl = reflector.New(cfg, log)
+	return h, nil
+}
+
+// close releases the harness sockets
+func (h *harness) close() {
+	for _, conn := range append([]*net.UDPConn{h.remote}, h.repeaters[:]...) {
+		if conn != nil {
+			_ = conn.Close()
+		}
+	}
+}
+
+// poll registers a simulated repeater and waits for the poll reply. The
+// reflector may still be starting, so the poll is retried until it answers.
+func (h *harness) poll(i int, callsign string) error {
+	conn := h.repeaters[i]
+	deadline := time.Now().Add(replyTimeout)
+	for time.Now().Before(deadline) {
+		if _, err := conn.WriteToUDP(network.CreatePollPacket(network.PacketTypePoll, callsign), h.target); err != nil {
+			return fmt.Errorf("send poll: %w", err)
+		}
+		if _, err := readPacket(conn, 200*time.Millisecond, func(data []byte) bool {
+			return len(data) == network.PollPacketSize && string(data[:4]) == network.PacketTypePoll
+		}); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("no poll reply within %s", replyTimeout)
+}
+
+// status sends a minimal status request and checks the announced name
+func (h *harness) status() error {
+	conn := h.repeaters[0]
+	if _, err := conn.WriteToUDP([]byte(network.PacketTypeStatus), h.target); err != nil {
+		return fmt.Errorf("send status request: %w", err)
+	}
+	data, err := readPacket(conn, replyTimeout, func(data []byte) bool {
+		return string(data[:4]) == network.PacketTypeStatus
+	})
+	if err != nil {
+		return fmt.Errorf("no status reply: %w", err)
+	}
+	if len(data) != network.StatusPacketSize {
+		return fmt.Errorf("status reply is %d bytes, want %d", len(data), network.StatusPacketSize)
+	}
+	if name := strings.TrimSpace(string(data[9:25])); name != reflectorName {
+		return fmt.Errorf("status reply names %q, want %q", name, reflectorName)
+	}
+	return nil
+}
+
+// bridgeHandshake waits for the loopback bridge to connect to the mock remote
+func (h *harness) bridgeHandshake() error {
+	if _, err := readPacket(h.remote, replyTimeout, func(data []byte) bool {
+		return string(data[:4]) == network.PacketTypePoll
+	}); err != nil {
+		return fmt.Errorf("bridge did not connect: %w", err)
+	}
+	return nil
+}
+
+// bridgeToLocal sends a transmission from the mock remote and expects both
+// simulated repeaters to receive it
+func (h *harness) bridgeToLocal() error {
+	for _, frame := range streamPackets("REMOTE", "SELFBRG") {
+		if _, err := h.remote.WriteToUDP(frame, h.target); err != nil {
+			return fmt.Errorf("send bridge data: %w", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	for i, conn := range h.repeaters {
+		if got := countData(conn, streamFrames, replyTimeout); got != streamFrames {
+			return fmt.Errorf("repeater %d received %d of %d bridge frames", i+1, got, streamFrames)
+		}
+	}
+	return nil
+}
+
+// localStream sends a transmission from the first repeater and expects the
+// second repeater and the mock remote to receive it
+func (h *harness) localStream() error {
+	for _, frame := range streamPackets("SELFTST1", "SELFTST1") {
+		if _, err := h.repeaters[0].WriteToUDP(frame, h.target); err != nil {
+			return fmt.Errorf("send local data: %w", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if got := countData(h.repeaters[1], streamFrames, replyTimeout); got != streamFrames {
+		return fmt.Errorf("second repeater received %d of %d frames", got, streamFrames)
+	}
+	if got := countData(h.remote, streamFrames, replyTimeout); got != streamFrames {
+		return fmt.Errorf("bridge received %d of %d frames", got, streamFrames)
+	}
+	return nil
+}
+
+// unlink disconnects the second repeater and waits for it to be removed
+func (h *harness) unlink() error {
+	if _, err := h.repeaters[1].WriteToUDP(network.CreatePollPacket(network.PacketTypeUnlink, "SELFTST2"), h.target); err != nil {
+		return fmt.Errorf("send unlink: %w", err)
+	}
+	deadline := time.Now().Add(replyTimeout)
+	for time.Now().Before(deadline) {
+		if h.refl.GetStats().ActiveRepeaters == 1 {
+			return nil
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return fmt.Errorf("%d repeaters still connected after unlink, want 1", h.refl.GetStats().ActiveRepeaters)
+}
+
+// streamPackets builds a complete transmission: header, voice frames and terminator
+func streamPackets(gateway, source string) [][]byte {
+	frames := make([][]byte, streamFrames)
+	for i := range frames {
+		frames[i] = network.CreateDataPacket(gateway, source, i, i == streamFrames-1)
+	}
+	return frames
+}
+
+// readPacket reads from conn until a packet matches or timeout expires
+func readPacket(conn *net.UDPConn, timeout time.Duration, match func([]byte) bool) ([]byte, error) {
+	if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 1024)
+	for {
+		n, _, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			return nil, err
+		}
+		if n >= 4 && match(buf[:n]) {
+			return append([]byte(nil), buf[:n]...), nil
+		}
+	}
+}
+
+// countData counts data packets received on conn, up to want
+func countData(conn *net.UDPConn, want int, timeout time.Duration) int {
+	deadline := time.Now().Add(timeout)
+	got := 0
+	for got < want {
+		if _, err := readPacket(conn, time.Until(deadline), func(data []byte) bool {
+			return len(data) == network.DataPacketSize && string(data[:4]) == network.PacketTypeData
+		}); err != nil {
+			break
+		}
+		got++
+	}
+	return got
+}
+
+// listenLoopback binds a UDP socket on an ephemeral loopback port
+func listenLoopback() (*net.UDPConn, error) {
+	return net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+}
+
+// freeUDPPort returns a loopback UDP port that was free at the time of the call
+func freeUDPPort() (int, error) {
+	conn, err := listenLoopback()
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = conn.Close() }()
+	return conn.LocalAddr().(*net.UDPAddr).Port, nil
+}
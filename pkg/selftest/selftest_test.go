@@ -0,0 +1,28 @@
+package selftest
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+func TestRunPasses(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	report, err := Run(ctx, logger.NewTestLogger(io.Discard))
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(report.Checks) == 0 {
+		t.Fatalf("expected checks in the report")
+	}
+	for _, c := range report.Checks {
+		if !c.Passed {
+			t.Errorf("check %q failed: %s", c.Name, c.Detail)
+		}
+	}
+}
@@ -0,0 +1,399 @@
+// Package snmp implements a small read-only SNMP v1/v2c agent so clubs that
+// monitor infrastructure with SNMP can poll the reflector.
+//
+// The MIB is flat and rooted at a configurable base OID:
+//
+//	base.1.0        uptime (TimeTicks)
+//	base.2.0        connected repeaters (Gauge32)
+//	base.3.0        active talker callsign, empty when idle (OCTET STRING)
+//	base.4.0        packets received (Counter64)
+//	base.5.0        packets sent (Counter64)
+//	base.6.0        bytes received (Counter64)
+//	base.7.0        bytes sent (Counter64)
+//	base.8.0        configured bridges (Gauge32)
+//	base.9.1.1.N    bridge name (OCTET STRING)
+//	base.9.1.2.N    bridge state (OCTET STRING)
+//	base.9.1.3.N    bridge packets received (Counter64)
+//	base.9.1.4.N    bridge packets sent (Counter64)
+//
+// Bridge rows are numbered from 1 in name order. Counter64 objects are not
+// visible to v1 managers.
+package snmp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/gosnmp/gosnmp"
+)
+
+// maxRepetitions caps the rows returned per GETBULK varbind
+const maxRepetitions = 64
+
+// Values is a point-in-time view of the reflector served by the agent
+type Values struct {
+	Uptime       time.Duration
+	Repeaters    int
+	ActiveTalker string
+	PacketsIn    uint64
+	PacketsOut   uint64
+	BytesIn      uint64
+	BytesOut     uint64
+	Bridges      []Bridge
+}
+
+// Bridge is one row of the bridge table
+type Bridge struct {
+	Name      string
+	State     string
+	PacketsRx uint64
+	PacketsTx uint64
+}
+
+// Options configures an Agent
+type Options struct {
+	Listen    string // UDP host:port
+	Community string // v1/v2c community; requests with another community are ignored
+	BaseOID   string // MIB root, e.g. 1.3.6.1.4.1.32473.1
+}
+
+// Agent answers SNMP GET, GETNEXT and GETBULK requests from a Values source
+type Agent struct {
+	listen    string
+	community string
+	base      oid
+	source    func() Values
+	logger    *logger.Logger
+}
+
+// New creates an agent that reads its values from source on every request
+func New(opts Options, source func() Values, log *logger.Logger) (*Agent, error) {
+	base, err := parseOID(opts.BaseOID)
+	if err != nil {
+		return nil, err
+	}
+	return &Agent{
+		listen:    opts.Listen,
+		community: opts.Community,
+		base:      base,
+		source:    source,
+		logger:    log.WithComponent("snmp"),
+	}, nil
+}
+
+// Start listens for requests until ctx is cancelled
+func (a *Agent) Start(ctx context.Context) error {
+	addr, err := net.ResolveUDPAddr("udp", a.listen)
+	if err != nil {
+		return fmt.Errorf("invalid snmp listen address %q: %w", a.listen, err)
+	}
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return fmt.Errorf("snmp listen on %s: %w", a.listen, err)
+	}
+	return a.Serve(ctx, conn)
+}
+
+// Serve answers requests on a socket bound by the caller, e.g. one inherited
+// from a previous process, until ctx is cancelled. The socket is closed then.
+func (a *Agent) Serve(ctx context.Context, conn *net.UDPConn) error {
+	a.logger.Info("SNMP agent listening",
+		logger.String("address", conn.LocalAddr().String()),
+		logger.String("base_oid", a.base.String()))
+
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+
+	buf := make([]byte, 65535)
+	for {
+		n, remote, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			a.logger.Warn("SNMP read failed", logger.Error(err))
+			continue
+		}
+
+		resp, err := a.handle(buf[:n])
+		if err != nil {
+			a.logger.Debug("Ignoring SNMP request",
+				logger.String("source", remote.String()),
+				logger.Error(err))
+			continue
+		}
+		if _, err := conn.WriteToUDP(resp, remote); err != nil {
+			a.logger.Warn("SNMP reply failed", logger.String("destination", remote.String()), logger.Error(err))
+		}
+	}
+}
+
+// handle decodes a request and returns the encoded response
+func (a *Agent) handle(data []byte) (resp []byte, err error) {
+	// The decoder assumes well-formed input in places; a malformed datagram
+	// from the network must not take the agent down
+	defer func() {
+		if r := recover(); r != nil {
+			resp, err = nil, fmt.Errorf("malformed request: %v", r)
+		}
+	}()
+
+	decoder := &gosnmp.GoSNMP{}
+	req, err := decoder.SnmpDecodePacket(data)
+	if err != nil {
+		return nil, err
+	}
+	if req.Version != gosnmp.Version1 && req.Version != gosnmp.Version2c {
+		return nil, fmt.Errorf("unsupported version %v", req.Version)
+	}
+	if req.Community != a.community {
+		return nil, fmt.Errorf("wrong community")
+	}
+
+	table := a.table(a.source(), req.Version)
+	reply := &gosnmp.SnmpPacket{
+		Version:   req.Version,
+		Community: req.Community,
+		PDUType:   gosnmp.GetResponse,
+		RequestID: req.RequestID,
+	}
+
+	switch req.PDUType {
+	case gosnmp.GetRequest:
+		reply.Variables, reply.Error, reply.ErrorIndex = table.get(req)
+	case gosnmp.GetNextRequest:
+		reply.Variables, reply.Error, reply.ErrorIndex = table.getNext(req)
+	case gosnmp.GetBulkRequest:
+		if req.Version == gosnmp.Version1 {
+			return nil, fmt.Errorf("GETBULK is not valid in SNMPv1")
+		}
+		reply.Variables = table.getBulk(req)
+	default:
+		return nil, fmt.Errorf("unsupported PDU type %v", req.PDUType)
+	}
+
+	return reply.MarshalMsg()
+}
+
+// table builds the sorted MIB for values
+func (a *Agent) table(v Values, version gosnmp.SnmpVersion) mib {
+	var entries mib
+	add := func(pduType gosnmp.Asn1BER, value interface{}, arcs ...int) {
+		// v1 has no Counter64 type
+		if pduType == gosnmp.Counter64 && version == gosnmp.Version1 {
+			return
+		}
+		entries = append(entries, entry{oid: a.base.child(arcs...), pduType: pduType, value: value})
+	}
+
+	add(gosnmp.TimeTicks, uint32(v.Uptime/(10*time.Millisecond)), 1, 0)
+	add(gosnmp.Gauge32, uint32(v.Repeaters), 2, 0)
+	add(gosnmp.OctetString, v.ActiveTalker, 3, 0)
+	add(gosnmp.Counter64, v.PacketsIn, 4, 0)
+	add(gosnmp.Counter64, v.PacketsOut, 5, 0)
+	add(gosnmp.Counter64, v.BytesIn, 6, 0)
+	add(gosnmp.Counter64, v.BytesOut, 7, 0)
+	add(gosnmp.Gauge32, uint32(len(v.Bridges)), 8, 0)
+
+	bridges := append([]Bridge(nil), v.Bridges...)
+	sort.Slice(bridges, func(i, j int) bool { return bridges[i].Name < bridges[j].Name })
+	for i, b := range bridges {
+		row := i + 1
+		add(gosnmp.OctetString, b.Name, 9, 1, 1, row)
+		add(gosnmp.OctetString, b.State, 9, 1, 2, row)
+		add(gosnmp.Counter64, b.PacketsRx, 9, 1, 3, row)
+		add(gosnmp.Counter64, b.PacketsTx, 9, 1, 4, row)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].oid.compare(entries[j].oid) < 0 })
+	return entries
+}
+
+// entry is one object instance in the MIB
+type entry struct {
+	oid     oid
+	pduType gosnmp.Asn1BER
+	value   interface{}
+}
+
+// pdu returns the entry as a response varbind
+func (e entry) pdu() gosnmp.SnmpPDU {
+	return gosnmp.SnmpPDU{Name: e.oid.String(), Type: e.pduType, Value: e.value}
+}
+
+// mib is a table of entries sorted by OID
+type mib []entry
+
+// find returns the entry with exactly o
+func (m mib) find(o oid) (entry, bool) {
+	i := sort.Search(len(m), func(i int) bool { return m[i].oid.compare(o) >= 0 })
+	if i < len(m) && m[i].oid.compare(o) == 0 {
+		return m[i], true
+	}
+	return entry{}, false
+}
+
+// next returns the first entry after o
+func (m mib) next(o oid) (entry, bool) {
+	i := sort.Search(len(m), func(i int) bool { return m[i].oid.compare(o) > 0 })
+	if i < len(m) {
+		return m[i], true
+	}
+	return entry{}, false
+}
+
+// get answers a GET request
+func (m mib) get(req *gosnmp.SnmpPacket) ([]gosnmp.SnmpPDU, gosnmp.SNMPError, uint8) {
+	vars := make([]gosnmp.SnmpPDU, len(req.Variables))
+	for i, v := range req.Variables {
+		o, err := parseOID(v.Name)
+		e, ok := m.find(o)
+		if err == nil && ok {
+			vars[i] = e.pdu()
+			continue
+		}
+		if req.Version == gosnmp.Version1 {
+			return nullVars(req.Variables), gosnmp.NoSuchName, uint8(i + 1)
+		}
+		vars[i] = gosnmp.SnmpPDU{Name: v.Name, Type: gosnmp.NoSuchObject}
+	}
+	return vars, gosnmp.NoError, 0
+}
+
+// getNext answers a GETNEXT request
+func (m mib) getNext(req *gosnmp.SnmpPacket) ([]gosnmp.SnmpPDU, gosnmp.SNMPError, uint8) {
+	vars := make([]gosnmp.SnmpPDU, len(req.Variables))
+	for i, v := range req.Variables {
+		o, err := parseOID(v.Name)
+		e, ok := m.next(o)
+		if err == nil && ok {
+			vars[i] = e.pdu()
+			continue
+		}
+		if req.Version == gosnmp.Version1 {
+			return nullVars(req.Variables), gosnmp.NoSuchName, uint8(i + 1)
+		}
+		vars[i] = gosnmp.SnmpPDU{Name: v.Name, Type: gosnmp.EndOfMibView}
+	}
+	return vars, gosnmp.NoError, 0
+}
+
+// getBulk answers a GETBULK request: one GETNEXT for each non-repeater, then
+// up to MaxRepetitions GETNEXTs for each remaining varbind
+func (m mib) getBulk(req *gosnmp.SnmpPacket) []gosnmp.SnmpPDU {
+	nonRepeaters := int(req.NonRepeaters)
+	if nonRepeaters > len(req.Variables) {
+		nonRepeaters = len(req.Variables)
+	}
+	repetitions := int(req.MaxRepetitions)
+	if repetitions > maxRepetitions {
+		repetitions = maxRepetitions
+	}
+
+	next := func(name string) gosnmp.SnmpPDU {
+		o, err := parseOID(name)
+		if e, ok := m.next(o); err == nil && ok {
+			return e.pdu()
+		}
+		return gosnmp.SnmpPDU{Name: name, Type: gosnmp.EndOfMibView}
+	}
+
+	var vars []gosnmp.SnmpPDU
+	for _, v := range req.Variables[:nonRepeaters] {
+		vars = append(vars, next(v.Name))
+	}
+
+	repeaters := req.Variables[nonRepeaters:]
+	cursors := make([]string, len(repeaters))
+	for i, v := range repeaters {
+		cursors[i] = v.Name
+	}
+	for r := 0; r < repetitions && len(cursors) > 0; r++ {
+		done := true
+		for i, name := range cursors {
+			pdu := next(name)
+			vars = append(vars, pdu)
+			cursors[i] = pdu.Name
+			if pdu.Type != gosnmp.EndOfMibView {
+				done = false
+			}
+		}
+		if done {
+			break
+		}
+	}
+	return vars
+}
+
+// nullVars echoes the request varbinds with null values, as v1 error
+// responses require
+func nullVars(vars []gosnmp.SnmpPDU) []gosnmp.SnmpPDU {
+	out := make([]gosnmp.SnmpPDU, len(vars))
+	for i, v := range vars {
+		out[i] = gosnmp.SnmpPDU{Name: v.Name, Type: gosnmp.Null}
+	}
+	return out
+}
+
+// oid is a parsed object identifier
+type oid []uint32
+
+// parseOID parses a dotted OID, with or without a leading dot
+func parseOID(s string) (oid, error) {
+	s = strings.TrimPrefix(s, ".")
+	if s == "" {
+		return nil, fmt.Errorf("empty OID")
+	}
+	parts := strings.Split(s, ".")
+	o := make(oid, len(parts))
+	for i, p := range parts {
+		n, err := strconv.ParseUint(p, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OID %q", s)
+		}
+		o[i] = uint32(n)
+	}
+	return o, nil
+}
+
+// child returns o extended by arcs
+func (o oid) child(arcs ...int) oid {
+	c := make(oid, len(o), len(o)+len(arcs))
+	copy(c, o)
+	for _, a := range arcs {
+		c = append(c, uint32(a))
+	}
+	return c
+}
+
+// compare orders OIDs lexicographically by arc
+func (o oid) compare(other oid) int {
+	for i := 0; i < len(o) && i < len(other); i++ {
+		switch {
+		case o[i] < other[i]:
+			return -1
+		case o[i] > other[i]:
+			return 1
+		}
+	}
+	return len(o) - len(other)
+}
+
+// String formats o with a leading dot, as gosnmp does
+func (o oid) String() string {
+	var sb strings.Builder
+	for _, a := range o {
+		sb.WriteByte('.')
+		sb.WriteString(strconv.FormatUint(uint64(a), 10))
+	}
+	return sb.String()
+}
@@ -0,0 +1,167 @@
+package snmp
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/gosnmp/gosnmp"
+)
+
+const testBase = "1.3.6.1.4.1.32473.1"
+
+func newTestAgent(t *testing.T) *Agent {
+	t.Helper()
+	agent, err := New(Options{Listen: "127.0.0.1:0", Community: "public", BaseOID: testBase}, func() Values {
+		return Values{
+			Uptime:       90 * time.Second,
+			Repeaters:    3,
+			ActiveTalker: "W1AW",
+			PacketsIn:    100,
+			PacketsOut:   250,
+			Bridges: []Bridge{
+				{Name: "zulu", State: "scheduled"},
+				{Name: "alpha", State: "connected", PacketsRx: 7, PacketsTx: 9},
+			},
+		}
+	}, logger.NewTestLogger(os.Stdout))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return agent
+}
+
+// request encodes a request, runs it through the agent and decodes the reply
+func request(t *testing.T, agent *Agent, req *gosnmp.SnmpPacket) *gosnmp.SnmpPacket {
+	t.Helper()
+	data, err := req.MarshalMsg()
+	if err != nil {
+		t.Fatalf("marshal request: %v", err)
+	}
+	resp, err := agent.handle(data)
+	if err != nil {
+		t.Fatalf("handle: %v", err)
+	}
+	reply, err := (&gosnmp.GoSNMP{}).SnmpDecodePacket(resp)
+	if err != nil {
+		t.Fatalf("decode reply: %v", err)
+	}
+	if reply.PDUType != gosnmp.GetResponse || reply.RequestID != req.RequestID {
+		t.Fatalf("unexpected reply header: type %v id %d", reply.PDUType, reply.RequestID)
+	}
+	return reply
+}
+
+func varbinds(names ...string) []gosnmp.SnmpPDU {
+	vars := make([]gosnmp.SnmpPDU, len(names))
+	for i, n := range names {
+		vars[i] = gosnmp.SnmpPDU{Name: n, Type: gosnmp.Null}
+	}
+	return vars
+}
+
+func TestAgentGet(t *testing.T) {
+	agent := newTestAgent(t)
+	reply := request(t, agent, &gosnmp.SnmpPacket{
+		Version: gosnmp.Version2c, Community: "public", PDUType: gosnmp.GetRequest, RequestID: 1,
+		Variables: varbinds("."+testBase+".1.0", "."+testBase+".2.0", "."+testBase+".3.0", "."+testBase+".4.0", "."+testBase+".99.0"),
+	})
+
+	if len(reply.Variables) != 5 {
+		t.Fatalf("expected 5 varbinds, got %d", len(reply.Variables))
+	}
+	if got := gosnmp.ToBigInt(reply.Variables[0].Value).Int64(); got != 9000 {
+		t.Errorf("expected uptime 9000 ticks, got %d", got)
+	}
+	if got := gosnmp.ToBigInt(reply.Variables[1].Value).Int64(); got != 3 {
+		t.Errorf("expected 3 repeaters, got %d", got)
+	}
+	if got := string(reply.Variables[2].Value.([]byte)); got != "W1AW" {
+		t.Errorf("expected active talker W1AW, got %q", got)
+	}
+	if reply.Variables[3].Type != gosnmp.Counter64 || gosnmp.ToBigInt(reply.Variables[3].Value).Int64() != 100 {
+		t.Errorf("unexpected packets in varbind %+v", reply.Variables[3])
+	}
+	if reply.Variables[4].Type != gosnmp.NoSuchObject {
+		t.Errorf("expected noSuchObject for unknown OID, got %v", reply.Variables[4].Type)
+	}
+}
+
+func TestAgentWalkBridgeTable(t *testing.T) {
+	agent := newTestAgent(t)
+
+	// Walk the name column with GETNEXT; rows are ordered by bridge name
+	var names []string
+	column := "." + testBase + ".9.1.1"
+	next := column
+	for {
+		reply := request(t, agent, &gosnmp.SnmpPacket{
+			Version: gosnmp.Version2c, Community: "public", PDUType: gosnmp.GetNextRequest, RequestID: 2,
+			Variables: varbinds(next),
+		})
+		v := reply.Variables[0]
+		if !strings.HasPrefix(v.Name, column+".") {
+			break
+		}
+		names = append(names, string(v.Value.([]byte)))
+		next = v.Name
+	}
+	if len(names) != 2 || names[0] != "alpha" || names[1] != "zulu" {
+		t.Fatalf("unexpected bridge names %v", names)
+	}
+
+	// GETBULK past the end of the MIB reports endOfMibView
+	reply := request(t, agent, &gosnmp.SnmpPacket{
+		Version: gosnmp.Version2c, Community: "public", PDUType: gosnmp.GetBulkRequest, RequestID: 3,
+		MaxRepetitions: 100, Variables: varbinds("." + testBase + ".9.1.4"),
+	})
+	if len(reply.Variables) != 3 {
+		t.Fatalf("expected 2 rows and endOfMibView, got %d varbinds", len(reply.Variables))
+	}
+	if reply.Variables[0].Name != "."+testBase+".9.1.4.1" || gosnmp.ToBigInt(reply.Variables[0].Value).Int64() != 9 {
+		t.Errorf("unexpected first bulk varbind %+v", reply.Variables[0])
+	}
+	if reply.Variables[2].Type != gosnmp.EndOfMibView {
+		t.Errorf("expected endOfMibView, got %v", reply.Variables[2].Type)
+	}
+}
+
+func TestAgentV1(t *testing.T) {
+	agent := newTestAgent(t)
+
+	// Counter64 objects are skipped for v1 managers
+	reply := request(t, agent, &gosnmp.SnmpPacket{
+		Version: gosnmp.Version1, Community: "public", PDUType: gosnmp.GetNextRequest, RequestID: 4,
+		Variables: varbinds("." + testBase + ".3.0"),
+	})
+	if got := reply.Variables[0].Name; got != "."+testBase+".8.0" {
+		t.Errorf("expected next v1 object to be bridge count, got %s", got)
+	}
+
+	reply = request(t, agent, &gosnmp.SnmpPacket{
+		Version: gosnmp.Version1, Community: "public", PDUType: gosnmp.GetRequest, RequestID: 5,
+		Variables: varbinds("."+testBase+".1.0", "."+testBase+".4.0"),
+	})
+	if reply.Error != gosnmp.NoSuchName || reply.ErrorIndex != 2 {
+		t.Errorf("expected noSuchName at index 2, got %v at %d", reply.Error, reply.ErrorIndex)
+	}
+}
+
+func TestAgentIgnoresWrongCommunity(t *testing.T) {
+	agent := newTestAgent(t)
+	data, err := (&gosnmp.SnmpPacket{
+		Version: gosnmp.Version2c, Community: "private", PDUType: gosnmp.GetRequest, RequestID: 6,
+		Variables: varbinds("." + testBase + ".1.0"),
+	}).MarshalMsg()
+	if err != nil {
+		t.Fatalf("marshal request: %v", err)
+	}
+	if _, err := agent.handle(data); err == nil {
+		t.Fatal("expected request with wrong community to be ignored")
+	}
+	if _, err := agent.handle([]byte{0x30, 0x03, 0x02, 0x01}); err == nil {
+		t.Fatal("expected malformed request to be rejected")
+	}
+}
@@ -0,0 +1,239 @@
+package talklog
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sheetsScope is the OAuth scope for appending to spreadsheets
+const sheetsScope = "https://www.googleapis.com/auth/spreadsheets"
+
+// sheetsBaseURL is the Google Sheets API endpoint
+const sheetsBaseURL = "https://sheets.googleapis.com/v4/spreadsheets"
+
+// defaultTokenURL is used when the key file does not name a token endpoint
+const defaultTokenURL = "https://oauth2.googleapis.com/token"
+
+// serviceAccountKey is the part of a Google service account JSON key that
+// is needed to request access tokens
+type serviceAccountKey struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// GoogleSheet appends batches to a Google Sheet as a service account. The
+// sheet must be shared with the service account's email address.
+type GoogleSheet struct {
+	spreadsheetID string
+	sheet         string
+	email         string
+	key           *rsa.PrivateKey
+	tokenURL      string
+	baseURL       string
+	client        *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewGoogleSheet creates a Google Sheets sink from a service account key
+// file. Rows are appended after the last row of sheet (e.g. "Sheet1").
+func NewGoogleSheet(spreadsheetID, sheet, credentialsFile string) (*GoogleSheet, error) {
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials: %w", err)
+	}
+	var sa serviceAccountKey
+	if err := json.Unmarshal(data, &sa); err != nil {
+		return nil, fmt.Errorf("invalid credentials file: %w", err)
+	}
+	if sa.ClientEmail == "" || sa.PrivateKey == "" {
+		return nil, fmt.Errorf("credentials file is not a service account key")
+	}
+	key, err := parsePrivateKey(sa.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	if sa.TokenURI == "" {
+		sa.TokenURI = defaultTokenURL
+	}
+	if sheet == "" {
+		sheet = "Sheet1"
+	}
+
+	return &GoogleSheet{
+		spreadsheetID: spreadsheetID,
+		sheet:         sheet,
+		email:         sa.ClientEmail,
+		key:           key,
+		tokenURL:      sa.TokenURI,
+		baseURL:       sheetsBaseURL,
+		client:        &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// parsePrivateKey decodes the PEM RSA key of a service account
+func parsePrivateKey(pemKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return nil, fmt.Errorf("invalid private key")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// Name identifies the sink in logs
+func (g *GoogleSheet) Name() string {
+	return "google_sheets"
+}
+
+// Append adds the records as rows below the last row of the sheet
+func (g *GoogleSheet) Append(ctx context.Context, records []Record) error {
+	token, err := g.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	values := make([][]string, 0, len(records))
+	for _, rec := range records {
+		values = append(values, rec.Row())
+	}
+	body, err := json.Marshal(map[string]interface{}{"values": values})
+	if err != nil {
+		return err
+	}
+
+	// RAW stores values as text; a callsign starting with '=' must not become a formula
+	endpoint := fmt.Sprintf("%s/%s/values/%s:append?valueInputOption=RAW&insertDataOption=INSERT_ROWS",
+		g.baseURL, url.PathEscape(g.spreadsheetID), url.PathEscape(g.sheet))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		// Fetch a new token on the retry
+		g.mu.Lock()
+		g.token = ""
+		g.mu.Unlock()
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("sheets API returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// accessToken returns a cached access token, requesting a new one with a
+// signed JWT assertion when it is missing or about to expire
+func (g *GoogleSheet) accessToken(ctx context.Context) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	if g.token != "" && now.Before(g.expires.Add(-time.Minute)) {
+		return g.token, nil
+	}
+
+	assertion, err := g.signAssertion(now)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("token request returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return "", fmt.Errorf("invalid token response: %w", err)
+	}
+	if tok.AccessToken == "" {
+		return "", fmt.Errorf("token response has no access token")
+	}
+
+	g.token = tok.AccessToken
+	g.expires = now.Add(time.Duration(tok.ExpiresIn) * time.Second)
+	return g.token, nil
+}
+
+// signAssertion builds the RS256-signed JWT exchanged for an access token
+func (g *GoogleSheet) signAssertion(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss":   g.email,
+		"scope": sheetsScope,
+		"aud":   g.tokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, g.key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token request: %w", err)
+	}
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
@@ -0,0 +1,105 @@
+package talklog
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGoogleSheetAppend(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokenRequests := 0
+	var appended struct {
+		Values [][]string `json:"values"`
+	}
+	var appendPath, inputOption, auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			tokenRequests++
+			_ = r.ParseForm()
+			parts := strings.Split(r.PostForm.Get("assertion"), ".")
+			if len(parts) != 3 {
+				http.Error(w, "bad assertion", http.StatusBadRequest)
+				return
+			}
+			sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+			sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+			if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, sum[:], sig); err != nil {
+				http.Error(w, "bad signature", http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "tok123", "expires_in": 3600})
+		default:
+			appendPath = r.URL.Path
+			inputOption = r.URL.Query().Get("valueInputOption")
+			auth = r.Header.Get("Authorization")
+			_ = json.NewDecoder(r.Body).Decode(&appended)
+		}
+	}))
+	defer srv.Close()
+
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: mustPKCS8(t, key)})
+	creds, _ := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "nexus@club.iam.gserviceaccount.com",
+		"private_key":  string(keyPEM),
+		"token_uri":    srv.URL + "/token",
+	})
+	path := filepath.Join(t.TempDir(), "key.json")
+	if err := os.WriteFile(path, creds, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	sheet, err := NewGoogleSheet("sheet-id", "Net Log", path)
+	if err != nil {
+		t.Fatalf("NewGoogleSheet: %v", err)
+	}
+	sheet.baseURL = srv.URL + "/v4/spreadsheets"
+
+	for i := 0; i < 2; i++ {
+		if err := sheet.Append(context.Background(), []Record{record("K1ABC", 12)}); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	if tokenRequests != 1 {
+		t.Errorf("expected the access token to be reused, got %d token requests", tokenRequests)
+	}
+	if auth != "Bearer tok123" {
+		t.Errorf("unexpected authorization %q", auth)
+	}
+	if appendPath != "/v4/spreadsheets/sheet-id/values/Net Log:append" {
+		t.Errorf("unexpected append path %q", appendPath)
+	}
+	if inputOption != "RAW" {
+		t.Errorf("valueInputOption = %q, want RAW so callsigns are never parsed as formulas", inputOption)
+	}
+	if len(appended.Values) != 1 || appended.Values[0][1] != "K1ABC" {
+		t.Errorf("unexpected values %+v", appended.Values)
+	}
+}
+
+func mustPKCS8(t *testing.T, key *rsa.PrivateKey) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
@@ -0,0 +1,237 @@
+// Package talklog exports finished transmissions to external logs such as a
+// Google Sheet or a CSV-append webhook, so clubs can keep net check-in
+// records without copying them from the dashboard by hand. Records are
+// batched, and a batch a destination rejects is retried until it is accepted
+// or the pending limit pushes it out.
+package talklog
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// Record is one finished transmission
+type Record struct {
+	Time     time.Time     // When the transmission ended
+	Callsign string        // Talker callsign
+	Duration time.Duration // Length of the transmission
+	Gateway  string        // Repeater/gateway it came through
+	Bridge   string        // Bridge it arrived on, if any
+}
+
+// Row returns the record as spreadsheet/CSV columns: time, callsign,
+// duration in seconds, gateway and bridge
+func (r Record) Row() []string {
+	return []string{
+		r.Time.UTC().Format(time.RFC3339),
+		r.Callsign,
+		strconv.FormatFloat(r.Duration.Seconds(), 'f', 1, 64),
+		r.Gateway,
+		r.Bridge,
+	}
+}
+
+// Sink is a destination for talk records
+type Sink interface {
+	// Name identifies the sink in logs
+	Name() string
+	// Append adds the records, in order, to the destination
+	Append(ctx context.Context, records []Record) error
+}
+
+// Options configures an Exporter
+type Options struct {
+	BatchSize     int           // Records per append; a full batch is sent at once
+	FlushInterval time.Duration // Longest time a record waits for its batch to fill
+	RetryInterval time.Duration // Wait after a failed append before retrying
+	MaxPending    int           // Records kept per sink while it fails; the oldest are dropped
+	MinDuration   time.Duration // Transmissions shorter than this are not exported
+}
+
+// Exporter batches records and appends them to its sinks. Each sink has its
+// own queue, so one failing destination neither blocks nor duplicates rows
+// in the others.
+type Exporter struct {
+	opts   Options
+	queues []*sinkQueue
+	logger *logger.Logger
+	wake   chan struct{}
+}
+
+// sinkQueue holds the records waiting for one sink
+type sinkQueue struct {
+	sink Sink
+
+	mu        sync.Mutex
+	pending   []Record
+	retryAt   time.Time
+	failures  int
+	dropped   uint64
+	exported  uint64
+	lastError string
+}
+
+// SinkStatus reports the state of one sink
+type SinkStatus struct {
+	Name      string `json:"name"`
+	Pending   int    `json:"pending"`
+	Exported  uint64 `json:"exported"`
+	Dropped   uint64 `json:"dropped"`
+	Failures  int    `json:"failures"` // Consecutive failed appends
+	LastError string `json:"last_error,omitempty"`
+}
+
+// New creates an exporter for the given sinks
+func New(opts Options, sinks []Sink, log *logger.Logger) *Exporter {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Minute
+	}
+	if opts.RetryInterval <= 0 {
+		opts.RetryInterval = 30 * time.Second
+	}
+
+	e := &Exporter{
+		opts:   opts,
+		logger: log.WithComponent("talklog"),
+		wake:   make(chan struct{}, 1),
+	}
+	for _, s := range sinks {
+		e.queues = append(e.queues, &sinkQueue{sink: s})
+	}
+	return e
+}
+
+// Add queues a record for every sink. A full batch wakes the exporter.
+func (e *Exporter) Add(rec Record) {
+	if rec.Duration < e.opts.MinDuration {
+		return
+	}
+
+	full := false
+	for _, q := range e.queues {
+		q.mu.Lock()
+		q.pending = append(q.pending, rec)
+		if e.opts.MaxPending > 0 && len(q.pending) > e.opts.MaxPending {
+			drop := len(q.pending) - e.opts.MaxPending
+			q.pending = q.pending[drop:]
+			q.dropped += uint64(drop)
+			e.logger.Warn("Talk log export backlog full, dropping oldest records",
+				logger.String("sink", q.sink.Name()),
+				logger.Int("dropped", drop))
+		}
+		full = full || len(q.pending) >= e.opts.BatchSize
+		q.mu.Unlock()
+	}
+
+	if full {
+		select {
+		case e.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Run flushes batches until ctx is cancelled, then makes a last attempt to
+// deliver what is pending
+func (e *Exporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.opts.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			final, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			_ = e.flush(final, time.Now(), true)
+			cancel()
+			return
+		case <-ticker.C:
+			_ = e.flush(ctx, time.Now(), true)
+		case <-e.wake:
+			_ = e.flush(ctx, time.Now(), false)
+		}
+	}
+}
+
+// Flush appends all pending records to every sink that is not waiting to
+// retry. It returns the first append error.
+func (e *Exporter) Flush(ctx context.Context) error {
+	return e.flush(ctx, time.Now(), true)
+}
+
+// flush sends the pending records of each sink in batches. Unless all is set
+// only full batches are sent.
+func (e *Exporter) flush(ctx context.Context, now time.Time, all bool) error {
+	var firstErr error
+	for _, q := range e.queues {
+		if err := e.flushQueue(ctx, q, now, all); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// flushQueue sends the pending records of one sink
+func (e *Exporter) flushQueue(ctx context.Context, q *sinkQueue, now time.Time, all bool) error {
+	for {
+		q.mu.Lock()
+		if now.Before(q.retryAt) || len(q.pending) == 0 || (!all && len(q.pending) < e.opts.BatchSize) {
+			q.mu.Unlock()
+			return nil
+		}
+		n := min(len(q.pending), e.opts.BatchSize)
+		batch := append([]Record(nil), q.pending[:n]...)
+		droppedBefore := q.dropped
+		q.mu.Unlock()
+
+		err := q.sink.Append(ctx, batch)
+
+		q.mu.Lock()
+		if err != nil {
+			q.failures++
+			q.lastError = err.Error()
+			q.retryAt = now.Add(e.opts.RetryInterval)
+			failures := q.failures
+			q.mu.Unlock()
+			e.logger.Warn("Talk log export failed, will retry",
+				logger.String("sink", q.sink.Name()),
+				logger.Int("records", len(batch)),
+				logger.Int("failures", failures),
+				logger.Error(err))
+			return fmt.Errorf("%s: %w", q.sink.Name(), err)
+		}
+
+		// Records dropped from the front while the append was in flight may
+		// include part of the batch
+		q.pending = q.pending[n-min(n, int(q.dropped-droppedBefore)):]
+		q.exported += uint64(n)
+		q.failures = 0
+		q.lastError = ""
+		q.mu.Unlock()
+	}
+}
+
+// Status reports the state of every sink
+func (e *Exporter) Status() []SinkStatus {
+	status := make([]SinkStatus, 0, len(e.queues))
+	for _, q := range e.queues {
+		q.mu.Lock()
+		status = append(status, SinkStatus{
+			Name:      q.sink.Name(),
+			Pending:   len(q.pending),
+			Exported:  q.exported,
+			Dropped:   q.dropped,
+			Failures:  q.failures,
+			LastError: q.lastError,
+		})
+		q.mu.Unlock()
+	}
+	return status
+}
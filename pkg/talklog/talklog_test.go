@@ -0,0 +1,137 @@
+package talklog
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// fakeSink records appended batches and fails while failing is set
+type fakeSink struct {
+	mu      sync.Mutex
+	batches [][]Record
+	failing bool
+}
+
+func (f *fakeSink) Name() string { return "fake" }
+
+func (f *fakeSink) Append(ctx context.Context, records []Record) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failing {
+		return errors.New("unavailable")
+	}
+	f.batches = append(f.batches, records)
+	return nil
+}
+
+func record(callsign string, seconds int) Record {
+	return Record{
+		Time:     time.Date(2024, 3, 2, 19, 30, 0, 0, time.UTC),
+		Callsign: callsign,
+		Duration: time.Duration(seconds) * time.Second,
+		Gateway:  "W1AW-RPT",
+	}
+}
+
+func TestExporterBatchesAndRetries(t *testing.T) {
+	sink := &fakeSink{failing: true}
+	e := New(Options{BatchSize: 2, RetryInterval: time.Hour, MinDuration: time.Second}, []Sink{sink}, logger.NewTestLogger(io.Discard))
+
+	e.Add(record("K1ABC", 12))
+	e.Add(record("N0CALL", 0)) // Shorter than the minimum
+	e.Add(record("W1AW", 30))
+	e.Add(record("KF8S", 5))
+
+	if err := e.Flush(context.Background()); err == nil {
+		t.Fatal("expected the failing sink to report an error")
+	}
+	status := e.Status()[0]
+	if status.Pending != 3 || status.Failures != 1 || status.LastError == "" {
+		t.Fatalf("unexpected status after failure %+v", status)
+	}
+
+	// Within the retry interval nothing is sent
+	sink.failing = false
+	if err := e.flush(context.Background(), time.Now(), true); err != nil || len(sink.batches) != 0 {
+		t.Fatalf("expected no append before the retry, got %v %d", err, len(sink.batches))
+	}
+
+	if err := e.flush(context.Background(), time.Now().Add(2*time.Hour), true); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if len(sink.batches) != 2 || len(sink.batches[0]) != 2 || len(sink.batches[1]) != 1 {
+		t.Fatalf("expected batches of 2 and 1, got %+v", sink.batches)
+	}
+	if sink.batches[0][0].Callsign != "K1ABC" || sink.batches[1][0].Callsign != "KF8S" {
+		t.Errorf("records out of order: %+v", sink.batches)
+	}
+	if status := e.Status()[0]; status.Pending != 0 || status.Exported != 3 || status.Failures != 0 {
+		t.Errorf("unexpected status after retry %+v", status)
+	}
+}
+
+func TestExporterDropsOldestBeyondMaxPending(t *testing.T) {
+	sink := &fakeSink{failing: true}
+	e := New(Options{BatchSize: 10, MaxPending: 2}, []Sink{sink}, logger.NewTestLogger(io.Discard))
+
+	e.Add(record("K1ABC", 5))
+	e.Add(record("W1AW", 5))
+	e.Add(record("KF8S", 5))
+
+	sink.failing = false
+	if err := e.Flush(context.Background()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if len(sink.batches) != 1 || len(sink.batches[0]) != 2 || sink.batches[0][0].Callsign != "W1AW" {
+		t.Fatalf("expected the oldest record to be dropped, got %+v", sink.batches)
+	}
+	if status := e.Status()[0]; status.Dropped != 1 {
+		t.Errorf("expected one dropped record, got %+v", status)
+	}
+}
+
+func TestCSVWebhook(t *testing.T) {
+	var rows [][]string
+	var contentType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		rows, _ = csv.NewReader(r.Body).ReadAll()
+	}))
+	defer srv.Close()
+
+	hook := NewCSVWebhook(srv.URL)
+	rec := record("K1ABC", 12)
+	rec.Bridge = "FCS004"
+	if err := hook.Append(context.Background(), []Record{rec, record("W1AW", 3)}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if contentType != "text/csv" {
+		t.Errorf("unexpected content type %q", contentType)
+	}
+	want := []string{"2024-03-02T19:30:00Z", "K1ABC", "12.0", "W1AW-RPT", "FCS004"}
+	if len(rows) != 2 || len(rows[0]) != len(want) {
+		t.Fatalf("unexpected rows %v", rows)
+	}
+	for i := range want {
+		if rows[0][i] != want[i] {
+			t.Errorf("column %d = %q, want %q", i, rows[0][i], want[i])
+		}
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusBadGateway)
+	}))
+	defer failing.Close()
+	if err := NewCSVWebhook(failing.URL).Append(context.Background(), []Record{rec}); err == nil {
+		t.Error("expected an error for a failing webhook")
+	}
+}
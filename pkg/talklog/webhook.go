@@ -0,0 +1,61 @@
+package talklog
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// CSVWebhook posts batches as CSV rows (no header) to a URL that appends
+// them, e.g. a script in front of a spreadsheet or a log collector
+type CSVWebhook struct {
+	url    string
+	client *http.Client
+}
+
+// NewCSVWebhook creates a CSV-append webhook sink
+func NewCSVWebhook(url string) *CSVWebhook {
+	return &CSVWebhook{url: url, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Name identifies the sink in logs
+func (w *CSVWebhook) Name() string {
+	return "csv_webhook"
+}
+
+// Append posts the records as text/csv
+func (w *CSVWebhook) Append(ctx context.Context, records []Record) error {
+	var body bytes.Buffer
+	cw := csv.NewWriter(&body)
+	for _, rec := range records {
+		if err := cw.Write(rec.Row()); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/csv")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
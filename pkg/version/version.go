@@ -0,0 +1,111 @@
+// Package version holds the build metadata of the binary. The variables are
+// set at link time, for example:
+//
+//	go build -ldflags "-X github.com/dbehnke/ysf-nexus/pkg/version.Version=v1.4.0 \
+//	  -X github.com/dbehnke/ysf-nexus/pkg/version.Commit=$(git rev-parse HEAD)"
+//
+// Fields left unset fall back to what the Go toolchain recorded in the
+// binary: the module version for go install, and the VCS revision and
+// modified flag for builds from a checkout.
+package version
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Set at link time with -X
+var (
+	// Version is the semantic version, e.g. "v1.4.0"
+	Version = "dev"
+	// Commit is the VCS revision the binary was built from
+	Commit = ""
+	// Dirty is "true" if the working tree had uncommitted changes
+	Dirty = ""
+	// BuildTime is when the binary was built
+	BuildTime = "unknown"
+)
+
+// shortCommitLength is how much of the commit hash String shows
+const shortCommitLength = 7
+
+// Info is the build metadata of the running binary
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Dirty     bool   `json:"dirty"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"` // GOOS/GOARCH
+}
+
+// Get returns the build metadata
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		Dirty:     Dirty == "true",
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	if info.Version == "dev" && build.Main.Version != "" && build.Main.Version != "(devel)" {
+		info.Version = build.Main.Version
+	}
+	if info.Commit == "" {
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				info.Commit = setting.Value
+			case "vcs.modified":
+				if Dirty == "" {
+					info.Dirty = setting.Value == "true"
+				}
+			}
+		}
+	}
+	return info
+}
+
+// ShortCommit returns the abbreviated commit hash
+func (i Info) ShortCommit() string {
+	if len(i.Commit) > shortCommitLength {
+		return i.Commit[:shortCommitLength]
+	}
+	return i.Commit
+}
+
+// Semver returns the version with the commit as semver build metadata, e.g.
+// "v1.4.0+1a2b3c4.dirty", for places with room for one word only
+func (i Info) Semver() string {
+	commit := i.ShortCommit()
+	if commit == "" || strings.Contains(i.Version, commit) {
+		return i.Version
+	}
+	v := i.Version + "+" + commit
+	if i.Dirty {
+		v += ".dirty"
+	}
+	return v
+}
+
+// String formats the metadata for --version output, e.g.
+// "v1.4.0 (commit 1a2b3c4, dirty, built 2025-01-02T03:04:05Z, go1.25.0 linux/amd64)"
+func (i Info) String() string {
+	details := make([]string, 0, 4)
+	if commit := i.ShortCommit(); commit != "" {
+		if i.Dirty {
+			commit += ", dirty"
+		}
+		details = append(details, "commit "+commit)
+	}
+	details = append(details, "built "+i.BuildTime, i.GoVersion+" "+i.Platform)
+	return fmt.Sprintf("%s (%s)", i.Version, strings.Join(details, ", "))
+}
@@ -0,0 +1,58 @@
+package version
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestInfoString(t *testing.T) {
+	info := Info{
+		Version:   "v1.4.0",
+		Commit:    "1a2b3c4d5e6f",
+		Dirty:     true,
+		BuildTime: "2025-01-02T03:04:05Z",
+		GoVersion: "go1.25.0",
+		Platform:  "linux/amd64",
+	}
+	want := "v1.4.0 (commit 1a2b3c4, dirty, built 2025-01-02T03:04:05Z, go1.25.0 linux/amd64)"
+	if got := info.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	info.Commit = ""
+	want = "v1.4.0 (built 2025-01-02T03:04:05Z, go1.25.0 linux/amd64)"
+	if got := info.String(); got != want {
+		t.Errorf("String() without commit = %q, want %q", got, want)
+	}
+}
+
+func TestInfoSemver(t *testing.T) {
+	tests := []struct {
+		info Info
+		want string
+	}{
+		{Info{Version: "v1.4.0"}, "v1.4.0"},
+		{Info{Version: "v1.4.0", Commit: "1a2b3c4d5e6f"}, "v1.4.0+1a2b3c4"},
+		{Info{Version: "v1.4.0", Commit: "1a2b3c4d5e6f", Dirty: true}, "v1.4.0+1a2b3c4.dirty"},
+		// git describe output already names the commit
+		{Info{Version: "v1.4.0-3-g1a2b3c4", Commit: "1a2b3c4d5e6f"}, "v1.4.0-3-g1a2b3c4"},
+	}
+	for _, tt := range tests {
+		if got := tt.info.Semver(); got != tt.want {
+			t.Errorf("Semver(%+v) = %q, want %q", tt.info, got, tt.want)
+		}
+	}
+}
+
+func TestGetUsesLinkTimeValues(t *testing.T) {
+	defer func(v, c, d, b string) { Version, Commit, Dirty, BuildTime = v, c, d, b }(Version, Commit, Dirty, BuildTime)
+	Version, Commit, Dirty, BuildTime = "v2.0.0", "abcdef1234", "true", "yesterday"
+
+	info := Get()
+	if info.Version != "v2.0.0" || info.Commit != "abcdef1234" || !info.Dirty || info.BuildTime != "yesterday" {
+		t.Errorf("Get() = %+v", info)
+	}
+	if info.GoVersion != runtime.Version() || info.Platform != runtime.GOOS+"/"+runtime.GOARCH {
+		t.Errorf("Get() runtime fields = %+v", info)
+	}
+}
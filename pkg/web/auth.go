@@ -0,0 +1,158 @@
+package web
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+)
+
+// Dashboard authentication. Every backend ends in a user with a role: the
+// local backend logs the configured web user in as admin, proxy reads the
+// user and groups a trusted reverse proxy sets on each request, and oidc
+// creates a session after signing in with an OpenID Connect provider (see
+// oidc.go). Admins may use every protected endpoint, viewers may only read.
+
+// sessionTTL is how long a login lasts
+const sessionTTL = 24 * time.Hour
+
+// session is a logged-in dashboard user
+type session struct {
+	user   string
+	role   string
+	expiry time.Time
+}
+
+// authBackend returns the configured authentication backend
+func (s *Server) authBackend() string {
+	if backend := s.config.Web.Auth.Backend; backend != "" {
+		return backend
+	}
+	return config.AuthBackendLocal
+}
+
+// sessionToken returns the session token from the Authorization header or
+// the session cookie
+func sessionToken(r *http.Request) string {
+	if token := r.Header.Get("Authorization"); token != "" {
+		return strings.TrimPrefix(token, "Bearer ")
+	}
+	if cookie, err := r.Cookie("session_token"); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+// authenticate returns the user making the request, if any
+func (s *Server) authenticate(r *http.Request) (session, bool) {
+	if s.authBackend() == config.AuthBackendProxy {
+		return s.proxyUser(r)
+	}
+
+	token := sessionToken(r)
+	if token == "" {
+		return session{}, false
+	}
+	s.sessionsMu.RLock()
+	sess, exists := s.sessions[token]
+	s.sessionsMu.RUnlock()
+	if !exists || time.Now().After(sess.expiry) {
+		return session{}, false
+	}
+	return sess, true
+}
+
+// authorize reports whether a user may make a request with the given method
+// to a protected endpoint
+func authorize(sess session, method string) bool {
+	switch sess.role {
+	case config.RoleAdmin:
+		return true
+	case config.RoleViewer:
+		return method == http.MethodGet || method == http.MethodHead
+	default:
+		return false
+	}
+}
+
+// newSession stores a session for a logged-in user and returns its token
+func (s *Server) newSession(user, role string) (string, time.Time, error) {
+	token, err := s.generateSessionToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	expiry := time.Now().Add(sessionTTL)
+	s.sessionsMu.Lock()
+	s.sessions[token] = session{user: user, role: role, expiry: expiry}
+	s.sessionsMu.Unlock()
+	return token, expiry, nil
+}
+
+// setSessionCookie hands the session token to the browser
+func setSessionCookie(w http.ResponseWriter, r *http.Request, token string, expiry time.Time) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "session_token",
+		Value:    token,
+		Expires:  expiry,
+		HttpOnly: true,
+		Secure:   r.TLS != nil, // Only secure if HTTPS
+		SameSite: http.SameSiteStrictMode,
+		Path:     "/",
+	})
+}
+
+// proxyUser returns the user named by the reverse proxy headers. Headers are
+// only believed from trusted proxies; anyone else could set them.
+func (s *Server) proxyUser(r *http.Request) (session, bool) {
+	pc := s.config.Web.Auth.Proxy
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !pc.Trusts(ip) {
+		return session{}, false
+	}
+
+	user := strings.TrimSpace(r.Header.Get(pc.UserHeader))
+	if user == "" {
+		return session{}, false
+	}
+	var groups []string
+	if pc.GroupsHeader != "" {
+		for _, group := range strings.Split(r.Header.Get(pc.GroupsHeader), ",") {
+			if group = strings.TrimSpace(group); group != "" {
+				groups = append(groups, group)
+			}
+		}
+	}
+	return session{user: user, role: mapRole(s.config.Web.Auth, groups)}, true
+}
+
+// mapRole returns the role of a user belonging to groups: admin or viewer
+// when in one of their groups, otherwise the default role, none if unset
+func mapRole(auth config.AuthConfig, groups []string) string {
+	member := func(names []string) bool {
+		for _, name := range names {
+			for _, group := range groups {
+				if group == name {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	switch {
+	case member(auth.AdminGroups):
+		return config.RoleAdmin
+	case member(auth.ViewerGroups):
+		return config.RoleViewer
+	case auth.DefaultRole == "":
+		return config.RoleNone
+	default:
+		return auth.DefaultRole
+	}
+}
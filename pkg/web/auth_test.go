@@ -0,0 +1,253 @@
+package web
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+)
+
+func TestProxyAuthRoles(t *testing.T) {
+	s, _ := newTestServer(t)
+	s.config.Web.AuthRequired = true
+	s.config.Web.Auth = config.AuthConfig{
+		Backend: config.AuthBackendProxy,
+		Proxy: config.ProxyAuthConfig{
+			UserHeader:     "Remote-User",
+			GroupsHeader:   "Remote-Groups",
+			TrustedProxies: []string{"192.0.2.0/24"},
+		},
+		AdminGroups:  []string{"ysf-admins"},
+		ViewerGroups: []string{"ysf-viewers"},
+		DefaultRole:  config.RoleNone,
+	}
+	protected := s.authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	request := func(method, remote, user, groups string) int {
+		t.Helper()
+		req := httptest.NewRequest(method, "/api/config/server", nil)
+		req.RemoteAddr = remote
+		if user != "" {
+			req.Header.Set("Remote-User", user)
+			req.Header.Set("Remote-Groups", groups)
+		}
+		rec := httptest.NewRecorder()
+		protected.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	tests := []struct {
+		name           string
+		method, remote string
+		user, groups   string
+		want           int
+	}{
+		{"admin reads", http.MethodGet, "192.0.2.1:4000", "alice", "users, ysf-admins", http.StatusNoContent},
+		{"admin writes", http.MethodPut, "192.0.2.1:4000", "alice", "ysf-admins", http.StatusNoContent},
+		{"viewer reads", http.MethodGet, "192.0.2.1:4000", "bob", "ysf-viewers", http.StatusNoContent},
+		{"viewer writes", http.MethodPut, "192.0.2.1:4000", "bob", "ysf-viewers", http.StatusForbidden},
+		{"user in no group", http.MethodGet, "192.0.2.1:4000", "carol", "users", http.StatusForbidden},
+		{"no user header", http.MethodGet, "192.0.2.1:4000", "", "", http.StatusUnauthorized},
+		{"untrusted proxy", http.MethodGet, "198.51.100.7:4000", "alice", "ysf-admins", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		if got := request(tt.method, tt.remote, tt.user, tt.groups); got != tt.want {
+			t.Errorf("%s: status %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	// Password logins are refused; the proxy does the logging in
+	rec := httptest.NewRecorder()
+	s.handleLogin(rec, httptest.NewRequest(http.MethodPost, "/api/auth/login", strings.NewReader(`{"username":"admin","password":"x"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("password login status %d, want 400", rec.Code)
+	}
+}
+
+// fakeIDToken encodes claims as an unsigned JWT
+func fakeIDToken(claims map[string]interface{}) string {
+	payload, _ := json.Marshal(claims)
+	return base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." +
+		base64.RawURLEncoding.EncodeToString(payload) + ".sig"
+}
+
+func TestOIDCLogin(t *testing.T) {
+	var provider *httptest.Server
+	var challenge, nonce string
+	provider = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"issuer":                 provider.URL,
+				"authorization_endpoint": provider.URL + "/authorize",
+				"token_endpoint":         provider.URL + "/token",
+			})
+		case "/token":
+			user, secret, _ := r.BasicAuth()
+			verifier := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+			if user != "nexus" || secret != "s3cret" || r.PostFormValue("code") != "code-1" ||
+				base64.RawURLEncoding.EncodeToString(verifier[:]) != challenge {
+				http.Error(w, "invalid_grant", http.StatusBadRequest)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]string{"id_token": fakeIDToken(map[string]interface{}{
+				"iss":                provider.URL,
+				"aud":                []string{"nexus"},
+				"exp":                time.Now().Add(time.Hour).Unix(),
+				"nonce":              nonce,
+				"sub":                "f81d4fae",
+				"preferred_username": "n0call",
+				"realm_access":       map[string]interface{}{"roles": []string{"ysf-admins"}},
+			})})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer provider.Close()
+
+	s, _ := newTestServer(t)
+	s.config.Web.AuthRequired = true
+	s.config.Web.Auth = config.AuthConfig{
+		Backend: config.AuthBackendOIDC,
+		OIDC: config.OIDCAuthConfig{
+			Issuer:        provider.URL,
+			ClientID:      "nexus",
+			ClientSecret:  "s3cret",
+			RedirectURL:   "http://dashboard.example.org/api/auth/oidc/callback",
+			Scopes:        []string{"profile"},
+			UsernameClaim: "preferred_username",
+			GroupsClaim:   "realm_access.roles",
+		},
+		AdminGroups: []string{"ysf-admins"},
+		DefaultRole: config.RoleNone,
+	}
+	s.oidc = newOIDCClient(s.config.Web.Auth.OIDC)
+	router := s.setupRoutes()
+
+	// The login endpoint sends the browser to the provider
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/auth/oidc/login", nil))
+	if rec.Code != http.StatusFound {
+		t.Fatalf("login status %d, want 302", rec.Code)
+	}
+	location, err := url.Parse(rec.Header().Get("Location"))
+	if err != nil || location.Path != "/authorize" {
+		t.Fatalf("unexpected redirect %q", rec.Header().Get("Location"))
+	}
+	query := location.Query()
+	if query.Get("client_id") != "nexus" || query.Get("scope") != "openid profile" || query.Get("code_challenge_method") != "S256" {
+		t.Errorf("unexpected authorization request %v", query)
+	}
+	state := query.Get("state")
+	challenge, nonce = query.Get("code_challenge"), query.Get("nonce")
+	var stateCookie *http.Cookie
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == oidcStateCookie {
+			stateCookie = cookie
+		}
+	}
+	if stateCookie == nil || stateCookie.Value != state {
+		t.Fatal("expected the state cookie to carry the state")
+	}
+
+	callback := func(state string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/auth/oidc/callback?code=code-1&state="+url.QueryEscape(state), nil)
+		req.AddCookie(stateCookie)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// A callback whose state does not match the browser's is refused
+	if rec := callback("forged"); rec.Code != http.StatusBadRequest {
+		t.Errorf("forged state status %d, want 400", rec.Code)
+	}
+
+	rec = callback(state)
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/" {
+		t.Fatalf("callback status %d location %q: %s", rec.Code, rec.Header().Get("Location"), rec.Body.String())
+	}
+	var sessionCookie *http.Cookie
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == "session_token" {
+			sessionCookie = cookie
+		}
+	}
+	if sessionCookie == nil {
+		t.Fatal("expected a session cookie")
+	}
+
+	// The session belongs to the user with the mapped role
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/status", nil)
+	req.AddCookie(sessionCookie)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	var status map[string]interface{}
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+		t.Fatalf("decode status: %v", err)
+	}
+	if status["authenticated"] != true || status["user"] != "n0call" || status["role"] != config.RoleAdmin || status["backend"] != config.AuthBackendOIDC {
+		t.Errorf("unexpected auth status %v", status)
+	}
+
+	// Each sign-in completes once
+	if rec := callback(state); rec.Code != http.StatusUnauthorized {
+		t.Errorf("replayed callback status %d, want 401", rec.Code)
+	}
+}
+
+func TestOIDCVerifyClaims(t *testing.T) {
+	c := newOIDCClient(config.OIDCAuthConfig{ClientID: "nexus"})
+	now := time.Unix(1700000000, 0)
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{"iss": "https://idp", "aud": "nexus", "exp": float64(now.Unix() + 60), "nonce": "n"}
+	}
+	if err := c.verify(valid(), "https://idp", "n", now); err != nil {
+		t.Fatalf("valid claims rejected: %v", err)
+	}
+
+	for name, change := range map[string]func(map[string]interface{}){
+		"issuer":   func(c map[string]interface{}) { c["iss"] = "https://evil" },
+		"audience": func(c map[string]interface{}) { c["aud"] = []interface{}{"other"} },
+		"expired":  func(c map[string]interface{}) { c["exp"] = float64(now.Unix()) },
+		"nonce":    func(c map[string]interface{}) { c["nonce"] = "replayed" },
+	} {
+		claims := valid()
+		change(claims)
+		if err := c.verify(claims, "https://idp", "n", now); err == nil {
+			t.Errorf("%s: expected claims to be rejected", name)
+		}
+	}
+}
+
+func TestOIDCRejectsPlainHTTPEndpoints(t *testing.T) {
+	var provider *httptest.Server
+	provider = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 provider.URL,
+			"authorization_endpoint": provider.URL + "/authorize",
+			"token_endpoint":         "http://idp.example.org/token",
+		})
+	}))
+	defer provider.Close()
+
+	c := newOIDCClient(config.OIDCAuthConfig{Issuer: provider.URL, ClientID: "nexus"})
+	if _, _, err := c.authURL(context.Background(), time.Now()); err == nil {
+		t.Fatal("expected a token endpoint over plain http to be rejected")
+	}
+
+	c = newOIDCClient(config.OIDCAuthConfig{Issuer: "http://idp.example.org", ClientID: "nexus"})
+	if _, err := c.discover(context.Background()); err == nil {
+		t.Fatal("expected an issuer over plain http to be rejected")
+	}
+}
@@ -0,0 +1,201 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/persist"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+// CallsignStats holds lifetime statistics for a source callsign
+type CallsignStats struct {
+	Callsign      string    `json:"callsign"`
+	Transmissions uint64    `json:"transmissions"`
+	TalkTime      int64     `json:"talk_time"` // in seconds
+	LastGateway   string    `json:"last_gateway,omitempty"`
+	LastBridge    string    `json:"last_bridge,omitempty"`
+	FirstHeard    time.Time `json:"first_heard"`
+	LastHeard     time.Time `json:"last_heard"`
+}
+
+// Leaderboard sort keys
+const (
+	LeaderboardByTalkTime      = "talk_time"
+	LeaderboardByTransmissions = "transmissions"
+	LeaderboardByLastHeard     = "last_heard"
+)
+
+// callsignTracker accumulates per-callsign statistics from talk_end events,
+// optionally persisted to a JSON file so they survive restarts
+type callsignTracker struct {
+	mu    sync.RWMutex
+	stats map[string]*CallsignStats
+	path  string
+	dirty bool
+}
+
+func newCallsignTracker(path string) *callsignTracker {
+	return &callsignTracker{
+		stats: make(map[string]*CallsignStats),
+		path:  path,
+	}
+}
+
+// record adds a finished transmission
+func (c *callsignTracker) record(event repeater.Event) {
+	callsign := strings.ToUpper(strings.TrimSpace(event.Callsign))
+	if callsign == "" {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats, ok := c.stats[callsign]
+	if !ok {
+		stats = &CallsignStats{Callsign: callsign, FirstHeard: event.Timestamp}
+		c.stats[callsign] = stats
+	}
+	stats.Transmissions++
+	stats.TalkTime += int64(event.Duration.Seconds())
+	stats.LastHeard = event.Timestamp
+	if event.Gateway != "" {
+		stats.LastGateway = event.Gateway
+	}
+	stats.LastBridge = event.Bridge
+	c.dirty = true
+}
+
+// get returns the statistics for a callsign
+func (c *callsignTracker) get(callsign string) (CallsignStats, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	stats, ok := c.stats[strings.ToUpper(strings.TrimSpace(callsign))]
+	if !ok {
+		return CallsignStats{}, false
+	}
+	return *stats, true
+}
+
+// forget removes the statistics of a callsign and reports whether there were any
+func (c *callsignTracker) forget(callsign string) bool {
+	key := strings.ToUpper(strings.TrimSpace(callsign))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.stats[key]; !ok {
+		return false
+	}
+	delete(c.stats, key)
+	c.dirty = true
+	return true
+}
+
+// leaderboard returns the top callsigns by the given key (at most limit, 0 = all)
+func (c *callsignTracker) leaderboard(sortBy string, limit int) ([]CallsignStats, error) {
+	var less func(a, b CallsignStats) bool
+	switch sortBy {
+	case "", LeaderboardByTalkTime:
+		less = func(a, b CallsignStats) bool { return a.TalkTime > b.TalkTime }
+	case LeaderboardByTransmissions:
+		less = func(a, b CallsignStats) bool { return a.Transmissions > b.Transmissions }
+	case LeaderboardByLastHeard:
+		less = func(a, b CallsignStats) bool { return a.LastHeard.After(b.LastHeard) }
+	default:
+		return nil, fmt.Errorf("unknown sort key %q", sortBy)
+	}
+
+	c.mu.RLock()
+	board := make([]CallsignStats, 0, len(c.stats))
+	for _, stats := range c.stats {
+		board = append(board, *stats)
+	}
+	c.mu.RUnlock()
+
+	sort.Slice(board, func(i, j int) bool {
+		if less(board[i], board[j]) != less(board[j], board[i]) {
+			return less(board[i], board[j])
+		}
+		return board[i].Callsign < board[j].Callsign
+	})
+	if limit > 0 && len(board) > limit {
+		board = board[:limit]
+	}
+	return board, nil
+}
+
+// load reads persisted statistics; a missing file is not an error
+func (c *callsignTracker) load() error {
+	if c.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(c.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var entries []CallsignStats
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", c.path, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range entries {
+		c.stats[entries[i].Callsign] = &entries[i]
+	}
+	return nil
+}
+
+// save writes the statistics if they changed since the last save
+func (c *callsignTracker) save() error {
+	if c.path == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	if !c.dirty {
+		c.mu.Unlock()
+		return nil
+	}
+	entries := make([]CallsignStats, 0, len(c.stats))
+	for _, stats := range c.stats {
+		entries = append(entries, *stats)
+	}
+	c.dirty = false
+	c.mu.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Callsign < entries[j].Callsign })
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	return persist.WriteFile(c.path, ".callsigns-*.json", data)
+}
+
+// saveCallsigns writes callsign statistics, logging failures
+func (s *Server) saveCallsigns() {
+	if err := s.callsigns.save(); err != nil {
+		s.logger.Error("Failed to save callsign statistics", logger.Error(err))
+	}
+}
+
+// runCallsignPersistence saves callsign statistics every minute and once more on shutdown
+func (s *Server) runCallsignPersistence(ctx context.Context) {
+	persist.Every(ctx, time.Minute, s.saveCallsigns)
+	s.saveCallsigns()
+}
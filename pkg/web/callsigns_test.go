@@ -0,0 +1,89 @@
+package web
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+func TestCallsignTrackerRecordAndLeaderboard(t *testing.T) {
+	c := newCallsignTracker("")
+	start := time.Date(2025, 10, 3, 12, 0, 0, 0, time.UTC)
+
+	c.record(repeater.Event{Callsign: "w1aw", Duration: 30 * time.Second, Gateway: "GW1", Timestamp: start})
+	c.record(repeater.Event{Callsign: "W1AW", Duration: 10 * time.Second, Gateway: "GW2", Timestamp: start.Add(time.Minute)})
+	c.record(repeater.Event{Callsign: "K8ABC", Duration: 60 * time.Second, Bridge: "net", Timestamp: start.Add(2 * time.Minute)})
+
+	stats, ok := c.get("W1AW")
+	if !ok {
+		t.Fatalf("expected stats for W1AW")
+	}
+	if stats.Transmissions != 2 || stats.TalkTime != 40 || stats.LastGateway != "GW2" {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if !stats.FirstHeard.Equal(start) || !stats.LastHeard.Equal(start.Add(time.Minute)) {
+		t.Errorf("unexpected first/last heard: %+v", stats)
+	}
+
+	board, err := c.leaderboard(LeaderboardByTalkTime, 0)
+	if err != nil || len(board) != 2 || board[0].Callsign != "K8ABC" {
+		t.Errorf("unexpected talk time leaderboard: %+v (%v)", board, err)
+	}
+	board, _ = c.leaderboard(LeaderboardByTransmissions, 1)
+	if len(board) != 1 || board[0].Callsign != "W1AW" {
+		t.Errorf("unexpected transmissions leaderboard: %+v", board)
+	}
+	if _, err := c.leaderboard("bogus", 0); err == nil {
+		t.Errorf("expected error for unknown sort key")
+	}
+}
+
+func TestCallsignTrackerRecordsLocalTransmissions(t *testing.T) {
+	events := make(chan repeater.Event, 10)
+	m := repeater.NewManager(5*time.Second, 10, events, 180*time.Second, 0)
+	addr := &net.UDPAddr{IP: net.ParseIP("192.0.2.10"), Port: 42000}
+	m.AddRepeater("GW1", addr)
+	m.ProcessPacket("N0CALL", addr, "YSFD", 155)
+	m.RemoveRepeater(addr, repeater.DisconnectUnlink)
+
+	c := newCallsignTracker("")
+	for len(events) > 0 {
+		if event := <-events; event.Type == repeater.EventTalkEnd {
+			c.record(event)
+		}
+	}
+
+	stats, ok := c.get("N0CALL")
+	if !ok || stats.Transmissions != 1 || stats.LastGateway != "GW1" {
+		t.Errorf("expected one transmission by N0CALL via GW1, got %+v", stats)
+	}
+	if _, ok := c.get("GW1"); ok {
+		t.Error("expected no stats under the gateway callsign")
+	}
+}
+
+func TestCallsignTrackerPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "callsigns.json")
+
+	c := newCallsignTracker(path)
+	c.record(repeater.Event{Callsign: "W1AW", Duration: 30 * time.Second, Timestamp: time.Now()})
+	if err := c.save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	restored := newCallsignTracker(path)
+	if err := restored.load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if stats, ok := restored.get("W1AW"); !ok || stats.TalkTime != 30 {
+		t.Errorf("expected restored stats, got %+v", stats)
+	}
+
+	// A missing file is not an error
+	if err := newCallsignTracker(filepath.Join(t.TempDir(), "missing.json")).load(); err != nil {
+		t.Errorf("unexpected error for missing file: %v", err)
+	}
+}
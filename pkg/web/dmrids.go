@@ -0,0 +1,118 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/dbehnke/ysf-nexus/pkg/dmrid"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/gorilla/mux"
+)
+
+// directory returns the DMR ID directory, or writes a 404 when there is none
+func (s *Server) directory(w http.ResponseWriter) *dmrid.Directory {
+	s.mu.RLock()
+	d := s.dmrIDs
+	s.mu.RUnlock()
+
+	if d == nil {
+		http.Error(w, "DMR ID directory not available", http.StatusNotFound)
+	}
+	return d
+}
+
+// handleGetDMRIDs lists the DMR ID overrides
+func (s *Server) handleGetDMRIDs(w http.ResponseWriter, r *http.Request) {
+	d := s.directory(w)
+	if d == nil {
+		return
+	}
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"overrides": d.Overrides(),
+	}); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+// handleLookupDMRID resolves ?callsign= to a DMR ID or ?id= to a callsign,
+// overrides first
+func (s *Server) handleLookupDMRID(w http.ResponseWriter, r *http.Request) {
+	d := s.directory(w)
+	if d == nil {
+		return
+	}
+
+	var (
+		entry dmrid.Entry
+		found bool
+	)
+	query := r.URL.Query()
+	switch {
+	case query.Get("callsign") != "":
+		entry, found = d.ByCallsign(query.Get("callsign"))
+	case query.Get("id") != "":
+		id, err := strconv.ParseUint(query.Get("id"), 10, 32)
+		if err != nil {
+			http.Error(w, "Invalid DMR ID", http.StatusBadRequest)
+			return
+		}
+		entry, found = d.ByID(uint32(id))
+	default:
+		http.Error(w, "callsign or id is required", http.StatusBadRequest)
+		return
+	}
+	if !found {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(entry); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+// handlePutDMRID adds or replaces the DMR ID override for a callsign until restart
+func (s *Server) handlePutDMRID(w http.ResponseWriter, r *http.Request) {
+	d := s.directory(w)
+	if d == nil {
+		return
+	}
+
+	var body struct {
+		ID   uint32 `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	entry := dmrid.Entry{Callsign: mux.Vars(r)["callsign"], ID: body.ID, Name: body.Name}
+	if err := d.Put(entry); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.logger.Info("DMR ID override updated",
+		logger.String("callsign", entry.Callsign),
+		logger.Uint32("dmr_id", entry.ID))
+
+	s.handleGetDMRIDs(w, r)
+}
+
+// handleDeleteDMRID removes the DMR ID override for a callsign
+func (s *Server) handleDeleteDMRID(w http.ResponseWriter, r *http.Request) {
+	d := s.directory(w)
+	if d == nil {
+		return
+	}
+
+	callsign := mux.Vars(r)["callsign"]
+	if !d.Delete(callsign) {
+		http.Error(w, "Override not found", http.StatusNotFound)
+		return
+	}
+	s.logger.Info("DMR ID override removed", logger.String("callsign", callsign))
+
+	s.handleGetDMRIDs(w, r)
+}
@@ -0,0 +1,66 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dbehnke/ysf-nexus/pkg/dmrid"
+)
+
+func TestDMRIDsAPI(t *testing.T) {
+	s, _ := newTestServer(t)
+	router := s.setupRoutes()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/dmrids", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 without a directory, got %d", rec.Code)
+	}
+
+	d, _ := dmrid.New(nil)
+	s.SetDMRIDs(d)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/api/dmrids/w1aw",
+		strings.NewReader(`{"id":3100001,"name":"ARRL HQ"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT override: %d %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Overrides []dmrid.Entry `json:"overrides"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decode overrides: %v", err)
+	}
+	if len(body.Overrides) != 1 || body.Overrides[0].Callsign != "W1AW" {
+		t.Fatalf("unexpected overrides %+v", body.Overrides)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/api/dmrids/K1ABC",
+		strings.NewReader(`{"id":3100001}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 reusing an ID, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/dmrids/lookup?id=3100001", nil))
+	var entry dmrid.Entry
+	if err := json.NewDecoder(rec.Body).Decode(&entry); err != nil || entry.Callsign != "W1AW" || entry.Source != dmrid.SourceOverride {
+		t.Errorf("unexpected lookup by ID: %+v (%v)", entry, err)
+	}
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/dmrids/lookup?callsign=N0CALL", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown callsign, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/dmrids/W1AW", nil))
+	if rec.Code != http.StatusOK || len(d.Overrides()) != 0 {
+		t.Errorf("expected the override to be deleted, got %d", rec.Code)
+	}
+}
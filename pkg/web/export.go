@@ -0,0 +1,192 @@
+package web
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+// Net log exports. /api/repeaters/export and /api/bridges/export return the
+// current state as a CSV (default) or JSON attachment, selected with
+// ?format=csv|json. Addresses are masked at the request's mask level.
+
+// RepeaterExport is a connected repeater in a net log export
+type RepeaterExport struct {
+	Callsign         string    `json:"callsign"`
+	Address          string    `json:"address"`
+	Connected        time.Time `json:"connected"`
+	LastSeen         time.Time `json:"last_seen"`
+	Uptime           int       `json:"uptime"` // in seconds
+	IsTalking        bool      `json:"is_talking"`
+	TotalTalkTime    int       `json:"total_talk_time"` // in seconds
+	PacketCount      uint64    `json:"packet_count"`
+	BytesReceived    uint64    `json:"bytes_received"`
+	BytesTransmitted uint64    `json:"bytes_transmitted"`
+}
+
+// BridgeExport is a bridge in a net log export
+type BridgeExport struct {
+	Name        string     `json:"name"`
+	Address     string     `json:"address,omitempty"`
+	State       string     `json:"state"`
+	ConnectedAt *time.Time `json:"connected_at,omitempty"`
+	Temporary   bool       `json:"temporary"`
+	RxOnly      bool       `json:"rx_only"`
+	Connections uint64     `json:"connections"`
+	PacketsRx   uint64     `json:"packets_rx"`
+	PacketsTx   uint64     `json:"packets_tx"`
+	BytesRx     uint64     `json:"bytes_rx"`
+	BytesTx     uint64     `json:"bytes_tx"`
+}
+
+// handleExportRepeaters exports the connected repeaters
+func (s *Server) handleExportRepeaters(w http.ResponseWriter, r *http.Request) {
+	format, ok := exportFormat(w, r)
+	if !ok {
+		return
+	}
+	level := s.maskLevel(r)
+
+	rows := []RepeaterExport{}
+	for _, rp := range s.repeaterManager.GetAllRepeaters() {
+		rows = append(rows, RepeaterExport{
+			Callsign:         rp.Callsign(),
+			Address:          repeater.MaskAddress(rp.Address().String(), level),
+			Connected:        rp.Connected(),
+			LastSeen:         rp.LastSeen(),
+			Uptime:           int(rp.Uptime().Seconds()),
+			IsTalking:        rp.IsTalking(),
+			TotalTalkTime:    int(rp.TotalTalkTime().Seconds()),
+			PacketCount:      rp.PacketCount(),
+			BytesReceived:    rp.BytesReceived(),
+			BytesTransmitted: rp.BytesTransmitted(),
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Callsign < rows[j].Callsign })
+
+	header := []string{"callsign", "address", "connected", "last_seen", "uptime", "is_talking",
+		"total_talk_time", "packet_count", "bytes_received", "bytes_transmitted"}
+	records := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		records = append(records, []string{
+			row.Callsign,
+			row.Address,
+			row.Connected.UTC().Format(time.RFC3339),
+			row.LastSeen.UTC().Format(time.RFC3339),
+			strconv.Itoa(row.Uptime),
+			strconv.FormatBool(row.IsTalking),
+			strconv.Itoa(row.TotalTalkTime),
+			strconv.FormatUint(row.PacketCount, 10),
+			strconv.FormatUint(row.BytesReceived, 10),
+			strconv.FormatUint(row.BytesTransmitted, 10),
+		})
+	}
+
+	s.writeExport(w, "repeaters", format, rows, header, records)
+}
+
+// handleExportBridges exports the bridges and their state
+func (s *Server) handleExportBridges(w http.ResponseWriter, r *http.Request) {
+	format, ok := exportFormat(w, r)
+	if !ok {
+		return
+	}
+	level := s.maskLevel(r)
+
+	bridges := s.config.Bridges
+	if bm, ok := s.bridgeManager.(interface{ Configs() []config.BridgeConfig }); ok {
+		bridges = bm.Configs()
+	}
+	addresses := make(map[string]string, len(bridges))
+	for _, b := range bridges {
+		addresses[b.Name] = repeater.MaskAddress(net.JoinHostPort(b.Host, strconv.Itoa(b.Port)), level)
+	}
+
+	rows := []BridgeExport{}
+	for name, status := range s.bridgeStatuses() {
+		rows = append(rows, BridgeExport{
+			Name:        name,
+			Address:     addresses[name],
+			State:       string(status.State),
+			ConnectedAt: status.ConnectedAt,
+			Temporary:   status.Temporary,
+			RxOnly:      status.RxOnly,
+			Connections: status.Connections,
+			PacketsRx:   status.PacketsRx,
+			PacketsTx:   status.PacketsTx,
+			BytesRx:     status.BytesRx,
+			BytesTx:     status.BytesTx,
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Name < rows[j].Name })
+
+	header := []string{"name", "address", "state", "connected_at", "temporary", "rx_only",
+		"connections", "packets_rx", "packets_tx", "bytes_rx", "bytes_tx"}
+	records := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		connectedAt := ""
+		if row.ConnectedAt != nil {
+			connectedAt = row.ConnectedAt.UTC().Format(time.RFC3339)
+		}
+		records = append(records, []string{
+			row.Name,
+			row.Address,
+			row.State,
+			connectedAt,
+			strconv.FormatBool(row.Temporary),
+			strconv.FormatBool(row.RxOnly),
+			strconv.FormatUint(row.Connections, 10),
+			strconv.FormatUint(row.PacketsRx, 10),
+			strconv.FormatUint(row.PacketsTx, 10),
+			strconv.FormatUint(row.BytesRx, 10),
+			strconv.FormatUint(row.BytesTx, 10),
+		})
+	}
+
+	s.writeExport(w, "bridges", format, rows, header, records)
+}
+
+// exportFormat returns the requested export format, answering 400 for an unknown one
+func exportFormat(w http.ResponseWriter, r *http.Request) (string, bool) {
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "csv":
+		return "csv", true
+	case "json":
+		return "json", true
+	default:
+		http.Error(w, "Invalid format (use csv or json)", http.StatusBadRequest)
+		return "", false
+	}
+}
+
+// writeExport writes an export as a CSV or JSON attachment
+func (s *Server) writeExport(w http.ResponseWriter, name, format string, rows interface{}, header []string, records [][]string) {
+	generated := time.Now().UTC()
+	filename := fmt.Sprintf("%s-%s.%s", name, generated.Format("20060102-150405"), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	if format == "json" {
+		if err := json.NewEncoder(w).Encode(map[string]interface{}{
+			"generated": generated,
+			name:        rows,
+		}); err != nil {
+			s.logger.Error("failed to encode JSON response", logger.Error(err))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(append([][]string{header}, records...)); err != nil {
+		s.logger.Error("failed to write CSV export", logger.Error(err))
+	}
+}
@@ -0,0 +1,61 @@
+package web
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+)
+
+func TestExportRepeatersMasksAddressesForGuests(t *testing.T) {
+	s, _ := newTestServer(t)
+	s.repeaterManager.AddRepeater("W1AW", &net.UDPAddr{IP: net.ParseIP("192.0.2.10"), Port: 42000})
+	s.config.Web.AuthRequired = true
+	s.sessions["token"] = session{role: config.RoleAdmin, expiry: time.Now().Add(time.Hour)}
+
+	rec := httptest.NewRecorder()
+	s.handleExportRepeaters(rec, httptest.NewRequest(http.MethodGet, "/api/repeaters/export", nil))
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/csv") {
+		t.Fatalf("expected CSV content type, got %q", got)
+	}
+	if got := rec.Header().Get("Content-Disposition"); !strings.HasPrefix(got, "attachment; filename=\"repeaters-") {
+		t.Errorf("expected attachment disposition, got %q", got)
+	}
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("parse CSV: %v", err)
+	}
+	if len(records) != 2 || records[1][0] != "W1AW" || records[1][1] != "192.0.**:42000" {
+		t.Fatalf("expected one masked repeater row, got %v", records)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/repeaters/export?format=json", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	rec = httptest.NewRecorder()
+	s.handleExportRepeaters(rec, req)
+	var body struct {
+		Repeaters []RepeaterExport `json:"repeaters"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decode JSON: %v", err)
+	}
+	if len(body.Repeaters) != 1 || body.Repeaters[0].Address != "192.0.2.10:42000" {
+		t.Errorf("expected unmasked address for an authenticated user, got %+v", body.Repeaters)
+	}
+}
+
+func TestExportRejectsUnknownFormat(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	rec := httptest.NewRecorder()
+	s.handleExportBridges(rec, httptest.NewRequest(http.MethodGet, "/api/bridges/export?format=xml", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,43 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+)
+
+func TestFeaturesAPI(t *testing.T) {
+	s, _ := newTestServer(t)
+	s.config.Features = map[string]bool{config.FeatureRecording: true}
+	router := s.setupRoutes()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/features", nil))
+	var features []config.Feature
+	if err := json.NewDecoder(rec.Body).Decode(&features); err != nil {
+		t.Fatalf("decode features: %v", err)
+	}
+	if len(features) != len(config.KnownFeatures) {
+		t.Fatalf("expected every known flag, got %+v", features)
+	}
+	for _, feature := range features {
+		if feature.Enabled != (feature.Name == config.FeatureRecording) {
+			t.Errorf("unexpected flag %+v", feature)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/system/info", nil))
+	var info struct {
+		Features []string `json:"features"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&info); err != nil {
+		t.Fatalf("decode system info: %v", err)
+	}
+	if len(info.Features) != 1 || info.Features[0] != config.FeatureRecording {
+		t.Errorf("expected recording in system info, got %v", info.Features)
+	}
+}
@@ -0,0 +1,61 @@
+package web
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+func TestGroupsAPI(t *testing.T) {
+	s, _ := newTestServer(t)
+	s.repeaterManager.AddRepeater("W1AW", &net.UDPAddr{IP: net.ParseIP("192.0.2.10"), Port: 42000})
+	s.repeaterManager.AddRepeater("K2XX", &net.UDPAddr{IP: net.ParseIP("192.0.2.11"), Port: 42000})
+	router := s.setupRoutes()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/api/groups/east",
+		strings.NewReader(`{"callsigns":["w1*"],"no_bridge":true}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT group: %d %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Groups []repeater.GroupStatus `json:"groups"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decode groups: %v", err)
+	}
+	if len(body.Groups) != 1 || len(body.Groups[0].Members) != 1 || body.Groups[0].Members[0] != "W1AW" {
+		t.Fatalf("unexpected groups %+v", body.Groups)
+	}
+
+	rec = httptest.NewRecorder()
+	s.handleRepeaters(rec, httptest.NewRequest(http.MethodGet, "/api/repeaters?group=east", nil))
+	var page struct {
+		Repeaters []repeater.RepeaterStats `json:"repeaters"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&page); err != nil || len(page.Repeaters) != 1 || page.Repeaters[0].Callsign != "W1AW" {
+		t.Fatalf("expected only W1AW in the filtered view, got %+v (%v)", page.Repeaters, err)
+	}
+
+	rec = httptest.NewRecorder()
+	s.handleRepeaters(rec, httptest.NewRequest(http.MethodGet, "/api/repeaters?group=west", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown group, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/groups/west", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 deleting an unknown group, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/groups/east", nil))
+	if rec.Code != http.StatusOK || len(s.repeaterManager.GetGroups()) != 0 {
+		t.Errorf("expected the group to be deleted, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,76 @@
+package web
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// SetListenFunc replaces how the listener for each web address is opened,
+// e.g. to take over a listener inherited from a previous process. Must be
+// called before Start.
+func (s *Server) SetListenFunc(fn func(address string) (net.Listener, error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listenFunc = fn
+}
+
+// ListenerFiles returns duplicates of the listeners' file descriptors, for
+// handing them to another process. The caller closes them.
+func (s *Server) ListenerFiles() ([]*os.File, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	files := make([]*os.File, 0, len(s.listeners))
+	for _, l := range s.listeners {
+		tl, ok := l.(interface{ File() (*os.File, error) })
+		if !ok {
+			continue
+		}
+		f, err := tl.File()
+		if err != nil {
+			for _, opened := range files {
+				_ = opened.Close()
+			}
+			return nil, fmt.Errorf("failed to duplicate listener %s: %w", l.Addr(), err)
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// Session is a login session, as handed to a new process
+type Session struct {
+	User   string
+	Role   string
+	Expiry time.Time
+}
+
+// Sessions returns the unexpired login sessions by token
+func (s *Server) Sessions() map[string]Session {
+	now := time.Now()
+	s.sessionsMu.RLock()
+	defer s.sessionsMu.RUnlock()
+
+	sessions := make(map[string]Session, len(s.sessions))
+	for token, sess := range s.sessions {
+		if now.Before(sess.expiry) {
+			sessions[token] = Session{User: sess.user, Role: sess.role, Expiry: sess.expiry}
+		}
+	}
+	return sessions
+}
+
+// RestoreSessions adds login sessions taken over from a previous process, so
+// dashboard users stay logged in across an upgrade
+func (s *Server) RestoreSessions(sessions map[string]Session) {
+	now := time.Now()
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+	for token, sess := range sessions {
+		if now.Before(sess.Expiry) {
+			s.sessions[token] = session{user: sess.User, role: sess.Role, expiry: sess.Expiry}
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/persist"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+// Activity heatmap. Talk time is kept in hourly buckets per source for a
+// rolling window of weeks; /api/stats/heatmap folds the buckets into the 168
+// hours of the week so the dashboard can show when the reflector is busiest
+// and which links carry the traffic.
+
+const (
+	// HeatmapLocal is the source of transmissions from repeaters linked directly
+	HeatmapLocal = "local"
+	// hoursPerWeek is the number of heatmap cells per source
+	hoursPerWeek = 7 * 24
+	// defaultHeatmapWeeks is the window used when web.heatmap_weeks is unset
+	defaultHeatmapWeeks = 4
+)
+
+// Heatmap is talk time per hour of the week. Hours are indexed by
+// weekday*24 + hour in the reflector's time zone, with Sunday as day 0.
+type Heatmap struct {
+	Weeks    int             `json:"weeks"`
+	From     time.Time       `json:"from"`
+	To       time.Time       `json:"to"`
+	TimeZone string          `json:"time_zone"`
+	Total    []int64         `json:"total"` // Seconds per hour of the week, all sources
+	Sources  []HeatmapSource `json:"sources"`
+}
+
+// HeatmapSource is the talk time of one source: local or a bridge name
+type HeatmapSource struct {
+	Source   string  `json:"source"`
+	TalkTime int64   `json:"talk_time"` // in seconds
+	Hours    []int64 `json:"hours"`     // Seconds per hour of the week
+}
+
+// heatmapBucket is the talk time per source in one hour, as persisted
+type heatmapBucket struct {
+	Hour    time.Time        `json:"hour"`
+	Seconds map[string]int64 `json:"seconds"`
+}
+
+// heatmapTracker accumulates talk time from talk_end events in hourly
+// buckets, optionally persisted to a JSON file so they survive restarts
+type heatmapTracker struct {
+	mu      sync.Mutex
+	buckets map[int64]map[string]int64 // hour start (unix seconds) -> source -> seconds
+	weeks   int
+	path    string
+	dirty   bool
+}
+
+func newHeatmapTracker(path string, weeks int) *heatmapTracker {
+	if weeks <= 0 {
+		weeks = defaultHeatmapWeeks
+	}
+	return &heatmapTracker{
+		buckets: make(map[int64]map[string]int64),
+		weeks:   weeks,
+		path:    path,
+	}
+}
+
+// record adds a finished transmission, split across the hours it spans
+func (h *heatmapTracker) record(event repeater.Event) {
+	if event.Duration <= 0 {
+		return
+	}
+	source := event.Bridge
+	if source == "" {
+		source = HeatmapLocal
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	end := event.Timestamp
+	for from := end.Add(-event.Duration); from.Before(end); {
+		hour := from.Truncate(time.Hour)
+		next := hour.Add(time.Hour)
+		if next.After(end) {
+			next = end
+		}
+		bucket, ok := h.buckets[hour.Unix()]
+		if !ok {
+			bucket = make(map[string]int64)
+			h.buckets[hour.Unix()] = bucket
+		}
+		bucket[source] += int64(next.Sub(from).Seconds())
+		from = next
+	}
+	h.pruneLocked(end)
+	h.dirty = true
+}
+
+// pruneLocked drops buckets older than the window (assumes mutex is locked)
+func (h *heatmapTracker) pruneLocked(now time.Time) {
+	oldest := now.Truncate(time.Hour).Add(-time.Duration(h.weeks*hoursPerWeek-1) * time.Hour).Unix()
+	for hour := range h.buckets {
+		if hour < oldest {
+			delete(h.buckets, hour)
+			h.dirty = true
+		}
+	}
+}
+
+// snapshot folds the last weeks of buckets (at most the tracker's window)
+// into hours of the week in now's time zone
+func (h *heatmapTracker) snapshot(now time.Time, weeks int) Heatmap {
+	if weeks <= 0 || weeks > h.weeks {
+		weeks = h.weeks
+	}
+	to := now.Truncate(time.Hour).Add(time.Hour)
+	from := to.Add(-time.Duration(weeks*hoursPerWeek) * time.Hour)
+
+	heatmap := Heatmap{
+		Weeks:    weeks,
+		From:     from,
+		To:       to,
+		TimeZone: now.Location().String(),
+		Total:    make([]int64, hoursPerWeek),
+		Sources:  []HeatmapSource{},
+	}
+	sources := make(map[string]*HeatmapSource)
+
+	h.mu.Lock()
+	h.pruneLocked(now)
+	for hour, bucket := range h.buckets {
+		if hour < from.Unix() {
+			continue
+		}
+		at := time.Unix(hour, 0).In(now.Location())
+		cell := int(at.Weekday())*24 + at.Hour()
+		for name, seconds := range bucket {
+			source, ok := sources[name]
+			if !ok {
+				source = &HeatmapSource{Source: name, Hours: make([]int64, hoursPerWeek)}
+				sources[name] = source
+			}
+			source.Hours[cell] += seconds
+			source.TalkTime += seconds
+			heatmap.Total[cell] += seconds
+		}
+	}
+	h.mu.Unlock()
+
+	for _, source := range sources {
+		heatmap.Sources = append(heatmap.Sources, *source)
+	}
+	// Local first, then bridges by name
+	sort.Slice(heatmap.Sources, func(i, j int) bool {
+		a, b := heatmap.Sources[i].Source, heatmap.Sources[j].Source
+		if (a == HeatmapLocal) != (b == HeatmapLocal) {
+			return a == HeatmapLocal
+		}
+		return a < b
+	})
+	return heatmap
+}
+
+// load reads persisted buckets; a missing file is not an error
+func (h *heatmapTracker) load() error {
+	if h.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(h.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var entries []heatmapBucket
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", h.path, err)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, entry := range entries {
+		if len(entry.Seconds) > 0 {
+			h.buckets[entry.Hour.Unix()] = entry.Seconds
+		}
+	}
+	return nil
+}
+
+// save writes the buckets if they changed since the last save
+func (h *heatmapTracker) save(now time.Time) error {
+	if h.path == "" {
+		return nil
+	}
+
+	h.mu.Lock()
+	h.pruneLocked(now)
+	if !h.dirty {
+		h.mu.Unlock()
+		return nil
+	}
+	entries := make([]heatmapBucket, 0, len(h.buckets))
+	for hour, bucket := range h.buckets {
+		seconds := make(map[string]int64, len(bucket))
+		for source, n := range bucket {
+			seconds[source] = n
+		}
+		entries = append(entries, heatmapBucket{Hour: time.Unix(hour, 0).UTC(), Seconds: seconds})
+	}
+	h.dirty = false
+	h.mu.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Hour.Before(entries[j].Hour) })
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	return persist.WriteFile(h.path, ".heatmap-*.json", data)
+}
+
+// saveHeatmap writes the heatmap, logging failures
+func (s *Server) saveHeatmap() {
+	if err := s.heatmap.save(time.Now()); err != nil {
+		s.logger.Error("Failed to save activity heatmap", logger.Error(err))
+	}
+}
+
+// runHeatmapPersistence saves the heatmap every minute and once more on shutdown
+func (s *Server) runHeatmapPersistence(ctx context.Context) {
+	persist.Every(ctx, time.Minute, s.saveHeatmap)
+	s.saveHeatmap()
+}
+
+// handleHeatmap returns talk time per hour of the week and source. The
+// optional weeks query parameter narrows the window (default and maximum:
+// web.heatmap_weeks).
+func (s *Server) handleHeatmap(w http.ResponseWriter, r *http.Request) {
+	weeks := 0
+	if value := r.URL.Query().Get("weeks"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			http.Error(w, "Invalid weeks", http.StatusBadRequest)
+			return
+		}
+		weeks = n
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(s.heatmap.snapshot(time.Now(), weeks)); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
@@ -0,0 +1,99 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+func TestHeatmapTrackerRecord(t *testing.T) {
+	h := newHeatmapTracker("", 2)
+	// Wednesday 2025-10-01; the first transmission spans 13:59:30 to 14:00:30
+	wednesday := time.Date(2025, 10, 1, 14, 0, 30, 0, time.UTC)
+
+	h.record(repeater.Event{Callsign: "W1AW", Duration: time.Minute, Timestamp: wednesday})
+	h.record(repeater.Event{Callsign: "K8ABC", Bridge: "Regional", Duration: 20 * time.Second, Timestamp: wednesday.Add(time.Minute)})
+	// Outside the two-week window by the time of the snapshot
+	h.record(repeater.Event{Callsign: "W1AW", Duration: time.Minute, Timestamp: wednesday.AddDate(0, 0, -21)})
+
+	heatmap := h.snapshot(wednesday.Add(time.Hour), 0)
+	if heatmap.Weeks != 2 || heatmap.TimeZone != "UTC" {
+		t.Errorf("unexpected window: %+v", heatmap)
+	}
+	if len(heatmap.Sources) != 2 || heatmap.Sources[0].Source != HeatmapLocal || heatmap.Sources[1].Source != "Regional" {
+		t.Fatalf("unexpected sources: %+v", heatmap.Sources)
+	}
+
+	cell := 3*24 + 14
+	local := heatmap.Sources[0]
+	if local.TalkTime != 60 || local.Hours[cell-1] != 30 || local.Hours[cell] != 30 {
+		t.Errorf("unexpected local hours: %d, %d (total %d)", local.Hours[cell-1], local.Hours[cell], local.TalkTime)
+	}
+	if heatmap.Sources[1].Hours[cell] != 20 || heatmap.Total[cell] != 50 || heatmap.Total[cell-1] != 30 {
+		t.Errorf("unexpected totals: %d, %d", heatmap.Total[cell-1], heatmap.Total[cell])
+	}
+
+	// Narrowing the window to a week leaves the current transmissions
+	if narrowed := h.snapshot(wednesday.Add(time.Hour), 1); narrowed.Weeks != 1 || len(narrowed.Sources) != 2 {
+		t.Errorf("unexpected narrowed heatmap: %+v", narrowed)
+	}
+	// Three weeks later everything has rolled out of the window
+	if later := h.snapshot(wednesday.AddDate(0, 0, 21), 0); len(later.Sources) != 0 {
+		t.Errorf("expected an empty heatmap, got %+v", later.Sources)
+	}
+}
+
+func TestHeatmapTrackerPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "heatmap.json")
+	now := time.Date(2025, 10, 1, 14, 0, 0, 0, time.UTC)
+
+	h := newHeatmapTracker(path, 0)
+	h.record(repeater.Event{Callsign: "W1AW", Bridge: "Regional", Duration: 45 * time.Second, Timestamp: now})
+	if err := h.save(now); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	restored := newHeatmapTracker(path, 0)
+	if err := restored.load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	heatmap := restored.snapshot(now, 0)
+	if heatmap.Weeks != defaultHeatmapWeeks || len(heatmap.Sources) != 1 || heatmap.Sources[0].TalkTime != 45 {
+		t.Errorf("expected restored heatmap, got %+v", heatmap)
+	}
+
+	// A missing file is not an error
+	if err := newHeatmapTracker(filepath.Join(t.TempDir(), "missing.json"), 0).load(); err != nil {
+		t.Errorf("unexpected error for missing file: %v", err)
+	}
+}
+
+func TestHeatmapAPI(t *testing.T) {
+	s, _ := newTestServer(t)
+	router := s.setupRoutes()
+	s.handleEvent(repeater.Event{Type: repeater.EventTalkEnd, Callsign: "W1AW", Duration: 30 * time.Second, Timestamp: time.Now()})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/stats/heatmap", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var heatmap Heatmap
+	if err := json.NewDecoder(rec.Body).Decode(&heatmap); err != nil {
+		t.Fatalf("decode heatmap: %v", err)
+	}
+	if len(heatmap.Total) != hoursPerWeek || len(heatmap.Sources) != 1 || heatmap.Sources[0].Source != HeatmapLocal {
+		t.Errorf("unexpected heatmap: %+v", heatmap)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/stats/heatmap?weeks=0", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid weeks, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,69 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+// handleGetHold reports whether the reflector is holding for priority traffic
+func (s *Server) handleGetHold(w http.ResponseWriter, r *http.Request) {
+	if err := json.NewEncoder(w).Encode(s.repeaterManager.GetHold()); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+// handleStartHold puts the reflector on hold: only the listed callsigns may
+// transmit until the hold is lifted or expires
+func (s *Server) handleStartHold(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Callsigns []string `json:"callsigns"` // Cleared to talk, e.g. net control
+		Duration  string   `json:"duration"`  // Go duration, e.g. "15m"; empty uses server.hold.duration
+		Reason    string   `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(request.Callsigns) == 0 {
+		http.Error(w, "callsigns is required", http.StatusBadRequest)
+		return
+	}
+	var duration time.Duration
+	if request.Duration != "" {
+		d, err := time.ParseDuration(request.Duration)
+		if err != nil || d <= 0 {
+			http.Error(w, "Invalid duration", http.StatusBadRequest)
+			return
+		}
+		duration = d
+	}
+
+	status, err := s.repeaterManager.StartHold(request.Callsigns, duration, request.Reason)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, repeater.ErrHoldDuration) {
+			code = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+// handleEndHold lifts the hold before it expires
+func (s *Server) handleEndHold(w http.ResponseWriter, r *http.Request) {
+	if !s.repeaterManager.EndHold() {
+		http.Error(w, "No hold is active", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
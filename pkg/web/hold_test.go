@@ -0,0 +1,50 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+func TestHoldAPI(t *testing.T) {
+	s, _ := newTestServer(t)
+	router := s.setupRoutes()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/hold", strings.NewReader(`{"callsigns":[]}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without callsigns, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/hold",
+		strings.NewReader(`{"callsigns":["w1nc"],"duration":"15m","reason":"emergency traffic"}`)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST hold: %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/hold", nil))
+	var status repeater.HoldStatus
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+		t.Fatalf("decode hold: %v", err)
+	}
+	if !status.Active || len(status.Callsigns) != 1 || status.Callsigns[0] != "W1NC" || status.Reason != "emergency traffic" {
+		t.Fatalf("unexpected hold %+v", status)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/hold", nil))
+	if rec.Code != http.StatusNoContent || s.repeaterManager.GetHold().Active {
+		t.Errorf("expected the hold to be lifted, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/hold", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 without a hold, got %d", rec.Code)
+	}
+}
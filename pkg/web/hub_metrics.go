@@ -0,0 +1,54 @@
+package web
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// writeWait bounds a single WebSocket write. A client that cannot take a
+// message within it is disconnected rather than stalling every other client.
+const writeWait = 5 * time.Second
+
+// hubMetrics counts WebSocket hub activity
+type hubMetrics struct {
+	queued          atomic.Uint64 // messages handed to the run loop
+	dropped         atomic.Uint64 // messages dropped because the hub channel was full
+	sent            atomic.Uint64 // successful writes to a client
+	slowDisconnects atomic.Uint64 // clients dropped after a failed or timed-out write
+}
+
+// WebSocketStats reports WebSocket hub health
+type WebSocketStats struct {
+	Clients               int    `json:"clients"`
+	MessagesQueued        uint64 `json:"messages_queued"`
+	MessagesSent          uint64 `json:"messages_sent"`
+	MessagesDropped       uint64 `json:"messages_dropped"`
+	SlowClientDisconnects uint64 `json:"slow_client_disconnects"`
+}
+
+// WebSocketStats returns a snapshot of the WebSocket hub counters
+func (s *Server) WebSocketStats() WebSocketStats {
+	hub := s.websocketHub
+	return WebSocketStats{
+		Clients:               hub.clientCount(),
+		MessagesQueued:        hub.metrics.queued.Load(),
+		MessagesSent:          hub.metrics.sent.Load(),
+		MessagesDropped:       hub.metrics.dropped.Load(),
+		SlowClientDisconnects: hub.metrics.slowDisconnects.Load(),
+	}
+}
+
+// write sends a message to a client, disconnecting it if the write fails or
+// times out. Caller must hold hub.mu.
+func (hub *WebSocketHub) write(client *websocket.Conn, data []byte) {
+	_ = client.SetWriteDeadline(time.Now().Add(writeWait))
+	if err := client.WriteMessage(websocket.TextMessage, data); err != nil {
+		hub.metrics.slowDisconnects.Add(1)
+		delete(hub.clients, client)
+		hub.closeClient(client)
+		return
+	}
+	hub.metrics.sent.Add(1)
+}
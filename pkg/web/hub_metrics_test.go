@@ -0,0 +1,18 @@
+package web
+
+import "testing"
+
+func TestWebSocketStatsCountsDrops(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	// With no run loop draining the hub, messages beyond the channel capacity are dropped
+	capacity := cap(s.websocketHub.broadcast)
+	for i := 0; i < capacity+3; i++ {
+		s.broadcastWebSocketMessage("event", i)
+	}
+
+	stats := s.WebSocketStats()
+	if stats.MessagesQueued != uint64(capacity) || stats.MessagesDropped != 3 {
+		t.Errorf("expected %d queued and 3 dropped, got %+v", capacity, stats)
+	}
+}
@@ -0,0 +1,40 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+// IP addresses in API responses and WebSocket messages are masked at the
+// level set by web.ip_masking. Logged-in operators, i.e. admins, see them
+// unmasked in API responses; viewers see what visitors see, since SSO users
+// in no group may be viewers by default_role. WebSocket broadcasts are shared
+// by all clients and always use the configured level.
+
+// isOperator reports whether the request comes from a logged-in admin.
+// Without auth_required nobody logs in, so there are no operators.
+func (s *Server) isOperator(r *http.Request) bool {
+	if !s.config.Web.AuthRequired {
+		return false
+	}
+	sess, ok := s.authenticate(r)
+	return ok && sess.role == config.RoleAdmin
+}
+
+// guestMaskLevel returns the configured mask level for everyone but operators
+func (s *Server) guestMaskLevel() string {
+	if s.config.Web.IPMasking == "" {
+		return repeater.MaskPartial
+	}
+	return s.config.Web.IPMasking
+}
+
+// maskLevel returns the mask level for addresses in a response to r
+func (s *Server) maskLevel(r *http.Request) string {
+	if s.isOperator(r) {
+		return repeater.MaskNone
+	}
+	return s.guestMaskLevel()
+}
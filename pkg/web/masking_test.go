@@ -0,0 +1,67 @@
+package web
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+func TestRepeatersMaskLevel(t *testing.T) {
+	s, _ := newTestServer(t)
+	s.repeaterManager.AddRepeater("W1AW", &net.UDPAddr{IP: net.ParseIP("192.0.2.10"), Port: 42000})
+	s.config.Web.IPMasking = repeater.MaskFull
+	s.config.Web.AuthRequired = true
+	s.sessions["token"] = session{role: config.RoleAdmin, expiry: time.Now().Add(time.Hour)}
+
+	address := func(req *http.Request) string {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		s.handleRepeaters(rec, req)
+		var body struct {
+			Repeaters []repeater.RepeaterStats `json:"repeaters"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || len(body.Repeaters) != 1 {
+			t.Fatalf("decode repeaters: %v (%d repeaters)", err, len(body.Repeaters))
+		}
+		return body.Repeaters[0].Address
+	}
+
+	if got := address(httptest.NewRequest(http.MethodGet, "/api/repeaters", nil)); got != "**:42000" {
+		t.Errorf("expected fully masked address for a visitor, got %q", got)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/repeaters", nil)
+	req.AddCookie(&http.Cookie{Name: "session_token", Value: "token"})
+	if got := address(req); got != "192.0.2.10:42000" {
+		t.Errorf("expected unmasked address for an operator, got %q", got)
+	}
+
+	// Viewers, such as SSO users in no group under a viewer default_role,
+	// see what visitors see
+	s.sessions["viewer"] = session{role: config.RoleViewer, expiry: time.Now().Add(time.Hour)}
+	req = httptest.NewRequest(http.MethodGet, "/api/repeaters", nil)
+	req.AddCookie(&http.Cookie{Name: "session_token", Value: "viewer"})
+	if got := address(req); got != "**:42000" {
+		t.Errorf("expected fully masked address for a viewer, got %q", got)
+	}
+}
+
+func TestMapRoleDefaultsToNone(t *testing.T) {
+	auth := config.AuthConfig{AdminGroups: []string{"ysf-admins"}, ViewerGroups: []string{"ysf-viewers"}}
+	if got := mapRole(auth, []string{"users"}); got != config.RoleNone {
+		t.Errorf("role of a user in no group = %q, want none", got)
+	}
+	if got := mapRole(auth, []string{"ysf-viewers"}); got != config.RoleViewer {
+		t.Errorf("role of a viewer group member = %q, want viewer", got)
+	}
+	auth.DefaultRole = config.RoleViewer
+	if got := mapRole(auth, nil); got != config.RoleViewer {
+		t.Errorf("role with a viewer default_role = %q, want viewer", got)
+	}
+}
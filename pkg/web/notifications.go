@@ -0,0 +1,303 @@
+package web
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+// Watch-list notifications. Every dashboard WebSocket connection belongs to a
+// notification session, announced to the client in a "session" message; a
+// client resumes its session by connecting to /ws?session=<id>. An event of a
+// configured type for a callsign on the server-wide watch list notifies every
+// client, while a callsign on a session's own watch list only notifies the
+// clients of that session.
+
+// sessionIdleTTL is how long a watch list is kept once its session has no clients
+const sessionIdleTTL = 24 * time.Hour
+
+// errUnknownSession is returned for a session id the server does not know
+var errUnknownSession = errors.New("unknown notification session")
+
+// Notification is pushed to dashboard clients in a "notification" message
+type Notification struct {
+	Event     string    `json:"event"`
+	Callsign  string    `json:"callsign"`
+	Watched   string    `json:"watched"`
+	Gateway   string    `json:"gateway,omitempty"`
+	Bridge    string    `json:"bridge,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// WatchList is the notification state of a session
+type WatchList struct {
+	Session  string   `json:"session"`
+	Watch    []string `json:"watch"`
+	Global   []string `json:"global"`
+	Events   []string `json:"events"`
+	MaxWatch int      `json:"max_watch"`
+}
+
+// watchSession is a dashboard notification session
+type watchSession struct {
+	watch     map[string]bool
+	clients   map[*websocket.Conn]bool
+	idleSince time.Time // when the last client left
+}
+
+// notifier matches events against the watch lists
+type notifier struct {
+	mu       sync.Mutex
+	events   []string
+	global   map[string]bool
+	maxWatch int
+	sessions map[string]*watchSession
+}
+
+func newNotifier(cfg config.NotificationConfig) *notifier {
+	global := make(map[string]bool, len(cfg.Watch))
+	for _, callsign := range cfg.Watch {
+		if key := watchKey(callsign); key != "" {
+			global[key] = true
+		}
+	}
+	return &notifier{
+		events:   cfg.Events,
+		global:   global,
+		maxWatch: cfg.MaxWatch,
+		sessions: make(map[string]*watchSession),
+	}
+}
+
+// watchKey normalizes a callsign for watch-list matching: upper case, without
+// any -SSID or /portable suffix, so watching W1AW also matches W1AW-7
+func watchKey(callsign string) string {
+	key := strings.ToUpper(strings.TrimSpace(callsign))
+	if i := strings.IndexAny(key, "-/"); i >= 0 {
+		key = key[:i]
+	}
+	return key
+}
+
+// validSessionID reports whether id has the format of a generated session id
+func validSessionID(id string) bool {
+	b, err := hex.DecodeString(id)
+	return err == nil && len(b) == 16
+}
+
+// attach adds a client to the session with the given id, or to a new session
+// if id is empty or malformed, and returns the session id
+func (n *notifier) attach(client *websocket.Conn, id string, now time.Time) (string, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.prune(now)
+
+	if !validSessionID(id) {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		id = hex.EncodeToString(b)
+	}
+
+	// A well-formed unknown id is adopted, so a client keeps its id across server restarts
+	session, ok := n.sessions[id]
+	if !ok {
+		session = &watchSession{watch: make(map[string]bool), clients: make(map[*websocket.Conn]bool)}
+		n.sessions[id] = session
+	}
+	session.clients[client] = true
+	return id, nil
+}
+
+// detach removes a client from its session
+func (n *notifier) detach(client *websocket.Conn, now time.Time) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for _, session := range n.sessions {
+		if session.clients[client] {
+			delete(session.clients, client)
+			if len(session.clients) == 0 {
+				session.idleSince = now
+			}
+			return
+		}
+	}
+}
+
+// prune drops sessions that have had no client for sessionIdleTTL. Caller must hold n.mu.
+func (n *notifier) prune(now time.Time) {
+	for id, session := range n.sessions {
+		if len(session.clients) == 0 && now.Sub(session.idleSince) > sessionIdleTTL {
+			delete(n.sessions, id)
+		}
+	}
+}
+
+// watchList returns the watch list of a session
+func (n *notifier) watchList(id string) (WatchList, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	session, ok := n.sessions[id]
+	if !ok {
+		return WatchList{}, errUnknownSession
+	}
+	return n.describe(id, session), nil
+}
+
+// setWatch replaces the watch list of a session
+func (n *notifier) setWatch(id string, callsigns []string) (WatchList, error) {
+	watch := make(map[string]bool, len(callsigns))
+	for _, callsign := range callsigns {
+		key := watchKey(callsign)
+		if len(key) < 3 || len(key) > 10 || strings.Trim(key, "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789") != "" {
+			return WatchList{}, fmt.Errorf("invalid callsign: %q", callsign)
+		}
+		watch[key] = true
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	session, ok := n.sessions[id]
+	if !ok {
+		return WatchList{}, errUnknownSession
+	}
+	if len(watch) > n.maxWatch {
+		return WatchList{}, fmt.Errorf("watch list is limited to %d callsigns", n.maxWatch)
+	}
+	session.watch = watch
+	return n.describe(id, session), nil
+}
+
+// describe builds the WatchList of a session. Caller must hold n.mu.
+func (n *notifier) describe(id string, session *watchSession) WatchList {
+	return WatchList{
+		Session:  id,
+		Watch:    sortedKeys(session.watch),
+		Global:   sortedKeys(n.global),
+		Events:   append([]string{}, n.events...),
+		MaxWatch: n.maxWatch,
+	}
+}
+
+// match returns the notification for an event and its recipients. all is true
+// when every client is notified; otherwise clients lists the recipients.
+func (n *notifier) match(event repeater.Event) (note Notification, clients []*websocket.Conn, all bool) {
+	notify := false
+	for _, t := range n.events {
+		if t == event.Type {
+			notify = true
+			break
+		}
+	}
+	key := watchKey(event.Callsign)
+	if !notify || key == "" {
+		return Notification{}, nil, false
+	}
+
+	note = Notification{
+		Event:     event.Type,
+		Callsign:  event.Callsign,
+		Watched:   key,
+		Gateway:   event.Gateway,
+		Bridge:    event.Bridge,
+		Timestamp: event.Timestamp,
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.global[key] {
+		return note, nil, true
+	}
+	for _, session := range n.sessions {
+		if !session.watch[key] {
+			continue
+		}
+		for client := range session.clients {
+			clients = append(clients, client)
+		}
+	}
+	return note, clients, false
+}
+
+// sortedKeys returns the keys of a set in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// notifyWatchers pushes a notification for an event to the clients watching its callsign
+func (s *Server) notifyWatchers(event repeater.Event) {
+	if s.notifier == nil {
+		return
+	}
+
+	note, clients, all := s.notifier.match(event)
+	switch {
+	case all:
+		s.broadcastWebSocketMessage("notification", note)
+	case len(clients) > 0:
+		s.sendWebSocketMessageTo(clients, "notification", note)
+	}
+}
+
+// handleGetWatchList returns the watch list of a notification session
+func (s *Server) handleGetWatchList(w http.ResponseWriter, r *http.Request) {
+	list, err := s.notifier.watchList(mux.Vars(r)["session"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(list); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+// handleUpdateWatchList replaces the watch list of a notification session
+func (s *Server) handleUpdateWatchList(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Watch []string `json:"watch"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	list, err := s.notifier.setWatch(mux.Vars(r)["session"], request.Watch)
+	if errors.Is(err, errUnknownSession) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(list); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
@@ -0,0 +1,163 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+func TestNotifierMatchesWatchLists(t *testing.T) {
+	n := newNotifier(config.NotificationConfig{
+		Enabled:  true,
+		Events:   []string{repeater.EventTalkStart},
+		Watch:    []string{"w1aw"},
+		MaxWatch: 2,
+	})
+	a, b := &websocket.Conn{}, &websocket.Conn{}
+	idA, _ := n.attach(a, "", time.Now())
+	if _, err := n.attach(b, "", time.Now()); err != nil {
+		t.Fatalf("attach: %v", err)
+	}
+	if _, err := n.setWatch(idA, []string{"K1ABC-7"}); err != nil {
+		t.Fatalf("setWatch: %v", err)
+	}
+
+	// Global watch list notifies everyone, regardless of suffix
+	if _, _, all := n.match(repeater.Event{Type: repeater.EventTalkStart, Callsign: "W1AW/P"}); !all {
+		t.Errorf("expected global watch to notify all clients")
+	}
+
+	// Session watch list only notifies that session
+	note, clients, all := n.match(repeater.Event{Type: repeater.EventTalkStart, Callsign: "K1ABC"})
+	if all || len(clients) != 1 || clients[0] != a || note.Watched != "K1ABC" {
+		t.Errorf("expected only the watching session, got all=%v clients=%d note=%+v", all, len(clients), note)
+	}
+
+	// Event types outside the rules are ignored
+	if _, clients, all := n.match(repeater.Event{Type: repeater.EventTalkEnd, Callsign: "K1ABC"}); all || len(clients) != 0 {
+		t.Errorf("expected no notification for talk_end")
+	}
+}
+
+func TestNotifierRejectsInvalidWatchLists(t *testing.T) {
+	n := newNotifier(config.NotificationConfig{Enabled: true, MaxWatch: 1})
+	id, _ := n.attach(&websocket.Conn{}, "", time.Now())
+
+	if _, err := n.setWatch("0123456789abcdef0123456789abcdef", []string{"W1AW"}); err != errUnknownSession {
+		t.Errorf("expected errUnknownSession, got %v", err)
+	}
+	if _, err := n.setWatch(id, []string{"W1AW", "K1ABC"}); err == nil {
+		t.Errorf("expected error above max_watch")
+	}
+	if _, err := n.setWatch(id, []string{"not a call"}); err == nil {
+		t.Errorf("expected error for an invalid callsign")
+	}
+}
+
+func TestNotifierResumesAndPrunesSessions(t *testing.T) {
+	n := newNotifier(config.NotificationConfig{Enabled: true, MaxWatch: 5})
+	now := time.Now()
+	client := &websocket.Conn{}
+	id, _ := n.attach(client, "", now)
+	if _, err := n.setWatch(id, []string{"W1AW"}); err != nil {
+		t.Fatalf("setWatch: %v", err)
+	}
+	n.detach(client, now)
+
+	// Reconnecting with the id keeps the watch list
+	if got, _ := n.attach(&websocket.Conn{}, id, now.Add(time.Hour)); got != id {
+		t.Fatalf("expected session %s to resume, got %s", id, got)
+	}
+	if list, _ := n.watchList(id); len(list.Watch) != 1 {
+		t.Errorf("expected resumed watch list, got %v", list.Watch)
+	}
+
+	// An idle session is dropped after the TTL
+	other := &websocket.Conn{}
+	otherID, _ := n.attach(other, "", now)
+	n.detach(other, now)
+	n.attach(&websocket.Conn{}, "", now.Add(sessionIdleTTL+time.Minute))
+	if _, err := n.watchList(otherID); err != errUnknownSession {
+		t.Errorf("expected idle session to be pruned, got %v", err)
+	}
+}
+
+func TestNotificationsOverWebSocket(t *testing.T) {
+	s, port := newTestServer(t)
+	s.notifier = newNotifier(config.NotificationConfig{Enabled: true, Events: []string{repeater.EventTalkStart}, MaxWatch: 5})
+
+	go func() { _ = s.Start(context.Background()) }()
+	defer func() { _ = s.Stop() }()
+	waitForHTTP(t, port)
+
+	dial := func() (*websocket.Conn, WatchList) {
+		conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://127.0.0.1:%d/ws", port), nil)
+		if err != nil {
+			t.Fatalf("websocket dial: %v", err)
+		}
+		list, ok := readMessage(t, conn, "session")
+		if !ok {
+			t.Fatalf("expected a session message")
+		}
+		var w WatchList
+		if err := json.Unmarshal(list, &w); err != nil {
+			t.Fatalf("decode session: %v", err)
+		}
+		return conn, w
+	}
+	watcher, session := dial()
+	defer func() { _ = watcher.Close() }()
+	other, _ := dial()
+	defer func() { _ = other.Close() }()
+
+	body, _ := json.Marshal(map[string][]string{"watch": {"K1ABC"}})
+	req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("http://127.0.0.1:%d/api/watchlist/%s", port, session.Session), bytes.NewReader(body))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("put watch list: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	// Wait for both clients to be registered with the hub
+	for i := 0; i < 50 && s.websocketHub.clientCount() < 2; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	s.handleEvent(repeater.Event{Type: repeater.EventTalkStart, Callsign: "K1ABC-9", Timestamp: time.Now()})
+
+	if _, ok := readMessage(t, watcher, "notification"); !ok {
+		t.Errorf("expected the watching client to be notified")
+	}
+	if _, ok := readMessage(t, other, "notification"); ok {
+		t.Errorf("expected the other client not to be notified")
+	}
+}
+
+// readMessage reads messages from conn until one of the given type arrives
+func readMessage(t *testing.T, conn *websocket.Conn, messageType string) (json.RawMessage, bool) {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(500 * time.Millisecond))
+	for {
+		var msg struct {
+			Type string          `json:"type"`
+			Data json.RawMessage `json:"data"`
+		}
+		if err := conn.ReadJSON(&msg); err != nil {
+			return nil, false
+		}
+		if msg.Type == messageType {
+			return msg.Data, true
+		}
+	}
+}
@@ -0,0 +1,407 @@
+package web
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+)
+
+// OpenID Connect sign-in uses the authorization code flow with PKCE. The ID
+// token is received directly from the provider's token endpoint over TLS,
+// which OpenID Connect Core (section 3.1.3.7) accepts in place of checking
+// its signature; its issuer, audience, expiry and nonce are checked. That
+// only holds when the discovery document and the token come over https, so
+// the issuer and the endpoints it lists must use it, except on loopback.
+
+const (
+	// oidcLoginTTL is how long a user has to complete a sign-in at the provider
+	oidcLoginTTL = 10 * time.Minute
+	// oidcMaxPending caps the sign-ins waiting for their callback
+	oidcMaxPending = 1000
+	// oidcStateCookie ties a sign-in to the browser that started it
+	oidcStateCookie = "oidc_state"
+	// oidcCookiePath scopes the state cookie to the sign-in endpoints
+	oidcCookiePath = "/api/auth/oidc"
+)
+
+// oidcDiscovery holds the provider endpoints from its discovery document
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+}
+
+// oidcLogin is a sign-in waiting for the provider's callback
+type oidcLogin struct {
+	nonce    string
+	verifier string // PKCE code verifier
+	expiry   time.Time
+}
+
+// oidcIdentity is a signed-in user as described by the ID token
+type oidcIdentity struct {
+	user   string
+	groups []string
+}
+
+// oidcClient signs users in with an OpenID Connect provider
+type oidcClient struct {
+	config config.OIDCAuthConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery       // Fetched on the first sign-in
+	pending   map[string]oidcLogin // by state
+}
+
+// newOIDCClient creates a client for the configured provider
+func newOIDCClient(cfg config.OIDCAuthConfig) *oidcClient {
+	return &oidcClient{
+		config:  cfg,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		pending: make(map[string]oidcLogin),
+	}
+}
+
+// randomString returns n random bytes encoded for use in URLs
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// discover returns the provider endpoints, fetching them on first use
+func (c *oidcClient) discover(ctx context.Context) (*oidcDiscovery, error) {
+	c.mu.Lock()
+	cached := c.discovery
+	c.mu.Unlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	if !config.SecureURL(c.config.Issuer) {
+		return nil, fmt.Errorf("issuer %q does not use https", c.config.Issuer)
+	}
+	issuer := strings.TrimSuffix(c.config.Issuer, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch discovery document: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("discovery document: %s", resp.Status)
+	}
+
+	var discovery oidcDiscovery
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&discovery); err != nil {
+		return nil, fmt.Errorf("decode discovery document: %w", err)
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("discovery issuer %q does not match %q", discovery.Issuer, c.config.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" {
+		return nil, errors.New("discovery document lacks authorization or token endpoint")
+	}
+	for _, endpoint := range []string{discovery.AuthorizationEndpoint, discovery.TokenEndpoint} {
+		if !config.SecureURL(endpoint) {
+			return nil, fmt.Errorf("provider endpoint %q does not use https", endpoint)
+		}
+	}
+
+	c.mu.Lock()
+	c.discovery = &discovery
+	c.mu.Unlock()
+	return &discovery, nil
+}
+
+// scopes returns the requested scopes, openid first
+func (c *oidcClient) scopes() string {
+	scopes := []string{"openid"}
+	for _, scope := range c.config.Scopes {
+		if scope != "" && scope != "openid" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return strings.Join(scopes, " ")
+}
+
+// authURL starts a sign-in. It returns the provider URL to send the user to
+// and the state identifying the sign-in on the callback.
+func (c *oidcClient) authURL(ctx context.Context, now time.Time) (string, string, error) {
+	discovery, err := c.discover(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	endpoint, err := url.Parse(discovery.AuthorizationEndpoint)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid authorization endpoint: %w", err)
+	}
+
+	var state, nonce, verifier string
+	for _, value := range []*string{&state, &nonce, &verifier} {
+		if *value, err = randomString(32); err != nil {
+			return "", "", err
+		}
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+
+	c.mu.Lock()
+	for key, login := range c.pending {
+		if now.After(login.expiry) {
+			delete(c.pending, key)
+		}
+	}
+	if len(c.pending) >= oidcMaxPending {
+		c.mu.Unlock()
+		return "", "", errors.New("too many sign-ins in progress")
+	}
+	c.pending[state] = oidcLogin{nonce: nonce, verifier: verifier, expiry: now.Add(oidcLoginTTL)}
+	c.mu.Unlock()
+
+	query := endpoint.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", c.config.ClientID)
+	query.Set("redirect_uri", c.config.RedirectURL)
+	query.Set("scope", c.scopes())
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+	endpoint.RawQuery = query.Encode()
+	return endpoint.String(), state, nil
+}
+
+// exchange completes the sign-in identified by state with the authorization
+// code from the callback and returns the signed-in user
+func (c *oidcClient) exchange(ctx context.Context, state, code string, now time.Time) (oidcIdentity, error) {
+	c.mu.Lock()
+	login, ok := c.pending[state]
+	delete(c.pending, state)
+	discovery := c.discovery
+	c.mu.Unlock()
+	if !ok || now.After(login.expiry) || discovery == nil {
+		return oidcIdentity{}, errors.New("unknown or expired sign-in")
+	}
+	if code == "" {
+		return oidcIdentity{}, errors.New("callback without authorization code")
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", c.config.RedirectURL)
+	form.Set("client_id", c.config.ClientID)
+	form.Set("code_verifier", login.verifier)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return oidcIdentity{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(c.config.ClientID), url.QueryEscape(c.config.ClientSecret))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return oidcIdentity{}, fmt.Errorf("token request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return oidcIdentity{}, fmt.Errorf("token endpoint: %s", resp.Status)
+	}
+	var token struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&token); err != nil {
+		return oidcIdentity{}, fmt.Errorf("decode token response: %w", err)
+	}
+	if token.IDToken == "" {
+		return oidcIdentity{}, errors.New("token response without ID token")
+	}
+
+	claims, err := parseIDToken(token.IDToken)
+	if err != nil {
+		return oidcIdentity{}, err
+	}
+	if err := c.verify(claims, discovery.Issuer, login.nonce, now); err != nil {
+		return oidcIdentity{}, err
+	}
+	return c.identity(claims), nil
+}
+
+// parseIDToken returns the claims of a JWT ID token
+func parseIDToken(raw string) (map[string]interface{}, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed ID token")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("decode ID token: %w", err)
+	}
+	var claims map[string]interface{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("decode ID token claims: %w", err)
+	}
+	return claims, nil
+}
+
+// verify checks that the ID token was issued by the provider for this client
+// and this sign-in, and has not expired
+func (c *oidcClient) verify(claims map[string]interface{}, issuer, nonce string, now time.Time) error {
+	if iss, _ := claims["iss"].(string); iss != issuer {
+		return fmt.Errorf("ID token issuer %q does not match %q", iss, issuer)
+	}
+	audience := false
+	for _, aud := range claimStrings(claims["aud"]) {
+		audience = audience || aud == c.config.ClientID
+	}
+	if !audience {
+		return errors.New("ID token not issued for this client")
+	}
+	if exp, ok := claims["exp"].(float64); !ok || !now.Before(time.Unix(int64(exp), 0)) {
+		return errors.New("ID token expired")
+	}
+	if got, _ := claims["nonce"].(string); got != nonce {
+		return errors.New("ID token nonce does not match")
+	}
+	return nil
+}
+
+// identity returns the user name and groups from the ID token claims
+func (c *oidcClient) identity(claims map[string]interface{}) oidcIdentity {
+	user, _ := lookupClaim(claims, c.config.UsernameClaim).(string)
+	if user == "" {
+		user, _ = claims["sub"].(string)
+	}
+	return oidcIdentity{user: user, groups: claimStrings(lookupClaim(claims, c.config.GroupsClaim))}
+}
+
+// lookupClaim returns a claim by name, or by a dotted path into nested
+// claims such as Keycloak's realm_access.roles
+func lookupClaim(claims map[string]interface{}, name string) interface{} {
+	if name == "" {
+		return nil
+	}
+	if value, ok := claims[name]; ok {
+		return value
+	}
+	var value interface{} = claims
+	for _, key := range strings.Split(name, ".") {
+		nested, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = nested[key]
+	}
+	return value
+}
+
+// claimStrings returns a claim holding a string or a list of strings
+func claimStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// handleOIDCLogin sends the user to the provider to sign in
+func (s *Server) handleOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	authURL, state, err := s.oidc.authURL(r.Context(), time.Now())
+	if err != nil {
+		s.logger.Error("Failed to start OIDC sign-in", logger.Error(err))
+		http.Error(w, "Single sign-on unavailable", http.StatusBadGateway)
+		return
+	}
+
+	// Lax so the cookie comes back on the provider's redirect
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		MaxAge:   int(oidcLoginTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+		Path:     oidcCookiePath,
+	})
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// handleOIDCCallback completes a sign-in and starts a session for the user
+func (s *Server) handleOIDCCallback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if reason := query.Get("error"); reason != "" {
+		s.logger.Warn("OIDC sign-in failed",
+			logger.String("error", reason),
+			logger.String("description", query.Get("error_description")),
+			logger.String("remote_addr", r.RemoteAddr))
+		http.Error(w, "Sign-in failed", http.StatusUnauthorized)
+		return
+	}
+
+	state := query.Get("state")
+	cookie, err := r.Cookie(oidcStateCookie)
+	if err != nil || state == "" || cookie.Value != state {
+		http.Error(w, "Invalid sign-in state", http.StatusBadRequest)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: oidcStateCookie, Value: "", MaxAge: -1, Path: oidcCookiePath})
+
+	identity, err := s.oidc.exchange(r.Context(), state, query.Get("code"), time.Now())
+	if err != nil {
+		s.logger.Warn("OIDC sign-in failed", logger.Error(err), logger.String("remote_addr", r.RemoteAddr))
+		http.Error(w, "Sign-in failed", http.StatusUnauthorized)
+		return
+	}
+
+	role := mapRole(s.config.Web.Auth, identity.groups)
+	if role == config.RoleNone {
+		s.logger.Warn("OIDC user has no dashboard role", logger.String("username", identity.user), logger.String("remote_addr", r.RemoteAddr))
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return
+	}
+
+	token, expiry, err := s.newSession(identity.user, role)
+	if err != nil {
+		s.logger.Error("Failed to generate session token", logger.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	s.logger.Info("Successful login",
+		logger.String("username", identity.user),
+		logger.String("role", role),
+		logger.String("remote_addr", r.RemoteAddr))
+
+	setSessionCookie(w, r, token, expiry)
+	http.Redirect(w, r, "/", http.StatusFound)
+}
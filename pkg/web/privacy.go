@@ -0,0 +1,84 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/gorilla/mux"
+)
+
+// ForgetReport lists what was removed for a callsign by a privacy request
+type ForgetReport struct {
+	Callsign string `json:"callsign"`
+	TalkLogs int    `json:"talk_logs"` // Talk log entries removed
+	Events   int    `json:"events"`    // Replayable events removed
+	Stats    bool   `json:"stats"`     // Whether lifetime statistics were removed
+}
+
+// PruneEvents drops replayable events older than maxAge and returns how many were dropped
+func (s *Server) PruneEvents(maxAge time.Duration) int {
+	if maxAge <= 0 {
+		return 0
+	}
+	return s.events.Prune(maxAge)
+}
+
+// ScrubAddresses blanks the IP addresses of replayable events older than
+// maxAge and returns how many were scrubbed
+func (s *Server) ScrubAddresses(maxAge time.Duration) int {
+	if maxAge <= 0 {
+		return 0
+	}
+	return s.events.ScrubAddresses(maxAge)
+}
+
+// ForgetCallsign removes the history of a callsign: its talk log entries,
+// replayable events and lifetime statistics. Persisted statistics are
+// rewritten at once.
+func (s *Server) ForgetCallsign(callsign string) ForgetReport {
+	callsign = strings.ToUpper(strings.TrimSpace(callsign))
+	report := ForgetReport{Callsign: callsign}
+
+	s.mu.Lock()
+	kept := s.talkLogs[:0]
+	for _, entry := range s.talkLogs {
+		if strings.EqualFold(entry.Callsign, callsign) {
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	report.TalkLogs = len(s.talkLogs) - len(kept)
+	clear(s.talkLogs[len(kept):])
+	s.talkLogs = kept
+	s.mu.Unlock()
+
+	report.Events = s.events.Forget(callsign)
+
+	if report.Stats = s.callsigns.forget(callsign); report.Stats {
+		if err := s.callsigns.save(); err != nil {
+			s.logger.Error("Failed to save callsign statistics", logger.Error(err))
+		}
+	}
+
+	s.logger.Info("Callsign history removed",
+		logger.String("callsign", callsign),
+		logger.Int("talk_logs", report.TalkLogs),
+		logger.Int("events", report.Events))
+	return report
+}
+
+// handleForgetCallsign removes a callsign's history, e.g. for a privacy request
+func (s *Server) handleForgetCallsign(w http.ResponseWriter, r *http.Request) {
+	callsign := strings.TrimSpace(mux.Vars(r)["callsign"])
+	if callsign == "" {
+		http.Error(w, "callsign is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(s.ForgetCallsign(callsign)); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
@@ -0,0 +1,60 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+func TestForgetCallsign(t *testing.T) {
+	s, _ := newTestServer(t)
+	router := s.setupRoutes()
+
+	now := time.Now()
+	s.talkLogs = []TalkLogEntry{
+		{Callsign: "W1AW", Timestamp: now},
+		{Callsign: "K8ABC", Timestamp: now.Add(-time.Minute)},
+		{Callsign: "w1aw", Timestamp: now.Add(-2 * time.Minute)},
+	}
+	s.callsigns.record(repeater.Event{Callsign: "W1AW", Duration: 30 * time.Second, Timestamp: now})
+	s.events.Publish(repeater.Event{Type: repeater.EventConnect, Callsign: "W1AW", Address: "192.0.2.1:42000"})
+	s.events.Publish(repeater.Event{Type: repeater.EventConnect, Callsign: "K8ABC", Address: "192.0.2.2:42000"})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/callsigns/w1aw", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("DELETE callsign: %d %s", rec.Code, rec.Body.String())
+	}
+	var report ForgetReport
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if report.Callsign != "W1AW" || report.TalkLogs != 2 || report.Events != 1 || !report.Stats {
+		t.Errorf("unexpected report %+v", report)
+	}
+
+	if len(s.talkLogs) != 1 || s.talkLogs[0].Callsign != "K8ABC" {
+		t.Errorf("unexpected talk logs %+v", s.talkLogs)
+	}
+	if _, ok := s.callsigns.get("W1AW"); ok {
+		t.Error("expected W1AW statistics to be removed")
+	}
+	replay := s.events.Subscribe(10, now.Add(-time.Minute))
+	if len(replay.C) != 1 {
+		t.Fatalf("expected 1 replayable event, got %d", len(replay.C))
+	}
+	if event := <-replay.C; event.Callsign != "K8ABC" {
+		t.Errorf("unexpected remaining event %+v", event)
+	}
+
+	// The public lookup still answers GET on the same path
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/callsigns/W1AW", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for a forgotten callsign, got %d", rec.Code)
+	}
+}
@@ -7,11 +7,12 @@ import (
 	"embed"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
+	"net"
 	"net/http"
-	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -20,22 +21,23 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 
+	"github.com/dbehnke/ysf-nexus/pkg/alerting"
 	"github.com/dbehnke/ysf-nexus/pkg/bridge"
 	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/dmrid"
+	"github.com/dbehnke/ysf-nexus/pkg/health"
+	"github.com/dbehnke/ysf-nexus/pkg/links"
 	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/mirror"
+	"github.com/dbehnke/ysf-nexus/pkg/network"
+	"github.com/dbehnke/ysf-nexus/pkg/ntpcheck"
 	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+	"github.com/dbehnke/ysf-nexus/pkg/version"
 )
 
 //go:embed dist
 var staticFiles embed.FS
 
-// maskIPAddress masks the last two octets of an IP address for privacy
-// Example: 192.168.1.100:42000 -> 192.168.**:42000
-func maskIPAddress(address string) string {
-	re := regexp.MustCompile(`^(\d+\.\d+\.)\d+\.\d+(:\d+)?$`)
-	return re.ReplaceAllString(address, "${1}**${2}")
-}
-
 // Server represents the web dashboard server
 type Server struct {
 	config          *config.Config
@@ -44,24 +46,79 @@ type Server struct {
 	repeaterManager *repeater.Manager
 	bridgeManager   interface{}
 	reflector       interface{}
-	eventChan       <-chan repeater.Event
+	alerts          *alerting.Manager
+	mirror          *mirror.Mirror
+	externalLinks   *links.Registry
+	clockCheck      *ntpcheck.Checker
+	dmrIDs          *dmrid.Directory
+	runtime         *health.Monitor
+	events          *repeater.EventBus
 	talkLogs        []TalkLogEntry
+	callsigns       *callsignTracker
+	heatmap         *heatmapTracker
 	websocketHub    *WebSocketHub
 	startTime       time.Time
 	version         string
 	buildTime       string
 	mu              sync.RWMutex
 	running         bool
-	sessions        map[string]time.Time // session token -> expiry time
+	cancel          context.CancelFunc // stops the current run started by Start
+	unsubscribedAt  time.Time          // when the last run stopped consuming events
+	sessions        map[string]session // by session token
 	sessionsMu      sync.RWMutex
+	// oidc signs users in with an OpenID Connect provider; nil unless the oidc backend is used
+	oidc *oidcClient
+
+	// notifier matches events against dashboard watch lists; nil when notifications are disabled
+	notifier *notifier
+	// ready is closed once Start has bound every listen address; Stop replaces it for the next run
+	ready chan struct{}
+	// traces holds the clients of the packet trace WebSocket
+	traces traceStream
+	// listenFunc opens the listener for an address; nil uses net.Listen (see handover.go)
+	listenFunc func(address string) (net.Listener, error)
+	// listeners are the listeners of the current run
+	listeners []net.Listener
 }
 
 // TalkLogEntry represents a talk log entry
 type TalkLogEntry struct {
-	ID        int64     `json:"id"`
-	Callsign  string    `json:"callsign"`
-	Duration  int       `json:"duration"` // in seconds
-	Timestamp time.Time `json:"timestamp"`
+	ID        int64                   `json:"id"`
+	Callsign  string                  `json:"callsign"`
+	Duration  int                     `json:"duration"` // in seconds
+	Timestamp time.Time               `json:"timestamp"`
+	Quality   *repeater.QualityReport `json:"quality,omitempty"`
+	Gateway   string                  `json:"gateway,omitempty"`
+	Bridge    string                  `json:"bridge,omitempty"`
+	// SourceType is repeater, bridge or ysf2dmr
+	SourceType string `json:"source_type,omitempty"`
+	DGID       *uint8 `json:"dgid,omitempty"`
+	TalkGroup  uint32 `json:"talkgroup,omitempty"`
+	// Recipients is the number of repeaters and bridges the transmission reached
+	Recipients int `json:"recipients"`
+	// EndReason is the disconnect reason when the repeater dropped mid-transmission
+	EndReason string `json:"end_reason,omitempty"`
+}
+
+// newTalkLogEntry creates the talk log entry for a talk_end event
+func newTalkLogEntry(event repeater.Event) TalkLogEntry {
+	entry := TalkLogEntry{
+		ID:        time.Now().UnixNano(),
+		Callsign:  event.Callsign,
+		Duration:  int(event.Duration.Seconds()),
+		Timestamp: event.Timestamp,
+		Quality:   event.Quality,
+		Gateway:   event.Gateway,
+		Bridge:    event.Bridge,
+		EndReason: event.Reason,
+	}
+	if o := event.Origin; o != nil {
+		entry.SourceType = o.SourceType
+		entry.DGID = o.DGID
+		entry.TalkGroup = o.TalkGroup
+		entry.Recipients = o.Recipients
+	}
+	return entry
 }
 
 // WebSocketHub manages WebSocket connections
@@ -72,12 +129,29 @@ type WebSocketHub struct {
 	unregister chan *websocket.Conn
 	mu         sync.RWMutex
 	logger     *logger.Logger
+	// direct carries messages for a subset of the clients
+	direct chan directMessage
+	// metrics counts queued, sent and dropped messages
+	metrics hubMetrics
+	// done is closed when the current run loop exits; nil before the first run
+	done chan struct{}
+	// seq is the sequence number of the last broadcast message
+	seq   uint64
+	seqMu sync.Mutex
+}
+
+// directMessage is a message for the listed clients only
+type directMessage struct {
+	clients []*websocket.Conn
+	data    []byte
 }
 
 // WebSocketMessage represents a WebSocket message
 type WebSocketMessage struct {
 	Type string      `json:"type"`
 	Data interface{} `json:"data"`
+	// Seq numbers broadcast messages (see snapshot.go); direct messages have none
+	Seq uint64 `json:"seq,omitempty"`
 }
 
 var upgrader = websocket.Upgrader{
@@ -87,89 +161,214 @@ var upgrader = websocket.Upgrader{
 }
 
 // NewServer creates a new web server
-func NewServer(cfg *config.Config, log *logger.Logger, manager *repeater.Manager, eventChan <-chan repeater.Event, bridgeManager interface{}, reflector interface{}, version, buildTime string) *Server {
+func NewServer(cfg *config.Config, log *logger.Logger, manager *repeater.Manager, events *repeater.EventBus, bridgeManager interface{}, reflector interface{}, version, buildTime string) *Server {
 	hub := &WebSocketHub{
 		clients:    make(map[*websocket.Conn]bool),
 		broadcast:  make(chan []byte, 256),
 		register:   make(chan *websocket.Conn),
 		unregister: make(chan *websocket.Conn),
+		direct:     make(chan directMessage, 64),
 	}
 	// Assign logger to hub for internal logging
 	hub.logger = log.WithComponent("web.hub")
 
+	callsigns := newCallsignTracker(cfg.Web.CallsignStatsFile)
+	if err := callsigns.load(); err != nil {
+		log.Warn("Failed to load callsign statistics", logger.Error(err))
+	}
+
+	heatmap := newHeatmapTracker(cfg.Web.HeatmapFile, cfg.Web.HeatmapWeeks)
+	if err := heatmap.load(); err != nil {
+		log.Warn("Failed to load activity heatmap", logger.Error(err))
+	}
+
+	var notifications *notifier
+	if cfg.Web.Notifications.Enabled {
+		notifications = newNotifier(cfg.Web.Notifications)
+	}
+
+	var oidc *oidcClient
+	if cfg.Web.AuthRequired && cfg.Web.Auth.Backend == config.AuthBackendOIDC {
+		oidc = newOIDCClient(cfg.Web.Auth.OIDC)
+	}
+
 	return &Server{
 		config:          cfg,
 		logger:          log.WithComponent("web"),
 		repeaterManager: manager,
 		bridgeManager:   bridgeManager,
 		reflector:       reflector,
-		eventChan:       eventChan,
+		events:          events,
 		talkLogs:        make([]TalkLogEntry, 0),
 		websocketHub:    hub,
 		startTime:       time.Now(),
 		version:         version,
 		buildTime:       buildTime,
-		sessions:        make(map[string]time.Time),
+		sessions:        make(map[string]session),
+		oidc:            oidc,
+		callsigns:       callsigns,
+		heatmap:         heatmap,
+		notifier:        notifications,
+		ready:           make(chan struct{}),
 	}
 }
 
-// Start starts the web server
+// SetAlertManager attaches the alert manager whose state is served at /api/alerts
+func (s *Server) SetAlertManager(alerts *alerting.Manager) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.alerts = alerts
+}
+
+// SetMirror attaches the traffic mirror controlled through /api/mirror
+func (s *Server) SetMirror(m *mirror.Mirror) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mirror = m
+}
+
+// SetExternalLinks attaches the external link registry merged into /api/bridges
+func (s *Server) SetExternalLinks(r *links.Registry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.externalLinks = r
+}
+
+// SetClockCheck attaches the NTP clock check reported at /api/system/info
+func (s *Server) SetClockCheck(c *ntpcheck.Checker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clockCheck = c
+}
+
+// SetRuntimeMonitor attaches the runtime monitor reported at /api/system/runtime
+func (s *Server) SetRuntimeMonitor(m *health.Monitor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runtime = m
+}
+
+// BroadcastBacklog returns the WebSocket messages waiting to be sent to all
+// clients and the queue size
+func (s *Server) BroadcastBacklog() (int, int) {
+	return len(s.websocketHub.broadcast), cap(s.websocketHub.broadcast)
+}
+
+// SetDMRIDs attaches the DMR ID directory managed through /api/dmrids
+func (s *Server) SetDMRIDs(d *dmrid.Directory) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dmrIDs = d
+}
+
+// Start starts the web server and blocks until ctx is cancelled or Stop is called.
+// The server can be started again after it has stopped.
 func (s *Server) Start(ctx context.Context) error {
 	if !s.config.Web.Enabled {
 		s.logger.Info("Web server disabled")
 		return nil
 	}
 
+	runCtx, cancel := context.WithCancel(ctx)
+
 	s.mu.Lock()
 	if s.running {
 		s.mu.Unlock()
+		cancel()
 		return fmt.Errorf("web server already running")
 	}
 	s.running = true
+	s.cancel = cancel
+	ready := s.ready
+
+	// Resubscribe, replaying events published while a previous run was stopped
+	sub := s.events.Subscribe(1000, s.unsubscribedAt)
+
+	// Setup routes
+	router := s.setupRoutes()
+
+	// Create HTTP server
+	addrs := s.config.Web.ListenAddresses()
+	httpServer := &http.Server{
+		Addr:    addrs[0],
+		Handler: router,
+	}
+	s.httpServer = httpServer
+	listen := s.listenFunc
+	s.mu.Unlock()
+	if listen == nil {
+		listen = func(address string) (net.Listener, error) { return net.Listen("tcp", address) }
+	}
+
+	// Bind every listen address before serving so a bad address fails startup
+	listeners := make([]net.Listener, 0, len(addrs))
+	for _, addr := range addrs {
+		l, err := listen(addr)
+		if err != nil {
+			for _, bound := range listeners {
+				_ = bound.Close()
+			}
+			sub.Close()
+			_ = s.Stop()
+			return fmt.Errorf("failed to listen on %s: %w", addr, err)
+		}
+		listeners = append(listeners, l)
+	}
+	s.mu.Lock()
+	s.listeners = listeners
 	s.mu.Unlock()
 
+	close(ready)
+
 	// Start WebSocket hub
-	go s.websocketHub.run()
+	go s.websocketHub.run(runCtx)
 
 	// Start event processor
-	go s.processEvents(ctx)
+	go s.processEvents(runCtx, sub)
+
+	if s.config.Web.CallsignStatsFile != "" {
+		go s.runCallsignPersistence(runCtx)
+	}
+	if s.config.Web.HeatmapFile != "" {
+		go s.runHeatmapPersistence(runCtx)
+	}
 
 	// Start session cleanup if auth is enabled
 	if s.config.Web.AuthRequired {
-		go s.startSessionCleanup(ctx)
+		go s.startSessionCleanup(runCtx)
 	}
 
-	// Setup routes
-	router := s.setupRoutes()
-
-	// Create HTTP server
-	addr := fmt.Sprintf("%s:%d", s.config.Web.Host, s.config.Web.Port)
-	s.httpServer = &http.Server{
-		Addr:    addr,
-		Handler: router,
+	// Serve each listener in its own goroutine
+	serverErr := make(chan error, len(listeners))
+	for _, l := range listeners {
+		s.logger.Info("Starting web server", logger.String("address", l.Addr().String()))
+		go func(l net.Listener) {
+			if err := httpServer.Serve(l); err != nil && err != http.ErrServerClosed {
+				serverErr <- err
+			}
+		}(l)
 	}
 
-	s.logger.Info("Starting web server", logger.String("address", addr))
-
-	// Start server in goroutine
-	serverErr := make(chan error, 1)
-	go func() {
-		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			serverErr <- err
-		}
-	}()
-
-	// Wait for context cancellation or server error
+	// Wait for context cancellation, Stop or server error
 	select {
 	case err := <-serverErr:
+		_ = s.Stop()
 		return err
-	case <-ctx.Done():
+	case <-runCtx.Done():
 		s.logger.Info("Shutting down web server")
 		return s.Stop()
 	}
 }
 
-// Stop stops the web server
+// Ready returns a channel that is closed once the current or next Start is
+// listening on every address
+func (s *Server) Ready() <-chan struct{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ready
+}
+
+// Stop stops the web server, closing WebSocket clients and waiting for the hub to exit
 func (s *Server) Stop() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -179,16 +378,38 @@ func (s *Server) Stop() error {
 	}
 
 	s.running = false
+	s.unsubscribedAt = time.Now()
+	s.ready = make(chan struct{})
+	s.listeners = nil
+
+	// Stop the hub, event processor and session cleanup first. Hijacked
+	// WebSocket connections are not closed by http.Server.Shutdown.
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+	if done := s.websocketHub.stopped(); done != nil {
+		<-done
+	}
 
 	if s.httpServer != nil {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
-		return s.httpServer.Shutdown(ctx)
+		err := s.httpServer.Shutdown(ctx)
+		s.httpServer = nil
+		return err
 	}
 
 	return nil
 }
 
+// IsRunning reports whether the web server is running
+func (s *Server) IsRunning() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.running
+}
+
 // setupRoutes configures HTTP routes
 func (s *Server) setupRoutes() *mux.Router {
 	router := mux.NewRouter()
@@ -200,18 +421,38 @@ func (s *Server) setupRoutes() *mux.Router {
 
 	// Stats endpoints
 	api.HandleFunc("/stats", s.handleStats).Methods("GET")
+	api.HandleFunc("/stats/realtime", s.handleRealtimeStats).Methods("GET")
+	api.HandleFunc("/stats/latency", s.handleSendLatency).Methods("GET")
+	api.HandleFunc("/stats/heatmap", s.handleHeatmap).Methods("GET")
 	api.HandleFunc("/repeaters", s.handleRepeaters).Methods("GET")
+	api.HandleFunc("/repeaters/export", s.handleExportRepeaters).Methods("GET")
 	api.HandleFunc("/bridges", s.handleBridges).Methods("GET")
+	api.HandleFunc("/bridges/export", s.handleExportBridges).Methods("GET")
+	api.HandleFunc("/bridges/schedules", s.handleBridgeSchedules).Methods("GET")
+	api.HandleFunc("/bridges/{name}/schedule/preview", s.handleBridgeSchedulePreview).Methods("GET")
+	api.HandleFunc("/bridges/{name}/usage", s.handleBridgeUsage).Methods("GET")
 	api.HandleFunc("/logs/talk", s.handleTalkLogs).Methods("GET")
 	api.HandleFunc("/current-talker", s.handleCurrentTalker).Methods("GET")
+	api.HandleFunc("/snapshot", s.handleSnapshot).Methods("GET")
+	api.HandleFunc("/callsigns/leaderboard", s.handleCallsignLeaderboard).Methods("GET")
+	api.HandleFunc("/callsigns/{callsign}", s.handleCallsign).Methods("GET")
+	api.HandleFunc("/doublings", s.handleDoublings).Methods("GET")
+	api.HandleFunc("/alerts", s.handleAlerts).Methods("GET")
 
 	// System endpoints
 	api.HandleFunc("/system/info", s.handleSystemInfo).Methods("GET")
+	api.HandleFunc("/version", s.handleVersion).Methods("GET")
+	api.HandleFunc("/features", s.handleFeatures).Methods("GET")
+	api.HandleFunc("/system/runtime", s.handleSystemRuntime).Methods("GET")
 
 	// Authentication endpoints
 	api.HandleFunc("/auth/login", s.handleLogin).Methods("POST")
 	api.HandleFunc("/auth/logout", s.handleLogout).Methods("POST")
 	api.HandleFunc("/auth/status", s.handleAuthStatus).Methods("GET")
+	if s.oidc != nil {
+		api.HandleFunc("/auth/oidc/login", s.handleOIDCLogin).Methods("GET")
+		api.HandleFunc("/auth/oidc/callback", s.handleOIDCCallback).Methods("GET")
+	}
 
 	// Protected configuration endpoints
 	protectedAPI := api.PathPrefix("/config").Subrouter()
@@ -222,12 +463,100 @@ func (s *Server) setupRoutes() *mux.Router {
 	protectedAPI.HandleFunc("/blocklist", s.handleUpdateBlocklistConfig).Methods("PUT")
 	protectedAPI.HandleFunc("/logging", s.handleGetLoggingConfig).Methods("GET")
 	protectedAPI.HandleFunc("/logging", s.handleUpdateLoggingConfig).Methods("PUT")
+	protectedAPI.HandleFunc("/status", s.handleConfigStatus).Methods("GET")
+
+	// Protected bridge reload from the config file and includes
+	reloadAPI := api.PathPrefix("/bridges/reload").Subrouter()
+	reloadAPI.Use(s.authMiddleware)
+	reloadAPI.HandleFunc("", s.handleReloadBridges).Methods("POST")
+
+	// Protected temporary bridge endpoints
+	temporaryAPI := api.PathPrefix("/bridges/temporary").Subrouter()
+	temporaryAPI.Use(s.authMiddleware)
+	temporaryAPI.HandleFunc("", s.handleStartTemporaryBridge).Methods("POST")
+	temporaryAPI.HandleFunc("/{name}", s.handleStopTemporaryBridge).Methods("DELETE")
+
+	// Protected bridge drain
+	drainAPI := api.PathPrefix("/bridges/{name}/drain").Subrouter()
+	drainAPI.Use(s.authMiddleware)
+	drainAPI.HandleFunc("", s.handleDrainBridge).Methods("POST")
+
+	// Protected traffic mirror control
+	mirrorAPI := api.PathPrefix("/mirror").Subrouter()
+	mirrorAPI.Use(s.authMiddleware)
+	mirrorAPI.HandleFunc("", s.handleGetMirror).Methods("GET")
+	mirrorAPI.HandleFunc("", s.handleUpdateMirror).Methods("PUT")
+
+	// Protected repeater diagnostics
+	probeAPI := api.PathPrefix("/repeaters/{callsign}/probe").Subrouter()
+	probeAPI.Use(s.authMiddleware)
+	probeAPI.HandleFunc("", s.handleProbeRepeater).Methods("POST")
+
+	// Protected removal of a connected repeater
+	kickAPI := api.PathPrefix("/repeaters/{callsign}/kick").Subrouter()
+	kickAPI.Use(s.authMiddleware)
+	kickAPI.HandleFunc("", s.handleKickRepeater).Methods("POST")
+
+	// Protected per-repeater packet traces
+	traceAPI := api.PathPrefix("/trace").Subrouter()
+	traceAPI.Use(s.authMiddleware)
+	traceAPI.HandleFunc("", s.handleGetTraces).Methods("GET")
+	traceAPI.HandleFunc("", s.handleStartTrace).Methods("POST")
+	traceAPI.HandleFunc("/{target}", s.handleStopTrace).Methods("DELETE")
+
+	// Protected removal of a callsign's history for privacy requests
+	forgetAPI := api.PathPrefix("/callsigns/{callsign}").Subrouter()
+	forgetAPI.Use(s.authMiddleware)
+	forgetAPI.HandleFunc("", s.handleForgetCallsign).Methods("DELETE")
+
+	// Protected listen-only callsigns
+	listenOnlyAPI := api.PathPrefix("/listen-only").Subrouter()
+	listenOnlyAPI.Use(s.authMiddleware)
+	listenOnlyAPI.HandleFunc("", s.handleGetListenOnly).Methods("GET")
+	listenOnlyAPI.HandleFunc("/{callsign}", s.handleAddListenOnly).Methods("PUT")
+	listenOnlyAPI.HandleFunc("/{callsign}", s.handleRemoveListenOnly).Methods("DELETE")
+
+	// Protected per-callsign talk limit overrides
+	talkLimitsAPI := api.PathPrefix("/talk-limits").Subrouter()
+	talkLimitsAPI.Use(s.authMiddleware)
+	talkLimitsAPI.HandleFunc("", s.handleGetTalkLimits).Methods("GET")
+	talkLimitsAPI.HandleFunc("/{callsign}", s.handlePutTalkLimit).Methods("PUT")
+	talkLimitsAPI.HandleFunc("/{callsign}", s.handleRemoveTalkLimit).Methods("DELETE")
+
+	// Net control hold; starting and lifting it is protected
+	api.HandleFunc("/hold", s.handleGetHold).Methods("GET")
+	holdAPI := api.PathPrefix("/hold").Subrouter()
+	holdAPI.Use(s.authMiddleware)
+	holdAPI.HandleFunc("", s.handleStartHold).Methods("POST")
+	holdAPI.HandleFunc("", s.handleEndHold).Methods("DELETE")
+
+	// Repeater groups; changes are protected and last until restart
+	api.HandleFunc("/groups", s.handleGetGroups).Methods("GET")
+	groupsAPI := api.PathPrefix("/groups/{name}").Subrouter()
+	groupsAPI.Use(s.authMiddleware)
+	groupsAPI.HandleFunc("", s.handlePutGroup).Methods("PUT")
+	groupsAPI.HandleFunc("", s.handleDeleteGroup).Methods("DELETE")
+
+	// DMR ID lookups and overrides; changes are protected and last until restart
+	api.HandleFunc("/dmrids", s.handleGetDMRIDs).Methods("GET")
+	api.HandleFunc("/dmrids/lookup", s.handleLookupDMRID).Methods("GET")
+	dmridAPI := api.PathPrefix("/dmrids/{callsign}").Subrouter()
+	dmridAPI.Use(s.authMiddleware)
+	dmridAPI.HandleFunc("", s.handlePutDMRID).Methods("PUT")
+	dmridAPI.HandleFunc("", s.handleDeleteDMRID).Methods("DELETE")
+
+	// Per-session notification watch lists
+	if s.notifier != nil {
+		api.HandleFunc("/watchlist/{session}", s.handleGetWatchList).Methods("GET")
+		api.HandleFunc("/watchlist/{session}", s.handleUpdateWatchList).Methods("PUT")
+	}
 
 	// Health check
 	api.HandleFunc("/health", s.handleHealth).Methods("GET")
 
 	// WebSocket endpoint
 	router.HandleFunc("/ws", s.handleWebSocket)
+	router.Handle("/ws/trace", s.authMiddleware(http.HandlerFunc(s.handleTraceWebSocket)))
 
 	// Static files (embedded frontend)
 	s.setupStaticRoutes(router)
@@ -279,12 +608,15 @@ func (s *Server) setupStaticRoutes(router *mux.Router) {
 }
 
 // processEvents processes repeater events and broadcasts them via WebSocket
-func (s *Server) processEvents(ctx context.Context) {
+// until ctx is cancelled, then unsubscribes
+func (s *Server) processEvents(ctx context.Context, sub *repeater.Subscription) {
+	defer sub.Close()
+
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case event := <-s.eventChan:
+		case event := <-sub.C:
 			s.handleEvent(event)
 		}
 	}
@@ -308,13 +640,10 @@ func (s *Server) handleEvent(event repeater.Event) {
 	case repeater.EventTalkEnd:
 		// Add to talk logs
 		s.mu.Lock()
-		entry := TalkLogEntry{
-			ID:        time.Now().UnixNano(),
-			Callsign:  event.Callsign,
-			Duration:  int(event.Duration.Seconds()),
-			Timestamp: event.Timestamp,
-		}
+		entry := newTalkLogEntry(event)
 		s.talkLogs = append([]TalkLogEntry{entry}, s.talkLogs...)
+		s.callsigns.record(event)
+		s.heatmap.record(event)
 
 		// Keep only last 1000 entries
 		if len(s.talkLogs) > 1000 {
@@ -324,37 +653,76 @@ func (s *Server) handleEvent(event repeater.Event) {
 
 		// Broadcast via WebSocket
 		s.broadcastWebSocketMessage("talk_end", map[string]interface{}{
-			"callsign": event.Callsign,
-			"duration": int(event.Duration.Seconds()),
+			"callsign":    entry.Callsign,
+			"duration":    entry.Duration,
+			"quality":     entry.Quality,
+			"gateway":     entry.Gateway,
+			"bridge":      entry.Bridge,
+			"source_type": entry.SourceType,
+			"dgid":        entry.DGID,
+			"talkgroup":   entry.TalkGroup,
+			"recipients":  entry.Recipients,
+			"end_reason":  entry.EndReason,
 		})
 
 	case repeater.EventTalkStart:
 		s.broadcastWebSocketMessage("talk_start", map[string]interface{}{
 			"callsign":  event.Callsign,
 			"timestamp": event.Timestamp,
+			"gateway":   event.Gateway,
+			"bridge":    event.Bridge,
+		})
+
+	case repeater.EventDoubling:
+		s.broadcastWebSocketMessage("doubling", map[string]interface{}{
+			"callsign":        event.Callsign,
+			"gateway":         event.Gateway,
+			"active_callsign": event.Data["active_callsign"],
+			"active_gateway":  event.Data["active_gateway"],
+			"duration":        event.Duration.Seconds(),
+			"timestamp":       event.Timestamp,
 		})
 
 	case repeater.EventConnect:
 		s.broadcastWebSocketMessage("repeater_connect", map[string]interface{}{
 			"callsign": event.Callsign,
-			"address":  maskIPAddress(event.Address),
+			"address":  repeater.MaskAddress(event.Address, s.guestMaskLevel()),
 		})
 
 	case repeater.EventDisconnect:
 		s.broadcastWebSocketMessage("repeater_disconnect", map[string]interface{}{
 			"callsign": event.Callsign,
-			"address":  maskIPAddress(event.Address),
+			"address":  repeater.MaskAddress(event.Address, s.guestMaskLevel()),
+			"reason":   event.Reason,
+			"uptime":   event.Duration.Seconds(),
 		})
 	}
 
+	s.notifyWatchers(event)
+
 	// Always broadcast the raw event
+	event.Address = repeater.MaskAddress(event.Address, s.guestMaskLevel())
 	s.broadcastWebSocketMessage("event", event)
 }
 
-// WebSocket hub run loop
-func (hub *WebSocketHub) run() {
+// WebSocket hub run loop. It exits when ctx is cancelled, closing all clients,
+// and can be started again afterwards.
+func (hub *WebSocketHub) run(ctx context.Context) {
+	hub.mu.Lock()
+	done := make(chan struct{})
+	hub.done = done
+	hub.mu.Unlock()
+
+	defer func() {
+		hub.closeAll()
+		close(done)
+	}()
+
 	for {
 		select {
+		case <-ctx.Done():
+			return
+
 		case client := <-hub.register:
 			hub.mu.Lock()
 			hub.clients[client] = true
@@ -364,27 +732,93 @@ func (hub *WebSocketHub) run() {
 			hub.mu.Lock()
 			if _, ok := hub.clients[client]; ok {
 				delete(hub.clients, client)
-				if err := client.Close(); err != nil {
-					if hub.logger != nil {
-						hub.logger.Warn("failed to close websocket client", logger.Error(err))
-					}
-				}
+				hub.closeClient(client)
 			}
 			hub.mu.Unlock()
 
 		case message := <-hub.broadcast:
-			hub.mu.RLock()
+			hub.mu.Lock()
 			for client := range hub.clients {
-				if err := client.WriteMessage(websocket.TextMessage, message); err != nil {
-					delete(hub.clients, client)
-					if err := client.Close(); err != nil {
-						if hub.logger != nil {
-							hub.logger.Warn("failed to close websocket client", logger.Error(err))
-						}
-					}
+				hub.write(client, message)
+			}
+			hub.mu.Unlock()
+
+		case message := <-hub.direct:
+			hub.mu.Lock()
+			for _, client := range message.clients {
+				if hub.clients[client] {
+					hub.write(client, message.data)
 				}
 			}
-			hub.mu.RUnlock()
+			hub.mu.Unlock()
+		}
+	}
+}
+
+// registerClient hands a client to the run loop. It returns false if the hub is not running.
+func (hub *WebSocketHub) registerClient(client *websocket.Conn) bool {
+	hub.mu.RLock()
+	done := hub.done
+	hub.mu.RUnlock()
+
+	if done == nil {
+		return false
+	}
+
+	select {
+	case hub.register <- client:
+		return true
+	case <-done:
+		return false
+	}
+}
+
+// unregisterClient removes a client, closing it directly if the hub has stopped
+func (hub *WebSocketHub) unregisterClient(client *websocket.Conn) {
+	hub.mu.RLock()
+	done := hub.done
+	hub.mu.RUnlock()
+
+	select {
+	case hub.unregister <- client:
+	case <-done:
+		// Hub already closed every client on shutdown
+	}
+}
+
+// stopped returns a channel that is closed once the current run loop has exited
+func (hub *WebSocketHub) stopped() <-chan struct{} {
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+	return hub.done
+}
+
+// closeAll closes and forgets every client
+func (hub *WebSocketHub) closeAll() {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	for client := range hub.clients {
+		_ = client.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"),
+			time.Now().Add(time.Second))
+		hub.closeClient(client)
+	}
+	hub.clients = make(map[*websocket.Conn]bool)
+}
+
+// clientCount returns the number of connected clients
+func (hub *WebSocketHub) clientCount() int {
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+	return len(hub.clients)
+}
+
+// closeClient closes a client connection. Caller must hold hub.mu.
+func (hub *WebSocketHub) closeClient(client *websocket.Conn) {
+	if err := client.Close(); err != nil {
+		if hub.logger != nil {
+			hub.logger.Warn("failed to close websocket client", logger.Error(err))
 		}
 	}
 }
@@ -395,28 +829,36 @@ func (s *Server) broadcastWebSocketMessage(messageType string, data interface{})
 		logger.String("message_type", messageType),
 		logger.Any("data", data))
 
-	message := WebSocketMessage{
+	// Numbering and queueing under one lock keeps the sequence in queue
+	// order; a dropped message still uses its number, so clients see the gap
+	hub := s.websocketHub
+	hub.seqMu.Lock()
+	defer hub.seqMu.Unlock()
+
+	jsonData, err := json.Marshal(WebSocketMessage{
 		Type: messageType,
 		Data: data,
-	}
-
-	jsonData, err := json.Marshal(message)
+		Seq:  hub.seq + 1,
+	})
 	if err != nil {
 		s.logger.Error("Failed to marshal WebSocket message", logger.Error(err))
 		return
 	}
+	hub.seq++
 
 	s.logger.Info("broadcastWebSocketMessage: attempting to send to hub",
 		logger.String("message_type", messageType),
-		logger.Int("broadcast_channel_len", len(s.websocketHub.broadcast)),
-		logger.Int("broadcast_channel_cap", cap(s.websocketHub.broadcast)))
+		logger.Int("broadcast_channel_len", len(hub.broadcast)),
+		logger.Int("broadcast_channel_cap", cap(hub.broadcast)))
 
 	select {
-	case s.websocketHub.broadcast <- jsonData:
+	case hub.broadcast <- jsonData:
+		hub.metrics.queued.Add(1)
 		s.logger.Info("broadcastWebSocketMessage: message sent to broadcast channel",
 			logger.String("message_type", messageType))
 	default:
 		// Don't block if broadcast channel is full
+		hub.metrics.dropped.Add(1)
 		s.logger.Warn("WebSocket broadcast channel full, dropping message",
 			logger.String("message_type", messageType))
 	}
@@ -454,36 +896,19 @@ func (s *Server) authMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		// Check for session token
-		token := r.Header.Get("Authorization")
-		if token == "" {
-			// Try cookie
-			if cookie, err := r.Cookie("session_token"); err == nil {
-				token = cookie.Value
+		sess, ok := s.authenticate(r)
+		if !ok {
+			if sessionToken(r) == "" {
+				http.Error(w, "Authentication required", http.StatusUnauthorized)
+			} else {
+				http.Error(w, "Invalid or expired session", http.StatusUnauthorized)
 			}
-		} else {
-			// Remove "Bearer " prefix if present
-			token = strings.TrimPrefix(token, "Bearer ")
-		}
-
-		if token == "" {
-			http.Error(w, "Authentication required", http.StatusUnauthorized)
 			return
 		}
 
-		// Check if session is valid
-		s.sessionsMu.RLock()
-		expiry, exists := s.sessions[token]
-		s.sessionsMu.RUnlock()
-
-		if !exists || time.Now().After(expiry) {
-			// Clean up expired session
-			if exists {
-				s.sessionsMu.Lock()
-				delete(s.sessions, token)
-				s.sessionsMu.Unlock()
-			}
-			http.Error(w, "Invalid or expired session", http.StatusUnauthorized)
+		// Viewers may only read
+		if !authorize(sess, r.Method) {
+			http.Error(w, "Insufficient permissions", http.StatusForbidden)
 			return
 		}
 
@@ -506,13 +931,38 @@ func (s *Server) cleanupExpiredSessions() {
 	defer s.sessionsMu.Unlock()
 
 	now := time.Now()
-	for token, expiry := range s.sessions {
-		if now.After(expiry) {
+	for token, sess := range s.sessions {
+		if now.After(sess.expiry) {
 			delete(s.sessions, token)
 		}
 	}
 }
 
+// PruneTalkLogs removes talk log entries older than maxAge and returns how many were removed
+func (s *Server) PruneTalkLogs(maxAge time.Duration) int {
+	if maxAge <= 0 {
+		return 0
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Talk logs are kept newest first, so everything after the first stale entry is stale too
+	keep := len(s.talkLogs)
+	for i, entry := range s.talkLogs {
+		if entry.Timestamp.Before(cutoff) {
+			keep = i
+			break
+		}
+	}
+
+	removed := len(s.talkLogs) - keep
+	s.talkLogs = s.talkLogs[:keep]
+	return removed
+}
+
 // startSessionCleanup runs periodic session cleanup
 func (s *Server) startSessionCleanup(ctx context.Context) {
 	ticker := time.NewTicker(time.Hour) // Clean up every hour
@@ -530,6 +980,13 @@ func (s *Server) startSessionCleanup(ctx context.Context) {
 
 // API Handlers
 func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	if err := json.NewEncoder(w).Encode(s.statsSummary()); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+// statsSummary returns the reflector statistics served by /api/stats
+func (s *Server) statsSummary() map[string]interface{} {
 	stats := s.repeaterManager.GetStats()
 
 	response := map[string]interface{}{
@@ -539,162 +996,838 @@ func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
 		"totalPackets":     stats.TotalPackets,
 		"bytesReceived":    stats.TotalBytesReceived,
 		"bytesSent":        stats.TotalBytesTransmitted,
+		"doublings":        stats.Doublings,
+		"contention":       stats.Contention,
+		"websocket":        s.WebSocketStats(),
 	}
 
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	if bm, ok := s.bridgeManager.(interface{ GetStats() bridge.BridgeStats }); ok {
+		response["bridges"] = bm.GetStats()
 	}
-}
 
-func (s *Server) handleRepeaters(w http.ResponseWriter, r *http.Request) {
-	stats := s.repeaterManager.GetStats()
-	if err := json.NewEncoder(w).Encode(map[string]interface{}{
-		"repeaters": stats.Repeaters,
-	}); err != nil {
-		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	if refl, ok := s.reflector.(interface{ PacketVariants() []network.VariantStat }); ok {
+		response["packet_variants"] = refl.PacketVariants()
 	}
-}
-
-func (s *Server) handleBridges(w http.ResponseWriter, r *http.Request) {
-	bridges := make(map[string]interface{})
 
-	// Check if bridge manager is available and has GetStatus method
-	if s.bridgeManager != nil {
-		// Use type assertion to check if it has GetStatus method with correct return type
-		if bm, ok := s.bridgeManager.(interface {
-			GetStatus() map[string]bridge.BridgeStatus
-		}); ok {
-			// Run GetStatus with a short timeout to avoid blocking the HTTP handler
-			type result struct {
-				status map[string]bridge.BridgeStatus
-			}
-			ch := make(chan result, 1)
+	if refl, ok := s.reflector.(interface{ SocketStats() network.SocketStats }); ok {
+		response["sockets"] = refl.SocketStats()
+	}
 
-			go func() {
-				ch <- result{status: bm.GetStatus()}
-			}()
+	return response
+}
 
-			select {
-			case res := <-ch:
-				for name, bridgeStatus := range res.status {
-					bridges[name] = bridgeStatus
-				}
-			case <-time.After(500 * time.Millisecond):
-				s.logger.Warn("bridge manager GetStatus timed out, returning partial/empty result")
-				// leave bridges empty
-			}
-		}
+// handleRealtimeStats returns per-second packet and byte counts for the last
+// two minutes, oldest first, for live graphs
+func (s *Server) handleRealtimeStats(w http.ResponseWriter, r *http.Request) {
+	samples := []network.RateSample{}
+	if refl, ok := s.reflector.(interface{ PacketRates() []network.RateSample }); ok {
+		samples = refl.PacketRates()
 	}
 
 	if err := json.NewEncoder(w).Encode(map[string]interface{}{
-		"bridges": bridges,
+		"interval": 1,
+		"window":   network.RateWindow,
+		"samples":  samples,
 	}); err != nil {
 		s.logger.Error("failed to encode JSON response", logger.Error(err))
 	}
 }
 
-func (s *Server) handleCurrentTalker(w http.ResponseWriter, r *http.Request) {
-	// First check for regular repeater talkers
-	stats := s.repeaterManager.GetStats()
-	for _, repeater := range stats.Repeaters {
-		if repeater.IsTalking {
-			// Found a regular repeater that's talking
-			response := map[string]interface{}{
-				"current_talker": map[string]interface{}{
-					"callsign":      repeater.Callsign,
-					"address":       repeater.Address,
-					"type":          "repeater",
-					"is_talking":    true,
-					"talk_duration": repeater.TalkDuration,
-				},
-			}
-			if err := json.NewEncoder(w).Encode(response); err != nil {
-				s.logger.Error("failed to encode JSON response", logger.Error(err))
-			}
-			return
-		}
+// handleSendLatency returns the per-destination broadcast send latency, when
+// broadcast_priority.measure_latency is enabled
+func (s *Server) handleSendLatency(w http.ResponseWriter, r *http.Request) {
+	latencies := []network.SendLatency{}
+	if refl, ok := s.reflector.(interface{ SendLatencies() []network.SendLatency }); ok {
+		latencies = refl.SendLatencies()
 	}
 
-	// No regular repeater talking, check for bridge talkers
-	if s.reflector != nil {
-		if refl, ok := s.reflector.(interface{ GetCurrentBridgeTalker() interface{} }); ok {
-			// Protect reflector call with a short timeout so slow reflector doesn't hang HTTP
-			type reflResult struct {
-				talker interface{}
-			}
-			ch := make(chan reflResult, 1)
-
-			go func() {
-				ch <- reflResult{talker: refl.GetCurrentBridgeTalker()}
-			}()
-
-			select {
-			case res := <-ch:
-				bridgeTalker := res.talker
-				if bridgeTalker != nil {
-					// Use type assertion to extract bridge talker information
-					if bt, ok := bridgeTalker.(interface {
-						GetCallsign() string
-						GetBridgeName() string
-						GetTalkDuration() time.Duration
-					}); ok {
-						response := map[string]interface{}{
-							"current_talker": map[string]interface{}{
-								"callsign":      bt.GetCallsign(),
-								"address":       bt.GetBridgeName(), // Show bridge name as "address"
-								"type":          "bridge",
-								"is_talking":    true,
-								"talk_duration": int(bt.GetTalkDuration().Seconds()),
-							},
-						}
-						if err := json.NewEncoder(w).Encode(response); err != nil {
-							s.logger.Error("failed to encode JSON response", logger.Error(err))
-						}
-						return
-					}
-				}
-			case <-time.After(500 * time.Millisecond):
-				s.logger.Warn("reflector GetCurrentBridgeTalker timed out, returning null")
-				// fall through to return null
-			}
-		}
+	level := s.maskLevel(r)
+	for i := range latencies {
+		latencies[i].Address = repeater.MaskAddress(latencies[i].Address, level)
 	}
 
-	// No one is talking
-	response := map[string]interface{}{
-		"current_talker": nil,
-	}
-	if err := json.NewEncoder(w).Encode(response); err != nil {
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"enabled":      s.config.Server.BroadcastPriority.MeasureLatency,
+		"destinations": latencies,
+	}); err != nil {
 		s.logger.Error("failed to encode JSON response", logger.Error(err))
 	}
 }
 
-func (s *Server) handleTalkLogs(w http.ResponseWriter, r *http.Request) {
-	limitStr := r.URL.Query().Get("limit")
-	limit := 100 // default
+// maxRepeaterPageSize caps the limit of a /api/repeaters page
+const maxRepeaterPageSize = 500
 
-	if limitStr != "" {
+// handleRepeaters lists repeaters. Optional query parameters:
+// search, sort (callsign, last_heard, talk_time), order (asc, desc), page and
+// limit, at most maxRepeaterPageSize.
+func (s *Server) handleRepeaters(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	query := repeater.RepeaterQuery{
+		Search: params.Get("search"),
+		Sort:   params.Get("sort"),
+		Group:  params.Get("group"),
+	}
+
+	// Most recent and most active first unless asked otherwise
+	switch params.Get("order") {
+	case "asc":
+	case "desc":
+		query.Desc = true
+	case "":
+		query.Desc = query.Sort == repeater.SortByLastHeard || query.Sort == repeater.SortByTalkTime
+	default:
+		http.Error(w, "Invalid order", http.StatusBadRequest)
+		return
+	}
+
+	if pageStr := params.Get("page"); pageStr != "" {
+		if parsed, err := strconv.Atoi(pageStr); err == nil && parsed > 0 {
+			query.Page = parsed
+		}
+	}
+	if limitStr := params.Get("limit"); limitStr != "" {
 		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
-			limit = parsed
+			query.Limit = min(parsed, maxRepeaterPageSize)
 		}
 	}
 
-	s.mu.RLock()
-	logs := s.talkLogs
-	if len(logs) > limit {
-		logs = logs[:limit]
+	page, err := s.repeaterManager.QueryRepeaters(query)
+	if err != nil {
+		http.Error(w, "Invalid query: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	level := s.maskLevel(r)
+	for i := range page.Repeaters {
+		page.Repeaters[i] = page.Repeaters[i].Masked(level)
+	}
+	byIP := s.repeaterManager.GetIPSummary()
+	for i := range byIP {
+		byIP[i] = byIP[i].Masked(level)
 	}
-	s.mu.RUnlock()
 
 	if err := json.NewEncoder(w).Encode(map[string]interface{}{
-		"logs": logs,
+		"repeaters":   page.Repeaters,
+		"total":       page.Total,
+		"page":        page.Page,
+		"limit":       page.Limit,
+		"by_ip":       byIP,
+		"talk_limits": s.repeaterManager.GetTalkLimits(),
 	}); err != nil {
 		s.logger.Error("failed to encode JSON response", logger.Error(err))
 	}
 }
 
-func (s *Server) handleSystemInfo(w http.ResponseWriter, r *http.Request) {
-	response := map[string]interface{}{
+func (s *Server) handleBridges(w http.ResponseWriter, r *http.Request) {
+	bridges := make(map[string]interface{})
+	for name, bridgeStatus := range s.bridgeStatuses() {
+		bridges[name] = bridgeStatus
+	}
+
+	// External links are not bridges but are part of the same interconnect
+	externalLinks := []links.Status{}
+	s.mu.RLock()
+	registry := s.externalLinks
+	s.mu.RUnlock()
+	if registry != nil {
+		externalLinks = registry.Statuses()
+	}
+
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"bridges":        bridges,
+		"external_links": externalLinks,
+	}); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+// bridgeStatuses returns the status of every bridge, or nothing if the bridge
+// manager is unavailable or does not answer in time
+func (s *Server) bridgeStatuses() map[string]bridge.BridgeStatus {
+	// Check if bridge manager is available and has GetStatus method
+	bm, ok := s.bridgeManager.(interface {
+		GetStatus() map[string]bridge.BridgeStatus
+	})
+	if !ok {
+		return nil
+	}
+
+	// Run GetStatus with a short timeout to avoid blocking the HTTP handler
+	ch := make(chan map[string]bridge.BridgeStatus, 1)
+	go func() {
+		ch <- bm.GetStatus()
+	}()
+
+	select {
+	case status := <-ch:
+		return status
+	case <-time.After(500 * time.Millisecond):
+		s.logger.Warn("bridge manager GetStatus timed out, returning partial/empty result")
+		return nil
+	}
+}
+
+func (s *Server) handleAlerts(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	alerts := s.alerts
+	s.mu.RUnlock()
+
+	response := map[string]interface{}{
+		"enabled": alerts != nil,
+		"alerts":  []alerting.Alert{},
+	}
+	if alerts != nil {
+		response["alerts"] = alerts.Alerts()
+	}
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+func (s *Server) handleBridgeSchedules(w http.ResponseWriter, r *http.Request) {
+	schedules := []bridge.ScheduleInfo{}
+	var stats *bridge.BridgeStats
+
+	if bm, ok := s.bridgeManager.(interface {
+		GetSchedules() []bridge.ScheduleInfo
+		GetStats() bridge.BridgeStats
+	}); ok {
+		schedules = bm.GetSchedules()
+		bridgeStats := bm.GetStats()
+		stats = &bridgeStats
+	}
+
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"schedules": schedules,
+		"stats":     stats,
+	}); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+// handleBridgeSchedulePreview returns the next planned runs of a scheduled bridge
+func (s *Server) handleBridgeSchedulePreview(w http.ResponseWriter, r *http.Request) {
+	bm, ok := s.bridgeManager.(interface {
+		PreviewSchedule(string, int) (bridge.SchedulePreview, error)
+	})
+	if !ok {
+		http.Error(w, "Bridge manager not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	count := 5
+	if countStr := r.URL.Query().Get("count"); countStr != "" {
+		if parsed, err := strconv.Atoi(countStr); err == nil && parsed > 0 && parsed <= 50 {
+			count = parsed
+		}
+	}
+
+	preview, err := bm.PreviewSchedule(mux.Vars(r)["name"], count)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(preview); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+// handleBridgeUsage returns the cumulative usage of a bridge with its per-day
+// aggregates, the last 31 days unless ?days= asks for more (0 = all)
+func (s *Server) handleBridgeUsage(w http.ResponseWriter, r *http.Request) {
+	bm, ok := s.bridgeManager.(interface {
+		GetUsage(string, int) (bridge.BridgeUsage, bool)
+	})
+	if !ok {
+		http.Error(w, "Bridge manager not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	days := 31
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		parsed, err := strconv.Atoi(daysStr)
+		if err != nil || parsed < 0 {
+			http.Error(w, "Invalid days", http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+
+	usage, found := bm.GetUsage(mux.Vars(r)["name"], days)
+	if !found {
+		http.Error(w, "No usage recorded for bridge", http.StatusNotFound)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(usage); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+// handleStartTemporaryBridge brings up a bridge that is torn down after the given duration
+func (s *Server) handleStartTemporaryBridge(w http.ResponseWriter, r *http.Request) {
+	bm, ok := s.bridgeManager.(interface {
+		StartTemporary(config.BridgeConfig, time.Duration) (bridge.BridgeStatus, error)
+	})
+	if !ok {
+		http.Error(w, "Bridge manager not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var request struct {
+		Name     string `json:"name"`
+		Host     string `json:"host"`
+		Port     int    `json:"port"`
+		Duration string `json:"duration"` // Go duration, e.g. "2h"
+		RxOnly   bool   `json:"rx_only"`
+		// Callsigns limits forwarded local traffic to these callsigns
+		Callsigns []string `json:"callsigns"`
+		Protocol  string   `json:"protocol"` // Keepalive variant; empty detects it
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.RxOnly && len(request.Callsigns) > 0 {
+		http.Error(w, "callsigns cannot be used with rx_only", http.StatusBadRequest)
+		return
+	}
+
+	duration, err := time.ParseDuration(request.Duration)
+	if err != nil {
+		http.Error(w, "Invalid duration", http.StatusBadRequest)
+		return
+	}
+
+	status, err := bm.StartTemporary(config.BridgeConfig{
+		Name:        request.Name,
+		Host:        request.Host,
+		Port:        request.Port,
+		RxOnly:      request.RxOnly,
+		Callsigns:   request.Callsigns,
+		Protocol:    request.Protocol,
+		HealthCheck: 60 * time.Second,
+	}, duration)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+// handleStopTemporaryBridge tears down a temporary bridge before it expires
+func (s *Server) handleStopTemporaryBridge(w http.ResponseWriter, r *http.Request) {
+	bm, ok := s.bridgeManager.(interface{ StopTemporary(string) error })
+	if !ok {
+		http.Error(w, "Bridge manager not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := bm.StopTemporary(mux.Vars(r)["name"]); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(map[string]string{"status": "stopping"}); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+// handleDrainBridge stops forwarding new transmissions to a bridge and
+// disconnects it once the current one has finished
+func (s *Server) handleDrainBridge(w http.ResponseWriter, r *http.Request) {
+	bm, ok := s.bridgeManager.(interface {
+		Drain(string) (bridge.BridgeStatus, error)
+	})
+	if !ok {
+		http.Error(w, "Bridge manager not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	status, err := bm.Drain(mux.Vars(r)["name"])
+	switch {
+	case errors.Is(err, bridge.ErrUnknownBridge):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+// handleConfigStatus reports differences between the running configuration
+// and the config file, and whether a restart is needed to apply them
+func (s *Server) handleConfigStatus(w http.ResponseWriter, r *http.Request) {
+	refl, ok := s.reflector.(interface {
+		ConfigStatus() (config.Status, error)
+	})
+	if !ok {
+		http.Error(w, "Config status not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	status, err := refl.ConfigStatus()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+// handleReloadBridges re-reads the bridge definitions and applies them
+func (s *Server) handleReloadBridges(w http.ResponseWriter, r *http.Request) {
+	refl, ok := s.reflector.(interface {
+		ReloadBridges() (bridge.ReloadResult, error)
+	})
+	if !ok {
+		http.Error(w, "Bridge reload not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	result, err := refl.ReloadBridges()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+func (s *Server) handleCurrentTalker(w http.ResponseWriter, r *http.Request) {
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"current_talker": s.currentTalker(s.maskLevel(r)),
+	}); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+// currentTalker returns the repeater or bridge talker holding the channel,
+// with repeater addresses masked at level, or nil if no one is talking
+func (s *Server) currentTalker(level string) map[string]interface{} {
+	// First check for regular repeater talkers
+	stats := s.repeaterManager.GetStats()
+	for _, repeater := range stats.Repeaters {
+		if repeater.IsTalking {
+			// Found a regular repeater that's talking
+			return map[string]interface{}{
+				"callsign":      repeater.Callsign,
+				"address":       repeater.Masked(level).Address,
+				"gateway":       repeater.Callsign,
+				"type":          "repeater",
+				"is_talking":    true,
+				"talk_duration": repeater.TalkDuration,
+			}
+		}
+	}
+
+	// No regular repeater talking, check for bridge talkers
+	if s.reflector != nil {
+		if refl, ok := s.reflector.(interface{ GetCurrentBridgeTalker() interface{} }); ok {
+			// Protect reflector call with a short timeout so slow reflector doesn't hang HTTP
+			type reflResult struct {
+				talker interface{}
+			}
+			ch := make(chan reflResult, 1)
+
+			go func() {
+				ch <- reflResult{talker: refl.GetCurrentBridgeTalker()}
+			}()
+
+			select {
+			case res := <-ch:
+				bridgeTalker := res.talker
+				if bridgeTalker != nil {
+					// Use type assertion to extract bridge talker information
+					if bt, ok := bridgeTalker.(interface {
+						GetCallsign() string
+						GetBridgeName() string
+						GetTalkDuration() time.Duration
+					}); ok {
+						current := map[string]interface{}{
+							"callsign":      bt.GetCallsign(),
+							"address":       bt.GetBridgeName(), // Show bridge name as "address"
+							"bridge":        bt.GetBridgeName(),
+							"type":          "bridge",
+							"is_talking":    true,
+							"talk_duration": int(bt.GetTalkDuration().Seconds()),
+						}
+						if gw, ok := bridgeTalker.(interface{ GetGateway() string }); ok {
+							current["gateway"] = gw.GetGateway()
+						}
+						return current
+					}
+				}
+			case <-time.After(500 * time.Millisecond):
+				s.logger.Warn("reflector GetCurrentBridgeTalker timed out, returning null")
+				// fall through to return null
+			}
+		}
+	}
+
+	// No one is talking
+	return nil
+}
+
+func (s *Server) handleTalkLogs(w http.ResponseWriter, r *http.Request) {
+	limitStr := r.URL.Query().Get("limit")
+	limit := 100 // default
+
+	if limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"logs": s.RecentTalkLogs(limit),
+	}); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+// RecentTalkLogs returns up to limit talk log entries, newest first
+func (s *Server) RecentTalkLogs(limit int) []TalkLogEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	logs := s.talkLogs
+	if len(logs) > limit {
+		logs = logs[:limit]
+	}
+	result := make([]TalkLogEntry, len(logs))
+	copy(result, logs)
+	return result
+}
+
+// handleCallsign returns lifetime statistics for a single callsign
+func (s *Server) handleCallsign(w http.ResponseWriter, r *http.Request) {
+	stats, ok := s.callsigns.get(mux.Vars(r)["callsign"])
+	if !ok {
+		http.Error(w, "Callsign not heard", http.StatusNotFound)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+// handleCallsignLeaderboard returns the top callsigns. Optional query parameters:
+// sort (talk_time, transmissions, last_heard) and limit.
+func (s *Server) handleCallsignLeaderboard(w http.ResponseWriter, r *http.Request) {
+	limit := 20
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	board, err := s.callsigns.leaderboard(r.URL.Query().Get("sort"), limit)
+	if err != nil {
+		http.Error(w, "Invalid sort", http.StatusBadRequest)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"callsigns": board,
+	}); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+// handleDoublings returns recent doublings and per-callsign counters
+func (s *Server) handleDoublings(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	recent, counts, total := s.repeaterManager.GetDoublings(limit)
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"doublings":   recent,
+		"by_callsign": counts,
+		"total":       total,
+	}); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+// handleGetMirror returns the traffic mirror status
+func (s *Server) handleGetMirror(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	m := s.mirror
+	s.mu.RUnlock()
+
+	if m == nil {
+		http.Error(w, "Traffic mirroring not configured", http.StatusNotFound)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(m.Status()); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+// handleUpdateMirror enables or disables the traffic mirror
+func (s *Server) handleUpdateMirror(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	m := s.mirror
+	s.mu.RUnlock()
+
+	if m == nil {
+		http.Error(w, "Traffic mirroring not configured", http.StatusNotFound)
+		return
+	}
+
+	var request struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Enabled == nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	m.SetEnabled(*request.Enabled)
+
+	if err := json.NewEncoder(w).Encode(m.Status()); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+// handleKickRepeater disconnects the repeaters with a callsign
+func (s *Server) handleKickRepeater(w http.ResponseWriter, r *http.Request) {
+	callsign := mux.Vars(r)["callsign"]
+	kicked := s.repeaterManager.KickRepeater(callsign)
+	if kicked == 0 {
+		http.Error(w, "Repeater not connected", http.StatusNotFound)
+		return
+	}
+	s.logger.Info("Repeater kicked",
+		logger.String("callsign", callsign),
+		logger.Int("count", kicked),
+		logger.String("remote_addr", r.RemoteAddr))
+
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"callsign": callsign,
+		"kicked":   kicked,
+	}); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+// handleProbeRepeater runs an on-demand latency probe against a connected repeater.
+// The request blocks until the probe finishes (a few seconds).
+func (s *Server) handleProbeRepeater(w http.ResponseWriter, r *http.Request) {
+	refl, ok := s.reflector.(interface {
+		ProbeRepeater(context.Context, string, int) (repeater.ProbeResult, error)
+	})
+	if !ok {
+		http.Error(w, "Probe not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	callsign := mux.Vars(r)["callsign"]
+	if s.repeaterManager.FindByCallsign(callsign) == nil {
+		http.Error(w, "Repeater not connected", http.StatusNotFound)
+		return
+	}
+
+	count := 0
+	if countStr := r.URL.Query().Get("count"); countStr != "" {
+		parsed, err := strconv.Atoi(countStr)
+		if err != nil || parsed < 1 || parsed > repeater.MaxProbeCount {
+			http.Error(w, fmt.Sprintf("count must be between 1 and %d", repeater.MaxProbeCount), http.StatusBadRequest)
+			return
+		}
+		count = parsed
+	}
+
+	result, err := refl.ProbeRepeater(r.Context(), callsign, count)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, repeater.ErrProbeInProgress) {
+			status = http.StatusConflict
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+// handleGetListenOnly lists the listen-only callsigns
+func (s *Server) handleGetListenOnly(w http.ResponseWriter, r *http.Request) {
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"callsigns": s.repeaterManager.GetListenOnly(),
+		"drops":     s.repeaterManager.GetStats().ListenOnlyDrops,
+	}); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+// handleAddListenOnly makes a callsign listen-only until restart
+func (s *Server) handleAddListenOnly(w http.ResponseWriter, r *http.Request) {
+	callsign := strings.ToUpper(strings.TrimSpace(mux.Vars(r)["callsign"]))
+	s.repeaterManager.AddListenOnly(callsign)
+	s.logger.Info("Callsign set to listen-only", logger.String("callsign", callsign))
+
+	s.handleGetListenOnly(w, r)
+}
+
+// handleRemoveListenOnly lets a listen-only callsign talk again
+func (s *Server) handleRemoveListenOnly(w http.ResponseWriter, r *http.Request) {
+	callsign := strings.ToUpper(strings.TrimSpace(mux.Vars(r)["callsign"]))
+	if !s.repeaterManager.RemoveListenOnly(callsign) {
+		http.Error(w, "Callsign is not listen-only", http.StatusNotFound)
+		return
+	}
+	s.logger.Info("Callsign no longer listen-only", logger.String("callsign", callsign))
+
+	s.handleGetListenOnly(w, r)
+}
+
+// handleGetTalkLimits lists the per-callsign talk limit overrides
+func (s *Server) handleGetTalkLimits(w http.ResponseWriter, r *http.Request) {
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"talk_limits": s.repeaterManager.GetTalkLimits(),
+	}); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+// handlePutTalkLimit overrides the talk limits of a callsign until restart
+func (s *Server) handlePutTalkLimit(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		TalkMaxDuration string `json:"talk_max_duration"` // Go duration, e.g. "20m"; empty keeps the global value
+		UnmuteAfter     string `json:"unmute_after"`      // Go duration; "0s" mutes until the station stops
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	limit := repeater.TalkLimit{Callsign: strings.ToUpper(strings.TrimSpace(mux.Vars(r)["callsign"]))}
+	if request.TalkMaxDuration != "" {
+		d, err := time.ParseDuration(request.TalkMaxDuration)
+		if err != nil || d <= 0 {
+			http.Error(w, "Invalid talk_max_duration", http.StatusBadRequest)
+			return
+		}
+		limit.TalkMaxDuration = &d
+	}
+	if request.UnmuteAfter != "" {
+		d, err := time.ParseDuration(request.UnmuteAfter)
+		if err != nil || d < 0 {
+			http.Error(w, "Invalid unmute_after", http.StatusBadRequest)
+			return
+		}
+		limit.UnmuteAfter = &d
+	}
+	if limit.TalkMaxDuration == nil && limit.UnmuteAfter == nil {
+		http.Error(w, "talk_max_duration or unmute_after is required", http.StatusBadRequest)
+		return
+	}
+
+	s.repeaterManager.PutTalkLimit(limit)
+	s.logger.Info("Talk limit override set",
+		logger.String("callsign", limit.Callsign),
+		logger.String("talk_max_duration", request.TalkMaxDuration),
+		logger.String("unmute_after", request.UnmuteAfter))
+
+	s.handleGetTalkLimits(w, r)
+}
+
+// handleRemoveTalkLimit returns a callsign to the global talk limits
+func (s *Server) handleRemoveTalkLimit(w http.ResponseWriter, r *http.Request) {
+	callsign := strings.ToUpper(strings.TrimSpace(mux.Vars(r)["callsign"]))
+	if !s.repeaterManager.RemoveTalkLimit(callsign) {
+		http.Error(w, "Callsign has no talk limit override", http.StatusNotFound)
+		return
+	}
+	s.logger.Info("Talk limit override removed", logger.String("callsign", callsign))
+
+	s.handleGetTalkLimits(w, r)
+}
+
+// handleGetGroups lists the repeater groups and their connected members
+func (s *Server) handleGetGroups(w http.ResponseWriter, r *http.Request) {
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"groups": s.repeaterManager.GetGroups(),
+	}); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+// handlePutGroup creates or replaces a repeater group until restart
+func (s *Server) handlePutGroup(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Callsigns []string `json:"callsigns"`
+		NoBridge  bool     `json:"no_bridge"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	group := repeater.Group{Name: mux.Vars(r)["name"], Callsigns: body.Callsigns, NoBridge: body.NoBridge}
+	if err := s.repeaterManager.PutGroup(group); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.logger.Info("Repeater group updated",
+		logger.String("group", group.Name),
+		logger.Int("patterns", len(group.Callsigns)))
+
+	s.handleGetGroups(w, r)
+}
+
+// handleDeleteGroup removes a repeater group
+func (s *Server) handleDeleteGroup(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	if !s.repeaterManager.DeleteGroup(name) {
+		http.Error(w, "Group not found", http.StatusNotFound)
+		return
+	}
+	s.logger.Info("Repeater group removed", logger.String("group", name))
+
+	s.handleGetGroups(w, r)
+}
+
+// handleSystemRuntime reports goroutines, heap, queue backlogs and runtime anomalies
+func (s *Server) handleSystemRuntime(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	m := s.runtime
+	s.mu.RUnlock()
+
+	if m == nil {
+		http.Error(w, "Runtime monitor not available", http.StatusNotFound)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(m.Status()); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+func (s *Server) handleSystemInfo(w http.ResponseWriter, r *http.Request) {
+	response := map[string]interface{}{
 		"name":           s.config.Server.Name,
 		"description":    s.config.Server.Description,
 		"version":        s.version,
@@ -703,6 +1836,23 @@ func (s *Server) handleSystemInfo(w http.ResponseWriter, r *http.Request) {
 		"port":           s.config.Server.Port,
 		"maxConnections": s.config.Server.MaxConnections,
 		"timeout":        s.config.Server.Timeout.String(),
+		"reflectorId":    s.config.Server.ReflectorID,
+		"branding": map[string]string{
+			"logoUrl":      s.config.Server.Branding.LogoURL,
+			"contactEmail": s.config.Server.Branding.ContactEmail,
+			"sponsorText":  s.config.Server.Branding.SponsorText,
+			"website":      s.config.Server.Branding.Website,
+		},
+		"features": s.config.EnabledFeatures(),
+	}
+
+	s.mu.RLock()
+	clockCheck := s.clockCheck
+	s.mu.RUnlock()
+	if clockCheck != nil {
+		response["clock"] = clockCheck.Status()
+	} else {
+		response["clock"] = ntpcheck.Status{}
 	}
 
 	if err := json.NewEncoder(w).Encode(response); err != nil {
@@ -710,6 +1860,26 @@ func (s *Server) handleSystemInfo(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleVersion returns the build metadata: version, commit, dirty flag, Go
+// version and platform
+func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
+	info := version.Get()
+	info.Version = s.version
+	info.BuildTime = s.buildTime
+
+	if err := json.NewEncoder(w).Encode(info); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+// handleFeatures lists the feature flags of experimental subsystems and
+// whether this instance enables them
+func (s *Server) handleFeatures(w http.ResponseWriter, r *http.Request) {
+	if err := json.NewEncoder(w).Encode(s.config.FeatureList()); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	if err := json.NewEncoder(w).Encode(map[string]string{
 		"status": "healthy",
@@ -728,16 +1898,28 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 
 	s.logger.Debug("New WebSocket connection", logger.String("remote", r.RemoteAddr))
 
+	if s.notifier != nil {
+		id, err := s.notifier.attach(conn, r.URL.Query().Get("session"), time.Now())
+		if err != nil {
+			s.logger.Error("Failed to create notification session", logger.Error(err))
+			_ = conn.Close()
+			return
+		}
+		defer s.notifier.detach(conn, time.Now())
+
+		if list, err := s.notifier.watchList(id); err == nil {
+			s.sendWebSocketMessage(conn, "session", list)
+		}
+	}
+
 	// Register client
-	s.websocketHub.register <- conn
+	if !s.websocketHub.registerClient(conn) {
+		_ = conn.Close()
+		return
+	}
 
 	// Handle client disconnect
-	defer func() {
-		s.websocketHub.unregister <- conn
-	}()
-
-	// Send initial data
-	s.sendInitialData(conn)
+	defer s.websocketHub.unregisterClient(conn)
 
 	// Keep connection alive and handle client messages
 	for {
@@ -752,18 +1934,22 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (s *Server) sendInitialData(conn *websocket.Conn) {
-	// Send current stats
-	stats := s.repeaterManager.GetStats()
-	s.sendWebSocketMessage(conn, "stats_update", map[string]interface{}{
-		"activeRepeaters": stats.ActiveRepeaters,
-		"totalPackets":    stats.TotalPackets,
-	})
+// sendWebSocketMessageTo sends a message to the listed clients through the hub
+func (s *Server) sendWebSocketMessageTo(clients []*websocket.Conn, messageType string, data interface{}) {
+	jsonData, err := json.Marshal(WebSocketMessage{Type: messageType, Data: data})
+	if err != nil {
+		s.logger.Error("Failed to marshal WebSocket message", logger.Error(err))
+		return
+	}
 
-	// Send current repeaters
-	s.sendWebSocketMessage(conn, "repeaters_update", map[string]interface{}{
-		"repeaters": stats.Repeaters,
-	})
+	select {
+	case s.websocketHub.direct <- directMessage{clients: clients, data: jsonData}:
+		s.websocketHub.metrics.queued.Add(1)
+	default:
+		s.websocketHub.metrics.dropped.Add(1)
+		s.logger.Warn("WebSocket direct channel full, dropping message",
+			logger.String("message_type", messageType))
+	}
 }
 
 func (s *Server) sendWebSocketMessage(conn *websocket.Conn, messageType string, data interface{}) {
@@ -799,8 +1985,11 @@ func (s *Server) handleUpdateServerConfig(w http.ResponseWriter, r *http.Request
 
 func (s *Server) handleGetBlocklistConfig(w http.ResponseWriter, r *http.Request) {
 	config := map[string]interface{}{
-		"enabled":   s.config.Blocklist.Enabled,
-		"callsigns": s.config.Blocklist.Callsigns,
+		"enabled":          s.config.Blocklist.Enabled,
+		"callsigns":        s.config.Blocklist.Callsigns,
+		"subscriptions":    s.config.Blocklist.Subscriptions,
+		"refresh_interval": s.config.Blocklist.RefreshInterval.String(),
+		"entries":          s.repeaterManager.GetBlocklist().GetEntries(),
 	}
 	if err := json.NewEncoder(w).Encode(config); err != nil {
 		s.logger.Error("failed to encode JSON response", logger.Error(err))
@@ -840,6 +2029,11 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Authentication not configured", http.StatusBadRequest)
 		return
 	}
+	// Proxy and OIDC users never present a password here
+	if s.authBackend() != config.AuthBackendLocal {
+		http.Error(w, "Password login not available", http.StatusBadRequest)
+		return
+	}
 
 	var loginRequest struct {
 		Username string `json:"username"`
@@ -862,32 +2056,18 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Generate session token
-	token, err := s.generateSessionToken()
+	// The configured web user is the administrator
+	token, expiry, err := s.newSession(loginRequest.Username, config.RoleAdmin)
 	if err != nil {
 		s.logger.Error("Failed to generate session token", logger.Error(err))
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	// Store session with 24-hour expiry
-	expiry := time.Now().Add(24 * time.Hour)
-	s.sessionsMu.Lock()
-	s.sessions[token] = expiry
-	s.sessionsMu.Unlock()
-
 	s.logger.Info("Successful login", logger.String("username", loginRequest.Username), logger.String("remote_addr", r.RemoteAddr))
 
 	// Set cookie and return token
-	http.SetCookie(w, &http.Cookie{
-		Name:     "session_token",
-		Value:    token,
-		Expires:  expiry,
-		HttpOnly: true,
-		Secure:   r.TLS != nil, // Only secure if HTTPS
-		SameSite: http.SameSiteStrictMode,
-		Path:     "/",
-	})
+	setSessionCookie(w, r, token, expiry)
 
 	if err := json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
@@ -900,16 +2080,7 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
 	// Get token from header or cookie
-	token := r.Header.Get("Authorization")
-	if token == "" {
-		if cookie, err := r.Cookie("session_token"); err == nil {
-			token = cookie.Value
-		}
-	} else {
-		token = strings.TrimPrefix(token, "Bearer ")
-	}
-
-	if token != "" {
+	if token := sessionToken(r); token != "" {
 		// Remove session
 		s.sessionsMu.Lock()
 		delete(s.sessions, token)
@@ -937,24 +2108,18 @@ func (s *Server) handleAuthStatus(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if s.config.Web.AuthRequired {
-		// Check if currently authenticated
-		token := r.Header.Get("Authorization")
-		if token == "" {
-			if cookie, err := r.Cookie("session_token"); err == nil {
-				token = cookie.Value
-			}
-		} else {
-			token = strings.TrimPrefix(token, "Bearer ")
+		response["backend"] = s.authBackend()
+		if s.oidc != nil {
+			response["login_url"] = "/api/auth/oidc/login"
 		}
 
-		if token != "" {
-			s.sessionsMu.RLock()
-			expiry, exists := s.sessions[token]
-			s.sessionsMu.RUnlock()
-
-			if exists && time.Now().Before(expiry) {
-				response["authenticated"] = true
-				response["expires"] = expiry.Format(time.RFC3339)
+		// Check if currently authenticated
+		if sess, ok := s.authenticate(r); ok && sess.role != config.RoleNone {
+			response["authenticated"] = true
+			response["user"] = sess.user
+			response["role"] = sess.role
+			if !sess.expiry.IsZero() {
+				response["expires"] = sess.expiry.Format(time.RFC3339)
 			}
 		}
 	} else {
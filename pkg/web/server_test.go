@@ -0,0 +1,243 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/dbehnke/ysf-nexus/pkg/config"
+	"github.com/dbehnke/ysf-nexus/pkg/health"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+	"github.com/dbehnke/ysf-nexus/pkg/version"
+)
+
+// freePort returns a TCP port that was free at the time of the call
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer func() { _ = l.Close() }()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func newTestServer(t *testing.T) (*Server, int) {
+	t.Helper()
+	port := freePort(t)
+	cfg := &config.Config{Web: config.WebConfig{Enabled: true, Host: "127.0.0.1", Port: port}}
+	log := logger.NewTestLogger(os.Stdout)
+	events := make(chan repeater.Event, 10)
+	manager := repeater.NewManagerWithLogger(time.Minute, 10, events, time.Minute, 0, log)
+	return NewServer(cfg, log, manager, repeater.NewEventBus(10, log), nil, nil, "test", "now"), port
+}
+
+// waitForHTTP polls the health endpoint until the server answers
+func waitForHTTP(t *testing.T, port int) {
+	t.Helper()
+	url := fmt.Sprintf("http://127.0.0.1:%d/api/health", port)
+	for i := 0; i < 50; i++ {
+		if resp, err := http.Get(url); err == nil {
+			_ = resp.Body.Close()
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("web server did not come up on port %d", port)
+}
+
+func TestServerStopClosesWebSocketsAndRestarts(t *testing.T) {
+	s, port := newTestServer(t)
+
+	for run := 0; run < 2; run++ {
+		startErr := make(chan error, 1)
+		go func() { startErr <- s.Start(context.Background()) }()
+		waitForHTTP(t, port)
+
+		conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://127.0.0.1:%d/ws", port), nil)
+		if err != nil {
+			t.Fatalf("run %d: websocket dial: %v", run, err)
+		}
+
+		// Wait for the hub to register the client
+		for i := 0; i < 50 && s.websocketHub.clientCount() == 0; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if s.websocketHub.clientCount() != 1 {
+			t.Fatalf("run %d: expected 1 registered client", run)
+		}
+
+		if err := s.Stop(); err != nil {
+			t.Fatalf("run %d: stop: %v", run, err)
+		}
+
+		select {
+		case err := <-startErr:
+			if err != nil {
+				t.Fatalf("run %d: start returned %v", run, err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("run %d: Start did not return after Stop", run)
+		}
+
+		if s.websocketHub.clientCount() != 0 {
+			t.Errorf("run %d: expected hub to drop all clients", run)
+		}
+
+		// The client must observe the close
+		_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				break
+			}
+		}
+		_ = conn.Close()
+
+		if s.IsRunning() {
+			t.Fatalf("run %d: server still reports running", run)
+		}
+	}
+}
+
+func TestServerStartTwiceFails(t *testing.T) {
+	s, port := newTestServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Start(ctx) }()
+	waitForHTTP(t, port)
+
+	if err := s.Start(ctx); err == nil {
+		t.Errorf("expected second Start to fail while running")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Start did not return after context cancel")
+	}
+}
+
+func TestServerRestartReplaysMissedEvents(t *testing.T) {
+	s, port := newTestServer(t)
+
+	startErr := make(chan error, 1)
+	go func() { startErr <- s.Start(context.Background()) }()
+	waitForHTTP(t, port)
+	if err := s.Stop(); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	<-startErr
+
+	// Published while the server is down
+	s.events.Publish(repeater.Event{Type: repeater.EventTalkEnd, Callsign: "W1AW", Duration: 5 * time.Second})
+
+	go func() { startErr <- s.Start(context.Background()) }()
+	defer func() { _ = s.Stop() }()
+	waitForHTTP(t, port)
+
+	for i := 0; i < 50; i++ {
+		if stats, ok := s.callsigns.get("W1AW"); ok && stats.Transmissions == 1 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("event published while stopped was not replayed after restart")
+}
+
+func TestSystemRuntime(t *testing.T) {
+	s, _ := newTestServer(t)
+	router := s.setupRoutes()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/system/runtime", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 without a monitor, got %d", rec.Code)
+	}
+
+	m := health.New(health.Options{QueueThreshold: 0.8}, logger.NewTestLogger(io.Discard))
+	m.AddQueue("websocket_broadcast", s.BroadcastBacklog)
+	s.SetRuntimeMonitor(m)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/system/runtime", nil))
+	var snap health.Snapshot
+	if err := json.NewDecoder(rec.Body).Decode(&snap); err != nil {
+		t.Fatalf("decode runtime: %v", err)
+	}
+	if snap.Goroutines == 0 || len(snap.Queues) != 1 || snap.Queues[0].Capacity != 256 {
+		t.Errorf("unexpected runtime snapshot %+v", snap)
+	}
+}
+
+func TestVersionEndpoint(t *testing.T) {
+	s, _ := newTestServer(t)
+	router := s.setupRoutes()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/version", nil))
+	var info version.Info
+	if err := json.NewDecoder(rec.Body).Decode(&info); err != nil {
+		t.Fatalf("decode version: %v", err)
+	}
+	if info.Version != "test" || info.BuildTime != "now" || info.GoVersion == "" || info.Platform == "" {
+		t.Errorf("unexpected version info %+v", info)
+	}
+}
+
+func TestKickRepeater(t *testing.T) {
+	s, _ := newTestServer(t)
+	router := s.setupRoutes()
+	addr := &net.UDPAddr{IP: net.ParseIP("192.0.2.10"), Port: 42000}
+	s.repeaterManager.AddRepeater("W1AW", addr)
+
+	kick := func() int {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/repeaters/W1AW/kick", nil))
+		return rec.Code
+	}
+	if code := kick(); code != http.StatusOK {
+		t.Fatalf("kick status %d, want 200", code)
+	}
+	if s.repeaterManager.GetRepeater(addr) != nil {
+		t.Error("expected the repeater to be disconnected")
+	}
+	if code := kick(); code != http.StatusNotFound {
+		t.Errorf("second kick status %d, want 404", code)
+	}
+}
+
+func TestRepeatersPageLimits(t *testing.T) {
+	s, _ := newTestServer(t)
+	s.repeaterManager.AddRepeater("W1AW", &net.UDPAddr{IP: net.ParseIP("192.0.2.10"), Port: 42000})
+
+	for _, query := range []string{
+		"page=2&limit=9223372036854775807",
+		"page=9223372036854775807&limit=9223372036854775807",
+		"limit=100000",
+	} {
+		rec := httptest.NewRecorder()
+		s.handleRepeaters(rec, httptest.NewRequest(http.MethodGet, "/api/repeaters?"+query, nil))
+		var body struct {
+			Limit int `json:"limit"`
+		}
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: status %d", query, rec.Code)
+			continue
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || body.Limit != maxRepeaterPageSize {
+			t.Errorf("%s: limit %d, want %d (%v)", query, body.Limit, maxRepeaterPageSize, err)
+		}
+	}
+}
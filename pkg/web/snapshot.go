@@ -0,0 +1,75 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/bridge"
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+// The WebSocket carries changes only. Broadcast messages are numbered, and
+// GET /api/snapshot returns the full dashboard state with the number of the
+// last message it includes. A client opens the socket first and buffers what
+// arrives, fetches the snapshot, drops buffered messages numbered at or below
+// its seq and applies the rest. A gap in the numbers means messages were
+// lost, because the hub channel was full or the client fell behind, and the
+// client fetches a new snapshot.
+//
+// The number is read before the state, so the snapshot reflects every
+// message up to seq; it may already reflect some later ones too, which
+// clients apply idempotently.
+
+// snapshotTalkLogs is the default number of talk log entries in a snapshot
+const snapshotTalkLogs = 50
+
+// sequence returns the number of the last broadcast message
+func (hub *WebSocketHub) sequence() uint64 {
+	hub.seqMu.Lock()
+	defer hub.seqMu.Unlock()
+	return hub.seq
+}
+
+// handleSnapshot returns the dashboard state to apply WebSocket messages to.
+// The logs query parameter sets the number of talk log entries.
+func (s *Server) handleSnapshot(w http.ResponseWriter, r *http.Request) {
+	seq := s.websocketHub.sequence()
+
+	logs := snapshotTalkLogs
+	if parsed, err := strconv.Atoi(r.URL.Query().Get("logs")); err == nil && parsed >= 0 {
+		logs = parsed
+	}
+
+	level := s.maskLevel(r)
+	repeaters := s.repeaterManager.GetStats().Repeaters
+	if repeaters == nil {
+		repeaters = []repeater.RepeaterStats{}
+	}
+	for i := range repeaters {
+		repeaters[i] = repeaters[i].Masked(level)
+	}
+	bridges := make(map[string]bridge.BridgeStatus)
+	for name, status := range s.bridgeStatuses() {
+		bridges[name] = status
+	}
+
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"seq":            seq,
+		"timestamp":      time.Now(),
+		"stats":          s.statsSummary(),
+		"repeaters":      repeaters,
+		"bridges":        bridges,
+		"current_talker": s.currentTalker(level),
+		"talk_logs":      s.RecentTalkLogs(logs),
+		// Messages waiting in the hub; a full channel drops new ones
+		"websocket": map[string]int{
+			"backlog":  len(s.websocketHub.broadcast),
+			"capacity": cap(s.websocketHub.broadcast),
+		},
+	}); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
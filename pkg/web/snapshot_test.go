@@ -0,0 +1,56 @@
+package web
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSnapshotSequence(t *testing.T) {
+	s, _ := newTestServer(t)
+	router := s.setupRoutes()
+	s.repeaterManager.AddRepeater("W1AW", &net.UDPAddr{IP: net.ParseIP("192.0.2.10"), Port: 42000})
+
+	s.broadcastWebSocketMessage("event", 1)
+	s.broadcastWebSocketMessage("event", 2)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/snapshot", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("snapshot status %d", rec.Code)
+	}
+	var snapshot struct {
+		Seq       uint64            `json:"seq"`
+		Repeaters []json.RawMessage `json:"repeaters"`
+		TalkLogs  []TalkLogEntry    `json:"talk_logs"`
+		WebSocket struct {
+			Backlog  int `json:"backlog"`
+			Capacity int `json:"capacity"`
+		} `json:"websocket"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&snapshot); err != nil {
+		t.Fatalf("decode snapshot: %v", err)
+	}
+	if snapshot.Seq != 2 || len(snapshot.Repeaters) != 1 || snapshot.TalkLogs == nil {
+		t.Errorf("unexpected snapshot %+v", snapshot)
+	}
+	if snapshot.WebSocket.Backlog != 2 || snapshot.WebSocket.Capacity != cap(s.websocketHub.broadcast) {
+		t.Errorf("unexpected hub backlog %+v", snapshot.WebSocket)
+	}
+
+	// Broadcasts are numbered in queue order; a dropped one leaves a gap
+	for want := uint64(1); want <= 2; want++ {
+		var msg WebSocketMessage
+		if err := json.Unmarshal(<-s.websocketHub.broadcast, &msg); err != nil || msg.Seq != want {
+			t.Fatalf("message seq %d, want %d (%v)", msg.Seq, want, err)
+		}
+	}
+	for i := 0; i < cap(s.websocketHub.broadcast)+1; i++ {
+		s.broadcastWebSocketMessage("event", i)
+	}
+	if got := s.websocketHub.sequence(); got != uint64(cap(s.websocketHub.broadcast))+3 {
+		t.Errorf("sequence %d after a dropped message, want %d", got, cap(s.websocketHub.broadcast)+3)
+	}
+}
@@ -0,0 +1,61 @@
+package web
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/repeater"
+)
+
+func TestTalkLimitsAPI(t *testing.T) {
+	s, _ := newTestServer(t)
+	s.repeaterManager.AddRepeater("W1NEWS", &net.UDPAddr{IP: net.ParseIP("192.0.2.20"), Port: 42000})
+	router := s.setupRoutes()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/api/talk-limits/w1news", strings.NewReader(`{}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without limits, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/api/talk-limits/w1news",
+		strings.NewReader(`{"talk_max_duration":"20m","unmute_after":"0s"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT talk limit: %d %s", rec.Code, rec.Body.String())
+	}
+	limit := s.repeaterManager.TalkLimitOf("W1NEWS")
+	if limit == nil || *limit.TalkMaxDuration != 20*time.Minute || *limit.UnmuteAfter != 0 {
+		t.Fatalf("unexpected override %+v", limit)
+	}
+
+	// The repeaters API lists the override
+	rec = httptest.NewRecorder()
+	s.handleRepeaters(rec, httptest.NewRequest(http.MethodGet, "/api/repeaters", nil))
+	var page struct {
+		Repeaters  []repeater.RepeaterStats `json:"repeaters"`
+		TalkLimits []repeater.TalkLimit     `json:"talk_limits"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&page); err != nil {
+		t.Fatalf("decode repeaters: %v", err)
+	}
+	if len(page.TalkLimits) != 1 || len(page.Repeaters) != 1 || page.Repeaters[0].TalkLimit == nil {
+		t.Fatalf("expected the override in the repeaters API, got %+v", page)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/talk-limits/W1NEWS", nil))
+	if rec.Code != http.StatusOK || s.repeaterManager.TalkLimitOf("W1NEWS") != nil {
+		t.Errorf("expected the override to be removed, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/talk-limits/W1NEWS", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for a callsign without an override, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,178 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/logger"
+	"github.com/dbehnke/ysf-nexus/pkg/network"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+// Packet traces are streamed on their own WebSocket, /ws/trace, rather than
+// through the hub: dashboard clients never see them and a busy trace cannot
+// crowd out regular updates. Each trace client has a small queue; packets
+// that do not fit are dropped for that client.
+
+// defaultTraceDuration is used when a trace request gives no duration
+const defaultTraceDuration = time.Minute
+
+// traceQueueSize is the number of packets buffered per trace client
+const traceQueueSize = 256
+
+// traceStream holds the trace WebSocket clients
+type traceStream struct {
+	mu      sync.Mutex
+	clients map[*websocket.Conn]chan []byte
+}
+
+// PublishTrace sends a traced packet to the trace WebSocket clients. It does
+// not block; a client whose queue is full misses the packet.
+func (s *Server) PublishTrace(packet network.TracedPacket) {
+	t := &s.traces
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.clients) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(WebSocketMessage{Type: "packet_trace", Data: packet})
+	if err != nil {
+		s.logger.Error("Failed to marshal packet trace", logger.Error(err))
+		return
+	}
+	for _, queue := range t.clients {
+		select {
+		case queue <- data:
+		default:
+		}
+	}
+}
+
+// handleTraceWebSocket streams traced packets until the client disconnects
+func (s *Server) handleTraceWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		s.logger.Error("WebSocket upgrade failed", logger.Error(err))
+		return
+	}
+
+	queue := make(chan []byte, traceQueueSize)
+	t := &s.traces
+	t.mu.Lock()
+	if t.clients == nil {
+		t.clients = make(map[*websocket.Conn]chan []byte)
+	}
+	t.clients[conn] = queue
+	t.mu.Unlock()
+
+	done := make(chan struct{})
+	defer func() {
+		t.mu.Lock()
+		delete(t.clients, conn)
+		t.mu.Unlock()
+		close(done)
+		_ = conn.Close()
+	}()
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case data := <-queue:
+				_ = conn.SetWriteDeadline(time.Now().Add(writeWait))
+				if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+					_ = conn.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	// Reads only detect the disconnect
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// handleGetTraces lists the active packet traces
+func (s *Server) handleGetTraces(w http.ResponseWriter, r *http.Request) {
+	refl, ok := s.reflector.(interface {
+		PacketTraces() []network.TraceInfo
+	})
+	if !ok {
+		http.Error(w, "Packet trace not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(refl.PacketTraces()); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+// handleStartTrace starts tracing a repeater's packets for a limited time
+func (s *Server) handleStartTrace(w http.ResponseWriter, r *http.Request) {
+	refl, ok := s.reflector.(interface {
+		StartPacketTrace(string, time.Duration) (network.TraceInfo, error)
+	})
+	if !ok {
+		http.Error(w, "Packet trace not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var request struct {
+		Target   string `json:"target"`   // Repeater callsign or host:port
+		Duration string `json:"duration"` // Go duration, e.g. "2m"
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.Target == "" {
+		http.Error(w, "target is required", http.StatusBadRequest)
+		return
+	}
+	duration := defaultTraceDuration
+	if request.Duration != "" {
+		d, err := time.ParseDuration(request.Duration)
+		if err != nil {
+			http.Error(w, "Invalid duration", http.StatusBadRequest)
+			return
+		}
+		duration = d
+	}
+
+	info, err := refl.StartPacketTrace(request.Target, duration)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(info); err != nil {
+		s.logger.Error("failed to encode JSON response", logger.Error(err))
+	}
+}
+
+// handleStopTrace ends a packet trace before it expires
+func (s *Server) handleStopTrace(w http.ResponseWriter, r *http.Request) {
+	refl, ok := s.reflector.(interface {
+		StopPacketTrace(string) bool
+	})
+	if !ok {
+		http.Error(w, "Packet trace not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	if !refl.StopPacketTrace(mux.Vars(r)["target"]) {
+		http.Error(w, "No trace on target", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
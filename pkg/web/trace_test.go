@@ -0,0 +1,58 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/dbehnke/ysf-nexus/pkg/network"
+)
+
+func TestTraceWebSocketStreamsPackets(t *testing.T) {
+	s, port := newTestServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = s.Start(ctx) }()
+	waitForHTTP(t, port)
+
+	conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://127.0.0.1:%d/ws/trace", port), nil)
+	if err != nil {
+		t.Fatalf("websocket dial: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	for i := 0; i < 50; i++ {
+		s.traces.mu.Lock()
+		n := len(s.traces.clients)
+		s.traces.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if s.websocketHub.clientCount() != 0 {
+		t.Errorf("trace client must not join the dashboard hub")
+	}
+
+	s.PublishTrace(network.TracedPacket{Direction: network.TraceRx, Address: "192.0.2.1:42000", Type: "YSFP", Size: 14})
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var msg struct {
+		Type string               `json:"type"`
+		Data network.TracedPacket `json:"data"`
+	}
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if msg.Type != "packet_trace" || msg.Data.Address != "192.0.2.1:42000" || msg.Data.Type != "YSFP" {
+		t.Errorf("unexpected message %s", data)
+	}
+}
@@ -0,0 +1,193 @@
+package wiresx
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/dbehnke/ysf-nexus/pkg/network"
+)
+
+// allResponse is the opcode of room list and search replies
+var allResponse = []byte{0x5D, 0x46, 0x5F, 0x26}
+
+const (
+	// MaxEntries is the most entries a reply lists
+	MaxEntries = 20
+	// replyDataSize is the size list replies are padded to before the end marker
+	replyDataSize = 1029
+	// laterBlockSize is the number of reply bytes in blocks after the first,
+	// whose frame 1 starts with a zero byte
+	laterBlockSize = blockSize - 1
+)
+
+// Node identifies the node answering Wires-X requests
+type Node struct {
+	ID       string // 5-digit ID
+	Name     string // Up to 10 characters
+	Callsign string // Up to 10 characters, sent as the gateway callsign
+}
+
+// Entry is a room in a room list or search reply
+type Entry struct {
+	ID          string // 5 digits
+	Name        string // Up to 16 characters
+	Count       int    // Stations in the room, up to 999
+	Description string // Up to 14 characters
+}
+
+// field returns s space-padded or cut to n bytes
+func field(s string, n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		if i < len(s) {
+			b[i] = s[i]
+		} else {
+			b[i] = ' '
+		}
+	}
+	return b
+}
+
+// SearchReply builds the reply to a search request listing the first
+// MaxEntries entries. seq is the reply sequence number, incremented by the
+// caller for each reply it sends.
+func SearchReply(seq byte, node Node, entries []Entry) []byte {
+	if len(entries) > MaxEntries {
+		entries = entries[:MaxEntries]
+	}
+
+	data := make([]byte, 0, replyDataSize+2)
+	data = append(data, seq)
+	data = append(data, allResponse...)
+	data = append(data, '0', '2')
+	data = append(data, field(node.ID, 5)...)
+	data = append(data, field(node.Name, 10)...)
+	data = append(data, '1')
+	data = append(data, fmt.Sprintf("%02d", len(entries))...)
+
+	for _, entry := range entries {
+		count := entry.Count
+		if count > 999 {
+			count = 999
+		}
+		data = append(data, '1')
+		data = append(data, field(entry.ID, 5)...)
+		data = append(data, field(entry.Name, 16)...)
+		data = append(data, fmt.Sprintf("%03d", count)...)
+		data = append(data, field("", 10)...)
+		data = append(data, field(entry.Description, 14)...)
+		data = append(data, 0x0D)
+	}
+
+	for len(data) < replyDataSize {
+		data = append(data, ' ')
+	}
+	data = append(data, endMarker)
+	return append(data, checksum(data))
+}
+
+// frameTotal returns the FICH frame total for the frames carrying length
+// bytes of reply data from offset on, frame 0 included
+func frameTotal(length, offset int) uint8 {
+	remaining := length - offset
+	switch {
+	case remaining > 220:
+		return 7
+	case remaining > 180:
+		return 6
+	case remaining > 140:
+		return 5
+	case remaining > 100:
+		return 4
+	case remaining > 60:
+		return 3
+	case remaining > 20:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// Frames encodes a reply as the YSFD packets of a data FR mode transmission:
+// a header, the data in blocks of eight frames each starting with the
+// callsign frame, and a terminator.
+func Frames(node Node, reply []byte) [][]byte {
+	length := len(reply)
+	var bt uint8
+	if length > blockSize {
+		// Blocks after the first give a byte of frame 1 to a zero prefix
+		bt = uint8(1 + (length-blockSize)/laterBlockSize)
+		length += int(bt)
+	}
+	// The last frame is padded with zeros
+	data := make([]byte, length+2*network.DataBlockSize)
+	copy(data, reply)
+
+	// Callsign data: the node in frame 0, the node ID in frame 1
+	csd1 := append(bytes.Repeat([]byte{'*'}, 10), field(node.Name, 10)...)
+	csd2 := field(node.Callsign, 20)
+	csd3 := field("", 20)
+	copy(csd3, field(node.ID, 5))
+	copy(csd3[15:], field(node.ID, 5))
+
+	fich := network.FICH{
+		FI: network.FIHeader,
+		CS: 1,
+		BT: bt,
+		FT: frameTotal(length, 0),
+		MR: 1,
+		DT: network.DTDataFR,
+	}
+	var seq byte
+	var packets [][]byte
+	emit := func(block1, block2 []byte) {
+		packet := make([]byte, network.DataPacketSize)
+		copy(packet, network.PacketTypeData)
+		copy(packet[network.GatewayFieldOffset:], field(node.Callsign, 10))
+		copy(packet[network.SourceFieldOffset:], field(node.Name, 10))
+		copy(packet[network.DestFieldOffset:], field("ALL", 10))
+		packet[network.FrameCounterOffset] = seq << 1
+		seq++
+
+		frame := packet[network.FrameOffset : network.FrameOffset+network.FrameSize]
+		copy(frame, network.SyncBytes[:])
+		network.EncodeFICH(fich, frame)
+		network.EncodeDataFR(block1, frame, 1)
+		network.EncodeDataFR(block2, frame, 2)
+		packets = append(packets, packet)
+	}
+
+	emit(csd1, csd2)
+
+	fich.FI = network.FICommunications
+	offset := 0
+	for offset < length {
+		switch fich.FN {
+		case 0:
+			fich.FT = frameTotal(length, offset)
+			emit(csd1, csd2)
+		case 1:
+			if fich.BN == 0 {
+				emit(csd3, data[offset:offset+network.DataBlockSize])
+				offset += network.DataBlockSize
+			} else {
+				block := append([]byte{0x00}, data[offset:offset+network.DataBlockSize-1]...)
+				emit(csd3, block)
+				offset += network.DataBlockSize - 1
+			}
+		default:
+			emit(data[offset:offset+network.DataBlockSize], data[offset+network.DataBlockSize:offset+2*network.DataBlockSize])
+			offset += 2 * network.DataBlockSize
+		}
+		fich.FN++
+		if fich.FN >= blockFrames {
+			fich.FN = 0
+			fich.BN++
+		}
+	}
+
+	fich.FI = network.FITerminator
+	emit(csd1, csd2)
+	packets[len(packets)-1][network.FrameCounterOffset] |= 0x01
+	return packets
+}
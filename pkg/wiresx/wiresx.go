@@ -0,0 +1,196 @@
+// Package wiresx implements the part of the Wires-X protocol a reflector
+// needs to answer radios: commands sent from a radio's Wires-X menu are
+// reassembled from the data FR mode frames of a transmission, and replies are
+// encoded as the frames of a transmission sent back. The command and reply
+// layouts match YSFGateway's Wires-X implementation, so gateways configured
+// to pass Wires-X commands through to the reflector work with real radios.
+package wiresx
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/network"
+)
+
+// Command types
+const (
+	CommandDX         = "dx"         // Node information request
+	CommandAll        = "all"        // Room list request
+	CommandSearch     = "search"     // Room search request
+	CommandConnect    = "connect"    // Connect to a room
+	CommandDisconnect = "disconnect" // Disconnect from the room
+	CommandCategory   = "category"   // Category list request
+)
+
+// Command opcodes, following the sequence number byte
+var (
+	dxRequest   = []byte{0x5D, 0x71, 0x5F}
+	allRequest  = []byte{0x5D, 0x66, 0x5F}
+	connRequest = []byte{0x5D, 0x23, 0x5F}
+	discRequest = []byte{0x5D, 0x2A, 0x5F}
+	catRequest  = []byte{0x5D, 0x67, 0x5F}
+)
+
+const (
+	// endMarker ends the data of a command or reply; a checksum byte follows
+	endMarker = 0x03
+	// blockFrames is the number of frames in a block
+	blockFrames = 8
+	// blockSize is the number of command bytes in the first block: 20 in
+	// frame 1 and 40 in each of frames 2 to 7 (frame 0 carries callsigns)
+	blockSize = 260
+)
+
+// Command is a Wires-X command received from a radio
+type Command struct {
+	Type   string
+	ID     string // Room ID of a connect request
+	Search string // Text of a search request, trailing spaces removed
+	Start  int    // First entry wanted by a room list or search request, from 1
+}
+
+// checksum is the sum of the bytes, the check following the end marker
+func checksum(data []byte) byte {
+	var sum byte
+	for _, b := range data {
+		sum += b
+	}
+	return sum
+}
+
+// parseCommand decodes the command bytes of a transmission. The end marker
+// is the first 0x03 after the opcode followed by a matching checksum.
+func parseCommand(data []byte) (Command, bool) {
+	end := -1
+	for i := 5; i+1 < len(data); i++ {
+		if data[i] == endMarker && checksum(data[:i+1]) == data[i+1] {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return Command{}, false
+	}
+	args := data[5:end]
+
+	opcode := data[1:4]
+	switch {
+	case bytes.Equal(opcode, dxRequest):
+		return Command{Type: CommandDX}, true
+	case bytes.Equal(opcode, connRequest):
+		if len(args) < 5 {
+			return Command{}, false
+		}
+		return Command{Type: CommandConnect, ID: string(args[:5])}, true
+	case bytes.Equal(opcode, discRequest):
+		return Command{Type: CommandDisconnect}, true
+	case bytes.Equal(opcode, catRequest):
+		return Command{Type: CommandCategory}, true
+	case bytes.Equal(opcode, allRequest):
+		// "01" lists rooms, "11" searches them; three digits give the first entry
+		if len(args) < 5 {
+			return Command{}, false
+		}
+		start, _ := strconv.Atoi(string(args[2:5]))
+		switch string(args[:2]) {
+		case "01":
+			return Command{Type: CommandAll, Start: start}, true
+		case "11":
+			text := args[5:]
+			if len(text) > 16 {
+				text = text[:16]
+			}
+			search := strings.TrimRight(string(bytes.TrimRight(text, "\x00")), " ")
+			return Command{Type: CommandSearch, Search: search, Start: start}, true
+		}
+	}
+	return Command{}, false
+}
+
+// Reassembler collects Wires-X commands from the frames of transmissions,
+// one command per source
+type Reassembler struct {
+	mu      sync.Mutex
+	pending map[string]*pendingCommand
+}
+
+// pendingCommand is a command whose last frame has not arrived yet
+type pendingCommand struct {
+	data    [blockSize]byte
+	updated time.Time
+}
+
+// NewReassembler creates an empty command reassembler
+func NewReassembler() *Reassembler {
+	return &Reassembler{pending: make(map[string]*pendingCommand)}
+}
+
+// Add feeds a 120-byte YSF frame received from source. It returns the
+// command once the last frame of a command arrived with a valid end marker.
+// Frames that are not Wires-X data, or fail their CRC, are ignored.
+func (r *Reassembler) Add(source string, frame []byte, now time.Time) (Command, bool) {
+	fich, ok := network.DecodeFICH(frame)
+	if !ok || fich.DT != network.DTDataFR {
+		return Command{}, false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if fich.FI == network.FIHeader {
+		// A new transmission starts over
+		delete(r.pending, source)
+		return Command{}, false
+	}
+	// Frame 0 carries callsigns; commands fit in the first block
+	if fich.FI != network.FICommunications || fich.BN != 0 || fich.FN == 0 {
+		return Command{}, false
+	}
+
+	cmd, ok := r.pending[source]
+	if !ok {
+		cmd = &pendingCommand{}
+		r.pending[source] = cmd
+	}
+	cmd.updated = now
+
+	if fich.FN == 1 {
+		// The first data block of frame 1 repeats the sender's ID
+		data, ok := network.DecodeDataFR(frame, 2)
+		if !ok {
+			return Command{}, false
+		}
+		copy(cmd.data[:network.DataBlockSize], data)
+	} else {
+		offset := int(fich.FN-2)*2*network.DataBlockSize + network.DataBlockSize
+		for block := 1; block <= 2; block++ {
+			data, ok := network.DecodeDataFR(frame, block)
+			if !ok {
+				return Command{}, false
+			}
+			copy(cmd.data[offset+(block-1)*network.DataBlockSize:], data)
+		}
+	}
+
+	if fich.FN != fich.FT {
+		return Command{}, false
+	}
+	delete(r.pending, source)
+	end := network.DataBlockSize + int(fich.FN-1)*2*network.DataBlockSize
+	return parseCommand(cmd.data[:end])
+}
+
+// Expire drops partial commands last updated before the given time
+func (r *Reassembler) Expire(before time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for source, cmd := range r.pending {
+		if cmd.updated.Before(before) {
+			delete(r.pending, source)
+		}
+	}
+}
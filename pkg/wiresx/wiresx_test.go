@@ -0,0 +1,157 @@
+package wiresx
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/dbehnke/ysf-nexus/pkg/network"
+)
+
+var testNode = Node{ID: "12345", Name: "YSF NEXUS", Callsign: "NEXUS"}
+
+// command builds the bytes of a command as a radio sends them
+func command(opcode []byte, args string) []byte {
+	data := append([]byte{0x00}, opcode...)
+	data = append(data, 0x26)
+	data = append(data, args...)
+	data = append(data, endMarker)
+	return append(data, checksum(data))
+}
+
+// feed passes the frames of a transmission to r and returns the command
+func feed(t *testing.T, r *Reassembler, source string, packets [][]byte) (Command, bool) {
+	t.Helper()
+	var cmd Command
+	var found bool
+	for _, packet := range packets {
+		frame := packet[network.FrameOffset : network.FrameOffset+network.FrameSize]
+		if c, ok := r.Add(source, frame, time.Now()); ok {
+			if found {
+				t.Fatalf("command completed twice: %+v", c)
+			}
+			cmd, found = c, true
+		}
+	}
+	return cmd, found
+}
+
+func TestReassembleSearch(t *testing.T) {
+	r := NewReassembler()
+	packets := Frames(testNode, command(allRequest, "11001LH              "))
+
+	cmd, ok := feed(t, r, "192.0.2.1:42000", packets)
+	if !ok {
+		t.Fatal("expected a command")
+	}
+	if cmd.Type != CommandSearch || cmd.Search != "LH" || cmd.Start != 1 {
+		t.Errorf("command = %+v", cmd)
+	}
+	if len(r.pending) != 0 {
+		t.Errorf("expected no pending commands, got %d", len(r.pending))
+	}
+}
+
+func TestReassembleCommands(t *testing.T) {
+	tests := []struct {
+		data []byte
+		want Command
+	}{
+		{command(dxRequest, ""), Command{Type: CommandDX}},
+		{command(allRequest, "01011"), Command{Type: CommandAll, Start: 11}},
+		{command(connRequest, "54321"), Command{Type: CommandConnect, ID: "54321"}},
+		{command(discRequest, ""), Command{Type: CommandDisconnect}},
+	}
+	for _, tt := range tests {
+		cmd, ok := feed(t, NewReassembler(), "src", Frames(testNode, tt.data))
+		if !ok || cmd != tt.want {
+			t.Errorf("command = %+v (%v), want %+v", cmd, ok, tt.want)
+		}
+	}
+
+	// A bad checksum is not a command
+	data := command(dxRequest, "")
+	data[len(data)-1]++
+	if cmd, ok := feed(t, NewReassembler(), "src", Frames(testNode, data)); ok {
+		t.Errorf("unexpected command %+v", cmd)
+	}
+}
+
+func TestReassemblerIgnoresVoiceAndExpires(t *testing.T) {
+	r := NewReassembler()
+
+	// A voice frame is ignored
+	voice := make([]byte, network.FrameSize)
+	network.EncodeFICH(network.FICH{FI: network.FICommunications, FN: 1, FT: 6, DT: network.DTVoiceData2}, voice)
+	if _, ok := r.Add("src", voice, time.Now()); ok || len(r.pending) != 0 {
+		t.Error("expected voice frames to be ignored")
+	}
+
+	// A partial command expires
+	packets := Frames(testNode, command(allRequest, "11001LH              "))
+	start := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	for _, packet := range packets[:3] {
+		r.Add("src", packet[network.FrameOffset:network.FrameOffset+network.FrameSize], start)
+	}
+	if len(r.pending) != 1 {
+		t.Fatalf("expected a pending command, got %d", len(r.pending))
+	}
+	r.Expire(start.Add(time.Second))
+	if len(r.pending) != 0 {
+		t.Error("expected the partial command to expire")
+	}
+}
+
+func TestSearchReplyFrames(t *testing.T) {
+	entries := []Entry{
+		{ID: "00001", Name: "W1AW", Description: "14:05 GW"},
+		{ID: "00002", Name: "K8ABC", Count: 1200, Description: "a description that is too long"},
+	}
+	reply := SearchReply(7, testNode, entries)
+	if len(reply) != replyDataSize+2 || reply[len(reply)-2] != endMarker || reply[len(reply)-1] != checksum(reply[:len(reply)-1]) {
+		t.Fatalf("bad reply framing (%d bytes)", len(reply))
+	}
+	header := reply[:25]
+	if header[0] != 7 || !bytes.Equal(header[1:5], allResponse) || string(header[5:25]) != "0212345YSF NEXUS 102" {
+		t.Errorf("header = %q", header)
+	}
+	second := string(reply[75:125])
+	if second != "100002K8ABC           999          a description \r" {
+		t.Errorf("second entry = %q", second)
+	}
+
+	packets := Frames(testNode, reply)
+	first, _ := network.DecodeFICH(packets[0][network.FrameOffset : network.FrameOffset+network.FrameSize])
+	last := packets[len(packets)-1]
+	lastFICH, _ := network.DecodeFICH(last[network.FrameOffset : network.FrameOffset+network.FrameSize])
+	if first.FI != network.FIHeader || first.BT != 3 || lastFICH.FI != network.FITerminator || last[network.FrameCounterOffset]&0x01 != 1 {
+		t.Errorf("header %+v, terminator %+v", first, lastFICH)
+	}
+
+	// Reassembling the data frames gives back the reply
+	var data []byte
+	for _, packet := range packets[1 : len(packets)-1] {
+		frame := packet[network.FrameOffset : network.FrameOffset+network.FrameSize]
+		fich, ok := network.DecodeFICH(frame)
+		if !ok || fich.FN == 0 {
+			continue
+		}
+		block1, ok1 := network.DecodeDataFR(frame, 1)
+		block2, ok2 := network.DecodeDataFR(frame, 2)
+		if !ok1 || !ok2 {
+			t.Fatalf("frame %d/%d failed its CRC", fich.BN, fich.FN)
+		}
+		switch {
+		case fich.FN > 1:
+			data = append(data, block1...)
+			data = append(data, block2...)
+		case fich.BN == 0:
+			data = append(data, block2...)
+		default:
+			data = append(data, block2[1:]...)
+		}
+	}
+	if len(data) < len(reply) || !bytes.Equal(data[:len(reply)], reply) {
+		t.Errorf("reassembled reply differs (%d bytes)", len(data))
+	}
+}